@@ -45,14 +45,24 @@ func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener ne
 	tequilapi_endpoints.AddRoutesForIdentities(router, di.IdentityManager, di.IdentitySelector, di.IdentityRegistry, di.ConsumerBalanceTracker, di.AddressProvider, di.HermesChannelRepository, di.BCHelper, di.Transactor, di.BeneficiaryProvider, di.IdentityMover)
 	tequilapi_endpoints.AddRoutesForConnection(router, di.ConnectionManager, di.StateKeeper, di.ProposalRepository, di.IdentityRegistry, di.EventBus, di.AddressProvider)
 	tequilapi_endpoints.AddRoutesForSessions(router, di.SessionStorage)
+	tequilapi_endpoints.AddRoutesForTrafficAccounting(router, di.TrafficAccountingStorage)
 	tequilapi_endpoints.AddRoutesForConnectionLocation(router, di.IPResolver, di.LocationResolver, di.LocationResolver)
-	tequilapi_endpoints.AddRoutesForProposals(router, di.ProposalRepository, di.QualityClient)
-	tequilapi_endpoints.AddRoutesForService(router, di.ServicesManager, services.JSONParsersByType)
+	tequilapi_endpoints.AddRoutesForProposals(router, di.ProposalRepository, di.QualityClient, di.ProposalHistory)
+	tequilapi_endpoints.AddRoutesForService(router, di.ServicesManager, di.IdentityManager, services.JSONParsersByType)
 	tequilapi_endpoints.AddRoutesForPayout(router, di.IdentityManager, di.SignerFactory, di.MysteriumAPI)
 	tequilapi_endpoints.AddRoutesForAccessPolicies(di.HTTPClient, router, config.GetString(config.FlagAccessPolicyAddress))
 	tequilapi_endpoints.AddRoutesForNAT(router, di.StateKeeper)
 	tequilapi_endpoints.AddRoutesForTransactor(router, di.IdentityRegistry, di.Transactor, di.HermesPromiseSettler, di.SettlementHistoryStorage, di.AddressProvider, di.BeneficiarySaver)
-	tequilapi_endpoints.AddRoutesForConfig(router)
+	if err := tequilapi_endpoints.AddRoutesForConfig(router, di.AuditLog); err != nil {
+		return nil, err
+	}
+	tequilapi_endpoints.AddRoutesForBackup(router, di.Backup)
+	tequilapi_endpoints.AddRoutesForStorage(router, di.Storage)
+	tequilapi_endpoints.AddRoutesForAudit(router, di.AuditLog)
+	tequilapi_endpoints.AddRoutesForEventBus(router, di.EventBus)
+	if di.EventHistory != nil {
+		tequilapi_endpoints.AddRoutesForEventHistory(router, di.EventHistory)
+	}
 	tequilapi_endpoints.AddRoutesForMMN(router, di.MMN)
 	tequilapi_endpoints.AddRoutesForFeedback(router, di.Reporter)
 	tequilapi_endpoints.AddRoutesForConnectivityStatus(router, di.SessionConnectivityStatusStorage)
@@ -68,7 +78,7 @@ func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener ne
 	}
 
 	corsPolicy := tequilapi.NewMysteriumCorsPolicy()
-	return tequilapi.NewServer(listener, router, corsPolicy), nil
+	return tequilapi.NewServer(listener, tequilapi.ApplyAudit(router, di.JWTAuthenticator, di.AuditLog), corsPolicy), nil
 }
 
 func (di *Dependencies) bootstrapUIServer(options node.Options) (err error) {
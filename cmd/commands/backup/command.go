@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
+	"github.com/mysteriumnetwork/node/config"
+)
+
+const (
+	// BackupCommandName is the name of the backup command.
+	BackupCommandName = "backup"
+	// RestoreCommandName is the name of the restore command.
+	RestoreCommandName = "restore"
+)
+
+var (
+	flagPassphrase = cli.StringFlag{
+		Name:     "passphrase",
+		Usage:    "Passphrase used to encrypt or decrypt the backup",
+		Required: true,
+	}
+	flagForce = cli.BoolFlag{
+		Name:  "force",
+		Usage: "Restore even if node has identities which are not in the backup",
+	}
+)
+
+// NewBackupCommand creates command which saves encrypted node backup to a file.
+func NewBackupCommand() *cli.Command {
+	return &cli.Command{
+		Name:      BackupCommandName,
+		Usage:     "Saves encrypted backup of identities, database and configuration of a running node",
+		ArgsUsage: "[BackupFile]",
+		Flags:     []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort, &flagPassphrase},
+		Action: func(ctx *cli.Context) error {
+			file := ctx.Args().First()
+			if file == "" {
+				return errors.New("backup file must be provided")
+			}
+
+			client, err := clio.NewTequilApiClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			archive, err := client.CreateBackup(ctx.String(flagPassphrase.Name))
+			if err != nil {
+				return fmt.Errorf("could not create backup: %w", err)
+			}
+			if err := ioutil.WriteFile(file, archive, 0600); err != nil {
+				return fmt.Errorf("could not write backup: %w", err)
+			}
+
+			clio.Success("Backup saved to", file)
+			return nil
+		},
+	}
+}
+
+// NewRestoreCommand creates command which restores node from encrypted backup file.
+func NewRestoreCommand() *cli.Command {
+	return &cli.Command{
+		Name:      RestoreCommandName,
+		Usage:     "Restores running node from encrypted backup",
+		ArgsUsage: "[BackupFile]",
+		Flags:     []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort, &flagPassphrase, &flagForce},
+		Action: func(ctx *cli.Context) error {
+			file := ctx.Args().First()
+			if file == "" {
+				return errors.New("backup file must be provided")
+			}
+			archive, err := ioutil.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("could not read backup: %w", err)
+			}
+
+			client, err := clio.NewTequilApiClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			res, err := client.RestoreBackup(archive, ctx.String(flagPassphrase.Name), ctx.Bool(flagForce.Name))
+			if err != nil {
+				return fmt.Errorf("could not restore backup: %w", err)
+			}
+
+			clio.Success(fmt.Sprintf("Backup of node %s created at %s restored", res.NodeVersion, res.CreatedAt))
+			clio.Info("Identities:", strings.Join(res.Identities, ", "))
+			if res.RestartRequired {
+				clio.Warn("Restart the node to apply restored database and configuration")
+			}
+			return nil
+		},
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"io"
 	stdlog "log"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -385,7 +386,7 @@ func (c *cliApp) serviceGet(id string) {
 func (c *cliApp) connect(argsString string) {
 	args := strings.Fields(argsString)
 
-	helpMsg := "Please type in the provider identity. connect <consumer-identity> <provider-identity> <service-type> [dns=auto|provider|system|1.1.1.1] [disable-kill-switch]"
+	helpMsg := "Please type in the provider identity. connect <consumer-identity> <provider-identity> <service-type> [dns=auto|provider|system|1.1.1.1] [disable-kill-switch] [include-cidrs=10.0.0.0/8,...] [exclude-cidrs=...] [include-domains=example.com,...] [exclude-domains=...] [proxy=127.0.0.1:1080] [forward-tcp=N] [forward-udp=N]"
 	if len(args) < 3 {
 		clio.Info(helpMsg)
 		return
@@ -400,8 +401,43 @@ func (c *cliApp) connect(argsString string) {
 
 	var disableKillSwitch bool
 	var dns connection.DNSOption
+	var splitTunnel contract.SplitTunnelDTO
+	var proxyAddress string
+	var portForwarding contract.PortForwardingDTO
 	var err error
 	for _, arg := range args[3:] {
+		if kv := strings.SplitN(arg, "=", 2); len(kv) == 2 {
+			switch kv[0] {
+			case "include-cidrs":
+				splitTunnel.IncludeCIDRs = strings.Split(kv[1], ",")
+				continue
+			case "exclude-cidrs":
+				splitTunnel.ExcludeCIDRs = strings.Split(kv[1], ",")
+				continue
+			case "include-domains":
+				splitTunnel.IncludeDomains = strings.Split(kv[1], ",")
+				continue
+			case "exclude-domains":
+				splitTunnel.ExcludeDomains = strings.Split(kv[1], ",")
+				continue
+			case "proxy":
+				proxyAddress = kv[1]
+				continue
+			case "forward-tcp", "forward-udp":
+				ports, err := strconv.Atoi(kv[1])
+				if err != nil {
+					clio.Warn("Invalid value: ", err)
+					clio.Info(helpMsg)
+					return
+				}
+				if kv[0] == "forward-tcp" {
+					portForwarding.TCP = ports
+				} else {
+					portForwarding.UDP = ports
+				}
+				continue
+			}
+		}
 		if strings.HasPrefix(arg, "dns=") {
 			kv := strings.Split(arg, "=")
 			dns, err = connection.NewDNSOption(kv[1])
@@ -425,6 +461,9 @@ func (c *cliApp) connect(argsString string) {
 	connectOptions := contract.ConnectOptions{
 		DNS:               dns,
 		DisableKillSwitch: disableKillSwitch,
+		SplitTunnel:       splitTunnel,
+		ProxyAddress:      proxyAddress,
+		PortForwarding:    portForwarding,
 	}
 
 	clio.Status("CONNECTING", "from:", consumerID, "to:", providerID)
@@ -492,6 +531,9 @@ func (c *cliApp) status() {
 	} else {
 		clio.Info("Status:", status.Status)
 		clio.Info("SID:", status.SessionID)
+		for _, port := range status.ForwardedPorts {
+			clio.Info(fmt.Sprintf("Forwarded port: %s %s:%d -> %d", port.Protocol, port.PublicIP, port.PublicPort, port.ConsumerPort))
+		}
 	}
 
 	ip, err := c.tequilapi.ConnectionIP()
@@ -747,6 +789,7 @@ func parseStartFlags(serviceType string, args ...string) (services.StartOptions,
 	config.RegisterFlagsServiceOpenvpn(&flags)
 	config.RegisterFlagsServiceWireguard(&flags)
 	config.RegisterFlagsServiceNoop(&flags)
+	config.RegisterFlagsServiceSOCKS5(&flags)
 
 	set := flag.NewFlagSet("", flag.ContinueOnError)
 	for _, f := range flags {
@@ -761,6 +804,7 @@ func parseStartFlags(serviceType string, args ...string) (services.StartOptions,
 	config.ParseFlagsServiceOpenvpn(ctx)
 	config.ParseFlagsServiceWireguard(ctx)
 	config.ParseFlagsServiceNoop(ctx)
+	config.ParseFlagsServiceSOCKS5(ctx)
 
 	return services.GetStartOptions(serviceType)
 }
@@ -18,13 +18,16 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"sort"
 	"strings"
 
 	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 
 	"github.com/urfave/cli/v2"
 )
@@ -40,21 +43,50 @@ func NewCommand() *cli.Command {
 		Usage:       "Manage your node config",
 		Description: "Using config subcommands you can view and manage your current node config",
 		Flags:       []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort},
-		Before: func(ctx *cli.Context) error {
-			var err error
-			cmd.tc, err = clio.NewTequilApiClient(ctx)
-			return err
-		},
 		Subcommands: []*cli.Command{
 			{
 
-				Name:  "show",
-				Usage: "Show current node config",
+				Name:   "show",
+				Usage:  "Show current node config",
+				Before: cmd.connect,
 				Action: func(ctx *cli.Context) error {
 					cmd.show()
 					return nil
 				},
 			},
+			{
+				Name:      "validate",
+				Usage:     "Validate config file, reporting unknown keys and invalid values",
+				ArgsUsage: "<file>",
+				Action: func(ctx *cli.Context) error {
+					if _, err := cmd.parse(ctx); err != nil {
+						return err
+					}
+					clio.Success(fmt.Sprintf("Config is valid (version %d)", config.SchemaVersion))
+					return nil
+				},
+			},
+			{
+				Name:      "diff",
+				Usage:     "Show effective config values and their sources, or how they would change if the given config file was applied",
+				ArgsUsage: "[file]",
+				Before:    cmd.connect,
+				Action: func(ctx *cli.Context) error {
+					if ctx.Args().Len() == 0 {
+						return cmd.settings()
+					}
+					return cmd.apply(ctx, true)
+				},
+			},
+			{
+				Name:      "apply",
+				Usage:     "Replace user config of a running node with the given config file",
+				ArgsUsage: "<file>",
+				Before:    cmd.connect,
+				Action: func(ctx *cli.Context) error {
+					return cmd.apply(ctx, false)
+				},
+			},
 		},
 	}
 }
@@ -63,6 +95,83 @@ type command struct {
 	tc *client.Client
 }
 
+func (c *command) connect(ctx *cli.Context) (err error) {
+	c.tc, err = clio.NewTequilApiClient(ctx)
+	return err
+}
+
+// parse reads and validates config file given as the first argument.
+func (c *command) parse(ctx *cli.Context) (string, error) {
+	file := ctx.Args().First()
+	if file == "" {
+		return "", errors.New("config file is required")
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	schema, err := config.NodeSchema()
+	if err != nil {
+		return "", err
+	}
+	_, issues := schema.Parse(content)
+	for _, issue := range issues {
+		clio.Error(fmt.Sprintf("%s: %s", file, issue))
+	}
+	if len(issues) > 0 {
+		return "", fmt.Errorf("config file has %d issues", len(issues))
+	}
+	return string(content), nil
+}
+
+func (c *command) settings() error {
+	res, err := c.tc.ConfigSettings()
+	if err != nil {
+		return fmt.Errorf("failed to fetch config: %w", err)
+	}
+	for _, setting := range res.Settings {
+		fmt.Printf("%s: %v (%s)\n", setting.Key, setting.Value, setting.Source)
+	}
+	return nil
+}
+
+func (c *command) apply(ctx *cli.Context, dryRun bool) error {
+	content, err := c.parse(ctx)
+	if err != nil {
+		return err
+	}
+	res, err := c.tc.ApplyConfig(content, dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to apply config: %w", err)
+	}
+
+	for _, change := range res.Changes {
+		fmt.Println(formatChange(change))
+	}
+	if res.Applied {
+		clio.Success(fmt.Sprintf("Config applied, %d settings changed", len(res.Changes)))
+	} else if len(res.Changes) == 0 {
+		clio.Info("No changes")
+	}
+	return nil
+}
+
+func formatChange(change contract.ConfigChangeDTO) string {
+	current := fmt.Sprintf("%v (%s)", change.Current.Value, change.Current.Source)
+	if change.Current.Source == "" {
+		current = "unset"
+	}
+	next := fmt.Sprintf("%v (%s)", change.Next.Value, change.Next.Source)
+	if change.Next.Source == "" {
+		next = "unset"
+	}
+	if change.Next.Source == config.SourceCLI {
+		next += ", overridden by CLI flag"
+	}
+	return fmt.Sprintf("%s: %s -> %s", change.Key, current, next)
+}
+
 func (c *command) show() {
 	config, err := c.tc.FetchConfig()
 	if err != nil {
@@ -51,6 +51,7 @@ func NewCommand(licenseCommandName string) *cli.Command {
 			config.ParseFlagsServiceOpenvpn(ctx)
 			config.ParseFlagsServiceWireguard(ctx)
 			config.ParseFlagsServiceNoop(ctx)
+			config.ParseFlagsServiceSOCKS5(ctx)
 			config.ParseFlagsNode(ctx)
 
 			if err := hasAcceptedTOS(ctx); err != nil {
@@ -86,6 +87,7 @@ func NewCommand(licenseCommandName string) *cli.Command {
 	config.RegisterFlagsServiceOpenvpn(&command.Flags)
 	config.RegisterFlagsServiceWireguard(&command.Flags)
 	config.RegisterFlagsServiceNoop(&command.Flags)
+	config.RegisterFlagsServiceSOCKS5(&command.Flags)
 
 	return command
 }
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package storage
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/cmd/commands/cli/clio"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/config/urfavecli/clicontext"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/storage/sqlite"
+)
+
+const (
+	// CompactCommandName is the name of the compact command.
+	CompactCommandName = "compact"
+	// MigrateCommandName is the name of the storage migration command.
+	MigrateCommandName = "migrate-storage"
+)
+
+// NewCompactCommand creates command which compacts database of a running node.
+func NewCompactCommand() *cli.Command {
+	return &cli.Command{
+		Name:  CompactCommandName,
+		Usage: "Compacts database of a running node, releasing space left by removed history",
+		Flags: []cli.Flag{&config.FlagTequilapiAddress, &config.FlagTequilapiPort},
+		Action: func(ctx *cli.Context) error {
+			client, err := clio.NewTequilApiClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			res, err := client.CompactStorage()
+			if err != nil {
+				return fmt.Errorf("could not compact database: %w", err)
+			}
+
+			clio.Success(fmt.Sprintf("Database compacted from %d to %d bytes", res.SizeBefore, res.SizeAfter))
+			return nil
+		},
+	}
+}
+
+// NewMigrateCommand creates command which copies bolt database into a new sqlite database.
+func NewMigrateCommand() *cli.Command {
+	return &cli.Command{
+		Name:   MigrateCommandName,
+		Usage:  "Copies bolt database into a new sqlite database, node must be stopped",
+		Before: clicontext.LoadUserConfigQuietly,
+		Action: func(ctx *cli.Context) error {
+			config.ParseFlagsNode(ctx)
+
+			dir := node.GetOptions().Directories.Storage
+			if err := sqlite.MigrateFromBolt(dir); err != nil {
+				return fmt.Errorf("could not migrate database: %w", err)
+			}
+
+			clio.Success(fmt.Sprintf("Database migrated, start the node with --%s=%s", config.FlagStorageBackend.Name, config.StorageBackendSQLite))
+			return nil
+		},
+	}
+}
@@ -36,7 +36,10 @@ import (
 	appconfig "github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
 	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/accounting"
+	"github.com/mysteriumnetwork/node/core/audit"
 	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/core/backup"
 	"github.com/mysteriumnetwork/node/core/beneficiary"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
@@ -51,9 +54,12 @@ import (
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/state"
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrator"
+	"github.com/mysteriumnetwork/node/core/storage/retention"
+	"github.com/mysteriumnetwork/node/core/storage/sqlite"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/feedback"
 	"github.com/mysteriumnetwork/node/firewall"
@@ -70,11 +76,13 @@ import (
 	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/nat/traversal"
 	"github.com/mysteriumnetwork/node/nat/upnp"
+	"github.com/mysteriumnetwork/node/obfs"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pilvytis"
 	"github.com/mysteriumnetwork/node/requests"
 	service_noop "github.com/mysteriumnetwork/node/services/noop"
 	service_openvpn "github.com/mysteriumnetwork/node/services/openvpn"
+	"github.com/mysteriumnetwork/node/services/upstream"
 	"github.com/mysteriumnetwork/node/session/connectivity"
 	"github.com/mysteriumnetwork/node/session/pingpong"
 	"github.com/mysteriumnetwork/node/sleep"
@@ -105,29 +113,40 @@ type Dependencies struct {
 	BrokerConnection nats.Connection
 
 	NATService       nat.NATService
-	Storage          *boltdb.Bolt
+	Upstream         upstream.Upstream
+	UpstreamMonitor  *upstream.Monitor
+	PortForwarder    service.PortForwarder
+	Storage          storage.Storage
 	Keystore         *identity.Keystore
 	IdentityManager  identity.Manager
 	SignerFactory    identity.SignerFactory
 	IdentityRegistry identity_registry.IdentityRegistry
 	IdentitySelector identity_selector.Handler
 	IdentityMover    *identity.Mover
+	Backup           *backup.Backup
 
 	DiscoveryFactory   service.DiscoveryFactory
 	ProposalRepository proposal.Repository
+	ProposalHistory    discovery.ProposalHistoryProvider
 	DiscoveryWorker    discovery.Worker
 
 	QualityClient *quality.MysteriumMORQA
 
 	IPResolver       ip.Resolver
 	LocationResolver *location.Cache
+	// UpstreamLocationResolver detects location of the upstream exit, nil if upstream is not configured.
+	UpstreamLocationResolver location.Resolver
 
 	PolicyOracle *policy.Oracle
 
 	SessionStorage                   *consumer_session.Storage
+	TrafficAccountingStorage         *accounting.Storage
 	SessionConnectivityStatusStorage connectivity.StatusStorage
+	// RetentionJob limits session and settlement history, nil if retention is not configured.
+	RetentionJob *retention.Job
 
-	EventBus eventbus.EventBus
+	EventBus     *eventbus.Bus
+	EventHistory *eventbus.History
 
 	ConnectionManager  connection.Manager
 	ConnectionRegistry *connection.Registry
@@ -137,16 +156,19 @@ type Dependencies struct {
 	ServiceSessions *service.SessionPool
 	ServiceFirewall firewall.IncomingTrafficFirewall
 
-	NATPinger  traversal.NATPinger
-	NATTracker *event.Tracker
-	PortPool   *port.Pool
-	PortMapper mapping.PortMapper
+	NATPinger     traversal.NATPinger
+	NATPingConfig *traversal.PingConfig
+	NATTracker    *event.Tracker
+	PortPool      *port.Pool
+	PortMapper    mapping.PortMapper
 
 	StateKeeper *state.Keeper
 
 	P2PDialer   p2p.Dialer
 	P2PListener p2p.Listener
 
+	WebSocketSignalling p2p.SignallingConn
+
 	Authenticator     *auth.Authenticator
 	JWTAuthenticator  *auth.JWTAuthenticator
 	UIServer          UIServer
@@ -155,6 +177,7 @@ type Dependencies struct {
 	ProviderRegistrar *registry.ProviderRegistrar
 
 	LogCollector *logconfig.Collector
+	AuditLog     *audit.Log
 	Reporter     *feedback.Reporter
 
 	BeneficiarySaver    beneficiary.Saver
@@ -203,13 +226,16 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 		return err
 	}
 
-	di.bootstrapEventBus()
+	if err := di.bootstrapEventBus(nodeOptions); err != nil {
+		return err
+	}
 
 	di.bootstrapAddressProvider(nodeOptions)
 
 	if err := di.bootstrapStorage(nodeOptions.Directories.Storage); err != nil {
 		return err
 	}
+	di.AuditLog = audit.NewLog(filepath.Join(nodeOptions.Directories.Storage, audit.FileName))
 
 	netutil.ClearStaleRoutes()
 
@@ -250,7 +276,11 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 		di.PortMapper = mapping.NewNoopPortMapper(di.EventBus)
 	}
 
-	di.bootstrapP2P(nodeOptions.P2PPorts)
+	obfuscation, err := obfs.ParseMethods(nodeOptions.OptionsNetwork.Obfuscation)
+	if err != nil {
+		return fmt.Errorf("invalid p2p obfuscation config: %w", err)
+	}
+	di.bootstrapP2P(nodeOptions.P2PPorts, nodeOptions.OptionsNetwork.SignallingAddresses, obfuscation)
 	di.SessionConnectivityStatusStorage = connectivity.NewStatusStorage()
 
 	if err := di.bootstrapServices(nodeOptions); err != nil {
@@ -301,7 +331,7 @@ func (di *Dependencies) bootstrapAddressProvider(nodeOptions node.Options) {
 	di.AddressProvider = pingpong.NewAddressProvider(keeper, common.HexToAddress(nodeOptions.Transactor.Identity))
 }
 
-func (di *Dependencies) bootstrapP2P(p2pPorts *port.Range) {
+func (di *Dependencies) bootstrapP2P(p2pPorts *port.Range, signallingAddresses []string, obfuscation []obfs.Method) {
 	portPool := di.PortPool
 	natPinger := di.NATPinger
 	identityVerifier := identity.NewVerifierSigned()
@@ -311,8 +341,34 @@ func (di *Dependencies) bootstrapP2P(p2pPorts *port.Range) {
 		natPinger = traversal.NewNoopPinger(di.EventBus)
 	}
 
-	di.P2PListener = p2p.NewListener(di.BrokerConnection, di.SignerFactory, identityVerifier, di.IPResolver, natPinger, portPool, di.PortMapper, di.EventBus)
-	di.P2PDialer = p2p.NewDialer(di.BrokerConnector, di.SignerFactory, identityVerifier, di.IPResolver, natPinger, portPool)
+	wsAuth := p2p.NewWebSocketAuth(di.SignerFactory, di.serviceIdentity)
+	signalling := []p2p.SignallingConn{p2p.NewNATSSignallingConn(di.BrokerConnection)}
+	if len(signallingAddresses) > 0 {
+		wsConn, err := p2p.NewWebSocketSignalling(signallingAddresses, wsAuth)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to set up WebSocket signalling relays, using message broker only")
+		} else {
+			di.WebSocketSignalling = wsConn
+			signalling = append(signalling, wsConn)
+		}
+	}
+	signallingConnectors := []p2p.SignallingConnector{
+		p2p.NewNATSSignallingConnector(di.BrokerConnector),
+		p2p.NewWebSocketSignallingConnector(di.SignerFactory),
+	}
+
+	di.P2PListener = p2p.NewListener(signalling, di.SignerFactory, identityVerifier, di.IPResolver, natPinger, di.NATPingConfig, portPool, di.PortMapper, obfuscation, di.EventBus)
+	di.P2PDialer = p2p.NewDialer(signallingConnectors, di.SignerFactory, identityVerifier, di.IPResolver, natPinger, di.NATPingConfig, portPool, obfuscation)
+}
+
+// serviceIdentity returns provider identity which authenticates node services to WebSocket signalling relays.
+func (di *Dependencies) serviceIdentity() (identity.Identity, error) {
+	if di.ServicesManager != nil {
+		for _, instance := range di.ServicesManager.List() {
+			return instance.ProviderID, nil
+		}
+	}
+	return identity.Identity{}, errors.New("no running service")
 }
 
 func (di *Dependencies) createTequilaListener(nodeOptions node.Options) (net.Listener, error) {
@@ -398,12 +454,26 @@ func (di *Dependencies) Shutdown() (err error) {
 	if di.PolicyOracle != nil {
 		di.PolicyOracle.Stop()
 	}
+	if di.RetentionJob != nil {
+		di.RetentionJob.Stop()
+	}
+	if di.EventHistory != nil {
+		di.EventHistory.Close()
+	}
 
 	if di.NATService != nil {
 		if err := di.NATService.Disable(); err != nil {
 			errs = append(errs, err)
 		}
 	}
+	if di.UpstreamMonitor != nil {
+		di.UpstreamMonitor.Stop()
+	}
+	if di.Upstream != nil {
+		if err := di.Upstream.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if di.DiscoveryWorker != nil {
 		di.DiscoveryWorker.Stop()
 	}
@@ -413,6 +483,9 @@ func (di *Dependencies) Shutdown() (err error) {
 	if di.BrokerConnection != nil {
 		di.BrokerConnection.Close()
 	}
+	if di.WebSocketSignalling != nil {
+		di.WebSocketSignalling.Close()
+	}
 
 	if di.QualityClient != nil {
 		di.QualityClient.Stop()
@@ -433,18 +506,40 @@ func (di *Dependencies) Shutdown() (err error) {
 }
 
 func (di *Dependencies) bootstrapStorage(path string) error {
-	localStorage, err := boltdb.NewStorage(path)
-	if err != nil {
+	if err := backup.ApplyPendingRestore(path); err != nil {
 		return err
 	}
 
-	migrator := migrator.NewMigrator(localStorage)
-	err = migrator.RunMigrations(history.Sequence)
-	if err != nil {
-		return err
-	}
+	var sessionRepository consumer_session.Repository
+	var settlementRepository pingpong.SettlementHistoryRepository
+	switch backend := config.GetString(config.FlagStorageBackend); backend {
+	case config.StorageBackendBolt:
+		localStorage, err := boltdb.NewStorage(path)
+		if err != nil {
+			return err
+		}
 
-	di.Storage = localStorage
+		migrator := migrator.NewMigrator(localStorage)
+		err = migrator.RunMigrations(history.Sequence)
+		if err != nil {
+			return err
+		}
+
+		di.Storage = localStorage
+		sessionRepository = consumer_session.NewBoltRepository(localStorage)
+		settlementRepository = pingpong.NewSettlementHistoryBoltRepository(localStorage)
+	case config.StorageBackendSQLite:
+		localStorage, err := sqlite.NewStorage(path)
+		if err != nil {
+			return err
+		}
+
+		di.Storage = localStorage
+		sessionRepository = sqlite.NewSessionRepository(localStorage)
+		settlementRepository = sqlite.NewSettlementHistoryRepository(localStorage)
+	default:
+		return fmt.Errorf("unknown storage backend %q", backend)
+	}
 
 	if !config.GetBool(config.FlagUserMode) {
 		netutil.SetRouteManagerStorage(di.Storage)
@@ -454,8 +549,21 @@ func (di *Dependencies) bootstrapStorage(path string) error {
 	di.ProviderInvoiceStorage = pingpong.NewProviderInvoiceStorage(invoiceStorage)
 	di.ConsumerTotalsStorage = pingpong.NewConsumerTotalsStorage(di.Storage, di.EventBus)
 	di.HermesPromiseStorage = pingpong.NewHermesPromiseStorage(di.Storage)
-	di.SessionStorage = consumer_session.NewSessionStorage(di.Storage)
-	di.SettlementHistoryStorage = pingpong.NewSettlementHistoryStorage(di.Storage)
+	di.SessionStorage = consumer_session.NewSessionStorage(sessionRepository)
+	di.SettlementHistoryStorage = pingpong.NewSettlementHistoryStorage(settlementRepository)
+	di.TrafficAccountingStorage = accounting.NewStorage(di.Storage, accounting.Retention{
+		MaxAge:      config.GetDuration(config.FlagAccountingRetention),
+		MaxSessions: config.GetInt(config.FlagAccountingMaxSessions),
+	})
+
+	retentionPolicy := retention.Policy{
+		MaxAge:  config.GetDuration(config.FlagStorageRetention),
+		MaxRows: config.GetInt(config.FlagStorageMaxSessions),
+	}
+	if retentionPolicy.Enabled() {
+		di.RetentionJob = retention.NewJob(retentionPolicy, di.SessionStorage, di.SettlementHistoryStorage, invoiceStorage)
+		di.RetentionJob.Start(config.GetDuration(config.FlagStorageRetentionInterval))
+	}
 	return di.SessionStorage.Subscribe(di.EventBus)
 }
 
@@ -545,10 +653,15 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 			di.ConsumerBalanceTracker,
 			di.IdentityManager,
 		),
+		di.IdentityManager,
 		di.P2PDialer,
 	)
 
 	di.LogCollector = logconfig.NewCollector(&logconfig.CurrentLogOptions)
+	di.LogCollector.AddDumper(di.AuditLog)
+	if di.EventHistory != nil {
+		di.LogCollector.AddDumper(di.EventHistory)
+	}
 	reporter, err := feedback.NewReporter(di.LogCollector, di.IdentityManager, nodeOptions.FeedbackURL)
 	if err != nil {
 		return err
@@ -678,8 +791,23 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 	return di.IdentityRegistry.Subscribe(di.EventBus)
 }
 
-func (di *Dependencies) bootstrapEventBus() {
+func (di *Dependencies) bootstrapEventBus(options node.Options) error {
 	di.EventBus = eventbus.New()
+	if err := di.EventBus.Define(eventTopics...); err != nil {
+		return err
+	}
+
+	size := config.GetInt(config.FlagEventHistorySize)
+	if size == 0 {
+		return nil
+	}
+	history, err := eventbus.NewHistory(size, filepath.Join(options.Directories.Storage, eventbus.HistoryFileName), config.GetBool(config.FlagEventHistoryPersist))
+	if err != nil {
+		return err
+	}
+	di.EventHistory = history
+	di.EventBus.RecordHistory(history)
+	return nil
 }
 
 func (di *Dependencies) bootstrapIdentityComponents(options node.Options) error {
@@ -693,14 +821,18 @@ func (di *Dependencies) bootstrapIdentityComponents(options node.Options) error
 	}
 
 	di.Keystore = identity.NewKeystoreFilesystem(options.Directories.Keystore, ks)
+	var externalSigner *identity.ExternalSigner
+	if options.Keystore.ExternalSigner != "" && len(options.Keystore.ExternalSignerIdentities) > 0 {
+		log.Info().Msgf("Using external signer %s for identities: %v", options.Keystore.ExternalSigner, options.Keystore.ExternalSignerIdentities)
+		externalSigner = identity.NewExternalSigner(options.Keystore.ExternalSigner, options.Keystore.ExternalSignerIdentities)
+		di.Keystore.UseExternalSigner(externalSigner)
+	}
 	if di.ResidentCountry == nil {
 		return errMissingDependency("di.residentCountry")
 	}
 	di.IdentityManager = identity.NewIdentityManager(di.Keystore, di.EventBus, di.ResidentCountry)
 
-	di.SignerFactory = func(id identity.Identity) identity.Signer {
-		return identity.NewSigner(di.Keystore, id)
-	}
+	di.SignerFactory = identity.NewSignerFactory(di.Keystore, externalSigner)
 	di.IdentitySelector = identity_selector.NewHandler(
 		di.IdentityManager,
 		di.MysteriumAPI,
@@ -711,6 +843,16 @@ func (di *Dependencies) bootstrapIdentityComponents(options node.Options) error
 		di.Keystore,
 		di.EventBus,
 		di.SignerFactory)
+	backupOptions := backup.Options{
+		KeystoreDir: options.Directories.Keystore,
+		StorageDir:  options.Directories.Storage,
+		DataDir:     options.Directories.Data,
+		ConfigFile:  config.Current.UserConfigLocation(),
+	}
+	if config.GetString(config.FlagStorageBackend) == config.StorageBackendSQLite {
+		backupOptions.DatabaseFile = sqlite.DatabaseFile
+	}
+	di.Backup = backup.NewBackup(backupOptions, di.Storage, di.IdentityManager)
 	return nil
 }
 
@@ -761,6 +903,21 @@ func (di *Dependencies) bootstrapQualityComponents(options node.OptionsQuality)
 	return nil
 }
 
+func newLocationResolver(options node.Options, httpClient *requests.HTTPClient, ipResolver ip.Resolver) (location.Resolver, error) {
+	switch options.Location.Type {
+	case node.LocationTypeManual:
+		return location.NewStaticResolver(options.Location.Country, options.Location.City, options.Location.NodeType, ipResolver), nil
+	case node.LocationTypeBuiltin:
+		return location.NewBuiltInResolver(ipResolver)
+	case node.LocationTypeMMDB:
+		return location.NewExternalDBResolver(filepath.Join(options.Directories.Script, options.Location.Address), ipResolver)
+	case node.LocationTypeOracle:
+		return location.NewOracleResolver(httpClient, options.Location.Address), nil
+	default:
+		return nil, errors.Errorf("unknown location provider: %s", options.Location.Type)
+	}
+}
+
 func (di *Dependencies) bootstrapLocationComponents(options node.Options) (err error) {
 	if _, err = firewall.AllowURLAccess(options.Location.IPDetectorURL); err != nil {
 		return errors.Wrap(err, "failed to add firewall exception")
@@ -771,37 +928,27 @@ func (di *Dependencies) bootstrapLocationComponents(options node.Options) (err e
 	ipResolver := ip.NewResolver(di.HTTPClient, options.BindAddress, options.Location.IPDetectorURL, ip.IPFallbackAddresses)
 	di.IPResolver = ip.NewCachedResolver(ipResolver, 5*time.Minute)
 
-	var resolver location.Resolver
-	switch options.Location.Type {
-	case node.LocationTypeManual:
-		resolver = location.NewStaticResolver(options.Location.Country, options.Location.City, options.Location.NodeType, di.IPResolver)
-	case node.LocationTypeBuiltin:
-		resolver, err = location.NewBuiltInResolver(di.IPResolver)
-	case node.LocationTypeMMDB:
-		resolver, err = location.NewExternalDBResolver(filepath.Join(options.Directories.Script, options.Location.Address), di.IPResolver)
-	case node.LocationTypeOracle:
+	if options.Location.Type == node.LocationTypeOracle {
 		if _, err := firewall.AllowURLAccess(options.Location.Address); err != nil {
 			return err
 		}
 		if _, err := di.ServiceFirewall.AllowURLAccess(options.Location.Address); err != nil {
 			return err
 		}
-		resolver, err = location.NewOracleResolver(di.HTTPClient, options.Location.Address), nil
-	default:
-		err = errors.Errorf("unknown location provider: %s", options.Location.Type)
 	}
+	resolver, err := newLocationResolver(options, di.HTTPClient, di.IPResolver)
 	if err != nil {
 		return err
 	}
 
 	di.LocationResolver = location.NewCache(resolver, di.EventBus, time.Minute*5)
 
-	err = di.EventBus.SubscribeAsync(connectionstate.AppTopicConnectionState, di.LocationResolver.HandleConnectionEvent)
+	err = connectionstate.SubscribeConnectionState(di.EventBus, eventbus.DeliverAsync, di.LocationResolver.HandleConnectionEvent)
 	if err != nil {
 		return err
 	}
 
-	err = di.EventBus.SubscribeAsync(nodevent.AppTopicNode, di.LocationResolver.HandleNodeEvent)
+	err = nodevent.SubscribeNode(di.EventBus, eventbus.DeliverAsync, di.LocationResolver.HandleNodeEvent)
 	if err != nil {
 		return err
 	}
@@ -856,10 +1003,16 @@ func (di *Dependencies) bootstrapNATComponents(options node.Options) error {
 		return err
 	}
 
+	pingConfig, err := options.Traversal.PingConfig()
+	if err != nil {
+		return fmt.Errorf("invalid NAT traversal config: %w", err)
+	}
+	di.NATPingConfig = pingConfig
+
 	if options.ExperimentNATPunching {
-		log.Debug().Msg("Experimental NAT punching enabled, creating a pinger")
+		log.Debug().Msgf("Experimental NAT punching enabled, creating a pinger with %q strategy", pingConfig.Strategy)
 		di.NATPinger = traversal.NewPinger(
-			traversal.DefaultPingConfig(),
+			pingConfig,
 			di.EventBus,
 		)
 	} else {
@@ -918,7 +1071,7 @@ func (di *Dependencies) handleConnStateChange() error {
 	}
 
 	latestState := connectionstate.NotConnected
-	return di.EventBus.SubscribeAsync(connectionstate.AppTopicConnectionState, func(e connectionstate.AppEventConnectionState) {
+	return connectionstate.SubscribeConnectionState(di.EventBus, eventbus.DeliverAsync, func(e connectionstate.AppEventConnectionState) {
 		// Here we care only about connected and disconnected events.
 		if e.State != connectionstate.Connected && e.State != connectionstate.NotConnected {
 			return
@@ -943,7 +1096,7 @@ func (di *Dependencies) handleConnStateChange() error {
 					log.Error().Err(err).Msg("Ethereum client failed to reconnect")
 				}
 			}
-			di.EventBus.Publish(registry.AppTopicEthereumClientReconnected, struct{}{})
+			registry.PublishEthereumClientReconnected(di.EventBus)
 		}
 		latestState = e.State
 	})
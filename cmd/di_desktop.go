@@ -18,24 +18,37 @@
 package cmd
 
 import (
+	"strings"
 	"time"
 
+	miekgdns "github.com/miekg/dns"
+
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/accounting"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/core/portforward"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/dns"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mmn"
 	"github.com/mysteriumnetwork/node/nat"
 	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/requests"
 	service_noop "github.com/mysteriumnetwork/node/services/noop"
 	service_openvpn "github.com/mysteriumnetwork/node/services/openvpn"
 	openvpn_discovery "github.com/mysteriumnetwork/node/services/openvpn/discovery"
 	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn/service"
+	service_socks5 "github.com/mysteriumnetwork/node/services/socks5"
+	"github.com/mysteriumnetwork/node/services/upstream"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	wireguard_connection "github.com/mysteriumnetwork/node/services/wireguard/connection"
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint"
@@ -62,6 +75,7 @@ func (di *Dependencies) bootstrapServices(nodeOptions node.Options) error {
 
 	di.bootstrapServiceOpenvpn(nodeOptions)
 	di.bootstrapServiceNoop(nodeOptions)
+	di.bootstrapServiceSOCKS5(nodeOptions)
 	di.bootstrapServiceWireguard(nodeOptions)
 
 	return nil
@@ -75,16 +89,19 @@ func (di *Dependencies) bootstrapServiceWireguard(nodeOptions node.Options) {
 			if err != nil {
 				return nil, market.ServiceProposal{}, err
 			}
+			originate, err := di.detectOriginateLocation(loc)
+			if err != nil {
+				return nil, market.ServiceProposal{}, err
+			}
 
 			wgOptions := serviceOptions.(wireguard_service.Options)
 
 			// TODO: Use global port pool once migrated to p2p.
-			var portPool port.ServicePortSupplier
-			if wgOptions.Ports.IsSpecified() {
-				log.Info().Msgf("Fixed service port range (%s) configured, using custom port pool", wgOptions.Ports)
-				portPool = port.NewFixedRangePool(*wgOptions.Ports)
-			} else {
-				portPool = port.NewPool()
+			portPool := wireguard_service.NewPortPool(wgOptions.Ports)
+
+			var accountingStorage *accounting.Storage
+			if config.GetBool(config.FlagAccountingEnabled) {
+				accountingStorage = di.TrafficAccountingStorage
 			}
 
 			svc := wireguard_service.NewManager(
@@ -96,8 +113,10 @@ func (di *Dependencies) bootstrapServiceWireguard(nodeOptions node.Options) {
 				wgOptions,
 				portPool,
 				di.ServiceFirewall,
+				accountingStorage,
+				di.providerDNSHandlerFactory(),
 			)
-			return svc, wireguard_service.GetProposal(loc), nil
+			return svc, wireguard_service.GetProposalWithOriginate(loc, originate, di.PortForwarder != nil), nil
 		},
 	)
 }
@@ -113,8 +132,17 @@ func (di *Dependencies) bootstrapServiceOpenvpn(nodeOptions node.Options) {
 			return nil, market.ServiceProposal{}, err
 		}
 
+		originate, err := di.detectOriginateLocation(loc)
+		if err != nil {
+			return nil, market.ServiceProposal{}, err
+		}
+
 		transportOptions := serviceOptions.(openvpn_service.Options)
-		proposal := openvpn_discovery.NewServiceProposalWithLocation(loc, transportOptions.Protocol)
+		proposal := openvpn_discovery.NewServiceProposalWithLocations(loc, originate, openvpn_discovery.Transport{
+			Protocol: transportOptions.Protocol,
+			Port:     transportOptions.Port,
+			TLSMode:  service_openvpn.TLSMode(transportOptions.TLSCrypt),
+		})
 
 		// TODO: Use global port pool once migrated to p2p.
 		var portPool port.ServicePortSupplier
@@ -132,9 +160,11 @@ func (di *Dependencies) bootstrapServiceOpenvpn(nodeOptions node.Options) {
 			di.ServiceSessions,
 			di.NATService,
 			di.NATTracker,
+			di.PortMapper,
 			portPool,
 			di.EventBus,
 			di.ServiceFirewall,
+			di.providerDNSHandlerFactory(),
 		)
 		return manager, proposal, nil
 	}
@@ -155,6 +185,150 @@ func (di *Dependencies) bootstrapServiceNoop(nodeOptions node.Options) {
 	)
 }
 
+func (di *Dependencies) bootstrapServiceSOCKS5(nodeOptions node.Options) {
+	di.ServiceRegistry.Register(
+		service_socks5.ServiceType,
+		func(serviceOptions service.Options) (service.Service, market.ServiceProposal, error) {
+			loc, err := di.LocationResolver.DetectLocation()
+			if err != nil {
+				return nil, market.ServiceProposal{}, err
+			}
+
+			var dialContext service_socks5.DialContext
+			if di.Upstream != nil {
+				dialContext = di.Upstream.DialContext
+			}
+			return service_socks5.NewManager(di.EventBus, nat.ProtectedNetworks(), dialContext), service_socks5.GetProposal(loc), nil
+		},
+	)
+}
+
+// bootstrapUpstream chains provider exit through the configured upstream tunnel.
+func (di *Dependencies) bootstrapUpstream(nodeOptions node.Options) error {
+	options := upstream.Options{
+		WireGuardConfig: config.GetString(config.FlagUpstreamWireGuardConfig),
+		SOCKS5Address:   config.GetString(config.FlagUpstreamSOCKS5Address),
+		DNS:             upstreamDNSServers(),
+	}
+	if !options.IsEnabled() {
+		return nil
+	}
+
+	up, err := upstream.New(options)
+	if err != nil {
+		return errors.Wrap(err, "could not create upstream")
+	}
+	di.Upstream = up
+
+	di.NATService = upstream.NewNATService(di.NATService, up)
+
+	httpClient := requests.NewHTTPClientWithTransport(requests.NewTransport(up.DialContext), requests.DefaultTimeout)
+	ipResolver := ip.NewResolver(httpClient, "", nodeOptions.Location.IPDetectorURL, ip.IPFallbackAddresses)
+	di.UpstreamLocationResolver, err = newLocationResolver(nodeOptions, httpClient, ipResolver)
+	if err != nil {
+		return err
+	}
+
+	checkUpstream := func() error {
+		_, err := ipResolver.GetPublicIP()
+		return err
+	}
+	var stopped []stoppedService
+	di.UpstreamMonitor = upstream.NewMonitor(
+		checkUpstream,
+		func(err error) { stopped = di.stopServicesOnUpstreamDown(err) },
+		func() {
+			di.startServicesOnUpstreamUp(stopped)
+			stopped = nil
+		},
+		config.GetDuration(config.FlagUpstreamCheckInterval),
+		3,
+	)
+	di.UpstreamMonitor.Start()
+
+	log.Info().Msgf("Consumer traffic is forwarded through upstream interface %s", up.InterfaceName())
+	return nil
+}
+
+// stoppedService holds what is needed to start the service again.
+type stoppedService struct {
+	providerID  identity.Identity
+	serviceType string
+	policyIDs   []string
+	options     service.Options
+	pm          market.PaymentMethod
+}
+
+func (di *Dependencies) stopServicesOnUpstreamDown(err error) (stopped []stoppedService) {
+	log.Error().Err(err).Msg("Upstream is down, stopping services")
+	for id, instance := range di.ServicesManager.List() {
+		proposal := instance.Proposal()
+		var policyIDs []string
+		if proposal.AccessPolicies != nil {
+			for _, policy := range *proposal.AccessPolicies {
+				policyIDs = append(policyIDs, policy.ID)
+			}
+		}
+
+		if err := di.ServicesManager.Stop(id); err != nil {
+			log.Error().Err(err).Msgf("Could not stop service %s", id)
+			continue
+		}
+		stopped = append(stopped, stoppedService{
+			providerID:  instance.ProviderID,
+			serviceType: instance.Type,
+			policyIDs:   policyIDs,
+			options:     instance.Options(),
+			pm:          proposal.PaymentMethod,
+		})
+	}
+	return stopped
+}
+
+func (di *Dependencies) startServicesOnUpstreamUp(stopped []stoppedService) {
+	log.Info().Msgf("Upstream recovered, starting %d stopped services", len(stopped))
+	for _, s := range stopped {
+		if _, err := di.ServicesManager.Start(s.providerID, s.serviceType, s.policyIDs, s.options, s.pm); err != nil {
+			log.Error().Err(err).Msgf("Could not start %s service", s.serviceType)
+		}
+	}
+}
+
+// providerDNSHandlerFactory resolves consumer DNS queries through the upstream when it is configured,
+// so they don't reveal provider's own address.
+func (di *Dependencies) providerDNSHandlerFactory() dns.HandlerFactory {
+	if di.Upstream == nil {
+		return dns.ResolveViaSystem
+	}
+	handler := dns.ResolveVia(di.Upstream.DialContext, upstreamDNSServers())
+	return func() (miekgdns.Handler, error) {
+		return handler, nil
+	}
+}
+
+func upstreamDNSServers() []string {
+	var servers []string
+	for _, server := range strings.Split(config.GetString(config.FlagUpstreamDNS), ",") {
+		if server = strings.TrimSpace(server); server != "" {
+			servers = append(servers, server)
+		}
+	}
+	return servers
+}
+
+// detectOriginateLocation returns location consumer traffic exits from.
+func (di *Dependencies) detectOriginateLocation(loc locationstate.Location) (locationstate.Location, error) {
+	if di.UpstreamLocationResolver == nil {
+		return loc, nil
+	}
+
+	originate, err := di.UpstreamLocationResolver.DetectLocation()
+	if err != nil {
+		return locationstate.Location{}, errors.Wrap(err, "could not detect upstream location")
+	}
+	return originate, nil
+}
+
 func (di *Dependencies) bootstrapProviderRegistrar(nodeOptions node.Options) error {
 	if nodeOptions.Consumer {
 		log.Debug().Msg("Skipping provider registrar for consumer mode")
@@ -220,6 +394,26 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 	if err := di.NATService.Enable(); err != nil {
 		log.Warn().Err(err).Msg("Failed to enable NAT forwarding")
 	}
+	if err := di.bootstrapUpstream(nodeOptions); err != nil {
+		return err
+	}
+	if config.GetBool(config.FlagPortForwardingEnabled) {
+		// Forwarded ports come from a separate range, so they never collide with service listen ports.
+		ports, err := port.ParseRange(config.GetString(config.FlagPortForwardingPorts))
+		if err != nil {
+			return errors.Wrap(err, "invalid port forwarding ports")
+		}
+		if !ports.IsSpecified() {
+			return errors.New("port forwarding ports range must be specified")
+		}
+		di.PortForwarder = portforward.NewForwarder(
+			di.NATService,
+			port.NewFixedRangePool(*ports),
+			di.IPResolver.GetPublicIP,
+			di.IPResolver.GetOutboundIP,
+			config.GetInt(config.FlagPortForwardingMaxPorts),
+		)
+	}
 	di.ServiceRegistry = service.NewRegistry()
 
 	di.ServiceSessions = service.NewSessionPool(di.EventBus)
@@ -242,7 +436,6 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 			nodeOptions.Payments.MaxUnpaidInvoiceValue,
 			di.HermesStatusChecker,
 			di.EventBus,
-			serviceInstance.Proposal,
 			di.HermesPromiseHandler,
 			di.AddressProvider,
 		)
@@ -253,6 +446,7 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 			di.NATTracker,
 			di.EventBus,
 			channel,
+			di.PortForwarder,
 			service.DefaultConfig(),
 		)
 	}
@@ -262,13 +456,15 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 		di.DiscoveryFactory,
 		di.EventBus,
 		di.PolicyOracle,
+		di.IdentityManager,
 		di.P2PListener,
 		newP2PSessionHandler,
 		di.SessionConnectivityStatusStorage,
+		service.NewProposalVersions(di.Storage),
 	)
 
 	serviceCleaner := service.Cleaner{SessionStorage: di.ServiceSessions}
-	if err := di.EventBus.Subscribe(servicestate.AppTopicServiceStatus, serviceCleaner.HandleServiceStatus); err != nil {
+	if err := servicestate.SubscribeServiceStatus(di.EventBus, eventbus.DeliverSync, serviceCleaner.HandleServiceStatus); err != nil {
 		log.Error().Err(err).Msg("Failed to subscribe service cleaner")
 	}
 
@@ -278,6 +474,7 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 func (di *Dependencies) registerConnections(nodeOptions node.Options) {
 	di.registerOpenvpnConnection(nodeOptions)
 	di.registerNoopConnection()
+	di.registerSOCKS5Connection()
 	di.registerWireguardConnection(nodeOptions)
 }
 
@@ -285,19 +482,28 @@ func (di *Dependencies) registerWireguardConnection(nodeOptions node.Options) {
 	wireguard.Bootstrap()
 	handshakeWaiter := wireguard_connection.NewHandshakeWaiter()
 	endpointFactory := func() (wireguard.ConnectionEndpoint, error) {
-		resourceAllocator := resources.NewAllocator(nil, wireguard_service.DefaultOptions.Subnet)
+		resourceAllocator := resources.NewAllocator(nil, wireguard_service.DefaultOptions.Subnet, nil)
 		return endpoint.NewConnectionEndpoint(resourceAllocator)
 	}
 	connFactory := func() (connection.Connection, error) {
 		opts := wireguard_connection.Options{
-			DNSScriptDir:     nodeOptions.Directories.Script,
-			HandshakeTimeout: 1 * time.Minute,
+			DNSScriptDir:         nodeOptions.Directories.Script,
+			HandshakeTimeout:     1 * time.Minute,
+			UserspaceFallbackDNS: strings.Split(config.GetString(config.FlagWireguardUserspaceDNS), ","),
 		}
 		return wireguard_connection.NewConnection(opts, di.IPResolver, endpointFactory, handshakeWaiter)
 	}
 	di.ConnectionRegistry.Register(wireguard.ServiceType, connFactory)
 }
 
+func (di *Dependencies) registerSOCKS5Connection() {
+	service_socks5.Bootstrap()
+	connFactory := func() (connection.Connection, error) {
+		return service_socks5.NewConnection(config.GetString(config.FlagSOCKS5ConsumerAddress))
+	}
+	di.ConnectionRegistry.Register(service_socks5.ServiceType, connFactory)
+}
+
 func (di *Dependencies) bootstrapMMN() error {
 	client := mmn.NewClient(di.HTTPClient, config.GetString(config.FlagMMNAPIAddress), di.SignerFactory)
 
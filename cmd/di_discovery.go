@@ -43,6 +43,7 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 
 		case node.DiscoveryTypeBroker:
 			storage := brokerdiscovery.NewStorage(di.EventBus)
+			di.ProposalHistory = storage
 			brokerRepository := brokerdiscovery.NewRepository(di.BrokerConnection, storage, options.PingInterval+time.Second, 1*time.Second)
 			if options.FetchEnabled {
 				discoveryWorker.AddWorker(brokerRepository)
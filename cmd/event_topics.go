@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/discovery"
+	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	nodevent "github.com/mysteriumnetwork/node/core/node/event"
+	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/market"
+	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/pilvytis"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/sleep"
+	"github.com/mysteriumnetwork/node/trace"
+)
+
+// eventTopics defines the event type of every topic published on the node event bus.
+// Periodic topics where only the latest event matters drop the oldest queued events instead of blocking publishers
+// and, like proposal updates, are left out of event history.
+// Topics carrying settings or personal data are masked in event history added to feedback archives.
+var eventTopics = []eventbus.Topic{
+	{Name: nodevent.AppTopicNode, Event: nodevent.Payload{}},
+	{Name: config.AppTopicConfig("*"), RedactHistory: true},
+	{Name: sleep.AppTopicSleepNotification, Event: sleep.EventWakeup},
+	{Name: trace.AppTopicTraceEvent, Event: trace.Event{}},
+	{Name: stateEvent.AppTopicState, Event: stateEvent.State{}, Overflow: eventbus.OverflowDropOldest, SkipHistory: true},
+	{Name: location.LocUpdateEvent, Event: locationstate.Location{}},
+
+	{Name: identity.AppTopicIdentityCreated, Event: ""},
+	{Name: identity.AppTopicIdentityDeleted, Event: identity.AppEventIdentityDeleted{}},
+	{Name: identity.AppTopicIdentityUnlock, Event: identity.AppEventIdentityUnlock{}},
+	{Name: identity.AppTopicResidentCountry, Event: identity.ResidentCountryEvent{}, RedactHistory: true},
+	{Name: registry.AppTopicIdentityRegistration, Event: registry.AppEventIdentityRegistration{}},
+	{Name: registry.AppTopicTransactorRegistration, Event: registry.IdentityRegistrationRequest{}, RedactHistory: true},
+	{Name: registry.AppTopicEthereumClientReconnected, Event: struct{}{}},
+
+	{Name: discovery.AppTopicProposalAdded, Event: market.ServiceProposal{}, SkipHistory: true},
+	{Name: discovery.AppTopicProposalUpdated, Event: discovery.ProposalUpdatedEvent{}, SkipHistory: true},
+	{Name: discovery.AppTopicProposalRemoved, Event: market.ServiceProposal{}, SkipHistory: true},
+	{Name: discovery.AppTopicProposalAnnounce, Event: market.ServiceProposal{}, SkipHistory: true},
+	{Name: servicestate.AppTopicServiceStatus, Event: servicestate.AppEventServiceStatus{}},
+	{Name: natEvent.AppTopicTraversal, Event: natEvent.Event{}},
+
+	{Name: connectionstate.AppTopicConnectionState, Event: connectionstate.AppEventConnectionState{}},
+	{Name: connectionstate.AppTopicConnectionStatistics, Event: connectionstate.AppEventConnectionStatistics{}, Overflow: eventbus.OverflowDropOldest, SkipHistory: true},
+	{Name: connectionstate.AppTopicConnectionSession, Event: connectionstate.AppEventConnectionSession{}},
+	{Name: bandwidth.AppTopicConnectionThroughput, Event: bandwidth.AppEventConnectionThroughput{}, Overflow: eventbus.OverflowDropOldest, SkipHistory: true},
+	{Name: quality.AppTopicConnectionEvents, Event: quality.ConnectionEvent{}},
+	{Name: quality.AppTopicConsumerPingP2P, Event: quality.PingEvent{}},
+	{Name: quality.AppTopicProviderPingP2P, Event: quality.PingEvent{}},
+
+	{Name: sessionEvent.AppTopicSession, Event: sessionEvent.AppEventSession{}},
+	{Name: sessionEvent.AppTopicDataTransferred, Event: sessionEvent.AppEventDataTransferred{}, SkipHistory: true},
+	{Name: sessionEvent.AppTopicTokensEarned, Event: sessionEvent.AppEventTokensEarned{}},
+	{Name: pingpongEvent.AppTopicHermesPromise, Event: pingpongEvent.AppEventHermesPromise{}},
+	{Name: pingpongEvent.AppTopicBalanceChanged, Event: pingpongEvent.AppEventBalanceChanged{}},
+	{Name: pingpongEvent.AppTopicEarningsChanged, Event: pingpongEvent.AppEventEarningsChanged{}},
+	{Name: pingpongEvent.AppTopicInvoicePaid, Event: pingpongEvent.AppEventInvoicePaid{}},
+	{Name: pingpongEvent.AppTopicSettlementRequest, Event: pingpongEvent.AppEventSettlementRequest{}},
+	{Name: pingpongEvent.AppTopicGrandTotalChanged, Event: pingpongEvent.AppEventGrandTotalChanged{}},
+	{Name: pilvytis.AppTopicOrderUpdated, Event: pilvytis.AppEventOrderUpdated{}},
+}
@@ -22,6 +22,7 @@ import (
 	"sync"
 
 	"github.com/mysteriumnetwork/node/cmd/commands/account"
+	"github.com/mysteriumnetwork/node/cmd/commands/backup"
 	command_cli "github.com/mysteriumnetwork/node/cmd/commands/cli"
 	command_cfg "github.com/mysteriumnetwork/node/cmd/commands/config"
 	"github.com/mysteriumnetwork/node/cmd/commands/connection"
@@ -29,6 +30,7 @@ import (
 	"github.com/mysteriumnetwork/node/cmd/commands/license"
 	"github.com/mysteriumnetwork/node/cmd/commands/reset"
 	"github.com/mysteriumnetwork/node/cmd/commands/service"
+	"github.com/mysteriumnetwork/node/cmd/commands/storage"
 	"github.com/mysteriumnetwork/node/cmd/commands/version"
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/logconfig"
@@ -53,6 +55,10 @@ var (
 	accountCommand    = account.NewCommand()
 	connectionCommand = connection.NewCommand()
 	configCommand     = command_cfg.NewCommand()
+	backupCommand     = backup.NewBackupCommand()
+	restoreCommand    = backup.NewRestoreCommand()
+	compactCommand    = storage.NewCompactCommand()
+	migrateCommand    = storage.NewMigrateCommand()
 )
 
 func main() {
@@ -99,6 +105,10 @@ func NewCommand() (*cli.App, error) {
 		accountCommand,
 		connectionCommand,
 		configCommand,
+		backupCommand,
+		restoreCommand,
+		compactCommand,
+		migrateCommand,
 	}
 
 	return app, nil
@@ -112,11 +122,15 @@ func newApp() (*cli.App, error) {
 // uiCommands is a map which consists of all
 // commands are used directly by a user.
 var uiCommands = map[string]struct{}{
-	command_cli.CommandName: {},
-	account.CommandName:     {},
-	connection.CommandName:  {},
-	command_cfg.CommandName: {},
-	reset.CommandName:       {},
+	command_cli.CommandName:    {},
+	account.CommandName:        {},
+	connection.CommandName:     {},
+	command_cfg.CommandName:    {},
+	reset.CommandName:          {},
+	backup.BackupCommandName:   {},
+	backup.RestoreCommandName:  {},
+	storage.CompactCommandName: {},
+	storage.MigrateCommandName: {},
 }
 
 // configureLogging returns a func which configures global
@@ -22,6 +22,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/node/event"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/tequilapi"
 )
 
@@ -30,11 +31,6 @@ type NATPinger interface {
 	Stop()
 }
 
-// Publisher is responsible for publishing given events
-type Publisher interface {
-	Publish(topic string, data interface{})
-}
-
 // SleepNotifier notifies node about pending sleep events
 type SleepNotifier interface {
 	Start()
@@ -42,7 +38,7 @@ type SleepNotifier interface {
 }
 
 // NewNode function creates new Mysterium node by given options
-func NewNode(connectionManager connection.Manager, tequilapiServer tequilapi.APIServer, publisher Publisher, natPinger NATPinger, uiServer UIServer, notifier SleepNotifier) *Node {
+func NewNode(connectionManager connection.Manager, tequilapiServer tequilapi.APIServer, publisher eventbus.Publisher, natPinger NATPinger, uiServer UIServer, notifier SleepNotifier) *Node {
 	return &Node{
 		connectionManager: connectionManager,
 		httpAPIServer:     tequilapiServer,
@@ -57,7 +53,7 @@ func NewNode(connectionManager connection.Manager, tequilapiServer tequilapi.API
 type Node struct {
 	connectionManager connection.Manager
 	httpAPIServer     tequilapi.APIServer
-	publisher         Publisher
+	publisher         eventbus.Publisher
 	natPinger         NATPinger
 	uiServer          UIServer
 	sleepNotifier     SleepNotifier
@@ -69,14 +65,14 @@ func (node *Node) Start() error {
 	node.httpAPIServer.StartServing()
 
 	node.uiServer.Serve()
-	node.publisher.Publish(event.AppTopicNode, event.Payload{Status: event.StatusStarted})
+	event.PublishNode(node.publisher, event.Payload{Status: event.StatusStarted})
 
 	return nil
 }
 
 // Wait blocks until Mysterium node is stopped
 func (node *Node) Wait() error {
-	defer node.publisher.Publish(event.AppTopicNode, event.Payload{Status: event.StatusStopped})
+	defer event.PublishNode(node.publisher, event.Payload{Status: event.StatusStopped})
 	return node.httpAPIServer.Wait()
 }
 
@@ -42,6 +42,7 @@ import (
 type Config struct {
 	userConfigLocation string
 	defaults           map[string]interface{}
+	remote             map[string]interface{}
 	user               map[string]interface{}
 	cli                map[string]interface{}
 	eventBus           eventbus.EventBus
@@ -55,6 +56,7 @@ func NewConfig() *Config {
 	return &Config{
 		userConfigLocation: "",
 		defaults:           make(map[string]interface{}),
+		remote:             make(map[string]interface{}),
 		user:               make(map[string]interface{}),
 		cli:                make(map[string]interface{}),
 	}
@@ -85,6 +87,11 @@ func (cfg *Config) LoadUserConfig(location string) error {
 	return nil
 }
 
+// UserConfigLocation returns location of the user configuration file, empty if it was not loaded.
+func (cfg *Config) UserConfigLocation() string {
+	return cfg.userConfigLocation
+}
+
 // SaveUserConfig saves user configuration to the file from which it was loaded.
 func (cfg *Config) SaveUserConfig() error {
 	log.Info().Msg("Saving user configuration")
@@ -122,6 +129,7 @@ func (cfg *Config) GetUserConfig() map[string]interface{} {
 func (cfg *Config) GetConfig() map[string]interface{} {
 	config := make(map[string]interface{})
 	mergeMaps(cfg.defaults, config, nil)
+	mergeMaps(cfg.remote, config, nil)
 	mergeMaps(cfg.user, config, nil)
 	mergeMaps(cfg.cli, config, nil)
 	return config
@@ -132,10 +140,18 @@ func (cfg *Config) SetDefault(key string, value interface{}) {
 	cfg.set(&cfg.defaults, key, value)
 }
 
+// SetRemote replaces values fetched from remote configuration, they override defaults only.
+func (cfg *Config) SetRemote(values map[string]interface{}) {
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+	cfg.remote = values
+}
+
 // SetUser sets user configuration value for key.
 func (cfg *Config) SetUser(key string, value interface{}) {
 	if cfg.eventBus != nil {
-		cfg.eventBus.Publish(AppTopicConfig(key), value)
+		publishConfig(cfg.eventBus, key, value)
 	}
 	cfg.set(&cfg.user, key, value)
 }
@@ -192,6 +208,11 @@ func (cfg *Config) Get(key string) interface{} {
 		log.Debug().Msgf("Returning user config value %v:%v", key, userValue)
 		return userValue
 	}
+	remoteValue := SearchMap(cfg.remote, segments)
+	if remoteValue != nil {
+		log.Debug().Msgf("Returning remote config value %v:%v", key, remoteValue)
+		return remoteValue
+	}
 	defaultValue := SearchMap(cfg.defaults, segments)
 	log.Debug().Msgf("Returning default value %v:%v", key, defaultValue)
 	return defaultValue
@@ -375,3 +396,12 @@ func GetFloat64(flag cli.Float64Flag) float64 {
 func AppTopicConfig(configKey string) string {
 	return "config:" + configKey
 }
+
+// SubscribeConfig subscribes fn to user updates of the given config key, fn receives the new value.
+func SubscribeConfig(subscriber eventbus.Subscriber, configKey string, delivery eventbus.Delivery, fn func(interface{})) error {
+	return eventbus.Handle(AppTopicConfig(configKey)).Subscribe(subscriber, delivery, fn)
+}
+
+func publishConfig(publisher eventbus.Publisher, configKey string, value interface{}) {
+	eventbus.Handle(AppTopicConfig(configKey)).Publish(publisher, value)
+}
@@ -235,3 +235,105 @@ func TestUserConfig_GetConfig(t *testing.T) {
 func must(t *testing.T, err error) {
 	assert.NoError(t, err)
 }
+
+func TestUserConfig_Replace(t *testing.T) {
+	configFileName := NewTempFileName(t)
+	defer os.Remove(configFileName)
+
+	cfg := NewConfig()
+	assert.NoError(t, cfg.LoadUserConfig(configFileName))
+	cfg.SetDefault("openvpn.port", 1194)
+	cfg.SetDefault("openvpn.proto", "udp")
+	cfg.SetUser("openvpn.proto", "tcp")
+	cfg.SetUser("ui.port", 4449)
+	cfg.SetCLI("ui.port", 4000)
+
+	user := map[string]interface{}{
+		"openvpn": map[string]interface{}{"port": int64(1195)},
+		"ui":      map[string]interface{}{"port": int64(5000)},
+	}
+	assert.Equal(t, []Change{
+		{
+			Key:     "openvpn.port",
+			Current: Setting{Key: "openvpn.port", Value: 1194, Source: SourceDefault},
+			Next:    Setting{Key: "openvpn.port", Value: int64(1195), Source: SourceUser},
+		},
+		{
+			Key:     "openvpn.proto",
+			Current: Setting{Key: "openvpn.proto", Value: "tcp", Source: SourceUser},
+			Next:    Setting{Key: "openvpn.proto", Value: "udp", Source: SourceDefault},
+		},
+		{
+			Key:     "ui.port",
+			Current: Setting{Key: "ui.port", Value: 4000, Source: SourceCLI},
+			Next:    Setting{Key: "ui.port", Value: 4000, Source: SourceCLI},
+		},
+	}, cfg.DiffUserConfig(user))
+
+	assert.NoError(t, cfg.ReplaceUserConfig(user))
+	assert.Equal(t, 1195, cfg.GetInt("openvpn.port"))
+	assert.Equal(t, "udp", cfg.GetString("openvpn.proto"))
+	assert.Empty(t, cfg.DiffUserConfig(user))
+
+	reloaded := NewConfig()
+	assert.NoError(t, reloaded.LoadUserConfig(configFileName))
+	assert.Equal(t, user, reloaded.GetUserConfig())
+}
+
+func TestUserConfig_ReplaceKeepsManagedKeys(t *testing.T) {
+	configFileName := NewTempFileName(t)
+	defer os.Remove(configFileName)
+
+	cfg := NewConfig()
+	assert.NoError(t, cfg.LoadUserConfig(configFileName))
+	cfg.SetUser("terms.consumer-agreed", true)
+	cfg.SetUser("terms.provider-agreed", true)
+	cfg.SetUser("mmn.api-key", "key")
+	cfg.SetUser("openvpn.proto", "tcp")
+
+	user := map[string]interface{}{
+		"terms": map[string]interface{}{"provider-agreed": false},
+	}
+	assert.Equal(t, []Change{
+		{
+			Key:     "openvpn.proto",
+			Current: Setting{Key: "openvpn.proto", Value: "tcp", Source: SourceUser},
+		},
+		{
+			Key:     "terms.provider-agreed",
+			Current: Setting{Key: "terms.provider-agreed", Value: true, Source: SourceUser},
+			Next:    Setting{Key: "terms.provider-agreed", Value: false, Source: SourceUser},
+		},
+	}, cfg.DiffUserConfig(user))
+
+	assert.NoError(t, cfg.ReplaceUserConfig(user))
+	assert.True(t, cfg.GetBool("terms.consumer-agreed"))
+	assert.False(t, cfg.GetBool("terms.provider-agreed"))
+	assert.Equal(t, "key", cfg.GetString("mmn.api-key"))
+	assert.Nil(t, cfg.Get("openvpn.proto"))
+	assert.Len(t, user, 1, "given configuration is not modified")
+}
+
+func TestConfig_RemoteLayer(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetDefault("openvpn.port", 1194)
+	cfg.SetDefault("openvpn.proto", "udp")
+	cfg.SetRemote(map[string]interface{}{
+		"openvpn": map[string]interface{}{"port": 1195, "proto": "tcp"},
+	})
+	cfg.SetUser("openvpn.proto", "udp")
+
+	assert.Equal(t, 1195, cfg.GetInt("openvpn.port"))
+	assert.Equal(t, "udp", cfg.GetString("openvpn.proto"))
+	assert.Equal(t, []Setting{
+		{Key: "openvpn.port", Value: 1195, Source: SourceRemote},
+		{Key: "openvpn.proto", Value: "udp", Source: SourceUser},
+	}, cfg.Settings())
+	assert.Equal(t, []Change{
+		{
+			Key:     "openvpn.proto",
+			Current: Setting{Key: "openvpn.proto", Value: "udp", Source: SourceUser},
+			Next:    Setting{Key: "openvpn.proto", Value: "tcp", Source: SourceRemote},
+		},
+	}, cfg.DiffUserConfig(map[string]interface{}{}))
+}
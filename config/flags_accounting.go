@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagAccountingEnabled enables aggregated provider traffic accounting.
+	FlagAccountingEnabled = cli.BoolFlag{
+		Name:  "accounting.enabled",
+		Usage: "Store aggregated per session traffic by destination category (DNS policy categories and port classes)",
+		Value: false,
+	}
+	// FlagAccountingRetention how long traffic accounting records are kept.
+	FlagAccountingRetention = cli.DurationFlag{
+		Name:  "accounting.retention",
+		Usage: `Traffic accounting records retention period { "24h", "720h" }`,
+		Value: 30 * 24 * time.Hour,
+	}
+	// FlagAccountingMaxSessions maximum number of sessions kept in traffic accounting.
+	FlagAccountingMaxSessions = cli.IntFlag{
+		Name:  "accounting.max-sessions",
+		Usage: "Maximum number of sessions kept in traffic accounting, 0 means unlimited",
+		Value: 1000,
+	}
+)
+
+// RegisterFlagsAccounting function registers traffic accounting flags to flag list.
+func RegisterFlagsAccounting(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagAccountingEnabled,
+		&FlagAccountingRetention,
+		&FlagAccountingMaxSessions,
+	)
+}
+
+// ParseFlagsAccounting function fills in traffic accounting options from CLI context.
+func ParseFlagsAccounting(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagAccountingEnabled)
+	Current.ParseDurationFlag(ctx, FlagAccountingRetention)
+	Current.ParseIntFlag(ctx, FlagAccountingMaxSessions)
+}
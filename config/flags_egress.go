@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagEgressIPs public addresses used to NAT consumer traffic out.
+	FlagEgressIPs = cli.StringSliceFlag{
+		Name:  "egress.ips",
+		Usage: "Public IPv4 address(es) assigned to this host used for consumer traffic, separated by comma. Outbound IP is used if not set",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagEgressSelection egress address assignment mode.
+	FlagEgressSelection = cli.StringFlag{
+		Name:  "egress.selection",
+		Usage: `Egress address assignment to sessions { "round-robin", "sticky" }. Sticky keeps the same address for all sessions of a consumer`,
+		Value: "round-robin",
+	}
+	// FlagEgressSkipLocalCheck allows egress addresses which are not assigned to local interfaces.
+	FlagEgressSkipLocalCheck = cli.BoolFlag{
+		Name:  "egress.skip-local-check",
+		Usage: "Don't require egress addresses to be assigned to local interfaces, e.g. on hosts behind 1:1 NAT",
+		Value: false,
+	}
+)
+
+// RegisterFlagsEgress function registers egress address pool flags to flag list.
+func RegisterFlagsEgress(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagEgressIPs,
+		&FlagEgressSelection,
+		&FlagEgressSkipLocalCheck,
+	)
+}
+
+// ParseFlagsEgress function fills in egress address pool options from CLI context.
+func ParseFlagsEgress(ctx *cli.Context) {
+	Current.ParseStringSliceFlag(ctx, FlagEgressIPs)
+	Current.ParseStringFlag(ctx, FlagEgressSelection)
+	Current.ParseBoolFlag(ctx, FlagEgressSkipLocalCheck)
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagEventHistorySize number of recent event bus events kept for debugging.
+	FlagEventHistorySize = cli.IntFlag{
+		Name:  "events.history-size",
+		Usage: "Number of recent event bus events kept for debugging, 0 disables event history",
+		Value: 1000,
+	}
+	// FlagEventHistoryPersist keeps event history across node restarts.
+	FlagEventHistoryPersist = cli.BoolFlag{
+		Name:  "events.history-persist",
+		Usage: "Write event history to the data directory so it survives node restarts",
+		Value: false,
+	}
+)
+
+// RegisterFlagsEvents function registers event bus flags to flag list.
+func RegisterFlagsEvents(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagEventHistorySize,
+		&FlagEventHistoryPersist,
+	)
+}
+
+// ParseFlagsEvents function fills in event bus options from CLI context.
+func ParseFlagsEvents(ctx *cli.Context) {
+	Current.ParseIntFlag(ctx, FlagEventHistorySize)
+	Current.ParseBoolFlag(ctx, FlagEventHistoryPersist)
+}
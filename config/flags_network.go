@@ -21,6 +21,7 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/mysteriumnetwork/node/nat/traversal"
 )
 
 var (
@@ -58,6 +59,18 @@ var (
 		Usage: "URI of message broker",
 		Value: cli.NewStringSlice(metadata.DefaultNetwork.BrokerAddresses...),
 	}
+	// FlagSignallingAddress WebSocket relay URIs used for p2p signalling.
+	FlagSignallingAddress = cli.StringSliceFlag{
+		Name:  "p2p.signalling-address",
+		Usage: "URI(s) of WebSocket relays used for p2p signalling when message broker is not reachable (e.g. wss://relay.example.com/p2p)",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagObfuscation obfuscation methods for p2p and service traffic.
+	FlagObfuscation = cli.StringSliceFlag{
+		Name:  "p2p.obfuscation",
+		Usage: "Obfuscation methods offered by provider or preferred by consumer in order of preference to avoid traffic fingerprinting (e.g. scramble)",
+		Value: cli.NewStringSlice(),
+	}
 	// FlagEtherRPC URL or IPC socket to connect to Ethereum node.
 	FlagEtherRPC = cli.StringFlag{
 		Name:  "ether.client.rpc",
@@ -70,6 +83,60 @@ var (
 		Usage: "Enables NAT hole punching",
 		Value: true,
 	}
+	// FlagTraversalStrategy NAT hole punching strategy.
+	FlagTraversalStrategy = cli.StringFlag{
+		Name:  "p2p.traversal.strategy",
+		Usage: "NAT hole punching strategy: 'default' or 'birthday' (sprays pings to random ports for symmetric NAT)",
+		Value: string(traversal.DefaultPingConfig().Strategy),
+	}
+	// FlagTraversalPorts number of ports used for NAT hole punching.
+	FlagTraversalPorts = cli.IntFlag{
+		Name:  "p2p.traversal.ports",
+		Usage: "Number of local ports used for NAT hole punching",
+		Value: traversal.DefaultPingConfig().MaxPorts,
+	}
+	// FlagTraversalProviderTTL initial TTL of provider NAT pings.
+	FlagTraversalProviderTTL = cli.IntFlag{
+		Name:  "p2p.traversal.provider-ttl",
+		Usage: "Initial TTL of provider NAT pings",
+		Value: traversal.DefaultPingConfig().ProviderInitialTTL,
+	}
+	// FlagTraversalConsumerTTL initial TTL of consumer NAT pings.
+	FlagTraversalConsumerTTL = cli.IntFlag{
+		Name:  "p2p.traversal.consumer-ttl",
+		Usage: "Initial TTL of consumer NAT pings",
+		Value: traversal.DefaultPingConfig().ConsumerInitialTTL,
+	}
+	// FlagTraversalTTLStep TTL increase for every next pinged port.
+	FlagTraversalTTLStep = cli.IntFlag{
+		Name:  "p2p.traversal.ttl-step",
+		Usage: "TTL increase for every next port pinged during NAT hole punching",
+		Value: traversal.DefaultPingConfig().TTLStep,
+	}
+	// FlagTraversalInterval interval between NAT pings.
+	FlagTraversalInterval = cli.DurationFlag{
+		Name:  "p2p.traversal.interval",
+		Usage: "Interval between NAT pings",
+		Value: traversal.DefaultPingConfig().Interval,
+	}
+	// FlagTraversalTimeout NAT hole punching timeout.
+	FlagTraversalTimeout = cli.DurationFlag{
+		Name:  "p2p.traversal.timeout",
+		Usage: "NAT hole punching timeout",
+		Value: traversal.DefaultPingConfig().Timeout,
+	}
+	// FlagTraversalSprayPorts number of random ports pinged by birthday strategy.
+	FlagTraversalSprayPorts = cli.IntFlag{
+		Name:  "p2p.traversal.spray-ports",
+		Usage: "Number of random peer ports pinged from every local port when 'birthday' strategy is used",
+		Value: traversal.DefaultPingConfig().SprayPorts,
+	}
+	// FlagTraversalPreferPortForwarding makes provider rely on manually forwarded ports.
+	FlagTraversalPreferPortForwarding = cli.BoolFlag{
+		Name:  "p2p.traversal.prefer-port-forwarding",
+		Usage: "Skip UPnP and NAT hole punching assuming p2p ports are forwarded manually on the router",
+		Value: false,
+	}
 	// FlagPortMapping enables NAT port mapping.
 	FlagPortMapping = cli.BoolFlag{
 		Name:  "nat-port-mapping",
@@ -104,8 +171,19 @@ func RegisterFlagsNetwork(flags *[]cli.Flag) {
 		&FlagLocalnet,
 		&FlagPortMapping,
 		&FlagNATPunching,
+		&FlagTraversalStrategy,
+		&FlagTraversalPorts,
+		&FlagTraversalProviderTTL,
+		&FlagTraversalConsumerTTL,
+		&FlagTraversalTTLStep,
+		&FlagTraversalInterval,
+		&FlagTraversalTimeout,
+		&FlagTraversalSprayPorts,
+		&FlagTraversalPreferPortForwarding,
 		&FlagAPIAddress,
 		&FlagBrokerAddress,
+		&FlagSignallingAddress,
+		&FlagObfuscation,
 		&FlagEtherRPC,
 		&FlagIncomingFirewall,
 		&FlagOutgoingFirewall,
@@ -122,9 +200,20 @@ func ParseFlagsNetwork(ctx *cli.Context) {
 	Current.ParseBoolFlag(ctx, FlagTestnet2)
 	Current.ParseStringFlag(ctx, FlagAPIAddress)
 	Current.ParseStringSliceFlag(ctx, FlagBrokerAddress)
+	Current.ParseStringSliceFlag(ctx, FlagSignallingAddress)
+	Current.ParseStringSliceFlag(ctx, FlagObfuscation)
 	Current.ParseStringFlag(ctx, FlagEtherRPC)
 	Current.ParseBoolFlag(ctx, FlagPortMapping)
 	Current.ParseBoolFlag(ctx, FlagNATPunching)
+	Current.ParseStringFlag(ctx, FlagTraversalStrategy)
+	Current.ParseIntFlag(ctx, FlagTraversalPorts)
+	Current.ParseIntFlag(ctx, FlagTraversalProviderTTL)
+	Current.ParseIntFlag(ctx, FlagTraversalConsumerTTL)
+	Current.ParseIntFlag(ctx, FlagTraversalTTLStep)
+	Current.ParseDurationFlag(ctx, FlagTraversalInterval)
+	Current.ParseDurationFlag(ctx, FlagTraversalTimeout)
+	Current.ParseIntFlag(ctx, FlagTraversalSprayPorts)
+	Current.ParseBoolFlag(ctx, FlagTraversalPreferPortForwarding)
 	Current.ParseBoolFlag(ctx, FlagIncomingFirewall)
 	Current.ParseBoolFlag(ctx, FlagOutgoingFirewall)
 	Current.ParseInt64Flag(ctx, FlagChainID)
@@ -110,6 +110,18 @@ var (
 		Usage: "Determines the scrypt memory complexity. If set to true, will use 4MB blocks instead of the standard 256MB ones",
 		Value: true,
 	}
+	// FlagKeystoreExternalSigner is a local socket of the external signer process.
+	FlagKeystoreExternalSigner = cli.StringFlag{
+		Name:  "keystore.external-signer",
+		Usage: "Path to the local socket of external signer, which holds keys of identities listed in --keystore.external-signer.identities",
+		Value: "",
+	}
+	// FlagKeystoreExternalSignerIdentities lists identities signed by the external signer.
+	FlagKeystoreExternalSignerIdentities = cli.StringSliceFlag{
+		Name:  "keystore.external-signer.identities",
+		Usage: "Identities (separated by comma) whose keys are held by external signer instead of the keystore",
+		Value: cli.NewStringSlice(),
+	}
 	// FlagLogHTTP enables HTTP payload logging.
 	FlagLogHTTP = cli.BoolFlag{
 		Name:  "log.http",
@@ -257,6 +269,13 @@ var (
 		Hidden: true,
 	}
 
+	// FlagSOCKS5ConsumerAddress sets the local address where SOCKS5/HTTP proxy is exposed when connected to SOCKS5 service.
+	FlagSOCKS5ConsumerAddress = cli.StringFlag{
+		Name:  "socks5.consumer.address",
+		Usage: "Local address of SOCKS5/HTTP proxy exposed when connected to SOCKS5 service",
+		Value: "127.0.0.1:1080",
+	}
+
 	// FlagResidentCountry sets the resident country
 	FlagResidentCountry = cli.StringFlag{
 		Name:  "resident-country",
@@ -275,6 +294,12 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 	RegisterFlagsTransactor(flags)
 	RegisterFlagsPayments(flags)
 	RegisterFlagsPolicy(flags)
+	RegisterFlagsAccounting(flags)
+	RegisterFlagsStorage(flags)
+	RegisterFlagsEvents(flags)
+	RegisterFlagsEgress(flags)
+	RegisterFlagsUpstream(flags)
+	RegisterFlagsPortForwarding(flags)
 	RegisterFlagsMMN(flags)
 	RegisterFlagsPilvytis(flags)
 	RegisterFlagsChains(flags)
@@ -293,6 +318,8 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		&FlagFirewallProtectedNetworks,
 		&FlagShaperEnabled,
 		&FlagKeystoreLightweight,
+		&FlagKeystoreExternalSigner,
+		&FlagKeystoreExternalSignerIdentities,
 		&FlagLogHTTP,
 		&FlagLogLevel,
 		&FlagVerbose,
@@ -315,6 +342,7 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		&FlagDocsURL,
 		&FlagDNSResolutionHeadstart,
 		&FlagResidentCountry,
+		&FlagSOCKS5ConsumerAddress,
 	)
 
 	return nil
@@ -329,6 +357,12 @@ func ParseFlagsNode(ctx *cli.Context) {
 	ParseFlagsTransactor(ctx)
 	ParseFlagsPayments(ctx)
 	ParseFlagsPolicy(ctx)
+	ParseFlagsAccounting(ctx)
+	ParseFlagsStorage(ctx)
+	ParseFlagsEvents(ctx)
+	ParseFlagsEgress(ctx)
+	ParseFlagsUpstream(ctx)
+	ParseFlagsPortForwarding(ctx)
 	ParseFlagsMMN(ctx)
 	ParseFlagPilvytis(ctx)
 	ParseFlagsChains(ctx)
@@ -346,6 +380,8 @@ func ParseFlagsNode(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagFirewallProtectedNetworks)
 	Current.ParseBoolFlag(ctx, FlagShaperEnabled)
 	Current.ParseBoolFlag(ctx, FlagKeystoreLightweight)
+	Current.ParseStringFlag(ctx, FlagKeystoreExternalSigner)
+	Current.ParseStringSliceFlag(ctx, FlagKeystoreExternalSignerIdentities)
 	Current.ParseBoolFlag(ctx, FlagLogHTTP)
 	Current.ParseBoolFlag(ctx, FlagVerbose)
 	Current.ParseStringFlag(ctx, FlagLogLevel)
@@ -367,6 +403,7 @@ func ParseFlagsNode(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagDefaultCurrency)
 	Current.ParseStringFlag(ctx, FlagDocsURL)
 	Current.ParseDurationFlag(ctx, FlagDNSResolutionHeadstart)
+	Current.ParseStringFlag(ctx, FlagSOCKS5ConsumerAddress)
 
 	ValidateAddressFlags(FlagTequilapiAddress)
 }
@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagPortForwardingEnabled allows consumers to request ports forwarded to them.
+	FlagPortForwardingEnabled = cli.BoolFlag{
+		Name:  "port-forwarding.enabled",
+		Usage: "Allow consumers to request provider ports forwarded to them over the tunnel",
+		Value: false,
+	}
+	// FlagPortForwardingMaxPorts maximum number of ports forwarded to a single consumer.
+	FlagPortForwardingMaxPorts = cli.IntFlag{
+		Name:  "port-forwarding.max-ports",
+		Usage: "Maximum number of TCP and UDP ports forwarded to a single consumer",
+		Value: 5,
+	}
+	// FlagPortForwardingPorts range of provider ports forwarded to consumers.
+	FlagPortForwardingPorts = cli.StringFlag{
+		Name:  "port-forwarding.ports",
+		Usage: "Range of ports forwarded to consumers (e.g. 30000:30999), must not overlap service listen ports",
+		Value: "30000:30999",
+	}
+)
+
+// RegisterFlagsPortForwarding function registers port forwarding flags to flag list.
+func RegisterFlagsPortForwarding(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagPortForwardingEnabled,
+		&FlagPortForwardingMaxPorts,
+		&FlagPortForwardingPorts,
+	)
+}
+
+// ParseFlagsPortForwarding function fills in port forwarding options from CLI context.
+func ParseFlagsPortForwarding(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagPortForwardingEnabled)
+	Current.ParseIntFlag(ctx, FlagPortForwardingMaxPorts)
+	Current.ParseStringFlag(ctx, FlagPortForwardingPorts)
+}
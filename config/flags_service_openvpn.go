@@ -31,9 +31,15 @@ var (
 	// FlagOpenvpnPort port for OpenVPN to use.
 	FlagOpenvpnPort = cli.IntFlag{
 		Name:  "openvpn.port",
-		Usage: "OpenVPN port to use. If not specified, random port will be used",
+		Usage: "OpenVPN port to use (e.g. 443 with tcp protocol for networks blocking UDP). If not specified, random port will be used",
 		Value: 0,
 	}
+	// FlagOpenvpnTLSCrypt enables tls-crypt for the OpenVPN control channel.
+	FlagOpenvpnTLSCrypt = cli.BoolFlag{
+		Name:  "openvpn.tls-crypt",
+		Usage: "Encrypt OpenVPN control channel with tls-crypt to obfuscate it, tls-auth is used otherwise",
+		Value: true,
+	}
 	// FlagOpenvpnSubnet OpenVPN subnet that will be used for connecting clients.
 	FlagOpenvpnSubnet = cli.StringFlag{
 		Name:  "openvpn.subnet",
@@ -68,6 +74,7 @@ func RegisterFlagsServiceOpenvpn(flags *[]cli.Flag) {
 	*flags = append(*flags,
 		&FlagOpenvpnProtocol,
 		&FlagOpenvpnPort,
+		&FlagOpenvpnTLSCrypt,
 		&FlagOpenvpnSubnet,
 		&FlagOpenvpnNetmask,
 		&FlagOpenVPNPriceMinute,
@@ -80,6 +87,7 @@ func RegisterFlagsServiceOpenvpn(flags *[]cli.Flag) {
 func ParseFlagsServiceOpenvpn(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagOpenvpnProtocol)
 	Current.ParseIntFlag(ctx, FlagOpenvpnPort)
+	Current.ParseBoolFlag(ctx, FlagOpenvpnTLSCrypt)
 	Current.ParseStringFlag(ctx, FlagOpenvpnSubnet)
 	Current.ParseStringFlag(ctx, FlagOpenvpnNetmask)
 	Current.ParseFloat64Flag(ctx, FlagOpenVPNPriceMinute)
@@ -34,6 +34,12 @@ var (
 		Usage: "Subnet to be used by the wireguard service",
 		Value: "10.182.0.0/16",
 	}
+	// FlagWireguardListenSubnet6 IPv6 subnet to be used by the wireguard service.
+	FlagWireguardListenSubnet6 = cli.StringFlag{
+		Name:  "wireguard.allowed.subnet6",
+		Usage: "IPv6 subnet (/56 or larger, e.g. fd6d:7973:7400::/48) to be used by the wireguard service. IPv6 inside the tunnel is disabled if empty",
+		Value: "",
+	}
 	// FlagWireguardPriceMinute sets the price per minute for provided wireguard service.
 	FlagWireguardPriceMinute = cli.Float64Flag{
 		Name:  "wireguard.price-minute",
@@ -44,6 +50,12 @@ var (
 		Name:  "wireguard.price-gb",
 		Usage: "Sets the price of the wireguard service per minute.",
 	}
+	// FlagWireguardUserspaceDNS DNS servers used by userspace mode connections when no DNS servers are resolved.
+	FlagWireguardUserspaceDNS = cli.StringFlag{
+		Name:  "wireguard.userspace.dns",
+		Usage: "Comma separated list of DNS servers used in userspace (proxy) mode when the connection has no DNS servers",
+		Value: "1.1.1.1,8.8.8.8",
+	}
 	// FlagWireguardAccessPolicies a comma-separated list of access policies that determines allowed identities to use the service.
 	FlagWireguardAccessPolicies = cli.StringFlag{
 		Name:  "wireguard.access-policies",
@@ -56,9 +68,11 @@ func RegisterFlagsServiceWireguard(flags *[]cli.Flag) {
 	*flags = append(*flags,
 		&FlagWireguardListenPorts,
 		&FlagWireguardListenSubnet,
+		&FlagWireguardListenSubnet6,
 		&FlagWireguardPriceMinute,
 		&FlagWireguardPriceGB,
 		&FlagWireguardAccessPolicies,
+		&FlagWireguardUserspaceDNS,
 	)
 }
 
@@ -66,7 +80,9 @@ func RegisterFlagsServiceWireguard(flags *[]cli.Flag) {
 func ParseFlagsServiceWireguard(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagWireguardListenPorts)
 	Current.ParseStringFlag(ctx, FlagWireguardListenSubnet)
+	Current.ParseStringFlag(ctx, FlagWireguardListenSubnet6)
 	Current.ParseFloat64Flag(ctx, FlagWireguardPriceMinute)
 	Current.ParseFloat64Flag(ctx, FlagWireguardPriceGB)
 	Current.ParseStringFlag(ctx, FlagWireguardAccessPolicies)
+	Current.ParseStringFlag(ctx, FlagWireguardUserspaceDNS)
 }
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Storage backends.
+const (
+	StorageBackendBolt   = "bolt"
+	StorageBackendSQLite = "sqlite"
+)
+
+var (
+	// FlagStorageBackend database used to persist node state.
+	FlagStorageBackend = cli.StringFlag{
+		Name:  "storage.backend",
+		Usage: `Database used to persist node state, existing bolt data is moved to sqlite with "migrate-storage" command, sqlite needs a node built with cgo { "bolt", "sqlite" }`,
+		Value: StorageBackendBolt,
+	}
+	// FlagStorageRetention how long session and settlement history rows are kept.
+	FlagStorageRetention = cli.DurationFlag{
+		Name:  "storage.retention",
+		Usage: `Session and settlement history retention period, older sessions are kept as daily totals, 0 means unlimited { "720h", "2160h" }`,
+		Value: 0,
+	}
+	// FlagStorageMaxSessions maximum number of sessions and settlements kept in history.
+	FlagStorageMaxSessions = cli.IntFlag{
+		Name:  "storage.max-sessions",
+		Usage: "Maximum number of sessions and settlements kept in history, older sessions are kept as daily totals, 0 means unlimited",
+		Value: 0,
+	}
+	// FlagStorageRetentionInterval how often history retention is applied.
+	FlagStorageRetentionInterval = cli.DurationFlag{
+		Name:  "storage.retention-interval",
+		Usage: "How often history retention is applied",
+		Value: time.Hour,
+	}
+)
+
+// RegisterFlagsStorage function registers storage flags to flag list.
+func RegisterFlagsStorage(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagStorageBackend,
+		&FlagStorageRetention,
+		&FlagStorageMaxSessions,
+		&FlagStorageRetentionInterval,
+	)
+}
+
+// ParseFlagsStorage function fills in storage options from CLI context.
+func ParseFlagsStorage(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagStorageBackend)
+	Current.ParseDurationFlag(ctx, FlagStorageRetention)
+	Current.ParseIntFlag(ctx, FlagStorageMaxSessions)
+	Current.ParseDurationFlag(ctx, FlagStorageRetentionInterval)
+}
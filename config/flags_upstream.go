@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagUpstreamWireGuardConfig upstream WireGuard configuration file.
+	FlagUpstreamWireGuardConfig = cli.StringFlag{
+		Name:  "upstream.wireguard.config",
+		Usage: "wg-quick style WireGuard configuration file of the upstream tunnel all consumer traffic is forwarded through",
+		Value: "",
+	}
+	// FlagUpstreamSOCKS5Address upstream SOCKS5 proxy address, it is rejected as SOCKS5 can't forward all consumer traffic.
+	FlagUpstreamSOCKS5Address = cli.StringFlag{
+		Name:   "upstream.socks5.address",
+		Usage:  "Not supported: SOCKS5 proxy forwards TCP only, use upstream.wireguard.config instead",
+		Value:  "",
+		Hidden: true,
+	}
+	// FlagUpstreamCheckInterval upstream health check interval.
+	FlagUpstreamCheckInterval = cli.DurationFlag{
+		Name:  "upstream.check-interval",
+		Usage: "How often the upstream is checked, services are stopped if it goes down and started again once it recovers",
+		Value: 30 * time.Second,
+	}
+	// FlagUpstreamDNS DNS servers consumer queries are resolved with through the upstream.
+	FlagUpstreamDNS = cli.StringFlag{
+		Name:  "upstream.dns",
+		Usage: "Comma separated list of DNS servers consumer queries are resolved with through the upstream, used when its WireGuard configuration has no DNS",
+		Value: "1.1.1.1,8.8.8.8",
+	}
+)
+
+// RegisterFlagsUpstream function registers upstream tunnel flags to flag list.
+func RegisterFlagsUpstream(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagUpstreamWireGuardConfig,
+		&FlagUpstreamSOCKS5Address,
+		&FlagUpstreamCheckInterval,
+		&FlagUpstreamDNS,
+	)
+}
+
+// ParseFlagsUpstream function fills in upstream tunnel options from CLI context.
+func ParseFlagsUpstream(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagUpstreamWireGuardConfig)
+	Current.ParseStringFlag(ctx, FlagUpstreamSOCKS5Address)
+	Current.ParseDurationFlag(ctx, FlagUpstreamCheckInterval)
+	Current.ParseStringFlag(ctx, FlagUpstreamDNS)
+}
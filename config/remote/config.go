@@ -49,13 +49,14 @@ func NewConfig(client Fetcher) (*Config, error) {
 	return cfg, cfg.RefreshRemoteConfig()
 }
 
-// RefreshRemoteConfig - will fetch latest config
+// RefreshRemoteConfig - will fetch latest config and make it the remote layer of the current configuration.
 func (rc *Config) RefreshRemoteConfig() error {
-	config, err := rc.client.FetchConfig()
+	remoteConfig, err := rc.client.FetchConfig()
 	if err != nil {
 		return err
 	}
-	rc.config = config
+	rc.config = remoteConfig
+	config.Current.SetRemote(remoteConfig)
 	return nil
 }
 
@@ -0,0 +1,274 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/urfave/cli/v2"
+)
+
+// SchemaVersion is the version of configuration file layout supported by this node.
+const SchemaVersion = 1
+
+// SchemaVersionKey is the configuration file key holding its schema version.
+const SchemaVersionKey = "config-version"
+
+// Kind is a type of configuration value.
+type Kind string
+
+// Kinds of configuration values.
+const (
+	KindBool        Kind = "bool"
+	KindInt         Kind = "int"
+	KindUint        Kind = "uint"
+	KindFloat       Kind = "float"
+	KindDuration    Kind = "duration"
+	KindString      Kind = "string"
+	KindStringSlice Kind = "string-slice"
+)
+
+// userKeys are configuration keys which are set by tequilapi and have no CLI flags.
+var userKeys = map[string]Kind{
+	"terms.consumer-agreed": KindBool,
+	"terms.provider-agreed": KindBool,
+	"terms.version":         KindString,
+}
+
+// Schema describes configuration keys known to the node, it is built from CLI flags.
+type Schema struct {
+	keys map[string]Kind
+}
+
+// NewSchema creates schema of the given flags.
+func NewSchema(flags []cli.Flag) *Schema {
+	schema := &Schema{keys: map[string]Kind{SchemaVersionKey: KindInt}}
+	for key, kind := range userKeys {
+		schema.keys[key] = kind
+	}
+	for _, flag := range flags {
+		if kind, ok := flagKind(flag); ok {
+			schema.keys[strings.ToLower(flag.Names()[0])] = kind
+		}
+	}
+	return schema
+}
+
+// NodeSchema creates schema of all flags node and its services can be configured with.
+func NodeSchema() (*Schema, error) {
+	var flags []cli.Flag
+	if err := RegisterFlagsNode(&flags); err != nil {
+		return nil, err
+	}
+	RegisterFlagsServiceStart(&flags)
+	RegisterFlagsServiceOpenvpn(&flags)
+	RegisterFlagsServiceWireguard(&flags)
+	RegisterFlagsServiceNoop(&flags)
+	RegisterFlagsServiceSOCKS5(&flags)
+	return NewSchema(flags), nil
+}
+
+func flagKind(flag cli.Flag) (Kind, bool) {
+	switch flag.(type) {
+	case *cli.BoolFlag:
+		return KindBool, true
+	case *cli.IntFlag, *cli.Int64Flag:
+		return KindInt, true
+	case *cli.UintFlag, *cli.Uint64Flag:
+		return KindUint, true
+	case *cli.Float64Flag:
+		return KindFloat, true
+	case *cli.DurationFlag:
+		return KindDuration, true
+	case *cli.StringFlag, *cli.PathFlag, *cli.GenericFlag:
+		return KindString, true
+	case *cli.StringSliceFlag:
+		return KindStringSlice, true
+	}
+	return "", false
+}
+
+// Kind returns the kind of the key value, false if the key is unknown.
+func (s *Schema) Kind(key string) (Kind, bool) {
+	kind, ok := s.keys[strings.ToLower(key)]
+	return kind, ok
+}
+
+// Issue is a problem found in configuration file.
+type Issue struct {
+	Line    int
+	Key     string
+	Message string
+}
+
+func (i Issue) String() string {
+	// Syntax errors carry the line in their message.
+	if i.Key == "" {
+		return i.Message
+	}
+	return fmt.Sprintf("line %d: %s: %s", i.Line, i.Key, i.Message)
+}
+
+var syntaxErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// Parse decodes TOML configuration file and validates it against the schema.
+// Configuration is returned only if there are no issues.
+func (s *Schema) Parse(data []byte) (map[string]interface{}, []Issue) {
+	config := make(map[string]interface{})
+	if _, err := toml.Decode(string(data), &config); err != nil {
+		issue := Issue{Message: err.Error()}
+		if match := syntaxErrorLine.FindStringSubmatch(err.Error()); match != nil {
+			issue.Line, _ = strconv.Atoi(match[1])
+		}
+		return nil, []Issue{issue}
+	}
+
+	lines := keyLines(data)
+	var issues []Issue
+	for key, value := range FlattenMap(config) {
+		kind, ok := s.Kind(key)
+		if !ok {
+			issues = append(issues, Issue{Line: lines[key], Key: key, Message: "unknown key"})
+			continue
+		}
+		if err := checkKind(kind, value); err != nil {
+			issues = append(issues, Issue{Line: lines[key], Key: key, Message: err.Error()})
+		}
+	}
+	if version, ok := config[SchemaVersionKey].(int64); ok && (version < 1 || version > SchemaVersion) {
+		issues = append(issues, Issue{
+			Line:    lines[SchemaVersionKey],
+			Key:     SchemaVersionKey,
+			Message: fmt.Sprintf("unsupported version %d, latest supported is %d", version, SchemaVersion),
+		})
+	}
+
+	if len(issues) > 0 {
+		sort.Slice(issues, func(i, j int) bool {
+			if issues[i].Line != issues[j].Line {
+				return issues[i].Line < issues[j].Line
+			}
+			return issues[i].Key < issues[j].Key
+		})
+		return nil, issues
+	}
+	return config, nil
+}
+
+func checkKind(kind Kind, value interface{}) error {
+	valid := false
+	switch kind {
+	case KindBool:
+		_, valid = value.(bool)
+	case KindInt:
+		_, valid = value.(int64)
+	case KindUint:
+		i, ok := value.(int64)
+		valid = ok && i >= 0
+	case KindFloat:
+		switch value.(type) {
+		case float64, int64:
+			valid = true
+		}
+	case KindDuration:
+		if s, ok := value.(string); ok {
+			_, err := time.ParseDuration(s)
+			valid = err == nil
+		}
+	case KindString:
+		_, valid = value.(string)
+	case KindStringSlice:
+		switch v := value.(type) {
+		case string:
+			valid = true
+		case []interface{}:
+			valid = true
+			for _, item := range v {
+				if _, ok := item.(string); !ok {
+					valid = false
+				}
+			}
+		}
+	}
+	if !valid {
+		return fmt.Errorf("expected %s, got %v", kind, value)
+	}
+	return nil
+}
+
+// keyLines finds lines where keys of TOML document are set.
+func keyLines(data []byte) map[string]int {
+	lines := make(map[string]int)
+	table := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#"):
+		case strings.HasPrefix(text, "["):
+			table = unquoteKey(strings.Trim(strings.SplitN(text, "]", 2)[0], "[ "))
+		default:
+			eq := strings.Index(text, "=")
+			if eq < 0 {
+				continue
+			}
+			key := unquoteKey(strings.TrimSpace(text[:eq]))
+			if table != "" {
+				key = table + "." + key
+			}
+			if _, ok := lines[key]; !ok {
+				lines[key] = line
+			}
+		}
+	}
+	return lines
+}
+
+func unquoteKey(key string) string {
+	segments := strings.Split(key, ".")
+	for i := range segments {
+		segments[i] = strings.Trim(strings.TrimSpace(segments[i]), `"'`)
+	}
+	return strings.ToLower(strings.Join(segments, "."))
+}
+
+// FlattenMap flattens nested configuration map into a map of dot separated keys.
+func FlattenMap(config map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	flattenMap(config, "", flat)
+	return flat
+}
+
+func flattenMap(config map[string]interface{}, prefix string, flat map[string]interface{}) {
+	for key, value := range config {
+		key = strings.ToLower(prefix + key)
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenMap(nested, key+".", flat)
+			continue
+		}
+		flat[key] = value
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func TestSchema_Parse(t *testing.T) {
+	schema := NewSchema([]cli.Flag{
+		&cli.IntFlag{Name: "openvpn.port"},
+		&cli.StringSliceFlag{Name: "discovery.type"},
+		&cli.DurationFlag{Name: "storage.retention"},
+		&cli.Float64Flag{Name: "payment.price-gb"},
+	})
+
+	config, issues := schema.Parse([]byte(`config-version = 1
+
+[discovery]
+type = ["api", "broker"]
+
+[openvpn]
+port = 1194
+
+[payment]
+price-gb = 1
+`))
+	assert.Empty(t, issues)
+	assert.Equal(t, int64(1194), config["openvpn"].(map[string]interface{})["port"])
+
+	config, issues = schema.Parse([]byte(`config-version = 2
+
+[openvpn]
+port = "1194"
+proto = "udp"
+
+[storage]
+retention = "30 days"
+`))
+	assert.Nil(t, config)
+	assert.Equal(t, []Issue{
+		{Line: 1, Key: "config-version", Message: "unsupported version 2, latest supported is 1"},
+		{Line: 4, Key: "openvpn.port", Message: "expected int, got 1194"},
+		{Line: 5, Key: "openvpn.proto", Message: "unknown key"},
+		{Line: 8, Key: "storage.retention", Message: "expected duration, got 30 days"},
+	}, issues)
+
+	_, issues = schema.Parse([]byte("[openvpn\nport = 1"))
+	assert.Len(t, issues, 1)
+	assert.Equal(t, 1, issues[0].Line)
+}
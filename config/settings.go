@@ -0,0 +1,188 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Layers configuration values come from.
+const (
+	SourceDefault = "default"
+	SourceRemote  = "remote"
+	SourceUser    = "user"
+	SourceCLI     = "cli"
+)
+
+// managedUserKeys are user configuration keys managed by tequilapi endpoints, e.g. accepted terms.
+// Replacing user configuration keeps their current values unless the new configuration sets them.
+var managedUserKeys = []string{
+	"terms.consumer-agreed",
+	"terms.provider-agreed",
+	"terms.version",
+	FlagMMNAPIKey.Name,
+}
+
+// Setting is an effective configuration value together with the layer it comes from.
+type Setting struct {
+	Key    string
+	Value  interface{}
+	Source string
+}
+
+// Change describes how replacing user configuration changes a setting.
+// Value stays the same if it is overridden by a CLI flag.
+type Change struct {
+	Key     string
+	Current Setting
+	Next    Setting
+}
+
+// Settings returns effective values of all configured keys ordered by key.
+func (cfg *Config) Settings() []Setting {
+	return settings(cfg.defaults, cfg.remote, cfg.user, cfg.cli)
+}
+
+// DiffUserConfig returns changes of effective values and user set values which replacing user configuration would cause.
+func (cfg *Config) DiffUserConfig(user map[string]interface{}) []Change {
+	user = cfg.keepManagedKeys(user)
+	current := settingsByKey(cfg.Settings())
+	next := settingsByKey(settings(cfg.defaults, cfg.remote, user, cfg.cli))
+	currentUser := FlattenMap(cfg.user)
+	nextUser := FlattenMap(user)
+
+	keys := make(map[string]struct{})
+	for key := range current {
+		keys[key] = struct{}{}
+	}
+	for key := range next {
+		keys[key] = struct{}{}
+	}
+
+	var changes []Change
+	for key := range keys {
+		currentUserValue, currentSet := currentUser[key]
+		nextUserValue, nextSet := nextUser[key]
+		userChanged := currentSet != nextSet || !sameValue(currentUserValue, nextUserValue)
+		effectiveChanged := current[key].Source != next[key].Source || !sameValue(current[key].Value, next[key].Value)
+		if !userChanged && !effectiveChanged {
+			continue
+		}
+		changes = append(changes, Change{Key: key, Current: current[key], Next: next[key]})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// ReplaceUserConfig atomically replaces user configuration file and values with the given ones.
+// Keys managed by tequilapi, like accepted terms, are kept when the given configuration does not set them.
+func (cfg *Config) ReplaceUserConfig(user map[string]interface{}) error {
+	if !cfg.userConfigLoaded() {
+		return errors.New("user configuration cannot be replaced, because it must be loaded first")
+	}
+	user = cfg.keepManagedKeys(user)
+	changes := cfg.DiffUserConfig(user)
+
+	var out strings.Builder
+	if err := toml.NewEncoder(&out).Encode(user); err != nil {
+		return errors.Wrap(err, "failed to write configuration as toml")
+	}
+	tmp := cfg.userConfigLocation + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(out.String()), 0700); err != nil {
+		return errors.Wrap(err, "failed to write configuration to file")
+	}
+	if err := os.Rename(tmp, cfg.userConfigLocation); err != nil {
+		os.Remove(tmp)
+		return errors.Wrap(err, "failed to write configuration to file")
+	}
+
+	cfg.user = user
+	log.Info().Msgf("User configuration replaced, %d settings changed", len(changes))
+	if cfg.eventBus != nil {
+		for _, change := range changes {
+			publishConfig(cfg.eventBus, change.Key, change.Next.Value)
+		}
+	}
+	return nil
+}
+
+// keepManagedKeys returns a copy of the user configuration with current values of managed keys it does not set.
+func (cfg *Config) keepManagedKeys(user map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for key, value := range FlattenMap(user) {
+		cfg.set(&result, key, value)
+	}
+	for _, key := range managedUserKeys {
+		segments := strings.Split(key, ".")
+		if SearchMap(result, segments) != nil {
+			continue
+		}
+		if current := SearchMap(cfg.user, segments); current != nil {
+			cfg.set(&result, key, current)
+		}
+	}
+	return result
+}
+
+func settings(defaults, remote, user, cli map[string]interface{}) []Setting {
+	byKey := make(map[string]Setting)
+	for _, layer := range []struct {
+		source string
+		values map[string]interface{}
+	}{
+		{SourceDefault, defaults},
+		{SourceRemote, remote},
+		{SourceUser, user},
+		{SourceCLI, cli},
+	} {
+		for key, value := range FlattenMap(layer.values) {
+			if value == nil {
+				continue
+			}
+			byKey[key] = Setting{Key: key, Value: value, Source: layer.source}
+		}
+	}
+
+	result := make([]Setting, 0, len(byKey))
+	for _, setting := range byKey {
+		result = append(result, setting)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+func settingsByKey(settings []Setting) map[string]Setting {
+	byKey := make(map[string]Setting, len(settings))
+	for _, setting := range settings {
+		byKey[setting.Key] = setting
+	}
+	return byKey
+}
+
+// sameValue compares values loosely, as the same value may be decoded into different types by TOML and JSON.
+func sameValue(a, b interface{}) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
@@ -20,6 +20,7 @@ package bandwidth
 import (
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/eventbus"
 )
 
 // AppTopicConnectionThroughput represents the session throughput topic.
@@ -35,3 +36,13 @@ type AppEventConnectionThroughput struct {
 type Throughput struct {
 	Up, Down datasize.BitSpeed
 }
+
+// PublishConnectionThroughput publishes connection throughput on the bus.
+func PublishConnectionThroughput(publisher eventbus.Publisher, e AppEventConnectionThroughput) {
+	eventbus.Handle(AppTopicConnectionThroughput).Publish(publisher, e)
+}
+
+// SubscribeConnectionThroughput subscribes fn to connection throughput.
+func SubscribeConnectionThroughput(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventConnectionThroughput)) error {
+	return eventbus.Handle(AppTopicConnectionThroughput).Subscribe(subscriber, delivery, fn)
+}
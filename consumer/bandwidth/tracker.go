@@ -30,18 +30,14 @@ import (
 
 const bitsInByte = 8
 
-type publisher interface {
-	Publish(topic string, data interface{})
-}
-
 // NewTracker creates instance of Tracker
-func NewTracker(publisher publisher) *Tracker {
+func NewTracker(publisher eventbus.Publisher) *Tracker {
 	return &Tracker{publisher: publisher}
 }
 
 // Tracker keeps track of current speed
 type Tracker struct {
-	publisher publisher
+	publisher eventbus.Publisher
 
 	previous connectionstate.Statistics
 	lock     sync.RWMutex
@@ -49,10 +45,10 @@ type Tracker struct {
 
 // Subscribe subscribes to relevant events of event bus.
 func (t *Tracker) Subscribe(bus eventbus.Subscriber) error {
-	if err := bus.SubscribeAsync(connectionstate.AppTopicConnectionSession, t.consumeSessionEvent); err != nil {
+	if err := connectionstate.SubscribeConnectionSession(bus, eventbus.DeliverAsync, t.consumeSessionEvent); err != nil {
 		return err
 	}
-	return bus.SubscribeAsync(connectionstate.AppTopicConnectionStatistics, t.consumeStatisticsEvent)
+	return connectionstate.SubscribeConnectionStatistics(bus, eventbus.DeliverAsync, t.consumeStatisticsEvent)
 }
 
 const consumeCooldown = 500 * time.Millisecond
@@ -79,7 +75,7 @@ func (t *Tracker) consumeStatisticsEvent(evt connectionstate.AppEventConnectionS
 	byteDownDiff := evt.Stats.BytesReceived - t.previous.BytesReceived
 	byteUpDiff := evt.Stats.BytesSent - t.previous.BytesSent
 
-	t.publisher.Publish(AppTopicConnectionThroughput, AppEventConnectionThroughput{
+	PublishConnectionThroughput(t.publisher, AppEventConnectionThroughput{
 		Throughput: Throughput{
 			Up:   datasize.BitSpeed(float64(byteUpDiff) / secondsSince * bitsInByte),
 			Down: datasize.BitSpeed(float64(byteDownDiff) / secondsSince * bitsInByte),
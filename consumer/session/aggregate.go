@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// Aggregate holds daily totals of sessions removed from history by retention.
+// Sessions are grouped by every field sessions can be filtered by, so statistics stay exact.
+type Aggregate struct {
+	ID          string `storm:"id"`
+	Day         time.Time
+	Direction   string
+	ConsumerID  identity.Identity
+	HermesID    string
+	ProviderID  identity.Identity
+	ServiceType string
+	Status      string
+
+	Count        int
+	DataSent     uint64
+	DataReceived uint64
+	Duration     time.Duration
+	Tokens       *big.Int
+}
+
+// NewAggregate creates empty aggregate the given session belongs to.
+func NewAggregate(session History) Aggregate {
+	day := session.Started.UTC().Truncate(stepDay)
+	return Aggregate{
+		ID: fmt.Sprintf(
+			"%s|%s|%s|%s|%s|%s|%s",
+			day.Format("2006-01-02"),
+			session.Direction,
+			session.ConsumerID.Address,
+			session.HermesID,
+			session.ProviderID.Address,
+			session.ServiceType,
+			session.Status,
+		),
+		Day:         day,
+		Direction:   session.Direction,
+		ConsumerID:  session.ConsumerID,
+		HermesID:    session.HermesID,
+		ProviderID:  session.ProviderID,
+		ServiceType: session.ServiceType,
+		Status:      session.Status,
+		Tokens:      new(big.Int),
+	}
+}
+
+// Add accumulates given session to the aggregate.
+func (a *Aggregate) Add(session History) {
+	a.Count++
+	a.DataSent += session.DataSent
+	a.DataReceived += session.DataReceived
+	a.Duration += session.GetDuration()
+	if session.Tokens != nil {
+		a.Tokens = new(big.Int).Add(a.Tokens, session.Tokens)
+	}
+}
@@ -91,6 +91,36 @@ func (f *Filter) SetStatus(status string) *Filter {
 	return f
 }
 
+// toAggregateMatcher matches daily aggregates of the days overlapping with the filtered period.
+func (f *Filter) toAggregateMatcher() q.Matcher {
+	where := make([]q.Matcher, 0)
+	if f.StartedFrom != nil {
+		where = append(where, q.Gte("Day", f.StartedFrom.Truncate(stepDay)))
+	}
+	if f.StartedTo != nil {
+		where = append(where, q.Lte("Day", *f.StartedTo))
+	}
+	if f.Direction != nil {
+		where = append(where, q.Eq("Direction", *f.Direction))
+	}
+	if f.ConsumerID != nil {
+		where = append(where, q.Eq("ConsumerID", *f.ConsumerID))
+	}
+	if f.HermesID != nil {
+		where = append(where, q.Eq("HermesID", *f.HermesID))
+	}
+	if f.ProviderID != nil {
+		where = append(where, q.Eq("ProviderID", *f.ProviderID))
+	}
+	if f.ServiceType != nil {
+		where = append(where, q.Eq("ServiceType", *f.ServiceType))
+	}
+	if f.Status != nil {
+		where = append(where, q.Eq("Status", *f.Status))
+	}
+	return q.And(where...)
+}
+
 func (f *Filter) toMatcher() q.Matcher {
 	where := make([]q.Matcher, 0)
 	if f.StartedFrom != nil {
@@ -0,0 +1,41 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"time"
+
+	session_node "github.com/mysteriumnetwork/node/session"
+)
+
+// Repository persists session history.
+type Repository interface {
+	// Store inserts new session.
+	Store(session History) error
+	// Update updates existing session.
+	Update(session History) error
+	// List returns sessions matching the filter, most recent first.
+	List(filter *Filter) ([]History, error)
+	// Stats returns statistics of sessions and daily aggregates matching the filter.
+	Stats(filter *Filter) (Stats, error)
+	// StatsByDay returns statistics of sessions and daily aggregates matching the filter grouped by day.
+	StatsByDay(filter *Filter) (map[time.Time]Stats, error)
+	// RollUp moves at most limit oldest sessions, which are started before the given time or
+	// are not among keep most recent sessions, into daily aggregates. Skipped sessions are not moved nor counted.
+	RollUp(before time.Time, keep, limit int, skip []session_node.ID) (int, error)
+}
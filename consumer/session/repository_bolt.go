@@ -0,0 +1,197 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"errors"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	session_node "github.com/mysteriumnetwork/node/session"
+)
+
+const (
+	sessionStorageBucketName   = "session-history"
+	sessionAggregateBucketName = "session-history-daily"
+)
+
+// BoltRepository stores session history in boltdb.
+type BoltRepository struct {
+	storage *boltdb.Bolt
+}
+
+// NewBoltRepository creates session history repository stored in boltdb.
+func NewBoltRepository(storage *boltdb.Bolt) *BoltRepository {
+	return &BoltRepository{storage: storage}
+}
+
+// Store inserts new session.
+func (br *BoltRepository) Store(session History) error {
+	return br.storage.Store(sessionStorageBucketName, &session)
+}
+
+// Update updates existing session.
+func (br *BoltRepository) Update(session History) error {
+	return br.storage.Update(sessionStorageBucketName, &session)
+}
+
+// List returns sessions matching the filter, most recent first.
+func (br *BoltRepository) List(filter *Filter) (result []History, err error) {
+	err = br.storage.WithDB(func(db *storm.DB) error {
+		return db.From(sessionStorageBucketName).
+			Select(filter.toMatcher()).
+			OrderBy("Started").
+			Reverse().
+			Find(&result)
+	})
+	if errors.Is(err, storm.ErrNotFound) {
+		return []History{}, nil
+	}
+
+	return result, err
+}
+
+// Stats returns statistics of sessions and daily aggregates matching the filter.
+func (br *BoltRepository) Stats(filter *Filter) (result Stats, err error) {
+	result = NewStats()
+	err = br.storage.WithDB(func(db *storm.DB) error {
+		err := db.From(sessionStorageBucketName).
+			Select(filter.toMatcher()).
+			Each(new(History), func(record interface{}) error {
+				result.Add(*record.(*History))
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+
+		return db.From(sessionAggregateBucketName).
+			Select(filter.toAggregateMatcher()).
+			Each(new(Aggregate), func(record interface{}) error {
+				result.AddAggregate(*record.(*Aggregate))
+				return nil
+			})
+	})
+	return result, err
+}
+
+// StatsByDay returns statistics of sessions and daily aggregates matching the filter grouped by day.
+func (br *BoltRepository) StatsByDay(filter *Filter) (result map[time.Time]Stats, err error) {
+	result = make(map[time.Time]Stats)
+	add := func(day time.Time, fn func(stats *Stats)) {
+		stats, ok := result[day]
+		if !ok {
+			stats = NewStats()
+		}
+		fn(&stats)
+		result[day] = stats
+	}
+
+	err = br.storage.WithDB(func(db *storm.DB) error {
+		err := db.From(sessionStorageBucketName).
+			Select(filter.toMatcher()).
+			Each(new(History), func(record interface{}) error {
+				session := record.(*History)
+				add(session.Started.Truncate(stepDay), func(stats *Stats) { stats.Add(*session) })
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+
+		return db.From(sessionAggregateBucketName).
+			Select(filter.toAggregateMatcher()).
+			Each(new(Aggregate), func(record interface{}) error {
+				aggregate := record.(*Aggregate)
+				add(aggregate.Day, func(stats *Stats) { stats.AddAggregate(*aggregate) })
+				return nil
+			})
+	})
+	return result, err
+}
+
+// RollUp moves oldest sessions outside of retention limits into daily aggregates.
+func (br *BoltRepository) RollUp(before time.Time, keep, limit int, skip []session_node.ID) (rolled int, err error) {
+	inactive := q.Not(q.In("SessionID", skip))
+
+	err = br.storage.WithDB(func(db *storm.DB) error {
+		tx, err := db.Begin(true)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		sessions := tx.From(sessionStorageBucketName)
+		expiredCount := 0
+		if keep > 0 {
+			total, err := sessions.Select(inactive).Count(new(History))
+			if err != nil {
+				return err
+			}
+			expiredCount = total - keep
+		}
+		if !before.IsZero() {
+			old, err := sessions.Select(inactive, q.Lt("Started", before)).Count(new(History))
+			if err != nil {
+				return err
+			}
+			if old > expiredCount {
+				expiredCount = old
+			}
+		}
+		if expiredCount <= 0 {
+			return nil
+		}
+		if expiredCount > limit {
+			expiredCount = limit
+		}
+
+		var expired []History
+		if err := sessions.Select(inactive).OrderBy("Started").Limit(expiredCount).Find(&expired); err != nil {
+			return err
+		}
+
+		aggregates := tx.From(sessionAggregateBucketName)
+		changed := make(map[string]*Aggregate)
+		for i := range expired {
+			aggregate := NewAggregate(expired[i])
+			if existing, ok := changed[aggregate.ID]; ok {
+				aggregate = *existing
+			} else if err := aggregates.One("ID", aggregate.ID, &aggregate); err != nil && !errors.Is(err, storm.ErrNotFound) {
+				return err
+			}
+			aggregate.Add(expired[i])
+			changed[aggregate.ID] = &aggregate
+
+			if err := sessions.DeleteStruct(&expired[i]); err != nil {
+				return err
+			}
+		}
+		for _, aggregate := range changed {
+			if err := aggregates.Save(aggregate); err != nil {
+				return err
+			}
+		}
+
+		rolled = len(expired)
+		return tx.Commit()
+	})
+	return rolled, err
+}
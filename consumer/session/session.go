@@ -49,6 +49,7 @@ type History struct {
 	ServiceType     string
 	ConsumerCountry string
 	ProviderCountry string
+	EgressIP        string
 	DataSent        uint64
 	DataReceived    uint64
 	Tokens          *big.Int
@@ -18,14 +18,11 @@
 package session
 
 import (
-	"errors"
 	"math/big"
 	"sync"
 	"time"
 
-	"github.com/asdine/storm/v3"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
-	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	session_node "github.com/mysteriumnetwork/node/session"
@@ -34,23 +31,24 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-const sessionStorageBucketName = "session-history"
+// rollUpBatchSize limits how many sessions are rolled up in a single transaction.
+const rollUpBatchSize = 1000
 
 type timeGetter func() time.Time
 
 // Storage contains functions for storing, getting session objects.
 type Storage struct {
-	storage    *boltdb.Bolt
+	repository Repository
 	timeGetter timeGetter
 
 	mu             sync.RWMutex
 	sessionsActive map[session_node.ID]History
 }
 
-// NewSessionStorage creates session repository with given dependencies.
-func NewSessionStorage(storage *boltdb.Bolt) *Storage {
+// NewSessionStorage creates session storage persisting history to the given repository.
+func NewSessionStorage(repository Repository) *Storage {
 	return &Storage{
-		storage:    storage,
+		repository: repository,
 		timeGetter: time.Now,
 
 		sessionsActive: make(map[session_node.ID]History),
@@ -59,22 +57,22 @@ func NewSessionStorage(storage *boltdb.Bolt) *Storage {
 
 // Subscribe subscribes to relevant events of event bus.
 func (repo *Storage) Subscribe(bus eventbus.Subscriber) error {
-	if err := bus.Subscribe(session_event.AppTopicSession, repo.consumeServiceSessionEvent); err != nil {
+	if err := session_event.SubscribeSession(bus, eventbus.DeliverSync, repo.consumeServiceSessionEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(session_event.AppTopicDataTransferred, repo.consumeServiceSessionStatisticsEvent); err != nil {
+	if err := session_event.SubscribeDataTransferred(bus, eventbus.DeliverAsync, repo.consumeServiceSessionStatisticsEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(session_event.AppTopicTokensEarned, repo.consumeServiceSessionEarningsEvent); err != nil {
+	if err := session_event.SubscribeTokensEarned(bus, eventbus.DeliverAsync, repo.consumeServiceSessionEarningsEvent); err != nil {
 		return err
 	}
-	if err := bus.Subscribe(connectionstate.AppTopicConnectionSession, repo.consumeConnectionSessionEvent); err != nil {
+	if err := connectionstate.SubscribeConnectionSession(bus, eventbus.DeliverSync, repo.consumeConnectionSessionEvent); err != nil {
 		return err
 	}
-	if err := bus.Subscribe(connectionstate.AppTopicConnectionStatistics, repo.consumeConnectionStatisticsEvent); err != nil {
+	if err := connectionstate.SubscribeConnectionStatistics(bus, eventbus.DeliverSync, repo.consumeConnectionStatisticsEvent); err != nil {
 		return err
 	}
-	return bus.Subscribe(pingpong_event.AppTopicInvoicePaid, repo.consumeConnectionSpendingEvent)
+	return pingpong_event.SubscribeInvoicePaid(bus, eventbus.DeliverSync, repo.consumeConnectionSpendingEvent)
 }
 
 // GetAll returns array of all sessions.
@@ -83,69 +81,56 @@ func (repo *Storage) GetAll() ([]History, error) {
 }
 
 // List retrieves stored entries.
-func (repo *Storage) List(filter *Filter) (result []History, err error) {
-	query := repo.storage.DB().
-		From(sessionStorageBucketName).
-		Select(filter.toMatcher()).
-		OrderBy("Started").
-		Reverse()
-
-	err = query.Find(&result)
-	if errors.Is(err, storm.ErrNotFound) {
-		return []History{}, nil
-	}
-
-	return result, err
+func (repo *Storage) List(filter *Filter) ([]History, error) {
+	return repo.repository.List(filter)
 }
 
 // Stats fetches aggregated statistics to Filter.Stats.
-func (repo *Storage) Stats(filter *Filter) (result Stats, err error) {
-	query := repo.storage.DB().
-		From(sessionStorageBucketName).
-		Select(filter.toMatcher()).
-		OrderBy("Started").
-		Reverse()
-
-	result = NewStats()
-	err = query.Each(new(History), func(record interface{}) error {
-		session := record.(*History)
-
-		result.Add(*session)
-
-		return nil
-	})
-	return result, err
+func (repo *Storage) Stats(filter *Filter) (Stats, error) {
+	return repo.repository.Stats(filter)
 }
 
 const stepDay = 24 * time.Hour
 
 // StatsByDay retrieves aggregated statistics grouped by day to Filter.StatsByDay.
-func (repo *Storage) StatsByDay(filter *Filter) (result map[time.Time]Stats, err error) {
-	query := repo.storage.DB().
-		From(sessionStorageBucketName).
-		Select(filter.toMatcher()).
-		OrderBy("Started").
-		Reverse()
+func (repo *Storage) StatsByDay(filter *Filter) (map[time.Time]Stats, error) {
+	result, err := repo.repository.StatsByDay(filter)
+	if err != nil {
+		return nil, err
+	}
 
 	// fill the period with zeros
-	result = make(map[time.Time]Stats)
 	if filter.StartedFrom != nil && filter.StartedTo != nil {
 		for i := filter.StartedFrom.Truncate(stepDay); !i.After(*filter.StartedTo); i = i.Add(stepDay) {
-			result[i] = NewStats()
+			if _, ok := result[i]; !ok {
+				result[i] = NewStats()
+			}
 		}
 	}
+	return result, nil
+}
 
-	err = query.Each(new(History), func(record interface{}) error {
-		session := record.(*History)
+// RollUp moves sessions started before the given time and all but keep most recent sessions into daily aggregates.
+// Zero before or keep disables the corresponding limit. Active sessions are never rolled up.
+func (repo *Storage) RollUp(before time.Time, keep int) (rolled int, err error) {
+	for {
+		n, err := repo.rollUpBatch(before.UTC(), keep)
+		rolled += n
+		if err != nil || n < rollUpBatchSize {
+			return rolled, err
+		}
+	}
+}
 
-		i := session.Started.Truncate(stepDay)
-		stats := result[i]
-		stats.Add(*session)
-		result[i] = stats
+func (repo *Storage) rollUpBatch(before time.Time, keep int) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
 
-		return nil
-	})
-	return result, err
+	active := make([]session_node.ID, 0, len(repo.sessionsActive))
+	for id := range repo.sessionsActive {
+		active = append(active, id)
+	}
+	return repo.repository.RollUp(before, keep, rollUpBatchSize, active)
 }
 
 // consumeServiceSessionEvent consumes the provided sessions.
@@ -155,6 +140,8 @@ func (repo *Storage) consumeServiceSessionEvent(e session_event.AppEventSession)
 	switch e.Status {
 	case session_event.RemovedStatus:
 		repo.handleEndedEvent(sessionID)
+	case session_event.UpdatedStatus:
+		repo.handleUpdatedEvent(sessionID, e.Session)
 	case session_event.CreatedStatus:
 		repo.mu.Lock()
 		repo.sessionsActive[sessionID] = History{
@@ -262,7 +249,7 @@ func (repo *Storage) consumeConnectionSpendingEvent(e pingpong_event.AppEventInv
 	row.Updated = repo.timeGetter().UTC()
 	row.Tokens = e.Invoice.AgreementTotal
 
-	err := repo.storage.Update(sessionStorageBucketName, &row)
+	err := repo.repository.Update(row)
 	if err != nil {
 		log.Error().Err(err).Msgf("Session %v update failed", sessionID)
 		return
@@ -284,7 +271,7 @@ func (repo *Storage) handleEndedEvent(sessionID session_node.ID) {
 	row.Updated = repo.timeGetter().UTC()
 	row.Status = StatusCompleted
 
-	err := repo.storage.Update(sessionStorageBucketName, &row)
+	err := repo.repository.Update(row)
 	if err != nil {
 		log.Error().Err(err).Msgf("Session %v update failed", sessionID)
 		return
@@ -294,6 +281,27 @@ func (repo *Storage) handleEndedEvent(sessionID session_node.ID) {
 	log.Debug().Msgf("Session %v updated with final data", sessionID)
 }
 
+func (repo *Storage) handleUpdatedEvent(sessionID session_node.ID, e session_event.SessionContext) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	row, ok := repo.sessionsActive[sessionID]
+	if !ok {
+		log.Warn().Msgf("Can't find session %v to update", sessionID)
+		return
+	}
+	row.EgressIP = e.EgressIP
+
+	err := repo.repository.Update(row)
+	if err != nil {
+		log.Error().Err(err).Msgf("Session %v update failed", row.SessionID)
+		return
+	}
+
+	repo.sessionsActive[sessionID] = row
+	log.Debug().Msgf("Session %v updated", row.SessionID)
+}
+
 func (repo *Storage) handleCreatedEvent(sessionID session_node.ID) {
 	repo.mu.Lock()
 	defer repo.mu.Unlock()
@@ -305,7 +313,7 @@ func (repo *Storage) handleCreatedEvent(sessionID session_node.ID) {
 	}
 	row.Status = StatusNew
 
-	err := repo.storage.Store(sessionStorageBucketName, &row)
+	err := repo.repository.Store(row)
 	if err != nil {
 		log.Error().Err(err).Msgf("Session %v insert failed", row.SessionID)
 		return
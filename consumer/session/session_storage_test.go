@@ -250,6 +250,87 @@ func TestSessionStorage_StatsByDay(t *testing.T) {
 	)
 }
 
+func TestSessionStorage_RollUp(t *testing.T) {
+	// given
+	newSession := func(id string, consumer string, started time.Time) History {
+		return History{
+			SessionID:    session_node.ID(id),
+			Direction:    DirectionProvided,
+			ConsumerID:   identity.FromAddress(consumer),
+			ServiceType:  "wireguard",
+			DataSent:     100,
+			DataReceived: 10,
+			Tokens:       big.NewInt(5),
+			Status:       StatusCompleted,
+			Started:      started,
+			Updated:      started.Add(time.Minute),
+		}
+	}
+	day1 := time.Date(2020, 6, 17, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2020, 6, 18, 10, 0, 0, 0, time.UTC)
+	active := newSession("active", "consumer1", day1.Add(-time.Hour))
+	storage, storageCleanup := newStorageWithSessions(
+		newSession("session1", "consumer1", day1),
+		newSession("session2", "consumer1", day1.Add(time.Hour)),
+		newSession("session3", "consumer2", day1.Add(2*time.Hour)),
+		newSession("session4", "consumer2", day2),
+		active,
+	)
+	defer storageCleanup()
+	storage.sessionsActive[active.SessionID] = active
+
+	filter := NewFilter().
+		SetStartedFrom(time.Date(2020, 6, 17, 0, 0, 0, 0, time.UTC)).
+		SetStartedTo(time.Date(2020, 6, 18, 0, 0, 0, 0, time.UTC))
+	statsExpected, err := storage.Stats(filter)
+	assert.NoError(t, err)
+	statsDailyExpected, err := storage.StatsByDay(filter)
+	assert.NoError(t, err)
+
+	// when
+	rolled, err := storage.RollUp(time.Date(2020, 6, 18, 0, 0, 0, 0, time.UTC), 0)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 3, rolled)
+	stats, err := storage.Stats(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, statsExpected, stats)
+	statsDaily, err := storage.StatsByDay(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, statsDailyExpected, statsDaily)
+
+	stats, err = storage.Stats(NewFilter().SetConsumerID(identity.FromAddress("consumer2")))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Count)
+	assert.Equal(t, uint64(200), stats.SumDataSent)
+
+	// when
+	rolled, err = storage.RollUp(time.Time{}, 1)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 0, rolled)
+	sessions, err := storage.GetAll()
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	// when
+	storage.sessionsActive = map[session_node.ID]History{}
+	rolled, err = storage.RollUp(time.Time{}, 1)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rolled)
+	sessions, err = storage.GetAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []session_node.ID{"session4"}, []session_node.ID{sessions[0].SessionID})
+	stats, err = storage.Stats(NewFilter())
+	assert.NoError(t, err)
+	assert.Equal(t, 5, stats.Count)
+	assert.Equal(t, 5*time.Minute, stats.SumDuration)
+}
+
 func TestSessionStorage_consumeServiceSessionsEvent(t *testing.T) {
 	// given
 	storage, storageCleanup := newStorage()
@@ -286,6 +367,12 @@ func TestSessionStorage_consumeServiceSessionsEvent(t *testing.T) {
 	)
 
 	// when
+	updatedSession := serviceSessionMock
+	updatedSession.EgressIP = "1.2.3.4"
+	storage.consumeServiceSessionEvent(session_event.AppEventSession{
+		Status:  session_event.UpdatedStatus,
+		Session: updatedSession,
+	})
 	storage.consumeServiceSessionStatisticsEvent(session_event.AppEventDataTransferred{
 		ID:   serviceSessionMock.ID,
 		Up:   123,
@@ -313,6 +400,7 @@ func TestSessionStorage_consumeServiceSessionsEvent(t *testing.T) {
 				ProviderID:      identity.FromAddress("providerID"),
 				ServiceType:     "serviceType",
 				ProviderCountry: "MU",
+				EgressIP:        "1.2.3.4",
 				Started:         time.Date(2020, 6, 17, 10, 11, 12, 0, time.UTC),
 				Status:          "Completed",
 				Updated:         time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC),
@@ -490,7 +578,7 @@ func newStorage() (*Storage, func()) {
 		panic(err)
 	}
 
-	return NewSessionStorage(db), func() {
+	return NewSessionStorage(NewBoltRepository(db)), func() {
 		err := db.Close()
 		if err != nil {
 			panic(err)
@@ -506,7 +594,7 @@ func newStorage() (*Storage, func()) {
 func newStorageWithSessions(sessions ...History) (*Storage, func()) {
 	storage, storageCleanup := newStorage()
 	for _, session := range sessions {
-		err := storage.storage.Store(sessionStorageBucketName, &session)
+		err := storage.repository.Store(session)
 		if err != nil {
 			panic(err)
 		}
@@ -57,3 +57,16 @@ func (s *Stats) Add(session History) {
 	s.SumDuration += session.GetDuration()
 	s.SumTokens = new(big.Int).Add(s.SumTokens, session.Tokens)
 }
+
+// AddAggregate accumulates given daily aggregate to statistics.
+func (s *Stats) AddAggregate(aggregate Aggregate) {
+	s.Count += aggregate.Count
+	s.ConsumerCounts[aggregate.ConsumerID] += aggregate.Count
+
+	s.SumDataReceived += aggregate.DataReceived
+	s.SumDataSent += aggregate.DataSent
+	s.SumDuration += aggregate.Duration
+	if aggregate.Tokens != nil {
+		s.SumTokens = new(big.Int).Add(s.SumTokens, aggregate.Tokens)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package accounting
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat"
+)
+
+func TestTrackerStoresSessionTraffic(t *testing.T) {
+	storage, cleanup := newTestStorage(t, Retention{})
+	defer cleanup()
+
+	policies := policy.NewRepository()
+	policies.SetPolicyRules(market.AccessPolicy{ID: "streaming"}, market.AccessPolicyRuleSet{
+		Allow: []market.AccessRule{{Type: market.AccessPolicyTypeDNSZone, Value: "video.com"}},
+	})
+	accountant := &mockTrafficAccountant{traffic: map[string]nat.TrafficCounter{"web": {Up: 10, Down: 100}}}
+	tracker := NewTracker(storage, policies, accountant)
+
+	_, network, _ := net.ParseCIDR("10.182.0.0/24")
+	tracker.StartSession("session1", "wireguard", *network)
+	tracker.RecordDNSQuery(net.ParseIP("10.182.0.2"), "cdn.video.com")
+	tracker.RecordDNSQuery(net.ParseIP("10.182.0.2"), "example.com")
+	tracker.RecordDNSQuery(net.ParseIP("10.182.0.2"), "example.org")
+	tracker.RecordDNSQuery(net.ParseIP("10.182.1.2"), "example.org")
+	tracker.EndSession("session1")
+
+	record, err := storage.Get("session1")
+	require.NoError(t, err)
+	assert.Equal(t, "wireguard", record.ServiceType)
+	assert.Equal(t, map[string]uint64{"streaming": 1, CategoryUncategorized: 2}, record.DNSQueries)
+	assert.Equal(t, accountant.traffic, record.Traffic)
+}
+
+func TestTrackerKeepsTrafficWhenCountersFail(t *testing.T) {
+	storage, cleanup := newTestStorage(t, Retention{})
+	defer cleanup()
+
+	accountant := &mockTrafficAccountant{traffic: map[string]nat.TrafficCounter{"mail": {Up: 1}}}
+	tracker := NewTracker(storage, nil, accountant)
+
+	_, network, _ := net.ParseCIDR("10.182.0.0/24")
+	tracker.StartSession("session1", "wireguard", *network)
+	tracker.flush()
+	accountant.err = errors.New("iptables failed")
+	tracker.EndSession("session1")
+
+	record, err := storage.Get("session1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]nat.TrafficCounter{"mail": {Up: 1}}, record.Traffic)
+}
+
+func TestTrackerRecordsDNSQueriesWhileSaving(t *testing.T) {
+	storage, cleanup := newTestStorage(t, Retention{})
+	defer cleanup()
+
+	accountant := &mockTrafficAccountant{reading: make(chan struct{}, 1), release: make(chan struct{})}
+	tracker := NewTracker(storage, nil, accountant)
+
+	_, network, _ := net.ParseCIDR("10.182.0.0/24")
+	tracker.StartSession("session1", "wireguard", *network)
+	flushed := make(chan struct{})
+	go func() {
+		tracker.flush()
+		close(flushed)
+	}()
+	<-accountant.reading
+	tracker.RecordDNSQuery(net.ParseIP("10.182.0.2"), "example.com")
+	close(accountant.release)
+	<-flushed
+	tracker.EndSession("session1")
+
+	record, err := storage.Get("session1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{CategoryUncategorized: 1}, record.DNSQueries)
+}
+
+func TestStorageRetention(t *testing.T) {
+	storage, cleanup := newTestStorage(t, Retention{MaxAge: 24 * time.Hour, MaxSessions: 2})
+	defer cleanup()
+
+	now := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	storage.timeGetter = func() time.Time { return now }
+
+	require.NoError(t, storage.Save(SessionTraffic{SessionID: "expired", Started: now.Add(-72 * time.Hour), Updated: now.Add(-48 * time.Hour)}))
+	require.NoError(t, storage.Save(SessionTraffic{SessionID: "oldest", Started: now.Add(-3 * time.Hour), Updated: now}))
+	require.NoError(t, storage.Save(SessionTraffic{SessionID: "older", Started: now.Add(-2 * time.Hour), Updated: now}))
+	require.NoError(t, storage.Save(SessionTraffic{SessionID: "newest", Started: now.Add(-1 * time.Hour), Updated: now}))
+
+	records, err := storage.List()
+	require.NoError(t, err)
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.SessionID)
+	}
+	assert.Equal(t, []string{"newest", "older"}, ids)
+}
+
+func newTestStorage(t *testing.T, retention Retention) (*Storage, func()) {
+	dir, err := ioutil.TempDir("", "accountingStorageTest")
+	require.NoError(t, err)
+	db, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+
+	return NewStorage(db, retention), func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+type mockTrafficAccountant struct {
+	traffic map[string]nat.TrafficCounter
+	err     error
+	reading chan struct{}
+	release chan struct{}
+}
+
+func (mta *mockTrafficAccountant) TrafficByPortClass(net.IPNet) (map[string]nat.TrafficCounter, error) {
+	if mta.reading != nil {
+		mta.reading <- struct{}{}
+		<-mta.release
+	}
+	return mta.traffic, mta.err
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package accounting
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/nat"
+)
+
+const bucketName = "traffic-accounting"
+
+// ErrNotFound is returned when the session has no traffic record.
+var ErrNotFound = errors.New("traffic accounting record not found")
+
+// SessionTraffic holds aggregated traffic of a service session.
+// Only counters are stored, hosts and addresses are never recorded.
+type SessionTraffic struct {
+	SessionID   string    `storm:"id" json:"session_id"`
+	ServiceType string    `json:"service_type"`
+	Started     time.Time `storm:"index" json:"started"`
+	Updated     time.Time `storm:"index" json:"updated"`
+	// DNSQueries counts DNS queries by access policy category.
+	DNSQueries map[string]uint64 `json:"dns_queries"`
+	// Traffic counts bytes by destination port class.
+	Traffic map[string]nat.TrafficCounter `json:"traffic"`
+}
+
+// Retention limits how much of accounting history is kept.
+type Retention struct {
+	MaxAge      time.Duration
+	MaxSessions int
+}
+
+// Storage stores session traffic records within retention limits.
+type Storage struct {
+	storage    storage.Storage
+	retention  Retention
+	timeGetter func() time.Time
+}
+
+// NewStorage creates traffic accounting storage.
+func NewStorage(storage storage.Storage, retention Retention) *Storage {
+	return &Storage{
+		storage:    storage,
+		retention:  retention,
+		timeGetter: time.Now,
+	}
+}
+
+// Save stores the record and removes records outside of retention limits.
+func (s *Storage) Save(record SessionTraffic) error {
+	if err := s.storage.Store(bucketName, &record); err != nil {
+		return err
+	}
+	return s.prune()
+}
+
+// List returns stored records, most recent sessions first.
+func (s *Storage) List() ([]SessionTraffic, error) {
+	records := []SessionTraffic{}
+	if err := s.storage.GetAllFrom(bucketName, &records); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Started.After(records[j].Started)
+	})
+	return records, nil
+}
+
+// Get returns the record of the given session.
+func (s *Storage) Get(sessionID string) (SessionTraffic, error) {
+	var record SessionTraffic
+	err := s.storage.GetOneByField(bucketName, "SessionID", sessionID, &record)
+	if errors.Is(err, storage.ErrNotFound) {
+		return record, ErrNotFound
+	}
+	return record, err
+}
+
+func (s *Storage) prune() error {
+	records, err := s.List()
+	if err != nil {
+		return err
+	}
+
+	var cutoff time.Time
+	if s.retention.MaxAge > 0 {
+		cutoff = s.timeGetter().Add(-s.retention.MaxAge)
+	}
+	for i := range records {
+		expired := s.retention.MaxSessions > 0 && i >= s.retention.MaxSessions
+		if !cutoff.IsZero() && records[i].Updated.Before(cutoff) {
+			expired = true
+		}
+		if !expired {
+			continue
+		}
+
+		if err := s.storage.Delete(bucketName, &records[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
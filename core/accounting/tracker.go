@@ -0,0 +1,194 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package accounting
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/nat"
+)
+
+// CategoryUncategorized is a DNS query category of hosts not matching any access policy.
+const CategoryUncategorized = "uncategorized"
+
+type activeSession struct {
+	record  SessionTraffic
+	network net.IPNet
+}
+
+// snapshot copies the session, so it can be saved without holding the tracker lock.
+func (s *activeSession) snapshot() activeSession {
+	record := s.record
+	record.DNSQueries = make(map[string]uint64, len(s.record.DNSQueries))
+	for category, count := range s.record.DNSQueries {
+		record.DNSQueries[category] = count
+	}
+	return activeSession{record: record, network: s.network}
+}
+
+// Tracker aggregates traffic of active service sessions and periodically stores it.
+type Tracker struct {
+	storage    *Storage
+	policies   *policy.Repository
+	accountant nat.TrafficAccountant
+	timeGetter func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*activeSession
+	// saveMu orders saves, so an older snapshot never overwrites a newer one.
+	saveMu sync.Mutex
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewTracker creates traffic tracker, accountant may be nil when NAT rules do not count traffic.
+func NewTracker(storage *Storage, policies *policy.Repository, accountant nat.TrafficAccountant) *Tracker {
+	return &Tracker{
+		storage:    storage,
+		policies:   policies,
+		accountant: accountant,
+		timeGetter: time.Now,
+		sessions:   make(map[string]*activeSession),
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start periodically stores traffic of active sessions.
+func (t *Tracker) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.flush()
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stores traffic of active sessions and stops periodic updates.
+func (t *Tracker) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.stop)
+		t.flush()
+	})
+}
+
+// StartSession starts accounting traffic of the session using given consumer network.
+func (t *Tracker) StartSession(sessionID, serviceType string, network net.IPNet) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.timeGetter()
+	t.sessions[sessionID] = &activeSession{
+		record: SessionTraffic{
+			SessionID:   sessionID,
+			ServiceType: serviceType,
+			Started:     now,
+			Updated:     now,
+			DNSQueries:  make(map[string]uint64),
+			Traffic:     make(map[string]nat.TrafficCounter),
+		},
+		network: network,
+	}
+}
+
+// EndSession stores final traffic of the session and stops accounting it.
+func (t *Tracker) EndSession(sessionID string) {
+	t.saveMu.Lock()
+	defer t.saveMu.Unlock()
+
+	t.mu.Lock()
+	session, ok := t.sessions[sessionID]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	delete(t.sessions, sessionID)
+	snapshot := session.snapshot()
+	t.mu.Unlock()
+
+	t.save(&snapshot)
+}
+
+// RecordDNSQuery counts DNS query of the session which owns the source address.
+func (t *Tracker) RecordDNSQuery(source net.IP, host string) {
+	category := CategoryUncategorized
+	if t.policies != nil {
+		if policy, ok := t.policies.PolicyForHost(host); ok {
+			category = policy.ID
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, session := range t.sessions {
+		if session.network.Contains(source) {
+			session.record.DNSQueries[category]++
+			return
+		}
+	}
+}
+
+func (t *Tracker) flush() {
+	t.saveMu.Lock()
+	defer t.saveMu.Unlock()
+
+	t.mu.Lock()
+	snapshots := make([]activeSession, 0, len(t.sessions))
+	for _, session := range t.sessions {
+		snapshots = append(snapshots, session.snapshot())
+	}
+	t.mu.Unlock()
+
+	for i := range snapshots {
+		t.save(&snapshots[i])
+	}
+}
+
+// save reads traffic counters and stores the session snapshot, it must not be called holding the tracker lock.
+func (t *Tracker) save(session *activeSession) {
+	if t.accountant != nil {
+		traffic, err := t.accountant.TrafficByPortClass(session.network)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not read traffic counters of session %s", session.record.SessionID)
+		} else {
+			session.record.Traffic = traffic
+			t.mu.Lock()
+			if active, ok := t.sessions[session.record.SessionID]; ok {
+				active.record.Traffic = traffic
+			}
+			t.mu.Unlock()
+		}
+	}
+
+	session.record.Updated = t.timeGetter()
+	if err := t.storage.Save(session.record); err != nil {
+		log.Error().Err(err).Msgf("Could not store traffic of session %s", session.record.SessionID)
+	}
+}
@@ -0,0 +1,257 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FileName is the name of the audit log file in the node's storage directory.
+const FileName = "audit.log"
+
+// RedactedFileName is the name of the redacted audit log copy added to feedback archives.
+const RedactedFileName = "audit-redacted.log"
+
+// Redacted replaces recorded values in the redacted audit log copy.
+const Redacted = "[redacted]"
+
+// UserLocal is recorded for actions not attributable to an authenticated tequilapi user.
+const UserLocal = "local"
+
+// Audited actions.
+const (
+	ActionConfigSet          = "config.set"
+	ActionConfigApply        = "config.apply"
+	ActionServiceStart       = "service.start"
+	ActionServiceUpdate      = "service.update"
+	ActionServiceStop        = "service.stop"
+	ActionIdentityCreate     = "identity.create"
+	ActionIdentityImport     = "identity.import"
+	ActionIdentityExport     = "identity.export"
+	ActionIdentityDelete     = "identity.delete"
+	ActionIdentityPassphrase = "identity.passphrase"
+	ActionIdentityRegister   = "identity.register"
+	ActionPayoutUpdate       = "payout.update"
+	ActionReferralUpdate     = "payout.referral"
+	ActionEmailUpdate        = "payout.email"
+	ActionSettle             = "settlement.settle"
+	ActionBeneficiary        = "settlement.beneficiary"
+	ActionStakeIncrease      = "settlement.stake-increase"
+	ActionStakeDecrease      = "settlement.stake-decrease"
+)
+
+// Entry is a single audit log record.
+type Entry struct {
+	Time   time.Time   `json:"time"`
+	User   string      `json:"user"`
+	Action string      `json:"action"`
+	Target string      `json:"target,omitempty"`
+	Old    interface{} `json:"old,omitempty"`
+	New    interface{} `json:"new,omitempty"`
+}
+
+// Redact returns a copy of the entry with old and new values masked,
+// as they may hold personal data such as emails, payout addresses or API keys.
+func (e Entry) Redact() Entry {
+	if e.Old != nil {
+		e.Old = Redacted
+	}
+	if e.New != nil {
+		e.New = Redacted
+	}
+	return e
+}
+
+// Filter narrows down audit log queries.
+type Filter struct {
+	// Action matches the action exactly or, when it ends with a dot, as a prefix.
+	Action string
+	User   string
+	From   *time.Time
+	To     *time.Time
+	// Limit caps the number of entries returned, 0 means no limit.
+	Limit int
+}
+
+func (f Filter) matches(e Entry) bool {
+	if f.Action != "" {
+		if strings.HasSuffix(f.Action, ".") {
+			if !strings.HasPrefix(e.Action, f.Action) {
+				return false
+			}
+		} else if e.Action != f.Action {
+			return false
+		}
+	}
+	if f.User != "" && e.User != f.User {
+		return false
+	}
+	if f.From != nil && e.Time.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && e.Time.After(*f.To) {
+		return false
+	}
+	return true
+}
+
+// Log is an append-only audit log stored as JSON lines.
+type Log struct {
+	path string
+	now  func() time.Time
+	mu   sync.Mutex
+}
+
+// NewLog creates an audit log backed by the given file.
+func NewLog(path string) *Log {
+	return &Log{path: path, now: time.Now}
+}
+
+// Path returns the location of the audit log file.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Dump writes a redacted copy of the log next to it and returns its location.
+// Only the redacted copy is meant to leave the node, e.g. in feedback archives.
+func (l *Log) Dump() (string, error) {
+	entries, err := l.Query(Filter{})
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	for i := len(entries) - 1; i >= 0; i-- {
+		line, err := json.Marshal(entries[i].Redact())
+		if err != nil {
+			return "", fmt.Errorf("could not encode audit entry: %w", err)
+		}
+		buf.Write(append(line, '\n'))
+	}
+
+	dumpPath := filepath.Join(filepath.Dir(l.path), RedactedFileName)
+	if err := ioutil.WriteFile(dumpPath, buf.Bytes(), 0600); err != nil {
+		return "", fmt.Errorf("could not write redacted audit log: %w", err)
+	}
+	return dumpPath, nil
+}
+
+// Record appends an entry attributed to the user found in the context.
+// Failures are logged rather than returned so that auditing never breaks the audited action.
+func (l *Log) Record(ctx context.Context, action, target string, old, new interface{}) {
+	entry := Entry{
+		Time:   l.now().UTC(),
+		User:   UserFrom(ctx),
+		Action: action,
+		Target: target,
+		Old:    old,
+		New:    new,
+	}
+	if err := l.Append(entry); err != nil {
+		log.Error().Err(err).Str("action", action).Msg("Failed to write audit log entry")
+	}
+}
+
+// Append writes the entry to the end of the log.
+func (l *Log) Append(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("could not encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("could not open audit log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("could not write audit log: %w", err)
+	}
+	return file.Sync()
+}
+
+// Query returns the entries matching the filter, newest first.
+func (l *Log) Query(filter Filter) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := []Entry{}
+	file, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			log.Warn().Err(err).Msg("Skipping malformed audit log line")
+			continue
+		}
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read audit log: %w", err)
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+type userKey struct{}
+
+// WithUser returns a context carrying the tequilapi user performing the action.
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFrom returns the user stored in the context, or UserLocal.
+func UserFrom(ctx context.Context) string {
+	if user, ok := ctx.Value(userKey{}).(string); ok && user != "" {
+		return user
+	}
+	return UserLocal
+}
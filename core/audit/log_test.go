@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package audit
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLog_RecordAndQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	log := NewLog(filepath.Join(dir, FileName))
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	log.now = func() time.Time { return now }
+
+	log.Record(context.Background(), ActionConfigSet, "payments.max-fee", "0.1", "0.2")
+	now = now.Add(time.Hour)
+	log.Record(WithUser(context.Background(), "myst"), ActionServiceStart, "wireguard", nil, map[string]string{"provider_id": "0x1"})
+	now = now.Add(time.Hour)
+	log.Record(WithUser(context.Background(), "myst"), ActionServiceStop, "wireguard", nil, nil)
+
+	entries, err := log.Query(Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, ActionServiceStop, entries[0].Action)
+	assert.Equal(t, Entry{
+		Time:   time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC),
+		User:   UserLocal,
+		Action: ActionConfigSet,
+		Target: "payments.max-fee",
+		Old:    "0.1",
+		New:    "0.2",
+	}, entries[2])
+
+	entries, err = log.Query(Filter{Action: "service."})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	entries, err = log.Query(Filter{User: UserLocal})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	from := time.Date(2020, 10, 1, 12, 30, 0, 0, time.UTC)
+	entries, err = log.Query(Filter{From: &from, Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, ActionServiceStop, entries[0].Action)
+
+	info, err := os.Stat(log.Path())
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestLog_QueryMissingFile(t *testing.T) {
+	entries, err := NewLog(filepath.Join(os.TempDir(), "no-such-dir", FileName)).Query(Filter{})
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestLog_DumpRedactsValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	log := NewLog(filepath.Join(dir, FileName))
+	log.Record(context.Background(), ActionConfigSet, "mmn.api-key", "old-key", "new-key")
+	log.Record(context.Background(), ActionEmailUpdate, "0x1", nil, map[string]string{"email": "user@example.com"})
+	log.Record(context.Background(), ActionServiceStop, "wireguard", nil, nil)
+
+	dumpPath, err := log.Dump()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, RedactedFileName), dumpPath)
+
+	dump, err := ioutil.ReadFile(dumpPath)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(dump), "old-key")
+	assert.NotContains(t, string(dump), "new-key")
+	assert.NotContains(t, string(dump), "user@example.com")
+
+	entries, err := NewLog(dumpPath).Query(Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, ActionConfigSet, entries[2].Action)
+	assert.Equal(t, "mmn.api-key", entries[2].Target)
+	assert.Equal(t, Redacted, entries[2].Old)
+	assert.Equal(t, Redacted, entries[2].New)
+	assert.Equal(t, Redacted, entries[1].New)
+	assert.Nil(t, entries[0].New)
+}
@@ -0,0 +1,358 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrator"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/rs/zerolog/log"
+)
+
+// ManifestVersion is the version of archive layout produced by this node.
+const ManifestVersion = 1
+
+// Names of the archive entries.
+const (
+	manifestName   = "manifest.json"
+	keystorePrefix = "keystore/"
+	databasePrefix = "storage/"
+	passwordName   = "data/nodeui-pass"
+	configName     = "config/config.toml"
+)
+
+const (
+	boltDatabaseFile = "myst.db"
+	passwordFile     = "nodeui-pass"
+	pendingRestore   = "restore"
+)
+
+var (
+	// ErrWrongPassphrase is returned when the archive can't be decrypted with the given passphrase.
+	ErrWrongPassphrase = errors.New("wrong backup passphrase")
+	// ErrInvalidArchive is returned for damaged or unknown archives.
+	ErrInvalidArchive = errors.New("invalid backup archive")
+	// ErrUnsupportedVersion is returned for archives created by a newer node.
+	ErrUnsupportedVersion = errors.New("unsupported backup version")
+	// ErrIdentityConflict is returned when node has identities which are missing in the backup.
+	ErrIdentityConflict = errors.New("node has identities which are not in the backup")
+	// ErrBackendMismatch is returned when the backup was created with a different storage backend.
+	ErrBackendMismatch = errors.New("backup was created with a different storage backend")
+)
+
+// Manifest describes the content of backup archive.
+type Manifest struct {
+	Version     int            `json:"version"`
+	NodeVersion string         `json:"node_version"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Identities  []string       `json:"identities"`
+	Files       []ManifestFile `json:"files"`
+}
+
+// ManifestFile describes single file of the backup.
+type ManifestFile struct {
+	Name   string `json:"name"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Options describes locations of the node state.
+type Options struct {
+	KeystoreDir string
+	StorageDir  string
+	// DatabaseFile is the database file name in StorageDir, defaults to boltdb one.
+	DatabaseFile string
+	DataDir      string
+	ConfigFile   string
+}
+
+type snapshotter interface {
+	Snapshot(w io.Writer) error
+}
+
+type identityLister interface {
+	GetIdentities() []identity.Identity
+}
+
+// Backup creates and restores encrypted archives of the node state.
+type Backup struct {
+	options    Options
+	db         snapshotter
+	identities identityLister
+}
+
+// NewBackup creates new Backup instance.
+func NewBackup(options Options, db snapshotter, identities identityLister) *Backup {
+	if options.DatabaseFile == "" {
+		options.DatabaseFile = boltDatabaseFile
+	}
+	return &Backup{
+		options:    options,
+		db:         db,
+		identities: identities,
+	}
+}
+
+// Create produces archive of the node state encrypted with the given passphrase.
+func (b *Backup) Create(passphrase string) ([]byte, error) {
+	var entries []entry
+
+	keyFiles, err := ioutil.ReadDir(b.options.KeystoreDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("could not read keystore: %w", err)
+	}
+	for _, file := range keyFiles {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(b.options.KeystoreDir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("could not read keystore: %w", err)
+		}
+		entries = append(entries, entry{name: keystorePrefix + file.Name(), data: data})
+	}
+
+	var db bytes.Buffer
+	if err := b.db.Snapshot(&db); err != nil {
+		return nil, fmt.Errorf("could not snapshot database: %w", err)
+	}
+	entries = append(entries, entry{name: databasePrefix + b.options.DatabaseFile, data: db.Bytes()})
+
+	optional := map[string]string{
+		passwordName: filepath.Join(b.options.DataDir, passwordFile),
+		configName:   b.options.ConfigFile,
+	}
+	for name, file := range optional {
+		if file == "" {
+			continue
+		}
+		data, err := ioutil.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", file, err)
+		}
+		entries = append(entries, entry{name: name, data: data})
+	}
+
+	manifest := Manifest{
+		Version:     ManifestVersion,
+		NodeVersion: metadata.VersionAsString(),
+		CreatedAt:   time.Now().UTC(),
+	}
+	for _, id := range b.identities.GetIdentities() {
+		manifest.Identities = append(manifest.Identities, strings.ToLower(id.Address))
+	}
+	for _, e := range entries {
+		manifest.Files = append(manifest.Files, ManifestFile{Name: e.name, Size: len(e.data), SHA256: checksum(e.data)})
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return seal(append([]entry{{name: manifestName, data: manifestJSON}}, entries...), passphrase)
+}
+
+// Restore verifies the archive and restores node state from it.
+// Identities of the node must be present in the backup, unless restore is forced.
+// Database is staged and replaces the current one on the next node start.
+func (b *Backup) Restore(archive []byte, passphrase string, force bool) (Manifest, error) {
+	entries, err := open(archive, passphrase)
+	if err != nil {
+		return Manifest{}, err
+	}
+	manifest, files, err := verify(entries)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	if conflicts := b.conflicts(manifest); len(conflicts) > 0 {
+		if !force {
+			return manifest, fmt.Errorf("%w: %s", ErrIdentityConflict, strings.Join(conflicts, ", "))
+		}
+		log.Warn().Msgf("Forcing restore, identities not in backup are kept: %s", strings.Join(conflicts, ", "))
+	}
+
+	data, ok := files[databasePrefix+b.options.DatabaseFile]
+	if !ok {
+		return manifest, ErrBackendMismatch
+	}
+	if err := b.stageDatabase(data); err != nil {
+		return manifest, err
+	}
+
+	if err := os.MkdirAll(b.options.KeystoreDir, 0700); err != nil {
+		return manifest, fmt.Errorf("could not restore keystore: %w", err)
+	}
+	for name, data := range files {
+		if !strings.HasPrefix(name, keystorePrefix) {
+			continue
+		}
+		file := filepath.Join(b.options.KeystoreDir, path.Base(name))
+		if _, err := os.Stat(file); err == nil {
+			continue
+		}
+		if err := writeFile(file, data); err != nil {
+			return manifest, fmt.Errorf("could not restore keystore: %w", err)
+		}
+	}
+
+	if data, ok := files[passwordName]; ok {
+		if err := writeFile(filepath.Join(b.options.DataDir, passwordFile), data); err != nil {
+			return manifest, fmt.Errorf("could not restore tequilapi password: %w", err)
+		}
+	}
+	if data, ok := files[configName]; ok && b.options.ConfigFile != "" {
+		if err := writeFile(b.options.ConfigFile, data); err != nil {
+			return manifest, fmt.Errorf("could not restore config: %w", err)
+		}
+	}
+
+	log.Info().Msgf("Node state restored from backup created at %s by node %s", manifest.CreatedAt, manifest.NodeVersion)
+	return manifest, nil
+}
+
+// stageDatabase migrates restored database and leaves it for ApplyPendingRestore.
+func (b *Backup) stageDatabase(data []byte) error {
+	dir := filepath.Join(b.options.StorageDir, pendingRestore)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("could not stage database: %w", err)
+	}
+	if err := writeFile(filepath.Join(dir, b.options.DatabaseFile), data); err != nil {
+		return fmt.Errorf("could not stage database: %w", err)
+	}
+	if b.options.DatabaseFile != boltDatabaseFile {
+		return nil
+	}
+
+	db, err := boltdb.NewStorage(dir)
+	if err != nil {
+		return fmt.Errorf("could not open restored database: %w", err)
+	}
+	defer db.Close()
+
+	if err := migrator.NewMigrator(db).RunMigrations(history.Sequence); err != nil {
+		return fmt.Errorf("could not migrate restored database: %w", err)
+	}
+	return nil
+}
+
+func (b *Backup) conflicts(manifest Manifest) []string {
+	inBackup := make(map[string]struct{}, len(manifest.Identities))
+	for _, id := range manifest.Identities {
+		inBackup[strings.ToLower(id)] = struct{}{}
+	}
+
+	var conflicts []string
+	for _, id := range b.identities.GetIdentities() {
+		if _, ok := inBackup[strings.ToLower(id.Address)]; !ok {
+			conflicts = append(conflicts, id.Address)
+		}
+	}
+	return conflicts
+}
+
+// ApplyPendingRestore replaces database with the restored one, must be called before the database is opened.
+func ApplyPendingRestore(storageDir string) error {
+	dir := filepath.Join(storageDir, pendingRestore)
+	staged, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not apply restored database: %w", err)
+	}
+
+	log.Info().Msg("Applying restored database")
+	for _, file := range staged {
+		if !file.Mode().IsRegular() || strings.HasSuffix(file.Name(), ".tmp") {
+			continue
+		}
+		if err := os.Rename(filepath.Join(dir, file.Name()), filepath.Join(storageDir, file.Name())); err != nil {
+			return fmt.Errorf("could not apply restored database: %w", err)
+		}
+	}
+	return os.RemoveAll(dir)
+}
+
+func verify(entries []entry) (Manifest, map[string][]byte, error) {
+	if len(entries) == 0 || entries[0].name != manifestName {
+		return Manifest{}, nil, fmt.Errorf("%w: manifest not found", ErrInvalidArchive)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(entries[0].data, &manifest); err != nil {
+		return Manifest{}, nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	if manifest.Version < 1 || manifest.Version > ManifestVersion {
+		return manifest, nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, manifest.Version)
+	}
+
+	files := make(map[string][]byte, len(entries)-1)
+	for _, e := range entries[1:] {
+		files[e.name] = e.data
+	}
+	for _, f := range manifest.Files {
+		data, ok := files[f.Name]
+		if !ok || len(data) != f.Size || checksum(data) != f.SHA256 {
+			return manifest, nil, fmt.Errorf("%w: %s is damaged", ErrInvalidArchive, f.Name)
+		}
+	}
+	if len(files) != len(manifest.Files) {
+		return manifest, nil, fmt.Errorf("%w: unexpected files", ErrInvalidArchive)
+	}
+	database := false
+	for name := range files {
+		database = database || strings.HasPrefix(name, databasePrefix)
+	}
+	if !database {
+		return manifest, nil, fmt.Errorf("%w: database not found", ErrInvalidArchive)
+	}
+	return manifest, files, nil
+}
+
+func writeFile(file string, data []byte) error {
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
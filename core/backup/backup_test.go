@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package backup
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type identityListerFake []identity.Identity
+
+func (il identityListerFake) GetIdentities() []identity.Identity {
+	return il
+}
+
+func newNodeDirs(t *testing.T) Options {
+	dir := boltdbtest.CreateTempDir(t)
+	options := Options{
+		KeystoreDir: filepath.Join(dir, "keystore"),
+		StorageDir:  filepath.Join(dir, "db"),
+		DataDir:     dir,
+		ConfigFile:  filepath.Join(dir, "config.toml"),
+	}
+	require.NoError(t, os.MkdirAll(options.KeystoreDir, 0700))
+	require.NoError(t, os.MkdirAll(options.StorageDir, 0700))
+	return options
+}
+
+func TestBackup_CreateAndRestore(t *testing.T) {
+	source := newNodeDirs(t)
+	defer boltdbtest.RemoveTempDir(t, source.DataDir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(source.KeystoreDir, "UTC--key-a"), []byte("key-a"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(source.DataDir, passwordFile), []byte("hash"), 0600))
+	require.NoError(t, ioutil.WriteFile(source.ConfigFile, []byte("[terms]"), 0600))
+
+	db, err := boltdb.NewStorage(source.StorageDir)
+	require.NoError(t, err)
+	require.NoError(t, db.SetValue("bucket", "key", "value"))
+	ids := identityListerFake{identity.FromAddress("0xAAA")}
+
+	archive, err := NewBackup(source, db, ids).Create("secret")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	target := newNodeDirs(t)
+	defer boltdbtest.RemoveTempDir(t, target.DataDir)
+
+	_, err = NewBackup(target, nil, ids).Restore(archive, "wrong", false)
+	assert.Equal(t, ErrWrongPassphrase, err)
+
+	manifest, err := NewBackup(target, nil, ids).Restore(archive, "secret", false)
+	require.NoError(t, err)
+	assert.Equal(t, ManifestVersion, manifest.Version)
+	assert.Equal(t, []string{"0xaaa"}, manifest.Identities)
+
+	key, err := ioutil.ReadFile(filepath.Join(target.KeystoreDir, "UTC--key-a"))
+	assert.NoError(t, err)
+	assert.Equal(t, "key-a", string(key))
+	config, err := ioutil.ReadFile(target.ConfigFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "[terms]", string(config))
+
+	require.NoError(t, ApplyPendingRestore(target.StorageDir))
+	restored, err := boltdb.NewStorage(target.StorageDir)
+	require.NoError(t, err)
+	defer restored.Close()
+	var value string
+	assert.NoError(t, restored.GetValue("bucket", "key", &value))
+	assert.Equal(t, "value", value)
+}
+
+func TestBackup_RestoreRefusesConflictingIdentities(t *testing.T) {
+	source := newNodeDirs(t)
+	defer boltdbtest.RemoveTempDir(t, source.DataDir)
+	db, err := boltdb.NewStorage(source.StorageDir)
+	require.NoError(t, err)
+	defer db.Close()
+
+	archive, err := NewBackup(source, db, identityListerFake{identity.FromAddress("0xaaa")}).Create("secret")
+	require.NoError(t, err)
+
+	target := newNodeDirs(t)
+	defer boltdbtest.RemoveTempDir(t, target.DataDir)
+	backup := NewBackup(target, nil, identityListerFake{identity.FromAddress("0xbbb")})
+
+	_, err = backup.Restore(archive, "secret", false)
+	assert.True(t, errors.Is(err, ErrIdentityConflict))
+	_, err = os.Stat(filepath.Join(target.StorageDir, pendingRestore))
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = backup.Restore(archive, "secret", true)
+	assert.NoError(t, err)
+}
+
+func TestBackup_RestoreRefusesOtherStorageBackend(t *testing.T) {
+	source := newNodeDirs(t)
+	defer boltdbtest.RemoveTempDir(t, source.DataDir)
+	db, err := boltdb.NewStorage(source.StorageDir)
+	require.NoError(t, err)
+	defer db.Close()
+	ids := identityListerFake{identity.FromAddress("0xaaa")}
+
+	archive, err := NewBackup(source, db, ids).Create("secret")
+	require.NoError(t, err)
+
+	target := newNodeDirs(t)
+	defer boltdbtest.RemoveTempDir(t, target.DataDir)
+	target.DatabaseFile = "myst.sqlite"
+
+	_, err = NewBackup(target, nil, ids).Restore(archive, "secret", false)
+	assert.Equal(t, ErrBackendMismatch, err)
+}
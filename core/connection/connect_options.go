@@ -33,6 +33,24 @@ type ConnectParams struct {
 	DisableKillSwitch bool
 	// DNS servers to use
 	DNS DNSOption
+	// SplitTunnel selects destinations routed through the tunnel
+	SplitTunnel SplitTunnel
+	// ProxyAddress enables userspace mode: instead of changing OS network configuration
+	// the connection exposes a local SOCKS5/HTTP proxy on this address
+	ProxyAddress string
+	// PortForwarding requests provider ports forwarded to the consumer
+	PortForwarding PortForwarding
+}
+
+// PortForwarding holds number of provider ports to forward to the consumer
+type PortForwarding struct {
+	TCP int
+	UDP int
+}
+
+// IsEnabled returns true if any port is requested.
+func (pf PortForwarding) IsEnabled() bool {
+	return pf.TCP > 0 || pf.UDP > 0
 }
 
 // ConnectOptions represents the params we need to ensure a successful connection
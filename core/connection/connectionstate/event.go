@@ -23,6 +23,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/session"
@@ -68,6 +69,8 @@ const (
 	StateConnectionFailed = State("ConnectionFailed")
 	// StateOnHold means that underlying connection failed, but manager keeps it not removed to prevent traffic leaks.
 	StateOnHold = State("OnHold")
+	// StateChannelMigrated means that p2p channel was moved to the new consumer network address keeping the same session.
+	StateChannelMigrated = State("ChannelMigrated")
 )
 
 // Status holds connection state, session id and proposal of the connection
@@ -79,6 +82,15 @@ type Status struct {
 	State            State
 	SessionID        session.ID
 	Proposal         market.ServiceProposal
+	ForwardedPorts   []ForwardedPort
+}
+
+// ForwardedPort describes provider's public port forwarded to the consumer
+type ForwardedPort struct {
+	Protocol     string
+	PublicIP     string
+	PublicPort   int
+	ConsumerPort int
 }
 
 // Duration returns elapsed time from marked session start
@@ -108,3 +120,38 @@ type AppEventConnectionStatistics struct {
 	Stats       Statistics
 	SessionInfo Status
 }
+
+// PublishConnectionState publishes connection state changes on the bus.
+func PublishConnectionState(publisher eventbus.Publisher, e AppEventConnectionState) {
+	eventbus.Handle(AppTopicConnectionState).Publish(publisher, e)
+}
+
+// SubscribeConnectionState subscribes fn to connection state changes.
+func SubscribeConnectionState(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventConnectionState)) error {
+	return eventbus.Handle(AppTopicConnectionState).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishConnectionStatistics publishes connection statistics on the bus.
+func PublishConnectionStatistics(publisher eventbus.Publisher, e AppEventConnectionStatistics) {
+	eventbus.Handle(AppTopicConnectionStatistics).Publish(publisher, e)
+}
+
+// SubscribeConnectionStatistics subscribes fn to connection statistics.
+func SubscribeConnectionStatistics(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventConnectionStatistics)) error {
+	return eventbus.Handle(AppTopicConnectionStatistics).Subscribe(subscriber, delivery, fn)
+}
+
+// UnsubscribeConnectionStatistics unsubscribes fn from connection statistics.
+func UnsubscribeConnectionStatistics(subscriber eventbus.Subscriber, fn func(AppEventConnectionStatistics)) error {
+	return eventbus.Handle(AppTopicConnectionStatistics).Unsubscribe(subscriber, fn)
+}
+
+// PublishConnectionSession publishes connection session events on the bus.
+func PublishConnectionSession(publisher eventbus.Publisher, e AppEventConnectionSession) {
+	eventbus.Handle(AppTopicConnectionSession).Publish(publisher, e)
+}
+
+// SubscribeConnectionSession subscribes fn to connection session events.
+func SubscribeConnectionSession(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventConnectionSession)) error {
+	return eventbus.Handle(AppTopicConnectionSession).Subscribe(subscriber, delivery, fn)
+}
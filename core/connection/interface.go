@@ -19,6 +19,7 @@ package connection
 
 import (
 	"context"
+	"net"
 
 	"github.com/ethereum/go-ethereum/common"
 
@@ -39,6 +40,19 @@ type Connection interface {
 	Statistics() (connectionstate.Statistics, error)
 }
 
+// ProxyConnection is implemented by connections which may expose a local proxy
+// instead of routing OS traffic. Kill switch is not applied to such connections.
+type ProxyConnection interface {
+	// ProxyAddr returns local proxy address or empty string when OS traffic is routed.
+	ProxyAddr() string
+}
+
+// ServiceConnMigrator is implemented by connections which can move their traffic
+// to the new p2p service conn after p2p channel migration.
+type ServiceConnMigrator interface {
+	MigrateServiceConn(conn *net.UDPConn) error
+}
+
 // StateChannel is the channel we receive state change events on
 type StateChannel chan connectionstate.State
 
@@ -20,6 +20,7 @@ package connection
 import (
 	"context"
 	"encoding/json"
+	stdErr "errors"
 	"fmt"
 	"sync"
 	"time"
@@ -63,6 +64,10 @@ var (
 	ErrInsufficientBalance = errors.New("insufficient balance")
 	// ErrUnlockRequired indicates that the consumer identity has not been unlocked yet
 	ErrUnlockRequired = errors.New("unlock required")
+	// ErrStaleProposal indicates that provider changed proposal and consumer should refresh it before connecting
+	ErrStaleProposal = errors.New("proposal is outdated, refresh proposals and connect again")
+
+	errServiceNotMigrated = errors.New("service conn is not migrated")
 )
 
 // IPCheckConfig contains common params for connection ip check.
@@ -109,6 +114,11 @@ type PaymentIssuer interface {
 	Stop()
 }
 
+// PortForwardingCharger is implemented by payment issuers which accept charges for forwarded ports.
+type PortForwardingCharger interface {
+	ChargeForwardedPorts(ports int)
+}
+
 type validator interface {
 	Validate(chainID int64, consumerID identity.Identity, proposal market.ServiceProposal) error
 }
@@ -129,6 +139,7 @@ type connectionManager struct {
 	config               Config
 	statsReportInterval  time.Duration
 	validator            validator
+	identities           identity.UsageTracker
 	p2pDialer            p2p.Dialer
 	timeGetter           TimeGetter
 
@@ -145,6 +156,9 @@ type connectionManager struct {
 	acknowledge            func()
 	cancel                 func()
 	channel                p2p.Channel
+	activeConnection       Connection
+	channelLock            sync.RWMutex
+	migrateLock            sync.Mutex
 
 	discoLock      sync.Mutex
 	connectOptions ConnectOptions
@@ -160,6 +174,7 @@ func NewManager(
 	config Config,
 	statsReportInterval time.Duration,
 	validator validator,
+	identities identity.UsageTracker,
 	p2pDialer p2p.Dialer,
 ) *connectionManager {
 	return &connectionManager{
@@ -174,6 +189,7 @@ func NewManager(
 		config:               config,
 		statsReportInterval:  statsReportInterval,
 		validator:            validator,
+		identities:           identities,
 		p2pDialer:            p2pDialer,
 		timeGetter:           time.Now,
 	}
@@ -208,6 +224,16 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		return err
 	}
 
+	// consumer identity can't be deleted until disconnect
+	release, err := m.identities.Use(consumerID.Address, "connection")
+	if err != nil {
+		return err
+	}
+	m.addCleanup(func() error {
+		release()
+		return nil
+	})
+
 	m.ctxLock.Lock()
 	m.ctx, m.cancel = context.WithCancel(context.Background())
 	m.ctxLock.Unlock()
@@ -237,7 +263,7 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		return err
 	}
 
-	sessionDTO, err := m.createP2PSession(m.currentCtx(), connection, m.channel, consumerID, hermesID, proposal, tracer)
+	sessionDTO, err := m.createP2PSession(m.currentCtx(), connection, m.channel, consumerID, hermesID, proposal, params.PortForwarding, tracer)
 	sessionID = session.ID(sessionDTO.GetID())
 	if err != nil {
 		m.sendSessionStatus(m.channel, consumerID, sessionID, connectivity.StatusSessionEstablishmentFailed, err)
@@ -246,11 +272,16 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 
 	traceStart := tracer.StartStage("Consumer session creation (start)")
 	go m.keepAliveLoop(m.channel, sessionID)
+	forwardedPorts := forwardedPortsFromResponse(sessionDTO)
 	m.setStatus(func(status *connectionstate.Status) {
 		status.SessionID = sessionID
+		status.ForwardedPorts = forwardedPorts
 	})
 	m.publishSessionCreate(sessionID)
 	paymentSession.SetSessionID(string(sessionID))
+	if charger, ok := paymentSession.(PortForwardingCharger); ok && len(forwardedPorts) > 0 {
+		charger.ChargeForwardedPorts(len(forwardedPorts))
+	}
 	tracer.EndStage(traceStart)
 
 	// Try to establish connection with peer.
@@ -412,7 +443,7 @@ func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, pr
 	trace := tracer.StartStage("Consumer P2P channel creation")
 	defer tracer.EndStage(trace)
 
-	contactDef, err := p2p.ParseContact(proposal.ProviderContacts)
+	contacts, err := p2p.ParseContacts(proposal.ProviderContacts)
 	if err != nil {
 		return fmt.Errorf("provider does not support p2p communication: %w", err)
 	}
@@ -421,7 +452,7 @@ func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, pr
 	defer cancel()
 
 	// TODO register all handlers before channel read/write loops
-	channel, err := m.p2pDialer.Dial(timeoutCtx, consumerID, providerID, proposal.ServiceType, contactDef, tracer)
+	channel, err := m.p2pDialer.Dial(timeoutCtx, consumerID, providerID, proposal.ServiceType, contacts, tracer)
 	if err != nil {
 		return fmt.Errorf("p2p dialer failed: %w", err)
 	}
@@ -432,7 +463,9 @@ func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, pr
 		return channel.Close()
 	})
 
+	m.channelLock.Lock()
 	m.channel = channel
+	m.channelLock.Unlock()
 	return nil
 }
 
@@ -448,7 +481,7 @@ func (m *connectionManager) addCleanup(fn func() error) {
 	m.cleanup = append(m.cleanup, fn)
 }
 
-func (m *connectionManager) createP2PSession(ctx context.Context, c Connection, p2pChannel p2p.ChannelSender, consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, tracer *trace.Tracer) (*pb.SessionResponse, error) {
+func (m *connectionManager) createP2PSession(ctx context.Context, c Connection, p2pChannel p2p.ChannelSender, consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, portForwarding PortForwarding, tracer *trace.Tracer) (*pb.SessionResponse, error) {
 	trace := tracer.StartStage("Consumer session creation")
 	defer tracer.EndStage(trace)
 
@@ -474,10 +507,19 @@ func (m *connectionManager) createP2PSession(ctx context.Context, c Connection,
 		ProposalID: int64(proposal.ID),
 		Config:     config,
 	}
+	if portForwarding.IsEnabled() {
+		sessionRequest.PortForwarding = &pb.PortForwardingRequest{
+			Tcp: uint32(portForwarding.TCP),
+			Udp: uint32(portForwarding.UDP),
+		}
+	}
 	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionCreate, sessionRequest.String())
 	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 	res, err := p2pChannel.Send(ctx, p2p.TopicSessionCreate, p2p.ProtoMessage(sessionRequest))
+	if err != nil && stdErr.Is(err, market.ErrProposalOutdated) {
+		return nil, fmt.Errorf("provider rejected proposal %d: %w", proposal.ID, ErrStaleProposal)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("could not send p2p session create request: %w", err)
 	}
@@ -525,8 +567,21 @@ func (m *connectionManager) createP2PSession(ctx context.Context, c Connection,
 	return &sessionResponse, nil
 }
 
+func forwardedPortsFromResponse(response *pb.SessionResponse) []connectionstate.ForwardedPort {
+	var ports []connectionstate.ForwardedPort
+	for _, port := range response.GetForwardedPorts() {
+		ports = append(ports, connectionstate.ForwardedPort{
+			Protocol:     port.GetProtocol(),
+			PublicIP:     port.GetPublicIP(),
+			PublicPort:   int(port.GetPublicPort()),
+			ConsumerPort: int(port.GetConsumerPort()),
+		})
+	}
+	return ports
+}
+
 func (m *connectionManager) publishSessionCreate(sessionID session.ID) {
-	m.eventBus.Publish(connectionstate.AppTopicConnectionSession, connectionstate.AppEventConnectionSession{
+	connectionstate.PublishConnectionSession(m.eventBus, connectionstate.AppEventConnectionSession{
 		Status:      connectionstate.SessionCreatedStatus,
 		SessionInfo: m.Status(),
 	})
@@ -534,7 +589,7 @@ func (m *connectionManager) publishSessionCreate(sessionID session.ID) {
 	m.addCleanup(func() error {
 		log.Trace().Msg("Cleaning: publishing session ended status")
 		defer log.Trace().Msg("Cleaning: publishing session ended status DONE")
-		m.eventBus.Publish(connectionstate.AppTopicConnectionSession, connectionstate.AppEventConnectionSession{
+		connectionstate.PublishConnectionSession(m.eventBus, connectionstate.AppEventConnectionSession{
 			Status:      connectionstate.SessionEndedStatus,
 			SessionInfo: m.Status(),
 		})
@@ -551,14 +606,25 @@ func (m *connectionManager) startConnection(ctx context.Context, conn Connection
 	if err = conn.Start(ctx, connectOptions); err != nil {
 		return err
 	}
+	m.channelLock.Lock()
+	m.activeConnection = conn
+	m.channelLock.Unlock()
 	m.addCleanup(func() error {
 		log.Trace().Msg("Cleaning: stopping connection")
 		defer log.Trace().Msg("Cleaning: stopping connection DONE")
 		conn.Stop()
+		m.channelLock.Lock()
+		m.activeConnection = nil
+		m.channelLock.Unlock()
 		return nil
 	})
 
-	err = m.setupTrafficBlock(connectOptions.Params.DisableKillSwitch)
+	disableKillSwitch := connectOptions.Params.DisableKillSwitch
+	if proxy, ok := conn.(ProxyConnection); ok && proxy.ProxyAddr() != "" {
+		log.Info().Msgf("Connection is exposed as a local proxy on %s, skipping kill switch", proxy.ProxyAddr())
+		disableKillSwitch = true
+	}
+	err = m.setupTrafficBlock(disableKillSwitch)
 	if err != nil {
 		return err
 	}
@@ -770,7 +836,7 @@ func (m *connectionManager) setupTrafficBlock(disableKillSwitch bool) error {
 }
 
 func (m *connectionManager) publishStateEvent(state connectionstate.State) {
-	m.eventBus.Publish(connectionstate.AppTopicConnectionState, connectionstate.AppEventConnectionState{
+	connectionstate.PublishConnectionState(m.eventBus, connectionstate.AppEventConnectionState{
 		State:       state,
 		SessionInfo: m.Status(),
 	})
@@ -788,8 +854,10 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 		return c.OK()
 	})
 
-	// Send pings to provider.
+	// Send pings to provider. Channel is migrated once per failure streak, migration
+	// does not reset err count so session is still closed if provider stays unreachable.
 	var errCount int
+	var migrated bool
 	for {
 		select {
 		case <-m.currentCtx().Done():
@@ -800,6 +868,20 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 			if err := m.sendKeepAlivePing(ctx, channel, sessionID); err != nil {
 				log.Err(err).Msgf("Failed to send p2p keepalive ping. SessionID=%s", sessionID)
 				errCount++
+				if !migrated {
+					migrated = true
+					err := m.migrateChannel()
+					if stdErr.Is(err, errServiceNotMigrated) {
+						// Channel is moved already, service traffic can be restored only by a new session.
+						log.Warn().Err(err).Msgf("Failed to migrate session service, creating new session. SessionID=%s", sessionID)
+						cancel()
+						go m.reconnect()
+						return
+					}
+					if err != nil {
+						log.Warn().Err(err).Msgf("Failed to migrate p2p channel. SessionID=%s", sessionID)
+					}
+				}
 				if errCount == m.config.KeepAlive.MaxSendErrCount {
 					log.Error().Msgf("Max p2p keepalive err count reached, disconnecting. SessionID=%s", sessionID)
 					if config.GetBool(config.FlagKeepConnectedOnFail) {
@@ -812,6 +894,7 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 				}
 			} else {
 				errCount = 0
+				migrated = false
 			}
 			cancel()
 		}
@@ -829,7 +912,7 @@ func (m *connectionManager) sendKeepAlivePing(ctx context.Context, channel p2p.C
 		return err
 	}
 
-	m.eventBus.Publish(quality.AppTopicConsumerPingP2P, quality.PingEvent{
+	quality.PublishConsumerPingP2P(m.eventBus, quality.PingEvent{
 		SessionID: string(sessionID),
 		Duration:  time.Now().Sub(start),
 	})
@@ -844,7 +927,78 @@ func (m *connectionManager) currentCtx() context.Context {
 	return m.ctx
 }
 
+// migrateChannel re-punches p2p channel and service conn from the current consumer network
+// address so established session keeps working after consumer network change.
+// Provider and consumer services are moved to the new service conn before reporting success.
+func (m *connectionManager) migrateChannel() error {
+	m.migrateLock.Lock()
+	defer m.migrateLock.Unlock()
+
+	m.channelLock.RLock()
+	channel, conn := m.channel, m.activeConnection
+	m.channelLock.RUnlock()
+
+	status := m.Status()
+	if channel == nil || conn == nil || status.State != connectionstate.Connected {
+		return ErrNoConnection
+	}
+	migrator, ok := conn.(ServiceConnMigrator)
+	if !ok {
+		return fmt.Errorf("connection of service %s does not support migration", status.Proposal.ServiceType)
+	}
+
+	contacts, err := p2p.ParseContacts(status.Proposal.ProviderContacts)
+	if err != nil {
+		return fmt.Errorf("provider does not support p2p communication: %w", err)
+	}
+
+	// Consumer public IP might be changed together with network.
+	m.clearIPCache()
+
+	tracer := trace.NewTracer("Consumer whole Migrate")
+	defer func() {
+		traceResult := tracer.Finish(m.eventBus, string(status.SessionID))
+		log.Debug().Msgf("Consumer migrate trace: %s", traceResult)
+	}()
+
+	ctx, cancel := context.WithTimeout(m.currentCtx(), p2pDialTimeout)
+	defer cancel()
+
+	providerID := identity.FromAddress(status.Proposal.ProviderID)
+	if err := m.p2pDialer.Migrate(ctx, channel, status.ConsumerID, providerID, status.Proposal.ServiceType, contacts, tracer); err != nil {
+		return fmt.Errorf("p2p dialer failed to migrate channel: %w", err)
+	}
+
+	si := &pb.SessionInfo{
+		ConsumerID: status.ConsumerID.Address,
+		SessionID:  string(status.SessionID),
+	}
+	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionMigrate, si.String())
+	if _, err := channel.Send(ctx, p2p.TopicSessionMigrate, p2p.ProtoMessage(si)); err != nil {
+		return fmt.Errorf("provider failed: %v: %w", err, errServiceNotMigrated)
+	}
+	if err := migrator.MigrateServiceConn(channel.ServiceConn()); err != nil {
+		return fmt.Errorf("consumer failed: %v: %w", err, errServiceNotMigrated)
+	}
+
+	log.Info().Msgf("P2P channel migrated. SessionID=%s", status.SessionID)
+	m.publishStateEvent(connectionstate.StateChannelMigrated)
+	return nil
+}
+
+// Reconnect reconnects current session. It tries to migrate p2p channel keeping
+// the same session first and falls back to the new session if migration fails.
 func (m *connectionManager) Reconnect() {
+	err := m.migrateChannel()
+	if err == nil {
+		return
+	}
+	log.Warn().Err(err).Msg("Failed to migrate p2p channel, creating new session")
+	m.reconnect()
+}
+
+// reconnect replaces current session with the new one.
+func (m *connectionManager) reconnect() {
 	err := m.Disconnect()
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed to disconnect stale session")
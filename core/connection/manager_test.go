@@ -64,6 +64,7 @@ type testContext struct {
 	statsReportInterval   time.Duration
 	mockP2P               *mockP2PDialer
 	mockTime              time.Time
+	identityUsage         *mockIdentityUsage
 	sync.RWMutex
 }
 
@@ -132,8 +133,9 @@ func (tc *testContext) SetupTest() {
 	brokerConn := nats.StartConnectionMock()
 	brokerConn.MockResponse("fake-node-1.p2p-config-exchange", []byte("123"))
 
-	tc.mockP2P = &mockP2PDialer{&mockP2PChannel{}}
+	tc.mockP2P = &mockP2PDialer{ch: &mockP2PChannel{}, migrateErr: errors.New("migration is not supported")}
 	tc.mockTime = time.Date(2000, time.January, 0, 10, 12, 3, 0, time.UTC)
+	tc.identityUsage = &mockIdentityUsage{}
 
 	tc.connManager = NewManager(
 		func(channel p2p.Channel,
@@ -151,6 +153,7 @@ func (tc *testContext) SetupTest() {
 		tc.config,
 		tc.statsReportInterval,
 		&mockValidator{},
+		tc.identityUsage,
 		tc.mockP2P,
 	)
 	tc.connManager.timeGetter = func() time.Time {
@@ -180,6 +183,14 @@ func (tc *testContext) TestOnConnectErrorStatusIsNotConnected() {
 	)
 }
 
+func (tc *testContext) TestConnectFailsWithStaleProposal() {
+	tc.mockP2P.ch.sessionCreateErr = fmt.Errorf("public peer error: %w", market.ErrProposalOutdated)
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{})
+
+	assert.True(tc.T(), errors.Is(err, ErrStaleProposal))
+}
+
 func (tc *testContext) TestWhenManagerMadeConnectionStatusReturnsConnectedStateAndSessionId() {
 	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{})
 	assert.NoError(tc.T(), err)
@@ -198,6 +209,19 @@ func (tc *testContext) TestWhenManagerMadeConnectionStatusReturnsConnectedStateA
 	)
 }
 
+func (tc *testContext) TestConnectReportsForwardedPorts() {
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{PortForwarding: PortForwarding{TCP: 2}})
+	assert.NoError(tc.T(), err)
+	assert.Equal(
+		tc.T(),
+		[]connectionstate.ForwardedPort{
+			{Protocol: "tcp", PublicIP: "1.2.3.4", PublicPort: 40000, ConsumerPort: 40000},
+			{Protocol: "tcp", PublicIP: "1.2.3.4", PublicPort: 40001, ConsumerPort: 40001},
+		},
+		tc.connManager.Status().ForwardedPorts,
+	)
+}
+
 func (tc *testContext) TestSessionDoesFullReconnectOnWakeupEvent() {
 	tc.connManager.eventBus = eventbus.New()
 
@@ -221,7 +245,7 @@ func (tc *testContext) TestSessionDoesFullReconnectOnWakeupEvent() {
 	)
 
 	stateCh := make(chan connectionstate.State, 2)
-	tc.connManager.eventBus.Subscribe(connectionstate.AppTopicConnectionState, func(e connectionstate.AppEventConnectionState) {
+	connectionstate.SubscribeConnectionState(tc.connManager.eventBus, eventbus.DeliverSync, func(e connectionstate.AppEventConnectionState) {
 		fmt.Println("got state: ", e)
 
 		if e.State == connectionstate.Connecting {
@@ -235,12 +259,72 @@ func (tc *testContext) TestSessionDoesFullReconnectOnWakeupEvent() {
 	})
 
 	fmt.Println("sending wakeup event")
-	tc.connManager.eventBus.Publish(sleep.AppTopicSleepNotification, sleep.EventWakeup)
+	sleep.PublishSleepNotification(tc.connManager.eventBus, sleep.EventWakeup)
 
 	assert.Equal(tc.T(), <-stateCh, connectionstate.Connecting)
 	assert.Equal(tc.T(), <-stateCh, connectionstate.Connected)
 }
 
+func (tc *testContext) TestSessionMigratesChannelOnWakeupEvent() {
+	tc.connManager.eventBus = eventbus.New()
+	tc.mockP2P.migrateErr = nil
+
+	sleepNotifier := sleep.NewNotifier(tc.connManager, tc.connManager.eventBus)
+	sleepNotifier.Subscribe()
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	stateCh := make(chan connectionstate.State, 10)
+	connectionstate.SubscribeConnectionState(tc.connManager.eventBus, eventbus.DeliverSync, func(e connectionstate.AppEventConnectionState) {
+		stateCh <- e.State
+	})
+
+	sleep.PublishSleepNotification(tc.connManager.eventBus, sleep.EventWakeup)
+
+	assert.Equal(tc.T(), connectionstate.StateChannelMigrated, <-stateCh)
+	assert.Equal(tc.T(), connectionstate.Connected, tc.connManager.Status().State)
+	assert.Equal(tc.T(), establishedSessionID, tc.connManager.Status().SessionID)
+}
+
+func (tc *testContext) TestKeepAliveDisconnectsWhenPingsFailAfterMigration() {
+	tc.mockP2P.migrateErr = nil
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	assert.Eventually(tc.T(), func() bool {
+		return tc.connManager.Status().State == connectionstate.NotConnected
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func (tc *testContext) TestSessionReconnectsOnWakeupEventWhenProviderFailsToMigrateService() {
+	tc.connManager.eventBus = eventbus.New()
+	tc.mockP2P.migrateErr = nil
+	tc.mockP2P.ch.sessionMigrateErr = errors.New("service does not support session migration")
+
+	sleepNotifier := sleep.NewNotifier(tc.connManager, tc.connManager.eventBus)
+	sleepNotifier.Subscribe()
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	stateCh := make(chan connectionstate.State, 10)
+	connectionstate.SubscribeConnectionState(tc.connManager.eventBus, eventbus.DeliverSync, func(e connectionstate.AppEventConnectionState) {
+		stateCh <- e.State
+	})
+
+	sleep.PublishSleepNotification(tc.connManager.eventBus, sleep.EventWakeup)
+
+	for state := range stateCh {
+		assert.NotEqual(tc.T(), connectionstate.StateChannelMigrated, state)
+		if state == connectionstate.Connected {
+			break
+		}
+	}
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
 func (tc *testContext) TestStatusReportsConnectingWhenConnectionIsInProgress() {
 	tc.fakeConnectionFactory.mockConnection.onStartReportStates = []fakeState{}
 
@@ -371,6 +455,22 @@ func (tc *testContext) TestTwoConnectDisconnectCyclesReturnNoError() {
 func (tc *testContext) TestConnectFailsIfConnectionFactoryReturnsError() {
 	tc.fakeConnectionFactory.mockError = errors.New("failed to create connection instance")
 	assert.Error(tc.T(), tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{}))
+	assert.Equal(tc.T(), 0, tc.identityUsage.users())
+}
+
+func (tc *testContext) TestConsumerIdentityIsUsedUntilDisconnect() {
+	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{}))
+	assert.Equal(tc.T(), 1, tc.identityUsage.users())
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	waitABit()
+	assert.Equal(tc.T(), 0, tc.identityUsage.users())
+}
+
+func (tc *testContext) TestConnectFailsIfIdentityCantBeUsed() {
+	tc.identityUsage.err = errors.New("identity not found")
+	assert.EqualError(tc.T(), tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{}), "identity not found")
+	assert.Equal(tc.T(), connectionstate.NotConnected, tc.connManager.Status().State)
 }
 
 func (tc *testContext) TestStatusIsConnectedWhenConnectCommandReturnsWithoutError() {
@@ -678,16 +778,23 @@ func (mpm *mockPaymentMethod) GetRate() market.PaymentRate {
 }
 
 type mockP2PDialer struct {
-	ch *mockP2PChannel
+	ch         *mockP2PChannel
+	migrateErr error
 }
 
-func (m mockP2PDialer) Dial(ctx context.Context, consumerID identity.Identity, providerID identity.Identity, serviceType string, contactDef p2p.ContactDefinition, tracer *trace.Tracer) (p2p.Channel, error) {
+func (m mockP2PDialer) Dial(ctx context.Context, consumerID identity.Identity, providerID identity.Identity, serviceType string, contacts market.ContactList, tracer *trace.Tracer) (p2p.Channel, error) {
 	return m.ch, nil
 }
 
+func (m mockP2PDialer) Migrate(ctx context.Context, ch p2p.Channel, consumerID identity.Identity, providerID identity.Identity, serviceType string, contacts market.ContactList, tracer *trace.Tracer) error {
+	return m.migrateErr
+}
+
 type mockP2PChannel struct {
-	status proto.Message
-	lock   sync.Mutex
+	status            proto.Message
+	sessionCreateErr  error
+	sessionMigrateErr error
+	lock              sync.Mutex
 }
 
 func (m *mockP2PChannel) Conn() *net.UDPConn {
@@ -703,9 +810,17 @@ func (m *mockP2PChannel) getSentMsg() proto.Message {
 func (m *mockP2PChannel) Send(_ context.Context, topic string, msg *p2p.Message) (*p2p.Message, error) {
 	switch topic {
 	case p2p.TopicSessionCreate:
+		if m.sessionCreateErr != nil {
+			return nil, m.sessionCreateErr
+		}
 		res := &pb.SessionResponse{
 			ID: string(establishedSessionID),
 		}
+		var req pb.SessionRequest
+		msg.UnmarshalProto(&req)
+		for i := uint32(0); i < req.GetPortForwarding().GetTcp(); i++ {
+			res.ForwardedPorts = append(res.ForwardedPorts, &pb.ForwardedPort{Protocol: "tcp", PublicIP: "1.2.3.4", PublicPort: 40000 + i, ConsumerPort: 40000 + i})
+		}
 		return p2p.ProtoMessage(res), nil
 	case p2p.TopicSessionStatus:
 		m.lock.Lock()
@@ -716,6 +831,8 @@ func (m *mockP2PChannel) Send(_ context.Context, topic string, msg *p2p.Message)
 		return nil, nil
 	case p2p.TopicSessionAcknowledge:
 		return nil, nil
+	case p2p.TopicSessionMigrate:
+		return nil, m.sessionMigrateErr
 	}
 
 	return nil, errors.New("unexpected error")
@@ -746,6 +863,35 @@ func (mv *mockValidator) Validate(chainID int64, consumerID identity.Identity, p
 	return mv.errorToReturn
 }
 
+type mockIdentityUsage struct {
+	mu    sync.Mutex
+	count int
+	err   error
+}
+
+func (mu *mockIdentityUsage) Use(_, _ string) (func(), error) {
+	mu.mu.Lock()
+	defer mu.mu.Unlock()
+	if mu.err != nil {
+		return nil, mu.err
+	}
+	mu.count++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			mu.mu.Lock()
+			defer mu.mu.Unlock()
+			mu.count--
+		})
+	}, nil
+}
+
+func (mu *mockIdentityUsage) users() int {
+	mu.mu.Lock()
+	defer mu.mu.Unlock()
+	return mu.count
+}
+
 type mockLocationResolver struct{}
 
 func (mlr *mockLocationResolver) GetOrigin() locationstate.Location {
@@ -0,0 +1,41 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateProxyAddress(t *testing.T) {
+	for address, valid := range map[string]bool{
+		"127.0.0.1:1080": true,
+		"[::1]:1080":     true,
+		"localhost:1080": true,
+		"0.0.0.0:1080":   false,
+		":1080":          false,
+		"[::]:1080":      false,
+		"10.0.0.5:1080":  false,
+		"example.com:80": false,
+		"127.0.0.1":      false,
+	} {
+		err := ValidateProxyAddress(address)
+		assert.Equal(t, valid, err == nil, address)
+	}
+}
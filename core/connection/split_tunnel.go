@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/utils/netutil"
+)
+
+// SplitTunnel selects destinations which are routed through the VPN tunnel.
+// When nothing is included all traffic except the excluded destinations goes through the tunnel.
+type SplitTunnel struct {
+	IncludeCIDRs   []string `json:"include_cidrs,omitempty"`
+	ExcludeCIDRs   []string `json:"exclude_cidrs,omitempty"`
+	IncludeDomains []string `json:"include_domains,omitempty"`
+	ExcludeDomains []string `json:"exclude_domains,omitempty"`
+}
+
+// IsEnabled returns true if any of split tunnel lists is set.
+func (s SplitTunnel) IsEnabled() bool {
+	return len(s.IncludeCIDRs) > 0 || len(s.ExcludeCIDRs) > 0 || len(s.IncludeDomains) > 0 || len(s.ExcludeDomains) > 0
+}
+
+// HasDomains returns true if any of split tunnel lists contains domains.
+func (s SplitTunnel) HasDomains() bool {
+	return len(s.IncludeDomains) > 0 || len(s.ExcludeDomains) > 0
+}
+
+// Validate checks that networks and domains are well formed.
+func (s SplitTunnel) Validate() error {
+	for _, cidr := range append(append([]string{}, s.IncludeCIDRs...), s.ExcludeCIDRs...) {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid CIDR %q", cidr)
+		}
+	}
+	for _, domain := range append(append([]string{}, s.IncludeDomains...), s.ExcludeDomains...) {
+		if !isDomain(domain) {
+			return fmt.Errorf("invalid domain %q", domain)
+		}
+	}
+	return nil
+}
+
+// Resolve converts split tunnel lists to networks, domains are resolved to host networks using the lookup function.
+func (s SplitTunnel) Resolve(lookup func(host string) ([]net.IP, error)) (SplitRoutes, error) {
+	var routes SplitRoutes
+	var err error
+	if routes.Include, err = resolveNetworks(s.IncludeCIDRs, s.IncludeDomains, lookup); err != nil {
+		return SplitRoutes{}, err
+	}
+	if routes.Exclude, err = resolveNetworks(s.ExcludeCIDRs, s.ExcludeDomains, lookup); err != nil {
+		return SplitRoutes{}, err
+	}
+	return routes, nil
+}
+
+// SplitRoutes holds networks of the resolved split tunnel.
+type SplitRoutes struct {
+	Include []net.IPNet
+	Exclude []net.IPNet
+}
+
+// TunnelNetworks returns networks which are routed through the tunnel.
+// Whole address space is split into halves, so tunnel routes take precedence
+// over the default routes instead of clashing with them.
+func (r SplitRoutes) TunnelNetworks() []net.IPNet {
+	include := r.Include
+	if len(include) == 0 {
+		include = []net.IPNet{
+			{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(1, 8*net.IPv4len)},
+			{IP: net.IPv4(128, 0, 0, 0).To4(), Mask: net.CIDRMask(1, 8*net.IPv4len)},
+			{IP: net.IPv6zero, Mask: net.CIDRMask(1, 8*net.IPv6len)},
+			{IP: net.ParseIP("8000::"), Mask: net.CIDRMask(1, 8*net.IPv6len)},
+		}
+	}
+
+	var networks []net.IPNet
+	for _, network := range include {
+		networks = append(networks, netutil.SubtractNetworks(network, r.Exclude)...)
+	}
+	return networks
+}
+
+// BypassNetworks returns IPv4 and IPv6 networks which are reached outside of the tunnel.
+func (r SplitRoutes) BypassNetworks() []net.IPNet {
+	tunnel := r.TunnelNetworks()
+	return append(
+		netutil.SubtractNetworks(net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 8*net.IPv4len)}, tunnel),
+		netutil.SubtractNetworks(net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 8*net.IPv6len)}, tunnel)...,
+	)
+}
+
+func resolveNetworks(cidrs, domains []string, lookup func(host string) ([]net.IP, error)) ([]net.IPNet, error) {
+	var networks []net.IPNet
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		networks = append(networks, *network)
+	}
+
+	for _, domain := range domains {
+		ips, err := lookup(domain)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve domain %q: %w", domain, err)
+		}
+		for _, ip := range ips {
+			if ip4 := ip.To4(); ip4 != nil {
+				networks = append(networks, net.IPNet{IP: ip4, Mask: net.CIDRMask(8*net.IPv4len, 8*net.IPv4len)})
+			} else {
+				networks = append(networks, net.IPNet{IP: ip, Mask: net.CIDRMask(8*net.IPv6len, 8*net.IPv6len)})
+			}
+		}
+	}
+	return networks, nil
+}
+
+func isDomain(domain string) bool {
+	domain = strings.TrimSuffix(domain, ".")
+	if domain == "" || len(domain) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
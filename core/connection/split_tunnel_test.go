@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitTunnel_Validate(t *testing.T) {
+	assert.NoError(t, SplitTunnel{}.Validate())
+	assert.NoError(t, SplitTunnel{IncludeCIDRs: []string{"10.0.0.0/8"}, ExcludeDomains: []string{"example.com"}}.Validate())
+	assert.Error(t, SplitTunnel{ExcludeCIDRs: []string{"10.0.0.0"}}.Validate())
+	assert.Error(t, SplitTunnel{IncludeDomains: []string{"http://example.com"}}.Validate())
+}
+
+func TestSplitTunnel_Resolve(t *testing.T) {
+	lookup := func(host string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("1.2.3.4")}, nil
+	}
+
+	routes, err := SplitTunnel{IncludeCIDRs: []string{"10.0.0.0/8"}, IncludeDomains: []string{"example.com"}}.Resolve(lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "1.2.3.4/32"}, networkStrings(routes.TunnelNetworks()))
+	assert.Contains(t, networkStrings(routes.BypassNetworks()), "128.0.0.0/1")
+	assert.NotContains(t, networkStrings(routes.BypassNetworks()), "10.0.0.0/8")
+	assert.Contains(t, networkStrings(routes.BypassNetworks()), "::/0")
+
+	routes, err = SplitTunnel{ExcludeCIDRs: []string{"128.0.0.0/1"}}.Resolve(lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0.0.0.0/1", "::/1", "8000::/1"}, networkStrings(routes.TunnelNetworks()))
+	assert.Equal(t, []string{"128.0.0.0/1"}, networkStrings(routes.BypassNetworks()))
+
+	routes, err = SplitTunnel{ExcludeCIDRs: []string{"8000::/1"}}.Resolve(lookup)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0.0.0.0/1", "128.0.0.0/1", "::/1"}, networkStrings(routes.TunnelNetworks()))
+	assert.Equal(t, []string{"8000::/1"}, networkStrings(routes.BypassNetworks()))
+}
+
+func TestSplitRoutes_TunnelNetworks_Default(t *testing.T) {
+	assert.Equal(t, []string{"0.0.0.0/1", "128.0.0.0/1", "::/1", "8000::/1"}, networkStrings(SplitRoutes{}.TunnelNetworks()))
+	assert.Empty(t, SplitRoutes{}.BypassNetworks())
+}
+
+func networkStrings(networks []net.IPNet) (res []string) {
+	for _, n := range networks {
+		res = append(res, n.String())
+	}
+	return res
+}
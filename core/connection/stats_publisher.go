@@ -55,7 +55,7 @@ func (s statsPublisher) start(sessionSupplier *connectionManager, statsSupplier
 				log.Warn().Err(err).Msg("Could not get connection statistics")
 				continue
 			}
-			s.bus.Publish(connectionstate.AppTopicConnectionStatistics, connectionstate.AppEventConnectionStatistics{
+			connectionstate.PublishConnectionStatistics(s.bus, connectionstate.AppEventConnectionStatistics{
 				Stats:       stats,
 				SessionInfo: sessionSupplier.Status(),
 			})
@@ -19,6 +19,7 @@ package connection
 
 import (
 	"context"
+	"net"
 	"sync"
 
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
@@ -123,6 +124,10 @@ func (foc *connectionMock) Start(ctx context.Context, connectionParams ConnectOp
 	return nil
 }
 
+func (foc *connectionMock) MigrateServiceConn(*net.UDPConn) error {
+	return nil
+}
+
 func (foc *connectionMock) Stop() {
 	for _, fakeState := range foc.onStopReportStates {
 		foc.reportState(fakeState)
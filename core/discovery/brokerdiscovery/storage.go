@@ -20,6 +20,7 @@ package brokerdiscovery
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/mysteriumnetwork/node/core/discovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
@@ -27,6 +28,9 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 )
 
+// proposalHistoryLimit is number of latest changes kept for each proposal.
+const proposalHistoryLimit = 20
+
 // ProposalReducer proposal match function
 type ProposalReducer func(proposal market.ServiceProposal) bool
 
@@ -35,6 +39,7 @@ func NewStorage(eventPublisher eventbus.Publisher) *ProposalStorage {
 	return &ProposalStorage{
 		eventPublisher: eventPublisher,
 		proposals:      make([]market.ServiceProposal, 0),
+		history:        make(map[market.ProposalID][]discovery.ProposalUpdatedEvent),
 	}
 }
 
@@ -43,6 +48,7 @@ type ProposalStorage struct {
 	eventPublisher eventbus.Publisher
 
 	proposals []market.ServiceProposal
+	history   map[market.ProposalID][]discovery.ProposalUpdatedEvent
 	mutex     sync.RWMutex
 }
 
@@ -86,14 +92,17 @@ func (s *ProposalStorage) Set(proposals []market.ServiceProposal) {
 	for _, p := range proposals {
 		index, exist := s.getProposalIndex(proposalsOld, p.UniqueID())
 		if exist {
-			go s.eventPublisher.Publish(discovery.AppTopicProposalUpdated, p)
+			if event, changed := s.recordChange(proposalsOld[index], p); changed {
+				go discovery.PublishProposalUpdated(s.eventPublisher, event)
+			}
 			proposalsOld = append(proposalsOld[:index], proposalsOld[index+1:]...)
 		} else {
-			go s.eventPublisher.Publish(discovery.AppTopicProposalAdded, p)
+			go discovery.PublishProposalAdded(s.eventPublisher, p)
 		}
 	}
 	for _, p := range proposalsOld {
-		go s.eventPublisher.Publish(discovery.AppTopicProposalRemoved, p)
+		delete(s.history, p.UniqueID())
+		go discovery.PublishProposalRemoved(s.eventPublisher, p)
 	}
 	s.proposals = proposals
 }
@@ -126,10 +135,12 @@ func (s *ProposalStorage) AddProposal(proposals ...market.ServiceProposal) {
 
 	for _, p := range proposals {
 		if index, exist := s.getProposalIndex(s.proposals, p.UniqueID()); !exist {
-			s.eventPublisher.Publish(discovery.AppTopicProposalAdded, p)
+			discovery.PublishProposalAdded(s.eventPublisher, p)
 			s.proposals = append(s.proposals, p)
 		} else {
-			s.eventPublisher.Publish(discovery.AppTopicProposalUpdated, p)
+			if event, changed := s.recordChange(s.proposals[index], p); changed {
+				discovery.PublishProposalUpdated(s.eventPublisher, event)
+			}
 			s.proposals[index] = p
 		}
 	}
@@ -141,11 +152,46 @@ func (s *ProposalStorage) RemoveProposal(id market.ProposalID) {
 	defer s.mutex.Unlock()
 
 	if index, exist := s.getProposalIndex(s.proposals, id); exist {
-		go s.eventPublisher.Publish(discovery.AppTopicProposalRemoved, s.proposals[index])
+		go discovery.PublishProposalRemoved(s.eventPublisher, s.proposals[index])
+		delete(s.history, id)
 		s.proposals = append(s.proposals[:index], s.proposals[index+1:]...)
 	}
 }
 
+// ProposalHistory returns latest changes of given proposal, oldest first.
+func (s *ProposalStorage) ProposalHistory(id market.ProposalID) []discovery.ProposalUpdatedEvent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	history := make([]discovery.ProposalUpdatedEvent, len(s.history[id]))
+	copy(history, s.history[id])
+	return history
+}
+
+// recordChange adds proposal change to history if re-announced proposal differs from the stored one.
+func (s *ProposalStorage) recordChange(old, new market.ServiceProposal) (discovery.ProposalUpdatedEvent, bool) {
+	changes := discovery.DiffProposals(old, new)
+	if len(changes) == 0 {
+		return discovery.ProposalUpdatedEvent{}, false
+	}
+
+	event := discovery.ProposalUpdatedEvent{
+		Proposal:   new,
+		PreviousID: old.ID,
+		Changes:    changes,
+		ChangedAt:  time.Now().UTC(),
+	}
+	if s.history == nil {
+		s.history = make(map[market.ProposalID][]discovery.ProposalUpdatedEvent)
+	}
+	history := append(s.history[new.UniqueID()], event)
+	if len(history) > proposalHistoryLimit {
+		history = history[len(history)-proposalHistoryLimit:]
+	}
+	s.history[new.UniqueID()] = history
+	return event, true
+}
+
 func (s *ProposalStorage) getProposalIndex(proposals []market.ServiceProposal, id market.ProposalID) (int, bool) {
 	for index, p := range proposals {
 		if p.UniqueID() == id {
@@ -20,10 +20,12 @@ package brokerdiscovery
 import (
 	"testing"
 
+	"github.com/mysteriumnetwork/node/core/discovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/discovery/reducer"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -189,6 +191,40 @@ func Test_Storage_AddProposal(t *testing.T) {
 	)
 }
 
+func Test_Storage_AddProposal_RecordsChanges(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	storage := NewStorage(publisher)
+	storage.AddProposal(proposalProvider1Streaming)
+	storage.AddProposal(proposalProvider1Streaming)
+	assert.Len(t, publisher.GetEventHistory(), 1)
+	assert.Empty(t, storage.ProposalHistory(proposalProvider1Streaming.UniqueID()))
+
+	updated := proposalProvider1Streaming
+	updated.ID = 2
+	updated.PaymentMethodType = "BYTES_TRANSFERRED"
+	storage.AddProposal(updated)
+
+	events := publisher.GetEventHistory()
+	assert.Len(t, events, 2)
+	assert.Equal(t, discovery.AppTopicProposalUpdated, events[1].Topic)
+	event, ok := events[1].Event.(discovery.ProposalUpdatedEvent)
+	assert.True(t, ok)
+	assert.Equal(t, 0, event.PreviousID)
+	assert.Equal(t, updated, event.Proposal)
+	assert.Equal(
+		t,
+		[]discovery.ProposalChange{
+			{Field: "id", Old: "0", New: "2"},
+			{Field: "payment_method_type", Old: `""`, New: `"BYTES_TRANSFERRED"`},
+		},
+		event.Changes,
+	)
+	assert.Equal(t, []discovery.ProposalUpdatedEvent{event}, storage.ProposalHistory(updated.UniqueID()))
+
+	storage.RemoveProposal(updated.UniqueID())
+	assert.Empty(t, storage.ProposalHistory(updated.UniqueID()))
+}
+
 func Test_Storage_RemoveProposal(t *testing.T) {
 	storage := createEmptyStorage()
 	storage.RemoveProposal(market.ProposalID{ServiceType: "streaming", ProviderID: "0x1"})
@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// ProposalChange describes single proposal field change.
+type ProposalChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ProposalUpdatedEvent represents changed proposal announcement.
+type ProposalUpdatedEvent struct {
+	Proposal   market.ServiceProposal `json:"proposal"`
+	PreviousID int                    `json:"previous_id"`
+	Changes    []ProposalChange       `json:"changes"`
+	ChangedAt  time.Time              `json:"changed_at"`
+}
+
+// ProposalHistoryProvider returns latest changes of a proposal, oldest first.
+type ProposalHistoryProvider interface {
+	ProposalHistory(id market.ProposalID) []ProposalUpdatedEvent
+}
+
+// DiffProposals returns changes between old and new proposal versions.
+func DiffProposals(old, new market.ServiceProposal) []ProposalChange {
+	fields := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"id", old.ID, new.ID},
+		{"location", location(old), location(new)},
+		{"payment_method_type", old.PaymentMethodType, new.PaymentMethodType},
+		{"payment_method", old.PaymentMethod, new.PaymentMethod},
+		{"access_policies", old.AccessPolicies, new.AccessPolicies},
+		{"provider_contacts", old.ProviderContacts, new.ProviderContacts},
+	}
+
+	var changes []ProposalChange
+	for _, f := range fields {
+		o, n := marshalField(f.old), marshalField(f.new)
+		if o != n {
+			changes = append(changes, ProposalChange{Field: f.name, Old: o, New: n})
+		}
+	}
+	return changes
+}
+
+func location(proposal market.ServiceProposal) interface{} {
+	if proposal.ServiceDefinition == nil {
+		return nil
+	}
+	return proposal.ServiceDefinition.GetLocation()
+}
+
+func marshalField(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
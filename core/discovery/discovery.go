@@ -18,6 +18,7 @@
 package discovery
 
 import (
+	"strings"
 	"sync"
 	"time"
 
@@ -159,7 +160,7 @@ func (d *Discovery) stopLoop() {
 
 func (d *Discovery) handleRegistrationEvent(rep registry.AppEventIdentityRegistration) {
 	log.Debug().Msgf("Registration event received for %v", rep.ID.Address)
-	if rep.ID.Address != d.ownIdentity.Address {
+	if !strings.EqualFold(rep.ID.Address, d.ownIdentity.Address) {
 		log.Debug().Msgf("Identity mismatch for registration. Expected %v got %v", d.ownIdentity.Address, rep.ID.Address)
 		return
 	}
@@ -178,7 +179,7 @@ func (d *Discovery) handleRegistrationEvent(rep registry.AppEventIdentityRegistr
 
 func (d *Discovery) registerIdentity() {
 	log.Info().Msg("Waiting for registration success event")
-	d.eventBus.Subscribe(registry.AppTopicIdentityRegistration, d.handleRegistrationEvent)
+	registry.SubscribeIdentityRegistration(d.eventBus, eventbus.DeliverSync, d.handleRegistrationEvent)
 	d.changeStatus(WaitingForRegistration)
 }
 
@@ -190,7 +191,7 @@ func (d *Discovery) registerProposal() {
 		d.changeStatus(RegisterProposal)
 		return
 	}
-	d.eventBus.Publish(AppTopicProposalAnnounce, d.proposal)
+	PublishProposalAnnounce(d.eventBus, d.proposal)
 	d.changeStatus(PingProposal)
 }
 
@@ -204,7 +205,7 @@ func (d *Discovery) pingProposal() {
 			log.Error().Err(err).Msg("Failed to ping proposal")
 		}
 
-		d.eventBus.Publish(AppTopicProposalAnnounce, d.proposal)
+		PublishProposalAnnounce(d.eventBus, d.proposal)
 		d.changeStatus(PingProposal)
 	}
 }
@@ -69,7 +69,7 @@ func TestStartRegistersIdentitySuccessfully(t *testing.T) {
 	actualStatus := observeStatus(d, WaitingForRegistration)
 	assert.Equal(t, WaitingForRegistration, actualStatus)
 
-	d.eventBus.Publish(identityregistry.AppTopicIdentityRegistration, identityregistry.AppEventIdentityRegistration{
+	identityregistry.PublishIdentityRegistration(d.eventBus, identityregistry.AppEventIdentityRegistration{
 		ID:     providerID,
 		Status: identityregistry.Registered,
 	})
@@ -89,7 +89,7 @@ func TestStartRegisterIdentityCancelled(t *testing.T) {
 	actualStatus := observeStatus(d, WaitingForRegistration)
 	assert.Equal(t, WaitingForRegistration, actualStatus)
 
-	d.eventBus.Publish(identityregistry.AppTopicIdentityRegistration, identityregistry.AppEventIdentityRegistration{
+	identityregistry.PublishIdentityRegistration(d.eventBus, identityregistry.AppEventIdentityRegistration{
 		ID:     providerID,
 		Status: identityregistry.RegistrationError,
 	})
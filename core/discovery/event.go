@@ -17,14 +17,59 @@
 
 package discovery
 
+import (
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/market"
+)
+
 // Topic represents the different topics a consumer can subscribe to
 const (
 	// AppTopicProposalAdded represents newly announced proposal
 	AppTopicProposalAdded = "ProposalAdded"
-	// AppTopicProposalUpdated represents re-announced proposal
+	// AppTopicProposalUpdated represents re-announced proposal which has changed, payload is ProposalUpdatedEvent
 	AppTopicProposalUpdated = "ProposalUpdated"
 	// AppTopicProposalRemoved represents newly de-announced proposal
 	AppTopicProposalRemoved = "ProposalRemoved"
 	// AppTopicProposalAnnounce represent proposal events topic.
 	AppTopicProposalAnnounce = "proposalEvent"
 )
+
+// PublishProposalAdded publishes added proposals on the bus.
+func PublishProposalAdded(publisher eventbus.Publisher, e market.ServiceProposal) {
+	eventbus.Handle(AppTopicProposalAdded).Publish(publisher, e)
+}
+
+// SubscribeProposalAdded subscribes fn to added proposals.
+func SubscribeProposalAdded(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(market.ServiceProposal)) error {
+	return eventbus.Handle(AppTopicProposalAdded).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishProposalUpdated publishes proposal updates on the bus.
+func PublishProposalUpdated(publisher eventbus.Publisher, e ProposalUpdatedEvent) {
+	eventbus.Handle(AppTopicProposalUpdated).Publish(publisher, e)
+}
+
+// SubscribeProposalUpdated subscribes fn to proposal updates.
+func SubscribeProposalUpdated(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(ProposalUpdatedEvent)) error {
+	return eventbus.Handle(AppTopicProposalUpdated).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishProposalRemoved publishes removed proposals on the bus.
+func PublishProposalRemoved(publisher eventbus.Publisher, e market.ServiceProposal) {
+	eventbus.Handle(AppTopicProposalRemoved).Publish(publisher, e)
+}
+
+// SubscribeProposalRemoved subscribes fn to removed proposals.
+func SubscribeProposalRemoved(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(market.ServiceProposal)) error {
+	return eventbus.Handle(AppTopicProposalRemoved).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishProposalAnnounce publishes announced proposals on the bus.
+func PublishProposalAnnounce(publisher eventbus.Publisher, e market.ServiceProposal) {
+	eventbus.Handle(AppTopicProposalAnnounce).Publish(publisher, e)
+}
+
+// SubscribeProposalAnnounce subscribes fn to announced proposals.
+func SubscribeProposalAnnounce(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(market.ServiceProposal)) error {
+	return eventbus.Handle(AppTopicProposalAnnounce).Subscribe(subscriber, delivery, fn)
+}
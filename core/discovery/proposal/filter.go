@@ -33,6 +33,7 @@ type Filter struct {
 	LocationCountry     string
 	AccessPolicyID      string
 	AccessPolicySource  string
+	Obfuscation         string
 	UpperTimePriceBound *big.Int
 	LowerTimePriceBound *big.Int
 	UpperGBPriceBound   *big.Int
@@ -64,6 +65,9 @@ func (filter *Filter) Matches(proposal market.ServiceProposal) bool {
 	if filter.AccessPolicyID != "" || filter.AccessPolicySource != "" {
 		conditions = append(conditions, reducer.AccessPolicy(filter.AccessPolicyID, filter.AccessPolicySource))
 	}
+	if filter.Obfuscation != "" {
+		conditions = append(conditions, reducer.Obfuscation(filter.Obfuscation))
+	}
 
 	if filter.UpperTimePriceBound != nil && filter.LowerTimePriceBound != nil {
 		conditions = append(conditions, reducer.PriceMinute(filter.LowerTimePriceBound, filter.UpperTimePriceBound))
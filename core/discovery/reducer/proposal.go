@@ -131,6 +131,18 @@ func AccessPolicy(id, source string) func(market.ServiceProposal) bool {
 	}
 }
 
+// Obfuscation returns a matcher for checking if proposal offers given p2p obfuscation method
+func Obfuscation(method string) func(market.ServiceProposal) bool {
+	return func(proposal market.ServiceProposal) bool {
+		for _, m := range proposal.ProviderContacts.ObfuscationMethods() {
+			if m == method {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 // Unsupported filters out unsupported proposals
 func Unsupported() func(market.ServiceProposal) bool {
 	return func(proposal market.ServiceProposal) bool {
@@ -22,6 +22,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p"
 )
 
 func Test_ProviderID(t *testing.T) {
@@ -87,6 +90,21 @@ func Test_AccessPolicy_FiltersByIDAndSource(t *testing.T) {
 	assert.True(t, match(proposalProvider2Streaming))
 }
 
+func Test_Obfuscation_FiltersByMethod(t *testing.T) {
+	proposalObfuscated := market.ServiceProposal{
+		ProviderContacts: market.ContactList{{
+			Type:       p2p.ContactTypeWebSocketV1,
+			Definition: p2p.WebSocketContactDefinition{Obfuscation: []string{"scramble"}},
+		}},
+	}
+	match := Obfuscation("scramble")
+
+	assert.False(t, match(proposalEmpty))
+	assert.False(t, match(proposalProvider1Streaming))
+	assert.True(t, match(proposalObfuscated))
+	assert.False(t, Obfuscation("unknown")(proposalObfuscated))
+}
+
 func Test_PriceMinute_FiltersByPrice(t *testing.T) {
 	match := PriceMinute(big.NewInt(100), big.NewInt(1000000))
 
@@ -24,6 +24,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/location/locationstate"
 	nodevent "github.com/mysteriumnetwork/node/core/node/event"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/rs/zerolog/log"
 )
 
@@ -34,19 +35,15 @@ type Cache struct {
 	location         locationstate.Location
 	origin           locationstate.Location
 	expiry           time.Duration
-	pub              publisher
+	pub              eventbus.Publisher
 	lock             sync.Mutex
 }
 
-type publisher interface {
-	Publish(topic string, data interface{})
-}
-
 // LocUpdateEvent is the event type used to sending or receiving event updates
 const LocUpdateEvent string = "location-update-event"
 
 // NewCache returns a new instance of location cache
-func NewCache(resolver Resolver, pub publisher, expiry time.Duration) *Cache {
+func NewCache(resolver Resolver, pub eventbus.Publisher, expiry time.Duration) *Cache {
 	return &Cache{
 		locationDetector: resolver,
 		expiry:           expiry,
@@ -63,7 +60,7 @@ func (c *Cache) fetchAndSave() (locationstate.Location, error) {
 
 	// on successful fetch save the values for further use
 	if err == nil {
-		c.pub.Publish(LocUpdateEvent, loc)
+		PublishLocationUpdate(c.pub, loc)
 		c.location = loc
 		c.lastFetched = time.Now()
 	}
@@ -123,3 +120,13 @@ func (c *Cache) HandleNodeEvent(se nodevent.Payload) {
 		log.Debug().Msgf("original location detected: %s (%s)", c.origin.Country, c.origin.NodeType)
 	}
 }
+
+// PublishLocationUpdate publishes location updates on the bus.
+func PublishLocationUpdate(publisher eventbus.Publisher, e locationstate.Location) {
+	eventbus.Handle(LocUpdateEvent).Publish(publisher, e)
+}
+
+// SubscribeLocationUpdate subscribes fn to location updates.
+func SubscribeLocationUpdate(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(locationstate.Location)) error {
+	return eventbus.Handle(LocUpdateEvent).Subscribe(subscriber, delivery, fn)
+}
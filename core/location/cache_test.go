@@ -23,6 +23,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -63,7 +64,7 @@ func TestCache_needsRefresh(t *testing.T) {
 			c := &Cache{
 				lastFetched: tt.fields.lastFetched,
 				expiry:      tt.fields.expiry,
-				pub:         mockPublisher{},
+				pub:         mocks.NewEventBus(),
 			}
 			if got := c.needsRefresh(); got != tt.want {
 				t.Errorf("Cache.needsRefresh() = %v, want %v", got, tt.want)
@@ -82,16 +83,12 @@ func (mr *mockResolver) DetectLocation() (locationstate.Location, error) {
 	return locationstate.Location{}, mr.errToReturn
 }
 
-type mockPublisher struct{}
-
-func (mp mockPublisher) Publish(topic string, data interface{}) {}
-
 func TestCacheHandlesConnection_Connected(t *testing.T) {
 	r := &mockResolver{}
 	c := &Cache{
 		expiry:           time.Second * 1,
 		locationDetector: r,
-		pub:              mockPublisher{},
+		pub:              mocks.NewEventBus(),
 	}
 	c.HandleConnectionEvent(connectionstate.AppEventConnectionState{State: connectionstate.Connected})
 	assert.True(t, r.called)
@@ -102,7 +99,7 @@ func TestCacheHandlesConnection_NotConnected(t *testing.T) {
 	c := &Cache{
 		expiry:           time.Second * 1,
 		locationDetector: r,
-		pub:              mockPublisher{},
+		pub:              mocks.NewEventBus(),
 	}
 	c.HandleConnectionEvent(connectionstate.AppEventConnectionState{State: connectionstate.NotConnected})
 	assert.True(t, r.called)
@@ -113,7 +110,7 @@ func TestCacheIgnoresOther(t *testing.T) {
 	c := &Cache{
 		expiry:           time.Second * 1,
 		locationDetector: r,
-		pub:              mockPublisher{},
+		pub:              mocks.NewEventBus(),
 	}
 	c.HandleConnectionEvent(connectionstate.AppEventConnectionState{State: connectionstate.Reconnecting})
 	assert.False(t, r.called)
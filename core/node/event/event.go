@@ -17,6 +17,8 @@
 
 package event
 
+import "github.com/mysteriumnetwork/node/eventbus"
+
 const (
 	// AppTopicNode represents the topic we're gonna be publishing and subscribing on
 	AppTopicNode = "Node"
@@ -33,3 +35,13 @@ type Status string
 type Payload struct {
 	Status Status
 }
+
+// PublishNode publishes node status events on the bus.
+func PublishNode(publisher eventbus.Publisher, e Payload) {
+	eventbus.Handle(AppTopicNode).Publish(publisher, e)
+}
+
+// SubscribeNode subscribes fn to node status events.
+func SubscribeNode(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(Payload)) error {
+	return eventbus.Handle(AppTopicNode).Subscribe(subscriber, delivery, fn)
+}
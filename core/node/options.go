@@ -93,8 +93,11 @@ func GetOptions() *Options {
 		Localnet:              config.GetBool(config.FlagLocalnet),
 		Testnet2:              config.GetBool(config.FlagTestnet2),
 		ExperimentNATPunching: config.GetBool(config.FlagNATPunching),
+		Traversal:             *GetOptionsTraversal(),
 		MysteriumAPIAddress:   config.GetString(config.FlagAPIAddress),
 		BrokerAddresses:       config.GetStringSlice(config.FlagBrokerAddress),
+		SignallingAddresses:   config.GetStringSlice(config.FlagSignallingAddress),
+		Obfuscation:           config.GetStringSlice(config.FlagObfuscation),
 		EtherClientRPC:        config.GetString(config.FlagEtherRPC),
 		ChainID:               config.GetInt64(config.FlagChainID),
 		DNSMap: map[string][]string{
@@ -125,7 +128,9 @@ func GetOptions() *Options {
 		SwarmDialerDNSHeadstart: config.GetDuration(config.FlagDNSResolutionHeadstart),
 		FeedbackURL:             config.GetString(config.FlagFeedbackURL),
 		Keystore: OptionsKeystore{
-			UseLightweight: config.GetBool(config.FlagKeystoreLightweight),
+			UseLightweight:           config.GetBool(config.FlagKeystoreLightweight),
+			ExternalSigner:           config.GetString(config.FlagKeystoreExternalSigner),
+			ExternalSignerIdentities: config.GetStringSlice(config.FlagKeystoreExternalSignerIdentities),
 		},
 		LogOptions:     *GetLogOptions(),
 		OptionsNetwork: network,
@@ -239,6 +244,9 @@ func GetDHTOptions() *OptionsDHT {
 // OptionsKeystore stores the keystore configuration
 type OptionsKeystore struct {
 	UseLightweight bool
+	// ExternalSigner is a socket of external signer, holding keys of ExternalSignerIdentities.
+	ExternalSigner           string
+	ExternalSignerIdentities []string
 }
 
 func getP2PListenPorts() *port.Range {
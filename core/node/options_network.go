@@ -24,9 +24,12 @@ type OptionsNetwork struct {
 	Testnet2 bool
 
 	ExperimentNATPunching bool
+	Traversal             OptionsTraversal
 
 	MysteriumAPIAddress string
 	BrokerAddresses     []string
+	SignallingAddresses []string
+	Obfuscation         []string
 	EtherClientRPC      string
 	ChainID             int64
 	DNSMap              map[string][]string
@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/nat/traversal"
+)
+
+// OptionsTraversal describes possible parameters of NAT traversal configuration.
+// Zero values fallback to traversal defaults.
+type OptionsTraversal struct {
+	Strategy             string
+	MaxPorts             int
+	ProviderInitialTTL   int
+	ConsumerInitialTTL   int
+	TTLStep              int
+	Interval             time.Duration
+	Timeout              time.Duration
+	SprayPorts           int
+	PreferPortForwarding bool
+}
+
+// GetOptionsTraversal retrieves NAT traversal options from the app configuration.
+func GetOptionsTraversal() *OptionsTraversal {
+	return &OptionsTraversal{
+		Strategy:             config.GetString(config.FlagTraversalStrategy),
+		MaxPorts:             config.GetInt(config.FlagTraversalPorts),
+		ProviderInitialTTL:   config.GetInt(config.FlagTraversalProviderTTL),
+		ConsumerInitialTTL:   config.GetInt(config.FlagTraversalConsumerTTL),
+		TTLStep:              config.GetInt(config.FlagTraversalTTLStep),
+		Interval:             config.GetDuration(config.FlagTraversalInterval),
+		Timeout:              config.GetDuration(config.FlagTraversalTimeout),
+		SprayPorts:           config.GetInt(config.FlagTraversalSprayPorts),
+		PreferPortForwarding: config.GetBool(config.FlagTraversalPreferPortForwarding),
+	}
+}
+
+// PingConfig returns NAT pinger config built from traversal options.
+func (o OptionsTraversal) PingConfig() (*traversal.PingConfig, error) {
+	cfg := traversal.DefaultPingConfig()
+	if o.Strategy != "" {
+		strategy, err := traversal.ParseStrategy(o.Strategy)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Strategy = strategy
+	}
+	if o.MaxPorts > 0 {
+		cfg.MaxPorts = o.MaxPorts
+	}
+	if o.ProviderInitialTTL > 0 {
+		cfg.ProviderInitialTTL = o.ProviderInitialTTL
+	}
+	if o.ConsumerInitialTTL > 0 {
+		cfg.ConsumerInitialTTL = o.ConsumerInitialTTL
+	}
+	if o.TTLStep > 0 {
+		cfg.TTLStep = o.TTLStep
+	}
+	if o.Interval > 0 {
+		cfg.Interval = o.Interval
+	}
+	if o.Timeout > 0 {
+		cfg.Timeout = o.Timeout
+	}
+	if o.SprayPorts > traversal.MaxSprayPorts {
+		return nil, fmt.Errorf("traversal spray ports %d exceed maximum of %d", o.SprayPorts, traversal.MaxSprayPorts)
+	}
+	if o.SprayPorts > 0 {
+		cfg.SprayPorts = o.SprayPorts
+	}
+	cfg.PreferPortForwarding = o.PreferPortForwarding
+	return cfg, nil
+}
@@ -136,6 +136,51 @@ func (pr *Oracle) SubscribePolicies(policies []market.AccessPolicy, repository *
 	return nil
 }
 
+// FetchPolicies fetches rules of given policies to a new repository, which is not synced afterwards.
+func (pr *Oracle) FetchPolicies(policies []market.AccessPolicy) (*Repository, error) {
+	repository := NewRepository()
+	for _, policy := range policies {
+		subscription := policySubscription{
+			policy:      policy,
+			subscribers: []*Repository{repository},
+		}
+		if err := pr.fetchPolicyRules(&subscription); err != nil {
+			return nil, err
+		}
+	}
+	return repository, nil
+}
+
+// ReplacePolicies replaces repository items with fetched ones and syncs changes of given policies
+// to repository instead of the ones it was subscribed to before.
+func (pr *Oracle) ReplacePolicies(policies []market.AccessPolicy, repository, fetched *Repository) {
+	pr.fetchLock.Lock()
+	defer pr.fetchLock.Unlock()
+
+	subscriptionsNew := make([]policySubscription, 0, len(pr.fetchSubscriptions)+len(policies))
+	for _, subscription := range pr.fetchSubscriptions {
+		subscribers := make([]*Repository, 0, len(subscription.subscribers))
+		for _, subscriber := range subscription.subscribers {
+			if subscriber != repository {
+				subscribers = append(subscribers, subscriber)
+			}
+		}
+		if len(subscribers) > 0 {
+			subscription.subscribers = subscribers
+			subscriptionsNew = append(subscriptionsNew, subscription)
+		}
+	}
+	for _, policy := range policies {
+		subscriptionsNew = append(subscriptionsNew, policySubscription{
+			policy:      policy,
+			subscribers: []*Repository{repository},
+		})
+	}
+
+	repository.replaceItems(fetched)
+	pr.fetchSubscriptions = subscriptionsNew
+}
+
 func (pr *Oracle) fetchPolicyRules(subscription *policySubscription) error {
 	req, err := requests.NewGetRequest(subscription.policy.Source, "", nil)
 	if err != nil {
@@ -161,6 +161,26 @@ func Test_Oracle_SubscribePolicies_MultipleSubscribers(t *testing.T) {
 	assert.Equal(t, []market.AccessPolicyRuleSet{policyOneRulesUpdated}, repo2.Rules())
 }
 
+func Test_Oracle_ReplacePolicies(t *testing.T) {
+	repo := NewRepository()
+	server := mockPolicyServer()
+	defer server.Close()
+
+	oracle := createFilledOracle(server.URL, time.Minute, repo)
+	fetched, err := oracle.FetchPolicies(oracle.Policies([]string{"3"}))
+	assert.NoError(t, err)
+	assert.Equal(t, []market.AccessPolicyRuleSet{policyThreeRulesUpdated}, fetched.Rules())
+	assert.Len(t, repo.Rules(), 2)
+
+	oracle.ReplacePolicies(oracle.Policies([]string{"3"}), repo, fetched)
+	assert.Equal(t, []market.AccessPolicyRuleSet{policyThreeRulesUpdated}, repo.Rules())
+	assert.Len(t, oracle.fetchSubscriptions, 1)
+	assert.Equal(t, []*Repository{repo}, oracle.fetchSubscriptions[0].subscribers)
+
+	_, err = oracle.FetchPolicies(oracle.Policies([]string{"4"}))
+	assert.Error(t, err)
+}
+
 func Test_Oracle_StartSyncsPolicies(t *testing.T) {
 	repo := NewRepository()
 	server := mockPolicyServer()
@@ -60,6 +60,17 @@ func (r *Repository) SetPolicyRules(policy market.AccessPolicy, policyRules mark
 	}
 }
 
+func (r *Repository) replaceItems(from *Repository) {
+	from.lock.RLock()
+	items := make([]listItem, len(from.items))
+	copy(items, from.items)
+	from.lock.RUnlock()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.items = items
+}
+
 // Policies list policies in repository
 func (r *Repository) Policies() []market.AccessPolicy {
 	r.lock.RLock()
@@ -181,6 +192,32 @@ func (r *Repository) IsHostAllowed(host string) bool {
 	return isAllowedByDefault
 }
 
+// PolicyForHost returns the first policy which has DNS rule matching given FQDN host
+func (r *Repository) PolicyForHost(host string) (market.AccessPolicy, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, item := range r.items {
+		for _, rule := range item.rules.Allow {
+			if rule.Type == market.AccessPolicyTypeDNSZone && hostInZone(host, rule.Value) {
+				return item.policy, true
+			}
+			if rule.Type == market.AccessPolicyTypeDNSHostname && host == rule.Value {
+				return item.policy, true
+			}
+		}
+	}
+
+	return market.AccessPolicy{}, false
+}
+
+// hostInZone checks if host is the zone itself or one of its subdomains.
+func hostInZone(host, zone string) bool {
+	host = strings.TrimSuffix(host, ".")
+	zone = strings.TrimSuffix(zone, ".")
+	return host == zone || strings.HasSuffix(host, "."+zone)
+}
+
 func (r *Repository) findItemFor(policy market.AccessPolicy) (*listItem, error) {
 	for i, item := range r.items {
 		if item.policy == policy {
@@ -133,6 +133,29 @@ func Test_Repository_Rules(t *testing.T) {
 	assert.Equal(t, []market.AccessPolicyRuleSet{policyOneRules, policyTwoRules}, repo.Rules())
 }
 
+func Test_Repository_PolicyForHost(t *testing.T) {
+	repo := createFullRepo()
+	repo.SetPolicyRules(policyThree, policyThreeRulesUpdated)
+
+	policy, found := repo.PolicyForHost("ipinfo.io")
+	assert.True(t, found)
+	assert.Equal(t, policyTwo, policy)
+
+	policy, found = repo.PolicyForHost("api.ipinfo.io")
+	assert.True(t, found)
+	assert.Equal(t, policyThree, policy)
+
+	policy, found = repo.PolicyForHost("api.ipinfo.io.")
+	assert.True(t, found)
+	assert.Equal(t, policyThree, policy)
+
+	_, found = repo.PolicyForHost("badipinfo.io")
+	assert.False(t, found)
+
+	_, found = repo.PolicyForHost("example.com")
+	assert.False(t, found)
+}
+
 func createEmptyRepo() *Repository {
 	return NewRepository()
 }
@@ -0,0 +1,207 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package portforward
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/nat"
+)
+
+// acquireAttempts limits how many times pool is asked for a port not yet forwarded.
+const acquireAttempts = 10
+
+var (
+	// ErrTooManyPorts is returned when consumer requests more ports than provider allows.
+	ErrTooManyPorts = errors.New("too many ports requested")
+	// ErrNoConsumerAddress is returned when service does not route consumer address, so nothing can be forwarded to it.
+	ErrNoConsumerAddress = errors.New("consumer address is not known")
+)
+
+// Mapping describes ports forwarded to a consumer.
+type Mapping struct {
+	PublicIP string
+	Ports    []nat.PortForward
+}
+
+// Forwarder allocates provider ports and forwards them to consumers.
+type Forwarder struct {
+	natService nat.NATService
+	pool       port.ServicePortSupplier
+	publicIP   func() (string, error)
+	outboundIP func() (string, error)
+	maxPorts   int
+
+	mu    sync.Mutex
+	inUse map[port.Port]io.Closer
+}
+
+// NewForwarder creates port forwarder allowing up to maxPorts ports per consumer.
+// Ports are forwarded from the outbound host address, public address is reported to consumers.
+func NewForwarder(natService nat.NATService, pool port.ServicePortSupplier, publicIP, outboundIP func() (string, error), maxPorts int) *Forwarder {
+	return &Forwarder{
+		natService: natService,
+		pool:       pool,
+		publicIP:   publicIP,
+		outboundIP: outboundIP,
+		maxPorts:   maxPorts,
+		inUse:      make(map[port.Port]io.Closer),
+	}
+}
+
+// Validate checks if requested number of ports can be forwarded to a single consumer.
+func (f *Forwarder) Validate(tcp, udp int) error {
+	if tcp < 0 || udp < 0 || tcp+udp > f.maxPorts {
+		return fmt.Errorf("%w: %d TCP and %d UDP, at most %d allowed", ErrTooManyPorts, tcp, udp, f.maxPorts)
+	}
+	return nil
+}
+
+// Forward forwards requested number of TCP and UDP ports to the consumer address.
+// Returned cleanup removes the forwarding and releases the ports.
+func (f *Forwarder) Forward(consumer net.IP, tcp, udp int) (Mapping, func(), error) {
+	if consumer == nil {
+		return Mapping{}, nil, ErrNoConsumerAddress
+	}
+	if err := f.Validate(tcp, udp); err != nil {
+		return Mapping{}, nil, err
+	}
+
+	publicIP, err := f.publicIP()
+	if err != nil {
+		return Mapping{}, nil, fmt.Errorf("could not get public IP: %w", err)
+	}
+	outboundIP, err := f.outboundIP()
+	if err != nil {
+		return Mapping{}, nil, fmt.Errorf("could not get outbound IP: %w", err)
+	}
+	address := net.ParseIP(outboundIP)
+	if address == nil {
+		return Mapping{}, nil, fmt.Errorf("invalid outbound IP %q", outboundIP)
+	}
+
+	ports, err := f.acquire(tcp + udp)
+	if err != nil {
+		return Mapping{}, nil, err
+	}
+
+	forwards := make([]nat.PortForward, 0, len(ports))
+	for i, p := range ports {
+		protocol := "tcp"
+		if i >= tcp {
+			protocol = "udp"
+		}
+		forwards = append(forwards, nat.PortForward{Protocol: protocol, Port: p.Num(), Consumer: consumer, Address: address})
+	}
+
+	rules, err := f.natService.ForwardPorts(forwards)
+	if err != nil {
+		f.release(ports)
+		return Mapping{}, nil, fmt.Errorf("could not forward ports: %w", err)
+	}
+
+	cleanup := func() {
+		if err := f.natService.Del(rules); err != nil {
+			log.Error().Err(err).Msgf("Could not remove port forwarding to %s", consumer)
+		}
+		f.release(ports)
+	}
+	return Mapping{PublicIP: publicIP, Ports: forwards}, cleanup, nil
+}
+
+func (f *Forwarder) acquire(n int) ([]port.Port, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ports := make([]port.Port, 0, n)
+	fail := func(err error) ([]port.Port, error) {
+		f.releaseLocked(ports)
+		return nil, err
+	}
+	for attempt := 0; len(ports) < n; attempt++ {
+		if attempt >= n*acquireAttempts {
+			return fail(errors.New("could not acquire ports for forwarding"))
+		}
+
+		p, err := f.pool.Acquire()
+		if err != nil {
+			return fail(err)
+		}
+		if _, ok := f.inUse[p]; ok {
+			continue
+		}
+		// Forwarded port is kept bound, so the pool does not hand it out to services listening on the host.
+		reservation, err := reserve(p)
+		if err != nil {
+			log.Debug().Err(err).Msgf("Could not reserve port %d", p.Num())
+			continue
+		}
+		f.inUse[p] = reservation
+		ports = append(ports, p)
+	}
+	return ports, nil
+}
+
+// reserve binds both TCP and UDP port, as the port might be forwarded for any of them.
+func reserve(p port.Port) (io.Closer, error) {
+	udp, err := net.ListenPacket("udp", fmt.Sprintf(":%d", p.Num()))
+	if err != nil {
+		return nil, err
+	}
+	tcp, err := net.Listen("tcp", fmt.Sprintf(":%d", p.Num()))
+	if err != nil {
+		udp.Close()
+		return nil, err
+	}
+	return reservation{udp, tcp}, nil
+}
+
+type reservation []io.Closer
+
+func (r reservation) Close() error {
+	var err error
+	for _, c := range r {
+		if cerr := c.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func (f *Forwarder) release(ports []port.Port) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.releaseLocked(ports)
+}
+
+func (f *Forwarder) releaseLocked(ports []port.Port) {
+	for _, p := range ports {
+		if reservation, ok := f.inUse[p]; ok {
+			reservation.Close()
+			delete(f.inUse, p)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package portforward
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/nat"
+)
+
+var consumerIP = net.ParseIP("10.182.0.2")
+
+func publicIP() (string, error) { return "1.2.3.4", nil }
+
+func outboundIP() (string, error) { return "192.168.1.10", nil }
+
+func TestForwarder_Forward(t *testing.T) {
+	natService := &natServiceMock{}
+	forwarder := NewForwarder(natService, &poolMock{ports: []port.Port{40001, 40001, 40002, 40003}}, publicIP, outboundIP, 5)
+
+	mapping, cleanup, err := forwarder.Forward(consumerIP, 2, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.2.3.4", mapping.PublicIP)
+	address := net.ParseIP("192.168.1.10")
+	assert.Equal(t, []nat.PortForward{
+		{Protocol: "tcp", Port: 40001, Consumer: consumerIP, Address: address},
+		{Protocol: "tcp", Port: 40002, Consumer: consumerIP, Address: address},
+		{Protocol: "udp", Port: 40003, Consumer: consumerIP, Address: address},
+	}, mapping.Ports)
+	assert.Equal(t, mapping.Ports, natService.forwarded)
+	assert.Len(t, forwarder.inUse, 3)
+
+	// Forwarded ports are reserved for both protocols.
+	_, err = net.Listen("tcp", ":40003")
+	assert.Error(t, err)
+	_, err = net.ListenPacket("udp", ":40001")
+	assert.Error(t, err)
+
+	cleanup()
+	assert.True(t, natService.deleted)
+	assert.Empty(t, forwarder.inUse)
+}
+
+func TestForwarder_ForwardRejectsTooManyPorts(t *testing.T) {
+	forwarder := NewForwarder(&natServiceMock{}, &poolMock{}, publicIP, outboundIP, 2)
+
+	_, _, err := forwarder.Forward(consumerIP, 2, 1)
+	assert.True(t, errors.Is(err, ErrTooManyPorts))
+	assert.True(t, errors.Is(forwarder.Validate(2, 1), ErrTooManyPorts))
+	assert.NoError(t, forwarder.Validate(1, 1))
+
+	_, _, err = forwarder.Forward(nil, 1, 0)
+	assert.Equal(t, ErrNoConsumerAddress, err)
+}
+
+func TestForwarder_ForwardReleasesPortsOnNATFailure(t *testing.T) {
+	forwarder := NewForwarder(&natServiceMock{err: nat.ErrPortForwardingNotSupported}, &poolMock{ports: []port.Port{40001}}, publicIP, outboundIP, 2)
+
+	_, _, err := forwarder.Forward(consumerIP, 1, 0)
+	assert.True(t, errors.Is(err, nat.ErrPortForwardingNotSupported))
+	assert.Empty(t, forwarder.inUse)
+}
+
+type poolMock struct {
+	ports []port.Port
+}
+
+func (p *poolMock) Acquire() (port.Port, error) {
+	if len(p.ports) == 0 {
+		return 0, errors.New("pool is exhausted")
+	}
+	next := p.ports[0]
+	p.ports = p.ports[1:]
+	return next, nil
+}
+
+func (p *poolMock) AcquireMultiple(n int) (ports []port.Port, err error) {
+	for i := 0; i < n; i++ {
+		next, err := p.Acquire()
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, next)
+	}
+	return ports, nil
+}
+
+type natServiceMock struct {
+	nat.NATService
+	err       error
+	forwarded []nat.PortForward
+	deleted   bool
+}
+
+func (n *natServiceMock) ForwardPorts(forwards []nat.PortForward) ([]interface{}, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+	n.forwarded = forwards
+	return []interface{}{"rule"}, nil
+}
+
+func (n *natServiceMock) Del(_ []interface{}) error {
+	n.deleted = true
+	return nil
+}
@@ -17,7 +17,11 @@
 
 package quality
 
-import "time"
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+)
 
 const (
 	// StagePraseRequest describes connection request parse event.
@@ -36,6 +40,8 @@ const (
 	StageConnectionCanceled = "connection_canceled"
 	// StageConnectionAlreadyExists describes already exists connection event.
 	StageConnectionAlreadyExists = "connection_already_exists"
+	// StageConnectionStaleProposal describes connection event with outdated proposal.
+	StageConnectionStaleProposal = "connection_stale_proposal"
 	// StageConnectionUnknownError describes unknown connection event.
 	StageConnectionUnknownError = "connection_unknown_error"
 
@@ -97,3 +103,33 @@ const (
 	// AppTopicProviderPingP2P represents event bus topic for provider p2p pings to consumer.
 	AppTopicProviderPingP2P = "provider_ping_p2p"
 )
+
+// PublishConnectionEvents publishes connection quality events on the bus.
+func PublishConnectionEvents(publisher eventbus.Publisher, e ConnectionEvent) {
+	eventbus.Handle(AppTopicConnectionEvents).Publish(publisher, e)
+}
+
+// SubscribeConnectionEvents subscribes fn to connection quality events.
+func SubscribeConnectionEvents(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(ConnectionEvent)) error {
+	return eventbus.Handle(AppTopicConnectionEvents).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishConsumerPingP2P publishes consumer P2P ping results on the bus.
+func PublishConsumerPingP2P(publisher eventbus.Publisher, e PingEvent) {
+	eventbus.Handle(AppTopicConsumerPingP2P).Publish(publisher, e)
+}
+
+// SubscribeConsumerPingP2P subscribes fn to consumer P2P ping results.
+func SubscribeConsumerPingP2P(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(PingEvent)) error {
+	return eventbus.Handle(AppTopicConsumerPingP2P).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishProviderPingP2P publishes provider P2P ping results on the bus.
+func PublishProviderPingP2P(publisher eventbus.Publisher, e PingEvent) {
+	eventbus.Handle(AppTopicProviderPingP2P).Publish(publisher, e)
+}
+
+// SubscribeProviderPingP2P subscribes fn to provider P2P ping results.
+func SubscribeProviderPingP2P(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(PingEvent)) error {
+	return eventbus.Handle(AppTopicProviderPingP2P).Subscribe(subscriber, delivery, fn)
+}
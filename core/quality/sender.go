@@ -152,25 +152,43 @@ type residentCountryEvent struct {
 
 // Subscribe subscribes to relevant events of event bus.
 func (s *Sender) Subscribe(bus eventbus.Subscriber) error {
-	subscription := map[string]interface{}{
-		AppTopicConnectionEvents:                     s.sendConnectionEvent,
-		connectionstate.AppTopicConnectionState:      s.sendConnStateEvent,
-		connectionstate.AppTopicConnectionSession:    s.sendSessionEvent,
-		connectionstate.AppTopicConnectionStatistics: s.sendSessionData,
-		discovery.AppTopicProposalAnnounce:           s.sendProposalEvent,
-		identity.AppTopicIdentityUnlock:              s.sendUnlockEvent,
-		pingpongEvent.AppTopicInvoicePaid:            s.sendSessionEarning,
-		registry.AppTopicIdentityRegistration:        s.sendRegistrationEvent,
-		sessionEvent.AppTopicSession:                 s.sendServiceSessionEvent,
-		trace.AppTopicTraceEvent:                     s.sendTraceEvent,
-		sevent.AppTopicDataTransferred:               s.sendServiceDataStatistics,
-		AppTopicConsumerPingP2P:                      s.sendConsumerPingDistance,
-		AppTopicProviderPingP2P:                      s.sendProviderPingDistance,
-		identity.AppTopicResidentCountry:             s.sendResidentCountry,
+	subscriptions := []func() error{
+		func() error { return SubscribeConnectionEvents(bus, eventbus.DeliverAsync, s.sendConnectionEvent) },
+		func() error {
+			return connectionstate.SubscribeConnectionState(bus, eventbus.DeliverAsync, s.sendConnStateEvent)
+		},
+		func() error {
+			return connectionstate.SubscribeConnectionSession(bus, eventbus.DeliverAsync, s.sendSessionEvent)
+		},
+		func() error {
+			return connectionstate.SubscribeConnectionStatistics(bus, eventbus.DeliverAsync, s.sendSessionData)
+		},
+		func() error {
+			return discovery.SubscribeProposalAnnounce(bus, eventbus.DeliverAsync, s.sendProposalEvent)
+		},
+		func() error { return identity.SubscribeIdentityUnlock(bus, eventbus.DeliverAsync, s.sendUnlockEvent) },
+		func() error {
+			return pingpongEvent.SubscribeInvoicePaid(bus, eventbus.DeliverAsync, s.sendSessionEarning)
+		},
+		func() error {
+			return registry.SubscribeIdentityRegistration(bus, eventbus.DeliverAsync, s.sendRegistrationEvent)
+		},
+		func() error {
+			return sessionEvent.SubscribeSession(bus, eventbus.DeliverAsync, s.sendServiceSessionEvent)
+		},
+		func() error { return trace.SubscribeTraceEvent(bus, eventbus.DeliverAsync, s.sendTraceEvent) },
+		func() error {
+			return sevent.SubscribeDataTransferred(bus, eventbus.DeliverAsync, s.sendServiceDataStatistics)
+		},
+		func() error { return SubscribeConsumerPingP2P(bus, eventbus.DeliverAsync, s.sendConsumerPingDistance) },
+		func() error { return SubscribeProviderPingP2P(bus, eventbus.DeliverAsync, s.sendProviderPingDistance) },
+		func() error {
+			return identity.SubscribeResidentCountry(bus, eventbus.DeliverAsync, s.sendResidentCountry)
+		},
 	}
 
-	for topic, fn := range subscription {
-		if err := bus.SubscribeAsync(topic, fn); err != nil {
+	for _, subscribe := range subscriptions {
+		if err := subscribe(); err != nil {
 			return err
 		}
 	}
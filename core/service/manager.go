@@ -19,10 +19,13 @@ package service
 
 import (
 	"fmt"
+	"reflect"
+	"sync"
 
 	"github.com/gofrs/uuid"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/p2p"
@@ -39,6 +42,8 @@ var (
 	ErrUnsupportedServiceType = errors.New("unsupported service type")
 	// ErrUnsupportedAccessPolicy indicates that manager tried to create service with unsupported access policy
 	ErrUnsupportedAccessPolicy = errors.New("unsupported access policy")
+	// ErrRestartRequired indicates that service changes can't be applied without restarting the service
+	ErrRestartRequired = errors.New("changes require service restart")
 )
 
 // Service interface represents pluggable Mysterium service
@@ -48,6 +53,12 @@ type Service interface {
 	ConfigProvider
 }
 
+// OptionsUpdater is implemented by services which are able to apply changed options without restarting.
+// ErrRestartRequired should be returned for options which can't be changed in place.
+type OptionsUpdater interface {
+	UpdateOptions(options Options) error
+}
+
 // DiscoveryFactory initiates instance which is able announce service discoverability
 type DiscoveryFactory func() Discovery
 
@@ -65,11 +76,13 @@ type WaitForNATHole func() error
 func NewManager(
 	serviceRegistry *Registry,
 	discoveryFactory DiscoveryFactory,
-	eventPublisher Publisher,
+	eventPublisher eventbus.Publisher,
 	policyOracle *policy.Oracle,
+	identities identity.UsageTracker,
 	p2pListener p2p.Listener,
 	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager,
 	statusStorage connectivity.StatusStorage,
+	proposalVersions ProposalVersioner,
 ) *Manager {
 	return &Manager{
 		serviceRegistry:  serviceRegistry,
@@ -77,9 +90,11 @@ func NewManager(
 		discoveryFactory: discoveryFactory,
 		eventPublisher:   eventPublisher,
 		policyOracle:     policyOracle,
+		identities:       identities,
 		p2pListener:      p2pListener,
 		sessionManager:   sessionManager,
 		statusStorage:    statusStorage,
+		proposalVersions: proposalVersions,
 	}
 }
 
@@ -89,18 +104,33 @@ type Manager struct {
 	servicePool     *Pool
 
 	discoveryFactory DiscoveryFactory
-	eventPublisher   Publisher
+	eventPublisher   eventbus.Publisher
 	policyOracle     *policy.Oracle
+	identities       identity.UsageTracker
 
 	p2pListener    p2p.Listener
 	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager
 	statusStorage  connectivity.StatusStorage
+
+	proposalVersions ProposalVersioner
+	updateLock       sync.Mutex
 }
 
 // Start starts an instance of the given service type if knows one in service registry.
 // It passes the options to the start method of the service.
 // If an error occurs in the underlying service, the error is then returned.
 func (manager *Manager) Start(providerID identity.Identity, serviceType string, policyIDs []string, options Options, pm market.PaymentMethod) (id ID, err error) {
+	// provider identity can't be deleted until the service stops
+	release, err := manager.identities.Use(providerID.Address, serviceType+" service")
+	if err != nil {
+		return id, err
+	}
+	defer func() {
+		if err != nil {
+			release()
+		}
+	}()
+
 	service, proposal, err := manager.serviceRegistry.Create(serviceType, options)
 	if err != nil {
 		return id, err
@@ -118,7 +148,10 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		proposal.SetAccessPolicies(&policies)
 	}
 
-	proposal.SetProviderContacts(providerID, market.ContactList{manager.p2pListener.GetContact()})
+	proposal.SetProviderContacts(providerID, manager.p2pListener.GetContacts())
+	if err := manager.proposalVersions.Assign(&proposal); err != nil {
+		return id, fmt.Errorf("could not assign proposal version: %w", err)
+	}
 
 	id, err = generateID()
 	if err != nil {
@@ -133,9 +166,9 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		ProviderID:     providerID,
 		Type:           serviceType,
 		state:          servicestate.Starting,
-		Options:        options,
+		options:        options,
 		service:        service,
-		Proposal:       proposal,
+		proposal:       proposal,
 		policies:       policyRules,
 		discovery:      discovery,
 		eventPublisher: manager.eventPublisher,
@@ -148,6 +181,7 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		subscribeSessionStatus(ch, manager.statusStorage)
 		subscribeSessionAcknowledge(mng, ch)
 		subscribeSessionDestroy(mng, ch)
+		subscribeSessionMigrate(mng, ch)
 		subscribeSessionPayments(mng, ch)
 	}
 	stopP2PListener, err := manager.p2pListener.Listen(providerID, serviceType, channelHandlers)
@@ -171,8 +205,9 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		if stopErr != nil {
 			log.Error().Err(stopErr).Msg("Service stop failed")
 		}
+		release()
 
-		discovery.Wait()
+		instance.getDiscovery().Wait()
 	}()
 
 	netutil.LogNetworkStats()
@@ -180,6 +215,80 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 	return id, nil
 }
 
+// Update applies changed options, access policies and payment method to the running service without
+// stopping its sessions. Active sessions keep the proposal they were started with, changed proposal
+// is re-announced and used for new sessions. ErrRestartRequired is returned if changes can't be applied in place.
+func (manager *Manager) Update(id ID, policyIDs []string, options Options, pm market.PaymentMethod) error {
+	manager.updateLock.Lock()
+	defer manager.updateLock.Unlock()
+
+	instance := manager.servicePool.Instance(id)
+	if instance == nil {
+		return ErrNoSuchInstance
+	}
+
+	proposal := instance.Proposal()
+	proposal.SetPaymentMethod(pm)
+
+	policies := manager.policyOracle.Policies(policyIDs)
+	policiesChanged := !samePolicies(proposal.AccessPolicies, policies)
+	var policiesFetched *policy.Repository
+	if policiesChanged {
+		var err error
+		policiesFetched, err = manager.policyOracle.FetchPolicies(policies)
+		if err != nil {
+			log.Warn().Err(err).Msg("Can't find given access policies")
+			return ErrUnsupportedAccessPolicy
+		}
+		if policiesFetched.HasDNSRules() != instance.Policies().HasDNSRules() {
+			return fmt.Errorf("%w: DNS access rules are applied on service start", ErrRestartRequired)
+		}
+
+		proposal.SetAccessPolicies(nil)
+		if len(policies) > 0 {
+			proposal.SetAccessPolicies(&policies)
+		}
+	}
+
+	if !reflect.DeepEqual(options, instance.Options()) {
+		updater, ok := instance.Service().(OptionsUpdater)
+		if !ok {
+			return fmt.Errorf("%w: %s service options can't be changed while running", ErrRestartRequired, instance.Type)
+		}
+		if err := updater.UpdateOptions(options); err != nil {
+			return err
+		}
+	}
+
+	if policiesChanged {
+		manager.policyOracle.ReplacePolicies(policies, instance.Policies(), policiesFetched)
+	}
+
+	if err := manager.proposalVersions.Assign(&proposal); err != nil {
+		return fmt.Errorf("could not assign proposal version: %w", err)
+	}
+
+	discovery := instance.getDiscovery()
+	if proposal.ID != instance.Proposal().ID || !reflect.DeepEqual(proposal, instance.Proposal()) {
+		log.Info().Msgf("Re-announcing changed proposal of service %s", id)
+		discovery.Stop()
+		discovery.Wait()
+
+		discovery = manager.discoveryFactory()
+		discovery.Start(instance.ProviderID, proposal)
+	}
+	instance.update(options, proposal, discovery)
+
+	return nil
+}
+
+func samePolicies(current *[]market.AccessPolicy, policies []market.AccessPolicy) bool {
+	if current == nil {
+		return len(policies) == 0
+	}
+	return reflect.DeepEqual(*current, policies)
+}
+
 func generateID() (ID, error) {
 	uid, err := uuid.NewV4()
 	if err != nil {
@@ -200,6 +309,9 @@ func (manager *Manager) Kill() error {
 
 // Stop stops the service.
 func (manager *Manager) Stop(id ID) error {
+	manager.updateLock.Lock()
+	defer manager.updateLock.Unlock()
+
 	err := manager.servicePool.Stop(id)
 	if err != nil {
 		return err
@@ -19,6 +19,7 @@ package service
 
 import (
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -57,7 +58,7 @@ func TestManager_StartRemovesServiceFromPoolIfServiceCrashes(t *testing.T) {
 		discoveryFactory,
 		mocks.NewEventBus(),
 		mockPolicyOracle,
-		&mockP2PListener{}, nil, nil,
+		&mockIdentityUsage{}, &mockP2PListener{}, nil, nil, &mockProposalVersioner{},
 	)
 	_, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
 	assert.Nil(t, err)
@@ -76,19 +77,22 @@ func TestManager_StartDoesNotCrashIfStoppedByUser(t *testing.T) {
 
 	discovery := mockDiscovery{}
 	discoveryFactory := MockDiscoveryFactoryFunc(&discovery)
+	identityUsage := &mockIdentityUsage{}
 	manager := NewManager(
 		registry,
 		discoveryFactory,
 		mocks.NewEventBus(),
 		mockPolicyOracle,
-		&mockP2PListener{}, nil, nil,
+		identityUsage, &mockP2PListener{}, nil, nil, &mockProposalVersioner{},
 	)
 	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
 	assert.Nil(t, err)
+	assert.Equal(t, 1, identityUsage.users())
 	err = manager.Stop(id)
 	assert.Nil(t, err)
 	discovery.Wait()
 	assert.Len(t, manager.servicePool.List(), 0)
+	assert.Eventually(t, func() bool { return identityUsage.users() == 0 }, time.Second, 10*time.Millisecond)
 }
 
 func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
@@ -101,13 +105,13 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 
 	discovery := mockDiscovery{}
 	discoveryFactory := MockDiscoveryFactoryFunc(&discovery)
-	eventBus := &mockPublisher{}
+	eventBus := mocks.NewEventBus()
 	manager := NewManager(
 		registry,
 		discoveryFactory,
 		eventBus,
 		mockPolicyOracle,
-		&mockP2PListener{}, nil, nil,
+		&mockIdentityUsage{}, &mockP2PListener{}, nil, nil, &mockProposalVersioner{},
 	)
 
 	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
@@ -125,15 +129,13 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 
 	time.Sleep(time.Millisecond * 30)
 
-	eventBus.lock.Lock()
-	defer eventBus.lock.Unlock()
-
-	assert.Equal(t, servicestate.AppTopicServiceStatus, eventBus.publishedTopic)
+	history := eventBus.GetEventHistory()
+	assert.Equal(t, servicestate.AppTopicServiceStatus, history[len(history)-1].Topic)
 
 	var matchFound bool
 	expectedPayload := servicestate.AppEventServiceStatus{ID: string(serviceID), ProviderID: "", Type: "", Status: "NotRunning"}
-	for i := range eventBus.publishedData {
-		e, ok := eventBus.publishedData[i].(servicestate.AppEventServiceStatus)
+	for i := range history {
+		e, ok := history[i].Event.(servicestate.AppEventServiceStatus)
 		if !ok {
 			continue
 		}
@@ -145,13 +147,79 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 	assert.True(t, matchFound)
 }
 
+func TestManager_UpdateAppliesChangesInPlace(t *testing.T) {
+	registry := NewRegistry()
+	mockCopy := *serviceMock
+	mockCopy.mockProcess = make(chan struct{})
+	registry.Register(serviceType, func(options Options) (Service, market.ServiceProposal, error) {
+		return &mockCopy, proposalMock, nil
+	})
+
+	discovery := mockDiscovery{}
+	manager := NewManager(
+		registry,
+		MockDiscoveryFactoryFunc(&discovery),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockIdentityUsage{}, &mockP2PListener{}, nil, nil, &mockProposalVersioner{},
+	)
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
+	assert.NoError(t, err)
+
+	pm := mocks.DefaultPaymentMethod()
+	err = manager.Update(id, nil, struct{}{}, pm)
+	assert.NoError(t, err)
+	assert.Equal(t, pm, manager.Service(id).Proposal().PaymentMethod)
+
+	err = manager.Update(id, nil, struct{ Port int }{Port: 1}, pm)
+	assert.True(t, errors.Is(err, ErrRestartRequired))
+	assert.Equal(t, struct{}{}, manager.Service(id).Options())
+
+	assert.Equal(t, ErrNoSuchInstance, manager.Update("unknown", nil, struct{}{}, pm))
+
+	assert.NoError(t, manager.Stop(id))
+	discovery.Wait()
+}
+
+type mockIdentityUsage struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (mu *mockIdentityUsage) Use(_, _ string) (func(), error) {
+	mu.mu.Lock()
+	defer mu.mu.Unlock()
+	mu.count++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			mu.mu.Lock()
+			defer mu.mu.Unlock()
+			mu.count--
+		})
+	}, nil
+}
+
+func (mu *mockIdentityUsage) users() int {
+	mu.mu.Lock()
+	defer mu.mu.Unlock()
+	return mu.count
+}
+
 type mockP2PListener struct {
 }
 
-func (m mockP2PListener) GetContact() market.Contact {
-	return market.Contact{}
+func (m mockP2PListener) GetContacts() market.ContactList {
+	return market.ContactList{}
 }
 
 func (m mockP2PListener) Listen(providerID identity.Identity, serviceType string, channelHandler func(ch p2p.Channel)) (func(), error) {
 	return func() {}, nil
 }
+
+type mockProposalVersioner struct {
+}
+
+func (m mockProposalVersioner) Assign(proposal *market.ServiceProposal) error {
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/p2p"
@@ -35,18 +36,13 @@ type ID string
 
 // Pool is responsible for supervising running instances
 type Pool struct {
-	eventPublisher Publisher
+	eventPublisher eventbus.Publisher
 	instances      map[ID]*Instance
 	sync.Mutex
 }
 
-// Publisher is responsible for publishing given events
-type Publisher interface {
-	Publish(topic string, data interface{})
-}
-
 // NewPool returns a empty service pool
-func NewPool(eventPublisher Publisher) *Pool {
+func NewPool(eventPublisher eventbus.Publisher) *Pool {
 	return &Pool{
 		eventPublisher: eventPublisher,
 		instances:      make(map[ID]*Instance),
@@ -131,8 +127,8 @@ func NewInstance(
 	return &Instance{
 		ProviderID: providerID,
 		Type:       serviceType,
-		Options:    options,
-		Proposal:   proposal,
+		options:    options,
+		proposal:   proposal,
 		state:      state,
 		service:    service,
 		policies:   policies,
@@ -147,12 +143,13 @@ type Instance struct {
 	stateLock       sync.RWMutex
 	ProviderID      identity.Identity
 	Type            string
-	Options         Options
 	service         Service
-	Proposal        market.ServiceProposal
 	policies        *policy.Repository
+	eventPublisher  eventbus.Publisher
+	mu              sync.RWMutex
+	options         Options
+	proposal        market.ServiceProposal
 	discovery       Discovery
-	eventPublisher  Publisher
 	p2pChannelsLock sync.Mutex
 	p2pChannels     []p2p.Channel
 }
@@ -162,6 +159,36 @@ func (i *Instance) Service() Service {
 	return i.service
 }
 
+// Options returns effective options of the running service instance.
+func (i *Instance) Options() Options {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.options
+}
+
+// Proposal returns the proposal currently announced by the service instance.
+func (i *Instance) Proposal() market.ServiceProposal {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.proposal
+}
+
+func (i *Instance) getDiscovery() Discovery {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.discovery
+}
+
+func (i *Instance) update(options Options, proposal market.ServiceProposal, discovery Discovery) {
+	i.mu.Lock()
+	i.options = options
+	i.proposal = proposal
+	i.discovery = discovery
+	i.mu.Unlock()
+
+	servicestate.PublishServiceStatus(i.eventPublisher, i.toEvent())
+}
+
 // Policies returns service policies of the running service instance.
 func (i *Instance) Policies() *policy.Repository {
 	return i.policies
@@ -179,7 +206,7 @@ func (i *Instance) setState(newState servicestate.State) {
 	defer i.stateLock.Unlock()
 	i.state = newState
 
-	i.eventPublisher.Publish(servicestate.AppTopicServiceStatus, i.toEvent())
+	servicestate.PublishServiceStatus(i.eventPublisher, i.toEvent())
 }
 
 func (i *Instance) addP2PChannel(ch p2p.Channel) {
@@ -207,8 +234,8 @@ func (i *Instance) closeP2PChannel(ch p2p.Channel) {
 
 func (i *Instance) stop() error {
 	errStop := utils.ErrorCollection{}
-	if i.discovery != nil {
-		i.discovery.Stop()
+	if discovery := i.getDiscovery(); discovery != nil {
+		discovery.Stop()
 	}
 	if i.service != nil {
 		errStop.Add(i.service.Stop())
@@ -226,10 +253,11 @@ func (i *Instance) stop() error {
 
 // toEvent returns an event representation of the instance
 func (i *Instance) toEvent() servicestate.AppEventServiceStatus {
+	proposal := i.Proposal()
 	return servicestate.AppEventServiceStatus{
 		ID:         string(i.ID),
-		ProviderID: i.Proposal.ProviderID,
-		Type:       i.Proposal.ServiceType,
+		ProviderID: proposal.ProviderID,
+		Type:       proposal.ServiceType,
 		Status:     string(i.state),
 	}
 }
@@ -21,9 +21,9 @@ import (
 	"encoding/json"
 	"errors"
 	"net"
-	"sync"
 	"testing"
 
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/stretchr/testify/assert"
 )
@@ -32,19 +32,6 @@ type mockService struct {
 	killErr error
 }
 
-type mockPublisher struct {
-	publishedTopic string
-	publishedData  []interface{}
-	lock           sync.Mutex
-}
-
-func (mockPublisher *mockPublisher) Publish(topic string, data interface{}) {
-	mockPublisher.lock.Lock()
-	defer mockPublisher.lock.Unlock()
-	mockPublisher.publishedTopic = topic
-	mockPublisher.publishedData = append(mockPublisher.publishedData, data)
-}
-
 func (mr *mockService) Serve(_ *Instance) error {
 	return nil
 }
@@ -53,7 +40,7 @@ func (mr *mockService) Stop() error {
 	return mr.killErr
 }
 
-func (mr *mockService) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn) (*ConfigParams, error) {
+func (mr *mockService) ProvideConfig(_ string, _ identity.Identity, _ json.RawMessage, _ *net.UDPConn) (*ConfigParams, error) {
 	return &ConfigParams{}, nil
 }
 
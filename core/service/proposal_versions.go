@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const proposalVersionsBucket = "proposal-versions"
+
+// ProposalVersioner assigns versions to provider proposals.
+type ProposalVersioner interface {
+	Assign(proposal *market.ServiceProposal) error
+}
+
+type proposalVersionStorage interface {
+	GetValue(bucket string, key interface{}, to interface{}) error
+	SetValue(bucket string, key interface{}, to interface{}) error
+}
+
+// proposalVersion is the last version assigned to provider's proposal of a service type.
+type proposalVersion struct {
+	ID          int
+	Fingerprint string
+}
+
+// ProposalVersions assigns monotonically increasing per provider proposal IDs which are persisted
+// across restarts. Proposal keeps its ID while its content stays the same.
+type ProposalVersions struct {
+	storage proposalVersionStorage
+	mu      sync.Mutex
+}
+
+// NewProposalVersions returns new proposal versions keeper.
+func NewProposalVersions(storage proposalVersionStorage) *ProposalVersions {
+	return &ProposalVersions{storage: storage}
+}
+
+// Assign sets proposal ID. Provider's latest ID is increased if proposal changed since it was last assigned.
+func (pv *ProposalVersions) Assign(proposal *market.ServiceProposal) error {
+	pv.mu.Lock()
+	defer pv.mu.Unlock()
+
+	fingerprint, err := proposalFingerprint(*proposal)
+	if err != nil {
+		return err
+	}
+
+	var current proposalVersion
+	if err := pv.get(proposal.ProviderID+"/"+proposal.ServiceType, &current); err != nil {
+		return err
+	}
+	if current.ID > 0 && current.Fingerprint == fingerprint {
+		proposal.ID = current.ID
+		return nil
+	}
+
+	var latest int
+	if err := pv.get(proposal.ProviderID, &latest); err != nil {
+		return err
+	}
+	latest++
+
+	if err := pv.storage.SetValue(proposalVersionsBucket, proposal.ProviderID, latest); err != nil {
+		return fmt.Errorf("could not store provider proposal version: %w", err)
+	}
+	next := proposalVersion{ID: latest, Fingerprint: fingerprint}
+	if err := pv.storage.SetValue(proposalVersionsBucket, proposal.ProviderID+"/"+proposal.ServiceType, next); err != nil {
+		return fmt.Errorf("could not store proposal version: %w", err)
+	}
+
+	proposal.ID = latest
+	return nil
+}
+
+func (pv *ProposalVersions) get(key string, to interface{}) error {
+	err := pv.storage.GetValue(proposalVersionsBucket, key, to)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("could not get proposal version: %w", err)
+	}
+	return nil
+}
+
+// proposalFingerprint hashes proposal content ignoring its ID.
+func proposalFingerprint(proposal market.ServiceProposal) (string, error) {
+	proposal.ID = 0
+	data, err := json.Marshal(proposal)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal proposal: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func TestProposalVersions_Assign(t *testing.T) {
+	dir, err := ioutil.TempDir("", "proposal-versions")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	versions := NewProposalVersions(db)
+
+	wireguard := market.ServiceProposal{ProviderID: "0x1", ServiceType: "wireguard", PaymentMethodType: "BYTES_TRANSFERRED"}
+	openvpn := market.ServiceProposal{ProviderID: "0x1", ServiceType: "openvpn"}
+
+	assert.NoError(t, versions.Assign(&wireguard))
+	assert.Equal(t, 1, wireguard.ID)
+	assert.NoError(t, versions.Assign(&openvpn))
+	assert.Equal(t, 2, openvpn.ID)
+
+	// Unchanged proposal keeps its version.
+	assert.NoError(t, versions.Assign(&wireguard))
+	assert.Equal(t, 1, wireguard.ID)
+
+	// Changed proposal gets next provider version which survives restart.
+	wireguard.PaymentMethodType = "BYTES_TRANSFERRED_WITH_TIME"
+	assert.NoError(t, versions.Assign(&wireguard))
+	assert.Equal(t, 3, wireguard.ID)
+	assert.NoError(t, db.Close())
+
+	db, err = boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer db.Close()
+	versions = NewProposalVersions(db)
+
+	assert.NoError(t, versions.Assign(&wireguard))
+	assert.Equal(t, 3, wireguard.ID)
+	wireguard.PaymentMethodType = "BYTES_TRANSFERRED"
+	assert.NoError(t, versions.Assign(&wireguard))
+	assert.Equal(t, 4, wireguard.ID)
+}
@@ -17,6 +17,8 @@
 
 package servicestate
 
+import "github.com/mysteriumnetwork/node/eventbus"
+
 const (
 	// AppTopicServiceStatus is used in event bus to announce the service status.
 	AppTopicServiceStatus = "Service status"
@@ -41,3 +43,13 @@ const (
 	// Running means that fully established service exists
 	Running = State("Running")
 )
+
+// PublishServiceStatus publishes service status changes on the bus.
+func PublishServiceStatus(publisher eventbus.Publisher, e AppEventServiceStatus) {
+	eventbus.Handle(AppTopicServiceStatus).Publish(publisher, e)
+}
+
+// SubscribeServiceStatus subscribes fn to service status changes.
+func SubscribeServiceStatus(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventServiceStatus)) error {
+	return eventbus.Handle(AppTopicServiceStatus).Subscribe(subscriber, delivery, fn)
+}
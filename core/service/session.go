@@ -41,6 +41,7 @@ type Session struct {
 	Proposal         market.ServiceProposal
 	ServiceID        string
 	CreatedAt        time.Time
+	EgressIP         string
 	request          *pb.SessionRequest
 	done             chan struct{}
 	cleanupLock      sync.Mutex
@@ -93,6 +94,7 @@ func (s *Session) toEvent(status event.Status) event.AppEventSession {
 			ConsumerLocation: s.ConsumerLocation,
 			HermesID:         s.HermesID,
 			Proposal:         s.Proposal,
+			EgressIP:         s.EgressIP,
 		},
 	}
 }
@@ -114,7 +116,7 @@ func NewSession(service *Instance, request *pb.SessionRequest, tracer *trace.Tra
 		ConsumerID:       identity.FromAddress(request.GetConsumer().GetId()),
 		ConsumerLocation: consumerLocation,
 		HermesID:         common.HexToAddress(request.GetConsumer().GetHermesID()),
-		Proposal:         service.Proposal,
+		Proposal:         service.Proposal(),
 		ServiceID:        string(service.ID),
 		CreatedAt:        time.Now().UTC(),
 		request:          request,
@@ -29,7 +29,9 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/portforward"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat/event"
@@ -47,6 +49,10 @@ var (
 	ErrorSessionNotExists = errors.New("session does not exists")
 	// ErrorWrongSessionOwner returned when consumer tries to destroy session that does not belongs to him
 	ErrorWrongSessionOwner = errors.New("wrong session owner")
+	// ErrorPortForwardingNotAllowed returned when consumer requests port forwarding which provider does not allow
+	ErrorPortForwardingNotAllowed = errors.New("port forwarding is not allowed")
+	// ErrorMigrationNotSupported returned when service can not move session to the migrated p2p service conn
+	ErrorMigrationNotSupported = errors.New("service does not support session migration")
 )
 
 // IDGenerator defines method for session id generation
@@ -56,16 +62,16 @@ type IDGenerator func() (session.ID, error)
 type ConfigParams struct {
 	SessionServiceConfig   ServiceConfiguration
 	SessionDestroyCallback DestroyCallback
+	// EgressIP is the public address session traffic leaves the provider from, empty if not known.
+	EgressIP string
+	// ConsumerIP is the consumer address inside the tunnel, nil if service does not route consumer address.
+	ConsumerIP net.IP
 }
 
 // ServiceConfiguration defines service configuration from underlying transport mechanism to be passed to remote party
 // should be serializable to json format.
 type ServiceConfiguration interface{}
 
-type publisher interface {
-	Publish(topic string, data interface{})
-}
-
 // KeepAliveConfig contains keep alive options.
 type KeepAliveConfig struct {
 	SendInterval    time.Duration
@@ -91,7 +97,13 @@ func DefaultConfig() Config {
 
 // ConfigProvider is able to handle config negotiations
 type ConfigProvider interface {
-	ProvideConfig(sessionID string, sessionConfig json.RawMessage, conn *net.UDPConn) (*ConfigParams, error)
+	ProvideConfig(sessionID string, consumerID identity.Identity, sessionConfig json.RawMessage, conn *net.UDPConn) (*ConfigParams, error)
+}
+
+// ServiceConnMigrator is implemented by services which can move session traffic
+// to the new p2p service conn after consumer p2p channel migration.
+type ServiceConnMigrator interface {
+	MigrateServiceConn(sessionID string, conn *net.UDPConn) error
 }
 
 // DestroyCallback cleanups session
@@ -105,8 +117,8 @@ type PromiseProcessor interface {
 	Stop() error
 }
 
-// PaymentEngineFactory creates a new instance of payment engine
-type PaymentEngineFactory func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage) (PaymentEngine, error)
+// PaymentEngineFactory creates a new instance of payment engine charging by the proposal session was started with
+type PaymentEngineFactory func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage, proposal market.ServiceProposal) (PaymentEngine, error)
 
 // PaymentEngine is responsible for interacting with the consumer in regard to payments.
 type PaymentEngine interface {
@@ -115,6 +127,17 @@ type PaymentEngine interface {
 	Stop()
 }
 
+// PortForwardingCharger is implemented by payment engines which charge for forwarded ports.
+type PortForwardingCharger interface {
+	ChargeForwardedPorts(ports int)
+}
+
+// PortForwarder forwards provider ports to session consumers.
+type PortForwarder interface {
+	Validate(tcp, udp int) error
+	Forward(consumer net.IP, tcp, udp int) (portforward.Mapping, func(), error)
+}
+
 // NATEventGetter lets us access the last known traversal event
 type NATEventGetter interface {
 	LastEvent() *event.Event
@@ -126,8 +149,9 @@ func NewSessionManager(
 	sessionStorage *SessionPool,
 	paymentEngineFactory PaymentEngineFactory,
 	natEventGetter NATEventGetter,
-	publisher publisher,
+	publisher eventbus.Publisher,
 	channel p2p.Channel,
+	portForwarder PortForwarder,
 	config Config,
 ) *SessionManager {
 	return &SessionManager{
@@ -138,6 +162,7 @@ func NewSessionManager(
 		paymentEngineFactory: paymentEngineFactory,
 		paymentEngineChan:    make(chan crypto.ExchangeMessage, 1),
 		channel:              channel,
+		portForwarder:        portForwarder,
 		config:               config,
 	}
 }
@@ -149,8 +174,9 @@ type SessionManager struct {
 	paymentEngineFactory PaymentEngineFactory
 	paymentEngineChan    chan crypto.ExchangeMessage
 	natEventGetter       NATEventGetter
-	publisher            publisher
+	publisher            eventbus.Publisher
 	channel              p2p.Channel
+	portForwarder        PortForwarder
 	config               Config
 }
 
@@ -178,11 +204,15 @@ func (manager *SessionManager) Start(request *pb.SessionRequest) (_ pb.SessionRe
 	if err = manager.startSession(session); err != nil {
 		return pb.SessionResponse{}, err
 	}
-	if err = manager.paymentLoop(session); err != nil {
+	if err = manager.validatePortForwarding(session); err != nil {
+		return pb.SessionResponse{}, err
+	}
+	engine, err := manager.paymentLoop(session)
+	if err != nil {
 		return pb.SessionResponse{}, err
 	}
 
-	return manager.providerService(session, manager.channel)
+	return manager.providerService(session, manager.channel, engine)
 }
 
 // Acknowledge marks the session as successfully established as far as the consumer is concerned.
@@ -195,7 +225,7 @@ func (manager *SessionManager) Acknowledge(consumerID identity.Identity, session
 		return ErrorWrongSessionOwner
 	}
 
-	manager.publisher.Publish(sevent.AppTopicSession, session.toEvent(sevent.AcknowledgedStatus))
+	sevent.PublishSession(manager.publisher, session.toEvent(sevent.AcknowledgedStatus))
 	return nil
 }
 
@@ -221,7 +251,12 @@ func (manager *SessionManager) startSession(session *Session) error {
 }
 
 func (manager *SessionManager) validateSession(session *Session) error {
-	if manager.service.Proposal.ID != int(session.request.GetProposalID()) {
+	requestedID := int(session.request.GetProposalID())
+	currentID := session.Proposal.ID
+	if requestedID > 0 && requestedID < currentID {
+		return fmt.Errorf("%w: requested %d, current %d", market.ErrProposalOutdated, requestedID, currentID)
+	}
+	if currentID != requestedID {
 		return ErrorInvalidProposal
 	}
 
@@ -247,6 +282,23 @@ func (manager *SessionManager) clearStaleSession(consumerID identity.Identity, s
 	}
 }
 
+// Migrate moves session service traffic to the current p2p service conn of the channel.
+func (manager *SessionManager) Migrate(consumerID identity.Identity, sessionID string) error {
+	session, found := manager.sessionStorage.Find(session.ID(sessionID))
+	if !found {
+		return ErrorSessionNotExists
+	}
+	if session.ConsumerID != consumerID {
+		return ErrorWrongSessionOwner
+	}
+
+	migrator, ok := manager.service.Service().(ServiceConnMigrator)
+	if !ok {
+		return ErrorMigrationNotSupported
+	}
+	return migrator.MigrateServiceConn(sessionID, manager.channel.ServiceConn())
+}
+
 // Destroy destroys session by given sessionID
 func (manager *SessionManager) Destroy(consumerID identity.Identity, sessionID string) error {
 	session, found := manager.sessionStorage.Find(session.ID(sessionID))
@@ -261,16 +313,16 @@ func (manager *SessionManager) Destroy(consumerID identity.Identity, sessionID s
 	return nil
 }
 
-func (manager *SessionManager) paymentLoop(session *Session) error {
+func (manager *SessionManager) paymentLoop(session *Session) (PaymentEngine, error) {
 	trace := session.tracer.StartStage("Provider session create (payment)")
 	defer session.tracer.EndStage(trace)
 
 	log.Info().Msg("Using new payments")
 
 	chainID := config.GetInt64(config.FlagChainID)
-	engine, err := manager.paymentEngineFactory(manager.service.ProviderID, session.ConsumerID, chainID, session.HermesID, string(session.ID), manager.paymentEngineChan)
+	engine, err := manager.paymentEngineFactory(manager.service.ProviderID, session.ConsumerID, chainID, session.HermesID, string(session.ID), manager.paymentEngineChan, session.Proposal)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// stop the balance tracker once the session is finished
@@ -289,17 +341,17 @@ func (manager *SessionManager) paymentLoop(session *Session) error {
 
 	log.Info().Msg("Waiting for a first invoice to be paid")
 	if err := engine.WaitFirstInvoice(30 * time.Second); err != nil {
-		return fmt.Errorf("first invoice was not paid: %w", err)
+		return nil, fmt.Errorf("first invoice was not paid: %w", err)
 	}
 
-	return nil
+	return engine, nil
 }
 
-func (manager *SessionManager) providerService(session *Session, channel p2p.Channel) (pb.SessionResponse, error) {
+func (manager *SessionManager) providerService(session *Session, channel p2p.Channel, engine PaymentEngine) (pb.SessionResponse, error) {
 	trace := session.tracer.StartStage("Provider session create (configure)")
 	defer session.tracer.EndStage(trace)
 
-	config, err := manager.service.Service().ProvideConfig(string(session.ID), session.request.GetConfig(), channel.ServiceConn())
+	config, err := manager.service.Service().ProvideConfig(string(session.ID), session.ConsumerID, session.request.GetConfig(), channel.ServiceConn())
 	if err != nil {
 		return pb.SessionResponse{}, fmt.Errorf("cannot get provider config for session %s: %w", string(session.ID), err)
 	}
@@ -311,18 +363,81 @@ func (manager *SessionManager) providerService(session *Session, channel p2p.Cha
 		})
 	}
 
+	if config.EgressIP != "" {
+		session.EgressIP = config.EgressIP
+		sevent.PublishSession(manager.publisher, session.toEvent(sevent.UpdatedStatus))
+	}
+
+	forwardedPorts, err := manager.forwardPorts(session, config.ConsumerIP, engine)
+	if err != nil {
+		return pb.SessionResponse{}, fmt.Errorf("cannot forward ports for session %s: %w", string(session.ID), err)
+	}
+
 	data, err := json.Marshal(config.SessionServiceConfig)
 	if err != nil {
 		return pb.SessionResponse{}, fmt.Errorf("cannot pack session %s service config: %w", string(session.ID), err)
 	}
 
 	return pb.SessionResponse{
-		ID:          string(session.ID),
-		PaymentInfo: "v3",
-		Config:      data,
+		ID:             string(session.ID),
+		PaymentInfo:    "v3",
+		Config:         data,
+		ForwardedPorts: forwardedPorts,
 	}, nil
 }
 
+// validatePortForwarding checks requested ports before consumer is charged for the session.
+func (manager *SessionManager) validatePortForwarding(session *Session) error {
+	request := session.request.GetPortForwarding()
+	if request.GetTcp() == 0 && request.GetUdp() == 0 {
+		return nil
+	}
+	if manager.portForwarder == nil {
+		return ErrorPortForwardingNotAllowed
+	}
+	definition, ok := session.Proposal.ServiceDefinition.(market.PortForwardingDefinition)
+	if !ok || !definition.SupportsPortForwarding() {
+		return fmt.Errorf("%w: not supported by %s service", ErrorPortForwardingNotAllowed, session.Proposal.ServiceType)
+	}
+	return manager.portForwarder.Validate(int(request.GetTcp()), int(request.GetUdp()))
+}
+
+// forwardPorts forwards ports requested by the consumer and starts charging for them.
+func (manager *SessionManager) forwardPorts(session *Session, consumerIP net.IP, engine PaymentEngine) ([]*pb.ForwardedPort, error) {
+	request := session.request.GetPortForwarding()
+	if request.GetTcp() == 0 && request.GetUdp() == 0 {
+		return nil, nil
+	}
+	if manager.portForwarder == nil {
+		return nil, ErrorPortForwardingNotAllowed
+	}
+
+	mapping, cleanup, err := manager.portForwarder.Forward(consumerIP, int(request.GetTcp()), int(request.GetUdp()))
+	if err != nil {
+		return nil, err
+	}
+	session.addCleanup(func() error {
+		cleanup()
+		return nil
+	})
+
+	if charger, ok := engine.(PortForwardingCharger); ok {
+		charger.ChargeForwardedPorts(len(mapping.Ports))
+	}
+
+	forwardedPorts := make([]*pb.ForwardedPort, 0, len(mapping.Ports))
+	for _, forward := range mapping.Ports {
+		forwardedPorts = append(forwardedPorts, &pb.ForwardedPort{
+			Protocol:     forward.Protocol,
+			PublicIP:     mapping.PublicIP,
+			PublicPort:   uint32(forward.Port),
+			ConsumerPort: uint32(forward.Port),
+		})
+	}
+	log.Info().Msgf("Forwarded %d port(s) to consumer of session %s", len(forwardedPorts), session.ID)
+	return forwardedPorts, nil
+}
+
 func (manager *SessionManager) keepAliveLoop(sess *Session, channel p2p.Channel) {
 	// Register handler for handling p2p keep alive pings from consumer.
 	channel.Handle(p2p.TopicKeepAlive, func(c p2p.Context) error {
@@ -369,7 +484,7 @@ func (manager *SessionManager) sendKeepAlivePing(channel p2p.Channel, sessionID
 
 	start := time.Now()
 	_, err := channel.Send(ctx, p2p.TopicKeepAlive, p2p.ProtoMessage(msg))
-	manager.publisher.Publish(quality.AppTopicProviderPingP2P, quality.PingEvent{
+	quality.PublishProviderPingP2P(manager.publisher, quality.PingEvent{
 		SessionID: string(sessionID),
 		Duration:  time.Now().Sub(start),
 	})
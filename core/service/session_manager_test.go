@@ -26,10 +26,13 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/portforward"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/nat"
 	"github.com/mysteriumnetwork/node/nat/event"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
@@ -55,10 +58,30 @@ var (
 		policy.NewRepository(),
 		&mockDiscovery{},
 	)
+	forwardingService = NewInstance(
+		identity.FromAddress(currentProposal.ProviderID),
+		currentProposal.ServiceType,
+		struct{}{},
+		market.ServiceProposal{
+			ServiceType:       currentProposal.ServiceType,
+			ID:                currentProposalID,
+			ServiceDefinition: forwardingDefinition{},
+		},
+		servicestate.Running,
+		&mockService{},
+		policy.NewRepository(),
+		&mockDiscovery{},
+	)
 	consumerID = identity.FromAddress("deadbeef")
 	hermesID   = common.HexToAddress("0x1")
 )
 
+type forwardingDefinition struct{}
+
+func (forwardingDefinition) GetLocation() market.Location { return market.Location{} }
+
+func (forwardingDefinition) SupportsPortForwarding() bool { return true }
+
 type mockBalanceTracker struct {
 	paymentError      error
 	firstPaymentError error
@@ -272,6 +295,22 @@ func TestManager_Start_RejectsUnknownProposal(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
+func TestManager_Start_RejectsOutdatedProposal(t *testing.T) {
+	sessionStore := NewSessionPool(mocks.NewEventBus())
+	manager := newManager(currentService, sessionStore, mocks.NewEventBus(), &mockBalanceTracker{})
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+		},
+		ProposalID: int64(currentProposalID - 1),
+	})
+
+	assert.True(t, errors.Is(err, market.ErrProposalOutdated))
+	assert.Len(t, sessionStore.GetAll(), 0)
+}
+
 type MockNatEventTracker struct {
 }
 
@@ -279,6 +318,114 @@ func (mnet *MockNatEventTracker) LastEvent() *event.Event {
 	return &event.Event{}
 }
 
+func TestManager_Start_ForwardsRequestedPorts(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	engine := &mockChargingEngine{}
+	forwarder := &mockPortForwarder{}
+	manager := newManager(forwardingService, sessionStore, publisher, engine)
+	manager.portForwarder = forwarder
+
+	response, err := manager.Start(&pb.SessionRequest{
+		Consumer:       &pb.ConsumerInfo{Id: consumerID.Address, HermesID: hermesID.String()},
+		ProposalID:     int64(currentProposalID),
+		PortForwarding: &pb.PortForwardingRequest{Tcp: 1, Udp: 1},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, forwarder.tcp)
+	assert.Equal(t, 1, forwarder.udp)
+	assert.Equal(t, 2, engine.ports)
+	assert.Len(t, response.ForwardedPorts, 2)
+	assert.Equal(t, "tcp", response.ForwardedPorts[0].Protocol)
+	assert.Equal(t, "1.2.3.4", response.ForwardedPorts[0].PublicIP)
+	assert.Equal(t, uint32(40001), response.ForwardedPorts[0].PublicPort)
+	assert.Equal(t, uint32(40001), response.ForwardedPorts[0].ConsumerPort)
+
+	sessionStore.GetAll()[0].Close()
+	assert.True(t, forwarder.cleaned)
+}
+
+func TestManager_Start_RejectsPortForwardingWhenNotAllowed(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	manager := newManager(currentService, sessionStore, publisher, &mockBalanceTracker{})
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer:       &pb.ConsumerInfo{Id: consumerID.Address, HermesID: hermesID.String()},
+		ProposalID:     int64(currentProposalID),
+		PortForwarding: &pb.PortForwardingRequest{Tcp: 1},
+	})
+	assert.True(t, errors.Is(err, ErrorPortForwardingNotAllowed))
+}
+
+func TestManager_Start_RejectsPortForwardingBeforePayment(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	engine := &mockChargingEngine{}
+	forwarder := &mockPortForwarder{}
+	manager := newManager(currentService, sessionStore, publisher, engine)
+	manager.portForwarder = forwarder
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer:       &pb.ConsumerInfo{Id: consumerID.Address, HermesID: hermesID.String()},
+		ProposalID:     int64(currentProposalID),
+		PortForwarding: &pb.PortForwardingRequest{Tcp: 1},
+	})
+	assert.True(t, errors.Is(err, ErrorPortForwardingNotAllowed))
+	assert.False(t, engine.started)
+	assert.Zero(t, forwarder.tcp)
+
+	forwarder.validateErr = errors.New("too many ports")
+	manager = newManager(forwardingService, sessionStore, publisher, engine)
+	manager.portForwarder = forwarder
+
+	_, err = manager.Start(&pb.SessionRequest{
+		Consumer:       &pb.ConsumerInfo{Id: consumerID.Address, HermesID: hermesID.String()},
+		ProposalID:     int64(currentProposalID),
+		PortForwarding: &pb.PortForwardingRequest{Tcp: 10},
+	})
+	assert.Equal(t, forwarder.validateErr, err)
+	assert.False(t, engine.started)
+	assert.Zero(t, forwarder.tcp)
+}
+
+type mockChargingEngine struct {
+	mockBalanceTracker
+	ports   int
+	started bool
+}
+
+func (m *mockChargingEngine) Start() error {
+	m.started = true
+	return nil
+}
+
+func (m *mockChargingEngine) ChargeForwardedPorts(ports int) {
+	m.ports = ports
+}
+
+type mockPortForwarder struct {
+	tcp, udp    int
+	cleaned     bool
+	validateErr error
+}
+
+func (m *mockPortForwarder) Validate(tcp, udp int) error {
+	return m.validateErr
+}
+
+func (m *mockPortForwarder) Forward(consumer net.IP, tcp, udp int) (portforward.Mapping, func(), error) {
+	m.tcp, m.udp = tcp, udp
+	return portforward.Mapping{
+		PublicIP: "1.2.3.4",
+		Ports: []nat.PortForward{
+			{Protocol: "tcp", Port: 40001, Consumer: consumer},
+			{Protocol: "udp", Port: 40002, Consumer: consumer},
+		},
+	}, func() { m.cleaned = true }, nil
+}
+
 func TestManager_AcknowledgeSession_RejectsUnknown(t *testing.T) {
 	publisher := mocks.NewEventBus()
 	sessionStore := NewSessionPool(publisher)
@@ -333,16 +480,33 @@ func TestManager_AcknowledgeSession_PublishesEvent(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
-func newManager(service *Instance, sessions *SessionPool, publisher publisher, paymentEngine PaymentEngine) *SessionManager {
+func TestManager_Migrate_RejectsUnsupportedService(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	session, _ := NewSession(
+		currentService,
+		&pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: consumerID.Address}},
+		trace.NewTracer(""),
+	)
+	sessionStore.Add(session)
+
+	manager := newManager(currentService, sessionStore, publisher, &mockBalanceTracker{})
+
+	assert.Exactly(t, ErrorWrongSessionOwner, manager.Migrate(identity.FromAddress("some other id"), string(session.ID)))
+	assert.Exactly(t, ErrorMigrationNotSupported, manager.Migrate(consumerID, string(session.ID)))
+}
+
+func newManager(service *Instance, sessions *SessionPool, publisher eventbus.Publisher, paymentEngine PaymentEngine) *SessionManager {
 	return NewSessionManager(
 		service,
 		sessions,
-		func(_, _ identity.Identity, _ int64, _ common.Address, _ string, _ chan crypto.ExchangeMessage) (PaymentEngine, error) {
+		func(_, _ identity.Identity, _ int64, _ common.Address, _ string, _ chan crypto.ExchangeMessage, _ market.ServiceProposal) (PaymentEngine, error) {
 			return paymentEngine, nil
 		},
 		&MockNatEventTracker{},
 		publisher,
 		&mockP2PChannel{tracer: trace.NewTracer("Provider connect")},
+		nil,
 		DefaultConfig(),
 	)
 }
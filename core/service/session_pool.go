@@ -20,13 +20,14 @@ package service
 import (
 	"sync"
 
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/event"
 )
 
 // NewSessionPool initiates new session storage
-func NewSessionPool(publisher publisher) *SessionPool {
+func NewSessionPool(publisher eventbus.Publisher) *SessionPool {
 	sm := &SessionPool{
 		sessions:  make(map[session.ID]*Session),
 		lock:      sync.Mutex{},
@@ -39,7 +40,7 @@ func NewSessionPool(publisher publisher) *SessionPool {
 type SessionPool struct {
 	sessions  map[session.ID]*Session
 	lock      sync.Mutex
-	publisher publisher
+	publisher eventbus.Publisher
 }
 
 // Add puts given session to storage and publishes a creation event.
@@ -49,7 +50,7 @@ func (sp *SessionPool) Add(instance *Session) {
 	defer sp.lock.Unlock()
 
 	sp.sessions[instance.ID] = instance
-	sp.publisher.Publish(event.AppTopicSession, instance.toEvent(event.CreatedStatus))
+	event.PublishSession(sp.publisher, instance.toEvent(event.CreatedStatus))
 }
 
 // GetAll returns all sessions in storage
@@ -108,7 +109,7 @@ func (sp *SessionPool) Remove(id session.ID) {
 
 	if instance, found := sp.sessions[id]; found {
 		delete(sp.sessions, id)
-		go sp.publisher.Publish(event.AppTopicSession, instance.toEvent(event.RemovedStatus))
+		go event.PublishSession(sp.publisher, instance.toEvent(event.RemovedStatus))
 	}
 }
 
@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session"
@@ -157,7 +158,7 @@ func TestSessionPool_RemoveForService_PublishesEvents(t *testing.T) {
 	assert.Eventually(t, lastEventMatches(mp, sessionExisting.ID, sessionEvent.RemovedStatus), 2*time.Second, 10*time.Millisecond)
 }
 
-func mockPool(publisher publisher, sessionInstance *Session) *SessionPool {
+func mockPool(publisher eventbus.Publisher, sessionInstance *Session) *SessionPool {
 	return &SessionPool{
 		sessions:  map[session.ID]*Session{sessionInstance.ID: sessionInstance},
 		publisher: publisher,
@@ -52,7 +52,7 @@ func (service *serviceFake) GetType() string {
 	return "fake"
 }
 
-func (service *serviceFake) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn) (*ConfigParams, error) {
+func (service *serviceFake) ProvideConfig(_ string, _ identity.Identity, _ json.RawMessage, _ *net.UDPConn) (*ConfigParams, error) {
 	return &ConfigParams{}, nil
 }
 
@@ -18,11 +18,13 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
 
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session/connectivity"
@@ -39,6 +41,10 @@ func subscribeSessionCreate(mng *SessionManager, ch p2p.Channel) {
 		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionCreate, request.String())
 
 		response, err := mng.Start(&request)
+		if errors.Is(err, market.ErrProposalOutdated) {
+			// Let consumer know that it should refresh the proposal.
+			return c.Error(err)
+		}
 		if err != nil {
 			return fmt.Errorf("cannot start session: %s: %w", response.ID, err)
 		}
@@ -109,6 +115,25 @@ func subscribeSessionAcknowledge(mng *SessionManager, ch p2p.ChannelHandler) {
 	})
 }
 
+func subscribeSessionMigrate(mng *SessionManager, ch p2p.ChannelHandler) {
+	ch.Handle(p2p.TopicSessionMigrate, func(c p2p.Context) error {
+		var si pb.SessionInfo
+		if err := c.Request().UnmarshalProto(&si); err != nil {
+			return err
+		}
+		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionMigrate, si.String())
+		consumerID := identity.FromAddress(si.GetConsumerID())
+		sessionID := si.GetSessionID()
+
+		err := mng.Migrate(consumerID, sessionID)
+		if err != nil {
+			return fmt.Errorf("cannot migrate session %s: %w", sessionID, err)
+		}
+
+		return c.OK()
+	})
+}
+
 const bigIntBase int = 10
 
 func subscribeSessionPayments(mng *SessionManager, ch p2p.ChannelHandler) {
@@ -17,6 +17,8 @@
 
 package shaper
 
+import "github.com/mysteriumnetwork/node/eventbus"
+
 // Shaper shapes traffic on a network interface.
 type Shaper interface {
 	// Start applies shaping configuration on the specified interface and then continuously ensures it.
@@ -25,11 +27,7 @@ type Shaper interface {
 	Clear(interfaceName string)
 }
 
-type eventListener interface {
-	SubscribeAsync(topic string, fn interface{}) error
-}
-
 // New creates a traffic shaper (linux) or no-op.
-func New(listener eventListener) (shaper Shaper) {
+func New(listener eventbus.Subscriber) (shaper Shaper) {
 	return create(listener)
 }
@@ -1,3 +1,4 @@
+//go:build !linux
 // +build !linux
 
 /*
@@ -21,6 +22,7 @@ package shaper
 
 import (
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/rs/zerolog/log"
 )
 
@@ -28,7 +30,7 @@ import (
 type noopShaper struct {
 }
 
-func create(_ eventListener) *noopShaper {
+func create(_ eventbus.Subscriber) *noopShaper {
 	return &noopShaper{}
 }
 
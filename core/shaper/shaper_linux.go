@@ -20,6 +20,7 @@ package shaper
 import (
 	"github.com/mysteriumnetwork/go-wondershaper/wondershaper"
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
@@ -27,19 +28,17 @@ import (
 const limitKbps = 5000
 
 type linuxShaper struct {
-	ws          *wondershaper.Shaper
-	listener    eventListener
-	listenTopic string
+	ws       *wondershaper.Shaper
+	listener eventbus.Subscriber
 }
 
-func create(listener eventListener) *linuxShaper {
+func create(listener eventbus.Subscriber) *linuxShaper {
 	ws := wondershaper.New()
 	ws.Stdout = log.Logger
 	ws.Stderr = log.Logger
 	return &linuxShaper{
-		ws:          ws,
-		listener:    listener,
-		listenTopic: config.AppTopicConfig(config.FlagShaperEnabled.Name),
+		ws:       ws,
+		listener: listener,
 	}
 }
 
@@ -63,9 +62,11 @@ func (s *linuxShaper) Start(interfaceName string) error {
 		return nil
 	}
 
-	err := s.listener.SubscribeAsync(s.listenTopic, applyLimits)
+	err := config.SubscribeConfig(s.listener, config.FlagShaperEnabled.Name, eventbus.DeliverAsync, func(interface{}) {
+		_ = applyLimits()
+	})
 	if err != nil {
-		return errors.Wrap(err, "could not subscribe to topic: "+s.listenTopic)
+		return errors.Wrap(err, "could not subscribe to config: "+config.FlagShaperEnabled.Name)
 	}
 
 	return applyLimits()
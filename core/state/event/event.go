@@ -27,6 +27,7 @@ import (
 	"github.com/mysteriumnetwork/node/consumer/session"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/session/pingpong"
@@ -83,3 +84,13 @@ func (c Connection) String() string {
 		spent,
 	)
 }
+
+// PublishState publishes node state changes on the bus.
+func PublishState(publisher eventbus.Publisher, e State) {
+	eventbus.Handle(AppTopicState).Publish(publisher, e)
+}
+
+// SubscribeState subscribes fn to node state changes.
+func SubscribeState(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(State)) error {
+	return eventbus.Handle(AppTopicState).Subscribe(subscriber, delivery, fn)
+}
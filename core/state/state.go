@@ -19,6 +19,7 @@ package state
 
 import (
 	"math/big"
+	"strings"
 	"sync"
 	"time"
 
@@ -51,10 +52,6 @@ type natStatusProvider interface {
 	ConsumeNATEvent(event natEvent.Event)
 }
 
-type publisher interface {
-	Publish(topic string, data interface{})
-}
-
 type serviceLister interface {
 	List() map[service.ID]*service.Instance
 }
@@ -99,7 +96,7 @@ type Keeper struct {
 // KeeperDeps to construct the state.Keeper.
 type KeeperDeps struct {
 	NATStatusProvider         natStatusProvider
-	Publisher                 publisher
+	Publisher                 eventbus.Publisher
 	ServiceLister             serviceLister
 	IdentityProvider          identityProvider
 	IdentityRegistry          registry.IdentityRegistry
@@ -174,43 +171,46 @@ func (k *Keeper) fetchIdentities() []stateEvent.Identity {
 
 // Subscribe subscribes to the event bus.
 func (k *Keeper) Subscribe(bus eventbus.Subscriber) error {
-	if err := bus.SubscribeAsync(servicestate.AppTopicServiceStatus, k.consumeServiceStateEvent); err != nil {
+	if err := servicestate.SubscribeServiceStatus(bus, eventbus.DeliverAsync, func(e servicestate.AppEventServiceStatus) { k.consumeServiceStateEvent(e) }); err != nil {
+		return err
+	}
+	if err := sevent.SubscribeSession(bus, eventbus.DeliverAsync, k.consumeServiceSessionEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(sevent.AppTopicSession, k.consumeServiceSessionEvent); err != nil {
+	if err := sevent.SubscribeDataTransferred(bus, eventbus.DeliverAsync, func(e sevent.AppEventDataTransferred) { k.consumeServiceSessionStatisticsEvent(e) }); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(sevent.AppTopicDataTransferred, k.consumeServiceSessionStatisticsEvent); err != nil {
+	if err := sevent.SubscribeTokensEarned(bus, eventbus.DeliverAsync, func(e sevent.AppEventTokensEarned) { k.consumeServiceSessionEarningsEvent(e) }); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(sevent.AppTopicTokensEarned, k.consumeServiceSessionEarningsEvent); err != nil {
+	if err := natEvent.SubscribeTraversal(bus, eventbus.DeliverAsync, func(e natEvent.Event) { k.consumeNATEvent(e) }); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(natEvent.AppTopicTraversal, k.consumeNATEvent); err != nil {
+	if err := connectionstate.SubscribeConnectionState(bus, eventbus.DeliverAsync, k.consumeConnectionStateEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(connectionstate.AppTopicConnectionState, k.consumeConnectionStateEvent); err != nil {
+	if err := connectionstate.SubscribeConnectionStatistics(bus, eventbus.DeliverAsync, func(e connectionstate.AppEventConnectionStatistics) { k.consumeConnectionStatisticsEvent(e) }); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(connectionstate.AppTopicConnectionStatistics, k.consumeConnectionStatisticsEvent); err != nil {
+	if err := bandwidth.SubscribeConnectionThroughput(bus, eventbus.DeliverAsync, func(e bandwidth.AppEventConnectionThroughput) { k.consumeConnectionThroughputEvent(e) }); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(bandwidth.AppTopicConnectionThroughput, k.consumeConnectionThroughputEvent); err != nil {
+	if err := pingpongEvent.SubscribeInvoicePaid(bus, eventbus.DeliverAsync, func(e pingpongEvent.AppEventInvoicePaid) { k.consumeConnectionSpendingEvent(e) }); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(pingpongEvent.AppTopicInvoicePaid, k.consumeConnectionSpendingEvent); err != nil {
+	if err := identity.SubscribeIdentityCreated(bus, eventbus.DeliverAsync, k.consumeIdentityCreatedEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(identity.AppTopicIdentityCreated, k.consumeIdentityCreatedEvent); err != nil {
+	if err := identity.SubscribeIdentityDeleted(bus, eventbus.DeliverAsync, k.consumeIdentityDeletedEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(registry.AppTopicIdentityRegistration, k.consumeIdentityRegistrationEvent); err != nil {
+	if err := registry.SubscribeIdentityRegistration(bus, eventbus.DeliverAsync, k.consumeIdentityRegistrationEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(pingpongEvent.AppTopicBalanceChanged, k.consumeBalanceChangedEvent); err != nil {
+	if err := pingpongEvent.SubscribeBalanceChanged(bus, eventbus.DeliverAsync, k.consumeBalanceChangedEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(pingpongEvent.AppTopicEarningsChanged, k.consumeEarningsChangedEvent); err != nil {
+	if err := pingpongEvent.SubscribeEarningsChanged(bus, eventbus.DeliverAsync, k.consumeEarningsChangedEvent); err != nil {
 		return err
 	}
 	return nil
@@ -219,7 +219,7 @@ func (k *Keeper) Subscribe(bus eventbus.Subscriber) error {
 func (k *Keeper) announceState(_ interface{}) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
-	k.deps.Publisher.Publish(stateEvent.AppTopicState, *k.state)
+	stateEvent.PublishState(k.deps.Publisher, *k.state)
 }
 
 func (k *Keeper) updateServiceState(_ interface{}) {
@@ -242,9 +242,9 @@ func (k *Keeper) updateServices() {
 			ID:                   string(key),
 			ProviderID:           v.ProviderID.Address,
 			Type:                 v.Type,
-			Options:              v.Options,
+			Options:              v.Options(),
 			Status:               string(v.State()),
-			Proposal:             contract.NewProposalDTO(v.Proposal),
+			Proposal:             contract.NewProposalDTO(v.Proposal()),
 			ConnectionStatistics: match.ConnectionStatistics,
 		}
 		i++
@@ -295,6 +295,8 @@ func (k *Keeper) consumeServiceSessionEvent(e sevent.AppEventSession) {
 		k.incrementConnectCount(e.Service.ID, false)
 	case sevent.RemovedStatus:
 		k.removeSession(e)
+	case sevent.UpdatedStatus:
+		k.updateSession(e)
 	case sevent.AcknowledgedStatus:
 		k.incrementConnectCount(e.Service.ID, true)
 	}
@@ -332,6 +334,16 @@ func (k *Keeper) removeSession(e sevent.AppEventSession) {
 	}
 }
 
+func (k *Keeper) updateSession(e sevent.AppEventSession) {
+	for i := range k.state.Sessions {
+		if string(k.state.Sessions[i].SessionID) == e.Session.ID {
+			k.state.Sessions[i].EgressIP = e.Session.EgressIP
+			return
+		}
+	}
+	log.Warn().Msgf("Couldn't find a matching session for session update: %s", e.Session.ID)
+}
+
 // updates the data transfer info on the session
 func (k *Keeper) updateSessionStats(e interface{}) {
 	k.lock.Lock()
@@ -388,14 +400,9 @@ func (k *Keeper) updateSessionEarnings(e interface{}) {
 	go k.announceStateChanges(nil)
 }
 
-func (k *Keeper) consumeConnectionStateEvent(e interface{}) {
+func (k *Keeper) consumeConnectionStateEvent(evt connectionstate.AppEventConnectionState) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
-	evt, ok := e.(connectionstate.AppEventConnectionState)
-	if !ok {
-		log.Warn().Msg("Received a wrong kind of event for connection state update")
-		return
-	}
 
 	if evt.State == connectionstate.NotConnected {
 		k.state.Connection = stateEvent.Connection{}
@@ -449,14 +456,9 @@ func (k *Keeper) updateConnectionSpending(e interface{}) {
 	go k.announceStateChanges(nil)
 }
 
-func (k *Keeper) consumeBalanceChangedEvent(e interface{}) {
+func (k *Keeper) consumeBalanceChangedEvent(evt pingpongEvent.AppEventBalanceChanged) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
-	evt, ok := e.(pingpongEvent.AppEventBalanceChanged)
-	if !ok {
-		log.Warn().Msg("Received a wrong kind of event for balance change")
-		return
-	}
 	var id *stateEvent.Identity
 	for i := range k.state.Identities {
 		if k.state.Identities[i].Address == evt.Identity.Address {
@@ -472,14 +474,9 @@ func (k *Keeper) consumeBalanceChangedEvent(e interface{}) {
 	go k.announceStateChanges(nil)
 }
 
-func (k *Keeper) consumeEarningsChangedEvent(e interface{}) {
+func (k *Keeper) consumeEarningsChangedEvent(evt pingpongEvent.AppEventEarningsChanged) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
-	evt, ok := e.(pingpongEvent.AppEventEarningsChanged)
-	if !ok {
-		log.Warn().Msg("Received a wrong kind of event for earnings change")
-		return
-	}
 
 	k.state.ProviderChannels = k.deps.EarningsProvider.List(k.deps.ChainID)
 
@@ -500,20 +497,29 @@ func (k *Keeper) consumeEarningsChangedEvent(e interface{}) {
 	go k.announceStateChanges(nil)
 }
 
-func (k *Keeper) consumeIdentityCreatedEvent(_ interface{}) {
+func (k *Keeper) consumeIdentityCreatedEvent(_ string) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
 	k.state.Identities = k.fetchIdentities()
 	go k.announceStateChanges(nil)
 }
 
-func (k *Keeper) consumeIdentityRegistrationEvent(e interface{}) {
+func (k *Keeper) consumeIdentityDeletedEvent(e identity.AppEventIdentityDeleted) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
-	evt, ok := e.(registry.AppEventIdentityRegistration)
-	if !ok {
-		log.Warn().Msg("Received a wrong kind of event for identity registration")
+	identities := make([]stateEvent.Identity, 0, len(k.state.Identities))
+	for _, id := range k.state.Identities {
+		if !strings.EqualFold(id.Address, e.ID.Address) {
+			identities = append(identities, id)
+		}
 	}
+	k.state.Identities = identities
+	go k.announceStateChanges(nil)
+}
+
+func (k *Keeper) consumeIdentityRegistrationEvent(evt registry.AppEventIdentityRegistration) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
 	var id *stateEvent.Identity
 	for i := range k.state.Identities {
 		if k.state.Identities[i].Address == evt.ID.Address {
@@ -103,19 +103,6 @@ func (nspm *natStatusProviderMock) interactions() int {
 
 func (nspm *natStatusProviderMock) ConsumeNATEvent(event natEvent.Event) {}
 
-type mockPublisher struct {
-	lock           sync.Mutex
-	publishedTopic string
-	publishedData  interface{}
-}
-
-func (mp *mockPublisher) Publish(topic string, data interface{}) {
-	mp.lock.Lock()
-	defer mp.lock.Unlock()
-	mp.publishedData = data
-	mp.publishedTopic = topic
-}
-
 type serviceListerMock struct {
 	lock             sync.Mutex
 	numInteractions  int
@@ -139,7 +126,7 @@ func Test_ConsumesNATEvents(t *testing.T) {
 	natProvider := &natStatusProviderMock{
 		statusToReturn: mockNATStatus,
 	}
-	publisher := &mockPublisher{}
+	publisher := mocks.NewEventBus()
 	sl := &serviceListerMock{}
 
 	duration := time.Millisecond * 3
@@ -189,7 +176,7 @@ func Test_ConsumesSessionEvents(t *testing.T) {
 	keeper.Subscribe(eventBus)
 
 	// when
-	eventBus.Publish(sessionEvent.AppTopicSession, sessionEvent.AppEventSession{
+	sessionEvent.PublishSession(eventBus, sessionEvent.AppEventSession{
 		Status:  sessionEvent.CreatedStatus,
 		Session: expected,
 	})
@@ -216,7 +203,7 @@ func Test_ConsumesSessionEvents(t *testing.T) {
 	)
 
 	// when
-	eventBus.Publish(sessionEvent.AppTopicSession, sessionEvent.AppEventSession{
+	sessionEvent.PublishSession(eventBus, sessionEvent.AppEventSession{
 		Status:  sessionEvent.RemovedStatus,
 		Session: expected,
 	})
@@ -250,7 +237,7 @@ func Test_ConsumesSessionAcknowledgeEvents(t *testing.T) {
 	}
 
 	// when
-	eventBus.Publish(sessionEvent.AppTopicSession, sessionEvent.AppEventSession{
+	sessionEvent.PublishSession(eventBus, sessionEvent.AppEventSession{
 		Status: sessionEvent.AcknowledgedStatus,
 		Service: sessionEvent.ServiceContext{
 			ID: myID,
@@ -281,7 +268,7 @@ func Test_consumeServiceSessionEarningsEvent(t *testing.T) {
 	}
 
 	// when
-	eventBus.Publish(sessionEvent.AppTopicTokensEarned, sessionEvent.AppEventTokensEarned{
+	sessionEvent.PublishTokensEarned(eventBus, sessionEvent.AppEventTokensEarned{
 		SessionID: "1",
 		Total:     big.NewInt(500),
 	})
@@ -314,7 +301,7 @@ func Test_consumeServiceSessionStatisticsEvent(t *testing.T) {
 	}
 
 	// when
-	eventBus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{
+	sessionEvent.PublishDataTransferred(eventBus, sessionEvent.AppEventDataTransferred{
 		ID:   "1",
 		Up:   1,
 		Down: 2,
@@ -340,7 +327,7 @@ func Test_ConsumesServiceEvents(t *testing.T) {
 	natProvider := &natStatusProviderMock{
 		statusToReturn: mockNATStatus,
 	}
-	publisher := &mockPublisher{}
+	publisher := mocks.NewEventBus()
 	sl := &serviceListerMock{
 		servicesToReturn: map[service.ID]*service.Instance{
 			id: &expected,
@@ -368,9 +355,9 @@ func Test_ConsumesServiceEvents(t *testing.T) {
 	assert.Equal(t, string(id), actual.ID)
 	assert.Equal(t, expected.Type, actual.Type)
 	assert.Equal(t, expected.ProviderID.Address, actual.ProviderID)
-	assert.Equal(t, expected.Options, actual.Options)
+	assert.Equal(t, expected.Options(), actual.Options)
 	assert.Equal(t, string(expected.State()), actual.Status)
-	assert.EqualValues(t, contract.NewProposalDTO(expected.Proposal), actual.Proposal)
+	assert.EqualValues(t, contract.NewProposalDTO(expected.Proposal()), actual.Proposal)
 }
 
 func Test_ConsumesConnectionStateEvents(t *testing.T) {
@@ -390,7 +377,7 @@ func Test_ConsumesConnectionStateEvents(t *testing.T) {
 	assert.Equal(t, connectionstate.NotConnected, keeper.GetState().Connection.Session.State)
 
 	// when
-	eventBus.Publish(connectionstate.AppTopicConnectionState, connectionstate.AppEventConnectionState{
+	connectionstate.PublishConnectionState(eventBus, connectionstate.AppEventConnectionState{
 		State:       expected.State,
 		SessionInfo: expected,
 	})
@@ -423,7 +410,7 @@ func Test_ConsumesConnectionStatisticsEvents(t *testing.T) {
 	assert.True(t, keeper.GetState().Connection.Statistics.At.IsZero())
 
 	// when
-	eventBus.Publish(connectionstate.AppTopicConnectionStatistics, connectionstate.AppEventConnectionStatistics{
+	connectionstate.PublishConnectionStatistics(eventBus, connectionstate.AppEventConnectionStatistics{
 		Stats: expected,
 	})
 
@@ -454,7 +441,7 @@ func Test_ConsumesConnectionInvoiceEvents(t *testing.T) {
 	assert.True(t, keeper.GetState().Connection.Statistics.At.IsZero())
 
 	// when
-	eventBus.Publish(pingpongEvent.AppTopicInvoicePaid, pingpongEvent.AppEventInvoicePaid{
+	pingpongEvent.PublishInvoicePaid(eventBus, pingpongEvent.AppEventInvoicePaid{
 		Invoice: expected,
 	})
 
@@ -487,7 +474,7 @@ func Test_ConsumesBalanceChangeEvent(t *testing.T) {
 	assert.Zero(t, keeper.GetState().Identities[0].Balance.Uint64())
 
 	// when
-	eventBus.Publish(pingpongEvent.AppTopicBalanceChanged, pingpongEvent.AppEventBalanceChanged{
+	pingpongEvent.PublishBalanceChanged(eventBus, pingpongEvent.AppEventBalanceChanged{
 		Identity: identity.Identity{Address: "0x000000000000000000000000000000000000000a"},
 		Previous: big.NewInt(0),
 		Current:  big.NewInt(999),
@@ -534,7 +521,7 @@ func Test_ConsumesEarningsChangeEvent(t *testing.T) {
 		{ChannelID: "1"},
 		{ChannelID: "2"},
 	}
-	eventBus.Publish(pingpongEvent.AppTopicEarningsChanged, pingpongEvent.AppEventEarningsChanged{
+	pingpongEvent.PublishEarningsChanged(eventBus, pingpongEvent.AppEventEarningsChanged{
 		Identity: identity.Identity{Address: "0x000000000000000000000000000000000000000a"},
 		Previous: pingpongEvent.Earnings{},
 		Current:  pingpongEvent.Earnings{LifetimeBalance: big.NewInt(100), UnsettledBalance: big.NewInt(10)},
@@ -571,7 +558,7 @@ func Test_ConsumesIdentityRegistrationEvent(t *testing.T) {
 	assert.Equal(t, registry.Unregistered, keeper.GetState().Identities[0].RegistrationStatus)
 
 	// when
-	eventBus.Publish(registry.AppTopicIdentityRegistration, registry.AppEventIdentityRegistration{
+	registry.PublishIdentityRegistration(eventBus, registry.AppEventIdentityRegistration{
 		ID:     identity.Identity{Address: "0x000000000000000000000000000000000000000a"},
 		Status: registry.Registered,
 	})
@@ -582,12 +569,45 @@ func Test_ConsumesIdentityRegistrationEvent(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
+func Test_ConsumesIdentityDeletedEvent(t *testing.T) {
+	// given
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		NATStatusProvider: &natStatusProviderMock{statusToReturn: mockNATStatus},
+		Publisher:         eventBus,
+		ServiceLister:     &serviceListerMock{},
+		IdentityProvider: &mocks.IdentityProvider{
+			Identities: []identity.Identity{
+				{Address: "0x000000000000000000000000000000000000000a"},
+				{Address: "0x000000000000000000000000000000000000000b"},
+			},
+		},
+		IdentityRegistry:          &mocks.IdentityRegistry{Status: registry.Unregistered},
+		IdentityChannelCalculator: &mockChannelAddressCalculator{},
+		BalanceProvider:           &mockBalanceProvider{Balance: big.NewInt(0)},
+		EarningsProvider:          &mockEarningsProvider{},
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+	err := keeper.Subscribe(eventBus)
+	assert.NoError(t, err)
+	assert.Len(t, keeper.GetState().Identities, 2)
+
+	// when
+	identity.PublishIdentityDeleted(eventBus, identity.AppEventIdentityDeleted{ID: identity.FromAddress("0x000000000000000000000000000000000000000A")})
+
+	// then
+	assert.Eventually(t, func() bool {
+		ids := keeper.GetState().Identities
+		return len(ids) == 1 && ids[0].Address == "0x000000000000000000000000000000000000000b"
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
 func Test_getServiceByID(t *testing.T) {
 
 	natProvider := &natStatusProviderMock{
 		statusToReturn: mockNATStatus,
 	}
-	publisher := &mockPublisher{}
+	publisher := mocks.NewEventBus()
 	sl := &serviceListerMock{
 		servicesToReturn: map[service.ID]*service.Instance{},
 	}
@@ -623,7 +643,7 @@ func Test_incrementConnectionCount(t *testing.T) {
 	natProvider := &natStatusProviderMock{
 		statusToReturn: mockNATStatus,
 	}
-	publisher := &mockPublisher{}
+	publisher := mocks.NewEventBus()
 	sl := &serviceListerMock{
 		servicesToReturn: map[service.ID]*service.Instance{
 			id: &expected,
@@ -0,0 +1,180 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/asdine/storm/v3"
+	"github.com/mysteriumnetwork/node/core/storage"
+	bolt "go.etcd.io/bbolt"
+)
+
+// compactTxMaxSize limits amount of data written in a single transaction of compaction.
+const compactTxMaxSize = 64 << 20
+
+// Compact rewrites the database into a new file without free pages and replaces the current one.
+// Database is not available for other callers while it is being compacted.
+func (b *Bolt) Compact() (storage.Compaction, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	path := b.db.Bolt.Path()
+	before, err := os.Stat(path)
+	if err != nil {
+		return storage.Compaction{}, err
+	}
+
+	tmp := path + ".compact"
+	if err := compactFile(tmp, b.db.Bolt); err != nil {
+		os.Remove(tmp)
+		return storage.Compaction{}, fmt.Errorf("could not compact database: %w", err)
+	}
+
+	// The old file is kept until the compacted one is known to open.
+	compacted, err := storm.Open(tmp)
+	if err != nil {
+		os.Remove(tmp)
+		return storage.Compaction{}, fmt.Errorf("could not open compacted database: %w", err)
+	}
+	if err := compacted.Close(); err != nil {
+		os.Remove(tmp)
+		return storage.Compaction{}, err
+	}
+
+	if err := b.db.Close(); err != nil {
+		os.Remove(tmp)
+		return storage.Compaction{}, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		if reopenErr := b.reopen(path); reopenErr != nil {
+			return storage.Compaction{}, reopenErr
+		}
+		return storage.Compaction{}, fmt.Errorf("could not replace database: %w", err)
+	}
+	if err := b.reopen(path); err != nil {
+		return storage.Compaction{}, err
+	}
+
+	after, err := os.Stat(path)
+	if err != nil {
+		return storage.Compaction{}, err
+	}
+	return storage.Compaction{SizeBefore: before.Size(), SizeAfter: after.Size()}, nil
+}
+
+// reopen opens the database again after it was closed for compaction.
+// On failure every later storage call fails with a closed database, so the caller decides whether to shut down.
+func (b *Bolt) reopen(path string) error {
+	db, err := storm.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not reopen database after compaction: %w", err)
+	}
+	b.db = db
+	return nil
+}
+
+func compactFile(path string, src *bolt.DB) error {
+	dst, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	c := &compactor{dst: dst}
+	return src.View(func(srcTx *bolt.Tx) error {
+		if c.tx, err = dst.Begin(true); err != nil {
+			return err
+		}
+		err := srcTx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+			return c.copyBucket(bucket, [][]byte{name})
+		})
+		if err != nil {
+			c.tx.Rollback()
+			return err
+		}
+		// Keys and values of the source are valid only until its transaction is closed.
+		return c.tx.Commit()
+	})
+}
+
+// compactor copies buckets into destination database splitting the work into several transactions.
+type compactor struct {
+	dst  *bolt.DB
+	tx   *bolt.Tx
+	size int64
+}
+
+func (c *compactor) copyBucket(src *bolt.Bucket, path [][]byte) error {
+	dst, err := c.bucket(path)
+	if err != nil {
+		return err
+	}
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+
+	return src.ForEach(func(k, v []byte) error {
+		if v == nil {
+			return c.copyBucket(src.Bucket(k), append(path[:len(path):len(path)], k))
+		}
+		if err := c.grow(len(k) + len(v)); err != nil {
+			return err
+		}
+		dst, err := c.bucket(path)
+		if err != nil {
+			return err
+		}
+		return dst.Put(k, v)
+	})
+}
+
+// bucket returns the bucket of the given path in the current transaction.
+func (c *compactor) bucket(path [][]byte) (*bolt.Bucket, error) {
+	bucket, err := c.tx.CreateBucketIfNotExists(path[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range path[1:] {
+		if bucket, err = bucket.CreateBucketIfNotExists(name); err != nil {
+			return nil, err
+		}
+	}
+	// Rows are appended in key order, so pages can be filled completely.
+	bucket.FillPercent = 1.0
+	return bucket, nil
+}
+
+func (c *compactor) grow(size int) error {
+	c.size += int64(size)
+	if c.size < compactTxMaxSize {
+		return nil
+	}
+
+	if err := c.tx.Commit(); err != nil {
+		return err
+	}
+	tx, err := c.dst.Begin(true)
+	if err != nil {
+		return err
+	}
+	c.tx, c.size = tx, 0
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type compactTestType struct {
+	ID      int    `storm:"id,increment"`
+	Payload string `storm:"index"`
+}
+
+func Test_StorageCompact(t *testing.T) {
+	storage, close, err := createMockStorage(t)
+	require.NoError(t, err)
+	defer close()
+
+	payload := strings.Repeat("x", 4096)
+	for i := 0; i < 500; i++ {
+		require.NoError(t, storage.Store(bucket, &compactTestType{Payload: payload}))
+	}
+	var all []compactTestType
+	require.NoError(t, storage.GetAllFrom(bucket, &all))
+	for i := range all[1:] {
+		require.NoError(t, storage.Delete(bucket, &all[i+1]))
+	}
+	require.NoError(t, storage.SetValue("values", "key", "value"))
+
+	compaction, err := storage.Compact()
+	require.NoError(t, err)
+	assert.True(t, compaction.SizeAfter < compaction.SizeBefore)
+
+	var value string
+	assert.NoError(t, storage.GetValue("values", "key", &value))
+	assert.Equal(t, "value", value)
+
+	var left []compactTestType
+	assert.NoError(t, storage.GetAllFrom(bucket, &left))
+	assert.Equal(t, []compactTestType{all[0]}, left)
+
+	next := compactTestType{Payload: "y"}
+	assert.NoError(t, storage.Store(bucket, &next))
+	assert.Equal(t, 501, next.ID)
+}
+
+func Test_StorageCompactKeepsDatabaseOnFailure(t *testing.T) {
+	storage, close, err := createMockStorage(t)
+	require.NoError(t, err)
+	defer close()
+
+	require.NoError(t, storage.SetValue("values", "key", "value"))
+	// Compacted file can't be created in place of a directory.
+	require.NoError(t, os.Mkdir(storage.db.Bolt.Path()+".compact", 0700))
+
+	_, err = storage.Compact()
+	assert.Error(t, err)
+
+	var value string
+	assert.NoError(t, storage.GetValue("values", "key", &value))
+	assert.Equal(t, "value", value)
+}
@@ -37,4 +37,10 @@ var Sequence = []migrations.Migration{
 			2020, 8, 17, 14, 27, 00, 0, time.UTC),
 		Migrate: migrations.SettlementValuesToRows,
 	},
+	{
+		Name: "session-history-daily",
+		Date: time.Date(
+			2020, 10, 19, 12, 00, 00, 0, time.UTC),
+		Migrate: migrations.SessionHistoryDaily,
+	},
 }
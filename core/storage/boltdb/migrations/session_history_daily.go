@@ -0,0 +1,32 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migrations
+
+import (
+	"github.com/asdine/storm/v3"
+	"github.com/mysteriumnetwork/node/consumer/session"
+)
+
+const sessionHistoryDailyBucket = "session-history-daily"
+
+// SessionHistoryDaily only creates the empty bucket for daily session aggregates.
+// Existing sessions are not rolled up here, as retention limits are node configuration unknown to migrations,
+// the retention job rolls them up when the node starts.
+func SessionHistoryDaily(db *storm.DB) error {
+	return db.From(sessionHistoryDailyBucket).Init(&session.Aggregate{})
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migrations
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/bbolt"
+)
+
+func Test_SessionHistoryDaily(t *testing.T) {
+	// given
+	file, db := boltdbtest.CreateDB(t)
+	defer boltdbtest.CleanupDB(t, file, db)
+
+	// when
+	err := SessionHistoryDaily(db)
+	assert.NoError(t, err)
+
+	// then
+	var aggregates []session.Aggregate
+	assert.NoError(t, db.From(sessionHistoryDailyBucket).All(&aggregates))
+	assert.Empty(t, aggregates)
+	assert.NoError(t, db.Bolt.View(func(tx *bbolt.Tx) error {
+		assert.NotNil(t, tx.Bucket([]byte(sessionHistoryDailyBucket)))
+		return nil
+	}))
+}
@@ -18,14 +18,19 @@
 package boltdb
 
 import (
+	"io"
 	"path/filepath"
+	"reflect"
+	"sync"
 
 	"github.com/asdine/storm/v3"
 	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
 )
 
 // Bolt is a wrapper around boltdb
 type Bolt struct {
+	mu sync.RWMutex
 	db *storm.DB
 }
 
@@ -37,60 +42,200 @@ func NewStorage(path string) (*Bolt, error) {
 // openDB creates new or open existing BoltDB
 func openDB(name string) (*Bolt, error) {
 	db, err := storm.Open(name)
-	return &Bolt{db}, errors.Wrap(err, "failed to open boltDB")
+	return &Bolt{db: db}, errors.Wrap(err, "failed to open boltDB")
 }
 
 // GetValue gets key value
 func (b *Bolt) GetValue(bucket string, key interface{}, to interface{}) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	return b.db.Get(bucket, key, to)
 }
 
 // SetValue sets key value
 func (b *Bolt) SetValue(bucket string, key interface{}, to interface{}) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	return b.db.Set(bucket, key, to)
 }
 
+// GetAllValues gets all values of the bucket stored by SetValue
+func (b *Bolt) GetAllValues(bucket string, to interface{}) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	ref := reflect.ValueOf(to)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Slice {
+		return errors.New("provided target must be a pointer to slice")
+	}
+	slice := ref.Elem()
+	slice.SetLen(0)
+
+	return b.db.Bolt.View(func(tx *bolt.Tx) error {
+		values := tx.Bucket([]byte(bucket))
+		if values == nil {
+			return nil
+		}
+
+		return values.ForEach(func(k, v []byte) error {
+			// Skip nested buckets of storm metadata and structs.
+			if v == nil {
+				return nil
+			}
+
+			value := reflect.New(slice.Type().Elem())
+			if err := b.db.Codec().Unmarshal(v, value.Interface()); err != nil {
+				return err
+			}
+			slice.Set(reflect.Append(slice, value.Elem()))
+			return nil
+		})
+	})
+}
+
+// DeleteValues removes values of the bucket stored by SetValue for which remove returns true, in a single transaction.
+func (b *Bolt) DeleteValues(bucket string, sample interface{}, remove func(value interface{}) bool) (int, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	ref := reflect.ValueOf(sample)
+	if ref.Kind() != reflect.Ptr {
+		return 0, errors.New("provided sample must be a pointer")
+	}
+
+	deleted := 0
+	err := b.db.Bolt.Update(func(tx *bolt.Tx) error {
+		values := tx.Bucket([]byte(bucket))
+		if values == nil {
+			return nil
+		}
+
+		// Keys are collected first, as deleting while iterating makes the cursor skip keys.
+		var keys [][]byte
+		err := values.ForEach(func(k, v []byte) error {
+			// Skip nested buckets of storm metadata and structs.
+			if v == nil {
+				return nil
+			}
+
+			value := reflect.New(ref.Elem().Type())
+			if err := b.db.Codec().Unmarshal(v, value.Interface()); err != nil {
+				return err
+			}
+			if remove(value.Interface()) {
+				keys = append(keys, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			if err := values.Delete(k); err != nil {
+				return err
+			}
+		}
+		deleted = len(keys)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
 // Store allows to keep struct grouped by the bucket
 func (b *Bolt) Store(bucket string, data interface{}) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	return b.db.From(bucket).Save(data)
 }
 
 // GetAllFrom allows to get all structs from the bucket
 func (b *Bolt) GetAllFrom(bucket string, data interface{}) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	return b.db.From(bucket).All(data)
 }
 
 // Delete removes the given struct from the given bucket
 func (b *Bolt) Delete(bucket string, data interface{}) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	return b.db.From(bucket).DeleteStruct(data)
 }
 
 // Update allows to update the struct in the given bucket
 func (b *Bolt) Update(bucket string, object interface{}) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	return b.db.From(bucket).Update(object)
 }
 
 // GetOneByField returns an object from the given bucket by the given field
 func (b *Bolt) GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	return b.db.From(bucket).One(fieldName, key, to)
 }
 
 // GetLast returns the last entry in the bucket
 func (b *Bolt) GetLast(bucket string, to interface{}) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	return b.db.From(bucket).Select().Reverse().First(to)
 }
 
 // GetBuckets returns a list of buckets
 func (b *Bolt) GetBuckets() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	return b.db.Bucket()
 }
 
+// Snapshot writes consistent copy of the whole database to the given writer.
+func (b *Bolt) Snapshot(w io.Writer) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.db.Bolt.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// WithDB calls fn with raw storm DB, which is not replaced by compaction until fn returns.
+// fn must not call other methods of Bolt.
+func (b *Bolt) WithDB(fn func(db *storm.DB) error) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return fn(b.db)
+}
+
 // DB returns raw storm DB.
+// It must not be used concurrently with Compact, use WithDB instead.
 func (b *Bolt) DB() *storm.DB {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
 	return b.db
 }
 
 // Close closes database
 func (b *Bolt) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	return b.db.Close()
 }
@@ -22,4 +22,6 @@ import "github.com/asdine/storm/v3"
 var (
 	// ErrNotFound not found
 	ErrNotFound = storm.ErrNotFound
+	// ErrZeroID id field is not set
+	ErrZeroID = storm.ErrZeroID
 )
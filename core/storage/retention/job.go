@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package retention
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const day = 24 * time.Hour
+
+// Policy limits how much of history is kept, zero values mean unlimited.
+type Policy struct {
+	MaxAge  time.Duration
+	MaxRows int
+}
+
+// Enabled returns true if policy limits history in any way.
+func (p Policy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxRows > 0
+}
+
+type sessionRoller interface {
+	RollUp(before time.Time, keep int) (int, error)
+}
+
+type settlementPruner interface {
+	Prune(before time.Time, keep int) (int, error)
+}
+
+type agreementPruner interface {
+	PruneR(before time.Time, keep int) (int, error)
+}
+
+// Job periodically rolls old sessions up into daily aggregates and removes old settlements and agreement R values.
+type Job struct {
+	policy      Policy
+	sessions    sessionRoller
+	settlements settlementPruner
+	agreements  agreementPruner
+	timeGetter  func() time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewJob creates retention job for the given policy.
+func NewJob(policy Policy, sessions sessionRoller, settlements settlementPruner, agreements agreementPruner) *Job {
+	return &Job{
+		policy:      policy,
+		sessions:    sessions,
+		settlements: settlements,
+		agreements:  agreements,
+		timeGetter:  time.Now,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Start applies retention policy right away and then periodically.
+func (j *Job) Start(interval time.Duration) {
+	go func() {
+		j.run()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				j.run()
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops periodic retention.
+func (j *Job) Stop() {
+	j.stopOnce.Do(func() {
+		close(j.stop)
+	})
+}
+
+// Run applies retention policy once.
+func (j *Job) Run() error {
+	var before time.Time
+	if j.policy.MaxAge > 0 {
+		// Whole days are rolled up, so every daily aggregate is complete.
+		before = j.timeGetter().UTC().Add(-j.policy.MaxAge).Truncate(day)
+	}
+
+	rolled, err := j.sessions.RollUp(before, j.policy.MaxRows)
+	if err != nil {
+		return err
+	}
+	pruned, err := j.settlements.Prune(before, j.policy.MaxRows)
+	if err != nil {
+		return err
+	}
+	prunedR, err := j.agreements.PruneR(before, j.policy.MaxRows)
+	if err != nil {
+		return err
+	}
+
+	if rolled > 0 || pruned > 0 || prunedR > 0 {
+		log.Info().Msgf("History retention rolled up %d sessions and removed %d settlements and %d agreement R values", rolled, pruned, prunedR)
+	}
+	return nil
+}
+
+func (j *Job) run() {
+	if err := j.Run(); err != nil {
+		log.Error().Err(err).Msg("History retention failed")
+	}
+}
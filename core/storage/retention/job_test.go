@@ -0,0 +1,73 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pruneCall struct {
+	before time.Time
+	keep   int
+}
+
+type prunerFake struct {
+	calls []pruneCall
+}
+
+func (pf *prunerFake) RollUp(before time.Time, keep int) (int, error) {
+	pf.calls = append(pf.calls, pruneCall{before: before, keep: keep})
+	return 1, nil
+}
+
+func (pf *prunerFake) Prune(before time.Time, keep int) (int, error) {
+	return pf.RollUp(before, keep)
+}
+
+func (pf *prunerFake) PruneR(before time.Time, keep int) (int, error) {
+	return pf.RollUp(before, keep)
+}
+
+func TestJob_Run(t *testing.T) {
+	sessions, settlements, agreements := &prunerFake{}, &prunerFake{}, &prunerFake{}
+	job := NewJob(Policy{MaxAge: 48 * time.Hour, MaxRows: 100}, sessions, settlements, agreements)
+	job.timeGetter = func() time.Time {
+		return time.Date(2020, 10, 19, 15, 30, 0, 0, time.UTC)
+	}
+
+	assert.NoError(t, job.Run())
+
+	expected := []pruneCall{{before: time.Date(2020, 10, 17, 0, 0, 0, 0, time.UTC), keep: 100}}
+	assert.Equal(t, expected, sessions.calls)
+	assert.Equal(t, expected, settlements.calls)
+	assert.Equal(t, expected, agreements.calls)
+}
+
+func TestJob_RunWithoutMaxAge(t *testing.T) {
+	sessions, settlements, agreements := &prunerFake{}, &prunerFake{}, &prunerFake{}
+	job := NewJob(Policy{MaxRows: 10}, sessions, settlements, agreements)
+
+	assert.NoError(t, job.Run())
+
+	assert.Equal(t, []pruneCall{{keep: 10}}, sessions.calls)
+	assert.Equal(t, []pruneCall{{keep: 10}}, settlements.calls)
+	assert.Equal(t, []pruneCall{{keep: 10}}, agreements.calls)
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+)
+
+var (
+	errStructPtrNeeded = errors.New("provided target must be a pointer to struct")
+	errSlicePtrNeeded  = errors.New("provided target must be a pointer to slice")
+	errNoID            = errors.New("missing struct tag id or ID field")
+)
+
+// encodeKey encodes the key the same way as boltdb storage does.
+func encodeKey(key interface{}) ([]byte, error) {
+	switch k := key.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return k, nil
+	case string:
+		return []byte(k), nil
+	case int:
+		return numberToBytes(int64(k))
+	case uint:
+		return numberToBytes(uint64(k))
+	case int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		return numberToBytes(k)
+	default:
+		return json.Marshal(key)
+	}
+}
+
+func numberToBytes(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.BigEndian, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// structBucket returns the bucket structs of the type are stored in, structs are nested under their type name.
+func structBucket(bucket string, typ reflect.Type) string {
+	return bucket + "/" + typ.Name()
+}
+
+type id struct {
+	name      string
+	value     reflect.Value
+	increment bool
+}
+
+// idField finds the field tagged with `storm:"id"`, falling back to the field named ID.
+func idField(s reflect.Value) (id, error) {
+	typ := s.Type()
+	var fallback *id
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		for _, tag := range strings.Split(field.Tag.Get("storm"), ",") {
+			if tag == "id" {
+				return id{
+					name:      field.Name,
+					value:     s.Field(i),
+					increment: strings.Contains(field.Tag.Get("storm"), "increment"),
+				}, nil
+			}
+		}
+		if field.Name == "ID" {
+			fallback = &id{name: field.Name, value: s.Field(i)}
+		}
+	}
+	if fallback == nil {
+		return id{}, errNoID
+	}
+	return *fallback, nil
+}
+
+func structKey(bucket string, data interface{}) (string, []byte, error) {
+	ref := reflect.ValueOf(data)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Struct {
+		return "", nil, errStructPtrNeeded
+	}
+	id, err := idField(ref.Elem())
+	if err != nil {
+		return "", nil, err
+	}
+	if id.value.IsZero() {
+		return "", nil, storage.ErrZeroID
+	}
+	key, err := encodeKey(id.value.Interface())
+	if err != nil {
+		return "", nil, err
+	}
+	return structBucket(bucket, ref.Elem().Type()), key, nil
+}
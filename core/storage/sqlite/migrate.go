@@ -0,0 +1,185 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rs/zerolog/log"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrator"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+const (
+	boltDatabaseFile = "myst.db"
+
+	sessionHistoryBucket   = "session-history/History"
+	sessionAggregateBucket = "session-history-daily/Aggregate"
+	settlementBucket       = "settlement-history/SettlementHistoryEntry"
+
+	stormMetadataBucket = "__storm_metadata"
+	stormCounterSuffix  = "counter"
+)
+
+// MigrateFromBolt copies boltdb storage of the given directory into a new sqlite storage.
+// Node must not be running, boltdb file is left intact.
+func MigrateFromBolt(dir string) error {
+	target := filepath.Join(dir, DatabaseFile)
+	if _, err := os.Stat(target); err == nil {
+		return fmt.Errorf("sqlite storage %s already exists", target)
+	}
+	source := filepath.Join(dir, boltDatabaseFile)
+	if _, err := os.Stat(source); err != nil {
+		return fmt.Errorf("boltdb storage not found: %w", err)
+	}
+	if err := ensureNotLocked(source); err != nil {
+		return err
+	}
+
+	boltStorage, err := boltdb.NewStorage(dir)
+	if err != nil {
+		return err
+	}
+	defer boltStorage.Close()
+	if err := migrator.NewMigrator(boltStorage).RunMigrations(history.Sequence); err != nil {
+		return fmt.Errorf("could not migrate boltdb storage: %w", err)
+	}
+
+	tmp := target + ".migrating"
+	os.Remove(tmp)
+	db, err := open(tmp)
+	if err != nil {
+		return err
+	}
+	err = boltStorage.WithDB(func(source *storm.DB) error {
+		return copyBolt(source.Bolt, db)
+	})
+	if closeErr := db.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("could not copy boltdb storage: %w", err)
+	}
+	return os.Rename(tmp, target)
+}
+
+// ensureNotLocked fails if the boltdb file is held open by a running node, instead of waiting for it.
+func ensureNotLocked(path string) error {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return errors.New("boltdb storage is in use, stop the node first")
+	}
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+func copyBolt(source *bolt.DB, target *sql.DB) error {
+	tx, err := target.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	count := 0
+	err = source.View(func(sourceTx *bolt.Tx) error {
+		return sourceTx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return copyBucket(tx, string(name), b, &count)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msgf("Copied %d entries from boltdb storage", count)
+	return tx.Commit()
+}
+
+func copyBucket(tx *sql.Tx, path string, b *bolt.Bucket, count *int) error {
+	return b.ForEach(func(k, v []byte) error {
+		if v == nil {
+			if string(k) == stormMetadataBucket {
+				return copyCounter(tx, path, b.Bucket(k))
+			}
+			// Storm keeps indexes in buckets, sqlite does not need them.
+			if strings.HasPrefix(string(k), "__storm") {
+				return nil
+			}
+			return copyBucket(tx, path+"/"+string(k), b.Bucket(k), count)
+		}
+
+		*count++
+		switch path {
+		case sessionHistoryBucket:
+			var history session.History
+			if err := json.Unmarshal(v, &history); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`INSERT INTO session_history (`+sessionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, sessionValues(history)...)
+			return err
+		case sessionAggregateBucket:
+			var aggregate session.Aggregate
+			if err := json.Unmarshal(v, &aggregate); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`INSERT INTO session_history_daily (`+aggregateColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, aggregateValues(aggregate)...)
+			return err
+		case settlementBucket:
+			var entry pingpong.SettlementHistoryEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			values, err := settlementValues(entry)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`INSERT INTO settlement_history (`+settlementColumns+`) VALUES (?, ?, ?, ?, ?)`, values...)
+			return err
+		default:
+			_, err := tx.Exec(`INSERT INTO entries (bucket, key, value) VALUES (?, ?, ?)`, path, k, v)
+			return err
+		}
+	})
+}
+
+// copyCounter copies the last id storm has assigned to the structs of the bucket.
+func copyCounter(tx *sql.Tx, path string, metadata *bolt.Bucket) error {
+	return metadata.ForEach(func(k, v []byte) error {
+		if !strings.HasSuffix(string(k), stormCounterSuffix) || len(v) != 8 {
+			return nil
+		}
+		_, err := tx.Exec(`INSERT OR REPLACE INTO sequences (bucket, value) VALUES (?, ?)`, path, int64(binary.BigEndian.Uint64(v)))
+		return err
+	})
+}
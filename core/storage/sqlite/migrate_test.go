@@ -0,0 +1,88 @@
+// +build cgo
+
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+func Test_MigrateFromBolt(t *testing.T) {
+	dir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+	require.NoError(t, bolt.SetValue(bucket, "key", "value"))
+	require.NoError(t, bolt.Store(bucket, &testEntry{Name: "first"}))
+	require.NoError(t, bolt.Store(bucket, &testEntry{Name: "second"}))
+	repository := session.NewBoltRepository(bolt)
+	for _, history := range testHistory {
+		require.NoError(t, repository.Store(history))
+	}
+	rolled, err := repository.RollUp(day2, 0, 10, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, rolled)
+	stats, err := repository.Stats(session.NewFilter())
+	require.NoError(t, err)
+	require.NoError(t, pingpong.NewSettlementHistoryBoltRepository(bolt).Store(testSettlements[0]))
+	require.NoError(t, bolt.Close())
+
+	assert.NoError(t, MigrateFromBolt(dir))
+	assert.Error(t, MigrateFromBolt(dir))
+	_, err = os.Stat(filepath.Join(dir, "myst.db"))
+	assert.NoError(t, err)
+
+	s, err := NewStorage(dir)
+	require.NoError(t, err)
+	defer s.Close()
+
+	var value string
+	assert.NoError(t, s.GetValue(bucket, "key", &value))
+	assert.Equal(t, "value", value)
+
+	var entries []testEntry
+	assert.NoError(t, s.GetAllFrom(bucket, &entries))
+	assert.Equal(t, []testEntry{{ID: 1, Name: "first"}, {ID: 2, Name: "second"}}, entries)
+	assert.NoError(t, s.Store(bucket, &testEntry{Name: "third"}))
+	assert.NoError(t, s.GetOneByField(bucket, "Name", "third", &entries[0]))
+	assert.Equal(t, int64(3), entries[0].ID)
+
+	migrated := NewSessionRepository(s)
+	list, err := migrated.List(session.NewFilter())
+	assert.NoError(t, err)
+	assert.Equal(t, []session.History{testHistory[2]}, list)
+	migratedStats, err := migrated.Stats(session.NewFilter())
+	assert.NoError(t, err)
+	assert.Equal(t, stats, migratedStats)
+
+	settlements, err := NewSettlementHistoryRepository(s).List(pingpong.SettlementHistoryFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, testSettlements[:1], settlements)
+}
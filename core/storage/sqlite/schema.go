@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// schema holds the statements applied in order, the index of the last applied one is kept in user_version.
+var schema = []string{
+	`CREATE TABLE entries (
+		bucket TEXT NOT NULL,
+		key BLOB NOT NULL,
+		value BLOB NOT NULL,
+		PRIMARY KEY (bucket, key)
+	)`,
+	`CREATE TABLE sequences (
+		bucket TEXT NOT NULL PRIMARY KEY,
+		value INTEGER NOT NULL
+	)`,
+	`CREATE TABLE session_history (
+		session_id TEXT NOT NULL PRIMARY KEY,
+		direction TEXT NOT NULL,
+		consumer_id TEXT NOT NULL,
+		hermes_id TEXT NOT NULL,
+		provider_id TEXT NOT NULL,
+		service_type TEXT NOT NULL,
+		consumer_country TEXT NOT NULL,
+		provider_country TEXT NOT NULL,
+		egress_ip TEXT NOT NULL,
+		node_type TEXT NOT NULL,
+		data_sent INTEGER NOT NULL,
+		data_received INTEGER NOT NULL,
+		tokens TEXT,
+		status TEXT NOT NULL,
+		started INTEGER NOT NULL,
+		updated INTEGER NOT NULL
+	)`,
+	`CREATE INDEX session_history_started ON session_history (started)`,
+	`CREATE INDEX session_history_consumer ON session_history (consumer_id, started)`,
+	`CREATE INDEX session_history_provider ON session_history (provider_id, started)`,
+	`CREATE TABLE session_history_daily (
+		id TEXT NOT NULL PRIMARY KEY,
+		day INTEGER NOT NULL,
+		direction TEXT NOT NULL,
+		consumer_id TEXT NOT NULL,
+		hermes_id TEXT NOT NULL,
+		provider_id TEXT NOT NULL,
+		service_type TEXT NOT NULL,
+		status TEXT NOT NULL,
+		count INTEGER NOT NULL,
+		data_sent INTEGER NOT NULL,
+		data_received INTEGER NOT NULL,
+		duration INTEGER NOT NULL,
+		tokens TEXT
+	)`,
+	`CREATE INDEX session_history_daily_day ON session_history_daily (day)`,
+	`CREATE TABLE settlement_history (
+		tx_hash TEXT NOT NULL PRIMARY KEY,
+		provider_id TEXT NOT NULL,
+		hermes_id TEXT NOT NULL,
+		time INTEGER NOT NULL,
+		entry BLOB NOT NULL
+	)`,
+	`CREATE INDEX settlement_history_time ON settlement_history (time)`,
+}
+
+func migrateSchema(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("could not read schema version: %w", err)
+	}
+	if version > len(schema) {
+		return fmt.Errorf("database schema version %d is newer than supported %d", version, len(schema))
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := version; i < len(schema); i++ {
+		if _, err := tx.Exec(schema[i]); err != nil {
+			return fmt.Errorf("could not apply schema change %d: %w", i+1, err)
+		}
+	}
+	// PRAGMA does not support placeholders.
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, len(schema))); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
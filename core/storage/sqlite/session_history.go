@@ -0,0 +1,481 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/identity"
+	session_node "github.com/mysteriumnetwork/node/session"
+)
+
+const sessionColumns = `session_id, direction, consumer_id, hermes_id, provider_id, service_type,
+	consumer_country, provider_country, egress_ip, node_type, data_sent, data_received, tokens, status, started, updated`
+
+const aggregateColumns = `id, day, direction, consumer_id, hermes_id, provider_id, service_type, status,
+	count, data_sent, data_received, duration, tokens`
+
+// SessionRepository stores session history in sqlite tables, so it can be filtered by indexes.
+type SessionRepository struct {
+	storage *Storage
+}
+
+// NewSessionRepository creates session history repository stored in sqlite.
+func NewSessionRepository(storage *Storage) *SessionRepository {
+	return &SessionRepository{storage: storage}
+}
+
+// Store inserts new session.
+func (sr *SessionRepository) Store(history session.History) error {
+	_, err := sr.storage.DB().Exec(
+		`INSERT INTO session_history (`+sessionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sessionValues(history)...,
+	)
+	return err
+}
+
+// Update updates existing session.
+func (sr *SessionRepository) Update(history session.History) error {
+	values := sessionValues(history)
+	res, err := sr.storage.DB().Exec(
+		`UPDATE session_history SET direction = ?, consumer_id = ?, hermes_id = ?, provider_id = ?, service_type = ?,
+			consumer_country = ?, provider_country = ?, egress_ip = ?, node_type = ?, data_sent = ?, data_received = ?,
+			tokens = ?, status = ?, started = ?, updated = ?
+		WHERE session_id = ?`,
+		append(values[1:], values[0])...,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// List returns sessions matching the filter, most recent first.
+func (sr *SessionRepository) List(filter *session.Filter) ([]session.History, error) {
+	where, args := sessionWhere(filter)
+	result := []session.History{}
+	err := sr.eachSession(where+` ORDER BY started DESC`, args, func(history session.History) {
+		result = append(result, history)
+	})
+	return result, err
+}
+
+// Stats returns statistics of sessions and daily aggregates matching the filter.
+func (sr *SessionRepository) Stats(filter *session.Filter) (session.Stats, error) {
+	result := session.NewStats()
+
+	where, args := sessionWhere(filter)
+	err := sr.eachSession(where, args, func(history session.History) {
+		result.Add(history)
+	})
+	if err != nil {
+		return result, err
+	}
+
+	where, args = aggregateWhere(filter)
+	err = sr.eachAggregate(sr.storage.DB(), where, args, func(aggregate session.Aggregate) {
+		result.AddAggregate(aggregate)
+	})
+	return result, err
+}
+
+// StatsByDay returns statistics of sessions and daily aggregates matching the filter grouped by day.
+func (sr *SessionRepository) StatsByDay(filter *session.Filter) (map[time.Time]session.Stats, error) {
+	result := make(map[time.Time]session.Stats)
+	add := func(day time.Time, fn func(stats *session.Stats)) {
+		stats, ok := result[day]
+		if !ok {
+			stats = session.NewStats()
+		}
+		fn(&stats)
+		result[day] = stats
+	}
+
+	where, args := sessionWhere(filter)
+	err := sr.eachSession(where, args, func(history session.History) {
+		add(history.Started.Truncate(24*time.Hour), func(stats *session.Stats) { stats.Add(history) })
+	})
+	if err != nil {
+		return result, err
+	}
+
+	where, args = aggregateWhere(filter)
+	err = sr.eachAggregate(sr.storage.DB(), where, args, func(aggregate session.Aggregate) {
+		add(aggregate.Day, func(stats *session.Stats) { stats.AddAggregate(aggregate) })
+	})
+	return result, err
+}
+
+// RollUp moves oldest sessions outside of retention limits into daily aggregates.
+func (sr *SessionRepository) RollUp(before time.Time, keep, limit int, skip []session_node.ID) (int, error) {
+	tx, err := sr.storage.DB().Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	inactive := `1 = 1`
+	args := make([]interface{}, 0, len(skip))
+	if len(skip) > 0 {
+		inactive = `session_id NOT IN (?` + strings.Repeat(`, ?`, len(skip)-1) + `)`
+		for _, id := range skip {
+			args = append(args, string(id))
+		}
+	}
+
+	expiredCount := 0
+	if keep > 0 {
+		var total int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM session_history WHERE `+inactive, args...).Scan(&total); err != nil {
+			return 0, err
+		}
+		expiredCount = total - keep
+	}
+	if !before.IsZero() {
+		var old int
+		err := tx.QueryRow(
+			`SELECT COUNT(*) FROM session_history WHERE `+inactive+` AND started < ?`,
+			append(args, encodeTime(before))...,
+		).Scan(&old)
+		if err != nil {
+			return 0, err
+		}
+		if old > expiredCount {
+			expiredCount = old
+		}
+	}
+	if expiredCount <= 0 {
+		return 0, nil
+	}
+	if expiredCount > limit {
+		expiredCount = limit
+	}
+
+	rows, err := tx.Query(
+		`SELECT `+sessionColumns+` FROM session_history WHERE `+inactive+` ORDER BY started LIMIT ?`,
+		append(args, expiredCount)...,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var expired []session.History
+	for rows.Next() {
+		history, err := scanSession(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		expired = append(expired, history)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	changed := make(map[string]*session.Aggregate)
+	for i := range expired {
+		aggregate := session.NewAggregate(expired[i])
+		if existing, ok := changed[aggregate.ID]; ok {
+			aggregate = *existing
+		} else {
+			err := sr.eachAggregate(tx, ` WHERE id = ?`, []interface{}{aggregate.ID}, func(stored session.Aggregate) {
+				aggregate = stored
+			})
+			if err != nil {
+				return 0, err
+			}
+		}
+		aggregate.Add(expired[i])
+		changed[aggregate.ID] = &aggregate
+
+		if _, err := tx.Exec(`DELETE FROM session_history WHERE session_id = ?`, string(expired[i].SessionID)); err != nil {
+			return 0, err
+		}
+	}
+	for _, aggregate := range changed {
+		_, err := tx.Exec(
+			`INSERT OR REPLACE INTO session_history_daily (`+aggregateColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			aggregateValues(*aggregate)...,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(expired), tx.Commit()
+}
+
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func (sr *SessionRepository) eachSession(where string, args []interface{}, fn func(history session.History)) error {
+	rows, err := sr.storage.DB().Query(`SELECT `+sessionColumns+` FROM session_history`+where, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		history, err := scanSession(rows)
+		if err != nil {
+			return err
+		}
+		fn(history)
+	}
+	return rows.Err()
+}
+
+func (sr *SessionRepository) eachAggregate(db querier, where string, args []interface{}, fn func(aggregate session.Aggregate)) error {
+	rows, err := db.Query(`SELECT `+aggregateColumns+` FROM session_history_daily`+where, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		aggregate, err := scanAggregate(rows)
+		if err != nil {
+			return err
+		}
+		fn(aggregate)
+	}
+	return rows.Err()
+}
+
+func sessionWhere(filter *session.Filter) (string, []interface{}) {
+	var where []string
+	var args []interface{}
+	if filter.StartedFrom != nil {
+		where = append(where, `started >= ?`)
+		args = append(args, encodeTime(*filter.StartedFrom))
+	}
+	if filter.StartedTo != nil {
+		where = append(where, `started <= ?`)
+		args = append(args, encodeTime(*filter.StartedTo))
+	}
+	where, args = appendFieldsWhere(filter, where, args)
+	if len(where) == 0 {
+		return "", nil
+	}
+	return ` WHERE ` + strings.Join(where, ` AND `), args
+}
+
+// aggregateWhere matches daily aggregates of the days overlapping with the filtered period.
+func aggregateWhere(filter *session.Filter) (string, []interface{}) {
+	var where []string
+	var args []interface{}
+	if filter.StartedFrom != nil {
+		where = append(where, `day >= ?`)
+		args = append(args, encodeTime(filter.StartedFrom.Truncate(24*time.Hour)))
+	}
+	if filter.StartedTo != nil {
+		where = append(where, `day <= ?`)
+		args = append(args, encodeTime(*filter.StartedTo))
+	}
+	where, args = appendFieldsWhere(filter, where, args)
+	if len(where) == 0 {
+		return "", nil
+	}
+	return ` WHERE ` + strings.Join(where, ` AND `), args
+}
+
+func appendFieldsWhere(filter *session.Filter, where []string, args []interface{}) ([]string, []interface{}) {
+	if filter.Direction != nil {
+		where = append(where, `direction = ?`)
+		args = append(args, *filter.Direction)
+	}
+	if filter.ConsumerID != nil {
+		where = append(where, `consumer_id = ?`)
+		args = append(args, filter.ConsumerID.Address)
+	}
+	if filter.HermesID != nil {
+		where = append(where, `hermes_id = ?`)
+		args = append(args, *filter.HermesID)
+	}
+	if filter.ProviderID != nil {
+		where = append(where, `provider_id = ?`)
+		args = append(args, filter.ProviderID.Address)
+	}
+	if filter.ServiceType != nil {
+		where = append(where, `service_type = ?`)
+		args = append(args, *filter.ServiceType)
+	}
+	if filter.Status != nil {
+		where = append(where, `status = ?`)
+		args = append(args, *filter.Status)
+	}
+	return where, args
+}
+
+func sessionValues(history session.History) []interface{} {
+	return []interface{}{
+		string(history.SessionID),
+		history.Direction,
+		history.ConsumerID.Address,
+		history.HermesID,
+		history.ProviderID.Address,
+		history.ServiceType,
+		history.ConsumerCountry,
+		history.ProviderCountry,
+		history.EgressIP,
+		history.NodeType,
+		int64(history.DataSent),
+		int64(history.DataReceived),
+		encodeBigInt(history.Tokens),
+		history.Status,
+		encodeTime(history.Started),
+		encodeTime(history.Updated),
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSession(row scanner) (session.History, error) {
+	var history session.History
+	var id, consumerID, providerID string
+	var dataSent, dataReceived, started, updated int64
+	var tokens sql.NullString
+	err := row.Scan(
+		&id,
+		&history.Direction,
+		&consumerID,
+		&history.HermesID,
+		&providerID,
+		&history.ServiceType,
+		&history.ConsumerCountry,
+		&history.ProviderCountry,
+		&history.EgressIP,
+		&history.NodeType,
+		&dataSent,
+		&dataReceived,
+		&tokens,
+		&history.Status,
+		&started,
+		&updated,
+	)
+	if err != nil {
+		return history, err
+	}
+
+	history.SessionID = session_node.ID(id)
+	history.ConsumerID = identity.Identity{Address: consumerID}
+	history.ProviderID = identity.Identity{Address: providerID}
+	history.DataSent = uint64(dataSent)
+	history.DataReceived = uint64(dataReceived)
+	history.Started = decodeTime(started)
+	history.Updated = decodeTime(updated)
+	history.Tokens, err = decodeBigInt(tokens)
+	return history, err
+}
+
+func aggregateValues(aggregate session.Aggregate) []interface{} {
+	return []interface{}{
+		aggregate.ID,
+		encodeTime(aggregate.Day),
+		aggregate.Direction,
+		aggregate.ConsumerID.Address,
+		aggregate.HermesID,
+		aggregate.ProviderID.Address,
+		aggregate.ServiceType,
+		aggregate.Status,
+		aggregate.Count,
+		int64(aggregate.DataSent),
+		int64(aggregate.DataReceived),
+		int64(aggregate.Duration),
+		encodeBigInt(aggregate.Tokens),
+	}
+}
+
+func scanAggregate(row scanner) (session.Aggregate, error) {
+	var aggregate session.Aggregate
+	var consumerID, providerID string
+	var day, dataSent, dataReceived, duration int64
+	var tokens sql.NullString
+	err := row.Scan(
+		&aggregate.ID,
+		&day,
+		&aggregate.Direction,
+		&consumerID,
+		&aggregate.HermesID,
+		&providerID,
+		&aggregate.ServiceType,
+		&aggregate.Status,
+		&aggregate.Count,
+		&dataSent,
+		&dataReceived,
+		&duration,
+		&tokens,
+	)
+	if err != nil {
+		return aggregate, err
+	}
+
+	aggregate.Day = decodeTime(day)
+	aggregate.ConsumerID = identity.Identity{Address: consumerID}
+	aggregate.ProviderID = identity.Identity{Address: providerID}
+	aggregate.DataSent = uint64(dataSent)
+	aggregate.DataReceived = uint64(dataReceived)
+	aggregate.Duration = time.Duration(duration)
+	aggregate.Tokens, err = decodeBigInt(tokens)
+	return aggregate, err
+}
+
+// encodeTime stores time as unix nanoseconds, zero time is stored as 0.
+func encodeTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func decodeTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos).UTC()
+}
+
+func encodeBigInt(value *big.Int) interface{} {
+	if value == nil {
+		return nil
+	}
+	return value.String()
+}
+
+func decodeBigInt(value sql.NullString) (*big.Int, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	result, ok := new(big.Int).SetString(value.String, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", value.String)
+	}
+	return result, nil
+}
@@ -0,0 +1,123 @@
+// +build cgo
+
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/identity"
+	session_node "github.com/mysteriumnetwork/node/session"
+)
+
+var (
+	consumer1   = identity.FromAddress("0x0000000000000000000000000000000000000001")
+	consumer2   = identity.FromAddress("0x0000000000000000000000000000000000000002")
+	provider    = identity.FromAddress("0x00000000000000000000000000000000000000aa")
+	day1        = time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+	day2        = day1.AddDate(0, 0, 1)
+	testHistory = []session.History{
+		{SessionID: "s1", Direction: session.DirectionConsumed, ConsumerID: consumer1, ProviderID: provider, ServiceType: "wireguard", Status: session.StatusCompleted, DataSent: 10, DataReceived: 100, Tokens: big.NewInt(5), Started: day1, Updated: day1.Add(time.Minute)},
+		{SessionID: "s2", Direction: session.DirectionConsumed, ConsumerID: consumer2, ProviderID: provider, ServiceType: "wireguard", Status: session.StatusCompleted, DataSent: 20, DataReceived: 200, Tokens: big.NewInt(7), Started: day1.Add(time.Hour), Updated: day1.Add(2 * time.Hour)},
+		{SessionID: "s3", Direction: session.DirectionProvided, ConsumerID: consumer1, ProviderID: provider, ServiceType: "openvpn", Status: session.StatusCompleted, DataSent: 30, DataReceived: 300, Tokens: big.NewInt(11), Started: day2, Updated: day2.Add(time.Minute)},
+	}
+)
+
+func createSessionRepository(t *testing.T) (*SessionRepository, func()) {
+	s, cleanup := createStorage(t)
+	repository := NewSessionRepository(s)
+	for _, history := range testHistory {
+		require.NoError(t, repository.Store(history))
+	}
+	return repository, cleanup
+}
+
+func Test_SessionRepositoryList(t *testing.T) {
+	repository, cleanup := createSessionRepository(t)
+	defer cleanup()
+
+	result, err := repository.List(session.NewFilter())
+	assert.NoError(t, err)
+	assert.Equal(t, []session.History{testHistory[2], testHistory[1], testHistory[0]}, result)
+
+	result, err = repository.List(session.NewFilter().SetConsumerID(consumer1).SetStartedTo(day1.Add(time.Hour)))
+	assert.NoError(t, err)
+	assert.Equal(t, []session.History{testHistory[0]}, result)
+
+	updated := testHistory[0]
+	updated.Status = session.StatusNew
+	updated.Tokens = big.NewInt(50)
+	assert.NoError(t, repository.Update(updated))
+	result, err = repository.List(session.NewFilter().SetStatus(session.StatusNew))
+	assert.NoError(t, err)
+	assert.Equal(t, []session.History{updated}, result)
+
+	updated.SessionID = "unknown"
+	assert.Error(t, repository.Update(updated))
+}
+
+func Test_SessionRepositoryRollUpKeepsStats(t *testing.T) {
+	repository, cleanup := createSessionRepository(t)
+	defer cleanup()
+
+	filters := []*session.Filter{
+		session.NewFilter(),
+		session.NewFilter().SetConsumerID(consumer1),
+		session.NewFilter().SetDirection(session.DirectionConsumed),
+		session.NewFilter().SetStartedFrom(day1.Truncate(24 * time.Hour)).SetStartedTo(day2),
+	}
+	var before []session.Stats
+	var beforeByDay []map[time.Time]session.Stats
+	for _, filter := range filters {
+		stats, err := repository.Stats(filter)
+		require.NoError(t, err)
+		before = append(before, stats)
+		byDay, err := repository.StatsByDay(filter)
+		require.NoError(t, err)
+		beforeByDay = append(beforeByDay, byDay)
+	}
+	assert.Equal(t, 3, before[0].Count)
+	assert.Equal(t, big.NewInt(23), before[0].SumTokens)
+
+	rolled, err := repository.RollUp(day2, 0, 10, []session_node.ID{"s2"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rolled)
+	rolled, err = repository.RollUp(time.Time{}, 1, 10, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rolled)
+
+	result, err := repository.List(session.NewFilter())
+	assert.NoError(t, err)
+	assert.Equal(t, []session.History{testHistory[2]}, result)
+
+	for i, filter := range filters {
+		stats, err := repository.Stats(filter)
+		assert.NoError(t, err)
+		assert.Equal(t, before[i], stats)
+		byDay, err := repository.StatsByDay(filter)
+		assert.NoError(t, err)
+		assert.Equal(t, beforeByDay[i], byDay)
+	}
+}
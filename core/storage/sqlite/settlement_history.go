@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+const settlementColumns = `tx_hash, provider_id, hermes_id, time, entry`
+
+// SettlementHistoryRepository stores settlement history in a sqlite table, so it can be filtered by indexes.
+type SettlementHistoryRepository struct {
+	storage *Storage
+}
+
+// NewSettlementHistoryRepository creates settlement history repository stored in sqlite.
+func NewSettlementHistoryRepository(storage *Storage) *SettlementHistoryRepository {
+	return &SettlementHistoryRepository{storage: storage}
+}
+
+// Store inserts the entry.
+func (sr *SettlementHistoryRepository) Store(entry pingpong.SettlementHistoryEntry) error {
+	values, err := settlementValues(entry)
+	if err != nil {
+		return err
+	}
+	_, err = sr.storage.DB().Exec(`INSERT OR REPLACE INTO settlement_history (`+settlementColumns+`) VALUES (?, ?, ?, ?, ?)`, values...)
+	return err
+}
+
+// List returns entries matching the filter, most recent first.
+func (sr *SettlementHistoryRepository) List(filter pingpong.SettlementHistoryFilter) ([]pingpong.SettlementHistoryEntry, error) {
+	var where []string
+	var args []interface{}
+	if filter.TimeFrom != nil {
+		where = append(where, `time >= ?`)
+		args = append(args, encodeTime(*filter.TimeFrom))
+	}
+	if filter.TimeTo != nil {
+		where = append(where, `time <= ?`)
+		args = append(args, encodeTime(*filter.TimeTo))
+	}
+	if filter.ProviderID != nil {
+		where = append(where, `provider_id = ?`)
+		args = append(args, filter.ProviderID.Address)
+	}
+	if filter.HermesID != nil {
+		where = append(where, `hermes_id = ?`)
+		args = append(args, filter.HermesID.Hex())
+	}
+	query := `SELECT entry FROM settlement_history`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+
+	rows, err := sr.storage.DB().Query(query+` ORDER BY time DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []pingpong.SettlementHistoryEntry{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var entry pingpong.SettlementHistoryEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, err
+		}
+		result = append(result, entry)
+	}
+	return result, rows.Err()
+}
+
+// Prune removes entries settled before the given time and all but keep most recent entries in a single transaction.
+func (sr *SettlementHistoryRepository) Prune(before time.Time, keep int) (int, error) {
+	tx, err := sr.storage.DB().Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var removed int64
+	if !before.IsZero() {
+		res, err := tx.Exec(`DELETE FROM settlement_history WHERE time < ?`, encodeTime(before))
+		if err != nil {
+			return 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		removed += n
+	}
+	if keep > 0 {
+		res, err := tx.Exec(`DELETE FROM settlement_history WHERE tx_hash IN (
+			SELECT tx_hash FROM settlement_history ORDER BY time DESC LIMIT -1 OFFSET ?
+		)`, keep)
+		if err != nil {
+			return 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		removed += n
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(removed), nil
+}
+
+func settlementValues(entry pingpong.SettlementHistoryEntry) ([]interface{}, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{
+		entry.TxHash.Hex(),
+		entry.ProviderID.Address,
+		entry.HermesID.Hex(),
+		encodeTime(entry.Time),
+		data,
+	}, nil
+}
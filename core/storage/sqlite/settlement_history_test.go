@@ -0,0 +1,86 @@
+// +build cgo
+
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+var (
+	hermes1         = common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	hermes2         = common.HexToAddress("0x00000000000000000000000000000000000000b2")
+	testSettlements = []pingpong.SettlementHistoryEntry{
+		{TxHash: common.BigToHash(big.NewInt(1)), ProviderID: provider, HermesID: hermes1, Time: day1, Amount: big.NewInt(1), TotalSettled: big.NewInt(1)},
+		{TxHash: common.BigToHash(big.NewInt(2)), ProviderID: provider, HermesID: hermes2, Time: day1.Add(time.Hour), Amount: big.NewInt(2), TotalSettled: big.NewInt(3)},
+		{TxHash: common.BigToHash(big.NewInt(3)), ProviderID: consumer1, HermesID: hermes1, Time: day2, Amount: big.NewInt(3), TotalSettled: big.NewInt(6)},
+	}
+)
+
+func createSettlementRepository(t *testing.T) (*SettlementHistoryRepository, func()) {
+	s, cleanup := createStorage(t)
+	repository := NewSettlementHistoryRepository(s)
+	for _, entry := range testSettlements {
+		require.NoError(t, repository.Store(entry))
+	}
+	return repository, cleanup
+}
+
+func Test_SettlementHistoryRepositoryList(t *testing.T) {
+	repository, cleanup := createSettlementRepository(t)
+	defer cleanup()
+
+	result, err := repository.List(pingpong.SettlementHistoryFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, []pingpong.SettlementHistoryEntry{testSettlements[2], testSettlements[1], testSettlements[0]}, result)
+
+	from, to := day1.Add(time.Minute), day2
+	result, err = repository.List(pingpong.SettlementHistoryFilter{TimeFrom: &from, TimeTo: &to, ProviderID: &provider})
+	assert.NoError(t, err)
+	assert.Equal(t, []pingpong.SettlementHistoryEntry{testSettlements[1]}, result)
+
+	result, err = repository.List(pingpong.SettlementHistoryFilter{HermesID: &hermes1})
+	assert.NoError(t, err)
+	assert.Equal(t, []pingpong.SettlementHistoryEntry{testSettlements[2], testSettlements[0]}, result)
+}
+
+func Test_SettlementHistoryRepositoryPrune(t *testing.T) {
+	repository, cleanup := createSettlementRepository(t)
+	defer cleanup()
+
+	removed, err := repository.Prune(day1.Add(time.Minute), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	removed, err = repository.Prune(time.Time{}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	result, err := repository.List(pingpong.SettlementHistoryFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, testSettlements[2:], result)
+}
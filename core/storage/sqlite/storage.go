@@ -0,0 +1,404 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+)
+
+// DatabaseFile is the name of the sqlite database file in storage directory.
+const DatabaseFile = "myst.sqlite"
+
+// Storage is a sqlite backed storage.
+// Values and structs are stored as JSON in the same layout as boltdb storage, so data can be migrated as is.
+type Storage struct {
+	path string
+
+	mu sync.RWMutex
+	db *sql.DB
+}
+
+// NewStorage opens or creates sqlite storage in the given directory.
+func NewStorage(dir string) (*Storage, error) {
+	path := filepath.Join(dir, DatabaseFile)
+	db, err := open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite: %w", err)
+	}
+
+	return &Storage{path: path, db: db}, nil
+}
+
+func open(path string) (*sql.DB, error) {
+	if !Available {
+		return nil, errors.New("sqlite storage backend requires a node built with cgo, use boltdb instead")
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	// Single connection serializes writers, sqlite does not allow concurrent ones anyway.
+	db.SetMaxOpenConns(1)
+
+	if err := migrateSchema(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// DB returns raw sql DB.
+func (s *Storage) DB() *sql.DB {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.db
+}
+
+// GetValue gets the value stored by SetValue.
+func (s *Storage) GetValue(bucket string, key interface{}, to interface{}) error {
+	k, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+	return s.get(bucket, k, to)
+}
+
+// SetValue stores the value by key.
+func (s *Storage) SetValue(bucket string, key interface{}, to interface{}) error {
+	k, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+	return s.put(s.DB(), bucket, k, to)
+}
+
+// GetAllValues gets all values of the bucket stored by SetValue, to must be a pointer to slice.
+func (s *Storage) GetAllValues(bucket string, to interface{}) error {
+	return s.all(bucket, to)
+}
+
+// DeleteValues removes values of the bucket stored by SetValue for which remove returns true, in a single transaction.
+func (s *Storage) DeleteValues(bucket string, sample interface{}, remove func(value interface{}) bool) (int, error) {
+	ref := reflect.ValueOf(sample)
+	if ref.Kind() != reflect.Ptr {
+		return 0, errors.New("provided sample must be a pointer")
+	}
+
+	tx, err := s.DB().Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT key, value FROM entries WHERE bucket = ?`, bucket)
+	if err != nil {
+		return 0, err
+	}
+	var keys [][]byte
+	for rows.Next() {
+		var key, data []byte
+		if err := rows.Scan(&key, &data); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		value := reflect.New(ref.Elem().Type())
+		if err := json.Unmarshal(data, value.Interface()); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if remove(value.Interface()) {
+			keys = append(keys, key)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		if _, err := tx.Exec(`DELETE FROM entries WHERE bucket = ? AND key = ?`, bucket, key); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(keys), nil
+}
+
+// Store stores the struct, identified by its "id" field.
+func (s *Storage) Store(bucket string, data interface{}) error {
+	ref := reflect.ValueOf(data)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Struct {
+		return errStructPtrNeeded
+	}
+	id, err := idField(ref.Elem())
+	if err != nil {
+		return err
+	}
+	bucket = structBucket(bucket, ref.Elem().Type())
+
+	tx, err := s.DB().Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if id.value.IsZero() {
+		if !id.increment {
+			return storage.ErrZeroID
+		}
+		if err := nextSequence(tx, bucket, id.value); err != nil {
+			return err
+		}
+	}
+	key, err := encodeKey(id.value.Interface())
+	if err != nil {
+		return err
+	}
+	if err := s.put(tx, bucket, key, data); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAllFrom gets all structs of the bucket ordered by id, data must be a pointer to slice.
+func (s *Storage) GetAllFrom(bucket string, data interface{}) error {
+	ref := reflect.ValueOf(data)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Slice {
+		return errSlicePtrNeeded
+	}
+	elem := ref.Elem().Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return s.all(structBucket(bucket, elem), data)
+}
+
+// Delete removes the struct.
+func (s *Storage) Delete(bucket string, data interface{}) error {
+	bucket, key, err := structKey(bucket, data)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.DB().Exec(`DELETE FROM entries WHERE bucket = ? AND key = ?`, bucket, key)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// Update updates non zero fields of the stored struct.
+func (s *Storage) Update(bucket string, object interface{}) error {
+	bucket, key, err := structKey(bucket, object)
+	if err != nil {
+		return err
+	}
+
+	ref := reflect.ValueOf(object).Elem()
+	current := reflect.New(ref.Type())
+	if err := s.get(bucket, key, current.Interface()); err != nil {
+		return err
+	}
+	for i := 0; i < ref.NumField(); i++ {
+		field := ref.Field(i)
+		if ref.Type().Field(i).PkgPath != "" || field.IsZero() {
+			continue
+		}
+		current.Elem().Field(i).Set(field)
+	}
+	return s.put(s.DB(), bucket, key, current.Interface())
+}
+
+// GetOneByField gets the first struct which field equals to the key.
+func (s *Storage) GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error {
+	ref := reflect.ValueOf(to)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Struct {
+		return errStructPtrNeeded
+	}
+	typ := ref.Elem().Type()
+	if _, ok := typ.FieldByName(fieldName); !ok {
+		return fmt.Errorf("field %s not found", fieldName)
+	}
+	bucket = structBucket(bucket, typ)
+
+	if id, err := idField(ref.Elem()); err == nil && id.name == fieldName {
+		k, err := encodeKey(key)
+		if err != nil {
+			return err
+		}
+		return s.get(bucket, k, to)
+	}
+
+	all := reflect.New(reflect.SliceOf(typ))
+	if err := s.all(bucket, all.Interface()); err != nil {
+		return err
+	}
+	for i := 0; i < all.Elem().Len(); i++ {
+		item := all.Elem().Index(i)
+		if reflect.DeepEqual(item.FieldByName(fieldName).Interface(), key) {
+			ref.Elem().Set(item)
+			return nil
+		}
+	}
+	return storage.ErrNotFound
+}
+
+// Snapshot writes consistent copy of the whole database.
+func (s *Storage) Snapshot(w io.Writer) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), DatabaseFile+".snapshot")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	// VACUUM INTO requires the target file to not exist.
+	os.Remove(tmp.Name())
+	defer os.Remove(tmp.Name())
+
+	if _, err := s.DB().Exec(`VACUUM INTO ?`, tmp.Name()); err != nil {
+		return err
+	}
+	snapshot, err := os.Open(tmp.Name())
+	if err != nil {
+		return err
+	}
+	defer snapshot.Close()
+
+	_, err = io.Copy(w, snapshot)
+	return err
+}
+
+// Compact releases space left by removed data.
+func (s *Storage) Compact() (storage.Compaction, error) {
+	before, err := os.Stat(s.path)
+	if err != nil {
+		return storage.Compaction{}, err
+	}
+	if _, err := s.DB().Exec(`VACUUM`); err != nil {
+		return storage.Compaction{}, fmt.Errorf("could not compact database: %w", err)
+	}
+	after, err := os.Stat(s.path)
+	if err != nil {
+		return storage.Compaction{}, err
+	}
+	return storage.Compaction{SizeBefore: before.Size(), SizeAfter: after.Size()}, nil
+}
+
+// Close closes the storage.
+func (s *Storage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.db.Close()
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func (s *Storage) put(db execer, bucket string, key []byte, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT OR REPLACE INTO entries (bucket, key, value) VALUES (?, ?, ?)`, bucket, key, data)
+	return err
+}
+
+func (s *Storage) get(bucket string, key []byte, to interface{}) error {
+	var data []byte
+	err := s.DB().QueryRow(`SELECT value FROM entries WHERE bucket = ? AND key = ?`, bucket, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+
+func (s *Storage) all(bucket string, to interface{}) error {
+	ref := reflect.ValueOf(to)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Slice {
+		return errSlicePtrNeeded
+	}
+	slice := ref.Elem()
+
+	rows, err := s.DB().Query(`SELECT value FROM entries WHERE bucket = ? ORDER BY key`, bucket)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	result := reflect.MakeSlice(slice.Type(), 0, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		value := reflect.New(slice.Type().Elem())
+		if err := json.Unmarshal(data, value.Interface()); err != nil {
+			return err
+		}
+		result = reflect.Append(result, value.Elem())
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	slice.Set(result)
+	return nil
+}
+
+func nextSequence(tx *sql.Tx, bucket string, id reflect.Value) error {
+	var seq int64
+	err := tx.QueryRow(`SELECT value FROM sequences WHERE bucket = ?`, bucket).Scan(&seq)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	seq++
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO sequences (bucket, value) VALUES (?, ?)`, bucket, seq); err != nil {
+		return err
+	}
+
+	switch id.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		id.SetInt(seq)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		id.SetUint(uint64(seq))
+	default:
+		return errors.New("only integer ids can be incremented")
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// +build cgo
+
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+)
+
+type testEntry struct {
+	ID    int64 `storm:"id,increment"`
+	Name  string
+	Count int
+}
+
+const bucket = "test"
+
+func createStorage(t *testing.T) (*Storage, func()) {
+	dir := boltdbtest.CreateTempDir(t)
+	s, err := NewStorage(dir)
+	require.NoError(t, err)
+	return s, func() {
+		s.Close()
+		boltdbtest.RemoveTempDir(t, dir)
+	}
+}
+
+func Test_StorageValues(t *testing.T) {
+	s, cleanup := createStorage(t)
+	defer cleanup()
+
+	var value string
+	assert.Equal(t, storage.ErrNotFound, s.GetValue(bucket, "key", &value))
+
+	assert.NoError(t, s.SetValue(bucket, "b", "second"))
+	assert.NoError(t, s.SetValue(bucket, "a", "first"))
+	assert.NoError(t, s.SetValue(bucket, "a", "updated"))
+	assert.NoError(t, s.GetValue(bucket, "a", &value))
+	assert.Equal(t, "updated", value)
+
+	var values []string
+	assert.NoError(t, s.GetAllValues(bucket, &values))
+	assert.Equal(t, []string{"updated", "second"}, values)
+
+	deleted, err := s.DeleteValues(bucket, new(string), func(value interface{}) bool {
+		return *value.(*string) == "second"
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.Equal(t, storage.ErrNotFound, s.GetValue(bucket, "b", &value))
+	assert.NoError(t, s.GetValue(bucket, "a", &value))
+}
+
+func Test_StorageStructs(t *testing.T) {
+	s, cleanup := createStorage(t)
+	defer cleanup()
+
+	for _, name := range []string{"first", "second", "third"} {
+		assert.NoError(t, s.Store(bucket, &testEntry{Name: name, Count: 1}))
+	}
+
+	var entries []testEntry
+	assert.NoError(t, s.GetAllFrom(bucket, &entries))
+	assert.Equal(t, []testEntry{
+		{ID: 1, Name: "first", Count: 1},
+		{ID: 2, Name: "second", Count: 1},
+		{ID: 3, Name: "third", Count: 1},
+	}, entries)
+
+	assert.NoError(t, s.Update(bucket, &testEntry{ID: 2, Count: 5}))
+	var entry testEntry
+	assert.NoError(t, s.GetOneByField(bucket, "ID", int64(2), &entry))
+	assert.Equal(t, testEntry{ID: 2, Name: "second", Count: 5}, entry)
+	assert.NoError(t, s.GetOneByField(bucket, "Name", "third", &entry))
+	assert.Equal(t, int64(3), entry.ID)
+	assert.Equal(t, storage.ErrNotFound, s.GetOneByField(bucket, "Name", "missing", &entry))
+
+	assert.NoError(t, s.Delete(bucket, &testEntry{ID: 1}))
+	assert.Equal(t, storage.ErrNotFound, s.Delete(bucket, &testEntry{ID: 1}))
+	assert.Equal(t, storage.ErrNotFound, s.Update(bucket, &testEntry{ID: 1, Count: 1}))
+	assert.NoError(t, s.GetAllFrom(bucket, &entries))
+	assert.Len(t, entries, 2)
+}
+
+func Test_StorageSnapshotAndCompact(t *testing.T) {
+	s, cleanup := createStorage(t)
+	defer cleanup()
+
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, s.Store(bucket, &testEntry{Name: string(make([]byte, 1024))}))
+	}
+	var snapshot bytes.Buffer
+	assert.NoError(t, s.Snapshot(&snapshot))
+
+	var entries []testEntry
+	assert.NoError(t, s.GetAllFrom(bucket, &entries))
+	for i := range entries {
+		assert.NoError(t, s.Delete(bucket, &entries[i]))
+	}
+	compaction, err := s.Compact()
+	assert.NoError(t, err)
+	assert.Less(t, compaction.SizeAfter, compaction.SizeBefore)
+
+	dir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, DatabaseFile), snapshot.Bytes(), 0600))
+	restored, err := NewStorage(dir)
+	require.NoError(t, err)
+	defer restored.Close()
+	assert.NoError(t, restored.GetAllFrom(bucket, &entries))
+	assert.Len(t, entries, 100)
+}
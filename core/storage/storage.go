@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package storage
+
+import "io"
+
+// Storage persists node state, it is implemented by boltdb and sqlite backends.
+type Storage interface {
+	// GetValue gets the value stored by SetValue.
+	GetValue(bucket string, key interface{}, to interface{}) error
+	// SetValue stores the value by key.
+	SetValue(bucket string, key interface{}, to interface{}) error
+	// GetAllValues gets all values of the bucket stored by SetValue, to must be a pointer to slice.
+	GetAllValues(bucket string, to interface{}) error
+	// DeleteValues removes values of the bucket stored by SetValue for which remove returns true, in a single transaction.
+	// Every value is decoded into a new instance of the type sample points to before it is passed to remove.
+	DeleteValues(bucket string, sample interface{}, remove func(value interface{}) bool) (int, error)
+	// Store stores the struct, identified by its "id" field.
+	Store(bucket string, data interface{}) error
+	// GetAllFrom gets all structs of the bucket ordered by id, data must be a pointer to slice.
+	GetAllFrom(bucket string, data interface{}) error
+	// Delete removes the struct.
+	Delete(bucket string, data interface{}) error
+	// Update updates non zero fields of the stored struct.
+	Update(bucket string, object interface{}) error
+	// GetOneByField gets the first struct which field equals to the key.
+	GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error
+	// Snapshot writes consistent copy of the whole database.
+	Snapshot(w io.Writer) error
+	// Compact releases space left by removed data.
+	Compact() (Compaction, error)
+	// Close closes the storage.
+	Close() error
+}
+
+// Compaction describes the result of database compaction.
+type Compaction struct {
+	SizeBefore int64
+	SizeAfter  int64
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// QueryRecorder records DNS queries of consumers for traffic accounting.
+type QueryRecorder interface {
+	RecordDNSQuery(source net.IP, host string)
+}
+
+// AccountQueries creates a DNS handler that records queries before resolving them.
+func AccountQueries(resolver dns.Handler, recorder QueryRecorder) dns.Handler {
+	return &accountingHandler{
+		resolver: resolver,
+		recorder: recorder,
+	}
+}
+
+type accountingHandler struct {
+	resolver dns.Handler
+	recorder QueryRecorder
+}
+
+func (ah *accountingHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	var source net.IP
+	switch addr := writer.RemoteAddr().(type) {
+	case *net.UDPAddr:
+		source = addr.IP
+	case *net.TCPAddr:
+		source = addr.IP
+	}
+
+	if source != nil {
+		for _, question := range req.Question {
+			ah.recorder.RecordDNSQuery(source, strings.TrimRight(question.Name, "."))
+		}
+	}
+
+	ah.resolver.ServeDNS(writer, req)
+}
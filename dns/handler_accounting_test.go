@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AccountQueries(t *testing.T) {
+	recorder := &queryRecorderMock{}
+	resolved := false
+	handler := AccountQueries(
+		dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+			resolved = true
+		}),
+		recorder,
+	)
+
+	req := &dns.Msg{}
+	req.SetQuestion("single.com.", dns.TypeA)
+	handler.ServeDNS(&remoteAddrWriter{addr: &net.UDPAddr{IP: net.ParseIP("10.182.0.2"), Port: 5353}}, req)
+
+	assert.True(t, resolved)
+	assert.Equal(t, []string{"10.182.0.2 single.com"}, recorder.queries)
+}
+
+type queryRecorderMock struct {
+	queries []string
+}
+
+func (qrm *queryRecorderMock) RecordDNSQuery(source net.IP, host string) {
+	qrm.queries = append(qrm.queries, source.String()+" "+host)
+}
+
+type remoteAddrWriter struct {
+	recordingWriter
+	addr net.Addr
+}
+
+func (raw *remoteAddrWriter) RemoteAddr() net.Addr {
+	return raw.addr
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog/log"
+)
+
+// HandlerFactory creates DNS handler used by the service DNS proxy.
+type HandlerFactory func() (dns.Handler, error)
+
+// DialContext connects to the address on the named network.
+type DialContext func(ctx context.Context, network, address string) (net.Conn, error)
+
+// ResolveVia creates DNS handler proxying queries over TCP to the given servers,
+// connections are made with the given dialer, e.g. through an upstream tunnel.
+func ResolveVia(dial DialContext, servers []string) dns.Handler {
+	return &dialerHandler{dial: dial, servers: servers}
+}
+
+type dialerHandler struct {
+	dial    DialContext
+	servers []string
+}
+
+func (h *dialerHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	for _, server := range h.servers {
+		resp, err := h.exchange(req, net.JoinHostPort(server, "53"))
+		if err != nil {
+			log.Error().Err(err).Msg("Error proxying DNS query to " + server)
+			continue
+		}
+
+		writer.WriteMsg(resp)
+		return
+	}
+
+	resp := &dns.Msg{}
+	resp.SetRcode(req, dns.RcodeServerFailure)
+	writer.WriteMsg(resp)
+}
+
+func (h *dialerHandler) exchange(req *dns.Msg, address string) (*dns.Msg, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+
+	conn, err := h.dial(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(dnsTimeout))
+
+	co := &dns.Conn{Conn: conn}
+	if err := co.WriteMsg(req); err != nil {
+		return nil, err
+	}
+	return co.ReadMsg()
+}
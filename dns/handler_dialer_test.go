@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ResolveVia(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &dns.Server{
+		Listener: listener,
+		Handler: dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+			resp := &dns.Msg{}
+			resp.SetReply(req)
+			resp.Answer = append(resp.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   net.ParseIP("93.184.216.34"),
+			})
+			writer.WriteMsg(resp)
+		}),
+	}
+	go server.ActivateAndServe()
+	defer server.Shutdown()
+
+	var dialed []string
+	dial := func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialed = append(dialed, address)
+		if address == "1.1.1.1:53" {
+			return nil, assert.AnError
+		}
+		return (&net.Dialer{}).DialContext(ctx, network, listener.Addr().String())
+	}
+
+	req := &dns.Msg{}
+	req.SetQuestion("example.com.", dns.TypeA)
+	writer := &recordingWriter{}
+	ResolveVia(dial, []string{"1.1.1.1", "8.8.8.8"}).ServeDNS(writer, req)
+
+	assert.Equal(t, []string{"1.1.1.1:53", "8.8.8.8:53"}, dialed)
+	require.NotNil(t, writer.responseMsg)
+	require.Len(t, writer.responseMsg.Answer, 1)
+	assert.Equal(t, "93.184.216.34", writer.responseMsg.Answer[0].(*dns.A).A.String())
+}
@@ -18,6 +18,7 @@
 package dns
 
 import (
+	"net"
 	"strings"
 
 	"github.com/miekg/dns"
@@ -66,7 +67,11 @@ func (wh *whitelistHandler) whitelistByAnswer(response *dns.Msg) error {
 	for _, record := range response.Answer {
 		switch recordValue := record.(type) {
 		case *dns.A:
-			if err := wh.whitelistByARecord(recordValue); err != nil {
+			if err := wh.whitelistByRecord(recordValue.Hdr, recordValue.A); err != nil {
+				return err
+			}
+		case *dns.AAAA:
+			if err := wh.whitelistByRecord(recordValue.Hdr, recordValue.AAAA); err != nil {
 				return err
 			}
 		}
@@ -74,9 +79,8 @@ func (wh *whitelistHandler) whitelistByAnswer(response *dns.Msg) error {
 	return nil
 }
 
-func (wh *whitelistHandler) whitelistByARecord(record *dns.A) error {
-	host := strings.TrimRight(record.Hdr.Name, ".")
-	ip := record.A
+func (wh *whitelistHandler) whitelistByRecord(header dns.RR_Header, ip net.IP) error {
+	host := strings.TrimRight(header.Name, ".")
 
 	if wh.policies.IsHostAllowed(host) {
 		_, err := wh.trafficBlocker.AllowIPAccess(ip)
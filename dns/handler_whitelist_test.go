@@ -78,6 +78,20 @@ func Test_WhitelistAnswers(t *testing.T) {
 				"0.0.0.4": 1,
 			},
 		},
+		{
+			"should allow whitelisted hostname IPv6 addresses",
+			&dns.Msg{
+				Answer: []dns.RR{
+					&dns.AAAA{
+						Hdr:  dns.RR_Header{Name: "single.com.", Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: 0},
+						AAAA: net.ParseIP("2001:db8::3"),
+					},
+				},
+			},
+			map[string]int{
+				"2001:db8::3": 1,
+			},
+		},
 		{
 			"should not allow zone of whitelisted hostname",
 			&dns.Msg{
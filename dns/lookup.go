@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/miekg/dns"
+)
+
+// Lookup resolves IPv4 and IPv6 addresses of the host using given DNS handler.
+func Lookup(handler dns.Handler, host string) ([]net.IP, error) {
+	var ips []net.IP
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		req := &dns.Msg{}
+		req.SetQuestion(dns.Fqdn(host), qtype)
+
+		writer := &recordingWriter{}
+		handler.ServeDNS(writer, req)
+		if writer.responseMsg == nil || writer.responseMsg.Rcode != dns.RcodeSuccess {
+			continue
+		}
+
+		for _, record := range writer.responseMsg.Answer {
+			switch recordValue := record.(type) {
+			case *dns.A:
+				ips = append(ips, recordValue.A)
+			case *dns.AAAA:
+				ips = append(ips, recordValue.AAAA)
+			}
+		}
+	}
+
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+	return ips, nil
+}
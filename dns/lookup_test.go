@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Lookup(t *testing.T) {
+	handler := dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+		resp := &dns.Msg{}
+		resp.SetReply(req)
+		hdr := dns.RR_Header{Name: req.Question[0].Name, Rrtype: req.Question[0].Qtype, Class: dns.ClassINET}
+		if req.Question[0].Qtype == dns.TypeA {
+			resp.Answer = append(resp.Answer, &dns.A{Hdr: hdr, A: net.ParseIP("1.2.3.4")})
+		}
+		writer.WriteMsg(resp)
+	})
+
+	ips, err := Lookup(handler, "example.com")
+	assert.NoError(t, err)
+	assert.Len(t, ips, 1)
+	assert.Equal(t, "1.2.3.4", ips[0].String())
+
+	failing := dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+		resp := &dns.Msg{}
+		resp.SetRcode(req, dns.RcodeNameError)
+		writer.WriteMsg(resp)
+	})
+	_, err = Lookup(failing, "example.com")
+	assert.Error(t, err)
+}
@@ -18,7 +18,13 @@
 package eventbus
 
 import (
-	asaskevichEventBus "github.com/asaskevich/EventBus"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -29,32 +35,112 @@ type EventBus interface {
 	Subscriber
 }
 
-// Publisher publishes events
+// Publisher publishes events through typed wrappers of the packages owning the topics.
 type Publisher interface {
-	Publish(topic string, data interface{})
+	publish(topic string, data interface{})
 }
 
-// Subscriber subscribes to events
+// Subscriber subscribes to events through typed wrappers of the packages owning the topics.
 type Subscriber interface {
-	Subscribe(topic string, fn interface{}) error
-	SubscribeAsync(topic string, fn interface{}) error
-	Unsubscribe(topic string, fn interface{}) error
+	subscribe(topic string, fn interface{}, delivery Delivery, queueSize int, overflow OverflowPolicy) error
+	unsubscribe(topic string, fn interface{}) error
+}
+
+// ErrUndefinedTopic is returned when subscribing to a topic missing from bus definitions.
+var ErrUndefinedTopic = errors.New("undefined topic")
+
+// Bus delivers published events to topic subscribers.
+// Synchronous subscribers are called by the publisher, asynchronous ones
+// get a bounded queue drained by a dedicated goroutine and concurrent ones
+// are called from a new goroutine for every event.
+type Bus struct {
+	mu          sync.RWMutex
+	definitions map[string]Topic
+	topics      map[string]*topic
+	history     *History
 }
 
-type simplifiedEventBus struct {
-	bus asaskevichEventBus.Bus
+// New returns implementation of EventBus
+func New() *Bus {
+	return &Bus{
+		definitions: make(map[string]Topic),
+		topics:      make(map[string]*topic),
+	}
 }
 
-func (simplifiedBus simplifiedEventBus) Unsubscribe(topic string, fn interface{}) error {
-	return simplifiedBus.bus.Unsubscribe(topic, fn)
+// Define declares topics, subscribers and events of defined topics are checked against the topic event type.
+func (b *Bus) Define(topics ...Topic) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, t := range topics {
+		if err := t.validate(); err != nil {
+			return err
+		}
+		if _, exists := b.definitions[t.Name]; exists {
+			return fmt.Errorf("topic %q is already defined", t.Name)
+		}
+		b.definitions[t.Name] = t
+	}
+	return nil
+}
+
+// RecordHistory makes the bus record published events, except those of topics skipping history.
+func (b *Bus) RecordHistory(history *History) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.history = history
 }
 
-func (simplifiedBus simplifiedEventBus) Subscribe(topic string, fn interface{}) error {
-	return simplifiedBus.bus.Subscribe(topic, fn)
+func (b *Bus) subscribe(name string, fn interface{}, delivery Delivery, queueSize int, overflow OverflowPolicy) error {
+	handler := reflect.ValueOf(fn)
+	if handler.Kind() != reflect.Func {
+		return fmt.Errorf("topic %q: handler must be a function, got %T", name, fn)
+	}
+	if handler.Type().NumIn() > 1 {
+		return fmt.Errorf("topic %q: handler %s must take at most one argument", name, funcName(handler))
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.topicLocked(name)
+	if !t.defined && len(b.definitions) > 0 {
+		return fmt.Errorf("topic %q: handler %s: %w", name, funcName(handler), ErrUndefinedTopic)
+	}
+	if err := t.accepts(handler.Type()); err != nil {
+		return fmt.Errorf("topic %q: handler %s: %w", name, funcName(handler), err)
+	}
+
+	if queueSize == 0 {
+		queueSize = t.queueSize
+	}
+	if overflow == "" {
+		overflow = t.overflow
+	}
+	t.subscribers = append(t.subscribers, newSubscriber(handler, delivery, queueSize, overflow))
+	return nil
 }
 
-func (simplifiedBus simplifiedEventBus) SubscribeAsync(topic string, fn interface{}) error {
-	return simplifiedBus.bus.SubscribeAsync(topic, fn, false)
+func (b *Bus) unsubscribe(topic string, fn interface{}) error {
+	handler := reflect.ValueOf(fn)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t, ok := b.topics[topic]
+	if !ok {
+		return fmt.Errorf("topic %s doesn't exist", topic)
+	}
+	for i, s := range t.subscribers {
+		if s.handler.Type() == handler.Type() && s.handler.Pointer() == handler.Pointer() {
+			t.subscribers = append(t.subscribers[:i:i], t.subscribers[i+1:]...)
+			s.stop()
+			return nil
+		}
+	}
+	return fmt.Errorf("topic %s has no such handler", topic)
 }
 
 var logLevelsByTopic = map[string]zerolog.Level{
@@ -77,13 +163,81 @@ func levelFor(topic string) zerolog.Level {
 	return zerolog.DebugLevel
 }
 
-func (simplifiedBus simplifiedEventBus) Publish(topic string, data interface{}) {
+// publish delivers data to topic subscribers. Events not matching the topic definition are dropped.
+func (b *Bus) publish(topic string, data interface{}) {
 	log.WithLevel(levelFor(topic)).Msgf("Published topic=%q event=%+v", topic, data)
-	simplifiedBus.bus.Publish(topic, data)
+
+	t, subscribers, history := b.subscribers(topic)
+	if err := t.check(data); err != nil {
+		log.Error().Err(err).Msgf("Dropping event published to topic %q", topic)
+		return
+	}
+	atomic.AddUint64(&t.published, 1)
+	if history != nil && !t.skipHistory {
+		history.record(topic, data, t.redactHistory)
+	}
+
+	for _, s := range subscribers {
+		s.deliver(data)
+	}
 }
 
-// New returns implementation of EventBus
-func New() EventBus {
-	bus := asaskevichEventBus.New()
-	return simplifiedEventBus{bus: bus}
+func (b *Bus) subscribers(name string) (*topic, []*subscriber, *History) {
+	b.mu.RLock()
+	t, ok := b.topics[name]
+	if ok {
+		subscribers := append([]*subscriber(nil), t.subscribers...)
+		history := b.history
+		b.mu.RUnlock()
+		return t, subscribers, history
+	}
+	b.mu.RUnlock()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t = b.topicLocked(name)
+	return t, append([]*subscriber(nil), t.subscribers...), b.history
+}
+
+func (b *Bus) topicLocked(name string) *topic {
+	if t, ok := b.topics[name]; ok {
+		return t
+	}
+	t := newTopic(name, b.definitionLocked(name))
+	b.topics[name] = t
+	return t
+}
+
+func (b *Bus) definitionLocked(name string) *Topic {
+	if def, ok := b.definitions[name]; ok {
+		return &def
+	}
+	var match *Topic
+	for _, def := range b.definitions {
+		def := def
+		if def.matches(name) && (match == nil || len(def.Name) > len(match.Name)) {
+			match = &def
+		}
+	}
+	return match
+}
+
+// Stats returns topics with their subscribers and queue depths, sorted by topic name.
+func (b *Bus) Stats() []TopicStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	stats := make([]TopicStats, 0, len(b.topics))
+	for _, t := range b.topics {
+		stats = append(stats, t.stats())
+	}
+	for name, def := range b.definitions {
+		if _, seen := b.topics[name]; !seen && !def.isPrefix() {
+			stats = append(stats, newTopic(name, &def).stats())
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
 }
@@ -18,19 +18,223 @@
 package eventbus
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func Test_simplifiedEventBus_Publish_InvokesSubscribers(t *testing.T) {
+func Test_Bus_Publish_InvokesSubscribers(t *testing.T) {
 	eventBus := New()
 	var received string
-	eventBus.Subscribe("test topic", func(data string) {
+	Handle("test topic").Subscribe(eventBus, DeliverSync, func(data string) {
 		received = data
 	})
 
-	eventBus.Publish("test topic", "test data")
+	Handle("test topic").Publish(eventBus, "test data")
 
 	assert.Equal(t, "test data", received)
 }
+
+func Test_Bus_DefinedTopicRejectsMismatchingHandler(t *testing.T) {
+	bus := New()
+	assert.NoError(t, bus.Define(Topic{Name: "numbers", Event: 0}))
+
+	assert.Error(t, Handle("numbers").Subscribe(bus, DeliverSync, func(data string) {}))
+	assert.Error(t, Handle("numbers").Subscribe(bus, DeliverSync, "not a function"))
+	assert.Error(t, bus.Define(Topic{Name: "numbers"}))
+
+	var received []int
+	assert.NoError(t, Handle("numbers").Subscribe(bus, DeliverSync, func(data int) {
+		received = append(received, data)
+	}))
+	assert.NoError(t, Handle("numbers").Subscribe(bus, DeliverSync, func() {}))
+
+	Handle("numbers").Publish(bus, 1)
+	Handle("numbers").Publish(bus, "two")
+	assert.Equal(t, []int{1}, received)
+}
+
+func Test_Bus_PrefixDefinition(t *testing.T) {
+	bus := New()
+	assert.NoError(t, bus.Define(Topic{Name: "config:*", Event: ""}))
+
+	assert.Error(t, Handle("config:openvpn.port").Subscribe(bus, DeliverSync, func(data int) {}))
+	assert.NoError(t, Handle("config:openvpn.port").Subscribe(bus, DeliverSync, func(data string) {}))
+}
+
+func Test_Bus_AsyncDeliveryIsOrdered(t *testing.T) {
+	bus := New()
+	received := make(chan int, 10)
+	assert.NoError(t, Handle("numbers").Subscribe(bus, DeliverAsync, func(data int) {
+		received <- data
+	}))
+
+	for i := 0; i < 10; i++ {
+		Handle("numbers").Publish(bus, i)
+	}
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, i, <-received)
+	}
+}
+
+func Test_Bus_OverflowPolicies(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	received := make(chan int, 10)
+	handler := func(data int) {
+		<-release
+		received <- data
+	}
+	assert.NoError(t, Handle("newest").SubscribeQueued(bus, 1, OverflowDropNewest, handler))
+
+	// first event is taken by the handler, second fills the queue
+	Handle("newest").Publish(bus, 1)
+	assert.Eventually(t, func() bool { return bus.Stats()[0].Subscribers[0].QueueDepth == 0 }, time.Second, time.Millisecond)
+	Handle("newest").Publish(bus, 2)
+	Handle("newest").Publish(bus, 3)
+	close(release)
+	assert.Equal(t, 1, <-received)
+	assert.Equal(t, 2, <-received)
+
+	stats := bus.Stats()[0].Subscribers[0]
+	assert.Equal(t, uint64(1), stats.Dropped)
+	assert.Equal(t, 1, stats.QueueSize)
+	assert.Equal(t, OverflowDropNewest, stats.Overflow)
+}
+
+func Test_Bus_DropOldestKeepsLatest(t *testing.T) {
+	bus := New()
+	assert.NoError(t, bus.Define(Topic{Name: "stats", Event: 0, QueueSize: 2, Overflow: OverflowDropOldest}))
+	release := make(chan struct{})
+	received := make(chan int, 10)
+	assert.NoError(t, Handle("stats").Subscribe(bus, DeliverAsync, func(data int) {
+		<-release
+		received <- data
+	}))
+
+	Handle("stats").Publish(bus, 1)
+	assert.Eventually(t, func() bool { return bus.Stats()[0].Subscribers[0].QueueDepth == 0 }, time.Second, time.Millisecond)
+	for i := 2; i <= 5; i++ {
+		Handle("stats").Publish(bus, i)
+	}
+	close(release)
+	assert.Equal(t, 1, <-received)
+	assert.Equal(t, 4, <-received)
+	assert.Equal(t, 5, <-received)
+	assert.Equal(t, uint64(2), bus.Stats()[0].Subscribers[0].Dropped)
+}
+
+func Test_Bus_BlockAppliesBackPressure(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	assert.NoError(t, Handle("numbers").SubscribeQueued(bus, 1, OverflowBlock, func(data int) { <-release }))
+
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			Handle("numbers").Publish(bus, i)
+		}
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		t.Fatal("publisher should wait for a slow subscriber")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-published
+}
+
+func Test_Bus_ConcurrentDeliveryDoesNotBlock(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	received := make(chan int, 3)
+	assert.NoError(t, Handle("numbers").Subscribe(bus, DeliverConcurrent, func(data int) {
+		<-release
+		received <- data
+	}))
+
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			Handle("numbers").Publish(bus, i)
+		}
+		close(published)
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("publisher should not wait for a blocked concurrent subscriber")
+	}
+	assert.Eventually(t, func() bool {
+		return bus.Stats()[0].Subscribers[0].InFlight == 3
+	}, time.Second, 5*time.Millisecond)
+
+	close(release)
+	var sum int
+	for i := 0; i < 3; i++ {
+		sum += <-received
+	}
+	assert.Equal(t, 3, sum)
+}
+
+func Test_Bus_UndefinedTopicIsRejected(t *testing.T) {
+	bus := New()
+	assert.NoError(t, Handle("anything").Subscribe(bus, DeliverSync, func() {}))
+
+	assert.NoError(t, bus.Define(Topic{Name: "numbers", Event: 0}))
+	err := Handle("letters").Subscribe(bus, DeliverAsync, func(string) {})
+	assert.True(t, errors.Is(err, ErrUndefinedTopic))
+}
+
+func Test_Bus_Unsubscribe(t *testing.T) {
+	bus := New()
+	calls := 0
+	handler := func(data string) { calls++ }
+	assert.NoError(t, Handle("test topic").Subscribe(bus, DeliverSync, handler))
+	Handle("test topic").Publish(bus, "a")
+
+	assert.NoError(t, Handle("test topic").Unsubscribe(bus, handler))
+	Handle("test topic").Publish(bus, "b")
+	assert.Equal(t, 1, calls)
+	assert.Error(t, Handle("test topic").Unsubscribe(bus, handler))
+	assert.Error(t, Handle("other topic").Unsubscribe(bus, handler))
+}
+
+func Test_Bus_StatsListDefinedTopics(t *testing.T) {
+	bus := New()
+	assert.NoError(t, bus.Define(Topic{Name: "b", Event: ""}, Topic{Name: "a:*"}))
+	assert.NoError(t, Handle("b").Subscribe(bus, DeliverAsync, func(string) {}))
+	Handle("b").Publish(bus, "x")
+	Handle("c").Publish(bus, "x")
+
+	stats := bus.Stats()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "b", stats[0].Name)
+	assert.True(t, stats[0].Defined)
+	assert.Equal(t, "string", stats[0].Event)
+	assert.Equal(t, uint64(1), stats[0].Published)
+	assert.Len(t, stats[0].Subscribers, 1)
+	assert.True(t, stats[0].Subscribers[0].Async)
+	assert.Equal(t, DefaultQueueSize, stats[0].Subscribers[0].QueueSize)
+	assert.Equal(t, "c", stats[1].Name)
+	assert.False(t, stats[1].Defined)
+	assert.Equal(t, uint64(1), stats[1].Published)
+}
+
+func Test_Tap_PassesPublishedEvents(t *testing.T) {
+	var topics []string
+	bus := Tap(func(topic string, data interface{}) {
+		topics = append(topics, topic)
+	})
+
+	assert.NoError(t, Handle("numbers").Subscribe(bus, DeliverSync, func(data int) {
+		t.Fatal("tap should not deliver events to subscribers")
+	}))
+	Handle("numbers").Publish(bus, 1)
+	assert.Equal(t, []string{"numbers"}, topics)
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import "fmt"
+
+// Handle publishes and subscribes to a single topic.
+// Packages owning a topic keep its handle unexported and expose typed wrappers for it,
+// so events and handlers of a wrong type fail to compile instead of being dropped.
+type Handle string
+
+// Publish delivers data to the topic subscribers.
+func (h Handle) Publish(publisher Publisher, data interface{}) {
+	publisher.publish(string(h), data)
+}
+
+// Subscribe subscribes fn to the topic, async subscribers use the topic queue settings.
+func (h Handle) Subscribe(subscriber Subscriber, delivery Delivery, fn interface{}) error {
+	return subscriber.subscribe(string(h), fn, delivery, 0, "")
+}
+
+// SubscribeQueued subscribes fn to be called from its own queue of the given size and overflow policy.
+func (h Handle) SubscribeQueued(subscriber Subscriber, queueSize int, overflow OverflowPolicy, fn interface{}) error {
+	if queueSize < 0 {
+		return fmt.Errorf("invalid queue size %d", queueSize)
+	}
+	if err := overflow.validate(); err != nil {
+		return err
+	}
+	return subscriber.subscribe(string(h), fn, DeliverAsync, queueSize, overflow)
+}
+
+// Unsubscribe removes fn from the topic subscribers.
+func (h Handle) Unsubscribe(subscriber Subscriber, fn interface{}) error {
+	return subscriber.unsubscribe(string(h), fn)
+}
+
+// Tap returns an event bus passing every published event to fn and ignoring subscriptions.
+// Tests use it to check events published by the code under test.
+func Tap(fn func(topic string, data interface{})) EventBus {
+	return tap(fn)
+}
+
+type tap func(topic string, data interface{})
+
+func (t tap) publish(topic string, data interface{}) {
+	t(topic, data)
+}
+
+func (t tap) subscribe(string, interface{}, Delivery, int, OverflowPolicy) error {
+	return nil
+}
+
+func (t tap) unsubscribe(string, interface{}) error {
+	return nil
+}
@@ -0,0 +1,338 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HistoryFileName is the name of the event history file in the node's storage directory.
+const HistoryFileName = "events.log"
+
+// HistoryRedactedFileName is the name of the redacted event history copy added to feedback archives.
+const HistoryRedactedFileName = "events-redacted.log"
+
+// historyQueueSize is the number of events waiting to be recorded, newer events are dropped when it is full.
+const historyQueueSize = 1024
+
+// redactedEvent replaces payloads of sensitive events in the redacted history copy.
+var redactedEvent = json.RawMessage(`"[redacted]"`)
+
+// RecordedEvent is an event published on the bus.
+type RecordedEvent struct {
+	Time  time.Time       `json:"time"`
+	Topic string          `json:"topic"`
+	Event json.RawMessage `json:"event"`
+	// Sensitive events may hold personal data and are masked in the redacted copy.
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+type historyRequest struct {
+	time      time.Time
+	topic     string
+	data      interface{}
+	sensitive bool
+	flushed   chan struct{}
+}
+
+// HistoryFilter narrows down recorded events.
+type HistoryFilter struct {
+	// Topics to include, all topics if empty.
+	Topics []string
+	// Since excludes events published before it, if set.
+	Since time.Time
+	// Limit keeps only the newest events, 0 means no limit.
+	Limit int
+}
+
+func (f HistoryFilter) matches(e RecordedEvent) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if len(f.Topics) == 0 {
+		return true
+	}
+	for _, topic := range f.Topics {
+		if topic == e.Topic {
+			return true
+		}
+	}
+	return false
+}
+
+// History keeps a ring buffer of recently published events.
+// Events are encoded and persisted in the background so that publishers are not slowed down.
+// Persisted history is appended to a file which is loaded back when the node starts.
+type History struct {
+	path    string
+	persist bool
+	now     func() time.Time
+
+	queue    chan historyRequest
+	stop     chan struct{}
+	stopOnce sync.Once
+	dropped  uint64
+
+	mu      sync.Mutex
+	events  []RecordedEvent
+	next    int
+	written int
+}
+
+// NewHistory creates event history keeping the given number of events, dumped or persisted to the file at path.
+func NewHistory(size int, path string, persist bool) (*History, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid event history size %d", size)
+	}
+
+	h := &History{
+		path:    path,
+		persist: persist,
+		now:     time.Now,
+		events:  make([]RecordedEvent, 0, size),
+		queue:   make(chan historyRequest, historyQueueSize),
+		stop:    make(chan struct{}),
+	}
+	if persist {
+		if err := h.load(); err != nil {
+			return nil, err
+		}
+	}
+	go h.run()
+	return h, nil
+}
+
+// Close stops recording events.
+func (h *History) Close() {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+	})
+}
+
+// Path returns the file event history is dumped to.
+func (h *History) Path() string {
+	return h.path
+}
+
+// Record queues the event to be added to history.
+func (h *History) Record(topic string, data interface{}) {
+	h.record(topic, data, false)
+}
+
+func (h *History) record(topic string, data interface{}, sensitive bool) {
+	select {
+	case h.queue <- historyRequest{time: h.now().UTC(), topic: topic, data: data, sensitive: sensitive}:
+	default:
+		if dropped := atomic.AddUint64(&h.dropped, 1); dropped%historyQueueSize == 1 {
+			log.Warn().Uint64("dropped", dropped).Msg("Event history queue is full, dropping events")
+		}
+	}
+}
+
+// flush waits until all events queued before it are recorded.
+func (h *History) flush() {
+	flushed := make(chan struct{})
+	select {
+	case h.queue <- historyRequest{flushed: flushed}:
+	case <-h.stop:
+		return
+	}
+	select {
+	case <-flushed:
+	case <-h.stop:
+	}
+}
+
+func (h *History) run() {
+	for {
+		select {
+		case req := <-h.queue:
+			if req.flushed != nil {
+				close(req.flushed)
+				continue
+			}
+			h.store(req)
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+func (h *History) store(req historyRequest) {
+	event, err := json.Marshal(req.data)
+	if err != nil {
+		event, _ = json.Marshal(fmt.Sprintf("%+v", req.data))
+	}
+	recorded := RecordedEvent{Time: req.time, Topic: req.topic, Event: event, Sensitive: req.sensitive}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.add(recorded)
+	if !h.persist {
+		return
+	}
+	if err := h.append(recorded); err != nil {
+		log.Warn().Err(err).Msg("Failed to persist event history")
+	}
+}
+
+func (h *History) add(e RecordedEvent) {
+	if len(h.events) < cap(h.events) {
+		h.events = append(h.events, e)
+		return
+	}
+	h.events[h.next] = e
+	h.next = (h.next + 1) % len(h.events)
+}
+
+// ordered returns recorded events oldest first.
+func (h *History) ordered() []RecordedEvent {
+	return append(append([]RecordedEvent(nil), h.events[h.next:]...), h.events[:h.next]...)
+}
+
+// Events returns recorded events matching the filter, oldest first.
+func (h *History) Events(filter HistoryFilter) []RecordedEvent {
+	h.flush()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	events := []RecordedEvent{}
+	for _, e := range h.ordered() {
+		if filter.matches(e) {
+			events = append(events, e)
+		}
+	}
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[len(events)-filter.Limit:]
+	}
+	return events
+}
+
+// Dump writes a redacted copy of recorded events next to the history file and returns its path.
+// Payloads of sensitive events, e.g. config values, are masked as the copy is meant to leave the node.
+func (h *History) Dump() (string, error) {
+	h.flush()
+
+	h.mu.Lock()
+	events := h.ordered()
+	h.mu.Unlock()
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, e := range events {
+		if e.Sensitive {
+			e.Event = redactedEvent
+		}
+		if err := encoder.Encode(e); err != nil {
+			return "", fmt.Errorf("could not write redacted event history: %w", err)
+		}
+	}
+
+	dumpPath := filepath.Join(filepath.Dir(h.path), HistoryRedactedFileName)
+	if err := ioutil.WriteFile(dumpPath, buf.Bytes(), 0600); err != nil {
+		return "", fmt.Errorf("could not write redacted event history: %w", err)
+	}
+	return dumpPath, nil
+}
+
+func (h *History) append(e RecordedEvent) error {
+	// Keep the file bounded by rewriting it with the ring once it grows twice as long.
+	if h.written >= 2*cap(h.events) {
+		return h.rewrite()
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	h.written++
+	return nil
+}
+
+func (h *History) rewrite() error {
+	tmp, err := ioutil.TempFile(filepath.Dir(h.path), filepath.Base(h.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("could not write event history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	encoder := json.NewEncoder(tmp)
+	events := h.ordered()
+	for _, e := range events {
+		if err := encoder.Encode(e); err != nil {
+			tmp.Close()
+			return fmt.Errorf("could not write event history: %w", err)
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write event history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), h.path); err != nil {
+		return fmt.Errorf("could not write event history: %w", err)
+	}
+	h.written = len(events)
+	return nil
+}
+
+func (h *History) load() error {
+	file, err := os.Open(h.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not load event history: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var e RecordedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		h.add(e)
+		h.written++
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("could not load event history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEvent struct {
+	Status string
+}
+
+func TestHistory_KeepsNewestEvents(t *testing.T) {
+	history, err := NewHistory(3, filepath.Join(os.TempDir(), HistoryFileName), false)
+	require.NoError(t, err)
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	history.now = func() time.Time { return now }
+
+	for i, status := range []string{"Connecting", "Connected", "Disconnecting", "NotConnected"} {
+		now = now.Add(time.Duration(i) * time.Second)
+		history.Record("State", testEvent{Status: status})
+	}
+	history.Record("Traversal", "failed")
+
+	events := history.Events(HistoryFilter{})
+	assert.Len(t, events, 3)
+	assert.Equal(t, "State", events[0].Topic)
+	assert.JSONEq(t, `{"Status": "Disconnecting"}`, string(events[0].Event))
+	assert.Equal(t, "Traversal", events[2].Topic)
+
+	events = history.Events(HistoryFilter{Topics: []string{"State"}, Limit: 1})
+	assert.Len(t, events, 1)
+	assert.JSONEq(t, `{"Status": "NotConnected"}`, string(events[0].Event))
+
+	events = history.Events(HistoryFilter{Since: now.Add(time.Second)})
+	assert.Empty(t, events)
+}
+
+func TestHistory_Persist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, HistoryFileName)
+
+	history, err := NewHistory(2, path, true)
+	require.NoError(t, err)
+	defer history.Close()
+	for i := 0; i < 5; i++ {
+		history.Record("Node", i)
+	}
+	history.flush()
+
+	// file is kept bounded
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, len(splitLines(data)) <= 4)
+
+	restored, err := NewHistory(2, path, true)
+	require.NoError(t, err)
+	events := restored.Events(HistoryFilter{})
+	assert.Len(t, events, 2)
+	assert.Equal(t, json.RawMessage("3"), events[0].Event)
+	assert.Equal(t, json.RawMessage("4"), events[1].Event)
+}
+
+func TestHistory_Dump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	history, err := NewHistory(10, filepath.Join(dir, HistoryFileName), false)
+	require.NoError(t, err)
+	defer history.Close()
+	history.Record("Node", "started")
+	history.record("config:mmn.api-key", "secret-key", true)
+
+	path, err := history.Dump()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, HistoryRedactedFileName), path)
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-key")
+	lines := splitLines(data)
+	assert.Len(t, lines, 2)
+
+	var event RecordedEvent
+	require.NoError(t, json.Unmarshal(lines[0], &event))
+	assert.Equal(t, "Node", event.Topic)
+	assert.Equal(t, json.RawMessage(`"started"`), event.Event)
+	require.NoError(t, json.Unmarshal(lines[1], &event))
+	assert.Equal(t, "config:mmn.api-key", event.Topic)
+	assert.Equal(t, redactedEvent, event.Event)
+
+	// history itself keeps the original value
+	events := history.Events(HistoryFilter{Topics: []string{"config:mmn.api-key"}})
+	assert.Equal(t, json.RawMessage(`"secret-key"`), events[0].Event)
+}
+
+func TestHistory_RecordDoesNotBlockWhenQueueIsFull(t *testing.T) {
+	// history without a running recorder never drains its queue
+	history := &History{now: time.Now, queue: make(chan historyRequest, historyQueueSize)}
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 2*historyQueueSize; i++ {
+			history.Record("Node", i)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Record blocked on a full queue")
+	}
+	assert.Equal(t, uint64(historyQueueSize), history.dropped)
+}
+
+func TestBus_RecordsHistory(t *testing.T) {
+	history, err := NewHistory(10, filepath.Join(os.TempDir(), HistoryFileName), false)
+	require.NoError(t, err)
+	bus := New()
+	bus.RecordHistory(history)
+	require.NoError(t, bus.Define(
+		Topic{Name: "State", Event: testEvent{}},
+		Topic{Name: "Statistics", SkipHistory: true},
+		Topic{Name: "config:*", RedactHistory: true},
+	))
+
+	Handle("State").Publish(bus, testEvent{Status: "Connected"})
+	Handle("State").Publish(bus, "invalid")
+	Handle("Statistics").Publish(bus, 1)
+	Handle("config:mmn.api-key").Publish(bus, "secret-key")
+
+	events := history.Events(HistoryFilter{})
+	assert.Len(t, events, 2)
+	assert.Equal(t, "State", events[0].Topic)
+	assert.False(t, events[0].Sensitive)
+	assert.Equal(t, "config:mmn.api-key", events[1].Topic)
+	assert.True(t, events[1].Sensitive)
+}
+
+func splitLines(data []byte) (lines [][]byte) {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
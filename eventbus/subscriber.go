@@ -0,0 +1,191 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Delivery decides how events reach the subscriber.
+type Delivery int
+
+const (
+	// DeliverSync calls the handler by the publisher.
+	DeliverSync Delivery = iota
+	// DeliverAsync calls the handler from a queue drained by a dedicated goroutine.
+	DeliverAsync
+	// DeliverConcurrent calls the handler from a new goroutine for every event.
+	// It suits handlers which block for long, so they neither hold back later events nor the publisher.
+	DeliverConcurrent
+)
+
+type subscriber struct {
+	handler  reflect.Value
+	name     string
+	delivery Delivery
+	overflow OverflowPolicy
+	queue    chan interface{}
+	quit     chan struct{}
+	stopOnce sync.Once
+
+	delivered     uint64
+	dropped       uint64
+	inFlight      int64
+	maxQueueDepth int64
+}
+
+func newSubscriber(handler reflect.Value, delivery Delivery, queueSize int, overflow OverflowPolicy) *subscriber {
+	s := &subscriber{
+		handler:  handler,
+		name:     funcName(handler),
+		delivery: delivery,
+		overflow: overflow,
+		quit:     make(chan struct{}),
+	}
+	if delivery == DeliverAsync {
+		s.queue = make(chan interface{}, queueSize)
+		go s.run()
+	}
+	return s
+}
+
+func (s *subscriber) run() {
+	for {
+		select {
+		case data := <-s.queue:
+			s.call(data)
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+func (s *subscriber) stop() {
+	s.stopOnce.Do(func() {
+		close(s.quit)
+	})
+}
+
+func (s *subscriber) deliver(data interface{}) {
+	switch s.delivery {
+	case DeliverSync:
+		s.call(data)
+		return
+	case DeliverConcurrent:
+		atomic.AddInt64(&s.inFlight, 1)
+		go func() {
+			defer atomic.AddInt64(&s.inFlight, -1)
+			s.call(data)
+		}()
+		return
+	}
+
+	switch s.overflow {
+	case OverflowDropNewest:
+		select {
+		case s.queue <- data:
+		default:
+			s.drop()
+		}
+	case OverflowDropOldest:
+		for queued := false; !queued; {
+			select {
+			case s.queue <- data:
+				queued = true
+			default:
+				select {
+				case <-s.queue:
+					s.drop()
+				default:
+				}
+			}
+		}
+	default:
+		select {
+		case s.queue <- data:
+		case <-s.quit:
+			return
+		}
+	}
+	s.trackDepth()
+}
+
+func (s *subscriber) call(data interface{}) {
+	var args []reflect.Value
+	if s.handler.Type().NumIn() == 1 {
+		argType := s.handler.Type().In(0)
+		if data == nil {
+			args = []reflect.Value{reflect.Zero(argType)}
+		} else if value := reflect.ValueOf(data); value.Type().AssignableTo(argType) {
+			args = []reflect.Value{value}
+		} else {
+			log.Error().Msgf("Handler %s expects %s, dropping event of type %s", s.name, argType, value.Type())
+			s.drop()
+			return
+		}
+	}
+
+	s.handler.Call(args)
+	atomic.AddUint64(&s.delivered, 1)
+}
+
+func (s *subscriber) drop() {
+	if atomic.AddUint64(&s.dropped, 1) == 1 {
+		log.Warn().Msgf("Event subscriber %s is dropping events", s.name)
+	}
+}
+
+func (s *subscriber) trackDepth() {
+	depth := int64(len(s.queue))
+	for {
+		max := atomic.LoadInt64(&s.maxQueueDepth)
+		if depth <= max || atomic.CompareAndSwapInt64(&s.maxQueueDepth, max, depth) {
+			return
+		}
+	}
+}
+
+func (s *subscriber) stats() SubscriberStats {
+	stats := SubscriberStats{
+		Handler:       s.name,
+		Async:         s.delivery != DeliverSync,
+		Concurrent:    s.delivery == DeliverConcurrent,
+		InFlight:      int(atomic.LoadInt64(&s.inFlight)),
+		Delivered:     atomic.LoadUint64(&s.delivered),
+		Dropped:       atomic.LoadUint64(&s.dropped),
+		MaxQueueDepth: int(atomic.LoadInt64(&s.maxQueueDepth)),
+	}
+	if s.delivery == DeliverAsync {
+		stats.Overflow = s.overflow
+		stats.QueueSize = cap(s.queue)
+		stats.QueueDepth = len(s.queue)
+	}
+	return stats
+}
+
+func funcName(fn reflect.Value) string {
+	if f := runtime.FuncForPC(fn.Pointer()); f != nil {
+		return f.Name()
+	}
+	return fn.Type().String()
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what happens to events published to an async subscriber with a full queue.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock makes the publisher wait until the subscriber catches up.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest discards the oldest queued event to make room for the new one.
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+	// OverflowDropNewest discards the event being published.
+	OverflowDropNewest OverflowPolicy = "drop-newest"
+)
+
+func (p OverflowPolicy) validate() error {
+	switch p {
+	case OverflowBlock, OverflowDropOldest, OverflowDropNewest:
+		return nil
+	}
+	return fmt.Errorf("unknown overflow policy %q", p)
+}
+
+// DefaultQueueSize is the queue size of async subscribers when the topic does not set one.
+const DefaultQueueSize = 256
+
+// Topic defines the event type and async delivery settings of a topic.
+// Packages owning a topic publish and subscribe to it through a Handle wrapped in typed functions.
+type Topic struct {
+	// Name of the topic, a trailing "*" defines all topics with the given prefix.
+	Name string
+	// Event is a sample of published events, nil allows events of any type.
+	Event interface{}
+	// QueueSize of async subscribers, DefaultQueueSize if zero.
+	QueueSize int
+	// Overflow policy of async subscribers, OverflowBlock if empty.
+	Overflow OverflowPolicy
+	// SkipHistory excludes frequent events from event history.
+	SkipHistory bool
+	// RedactHistory masks events holding personal data in event history added to feedback archives.
+	RedactHistory bool
+}
+
+func (t Topic) validate() error {
+	if t.Name == "" {
+		return errors.New("topic name is empty")
+	}
+	if t.QueueSize < 0 {
+		return fmt.Errorf("topic %q: invalid queue size %d", t.Name, t.QueueSize)
+	}
+	if t.Overflow != "" {
+		if err := t.Overflow.validate(); err != nil {
+			return fmt.Errorf("topic %q: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+func (t Topic) isPrefix() bool {
+	return strings.HasSuffix(t.Name, "*")
+}
+
+func (t Topic) matches(name string) bool {
+	return t.isPrefix() && strings.HasPrefix(name, strings.TrimSuffix(t.Name, "*"))
+}
+
+type topic struct {
+	name          string
+	defined       bool
+	skipHistory   bool
+	redactHistory bool
+	eventType     reflect.Type
+	queueSize     int
+	overflow      OverflowPolicy
+	published     uint64
+	subscribers   []*subscriber
+}
+
+func newTopic(name string, def *Topic) *topic {
+	t := &topic{name: name, queueSize: DefaultQueueSize, overflow: OverflowBlock}
+	if def == nil {
+		return t
+	}
+
+	t.defined = true
+	t.skipHistory = def.SkipHistory
+	t.redactHistory = def.RedactHistory
+	t.eventType = reflect.TypeOf(def.Event)
+	if def.QueueSize > 0 {
+		t.queueSize = def.QueueSize
+	}
+	if def.Overflow != "" {
+		t.overflow = def.Overflow
+	}
+	return t
+}
+
+// accepts checks that the handler can receive events of the topic.
+func (t *topic) accepts(handler reflect.Type) error {
+	if t.eventType == nil || handler.NumIn() == 0 {
+		return nil
+	}
+	if !t.eventType.AssignableTo(handler.In(0)) {
+		return fmt.Errorf("expects %s, topic publishes %s", handler.In(0), t.eventType)
+	}
+	return nil
+}
+
+// check verifies that the event matches the topic type.
+func (t *topic) check(data interface{}) error {
+	if t.eventType == nil || data == nil {
+		return nil
+	}
+	if dataType := reflect.TypeOf(data); !dataType.AssignableTo(t.eventType) {
+		return fmt.Errorf("event of type %s, topic publishes %s", dataType, t.eventType)
+	}
+	return nil
+}
+
+func (t *topic) stats() TopicStats {
+	stats := TopicStats{
+		Name:        t.name,
+		Defined:     t.defined,
+		Published:   atomic.LoadUint64(&t.published),
+		Subscribers: make([]SubscriberStats, len(t.subscribers)),
+	}
+	if t.eventType != nil {
+		stats.Event = t.eventType.String()
+	}
+	for i, s := range t.subscribers {
+		stats.Subscribers[i] = s.stats()
+	}
+	return stats
+}
+
+// TopicStats describes a topic and its subscribers.
+type TopicStats struct {
+	Name        string
+	Event       string
+	Defined     bool
+	Published   uint64
+	Subscribers []SubscriberStats
+}
+
+// SubscriberStats describes delivery to a single subscriber.
+type SubscriberStats struct {
+	Handler       string
+	Async         bool
+	Concurrent    bool
+	InFlight      int
+	Overflow      OverflowPolicy
+	QueueSize     int
+	QueueDepth    int
+	MaxQueueDepth int
+	Delivered     uint64
+	Dropped       uint64
+}
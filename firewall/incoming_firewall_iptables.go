@@ -21,6 +21,7 @@ import (
 	"net"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mysteriumnetwork/node/firewall/ipset"
@@ -29,12 +30,17 @@ import (
 )
 
 const (
-	incomingFirewallChain = "MYST_PROVIDER_FIREWALL"
-	incomingFirewallIpset = "myst-provider-dst-whitelist"
+	incomingFirewallChain  = "MYST_PROVIDER_FIREWALL"
+	incomingFirewallIpset  = "myst-provider-dst-whitelist"
+	incomingFirewallIpset6 = "myst-provider-dst-whitelist6"
 )
 
 // incomingFirewallIptables allows incoming traffic blocking in IP granularity.
-type incomingFirewallIptables struct{}
+// IPv6 chain is set up on the first blocked IPv6 network only, as most providers don't use it.
+type incomingFirewallIptables struct {
+	mu   sync.Mutex
+	ipv6 bool
+}
 
 func (ibi *incomingFirewallIptables) Setup() error {
 	if err := ibi.checkIpsetVersion(); err != nil {
@@ -61,12 +67,30 @@ func (ibi *incomingFirewallIptables) Teardown() {
 	if errOutput, err := ipset.Exec(ipset.OpDelete(incomingFirewallIpset)); err != nil {
 		log.Warn().Err(err).Msgf("Error deleting ipset table. %s", strings.Join(errOutput, ""))
 	}
+
+	ibi.mu.Lock()
+	defer ibi.mu.Unlock()
+	if ibi.ipv6 {
+		if err := cleanupChain(iptables.Exec6); err != nil {
+			log.Warn().Err(err).Msg("Error cleaning up ip6tables rules, you might want to do it yourself")
+		}
+		if errOutput, err := ipset.Exec(ipset.OpDelete(incomingFirewallIpset6)); err != nil {
+			log.Warn().Err(err).Msgf("Error deleting ipset table. %s", strings.Join(errOutput, ""))
+		}
+		ibi.ipv6 = false
+	}
 }
 
 func (ibi *incomingFirewallIptables) BlockIncomingTraffic(network net.IPNet) (IncomingRuleRemove, error) {
-	remover, err := iptables.AddRuleWithRemoval(
-		iptables.AppendTo("FORWARD").RuleSpec("-s", network.String(), "-j", incomingFirewallChain),
-	)
+	rule := iptables.AppendTo("FORWARD").RuleSpec("-s", network.String(), "-j", incomingFirewallChain)
+	if network.IP.To4() == nil {
+		if err := ibi.setupIPv6(); err != nil {
+			return nil, err
+		}
+		rule = rule.IPv6()
+	}
+
+	remover, err := iptables.AddRuleWithRemoval(rule)
 	if err != nil {
 		return nil, err
 	}
@@ -106,15 +130,50 @@ func (ibi *incomingFirewallIptables) AllowURLAccess(rawURLs ...string) (Incoming
 }
 
 func (ibi *incomingFirewallIptables) AllowIPAccess(ip net.IP) (IncomingRuleRemove, error) {
-	if _, err := ipset.Exec(ipset.OpIPAdd(incomingFirewallIpset, ip, true)); err != nil {
+	setName := incomingFirewallIpset
+	if ip.To4() == nil {
+		ibi.mu.Lock()
+		ipv6 := ibi.ipv6
+		ibi.mu.Unlock()
+		// Nothing is blocked for IPv6 yet.
+		if !ipv6 {
+			return func() error { return nil }, nil
+		}
+		setName = incomingFirewallIpset6
+	}
+
+	if _, err := ipset.Exec(ipset.OpIPAdd(setName, ip, true)); err != nil {
 		return nil, err
 	}
 	return func() error {
-		_, err := ipset.Exec(ipset.OpIPRemove(incomingFirewallIpset, ip))
+		_, err := ipset.Exec(ipset.OpIPRemove(setName, ip))
 		return err
 	}, nil
 }
 
+func (ibi *incomingFirewallIptables) setupIPv6() error {
+	ibi.mu.Lock()
+	defer ibi.mu.Unlock()
+	if ibi.ipv6 {
+		return nil
+	}
+
+	if err := cleanupChain(iptables.Exec6); err != nil {
+		return err
+	}
+	ipset.Exec(ipset.OpDelete(incomingFirewallIpset6))
+
+	op := append(ipset.OpCreate(incomingFirewallIpset6, ipset.SetTypeHashIP, 24*time.Hour, nil, 0), "family", "inet6")
+	if _, err := ipset.Exec(op); err != nil {
+		return err
+	}
+	if err := setupChain(iptables.Exec6, incomingFirewallIpset6); err != nil {
+		return err
+	}
+	ibi.ipv6 = true
+	return nil
+}
+
 func (ibi *incomingFirewallIptables) checkIpsetVersion() error {
 	output, err := ipset.Exec(ipset.OpVersion())
 	if err != nil {
@@ -127,27 +186,35 @@ func (ibi *incomingFirewallIptables) checkIpsetVersion() error {
 }
 
 func (ibi *incomingFirewallIptables) setupFirewallChain() error {
+	return setupChain(iptables.Exec, incomingFirewallIpset)
+}
+
+func (ibi *incomingFirewallIptables) cleanupStaleRules() error {
+	return cleanupChain(iptables.Exec)
+}
+
+func setupChain(exec func(args ...string) ([]string, error), whitelistIpset string) error {
 	// Add chain
-	if _, err := iptables.Exec("-N", incomingFirewallChain); err != nil {
+	if _, err := exec("-N", incomingFirewallChain); err != nil {
 		return err
 	}
 
 	// Append rule - packets going to firewall with these destination IPs are whitelisted
-	if _, err := iptables.Exec("-A", incomingFirewallChain, "-m", "set", "--match-set", incomingFirewallIpset, "dst", "-j", "ACCEPT"); err != nil {
+	if _, err := exec("-A", incomingFirewallChain, "-m", "set", "--match-set", whitelistIpset, "dst", "-j", "ACCEPT"); err != nil {
 		return err
 	}
 
 	// Append rule - by default all packets going to firewall chain are rejected
-	if _, err := iptables.Exec("-A", incomingFirewallChain, "-j", "REJECT"); err != nil {
+	if _, err := exec("-A", incomingFirewallChain, "-j", "REJECT"); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (ibi *incomingFirewallIptables) cleanupStaleRules() error {
+func cleanupChain(exec func(args ...string) ([]string, error)) error {
 	// List rules
-	rules, err := iptables.Exec("-S", "FORWARD")
+	rules, err := exec("-S", "FORWARD")
 	if err != nil {
 		return err
 	}
@@ -156,26 +223,26 @@ func (ibi *incomingFirewallIptables) cleanupStaleRules() error {
 		if strings.HasSuffix(rule, incomingFirewallChain) {
 			deleteRule := strings.Replace(rule, "-A", "-D", 1)
 			deleteRuleArgs := strings.Split(deleteRule, " ")
-			if _, err := iptables.Exec(deleteRuleArgs...); err != nil {
+			if _, err := exec(deleteRuleArgs...); err != nil {
 				return err
 			}
 		}
 	}
 
 	// List chain rules
-	if _, err := iptables.Exec("-L", incomingFirewallChain); err != nil {
+	if _, err := exec("-L", incomingFirewallChain); err != nil {
 		// error means no such chain - log error just in case and bail out
 		log.Info().Err(err).Msg("[setup] Got error while listing kill switch chain rules. Probably nothing to worry about")
 		return nil
 	}
 
 	// Remove chain rules
-	if _, err := iptables.Exec("-F", incomingFirewallChain); err != nil {
+	if _, err := exec("-F", incomingFirewallChain); err != nil {
 		return err
 	}
 
 	// Remove chain
-	_, err = iptables.Exec("-X", incomingFirewallChain)
+	_, err = exec("-X", incomingFirewallChain)
 	return err
 }
 
@@ -150,3 +150,40 @@ func Test_incomingFirewallIptables_AllowIPAccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, mockedIpset.VerifyCalledWithArgs("del myst-provider-dst-whitelist 1.2.3.4"))
 }
+
+func Test_incomingFirewallIptables_BlockIncomingTrafficIPv6(t *testing.T) {
+	mockedIpset := ipsetExecMock{
+		mocks: map[string]ipsetExecResult{},
+	}
+	ipset.Exec = mockedIpset.Exec
+	mockedIptables := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedIptables.Exec
+	mockedIp6tables := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec6 = mockedIp6tables.Exec
+
+	fw := &incomingFirewallIptables{}
+
+	removeRule, err := fw.AllowIPAccess(net.ParseIP("2001:db8::1"))
+	assert.NoError(t, err)
+	assert.NoError(t, removeRule())
+	assert.False(t, mockedIpset.VerifyCalledWithArgs("add myst-provider-dst-whitelist6 2001:db8::1 --exist"))
+
+	_, network, _ := net.ParseCIDR("fd6d:7973:7400:1::1/64")
+	removeBlock, err := fw.BlockIncomingTraffic(*network)
+	assert.NoError(t, err)
+	assert.True(t, mockedIpset.VerifyCalledWithArgs("create myst-provider-dst-whitelist6 hash:ip --timeout 86400 family inet6"))
+	assert.True(t, mockedIp6tables.VerifyCalledWithArgs("-A MYST_PROVIDER_FIREWALL -m set --match-set myst-provider-dst-whitelist6 dst -j ACCEPT"))
+	assert.True(t, mockedIp6tables.VerifyCalledWithArgs("-A FORWARD -s fd6d:7973:7400:1::/64 -j MYST_PROVIDER_FIREWALL"))
+	assert.False(t, mockedIptables.VerifyCalledWithArgs("-A FORWARD -s fd6d:7973:7400:1::/64 -j MYST_PROVIDER_FIREWALL"))
+
+	_, err = fw.AllowIPAccess(net.ParseIP("2001:db8::1"))
+	assert.NoError(t, err)
+	assert.True(t, mockedIpset.VerifyCalledWithArgs("add myst-provider-dst-whitelist6 2001:db8::1 --exist"))
+
+	removeBlock()
+	assert.True(t, mockedIp6tables.VerifyCalledWithArgs("-D FORWARD -s fd6d:7973:7400:1::/64 -j MYST_PROVIDER_FIREWALL"))
+}
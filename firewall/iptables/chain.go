@@ -24,6 +24,7 @@ type Rule struct {
 	chainName string
 	action    []string
 	ruleSpec  []string
+	ipv6      bool
 }
 
 // AppendTo creates a new rule to be appended to the specified chain.
@@ -48,6 +49,17 @@ func (r Rule) RuleSpec(spec ...string) Rule {
 	return r
 }
 
+// IPv6 makes the rule to be applied by ip6tables.
+func (r Rule) IPv6() Rule {
+	r.ipv6 = true
+	return r
+}
+
+// IsIPv6 checks if the rule is applied by ip6tables.
+func (r Rule) IsIPv6() bool {
+	return r.ipv6
+}
+
 // ApplyArgs returns an argument list to be passed to the iptables executable to APPLY the rule.
 func (r Rule) ApplyArgs() []string {
 	return append(r.action, r.ruleSpec...)
@@ -61,6 +73,7 @@ func (r Rule) RemoveArgs() []string {
 // Equals checks if two Rules are equal.
 func (r Rule) Equals(another Rule) bool {
 	return r.chainName == another.chainName &&
+		r.ipv6 == another.ipv6 &&
 		equalStringSlice(r.ruleSpec, another.ruleSpec)
 }
 
@@ -37,6 +37,7 @@ type outgoingFirewallIptables struct {
 	lock             sync.Mutex
 	trafficLockScope Scope
 	referenceTracker map[string]refCount
+	ipv6             bool
 }
 
 // Setup tries to setup all changes made by setup and leave system in the state before setup.
@@ -44,17 +45,26 @@ func (obi *outgoingFirewallIptables) Setup() error {
 	if err := obi.checkIptablesVersion(); err != nil {
 		return err
 	}
-	if err := obi.cleanupStaleRules(); err != nil {
+	if err := cleanupKillSwitchChain(iptables.Exec); err != nil {
 		return err
 	}
-	return obi.setupKillSwitchChain()
+	return setupKillSwitchChain(iptables.Exec)
 }
 
 // Teardown tries to cleanup all changes made by setup and leave system in the state before setup.
 func (obi *outgoingFirewallIptables) Teardown() {
-	if err := obi.cleanupStaleRules(); err != nil {
+	if err := cleanupKillSwitchChain(iptables.Exec); err != nil {
 		log.Warn().Err(err).Msg("Error cleaning up iptables rules, you might want to do it yourself")
 	}
+
+	obi.lock.Lock()
+	defer obi.lock.Unlock()
+	if obi.ipv6 {
+		if err := cleanupKillSwitchChain(iptables.Exec6); err != nil {
+			log.Warn().Err(err).Msg("Error cleaning up ip6tables rules, you might want to do it yourself")
+		}
+		obi.ipv6 = false
+	}
 }
 
 // BlockOutgoingTraffic effectively disallows any outgoing traffic from consumer node with specified scope.
@@ -73,11 +83,17 @@ func (obi *outgoingFirewallIptables) BlockOutgoingTraffic(scope Scope, outboundI
 }
 
 // AllowIPAccess adds exception to blocked traffic for specified URL (host part is usually taken).
+// IPv6 addresses and networks are allowed in the ip6tables kill switch chain.
 func (obi *outgoingFirewallIptables) AllowIPAccess(ip string) (OutgoingRuleRemove, error) {
-	return obi.trackingReferenceCall("allow:"+ip, func() (rule OutgoingRuleRemove, e error) {
-		return iptables.AddRuleWithRemoval(
-			iptables.InsertAt(killswitchChain, 1).RuleSpec("-d", ip, "-j", "ACCEPT"),
-		)
+	return obi.trackingReferenceCall("allow:"+ip, func() (OutgoingRuleRemove, error) {
+		rule := iptables.InsertAt(killswitchChain, 1).RuleSpec("-d", ip, "-j", "ACCEPT")
+		if strings.Contains(ip, ":") {
+			if err := obi.setupIPv6(); err != nil {
+				return nil, err
+			}
+			rule = rule.IPv6()
+		}
+		return iptables.AddRuleWithRemoval(rule)
 	})
 }
 
@@ -117,31 +133,47 @@ func (obi *outgoingFirewallIptables) checkIptablesVersion() error {
 	return nil
 }
 
-func (obi *outgoingFirewallIptables) setupKillSwitchChain() error {
+// setupIPv6 creates ip6tables kill switch chain on first use, caller must hold the lock.
+func (obi *outgoingFirewallIptables) setupIPv6() error {
+	if obi.ipv6 {
+		return nil
+	}
+
+	if err := cleanupKillSwitchChain(iptables.Exec6); err != nil {
+		return err
+	}
+	if err := setupKillSwitchChain(iptables.Exec6); err != nil {
+		return err
+	}
+	obi.ipv6 = true
+	return nil
+}
+
+func setupKillSwitchChain(exec func(args ...string) ([]string, error)) error {
 	// Add chain
-	if _, err := iptables.Exec("-N", killswitchChain); err != nil {
+	if _, err := exec("-N", killswitchChain); err != nil {
 		return err
 	}
 	// Append rule - by default all packets going to kill switch chain are rejected
-	if _, err := iptables.Exec("-A", killswitchChain, "-m", "conntrack", "--ctstate", "NEW", "-j", "REJECT"); err != nil {
+	if _, err := exec("-A", killswitchChain, "-m", "conntrack", "--ctstate", "NEW", "-j", "REJECT"); err != nil {
 		return err
 	}
 
 	// Insert rule - TODO for now always allow outgoing DNS traffic, BUT it should be exposed as separate firewall call
-	if _, err := iptables.Exec("-I", killswitchChain, "1", "-p", "udp", "--dport", "53", "-j", "ACCEPT"); err != nil {
+	if _, err := exec("-I", killswitchChain, "1", "-p", "udp", "--dport", "53", "-j", "ACCEPT"); err != nil {
 		return err
 	}
 	// Insert rule - TCP DNS is not so popular - but for the sake of humanity, lets allow it too
-	if _, err := iptables.Exec("-I", killswitchChain, "1", "-p", "tcp", "--dport", "53", "-j", "ACCEPT"); err != nil {
+	if _, err := exec("-I", killswitchChain, "1", "-p", "tcp", "--dport", "53", "-j", "ACCEPT"); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (obi *outgoingFirewallIptables) cleanupStaleRules() error {
+func cleanupKillSwitchChain(exec func(args ...string) ([]string, error)) error {
 	// List rules
-	rules, err := iptables.Exec("-S", "OUTPUT")
+	rules, err := exec("-S", "OUTPUT")
 	if err != nil {
 		return err
 	}
@@ -150,26 +182,26 @@ func (obi *outgoingFirewallIptables) cleanupStaleRules() error {
 		if strings.HasSuffix(rule, killswitchChain) {
 			deleteRule := strings.Replace(rule, "-A", "-D", 1)
 			deleteRuleArgs := strings.Split(deleteRule, " ")
-			if _, err := iptables.Exec(deleteRuleArgs...); err != nil {
+			if _, err := exec(deleteRuleArgs...); err != nil {
 				return err
 			}
 		}
 	}
 
 	// List chain rules
-	if _, err := iptables.Exec("-L", killswitchChain); err != nil {
+	if _, err := exec("-L", killswitchChain); err != nil {
 		// error means no such chain - log error just in case and bail out
 		log.Info().Err(err).Msg("[setup] Got error while listing kill switch chain rules. Probably nothing to worry about")
 		return nil
 	}
 
 	// Remove chain rules
-	if _, err := iptables.Exec("-F", killswitchChain); err != nil {
+	if _, err := exec("-F", killswitchChain); err != nil {
 		return err
 	}
 
 	// Remove chain
-	_, err = iptables.Exec("-X", killswitchChain)
+	_, err = exec("-X", killswitchChain)
 	return err
 }
 
@@ -78,6 +78,34 @@ func Test_outgoingFirewallIptables_AllowIPAccessIsAddedAndRemoved(t *testing.T)
 	assert.Equal(t, 0, fw.referenceTracker["allow:test-ip"].count)
 }
 
+func Test_outgoingFirewallIptables_AllowIPv6AccessUsesIp6tables(t *testing.T) {
+	mockedExec := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	mockedExec6 := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedExec.Exec
+	iptables.Exec6 = mockedExec6.Exec
+
+	fw := &outgoingFirewallIptables{
+		referenceTracker: make(map[string]refCount),
+	}
+
+	removeRule, err := fw.AllowIPAccess("2001:db8::/32")
+	assert.NoError(t, err)
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-N", killswitchChain))
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-I", killswitchChain, "1", "-d", "2001:db8::/32", "-j", "ACCEPT"))
+	assert.False(t, mockedExec.VerifyCalledWithArgs("-I", killswitchChain, "1", "-d", "2001:db8::/32", "-j", "ACCEPT"))
+
+	removeRule()
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-D", killswitchChain, "-d", "2001:db8::/32", "-j", "ACCEPT"))
+
+	fw.Teardown()
+	assert.True(t, mockedExec6.VerifyCalledWithArgs("-X", killswitchChain))
+	assert.False(t, fw.ipv6)
+}
+
 func Test_outgoingFirewallIptables_HostsFromMultipleURLsAreAllowed(t *testing.T) {
 	fw := &outgoingFirewallIptables{
 		referenceTracker: make(map[string]refCount),
@@ -18,6 +18,7 @@
 package identity
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/ecdsa"
@@ -39,6 +40,7 @@ import (
 
 type ethKeystore interface {
 	Delete(a accounts.Account, passphrase string) error
+	Update(a accounts.Account, passphrase, newPassphrase string) error
 	Accounts() []accounts.Account
 	NewAccount(passphrase string) (accounts.Account, error)
 	Find(a accounts.Account) (accounts.Account, error)
@@ -62,6 +64,60 @@ type Keystore struct {
 
 	unlocked map[common.Address]*unlocked // Currently unlocked account (decrypted private keys)
 	mu       sync.RWMutex
+
+	external        *ExternalSigner
+	externalDerived map[common.Address][]byte // Encryption keys derived from external signatures
+}
+
+// errExternalKey is returned for operations which need the private key of an externally signed identity.
+var errExternalKey = errors.New("identity key is held by external signer")
+
+// errNonDeterministicSigner is returned when encryption key can't be derived from external signatures.
+var errNonDeterministicSigner = errors.New("external signer signatures are not deterministic (RFC 6979)")
+
+// externalKeyDerivationHash is signed by the external signer to derive the encryption key of an identity.
+var externalKeyDerivationHash = crypto.Keccak256([]byte("Mysterium identity encryption key"))
+
+// UseExternalSigner makes keystore delegate identities handled by the external signer, their keys never enter the node.
+func (ks *Keystore) UseExternalSigner(external *ExternalSigner) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.external = external
+	ks.externalDerived = make(map[common.Address][]byte)
+}
+
+// Accounts returns accounts from the filesystem and the ones held by external signer.
+func (ks *Keystore) Accounts() []accounts.Account {
+	list := ks.ethKeystore.Accounts()
+	for _, address := range ks.external.Addresses() {
+		list = append(list, accounts.Account{Address: address})
+	}
+	return list
+}
+
+// Find resolves the given account into a unique entry in the keystore.
+func (ks *Keystore) Find(a accounts.Account) (accounts.Account, error) {
+	if ks.external.Handles(a.Address) {
+		return accounts.Account{Address: a.Address}, nil
+	}
+	return ks.ethKeystore.Find(a)
+}
+
+// Delete deletes the key matched by account if the passphrase is correct.
+func (ks *Keystore) Delete(a accounts.Account, passphrase string) error {
+	if ks.external.Handles(a.Address) {
+		return errExternalKey
+	}
+	return ks.ethKeystore.Delete(a, passphrase)
+}
+
+// Update changes the passphrase of an existing account.
+func (ks *Keystore) Update(a accounts.Account, passphrase, newPassphrase string) error {
+	if ks.external.Handles(a.Address) {
+		return errExternalKey
+	}
+	return ks.ethKeystore.Update(a, passphrase, newPassphrase)
 }
 
 // Unlock unlocks the given account indefinitely.
@@ -88,7 +144,14 @@ func (ks *Keystore) Lock(addr common.Address) error {
 // If the account address is already unlocked for a duration, TimedUnlock extends or
 // shortens the active unlock timeout. If the address was previously unlocked
 // indefinitely the timeout is not altered.
+//
+// Externally signed accounts are unlocked as long as the external signer holds their keys,
+// so the passphrase and timeout are ignored and only the signer is checked.
 func (ks *Keystore) TimedUnlock(a accounts.Account, passphrase string, timeout time.Duration) error {
+	if ks.external.Handles(a.Address) {
+		return ks.external.Verify(a.Address)
+	}
+
 	a, key, err := ks.getDecryptedKey(a, passphrase)
 	if err != nil {
 		return err
@@ -146,8 +209,41 @@ func (ks *Keystore) expire(addr common.Address, u *unlocked, timeout time.Durati
 	}
 }
 
-// Encrypt takes a derived key for the given address and encrypts the plaintext.
-func (ks *Keystore) Encrypt(addr common.Address, plaintext []byte) ([]byte, error) {
+// derivedKey returns encryption key derived from the private key of the given address.
+// For externally signed identities it is derived from a signature of a constant hash,
+// which relies on deterministic (RFC 6979) signatures, so the hash is signed twice and
+// signers producing different signatures are refused instead of losing encrypted data on restart.
+func (ks *Keystore) derivedKey(addr common.Address) ([]byte, error) {
+	if ks.external.Handles(addr) {
+		ks.mu.RLock()
+		keyDerived, found := ks.externalDerived[addr]
+		ks.mu.RUnlock()
+		if found {
+			return keyDerived, nil
+		}
+
+		signature, err := ks.external.SignHash(addr, externalKeyDerivationHash)
+		if err != nil {
+			return nil, err
+		}
+		again, err := ks.external.SignHash(addr, externalKeyDerivationHash)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(signature, again) {
+			return nil, errNonDeterministicSigner
+		}
+		keyDerived, err = deriveKey(signature[:64])
+		if err != nil {
+			return nil, err
+		}
+
+		ks.mu.Lock()
+		ks.externalDerived[addr] = keyDerived
+		ks.mu.Unlock()
+		return keyDerived, nil
+	}
+
 	ks.mu.RLock()
 	defer ks.mu.RUnlock()
 
@@ -155,8 +251,12 @@ func (ks *Keystore) Encrypt(addr common.Address, plaintext []byte) ([]byte, erro
 	if !found {
 		return nil, ethKs.ErrLocked
 	}
+	return key.deriveKey()
+}
 
-	keyDerived, err := key.deriveKey()
+// Encrypt takes a derived key for the given address and encrypts the plaintext.
+func (ks *Keystore) Encrypt(addr common.Address, plaintext []byte) ([]byte, error) {
+	keyDerived, err := ks.derivedKey(addr)
 	if err != nil {
 		return nil, err
 	}
@@ -181,15 +281,7 @@ func (ks *Keystore) Encrypt(addr common.Address, plaintext []byte) ([]byte, erro
 
 // Decrypt takes a derived key for the given address and decrypts the encrypted message.
 func (ks *Keystore) Decrypt(addr common.Address, encrypted []byte) ([]byte, error) {
-	ks.mu.RLock()
-	defer ks.mu.RUnlock()
-
-	key, found := ks.unlocked[addr]
-	if !found {
-		return nil, ethKs.ErrLocked
-	}
-
-	keyDerived, err := key.deriveKey()
+	keyDerived, err := ks.derivedKey(addr)
 	if err != nil {
 		return nil, err
 	}
@@ -216,6 +308,10 @@ func (ks *Keystore) Decrypt(addr common.Address, encrypted []byte) ([]byte, erro
 // SignHash calculates a ECDSA signature for the given hash. The produced
 // signature is in the [R || S || V] format where V is 0 or 1.
 func (ks *Keystore) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
+	if ks.external.Handles(a.Address) {
+		return ks.external.SignHash(a.Address, hash)
+	}
+
 	// Look up the key to sign with and abort if it cannot be found
 	ks.mu.RLock()
 	defer ks.mu.RUnlock()
@@ -242,8 +338,12 @@ type unlocked struct {
 }
 
 func (u *unlocked) deriveKey() ([]byte, error) {
+	return deriveKey(u.Key.PrivateKey.D.Bytes())
+}
+
+func deriveKey(secret []byte) ([]byte, error) {
 	hashFunc := sha512.New
-	hkdfDerived := hkdf.New(hashFunc, u.Key.PrivateKey.D.Bytes(), nil, nil)
+	hkdfDerived := hkdf.New(hashFunc, secret, nil, nil)
 	key := make([]byte, 32)
 	_, err := io.ReadFull(hkdfDerived, key)
 	return key, err
@@ -128,6 +128,10 @@ func (ekm *ethKeystoreMock) Delete(a accounts.Account, passphrase string) error
 	return nil
 }
 
+func (ekm *ethKeystoreMock) Update(a accounts.Account, passphrase, newPassphrase string) error {
+	return nil
+}
+
 func (ekm *ethKeystoreMock) Export(a accounts.Account, passphrase, newPassphrase string) ([]byte, error) {
 	return []byte("exported"), nil
 }
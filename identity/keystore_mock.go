@@ -172,6 +172,35 @@ func (mk *mockKeystore) Find(a accounts.Account) (accounts.Account, error) {
 	return accounts.Account{}, ethKs.ErrNoMatch
 }
 
+func (mk *mockKeystore) Delete(a accounts.Account, passphrase string) error {
+	mk.lock.Lock()
+	defer mk.lock.Unlock()
+
+	if v, ok := mk.keys[a.Address]; ok {
+		if v.Pass != passphrase {
+			return ethKs.ErrDecrypt
+		}
+		delete(mk.keys, a.Address)
+		return nil
+	}
+	return ethKs.ErrNoMatch
+}
+
+func (mk *mockKeystore) Update(a accounts.Account, passphrase, newPassphrase string) error {
+	mk.lock.Lock()
+	defer mk.lock.Unlock()
+
+	if v, ok := mk.keys[a.Address]; ok {
+		if v.Pass != passphrase {
+			return ethKs.ErrDecrypt
+		}
+		v.Pass = newPassphrase
+		mk.keys[a.Address] = v
+		return nil
+	}
+	return ethKs.ErrNoMatch
+}
+
 // MockDecryptFunc represents the mock decrypt func
 var MockDecryptFunc = func(keyjson []byte, auth string) (*ethKs.Key, error) {
 	pk, err := crypto.HexToECDSA(common.Bytes2Hex(keyjson))
@@ -21,6 +21,7 @@
 package identity
 
 import (
+	"strings"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -35,6 +36,7 @@ import (
 const (
 	AppTopicIdentityUnlock  = "identity-unlocked"
 	AppTopicIdentityCreated = "identity-created"
+	AppTopicIdentityDeleted = "identity-deleted"
 )
 
 // AppEventIdentityUnlock represents the payload that is sent on identity unlock.
@@ -43,17 +45,27 @@ type AppEventIdentityUnlock struct {
 	ID      Identity
 }
 
+// AppEventIdentityDeleted represents the payload that is sent on identity deletion.
+type AppEventIdentityDeleted struct {
+	ID Identity
+}
+
 // ResidentCountryEvent represent actual resident country changed event
 type ResidentCountryEvent struct {
 	ID      string
 	Country string
 }
 
+// ErrIdentityInUse is returned when deleting an identity used by a service or connection.
+var ErrIdentityInUse = errors.New("identity is in use")
+
 type identityManager struct {
 	keystoreManager keystore
 	residentCountry *ResidentCountry
-	unlocked        map[string]bool // Currently unlocked addresses
+	unlocked        map[string]bool // Currently unlocked addresses, in lower case
 	unlockedMu      sync.RWMutex
+	users           map[string][]*string // Users of identities by address in lower case
+	usersMu         sync.Mutex
 	eventBus        eventbus.EventBus
 }
 
@@ -63,6 +75,9 @@ type keystore interface {
 	NewAccount(passphrase string) (accounts.Account, error)
 	Find(a accounts.Account) (accounts.Account, error)
 	Unlock(a accounts.Account, passphrase string) error
+	Lock(addr common.Address) error
+	Delete(a accounts.Account, passphrase string) error
+	Update(a accounts.Account, passphrase, newPassphrase string) error
 	SignHash(a accounts.Account, hash []byte) ([]byte, error)
 }
 
@@ -72,6 +87,7 @@ func NewIdentityManager(keystore keystore, eventBus eventbus.EventBus, residentC
 		keystoreManager: keystore,
 		residentCountry: residentCountry,
 		unlocked:        map[string]bool{},
+		users:           map[string][]*string{},
 		eventBus:        eventBus,
 	}
 }
@@ -80,7 +96,7 @@ func NewIdentityManager(keystore keystore, eventBus eventbus.EventBus, residentC
 func (idm *identityManager) IsUnlocked(identity string) bool {
 	idm.unlockedMu.Lock()
 	defer idm.unlockedMu.Unlock()
-	_, ok := idm.unlocked[identity]
+	_, ok := idm.unlocked[strings.ToLower(identity)]
 	return ok
 }
 
@@ -106,7 +122,7 @@ func (idm *identityManager) CreateNewIdentity(passphrase string) (identity Ident
 	}
 
 	identity = accountToIdentity(account)
-	idm.eventBus.Publish(AppTopicIdentityCreated, identity.Address)
+	PublishIdentityCreated(idm.eventBus, identity.Address)
 	return identity, nil
 }
 
@@ -139,7 +155,7 @@ func (idm *identityManager) Unlock(chainID int64, address string, passphrase str
 	idm.unlockedMu.Lock()
 	defer idm.unlockedMu.Unlock()
 
-	if idm.unlocked[address] {
+	if idm.unlocked[strings.ToLower(address)] {
 		log.Debug().Msg("Unlocked identity found in cache, skipping keystore: " + address)
 		return nil
 	}
@@ -154,10 +170,10 @@ func (idm *identityManager) Unlock(chainID int64, address string, passphrase str
 		return errors.Wrapf(err, "keystore failed to unlock identity: %s", address)
 	}
 	log.Debug().Msgf("Caching unlocked address: %s", address)
-	idm.unlocked[address] = true
+	idm.unlocked[strings.ToLower(address)] = true
 
 	go func() {
-		idm.eventBus.Publish(AppTopicIdentityUnlock, AppEventIdentityUnlock{
+		PublishIdentityUnlock(idm.eventBus, AppEventIdentityUnlock{
 			ChainID: chainID,
 			ID:      FromAddress(address),
 		})
@@ -167,6 +183,82 @@ func (idm *identityManager) Unlock(chainID int64, address string, passphrase str
 	return nil
 }
 
+// Use marks the identity as used by user until release is called, used identities can't be deleted.
+func (idm *identityManager) Use(address, user string) (func(), error) {
+	idm.usersMu.Lock()
+	defer idm.usersMu.Unlock()
+
+	if _, err := idm.findAccount(address); err != nil {
+		return nil, err
+	}
+
+	key := strings.ToLower(address)
+	usage := &user
+	idm.users[key] = append(idm.users[key], usage)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			idm.usersMu.Lock()
+			defer idm.usersMu.Unlock()
+
+			users := idm.users[key]
+			for i := range users {
+				if users[i] == usage {
+					users = append(users[:i:i], users[i+1:]...)
+					break
+				}
+			}
+			if len(users) == 0 {
+				delete(idm.users, key)
+			} else {
+				idm.users[key] = users
+			}
+		})
+	}, nil
+}
+
+// DeleteIdentity removes identity from the keystore, passphrase is required to confirm the deletion.
+// Identities used by services or connections are not deleted, ErrIdentityInUse is returned instead.
+func (idm *identityManager) DeleteIdentity(address string, passphrase string) error {
+	// Usage is checked under the same lock as the deletion, so the identity can't start being used in between.
+	idm.usersMu.Lock()
+	defer idm.usersMu.Unlock()
+
+	if users := idm.users[strings.ToLower(address)]; len(users) > 0 {
+		return errors.Wrapf(ErrIdentityInUse, "identity %s is used by %s", address, *users[0])
+	}
+
+	account, err := idm.findAccount(address)
+	if err != nil {
+		return err
+	}
+
+	if err := idm.keystoreManager.Delete(account, passphrase); err != nil {
+		return errors.Wrapf(err, "keystore failed to delete identity: %s", address)
+	}
+	if err := idm.keystoreManager.Lock(account.Address); err != nil {
+		log.Warn().Err(err).Msgf("Failed to lock deleted identity: %s", address)
+	}
+
+	idm.unlockedMu.Lock()
+	delete(idm.unlocked, strings.ToLower(address))
+	idm.unlockedMu.Unlock()
+
+	PublishIdentityDeleted(idm.eventBus, AppEventIdentityDeleted{ID: FromAddress(address)})
+	return nil
+}
+
+// ChangePassphrase encrypts identity key stored in the keystore with the new passphrase.
+func (idm *identityManager) ChangePassphrase(address string, passphrase, newPassphrase string) error {
+	account, err := idm.findAccount(address)
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrapf(idm.keystoreManager.Update(account, passphrase, newPassphrase), "keystore failed to change passphrase of identity: %s", address)
+}
+
 func (idm *identityManager) findAccount(address string) (accounts.Account, error) {
 	account, err := idm.keystoreManager.Find(addressToAccount(address))
 	if err != nil {
@@ -175,3 +267,33 @@ func (idm *identityManager) findAccount(address string) (accounts.Account, error
 
 	return account, err
 }
+
+// PublishIdentityCreated publishes created identity addresses on the bus.
+func PublishIdentityCreated(publisher eventbus.Publisher, e string) {
+	eventbus.Handle(AppTopicIdentityCreated).Publish(publisher, e)
+}
+
+// SubscribeIdentityCreated subscribes fn to created identity addresses.
+func SubscribeIdentityCreated(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(string)) error {
+	return eventbus.Handle(AppTopicIdentityCreated).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishIdentityDeleted publishes identity deletion events on the bus.
+func PublishIdentityDeleted(publisher eventbus.Publisher, e AppEventIdentityDeleted) {
+	eventbus.Handle(AppTopicIdentityDeleted).Publish(publisher, e)
+}
+
+// SubscribeIdentityDeleted subscribes fn to identity deletion events.
+func SubscribeIdentityDeleted(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventIdentityDeleted)) error {
+	return eventbus.Handle(AppTopicIdentityDeleted).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishIdentityUnlock publishes identity unlock events on the bus.
+func PublishIdentityUnlock(publisher eventbus.Publisher, e AppEventIdentityUnlock) {
+	eventbus.Handle(AppTopicIdentityUnlock).Publish(publisher, e)
+}
+
+// SubscribeIdentityUnlock subscribes fn to identity unlock events.
+func SubscribeIdentityUnlock(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventIdentityUnlock)) error {
+	return eventbus.Handle(AppTopicIdentityUnlock).Subscribe(subscriber, delivery, fn)
+}
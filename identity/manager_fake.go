@@ -27,12 +27,13 @@ type idmFake struct {
 	newIdentity          Identity
 	unlockFails          bool
 	isUnlocked           bool
+	used                 map[string]int
 }
 
 // NewIdentityManagerFake creates fake identity manager for testing purposes
 // TODO each caller should use it's own mocked manager part instead of global one
 func NewIdentityManagerFake(existingIdentities []Identity, newIdentity Identity) *idmFake {
-	return &idmFake{"", "", 0, existingIdentities, newIdentity, false, true}
+	return &idmFake{"", "", 0, existingIdentities, newIdentity, false, true, map[string]int{}}
 }
 
 func (fakeIdm *idmFake) IsUnlocked(id string) bool {
@@ -70,3 +71,35 @@ func (fakeIdm *idmFake) Unlock(chainID int64, address string, passphrase string)
 	}
 	return nil
 }
+
+func (fakeIdm *idmFake) DeleteIdentity(address string, _ string) error {
+	if fakeIdm.used[address] > 0 {
+		return ErrIdentityInUse
+	}
+	remaining := make([]Identity, 0, len(fakeIdm.existingIdentities))
+	for _, fakeIdentity := range fakeIdm.existingIdentities {
+		if address != fakeIdentity.Address {
+			remaining = append(remaining, fakeIdentity)
+		}
+	}
+	if len(remaining) == len(fakeIdm.existingIdentities) {
+		return errors.New("Identity not found")
+	}
+	fakeIdm.existingIdentities = remaining
+	return nil
+}
+
+func (fakeIdm *idmFake) Use(address, _ string) (func(), error) {
+	if _, err := fakeIdm.GetIdentity(address); err != nil {
+		return nil, err
+	}
+	fakeIdm.used[address]++
+	return func() {
+		fakeIdm.used[address]--
+	}, nil
+}
+
+func (fakeIdm *idmFake) ChangePassphrase(address string, _, _ string) error {
+	_, err := fakeIdm.GetIdentity(address)
+	return err
+}
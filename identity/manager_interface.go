@@ -26,4 +26,13 @@ type Manager interface {
 	HasIdentity(address string) bool
 	Unlock(chainID int64, address string, passphrase string) error
 	IsUnlocked(address string) bool
+	DeleteIdentity(address string, passphrase string) error
+	ChangePassphrase(address string, passphrase, newPassphrase string) error
+	UsageTracker
+}
+
+// UsageTracker marks identities used by services and connections, used identities can't be deleted.
+type UsageTracker interface {
+	// Use marks the identity as used by user until release is called.
+	Use(address, user string) (release func(), err error)
 }
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/mysteriumnetwork/node/eventbus"
@@ -32,6 +33,7 @@ func Test_IdentityManager(t *testing.T) {
 		keystoreManager: ks,
 		eventBus:        eventbus.New(),
 		unlocked:        map[string]bool{},
+		users:           map[string][]*string{},
 	}
 
 	t.Run("gets existing identities", func(t *testing.T) {
@@ -68,4 +70,33 @@ func Test_IdentityManager(t *testing.T) {
 		assert.True(t, idm.HasIdentity(newID.Address))
 		assert.False(t, idm.HasIdentity("0x000000000000000000000000000000000000000B"))
 	})
+
+	t.Run("changes passphrase", func(t *testing.T) {
+		assert.Error(t, idm.ChangePassphrase(newID.Address, "wrong", "new"))
+		assert.NoError(t, idm.ChangePassphrase(newID.Address, "", "new"))
+		assert.NoError(t, ks.Unlock(identityToAccount(newID), "new"))
+	})
+
+	t.Run("refuses to delete used identity", func(t *testing.T) {
+		release, err := idm.Use(newID.Address, "wireguard service")
+		assert.NoError(t, err)
+
+		err = idm.DeleteIdentity(newID.Address, "new")
+		assert.Equal(t, ErrIdentityInUse, errors.Cause(err))
+		assert.True(t, idm.HasIdentity(newID.Address))
+
+		release()
+		release()
+		assert.Empty(t, idm.users)
+	})
+
+	t.Run("deletes identity", func(t *testing.T) {
+		assert.Error(t, idm.DeleteIdentity(newID.Address, ""))
+		assert.NoError(t, idm.DeleteIdentity(newID.Address, "new"))
+		assert.False(t, idm.HasIdentity(newID.Address))
+		assert.False(t, idm.IsUnlocked(newID.Address))
+
+		_, err := idm.Use(newID.Address, "connection")
+		assert.Error(t, err)
+	})
 }
@@ -80,7 +80,7 @@ func (i *Importer) Import(blob []byte, currPass, newPass string) (Identity, erro
 	}
 
 	identity := accountToIdentity(acc)
-	i.eventBus.Publish(AppTopicIdentityCreated, identity.Address)
+	PublishIdentityCreated(i.eventBus, identity.Address)
 	return identity, nil
 }
 
@@ -68,7 +68,11 @@ type ProviderRegistrar struct {
 	once                      sync.Once
 	stopChan                  chan struct{}
 	queue                     chan queuedEvent
-	registeredIdentities      map[string]struct{}
+	handlers                  sync.WaitGroup
+
+	mu                   sync.Mutex
+	registeredIdentities map[string]struct{}
+	inProgress           map[string]struct{} // Identities being registered at the moment
 
 	cfg ProviderRegistrarConfig
 }
@@ -101,6 +105,7 @@ func NewProviderRegistrar(
 		registrationStatusChecker: registrationStatusChecker,
 		queue:                     make(chan queuedEvent),
 		registeredIdentities:      make(map[string]struct{}),
+		inProgress:                make(map[string]struct{}),
 		cfg:                       prc,
 		txer:                      transactor,
 		mysteriumAPI:              mysteriumAPI,
@@ -112,18 +117,19 @@ func NewProviderRegistrar(
 
 // Subscribe subscribes the provider registrar to service state change events
 func (pr *ProviderRegistrar) Subscribe(eb eventbus.EventBus) error {
-	err := eb.SubscribeAsync(event.AppTopicNode, pr.handleNodeStartupEvents)
+	err := event.SubscribeNode(eb, eventbus.DeliverAsync, pr.handleNodeStartupEvents)
 	if err != nil {
 		return errors.Wrap(err, "could not subscribe to node events")
 	}
-	return eb.SubscribeAsync(servicestate.AppTopicServiceStatus, pr.consumeServiceEvent)
+	// Service events wait for the registrar to pick them up, so they must not block other bus subscribers.
+	return servicestate.SubscribeServiceStatus(eb, eventbus.DeliverConcurrent, pr.consumeServiceEvent)
 }
 
 func (pr *ProviderRegistrar) handleNodeStartupEvents(e event.Payload) {
 	if e.Status == event.StatusStarted {
 		err := pr.start()
 		if err != nil {
-			log.Error().Err(err).Stack().Msgf("Fatal error for provider identity registrar. Identity will not be registered. Please restart your node.")
+			log.Error().Err(err).Stack().Msgf("Fatal error for provider identity registrar. Identities will not be registered. Please restart your node.")
 		}
 		return
 	}
@@ -134,9 +140,9 @@ func (pr *ProviderRegistrar) handleNodeStartupEvents(e event.Payload) {
 }
 
 func (pr *ProviderRegistrar) consumeServiceEvent(event servicestate.AppEventServiceStatus) {
-	pr.queue <- queuedEvent{
-		event:   event,
-		retries: 0,
+	select {
+	case <-pr.stopChan:
+	case pr.queue <- queuedEvent{event: event, retries: 0}:
 	}
 }
 
@@ -146,17 +152,42 @@ func (pr *ProviderRegistrar) needsHandling(qe queuedEvent) bool {
 		return false
 	}
 
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
 	if _, ok := pr.registeredIdentities[qe.event.ProviderID]; ok {
 		log.Info().Msgf("Provider %q already marked as registered, skipping", qe.event.ProviderID)
 		return false
 	}
 
+	// Requeued event keeps ownership of the registration in progress.
+	if _, ok := pr.inProgress[qe.event.ProviderID]; ok && qe.retries == 0 {
+		log.Info().Msgf("Provider %q registration already in progress, skipping", qe.event.ProviderID)
+		return false
+	}
+
+	pr.inProgress[qe.event.ProviderID] = struct{}{}
 	return true
 }
 
+func (pr *ProviderRegistrar) markRegistered(providerID string) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	pr.registeredIdentities[providerID] = struct{}{}
+}
+
+func (pr *ProviderRegistrar) markDone(providerID string) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	delete(pr.inProgress, providerID)
+}
+
 func (pr *ProviderRegistrar) handleEventWithRetries(qe queuedEvent) error {
 	err := pr.handleEvent(qe)
 	if err == nil {
+		pr.markDone(qe.event.ProviderID)
 		return nil
 	}
 	if qe.retries < pr.cfg.MaxRetries {
@@ -166,6 +197,7 @@ func (pr *ProviderRegistrar) handleEventWithRetries(qe queuedEvent) error {
 		return nil
 	}
 
+	pr.markDone(qe.event.ProviderID)
 	return errors.Wrap(err, "max attempts reached for provider registration")
 }
 
@@ -174,7 +206,11 @@ func (pr *ProviderRegistrar) delayedRequeue(qe queuedEvent) {
 	case <-pr.stopChan:
 		return
 	case <-time.After(pr.cfg.DelayBetweenRetries):
-		pr.queue <- qe
+	}
+
+	select {
+	case <-pr.stopChan:
+	case pr.queue <- qe:
 	}
 }
 
@@ -191,7 +227,7 @@ func (pr *ProviderRegistrar) handleEvent(qe queuedEvent) error {
 	switch registered {
 	case Registered:
 		log.Info().Msgf("Provider %q already registered on bc, skipping", qe.event.ProviderID)
-		pr.registeredIdentities[qe.event.ProviderID] = struct{}{}
+		pr.markRegistered(qe.event.ProviderID)
 		return nil
 	default:
 		log.Info().Msgf("Provider %q not registered on BC, will check if elgible for auto-registration", qe.event.ProviderID)
@@ -246,7 +282,7 @@ func (pr *ProviderRegistrar) registerIdentity(qe queuedEvent, id identity.Identi
 		return errors.Wrap(err, "could not register identity on BC")
 	}
 
-	pr.registeredIdentities[qe.event.ProviderID] = struct{}{}
+	pr.markRegistered(qe.event.ProviderID)
 	log.Info().Msgf("Registration success for provider %q", id.Address)
 	return nil
 }
@@ -298,9 +334,11 @@ func isZeroAddress(in common.Address) bool {
 	return bytes.EqualFold(in.Bytes(), zeroAddress.Bytes())
 }
 
-// start starts the provider registrar
+// start starts the provider registrar, identities are registered concurrently.
 func (pr *ProviderRegistrar) start() error {
 	log.Info().Msg("Starting provider registrar")
+	defer pr.handlers.Wait()
+
 	for {
 		select {
 		case <-pr.stopChan:
@@ -310,10 +348,13 @@ func (pr *ProviderRegistrar) start() error {
 				break
 			}
 
-			err := pr.handleEventWithRetries(event)
-			if err != nil {
-				return err
-			}
+			pr.handlers.Add(1)
+			go func(qe queuedEvent) {
+				defer pr.handlers.Done()
+				if err := pr.handleEventWithRetries(qe); err != nil {
+					log.Error().Err(err).Msgf("Provider %q will not be registered automatically", qe.event.ProviderID)
+				}
+			}(event)
 		}
 	}
 }
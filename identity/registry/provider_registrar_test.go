@@ -20,6 +20,7 @@ package registry
 import (
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
@@ -50,6 +51,23 @@ func Test_ProviderRegistrar_StartsAndStops(t *testing.T) {
 	<-done
 }
 
+func Test_ProviderRegistrar_DropsServiceEventsAfterStop(t *testing.T) {
+	registrar := NewProviderRegistrar(&mockTransactor{}, &mockRegistrationStatusProvider{}, &mockAPI{}, fakeSignerFactory, &mockAddressKeeper{}, &mockBlockchain{}, ProviderRegistrarConfig{})
+	registrar.stop()
+
+	consumed := make(chan struct{})
+	go func() {
+		registrar.consumeServiceEvent(servicestate.AppEventServiceStatus{ProviderID: "0x1", Status: string(servicestate.Running)})
+		close(consumed)
+	}()
+
+	select {
+	case <-consumed:
+	case <-time.After(time.Second):
+		t.Fatal("service event should not block after registrar stops")
+	}
+}
+
 func Test_Provider_Registrar_needsHandling(t *testing.T) {
 	mt := mockTransactor{}
 	mrsp := mockRegistrationStatusProvider{}
@@ -175,7 +193,7 @@ func Test_Provider_Registrar_Does_NotRegisterWithNoBounty_Testnet2(t *testing.T)
 	assert.True(t, ok)
 }
 
-func Test_Provider_Registrar_FailsAfterRetries(t *testing.T) {
+func Test_Provider_Registrar_KeepsRunningAfterFailedRetries(t *testing.T) {
 	mt := mockTransactor{}
 	mrsp := mockRegistrationStatusProvider{
 		err: errors.New("explosions everywhere"),
@@ -196,12 +214,37 @@ func Test_Provider_Registrar_FailsAfterRetries(t *testing.T) {
 
 	go func() {
 		err := registrar.start()
-		assert.NotNil(t, err)
+		assert.Nil(t, err)
 		done <- struct{}{}
 	}()
 
-	registrar.consumeServiceEvent(mockEvent.event)
+	registrar.queue <- mockEvent
+	// Registrar still accepts events of other identities.
+	registrar.consumeServiceEvent(servicestate.AppEventServiceStatus{Status: "Running", ProviderID: "0xother"})
+
+	registrar.stop()
 	<-done
+
+	_, ok := registrar.registeredIdentities[mockEvent.event.ProviderID]
+	assert.False(t, ok)
+}
+
+func Test_Provider_Registrar_needsHandling_SkipsInProgress(t *testing.T) {
+	registrar := NewProviderRegistrar(&mockTransactor{}, &mockRegistrationStatusProvider{}, &mockAPI{}, fakeSignerFactory, &mockAddressKeeper{}, &mockBlockchain{}, ProviderRegistrarConfig{})
+
+	mockEvent := queuedEvent{
+		event: servicestate.AppEventServiceStatus{Status: "Running", ProviderID: "0x000"},
+	}
+	assert.True(t, registrar.needsHandling(mockEvent))
+	assert.False(t, registrar.needsHandling(mockEvent))
+
+	otherEvent := queuedEvent{
+		event: servicestate.AppEventServiceStatus{Status: "Running", ProviderID: "0x001"},
+	}
+	assert.True(t, registrar.needsHandling(otherEvent))
+
+	mockEvent.retries = 1
+	assert.True(t, registrar.needsHandling(mockEvent))
 }
 
 type mockRegistrationStatusProvider struct {
@@ -69,15 +69,15 @@ func NewIdentityRegistryContract(ethClient *paymentClient.ReconnectableEthClient
 
 // Subscribe subscribes the contract registry to relevant events
 func (registry *contractRegistry) Subscribe(eb eventbus.Subscriber) error {
-	err := eb.SubscribeAsync(event.AppTopicNode, registry.handleNodeEvent)
+	err := event.SubscribeNode(eb, eventbus.DeliverAsync, registry.handleNodeEvent)
 	if err != nil {
 		return err
 	}
-	err = eb.SubscribeAsync(AppTopicEthereumClientReconnected, registry.handleEtherClientReconnect)
+	err = SubscribeEthereumClientReconnected(eb, eventbus.DeliverAsync, registry.handleEtherClientReconnect)
 	if err != nil {
 		return err
 	}
-	return eb.Subscribe(AppTopicTransactorRegistration, registry.handleRegistrationEvent)
+	return SubscribeTransactorRegistration(eb, eventbus.DeliverSync, registry.handleRegistrationEvent)
 }
 
 // GetRegistrationStatus returns the registration status of the provided identity
@@ -131,7 +131,7 @@ func (registry *contractRegistry) GetRegistrationStatus(chainID int64, id identi
 	// If current status was not registered and we are now registered
 	// publish an event for that to make sure that wasn't missed.
 	if currentStatus != newStatus && newStatus == Registered {
-		go registry.publisher.Publish(AppTopicIdentityRegistration, AppEventIdentityRegistration{
+		go PublishIdentityRegistration(registry.publisher, AppEventIdentityRegistration{
 			ID:      id,
 			Status:  newStatus,
 			ChainID: chainID,
@@ -190,7 +190,7 @@ func (registry *contractRegistry) handleRegistrationEvent(ev IdentityRegistratio
 
 	ID := identity.FromAddress(ev.Identity)
 
-	go registry.publisher.Publish(AppTopicIdentityRegistration, AppEventIdentityRegistration{
+	go PublishIdentityRegistration(registry.publisher, AppEventIdentityRegistration{
 		ID:      ID,
 		Status:  s,
 		ChainID: ev.ChainID,
@@ -240,7 +240,7 @@ func (registry *contractRegistry) subscribeToRegistrationEvent(chainID int64, id
 
 		subscription, err := filterer.WatchRegisteredIdentity(filterOps, sink, userIdentities)
 		if err != nil {
-			registry.publisher.Publish(AppTopicIdentityRegistration, AppEventIdentityRegistration{
+			PublishIdentityRegistration(registry.publisher, AppEventIdentityRegistration{
 				ID:      identity,
 				Status:  RegistrationError,
 				ChainID: chainID,
@@ -272,7 +272,7 @@ func (registry *contractRegistry) subscribeToRegistrationEvent(chainID int64, id
 			status := Registered
 
 			log.Debug().Msgf("Sending registration success event for %v", identity)
-			registry.publisher.Publish(AppTopicIdentityRegistration, AppEventIdentityRegistration{
+			PublishIdentityRegistration(registry.publisher, AppEventIdentityRegistration{
 				ID:      identity,
 				Status:  status,
 				ChainID: chainID,
@@ -292,7 +292,7 @@ func (registry *contractRegistry) subscribeToRegistrationEvent(chainID int64, id
 			}
 
 			log.Error().Err(err).Msg("Subscription error")
-			registry.publisher.Publish(AppTopicIdentityRegistration, AppEventIdentityRegistration{
+			PublishIdentityRegistration(registry.publisher, AppEventIdentityRegistration{
 				ID:      identity,
 				Status:  RegistrationError,
 				ChainID: chainID,
@@ -439,9 +439,19 @@ func (registry *contractRegistry) bcRegistrationStatus(chainID int64, id identit
 }
 
 // AppTopicEthereumClientReconnected indicates that the ethereum client has reconnected.
-var AppTopicEthereumClientReconnected = "ether-client-reconnect"
+const AppTopicEthereumClientReconnected = "ether-client-reconnect"
 
-func (registry *contractRegistry) handleEtherClientReconnect(_ interface{}) {
+// PublishEthereumClientReconnected notifies subscribers that the ethereum client has reconnected.
+func PublishEthereumClientReconnected(publisher eventbus.Publisher) {
+	eventbus.Handle(AppTopicEthereumClientReconnected).Publish(publisher, struct{}{})
+}
+
+// SubscribeEthereumClientReconnected subscribes fn to ethereum client reconnects.
+func SubscribeEthereumClientReconnected(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func()) error {
+	return eventbus.Handle(AppTopicEthereumClientReconnected).Subscribe(subscriber, delivery, fn)
+}
+
+func (registry *contractRegistry) handleEtherClientReconnect() {
 	err := registry.loadInitialState()
 	if err != nil {
 		log.Error().Err(err).Msg("could not resubscribe to identity status changes")
@@ -20,6 +20,7 @@ package registry
 import (
 	"time"
 
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 )
 
@@ -86,3 +87,13 @@ type AppEventIdentityRegistration struct {
 	Status  RegistrationStatus
 	ChainID int64
 }
+
+// PublishIdentityRegistration publishes identity registration status changes on the bus.
+func PublishIdentityRegistration(publisher eventbus.Publisher, e AppEventIdentityRegistration) {
+	eventbus.Handle(AppTopicIdentityRegistration).Publish(publisher, e)
+}
+
+// SubscribeIdentityRegistration subscribes fn to identity registration status changes.
+func SubscribeIdentityRegistration(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventIdentityRegistration)) error {
+	return eventbus.Handle(AppTopicIdentityRegistration).Subscribe(subscriber, delivery, fn)
+}
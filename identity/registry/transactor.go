@@ -197,7 +197,7 @@ func (t *Transactor) registerIdentity(id string, stake, fee *big.Int, beneficiar
 
 	// This is left as a synchronous call on purpose.
 	// We need to notify registry before returning.
-	t.publisher.Publish(AppTopicTransactorRegistration, regReq)
+	PublishTransactorRegistration(t.publisher, regReq)
 
 	return nil
 }
@@ -235,7 +235,7 @@ func (t *Transactor) registerIdentityWithReferralToken(id string, stake *big.Int
 
 	// This is left as a synchronous call on purpose.
 	// We need to notify registry before returning.
-	t.publisher.Publish(AppTopicTransactorRegistration, regReq)
+	PublishTransactorRegistration(t.publisher, regReq)
 
 	return nil
 }
@@ -659,3 +659,13 @@ func (t *Transactor) fillDecreaseStakeRequest(id string, chainID int64, amount,
 	}
 	return regReq, nil
 }
+
+// PublishTransactorRegistration publishes registration requests sent to transactor on the bus.
+func PublishTransactorRegistration(publisher eventbus.Publisher, e IdentityRegistrationRequest) {
+	eventbus.Handle(AppTopicTransactorRegistration).Publish(publisher, e)
+}
+
+// SubscribeTransactorRegistration subscribes fn to registration requests sent to transactor.
+func SubscribeTransactorRegistration(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(IdentityRegistrationRequest)) error {
+	return eventbus.Handle(AppTopicTransactorRegistration).Subscribe(subscriber, delivery, fn)
+}
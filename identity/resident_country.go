@@ -73,5 +73,15 @@ func (rc *ResidentCountry) publishResidentCountry(identity string) {
 		ID:      identity,
 		Country: country,
 	}
-	rc.eventBus.Publish(AppTopicResidentCountry, event)
+	PublishResidentCountry(rc.eventBus, event)
+}
+
+// PublishResidentCountry publishes resident country changes on the bus.
+func PublishResidentCountry(publisher eventbus.Publisher, e ResidentCountryEvent) {
+	eventbus.Handle(AppTopicResidentCountry).Publish(publisher, e)
+}
+
+// SubscribeResidentCountry subscribes fn to resident country changes.
+func SubscribeResidentCountry(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(ResidentCountryEvent)) error {
+	return eventbus.Handle(AppTopicResidentCountry).Subscribe(subscriber, delivery, fn)
 }
@@ -42,7 +42,7 @@ func TestResidentEvent(t *testing.T) {
 	residentCountry := NewResidentCountry(bus, newMockLocationResolver("UK"))
 
 	atomic := &AtomicResidentCountryEvent{}
-	err = bus.Subscribe(AppTopicResidentCountry, func(e ResidentCountryEvent) {
+	err = SubscribeResidentCountry(bus, eventbus.DeliverSync, func(e ResidentCountryEvent) {
 		atomic.Store(e)
 	})
 	assert.NoError(t, err)
@@ -0,0 +1,224 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// externalSignerTimeout is generous, as external signers may ask the operator to confirm each request.
+const externalSignerTimeout = 2 * time.Minute
+
+// ExternalSigner is a client of an external process holding identity keys.
+// It speaks JSON-RPC over a local socket:
+//
+//	account_list() -> [address]
+//	account_signHash(address, hash) -> signature in [R || S || V] format
+//
+// Payment promises are signed over raw hashes, so signers which only sign typed data or
+// prefixed messages (e.g. Clef) can't be used. Signatures must be deterministic (RFC 6979),
+// as the encryption key of the identity is derived from one.
+type ExternalSigner struct {
+	endpoint  string
+	addresses map[common.Address]struct{}
+
+	mu     sync.Mutex
+	client *rpc.Client
+}
+
+// NewExternalSigner creates external signer client for the given identities.
+// Connection to the signer is established lazily, so the signer process may be started after the node.
+func NewExternalSigner(endpoint string, identities []string) *ExternalSigner {
+	addresses := make(map[common.Address]struct{}, len(identities))
+	for _, id := range identities {
+		addresses[common.HexToAddress(strings.TrimSpace(id))] = struct{}{}
+	}
+	return &ExternalSigner{
+		endpoint:  endpoint,
+		addresses: addresses,
+	}
+}
+
+// Handles returns true if the given address is signed by the external signer.
+func (es *ExternalSigner) Handles(address common.Address) bool {
+	if es == nil {
+		return false
+	}
+	_, ok := es.addresses[address]
+	return ok
+}
+
+// Addresses returns addresses signed by the external signer.
+func (es *ExternalSigner) Addresses() []common.Address {
+	if es == nil {
+		return nil
+	}
+	result := make([]common.Address, 0, len(es.addresses))
+	for address := range es.addresses {
+		result = append(result, address)
+	}
+	return result
+}
+
+// Accounts returns addresses available in the external signer.
+func (es *ExternalSigner) Accounts() ([]common.Address, error) {
+	var result []common.Address
+	if err := es.call(&result, "account_list"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Verify checks that the external signer is reachable and holds the key of the given identity.
+func (es *ExternalSigner) Verify(address common.Address) error {
+	if !es.Handles(address) {
+		return fmt.Errorf("identity %s is not configured for external signing", address.Hex())
+	}
+	available, err := es.Accounts()
+	if err != nil {
+		return err
+	}
+	if missing := missingAddresses([]common.Address{address}, available); len(missing) > 0 {
+		return fmt.Errorf("external signer does not hold identity %s", address.Hex())
+	}
+	return nil
+}
+
+// SignHash asks the external signer to sign the given hash.
+func (es *ExternalSigner) SignHash(address common.Address, hash []byte) ([]byte, error) {
+	if !es.Handles(address) {
+		return nil, fmt.Errorf("identity %s is not configured for external signing", address.Hex())
+	}
+
+	var signature hexutil.Bytes
+	if err := es.call(&signature, "account_signHash", address, hexutil.Bytes(hash)); err != nil {
+		return nil, err
+	}
+	if len(signature) != 65 {
+		return nil, fmt.Errorf("external signer returned signature of invalid length %d", len(signature))
+	}
+	// Normalize Ethereum style V (27/28) returned by some signers.
+	if signature[64] >= 27 {
+		signature[64] -= 27
+	}
+	return signature, nil
+}
+
+func (es *ExternalSigner) call(result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), externalSignerTimeout)
+	defer cancel()
+
+	client, err := es.getClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := client.CallContext(ctx, result, method, args...); err != nil {
+		if _, ok := err.(rpc.Error); !ok {
+			// Transport level error, reconnect on the next call.
+			es.resetClient(client)
+		}
+		return fmt.Errorf("external signer call %s failed: %w", method, err)
+	}
+	return nil
+}
+
+func (es *ExternalSigner) getClient(ctx context.Context) (*rpc.Client, error) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if es.client != nil {
+		return es.client, nil
+	}
+
+	client, err := rpc.DialIPC(ctx, es.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to external signer at %s: %w", es.endpoint, err)
+	}
+
+	// Configured identities are checked once per connection, so misconfiguration fails early instead of on every signature.
+	var available []common.Address
+	if err := client.CallContext(ctx, &available, "account_list"); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("could not list external signer accounts: %w", err)
+	}
+	if missing := missingAddresses(es.Addresses(), available); len(missing) > 0 {
+		client.Close()
+		return nil, fmt.Errorf("external signer at %s does not hold identities %v", es.endpoint, missing)
+	}
+
+	es.client = client
+	return client, nil
+}
+
+func missingAddresses(wanted, available []common.Address) (missing []string) {
+	held := make(map[common.Address]struct{}, len(available))
+	for _, address := range available {
+		held[address] = struct{}{}
+	}
+	for _, address := range wanted {
+		if _, ok := held[address]; !ok {
+			missing = append(missing, address.Hex())
+		}
+	}
+	return missing
+}
+
+func (es *ExternalSigner) resetClient(client *rpc.Client) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if es.client == client {
+		es.client.Close()
+		es.client = nil
+	}
+}
+
+type externalSigner struct {
+	signer  *ExternalSigner
+	address common.Address
+}
+
+// Sign signs given message with the external signer and returns signature
+func (s *externalSigner) Sign(message []byte) (Signature, error) {
+	signature, err := s.signer.SignHash(s.address, messageHash(message))
+	if err != nil {
+		return Signature{}, err
+	}
+
+	return SignatureBytes(signature), nil
+}
+
+// NewSignerFactory returns SignerFactory which signs with the external signer for the identities it handles,
+// and with the keystore for the rest.
+func NewSignerFactory(keystore keystore, external *ExternalSigner) SignerFactory {
+	return func(id Identity) Signer {
+		if address := id.ToCommonAddress(); external.Handles(address) {
+			return &externalSigner{signer: external, address: address}
+		}
+		return NewSigner(keystore, id)
+	}
+}
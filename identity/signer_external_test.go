@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type externalSignerService struct {
+	key    *ecdsa.PrivateKey
+	random bool
+}
+
+func (s *externalSignerService) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(s.key.PublicKey)}
+}
+
+func (s *externalSignerService) SignHash(_ common.Address, hash hexutil.Bytes) (hexutil.Bytes, error) {
+	signature, err := crypto.Sign(hash, s.key)
+	if err != nil {
+		return nil, err
+	}
+	if s.random {
+		// Signers with random nonces produce a different signature every time.
+		if _, err := rand.Read(signature[:32]); err != nil {
+			return nil, err
+		}
+	}
+	// Some signers return Ethereum style V.
+	signature[64] += 27
+	return signature, nil
+}
+
+func startExternalSigner(t *testing.T) (string, func()) {
+	return startExternalSignerService(t, &externalSignerService{key: signerKey})
+}
+
+func startExternalSignerService(t *testing.T, service *externalSignerService) (string, func()) {
+	dir, err := ioutil.TempDir("", "external-signer")
+	require.NoError(t, err)
+	endpoint := filepath.Join(dir, "signer.ipc")
+
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("account", service))
+	listener, err := net.Listen("unix", endpoint)
+	require.NoError(t, err)
+	go server.ServeListener(listener)
+
+	return endpoint, func() {
+		listener.Close()
+		server.Stop()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestExternalSigner_SignsLikeKeystore(t *testing.T) {
+	endpoint, stop := startExternalSigner(t)
+	defer stop()
+
+	external := NewExternalSigner(endpoint, []string{"0x" + signerAddress})
+	addresses, err := external.Accounts()
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{signerAccount.Address}, addresses)
+
+	factory := NewSignerFactory(nil, external)
+	message := []byte("MystVpnSessionId:Boop!")
+	signature, err := factory(FromAddress(signerAddress)).Sign(message)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		SignatureBase64("V6ifmvLuAT+hbtLBX/0xm3C0afywxTIdw1HqLmA4onpwmibHbxVhl50Gr3aRUZMqw1WxkfSIVdhpbCluHGBKsgE="),
+		signature,
+	)
+}
+
+func TestKeystore_ExternalSignerIdentity(t *testing.T) {
+	endpoint, stop := startExternalSigner(t)
+	defer stop()
+
+	otherAccount := accounts.Account{Address: common.HexToAddress("0x000000000000000000000000000000000000000a")}
+	ks := NewKeystoreFilesystem("dir", &ethKeystoreMock{account: otherAccount})
+	ks.UseExternalSigner(NewExternalSigner(endpoint, []string{signerAddress}))
+
+	assert.Equal(t, []accounts.Account{otherAccount, {Address: signerAccount.Address}}, ks.Accounts())
+	assert.NoError(t, ks.Unlock(signerAccount, ""))
+	assert.Equal(t, errExternalKey, ks.Delete(signerAccount, ""))
+
+	encrypted, err := ks.Encrypt(signerAccount.Address, []byte("R recovery"))
+	assert.NoError(t, err)
+	decrypted, err := ks.Decrypt(signerAccount.Address, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("R recovery"), decrypted)
+
+	_, err = ks.SignHash(otherAccount, messageHash([]byte("locked")))
+	assert.Error(t, err)
+}
+
+func TestKeystore_ExternalSignerMissingIdentity(t *testing.T) {
+	endpoint, stop := startExternalSigner(t)
+	defer stop()
+
+	missing := accounts.Account{Address: common.HexToAddress("0x000000000000000000000000000000000000000b")}
+	ks := NewKeystoreFilesystem("dir", &ethKeystoreMock{})
+	ks.UseExternalSigner(NewExternalSigner(endpoint, []string{signerAddress, missing.Address.Hex()}))
+
+	assert.Error(t, ks.Unlock(missing, ""))
+	_, err := ks.SignHash(signerAccount, messageHash([]byte("message")))
+	assert.Error(t, err)
+}
+
+func TestKeystore_ExternalSignerRefusesNonDeterministicSignatures(t *testing.T) {
+	endpoint, stop := startExternalSignerService(t, &externalSignerService{key: signerKey, random: true})
+	defer stop()
+
+	ks := NewKeystoreFilesystem("dir", &ethKeystoreMock{})
+	ks.UseExternalSigner(NewExternalSigner(endpoint, []string{signerAddress}))
+
+	_, err := ks.Encrypt(signerAccount.Address, []byte("R recovery"))
+	assert.Equal(t, errNonDeterministicSigner, err)
+}
@@ -19,21 +19,35 @@ package logconfig
 
 import (
 	"io/ioutil"
+	"os"
 	"path"
 	"strings"
 
 	"github.com/mholt/archiver"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 )
 
 // Collector collects node logs.
 type Collector struct {
-	options *LogOptions
+	options    *LogOptions
+	extraFiles []string
+	dumpers    []Dumper
 }
 
-// NewCollector creates a Collector instance.
-func NewCollector(options *LogOptions) *Collector {
-	return &Collector{options: options}
+// Dumper writes in-memory state to a file to be added to the archive.
+type Dumper interface {
+	Dump() (filepath string, err error)
+}
+
+// NewCollector creates a Collector instance, extra files are added to the archive when they exist.
+func NewCollector(options *LogOptions, extraFiles ...string) *Collector {
+	return &Collector{options: options, extraFiles: extraFiles}
+}
+
+// AddDumper adds a file written by the dumper when the archive is created.
+func (c *Collector) AddDumper(dumper Dumper) {
+	c.dumpers = append(c.dumpers, dumper)
 }
 
 // Archive creates ZIP archive containing all node log files.
@@ -45,6 +59,14 @@ func (c *Collector) Archive() (outputFilepath string, err error) {
 	if err != nil {
 		return "", err
 	}
+	for _, dumper := range c.dumpers {
+		dump, err := dumper.Dump()
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to add dump to log archive")
+			continue
+		}
+		filepaths = append(filepaths, dump)
+	}
 
 	zip := archiver.NewZip()
 	zip.OverwriteExisting = true
@@ -70,5 +92,10 @@ func (c *Collector) logFilepaths() (result []string, err error) {
 			result = append(result, path.Join(dir, f.Name()))
 		}
 	}
+	for _, extra := range c.extraFiles {
+		if _, err := os.Stat(extra); err == nil {
+			result = append(result, extra)
+		}
+	}
 	return result, nil
 }
@@ -18,6 +18,7 @@
 package logconfig
 
 import (
+	"archive/zip"
 	"io/ioutil"
 	"os"
 	"path"
@@ -53,6 +54,33 @@ func TestCollector_List_ListsAllLogFilesMatchingPattern(t *testing.T) {
 	assert.Contains(logFiles, fn2)
 }
 
+func TestCollector_List_IncludesExistingExtraFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	// given
+	baseName := "mysterium-test.log"
+	fn1 := NewTempFileName(t, baseName)
+	defer os.Remove(fn1)
+
+	extra := NewTempFileName(t, "audit.log")
+	defer os.Remove(extra)
+
+	opts := LogOptions{
+		LogLevel: zerolog.DebugLevel,
+		Filepath: path.Join(path.Dir(fn1), baseName),
+	}
+	collector := NewCollector(&opts, extra, extra+".missing")
+
+	// when
+	logFiles, err := collector.logFilepaths()
+
+	// then
+	assert.NoError(err)
+	assert.Contains(logFiles, fn1)
+	assert.Contains(logFiles, extra)
+	assert.NotContains(logFiles, extra+".missing")
+}
+
 func TestCollector_Archive(t *testing.T) {
 	assert := assert.New(t)
 
@@ -79,6 +107,53 @@ func TestCollector_Archive(t *testing.T) {
 	assert.NotEmpty(zipFilename)
 }
 
+func TestCollector_Archive_IncludesDumps(t *testing.T) {
+	assert := assert.New(t)
+
+	// given
+	baseName := "mysterium-test.log"
+	fn1 := NewTempFileName(t, baseName)
+	defer os.Remove(fn1)
+
+	dump := NewTempFileName(t, "events.log")
+	defer os.Remove(dump)
+
+	opts := LogOptions{
+		LogLevel: zerolog.DebugLevel,
+		Filepath: path.Join(path.Dir(fn1), baseName),
+	}
+	collector := NewCollector(&opts)
+	dumper := &mockDumper{filepath: dump}
+	collector.AddDumper(dumper)
+
+	// when
+	zipFilename, err := collector.Archive()
+	defer os.Remove(zipFilename)
+
+	// then
+	assert.NoError(err)
+	assert.True(dumper.called)
+
+	archive, err := zip.OpenReader(zipFilename)
+	assert.NoError(err)
+	defer archive.Close()
+	var archived []string
+	for _, f := range archive.File {
+		archived = append(archived, path.Base(f.Name))
+	}
+	assert.Contains(archived, path.Base(dump))
+}
+
+type mockDumper struct {
+	filepath string
+	called   bool
+}
+
+func (m *mockDumper) Dump() (string, error) {
+	m.called = true
+	return m.filepath, nil
+}
+
 func NewTempFileName(t *testing.T, pattern string) string {
 	file, err := ioutil.TempFile("", pattern)
 	assert.NoError(t, err)
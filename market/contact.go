@@ -40,6 +40,23 @@ type Contact struct {
 type ContactDefinition interface {
 }
 
+// ObfuscatedContact is a contact definition which advertises traffic obfuscation methods
+type ObfuscatedContact interface {
+	ObfuscationMethods() []string
+}
+
+// ObfuscationMethods returns obfuscation methods advertised by the first contact which has any
+func (list ContactList) ObfuscationMethods() []string {
+	for _, c := range list {
+		if def, ok := c.Definition.(ObfuscatedContact); ok {
+			if methods := def.ObfuscationMethods(); len(methods) > 0 {
+				return methods
+			}
+		}
+	}
+	return nil
+}
+
 // UnsupportedContactType is a contact which is returned by unserializer when encountering unregistered types of contact
 type UnsupportedContactType struct {
 }
@@ -19,6 +19,7 @@ package market
 
 import (
 	"encoding/json"
+	"math/big"
 	"time"
 
 	"github.com/mysteriumnetwork/node/money"
@@ -33,6 +34,12 @@ type PaymentMethod interface {
 	GetRate() PaymentRate
 }
 
+// PortForwardingPricer is implemented by payment methods which charge forwarded ports separately.
+type PortForwardingPricer interface {
+	// GetPortForwardingPrice returns price of a single forwarded port per hour.
+	GetPortForwardingPrice() *big.Int
+}
+
 // PaymentRate represents the payment rate
 type PaymentRate struct {
 	PerTime time.Duration
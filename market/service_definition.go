@@ -28,6 +28,11 @@ type ServiceDefinition interface {
 	GetLocation() Location
 }
 
+// PortForwardingDefinition is implemented by service definitions which can advertise port forwarding support.
+type PortForwardingDefinition interface {
+	SupportsPortForwarding() bool
+}
+
 // UnsupportedServiceDefinition represents unknown or unsupported service definition returned by deserializer
 type UnsupportedServiceDefinition struct {
 }
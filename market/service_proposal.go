@@ -19,6 +19,7 @@ package market
 
 import (
 	"encoding/json"
+	"errors"
 
 	"github.com/mysteriumnetwork/node/identity"
 )
@@ -27,11 +28,14 @@ const (
 	proposalFormat = "service-proposal/v1"
 )
 
+// ErrProposalOutdated indicates that consumer requested older proposal version than provider currently serves.
+var ErrProposalOutdated = errors.New("proposal version is outdated")
+
 // ServiceProposal is top level structure which is presented to marketplace by service provider, and looked up by service consumer
 // service proposal can be marked as unsupported by deserializer, because of unknown service, payment method, or contact type
 type ServiceProposal struct {
-	// Per provider unique serial number of service description provided
-	// TODO Not supported yet
+	// Per provider unique serial number of service description provided.
+	// It increases every time provider changes the proposal.
 	ID int `json:"id"`
 
 	// A version number is included in the proposal to allow extensions to the proposal format
@@ -112,8 +116,6 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 // SetProviderContacts updates service proposal description with general data
 func (proposal *ServiceProposal) SetProviderContacts(providerID identity.Identity, contacts ContactList) {
 	proposal.Format = proposalFormat
-	// TODO This will be generated later
-	proposal.ID = 1
 	proposal.ProviderID = providerID.Address
 	proposal.ProviderContacts = contacts
 }
@@ -52,13 +52,13 @@ func NewMMN(resolver ip.Resolver, client *client) *MMN {
 
 // Subscribe subscribes to node events and reports them to MMN
 func (m *MMN) Subscribe(eventBus eventbus.EventBus) error {
-	if err := eventBus.SubscribeAsync(nodevent.AppTopicNode, m.handleNodeStart); err != nil {
+	if err := nodevent.SubscribeNode(eventBus, eventbus.DeliverAsync, m.handleNodeStart); err != nil {
 		return err
 	}
-	if err := eventBus.SubscribeAsync(identity.AppTopicIdentityUnlock, m.handleIdentityUnlock); err != nil {
+	if err := identity.SubscribeIdentityUnlock(eventBus, eventbus.DeliverAsync, m.handleIdentityUnlock); err != nil {
 		return err
 	}
-	return eventBus.SubscribeAsync(servicestate.AppTopicServiceStatus, m.handleServiceStart)
+	return servicestate.SubscribeServiceStatus(eventBus, eventbus.DeliverAsync, m.handleServiceStart)
 }
 
 // handleNodeStart handles node state change and fetches the IP accordingly.
@@ -79,6 +79,7 @@ func (m *MMN) handleIdentityUnlock(ev identity.AppEventIdentityUnlock) {
 }
 
 // handleServiceStart does auto-register to MMN, but only for providers.
+// Every identity running a service is registered, as node may provide services under several identities.
 func (m *MMN) handleServiceStart(e servicestate.AppEventServiceStatus) {
 	if e.Status != string(servicestate.Running) {
 		return
@@ -91,15 +92,15 @@ func (m *MMN) handleServiceStart(e servicestate.AppEventServiceStatus) {
 		return
 	}
 
-	if err := m.register(); err != nil {
-		log.Error().Msgf("Failed to register identity to MMN: %v", err)
+	if err := m.register(e.ProviderID); err != nil {
+		log.Error().Msgf("Failed to register identity %s to MMN: %v", e.ProviderID, err)
 	}
 }
 
-func (m *MMN) register() error {
+func (m *MMN) register(identity string) error {
 	return m.client.RegisterNode(&NodeInformationDto{
 		LocalIP:     m.lastIP,
-		Identity:    m.lastIdentity,
+		Identity:    identity,
 		APIKey:      config.GetString(config.FlagMMNAPIKey),
 		VendorID:    config.GetString(config.FlagVendorID),
 		Arch:        runtime.GOOS + "/" + runtime.GOARCH,
@@ -110,7 +111,7 @@ func (m *MMN) register() error {
 
 // Register registers node to MMN
 func (m *MMN) Register() error {
-	return m.register()
+	return m.register(m.lastIdentity)
 }
 
 // GetReport fetches node report from MMN
@@ -24,6 +24,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/pilvytis"
 )
@@ -164,7 +165,7 @@ type OrderUpdatedCallback interface {
 
 // RegisterOrderUpdatedCallback registers OrderStatusChanged callback.
 func (mb *MobileNode) RegisterOrderUpdatedCallback(cb OrderUpdatedCallback) {
-	_ = mb.eventBus.SubscribeAsync(pilvytis.AppTopicOrderUpdated, func(e pilvytis.AppEventOrderUpdated) {
+	_ = pilvytis.SubscribeOrderUpdated(mb.eventBus, eventbus.DeliverAsync, func(e pilvytis.AppEventOrderUpdated) {
 		payload := OrderUpdatedCallbackPayload{}
 		id, err := shrinkUint64(e.ID)
 		if err != nil {
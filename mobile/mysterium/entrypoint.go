@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"math/big"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/mysteriumnetwork/node/consumer/entertainment"
@@ -360,7 +361,7 @@ type StatisticsChangeCallback interface {
 // RegisterStatisticsChangeCallback registers callback which is called on active connection
 // statistics change.
 func (mb *MobileNode) RegisterStatisticsChangeCallback(cb StatisticsChangeCallback) {
-	_ = mb.eventBus.SubscribeAsync(connectionstate.AppTopicConnectionStatistics, func(e connectionstate.AppEventConnectionStatistics) {
+	_ = connectionstate.SubscribeConnectionStatistics(mb.eventBus, eventbus.DeliverAsync, func(e connectionstate.AppEventConnectionStatistics) {
 		tokensSpent := crypto.BigMystToFloat(mb.stateKeeper.GetState().Connection.Invoice.AgreementTotal)
 		cb.OnChange(int64(e.SessionInfo.Duration().Seconds()), int64(e.Stats.BytesReceived), int64(e.Stats.BytesSent), tokensSpent)
 	})
@@ -374,7 +375,7 @@ type ConnectionStatusChangeCallback interface {
 // RegisterConnectionStatusChangeCallback registers callback which is called on active connection
 // status change.
 func (mb *MobileNode) RegisterConnectionStatusChangeCallback(cb ConnectionStatusChangeCallback) {
-	_ = mb.eventBus.SubscribeAsync(connectionstate.AppTopicConnectionState, func(e connectionstate.AppEventConnectionState) {
+	_ = connectionstate.SubscribeConnectionState(mb.eventBus, eventbus.DeliverAsync, func(e connectionstate.AppEventConnectionState) {
 		cb.OnChange(string(e.State))
 	})
 }
@@ -386,7 +387,7 @@ type BalanceChangeCallback interface {
 
 // RegisterBalanceChangeCallback registers callback which is called on identity balance change.
 func (mb *MobileNode) RegisterBalanceChangeCallback(cb BalanceChangeCallback) {
-	_ = mb.eventBus.SubscribeAsync(event.AppTopicBalanceChanged, func(e event.AppEventBalanceChanged) {
+	_ = event.SubscribeBalanceChanged(mb.eventBus, eventbus.DeliverAsync, func(e event.AppEventBalanceChanged) {
 		balance := crypto.BigMystToFloat(e.Current)
 		cb.OnChange(e.Identity.Address, balance)
 	})
@@ -398,6 +399,9 @@ func (mb *MobileNode) RegisterBalanceChangeCallback(cb BalanceChangeCallback) {
  *	- "auto" (default) tries the following with fallbacks: provider's DNS -> client's system DNS -> public DNS
  *  - "provider" uses DNS servers from provider's system configuration
  *  - "system" uses DNS servers from client's system configuration
+ *
+ * IncludeCIDRs, ExcludeCIDRs, IncludeDomains and ExcludeDomains are comma separated
+ * split tunnelling lists, e.g. "10.0.0.0/8,192.168.0.0/16".
  */
 type ConnectRequest struct {
 	IdentityAddress   string
@@ -406,6 +410,10 @@ type ConnectRequest struct {
 	DNSOption         string
 	DisableKillSwitch bool
 	ForceReconnect    bool
+	IncludeCIDRs      string
+	ExcludeCIDRs      string
+	IncludeDomains    string
+	ExcludeDomains    string
 }
 
 func (cr *ConnectRequest) dnsOption() (connection.DNSOption, error) {
@@ -416,6 +424,26 @@ func (cr *ConnectRequest) dnsOption() (connection.DNSOption, error) {
 	return connection.DNSOptionAuto, nil
 }
 
+func (cr *ConnectRequest) splitTunnel() (connection.SplitTunnel, error) {
+	splitTunnel := connection.SplitTunnel{
+		IncludeCIDRs:   splitList(cr.IncludeCIDRs),
+		ExcludeCIDRs:   splitList(cr.ExcludeCIDRs),
+		IncludeDomains: splitList(cr.IncludeDomains),
+		ExcludeDomains: splitList(cr.ExcludeDomains),
+	}
+	return splitTunnel, splitTunnel.Validate()
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // ConnectResponse represents connect response with optional error code and message.
 type ConnectResponse struct {
 	ErrorCode    string
@@ -444,7 +472,7 @@ func (mb *MobileNode) Connect(req *ConnectRequest) *ConnectResponse {
 	if err != nil {
 		qualityEvent.Stage = quality.StageGetProposal
 		qualityEvent.Error = err.Error()
-		mb.eventBus.Publish(quality.AppTopicConnectionEvents, qualityEvent)
+		quality.PublishConnectionEvents(mb.eventBus, qualityEvent)
 
 		return &ConnectResponse{
 			ErrorCode:    connectErrInvalidProposal,
@@ -459,9 +487,17 @@ func (mb *MobileNode) Connect(req *ConnectRequest) *ConnectResponse {
 			ErrorMessage: err.Error(),
 		}
 	}
+	splitTunnel, err := req.splitTunnel()
+	if err != nil {
+		return &ConnectResponse{
+			ErrorCode:    connectErrUnknown,
+			ErrorMessage: err.Error(),
+		}
+	}
 	connectOptions := connection.ConnectParams{
 		DisableKillSwitch: req.DisableKillSwitch,
 		DNS:               dnsOption,
+		SplitTunnel:       splitTunnel,
 	}
 
 	hermes, err := mb.identityChannelCalculator.GetActiveHermes(mb.chainID)
@@ -475,7 +511,7 @@ func (mb *MobileNode) Connect(req *ConnectRequest) *ConnectResponse {
 	if err := mb.connectionManager.Connect(identity.FromAddress(req.IdentityAddress), hermes, *proposal, connectOptions); err != nil {
 		qualityEvent.Stage = quality.StageConnectionUnknownError
 		qualityEvent.Error = err.Error()
-		mb.eventBus.Publish(quality.AppTopicConnectionEvents, qualityEvent)
+		quality.PublishConnectionEvents(mb.eventBus, qualityEvent)
 
 		if errors.Is(err, connection.ErrInsufficientBalance) {
 			return &ConnectResponse{
@@ -490,7 +526,7 @@ func (mb *MobileNode) Connect(req *ConnectRequest) *ConnectResponse {
 	}
 
 	qualityEvent.Stage = quality.StageConnectionOK
-	mb.eventBus.Publish(quality.AppTopicConnectionEvents, qualityEvent)
+	quality.PublishConnectionEvents(mb.eventBus, qualityEvent)
 
 	return &ConnectResponse{}
 }
@@ -80,6 +80,7 @@ func NewWireGuardConnection(opts wireGuardOptions, device wireguardDevice, ipRes
 		privateKey:      privateKey,
 		ipResolver:      ipResolver,
 		handshakeWaiter: handshakeWaiter,
+		lookupVia:       wireguard_connection.LookupViaServers,
 	}, nil
 }
 
@@ -93,6 +94,7 @@ type wireguardConnection struct {
 	device          wireguardDevice
 	ipResolver      ip.Resolver
 	handshakeWaiter wireguard_connection.HandshakeWaiter
+	lookupVia       func(servers []string) func(host string) ([]net.IP, error)
 }
 
 var _ connection.Connection = &wireguardConnection{}
@@ -134,7 +136,24 @@ func (c *wireguardConnection) Start(ctx context.Context, options connection.Conn
 		config.Provider.Endpoint.Port = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
 	}
 
-	if err := c.device.Start(c.privateKey, config, options.ChannelConn); err != nil {
+	var tunnelNetworks []net.IPNet
+	if options.Params.SplitTunnel.IsEnabled() {
+		// Domains are resolved by the tunnel DNS servers, so routes match the addresses applications get.
+		autoDNS := connection.DNSOptionAuto
+		dnsIPs, err := autoDNS.ResolveIPs(config.Consumer.DNSIPs)
+		if err != nil {
+			return fmt.Errorf("could not resolve tunnel DNS servers: %w", err)
+		}
+		routes, err := options.Params.SplitTunnel.Resolve(c.lookupVia(dnsIPs))
+		if err != nil {
+			return fmt.Errorf("could not resolve split tunnel: %w", err)
+		}
+		if tunnelNetworks = routes.TunnelNetworks(); len(tunnelNetworks) == 0 {
+			return errors.New("split tunnel excludes all destinations")
+		}
+	}
+
+	if err := c.device.Start(c.privateKey, config, options.ChannelConn, tunnelNetworks); err != nil {
 		return errors.Wrap(err, "could not start device")
 	}
 
@@ -174,7 +193,7 @@ func (c *wireguardConnection) GetConfig() (connection.ConsumerConfig, error) {
 }
 
 type wireguardDevice interface {
-	Start(privateKey string, config wireguard.ServiceConfig, channelConn *net.UDPConn) error
+	Start(privateKey string, config wireguard.ServiceConfig, channelConn *net.UDPConn, tunnelNetworks []net.IPNet) error
 	Stop()
 	Stats() (*wgcfg.Stats, error)
 }
@@ -189,16 +208,16 @@ type wireguardDeviceImpl struct {
 	device *device.Device
 }
 
-func (w *wireguardDeviceImpl) Start(privateKey string, config wireguard.ServiceConfig, channelConn *net.UDPConn) error {
+func (w *wireguardDeviceImpl) Start(privateKey string, config wireguard.ServiceConfig, channelConn *net.UDPConn, tunnelNetworks []net.IPNet) error {
 	log.Debug().Msg("Creating tunnel device")
-	tunDevice, err := w.newTunnDevice(w.tunnelSetup, config)
+	tunDevice, err := w.newTunnDevice(w.tunnelSetup, config, tunnelNetworks)
 	if err != nil {
 		return errors.Wrap(err, "could not create tunnel device")
 	}
 
 	w.device = device.NewDevice(tunDevice, device.NewLogger(device.LogLevelDebug, "[userspace-wg]"))
 
-	err = w.applyConfig(w.device, privateKey, config)
+	err = w.applyConfig(w.device, privateKey, config, tunnelNetworks)
 	if err != nil {
 		return errors.Wrap(err, "could not setup device configuration")
 	}
@@ -248,7 +267,16 @@ func (w *wireguardDeviceImpl) Stats() (*wgcfg.Stats, error) {
 	return stats, nil
 }
 
-func (w *wireguardDeviceImpl) applyConfig(devApi *device.Device, privateKey string, config wireguard.ServiceConfig) error {
+func (w *wireguardDeviceImpl) applyConfig(devApi *device.Device, privateKey string, config wireguard.ServiceConfig, tunnelNetworks []net.IPNet) error {
+	// All traffic through this peer (unfortunately 0.0.0.0/0 didn't work as it was treated as ipv6)
+	allowedIPs := []string{"0.0.0.0/1", "128.0.0.0/1"}
+	if len(tunnelNetworks) > 0 {
+		allowedIPs = nil
+		for _, network := range tunnelNetworks {
+			allowedIPs = append(allowedIPs, network.String())
+		}
+	}
+
 	deviceConfig := wgcfg.DeviceConfig{
 		PrivateKey: privateKey,
 		ListenPort: config.LocalPort,
@@ -256,8 +284,7 @@ func (w *wireguardDeviceImpl) applyConfig(devApi *device.Device, privateKey stri
 			Endpoint:               &config.Provider.Endpoint,
 			PublicKey:              config.Provider.PublicKey,
 			KeepAlivePeriodSeconds: 18,
-			// All traffic through this peer (unfortunately 0.0.0.0/0 didn't work as it was treated as ipv6)
-			AllowedIPs: []string{"0.0.0.0/1", "128.0.0.0/1"},
+			AllowedIPs:             allowedIPs,
 		},
 	}
 
@@ -267,12 +294,16 @@ func (w *wireguardDeviceImpl) applyConfig(devApi *device.Device, privateKey stri
 	return nil
 }
 
-func (w *wireguardDeviceImpl) newTunnDevice(wgTunnSetup WireguardTunnelSetup, config wireguard.ServiceConfig) (tun.Device, error) {
+func (w *wireguardDeviceImpl) newTunnDevice(wgTunnSetup WireguardTunnelSetup, config wireguard.ServiceConfig, tunnelNetworks []net.IPNet) (tun.Device, error) {
 	consumerIP := config.Consumer.IPAddress
 	prefixLen, _ := consumerIP.Mask.Size()
 	wgTunnSetup.NewTunnel()
 	wgTunnSetup.SetSessionName("wg-tun-session")
 	wgTunnSetup.AddTunnelAddress(consumerIP.IP.String(), prefixLen)
+	if consumerIP6 := config.Consumer.IPAddress6; consumerIP6 != nil {
+		prefixLen6, _ := consumerIP6.Mask.Size()
+		wgTunnSetup.AddTunnelAddress(consumerIP6.IP.String(), prefixLen6)
+	}
 	wgTunnSetup.SetMTU(androidTunMtu)
 	wgTunnSetup.SetBlocking(true)
 
@@ -285,11 +316,19 @@ func (w *wireguardDeviceImpl) newTunnDevice(wgTunnSetup WireguardTunnelSetup, co
 		wgTunnSetup.AddDNS(dnsIP)
 	}
 
-	// Route all traffic through tunnel
-	wgTunnSetup.AddRoute("0.0.0.0", 1)
-	wgTunnSetup.AddRoute("128.0.0.0", 1)
-	wgTunnSetup.AddRoute("::", 1)
-	wgTunnSetup.AddRoute("8000::", 1)
+	if len(tunnelNetworks) > 0 {
+		// Route only split tunnel networks through tunnel
+		for _, network := range tunnelNetworks {
+			prefixLen, _ := network.Mask.Size()
+			wgTunnSetup.AddRoute(network.IP.String(), prefixLen)
+		}
+	} else {
+		// Route all traffic through tunnel
+		wgTunnSetup.AddRoute("0.0.0.0", 1)
+		wgTunnSetup.AddRoute("128.0.0.0", 1)
+		wgTunnSetup.AddRoute("::", 1)
+		wgTunnSetup.AddRoute("8000::", 1)
+	}
 
 	fd, err := wgTunnSetup.Establish()
 	if err != nil {
@@ -59,6 +59,29 @@ func TestConnectionStartStop(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestConnectionResolvesSplitTunnelViaTunnelDNS(t *testing.T) {
+	conn := newConn(t)
+	var lookupServers []string
+	conn.lookupVia = func(servers []string) func(host string) ([]net.IP, error) {
+		lookupServers = servers
+		return func(host string) ([]net.IP, error) {
+			return []net.IP{net.ParseIP("5.6.7.8")}, nil
+		}
+	}
+
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+	err := conn.Start(context.Background(), connection.ConnectOptions{
+		Params: connection.ConnectParams{
+			SplitTunnel: connection.SplitTunnel{IncludeDomains: []string{"example.com"}},
+		},
+		SessionConfig: sessionConfig,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"128.0.0.1"}, lookupServers)
+	conn.Stop()
+}
+
 func TestConnectionStopAfterHandshakeError(t *testing.T) {
 	conn := newConn(t)
 	handshakeTimeoutErr := errors.New("handshake timeout")
@@ -115,8 +138,9 @@ func newServiceConfig() wg.ServiceConfig {
 			Endpoint:  *endpoint,
 		},
 		Consumer: struct {
-			IPAddress net.IPNet
-			DNSIPs    string
+			IPAddress  net.IPNet
+			IPAddress6 *net.IPNet
+			DNSIPs     string
 		}{
 			IPAddress: net.IPNet{
 				IP:   net.IPv4(127, 0, 0, 1),
@@ -130,7 +154,7 @@ func newServiceConfig() wg.ServiceConfig {
 type mockWireGuardDevice struct {
 }
 
-func (m mockWireGuardDevice) Start(_ string, _ wg.ServiceConfig, _ *net.UDPConn) error {
+func (m mockWireGuardDevice) Start(_ string, _ wg.ServiceConfig, _ *net.UDPConn, _ []net.IPNet) error {
 	return nil
 }
 
@@ -19,6 +19,8 @@ package mocks
 
 import (
 	"sync"
+
+	"github.com/mysteriumnetwork/node/eventbus"
 )
 
 // EventBusEntry represents the entry in publisher's history
@@ -27,8 +29,9 @@ type EventBusEntry struct {
 	Event interface{}
 }
 
-// EventBus is a fake event bus.
+// EventBus is a fake event bus recording published events and ignoring subscriptions.
 type EventBus struct {
+	eventbus.EventBus
 	publishLast    interface{}
 	publishHistory []EventBusEntry
 	lock           sync.Mutex
@@ -36,13 +39,14 @@ type EventBus struct {
 
 // NewEventBus creates a new fake event bus.
 func NewEventBus() *EventBus {
-	return &EventBus{
+	mp := &EventBus{
 		publishHistory: make([]EventBusEntry, 0),
 	}
+	mp.EventBus = eventbus.Tap(mp.record)
+	return mp
 }
 
-// Publish fakes publish.
-func (mp *EventBus) Publish(topic string, event interface{}) {
+func (mp *EventBus) record(topic string, event interface{}) {
 	mp.lock.Lock()
 	defer mp.lock.Unlock()
 
@@ -53,21 +57,6 @@ func (mp *EventBus) Publish(topic string, event interface{}) {
 	mp.publishLast = event
 }
 
-// SubscribeAsync fakes async subsribe.
-func (mp *EventBus) SubscribeAsync(topic string, fn interface{}) error {
-	return nil
-}
-
-// Subscribe fakes subscribe.
-func (mp *EventBus) Subscribe(topic string, fn interface{}) error {
-	return nil
-}
-
-// Unsubscribe fakes unsubscribe.
-func (mp *EventBus) Unsubscribe(topic string, fn interface{}) error {
-	return nil
-}
-
 // Pop pops the last event for assertions.
 func (mp *EventBus) Pop() interface{} {
 	mp.lock.Lock()
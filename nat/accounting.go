@@ -0,0 +1,140 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/firewall/iptables"
+)
+
+// TrafficCounter holds bytes sent (Up) and received (Down) by consumers.
+type TrafficCounter struct {
+	Up   uint64 `json:"up"`
+	Down uint64 `json:"down"`
+}
+
+// PortClass groups destination ports of a similar kind of traffic.
+type PortClass struct {
+	Name     string
+	TCPPorts string
+	UDPPorts string
+}
+
+// PortClassOther names traffic not matched by any of PortClasses.
+const PortClassOther = "other"
+
+// PortClasses lists port classes used for traffic accounting.
+var PortClasses = []PortClass{
+	{Name: "web", TCPPorts: "80,443,8080,8443", UDPPorts: "443"},
+	{Name: "mail", TCPPorts: "25,110,143,465,587,993,995"},
+	{Name: "p2p", TCPPorts: "4662,6881:6999,51413", UDPPorts: "4672,6881:6999,51413"},
+}
+
+// TrafficAccountant reads counters of traffic accounting rules.
+type TrafficAccountant interface {
+	TrafficByPortClass(vpnNetwork net.IPNet) (map[string]TrafficCounter, error)
+}
+
+const (
+	accountingComment = "myst-accounting"
+	portClassTotal    = "total"
+	directionUp       = "up"
+	directionDown     = "down"
+)
+
+// TrafficByPortClass returns traffic of the VPN network grouped by destination port class.
+func (svc *serviceIPTables) TrafficByPortClass(vpnNetwork net.IPNet) (map[string]TrafficCounter, error) {
+	lines, err := iptables.Exec("--list", chainForward, "--verbose", "--exact", "--numeric")
+	if err != nil {
+		return nil, fmt.Errorf("could not list accounting rules: %w", err)
+	}
+	return parseAccountingCounters(lines, vpnNetwork.String()), nil
+}
+
+// makeAccountingRules creates counting only rules, they are inserted first so nothing else can skip them.
+func makeAccountingRules(vpnNetwork string) (rules []iptables.Rule) {
+	rule := func(class, direction string, spec ...string) iptables.Rule {
+		spec = append(spec, "--match", "comment", "--comment", strings.Join([]string{accountingComment, vpnNetwork, class, direction}, " "))
+		return iptables.InsertAt(chainForward, 1).RuleSpec(spec...)
+	}
+
+	for _, class := range PortClasses {
+		for _, protocolPorts := range [][2]string{{"tcp", class.TCPPorts}, {"udp", class.UDPPorts}} {
+			protocol, ports := protocolPorts[0], protocolPorts[1]
+			if ports == "" {
+				continue
+			}
+			rules = append(rules,
+				rule(class.Name, directionUp, "--source", vpnNetwork, "--protocol", protocol, "--match", "multiport", "--dports", ports),
+				rule(class.Name, directionDown, "--destination", vpnNetwork, "--protocol", protocol, "--match", "multiport", "--sports", ports),
+			)
+		}
+	}
+	return append(rules,
+		rule(portClassTotal, directionUp, "--source", vpnNetwork),
+		rule(portClassTotal, directionDown, "--destination", vpnNetwork),
+	)
+}
+
+func parseAccountingCounters(lines []string, vpnNetwork string) map[string]TrafficCounter {
+	prefix := "/* " + accountingComment + " " + vpnNetwork + " "
+	counters := make(map[string]TrafficCounter)
+	for _, line := range lines {
+		idx := strings.Index(line, prefix)
+		if idx < 0 {
+			continue
+		}
+		fields := strings.Fields(line)
+		comment := strings.Fields(strings.TrimSuffix(strings.TrimSpace(line[idx+len(prefix):]), "*/"))
+		if len(fields) < 2 || len(comment) != 2 {
+			continue
+		}
+		bytes, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		counter := counters[comment[0]]
+		if comment[1] == directionUp {
+			counter.Up += bytes
+		} else {
+			counter.Down += bytes
+		}
+		counters[comment[0]] = counter
+	}
+
+	other := counters[portClassTotal]
+	delete(counters, portClassTotal)
+	for _, counter := range counters {
+		other.Up -= minUint64(other.Up, counter.Up)
+		other.Down -= minUint64(other.Down, counter.Down)
+	}
+	counters[PortClassOther] = other
+	return counters
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/firewall/iptables"
+)
+
+func TestMakeAccountingRules(t *testing.T) {
+	rules := makeAccountingRules("10.182.0.0/24")
+
+	assert.Len(t, rules, 12)
+	assert.Equal(t, []string{
+		"-I", "FORWARD", "1", "--source", "10.182.0.0/24", "--protocol", "tcp", "--match", "multiport", "--dports", "80,443,8080,8443",
+		"--match", "comment", "--comment", "myst-accounting 10.182.0.0/24 web up",
+	}, rules[0].ApplyArgs())
+	assert.Equal(t, []string{
+		"-D", "FORWARD", "--destination", "10.182.0.0/24",
+		"--match", "comment", "--comment", "myst-accounting 10.182.0.0/24 total down",
+	}, rules[11].RemoveArgs())
+}
+
+func TestTrafficByPortClass(t *testing.T) {
+	defer func(exec func(args ...string) ([]string, error)) { iptables.Exec = exec }(iptables.Exec)
+	iptables.Exec = func(args ...string) ([]string, error) {
+		return []string{
+			"Chain FORWARD (policy ACCEPT 0 packets, 0 bytes)",
+			"    pkts      bytes target     prot opt in     out     source               destination",
+			"       3      500            all  --  *      *       0.0.0.0/0            10.182.0.0/24        /* myst-accounting 10.182.0.0/24 total down */",
+			"       4     1000            all  --  *      *       10.182.0.0/24        0.0.0.0/0            /* myst-accounting 10.182.0.0/24 total up */",
+			"       1      100            tcp  --  *      *       10.182.0.0/24        0.0.0.0/0            multiport dports 25 /* myst-accounting 10.182.0.0/24 mail up */",
+			"       2      300            tcp  --  *      *       0.0.0.0/0            10.182.0.0/24        multiport sports 80,443 /* myst-accounting 10.182.0.0/24 web down */",
+			"       1      200            udp  --  *      *       10.182.0.0/24        0.0.0.0/0            multiport dports 443 /* myst-accounting 10.182.0.0/24 web up */",
+			"       1      700            tcp  --  *      *       10.182.1.0/24        0.0.0.0/0            multiport dports 443 /* myst-accounting 10.182.1.0/24 web up */",
+			"       9     9000 ACCEPT     all  --  *      *       10.182.0.0/24        0.0.0.0/0",
+		}, nil
+	}
+
+	_, network, _ := net.ParseCIDR("10.182.0.0/24")
+	counters, err := (&serviceIPTables{}).TrafficByPortClass(*network)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]TrafficCounter{
+		"web":   {Up: 200, Down: 300},
+		"mail":  {Up: 100},
+		"other": {Up: 700, Down: 200},
+	}, counters)
+}
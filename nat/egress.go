@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sync"
+)
+
+const (
+	// EgressRoundRobin assigns egress addresses to sessions in turn.
+	EgressRoundRobin = "round-robin"
+	// EgressSticky assigns the same egress address to all sessions of a consumer.
+	EgressSticky = "sticky"
+)
+
+// EgressPool selects public addresses used to NAT consumer traffic out.
+// An empty pool selects nothing, meaning the outbound address is used.
+type EgressPool struct {
+	ips       []net.IP
+	selection string
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewEgressPool creates egress address pool with the given selection mode.
+func NewEgressPool(ips []string, selection string) (*EgressPool, error) {
+	switch selection {
+	case "":
+		selection = EgressRoundRobin
+	case EgressRoundRobin, EgressSticky:
+	default:
+		return nil, fmt.Errorf("unknown egress selection %q", selection)
+	}
+
+	pool := &EgressPool{selection: selection}
+	for _, value := range ips {
+		ip := net.ParseIP(value)
+		if ip == nil || ip.To4() == nil {
+			return nil, fmt.Errorf("invalid egress IPv4 address %q", value)
+		}
+		pool.ips = append(pool.ips, ip.To4())
+	}
+	return pool, nil
+}
+
+// IsEmpty returns true if the pool has no addresses.
+func (p *EgressPool) IsEmpty() bool {
+	return p == nil || len(p.ips) == 0
+}
+
+// Select returns the egress address of the next consumer session, nil if the pool is empty.
+func (p *EgressPool) Select(consumerID string) net.IP {
+	if p.IsEmpty() {
+		return nil
+	}
+
+	if p.selection == EgressSticky {
+		h := fnv.New32a()
+		h.Write([]byte(consumerID))
+		return p.ips[h.Sum32()%uint32(len(p.ips))]
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ip := p.ips[p.next%len(p.ips)]
+	p.next++
+	return ip
+}
+
+// CheckLocal verifies that all pool addresses are assigned to local interfaces, SNAT to other addresses breaks traffic.
+func (p *EgressPool) CheckLocal() error {
+	if p.IsEmpty() {
+		return nil
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return fmt.Errorf("could not list interface addresses: %w", err)
+	}
+	for _, ip := range p.ips {
+		if !hasAddress(addrs, ip) {
+			return fmt.Errorf("egress address %s: %w", ip, errNotLocalAddress)
+		}
+	}
+	return nil
+}
+
+var errNotLocalAddress = errors.New("address is not assigned to any local interface")
+
+func hasAddress(addrs []net.Addr, ip net.IP) bool {
+	for _, addr := range addrs {
+		if network, ok := addr.(*net.IPNet); ok && network.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgressPoolRoundRobin(t *testing.T) {
+	pool, err := NewEgressPool([]string{"1.1.1.1", "2.2.2.2"}, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, net.ParseIP("1.1.1.1").To4(), pool.Select("0x1"))
+	assert.Equal(t, net.ParseIP("2.2.2.2").To4(), pool.Select("0x1"))
+	assert.Equal(t, net.ParseIP("1.1.1.1").To4(), pool.Select("0x1"))
+}
+
+func TestEgressPoolSticky(t *testing.T) {
+	pool, err := NewEgressPool([]string{"1.1.1.1", "2.2.2.2", "3.3.3.3"}, EgressSticky)
+	require.NoError(t, err)
+
+	selected := pool.Select("0x1")
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, selected, pool.Select("0x1"))
+	}
+	assert.Contains(t, []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"}, selected.String())
+}
+
+func TestEgressPoolEmpty(t *testing.T) {
+	pool, err := NewEgressPool(nil, EgressSticky)
+	require.NoError(t, err)
+	assert.True(t, pool.IsEmpty())
+	assert.Nil(t, pool.Select("0x1"))
+	assert.NoError(t, pool.CheckLocal())
+
+	var nilPool *EgressPool
+	assert.Nil(t, nilPool.Select("0x1"))
+}
+
+func TestEgressPoolValidation(t *testing.T) {
+	_, err := NewEgressPool([]string{"1.1.1.1"}, "random")
+	assert.Error(t, err)
+
+	_, err = NewEgressPool([]string{"not-an-ip"}, EgressRoundRobin)
+	assert.Error(t, err)
+
+	_, err = NewEgressPool([]string{"::1"}, EgressRoundRobin)
+	assert.Error(t, err)
+}
+
+func TestEgressPoolCheckLocal(t *testing.T) {
+	pool, err := NewEgressPool([]string{"127.0.0.1"}, EgressRoundRobin)
+	require.NoError(t, err)
+	assert.NoError(t, pool.CheckLocal())
+
+	pool, err = NewEgressPool([]string{"192.0.2.55"}, EgressRoundRobin)
+	require.NoError(t, err)
+	assert.Error(t, pool.CheckLocal())
+}
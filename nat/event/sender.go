@@ -50,7 +50,7 @@ func NewSender(metricsSender metricsSender, ipResolver ipResolver, gatewayLoader
 
 // Subscribe subscribes to relevant events of event bus.
 func (es *Sender) Subscribe(bus eventbus.Subscriber) error {
-	return bus.Subscribe(AppTopicTraversal, es.consumeNATEvent)
+	return SubscribeTraversal(bus, eventbus.DeliverSync, es.consumeNATEvent)
 }
 
 // consumeNATEvent sends received event to server
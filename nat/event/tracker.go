@@ -61,7 +61,7 @@ func (et *Tracker) consumeNATEvent(event Event) {
 
 // Subscribe subscribes to relevant events of event bus.
 func (et *Tracker) Subscribe(bus eventbus.Subscriber) error {
-	return bus.Subscribe(AppTopicTraversal, et.consumeNATEvent)
+	return SubscribeTraversal(bus, eventbus.DeliverSync, et.consumeNATEvent)
 }
 
 // LastEvent returns the last known event and boolean flag, indicating if such event exists
@@ -87,3 +87,13 @@ type Event struct {
 	Successful bool   `json:"successful"`
 	Error      error  `json:"error,omitempty"`
 }
+
+// PublishTraversal publishes NAT traversal events on the bus.
+func PublishTraversal(publisher eventbus.Publisher, e Event) {
+	eventbus.Handle(AppTopicTraversal).Publish(publisher, e)
+}
+
+// SubscribeTraversal subscribes fn to NAT traversal events.
+func SubscribeTraversal(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(Event)) error {
+	return eventbus.Handle(AppTopicTraversal).Subscribe(subscriber, delivery, fn)
+}
@@ -17,13 +17,18 @@
 
 package nat
 
-import "net"
+import (
+	"errors"
+	"net"
+)
 
 // NATService routes internet traffic through provider and
 // sets up firewall rules for security
 type NATService interface {
 	Enable() error
 	Setup(opts Options) (rules []interface{}, err error)
+	// ForwardPorts sets up rules forwarding public ports of the provider to the consumers.
+	ForwardPorts(forwards []PortForward) (rules []interface{}, err error)
 	Del(rules []interface{}) error
 	Disable() error
 }
@@ -35,4 +40,28 @@ type Options struct {
 	EnableDNSRedirect bool
 	DNSIP             net.IP
 	DNSPort           int
+	// VPNNetwork6 enables IPv6 forwarding of the consumer network, DNSIP6 is its DNS address.
+	VPNNetwork6 *net.IPNet
+	DNSIP6      net.IP
+	// TrafficAccounting adds rules counting traffic by destination port class
+	TrafficAccounting bool
+	// UpstreamInterface forwards consumer traffic only through the given upstream interface instead of the provider's own address
+	UpstreamInterface string
 }
+
+// PortForward forwards public port of the provider to the same port of the consumer address.
+type PortForward struct {
+	// Protocol is either "tcp" or "udp".
+	Protocol string
+	Port     int
+	Consumer net.IP
+	// Address is the provider host address the port is forwarded from.
+	Address net.IP
+}
+
+var (
+	// ErrUpstreamNotSupported is returned when NAT service can't redirect traffic to the upstream proxy.
+	ErrUpstreamNotSupported = errors.New("forwarding through upstream is not supported on this platform")
+	// ErrPortForwardingNotSupported is returned when NAT service can't forward ports to consumers.
+	ErrPortForwardingNotSupported = errors.New("port forwarding is not supported on this platform")
+)
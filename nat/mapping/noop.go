@@ -35,7 +35,7 @@ type noopPortMapper struct {
 }
 
 func (p *noopPortMapper) Map(id, protocol string, port int, name string) (release func(), ok bool) {
-	p.publisher.Publish(event.AppTopicTraversal, event.BuildSuccessfulEvent(id, "noop_mapping"))
+	event.PublishTraversal(p.publisher, event.BuildSuccessfulEvent(id, "noop_mapping"))
 	log.Debug().Msgf("Noop port mapping requested: %d", port)
 
 	return func() {
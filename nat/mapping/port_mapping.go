@@ -130,9 +130,9 @@ func (p *portMapper) routerIPPublic() bool {
 
 func (p *portMapper) notify(id string, err error) {
 	if err != nil {
-		p.publisher.Publish(event.AppTopicTraversal, event.BuildFailureEvent(id, StageName, err))
+		event.PublishTraversal(p.publisher, event.BuildFailureEvent(id, StageName, err))
 	} else {
-		p.publisher.Publish(event.AppTopicTraversal, event.BuildSuccessfulEvent(id, StageName))
+		event.PublishTraversal(p.publisher, event.BuildSuccessfulEvent(id, StageName))
 	}
 }
 
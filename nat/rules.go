@@ -25,7 +25,18 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func protectedNetworks() (nets []*net.IPNet) {
+// protectedNetworks returns protected networks of the given address family.
+func protectedNetworks(ipv6 bool) (nets []*net.IPNet) {
+	for _, ipNet := range ProtectedNetworks() {
+		if (ipNet.IP.To4() == nil) == ipv6 {
+			nets = append(nets, ipNet)
+		}
+	}
+	return nets
+}
+
+// ProtectedNetworks returns provider networks which must not be reachable by consumers.
+func ProtectedNetworks() (nets []*net.IPNet) {
 	cfg := config.GetString(config.FlagFirewallProtectedNetworks)
 	if cfg == "" {
 		return nil
@@ -25,6 +25,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/utils"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 )
 
 const (
@@ -128,8 +129,20 @@ func (ics *serviceICS) enableRemoteAccessService() error {
 	return errors.Wrap(err, "failed to start RemoteAccess service")
 }
 
+// ForwardPorts is not supported by internet connection sharing.
+func (ics *serviceICS) ForwardPorts(_ []PortForward) ([]interface{}, error) {
+	return nil, ErrPortForwardingNotSupported
+}
+
 // Setup enables internet connection sharing for the local interface.
 func (ics *serviceICS) Setup(opts Options) (rules []interface{}, err error) {
+	if opts.UpstreamInterface != "" {
+		return nil, ErrUpstreamNotSupported
+	}
+	if opts.VPNNetwork6 != nil {
+		log.Warn().Msg("IPv6 forwarding is not supported by ICS, consumer IPv6 traffic will not be forwarded")
+	}
+
 	ics.mu.Lock()
 	defer ics.mu.Unlock()
 
@@ -18,7 +18,9 @@
 package nat
 
 import (
+	"net"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -33,6 +35,10 @@ type serviceIPTables struct {
 	mu        sync.Mutex
 	rules     []iptables.Rule
 	ipForward serviceIPForward
+	// ip6Forward is enabled on the first IPv6 setup only, as it changes router advertisement handling of the host.
+	ip6Forward        *serviceIPForward
+	ip6ForwardEnabled bool
+	restoreAcceptRA   func()
 }
 
 const (
@@ -62,6 +68,10 @@ func (svc *serviceIPTables) Setup(opts Options) (appliedRules []interface{}, err
 		}
 	}()
 
+	if opts.VPNNetwork6 != nil {
+		svc.enableIP6Forward()
+	}
+
 	for _, rule := range makeIPTablesRules(opts) {
 		if err := svc.applyRule(rule); err != nil {
 			return nil, err
@@ -72,6 +82,33 @@ func (svc *serviceIPTables) Setup(opts Options) (appliedRules []interface{}, err
 	return untypedIptRules(applied), nil
 }
 
+// ForwardPorts sets up DNAT rules forwarding public ports to the consumers.
+func (svc *serviceIPTables) ForwardPorts(forwards []PortForward) (appliedRules []interface{}, err error) {
+	log.Info().Msgf("Setting up port forwarding rules for %d port(s)", len(forwards))
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	var applied []iptables.Rule
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, rule := range applied {
+			if err := svc.removeRule(rule); err != nil {
+				log.Error().Err(err).Msg("Could not remove rule")
+			}
+		}
+	}()
+
+	for _, rule := range makePortForwardRules(forwards) {
+		if err := svc.applyRule(rule); err != nil {
+			return nil, err
+		}
+		applied = append(applied, rule)
+	}
+	return untypedIptRules(applied), nil
+}
+
 // Del removes given NAT/Firewall rules that were previously set up.
 func (svc *serviceIPTables) Del(rules []interface{}) (err error) {
 	log.Info().Msg("Deleting NAT/Firewall rules")
@@ -99,14 +136,84 @@ func (svc *serviceIPTables) Enable() error {
 	return err
 }
 
+func (svc *serviceIPTables) enableIP6Forward() {
+	if svc.ip6Forward == nil || svc.ip6ForwardEnabled {
+		return
+	}
+
+	// Forwarding host ignores router advertisements unless accept_ra is 2,
+	// so the uplink would lose its autoconfigured address and default route.
+	restore, err := acceptRouterAdvertisements(svc.ip6Forward.CommandFactory)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to keep accepting IPv6 router advertisements on the uplink")
+	}
+	svc.restoreAcceptRA = restore
+
+	if err := svc.ip6Forward.Enable(); err != nil {
+		log.Warn().Err(err).Msg("Failed to enable IPv6 forwarding")
+		return
+	}
+	svc.ip6ForwardEnabled = true
+}
+
+// acceptRouterAdvertisements sets accept_ra=2 on the interface of the default IPv6 route
+// and returns function restoring the previous value. Nil is returned if there is no IPv6 uplink.
+func acceptRouterAdvertisements(commandFactory CommandFactory) (func(), error) {
+	output, err := commandFactory("ip", "-6", "route", "show", "default").Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get default IPv6 route")
+	}
+	fields := strings.Fields(string(output))
+	var iface string
+	for i := 0; i < len(fields)-1; i++ {
+		if fields[i] == "dev" {
+			iface = fields[i+1]
+			break
+		}
+	}
+	if iface == "" {
+		return nil, nil
+	}
+
+	// Slash separated key keeps dots of VLAN interface names, e.g. eth0.100.
+	key := "net/ipv6/conf/" + iface + "/accept_ra"
+	output, err = commandFactory("/sbin/sysctl", "-n", key).Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read "+key)
+	}
+	previous := strings.TrimSpace(string(output))
+	if previous == "2" {
+		return nil, nil
+	}
+
+	if output, err := commandFactory("sudo", "/sbin/sysctl", "-w", key+"=2").CombinedOutput(); err != nil {
+		return nil, errors.Wrapf(err, "could not set %s: %s", key, string(output))
+	}
+	log.Info().Msgf("Router advertisements are accepted on %s with IPv6 forwarding enabled", iface)
+
+	return func() {
+		if output, err := commandFactory("sudo", "/sbin/sysctl", "-w", key+"="+previous).CombinedOutput(); err != nil {
+			log.Warn().Err(err).Msgf("Failed to restore %s: %s", key, string(output))
+		}
+	}, nil
+}
+
 // Disable disables NAT service and deletes all rules.
 func (svc *serviceIPTables) Disable() error {
 	svc.ipForward.Disable()
+	if svc.ip6ForwardEnabled {
+		svc.ip6Forward.Disable()
+		svc.ip6ForwardEnabled = false
+	}
+	if svc.restoreAcceptRA != nil {
+		svc.restoreAcceptRA()
+		svc.restoreAcceptRA = nil
+	}
 	return svc.Del(untypedIptRules(svc.rules))
 }
 
 func (svc *serviceIPTables) applyRule(rule iptables.Rule) error {
-	if err := iptablesExec(rule.ApplyArgs()...); err != nil {
+	if err := iptablesExec(rule.IsIPv6(), rule.ApplyArgs()...); err != nil {
 		return err
 	}
 	svc.rules = append(svc.rules, rule)
@@ -114,7 +221,7 @@ func (svc *serviceIPTables) applyRule(rule iptables.Rule) error {
 }
 
 func (svc *serviceIPTables) removeRule(rule iptables.Rule) error {
-	if err := iptablesExec(rule.RemoveArgs()...); err != nil {
+	if err := iptablesExec(rule.IsIPv6(), rule.RemoveArgs()...); err != nil {
 		return err
 	}
 	for i := range svc.rules {
@@ -169,7 +276,11 @@ func makeIPTablesRules(opts Options) (rules []iptables.Rule) {
 		rules = append(rules, rule)
 	}
 
-	for _, ipNet := range protectedNetworks() {
+	if opts.TrafficAccounting {
+		rules = append(rules, makeAccountingRules(vpnNetwork)...)
+	}
+
+	for _, ipNet := range protectedNetworks(false) {
 		// Protect private networks rule
 		rule := iptables.AppendTo(chainForward).RuleSpec(
 			"--source", vpnNetwork, "--destination", ipNet.String(),
@@ -183,6 +294,14 @@ func makeIPTablesRules(opts Options) (rules []iptables.Rule) {
 		rules = append(rules, rule)
 	}
 
+	if opts.VPNNetwork6 != nil {
+		rules = append(rules, makeIP6TablesRules(opts)...)
+	}
+
+	if opts.UpstreamInterface != "" {
+		return append(rules, makeUpstreamRules(vpnNetwork, opts.UpstreamInterface)...)
+	}
+
 	// NAT forwarding rule
 	rule := iptables.AppendTo(chainPostRouting).RuleSpec("--source", vpnNetwork, "!", "--destination", vpnNetwork,
 		"--jump", "SNAT", "--to", opts.ProviderExtIP.String(),
@@ -196,8 +315,93 @@ func makeIPTablesRules(opts Options) (rules []iptables.Rule) {
 	return rules
 }
 
-func iptablesExec(args ...string) error {
-	args = append([]string{"/usr/sbin/iptables"}, args...)
+// makeIP6TablesRules forwards consumer IPv6 traffic with NAT66, as tunnel addresses are not routable.
+func makeIP6TablesRules(opts Options) (rules []iptables.Rule) {
+	vpnNetwork := opts.VPNNetwork6.String()
+
+	if opts.EnableDNSRedirect && opts.DNSIP6 != nil {
+		dnsIP := opts.DNSIP6.String()
+		for _, protocol := range []string{"udp", "tcp"} {
+			rules = append(rules,
+				iptables.AppendTo(chainPreRouting).RuleSpec(
+					"--source", vpnNetwork, "--destination", dnsIP, "--protocol", protocol, "--dport", strconv.Itoa(53),
+					"--jump", "REDIRECT",
+					"--to-ports", strconv.Itoa(opts.DNSPort),
+					"--table", "nat",
+				).IPv6(),
+				iptables.InsertAt(chainInput, 1).RuleSpec(
+					"--source", vpnNetwork, "--destination", dnsIP, "--protocol", protocol, "--dport", strconv.Itoa(opts.DNSPort),
+					"--jump", "ACCEPT",
+				).IPv6(),
+			)
+		}
+		rules = append(rules, iptables.InsertAt(chainInput, 1).RuleSpec(
+			"--source", vpnNetwork, "--destination", dnsIP, "--protocol", "ipv6-icmp", "--jump", "ACCEPT",
+		).IPv6())
+	}
+
+	for _, ipNet := range protectedNetworks(true) {
+		rules = append(rules,
+			iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork, "--destination", ipNet.String(), "--jump", "DROP").IPv6(),
+			iptables.AppendTo(chainInput).RuleSpec("--source", vpnNetwork, "--destination", ipNet.String(), "--jump", "DROP").IPv6(),
+		)
+	}
+
+	// Upstream tunnel is IPv4 only, so IPv6 traffic must not leave through the provider's own address.
+	if opts.UpstreamInterface != "" {
+		return append(rules, iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork, "--jump", "DROP").IPv6())
+	}
+
+	return append(rules,
+		iptables.AppendTo(chainPostRouting).RuleSpec("--source", vpnNetwork, "!", "--destination", vpnNetwork,
+			"--jump", "MASQUERADE",
+			"--table", "nat").IPv6(),
+		iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork, "--jump", "ACCEPT").IPv6(),
+		iptables.AppendTo(chainForward).RuleSpec("--destination", vpnNetwork, "--jump", "ACCEPT").IPv6(),
+	)
+}
+
+// makeUpstreamRules forwards consumer traffic of all protocols only through the upstream interface,
+// it is routed there by the upstream routing rules. Anything else is dropped,
+// so consumer traffic never leaves through the provider's own address, even if the upstream goes down.
+func makeUpstreamRules(vpnNetwork string, iface string) []iptables.Rule {
+	return []iptables.Rule{
+		iptables.AppendTo(chainPostRouting).RuleSpec("--source", vpnNetwork, "--out-interface", iface,
+			"--jump", "MASQUERADE",
+			"--table", "nat"),
+		iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork, "--out-interface", iface, "--jump", "ACCEPT"),
+		iptables.AppendTo(chainForward).RuleSpec("--destination", vpnNetwork, "--in-interface", iface, "--jump", "ACCEPT"),
+		iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork, "--jump", "DROP"),
+	}
+}
+
+// makePortForwardRules forwards ports addressed to the provider address only,
+// so the same port on other host addresses stays available to the host.
+func makePortForwardRules(forwards []PortForward) (rules []iptables.Rule) {
+	for _, forward := range forwards {
+		port := strconv.Itoa(forward.Port)
+		rules = append(rules,
+			iptables.AppendTo(chainPreRouting).RuleSpec(
+				"--destination", forward.Address.String(), "--protocol", forward.Protocol, "--dport", port,
+				"--jump", "DNAT",
+				"--to-destination", net.JoinHostPort(forward.Consumer.String(), port),
+				"--table", "nat",
+			),
+			iptables.InsertAt(chainForward, 1).RuleSpec(
+				"--destination", forward.Consumer.String(), "--protocol", forward.Protocol, "--dport", port,
+				"--jump", "ACCEPT",
+			),
+		)
+	}
+	return rules
+}
+
+func iptablesExec(ipv6 bool, args ...string) error {
+	executable := "/usr/sbin/iptables"
+	if ipv6 {
+		executable = "/usr/sbin/ip6tables"
+	}
+	args = append([]string{executable}, args...)
 	if err := cmdutil.SudoExec(args...); err != nil {
 		return errors.Wrap(err, "error calling IPTables")
 	}
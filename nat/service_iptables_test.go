@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakePortForwardRules(t *testing.T) {
+	rules := makePortForwardRules([]PortForward{
+		{Protocol: "tcp", Port: 40001, Consumer: net.ParseIP("10.182.0.2"), Address: net.ParseIP("192.168.1.10")},
+		{Protocol: "udp", Port: 40002, Consumer: net.ParseIP("10.182.0.2"), Address: net.ParseIP("192.168.1.10")},
+	})
+
+	var args [][]string
+	for _, rule := range rules {
+		args = append(args, rule.ApplyArgs())
+	}
+	assert.Equal(t, [][]string{
+		{
+			"-A", "PREROUTING", "--destination", "192.168.1.10", "--protocol", "tcp", "--dport", "40001",
+			"--jump", "DNAT", "--to-destination", "10.182.0.2:40001", "--table", "nat",
+		},
+		{"-I", "FORWARD", "1", "--destination", "10.182.0.2", "--protocol", "tcp", "--dport", "40001", "--jump", "ACCEPT"},
+		{
+			"-A", "PREROUTING", "--destination", "192.168.1.10", "--protocol", "udp", "--dport", "40002",
+			"--jump", "DNAT", "--to-destination", "10.182.0.2:40002", "--table", "nat",
+		},
+		{"-I", "FORWARD", "1", "--destination", "10.182.0.2", "--protocol", "udp", "--dport", "40002", "--jump", "ACCEPT"},
+	}, args)
+}
+
+func TestMakeIPTablesRulesWithUpstream(t *testing.T) {
+	_, network, _ := net.ParseCIDR("10.182.0.0/24")
+	rules := makeIPTablesRules(Options{
+		VPNNetwork:        *network,
+		ProviderExtIP:     net.ParseIP("1.2.3.4"),
+		UpstreamInterface: "myst-upstream",
+	})
+
+	var args [][]string
+	for _, rule := range rules {
+		args = append(args, rule.ApplyArgs())
+	}
+	assert.Contains(t, args, []string{
+		"-A", "POSTROUTING", "--source", "10.182.0.0/24", "--out-interface", "myst-upstream",
+		"--jump", "MASQUERADE", "--table", "nat",
+	})
+	assert.Equal(t, [][]string{
+		{"-A", "FORWARD", "--source", "10.182.0.0/24", "--out-interface", "myst-upstream", "--jump", "ACCEPT"},
+		{"-A", "FORWARD", "--destination", "10.182.0.0/24", "--in-interface", "myst-upstream", "--jump", "ACCEPT"},
+		{"-A", "FORWARD", "--source", "10.182.0.0/24", "--jump", "DROP"},
+	}, args[len(args)-3:])
+	for _, arg := range args {
+		assert.NotContains(t, arg, "SNAT")
+		assert.NotContains(t, arg, "REDIRECT")
+		if arg[1] == chainForward && arg[len(arg)-1] == "ACCEPT" {
+			assert.Contains(t, arg, "myst-upstream", "consumer traffic must be forwarded only through upstream: %v", arg)
+		}
+	}
+}
+
+func TestMakeIPTablesRulesWithIPv6(t *testing.T) {
+	_, network, _ := net.ParseCIDR("10.182.1.0/24")
+	_, network6, _ := net.ParseCIDR("fd6d:7973:7400:1::/64")
+	rules := makeIPTablesRules(Options{
+		VPNNetwork:        *network,
+		ProviderExtIP:     net.ParseIP("1.2.3.4"),
+		EnableDNSRedirect: true,
+		DNSIP:             net.ParseIP("10.182.1.1"),
+		DNSPort:           11253,
+		VPNNetwork6:       network6,
+		DNSIP6:            net.ParseIP("fd6d:7973:7400:1::1"),
+	})
+
+	var args6 [][]string
+	for _, rule := range rules {
+		if rule.IsIPv6() {
+			args6 = append(args6, rule.ApplyArgs())
+		} else {
+			assert.NotContains(t, rule.ApplyArgs(), "fd6d:7973:7400:1::/64")
+		}
+	}
+	assert.Contains(t, args6, []string{
+		"-A", "POSTROUTING", "--source", "fd6d:7973:7400:1::/64", "!", "--destination", "fd6d:7973:7400:1::/64",
+		"--jump", "MASQUERADE", "--table", "nat",
+	})
+	assert.Contains(t, args6, []string{
+		"-A", "PREROUTING", "--source", "fd6d:7973:7400:1::/64", "--destination", "fd6d:7973:7400:1::1", "--protocol", "udp", "--dport", "53",
+		"--jump", "REDIRECT", "--to-ports", "11253", "--table", "nat",
+	})
+	assert.Contains(t, args6, []string{"-A", "FORWARD", "--source", "fd6d:7973:7400:1::/64", "--jump", "ACCEPT"})
+	assert.Contains(t, args6, []string{"-A", "FORWARD", "--destination", "fd6d:7973:7400:1::/64", "--jump", "ACCEPT"})
+}
+
+func TestAcceptRouterAdvertisements(t *testing.T) {
+	var commands []string
+	outputs := map[string]string{
+		"ip -6 route show default":                         "default via fe80::1 dev eth0.100 proto ra metric 1024 pref medium\n",
+		"/sbin/sysctl -n net/ipv6/conf/eth0.100/accept_ra": "1\n",
+	}
+	factory := func(name string, arg ...string) Command {
+		command := strings.Join(append([]string{name}, arg...), " ")
+		commands = append(commands, command)
+		return &mockCommand{OutputRes: []byte(outputs[command])}
+	}
+
+	restore, err := acceptRouterAdvertisements(factory)
+	assert.NoError(t, err)
+	assert.Contains(t, commands, "sudo /sbin/sysctl -w net/ipv6/conf/eth0.100/accept_ra=2")
+
+	restore()
+	assert.Contains(t, commands, "sudo /sbin/sysctl -w net/ipv6/conf/eth0.100/accept_ra=1")
+
+	outputs["ip -6 route show default"] = ""
+	restore, err = acceptRouterAdvertisements(factory)
+	assert.NoError(t, err)
+	assert.Nil(t, restore)
+}
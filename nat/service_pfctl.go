@@ -36,6 +36,13 @@ type servicePFCtl struct {
 
 // Setup sets NAT/Firewall rules for the given NATOptions.
 func (service *servicePFCtl) Setup(opts Options) (appliedRules []interface{}, err error) {
+	if opts.UpstreamInterface != "" {
+		return nil, ErrUpstreamNotSupported
+	}
+	if opts.VPNNetwork6 != nil {
+		log.Warn().Msg("IPv6 forwarding is not supported by pfctl, consumer IPv6 traffic will not be forwarded")
+	}
+
 	log.Info().Msg("Setting up NAT/Firewall rules")
 	service.mu.Lock()
 	defer service.mu.Unlock()
@@ -53,6 +60,11 @@ func (service *servicePFCtl) Setup(opts Options) (appliedRules []interface{}, er
 	return untypedPfctlRules(rules), nil
 }
 
+// ForwardPorts is not supported by pfctl NAT service.
+func (service *servicePFCtl) ForwardPorts(_ []PortForward) ([]interface{}, error) {
+	return nil, ErrPortForwardingNotSupported
+}
+
 func (service *servicePFCtl) Del(rules []interface{}) error {
 	log.Info().Msg("Deleting NAT/Firewall rules")
 	service.mu.Lock()
@@ -105,7 +117,7 @@ func makePfctlRules(opts Options) (rules []string, err error) {
 	}
 
 	// Protect private networks rule
-	networks := protectedNetworks()
+	networks := protectedNetworks(false)
 	if len(networks) > 0 {
 		var targets []string
 		for _, network := range networks {
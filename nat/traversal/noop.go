@@ -44,7 +44,7 @@ func (np *NoopPinger) PingProviderPeer(ctx context.Context, ip string, localPort
 
 // PingConsumerPeer does nothing.
 func (np *NoopPinger) PingConsumerPeer(ctx context.Context, id, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error) {
-	np.eventPublisher.Publish(event.AppTopicTraversal, event.BuildSuccessfulEvent(id, "noop_pinger"))
+	event.PublishTraversal(np.eventPublisher, event.BuildSuccessfulEvent(id, "noop_pinger"))
 	return []*net.UDPConn{}, nil
 }
 
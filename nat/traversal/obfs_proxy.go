@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traversal
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/obfs"
+)
+
+const obfsProxyBufferLen = 64 * 1024
+
+// ObfuscatedServiceConn starts proxy which obfuscates service traffic going through punched remote conn.
+// Returned loopback conn should be used by the service in place of remote conn: service closes it
+// and listens on its local port while proxy relays packets between that port and remote peer.
+// Service on the consumer side should use returned conn remote address as its peer endpoint.
+func ObfuscatedServiceConn(remoteConn *net.UDPConn, obfuscator obfs.Obfuscator) (serviceConn *net.UDPConn, stop func(), err error) {
+	proxyConn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create obfuscation proxy conn: %w", err)
+	}
+	serviceConn, err = net.DialUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, proxyConn.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		proxyConn.Close()
+		return nil, nil, fmt.Errorf("could not create service conn: %w", err)
+	}
+
+	p := &obfsProxy{
+		remoteConn:  remoteConn,
+		proxyConn:   proxyConn,
+		serviceAddr: serviceConn.LocalAddr().(*net.UDPAddr),
+		obfuscator:  obfuscator,
+	}
+	go p.remoteToService()
+	go p.serviceToRemote()
+
+	log.Debug().Msgf("Obfuscating service traffic from %s through proxy %s", p.serviceAddr, proxyConn.LocalAddr())
+	return serviceConn, p.stop, nil
+}
+
+type obfsProxy struct {
+	remoteConn  *net.UDPConn
+	proxyConn   *net.UDPConn
+	serviceAddr *net.UDPAddr
+	obfuscator  obfs.Obfuscator
+	once        sync.Once
+}
+
+func (p *obfsProxy) stop() {
+	p.once.Do(func() {
+		p.proxyConn.Close()
+		p.remoteConn.Close()
+	})
+}
+
+// remoteToService reads obfuscated packets from remote peer and passes restored packets to the service.
+func (p *obfsProxy) remoteToService() {
+	defer p.stop()
+
+	buf := make([]byte, obfsProxyBufferLen)
+	for {
+		n, err := p.remoteConn.Read(buf)
+		if err != nil {
+			log.Debug().Err(err).Msg("Obfuscation proxy stopped reading remote conn")
+			return
+		}
+		packet, err := p.obfuscator.Deobfuscate(buf[:n])
+		if err != nil {
+			log.Trace().Err(err).Msg("Dropping packet which could not be deobfuscated")
+			continue
+		}
+		if _, err := p.proxyConn.WriteToUDP(packet, p.serviceAddr); err != nil {
+			log.Debug().Err(err).Msg("Obfuscation proxy stopped writing to service")
+			return
+		}
+	}
+}
+
+// serviceToRemote reads packets sent by the service and passes obfuscated packets to remote peer.
+func (p *obfsProxy) serviceToRemote() {
+	defer p.stop()
+
+	buf := make([]byte, obfsProxyBufferLen)
+	for {
+		n, addr, err := p.proxyConn.ReadFromUDP(buf)
+		if err != nil {
+			log.Debug().Err(err).Msg("Obfuscation proxy stopped reading service conn")
+			return
+		}
+		if addr.Port != p.serviceAddr.Port {
+			continue
+		}
+		packet, err := p.obfuscator.Obfuscate(buf[:n])
+		if err != nil {
+			log.Warn().Err(err).Msg("Dropping packet which could not be obfuscated")
+			continue
+		}
+		if _, err := p.remoteConn.Write(packet); err != nil {
+			log.Debug().Err(err).Msg("Obfuscation proxy stopped writing to remote conn")
+			return
+		}
+	}
+}
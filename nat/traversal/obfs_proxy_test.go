@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traversal
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/obfs"
+)
+
+func TestObfuscatedServiceConn(t *testing.T) {
+	ports, err := port.NewPool().AcquireMultiple(2)
+	require.NoError(t, err)
+	providerAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: ports[0].Num()}
+	consumerAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: ports[1].Num()}
+	providerRemote, err := net.DialUDP("udp4", providerAddr, consumerAddr)
+	require.NoError(t, err)
+	consumerRemote, err := net.DialUDP("udp4", consumerAddr, providerAddr)
+	require.NoError(t, err)
+
+	secret := obfs.DeriveSecret([]byte("shared key"), "service")
+	providerObfs, err := obfs.New(obfs.MethodScramble, secret)
+	require.NoError(t, err)
+	consumerObfs, err := obfs.New(obfs.MethodScramble, secret)
+	require.NoError(t, err)
+
+	providerConn, stopProvider, err := ObfuscatedServiceConn(providerRemote, providerObfs)
+	require.NoError(t, err)
+	defer stopProvider()
+	consumerConn, stopConsumer, err := ObfuscatedServiceConn(consumerRemote, consumerObfs)
+	require.NoError(t, err)
+	defer stopConsumer()
+
+	// Services take over local ports of returned conns.
+	providerConn.Close()
+	providerService, err := net.ListenUDP("udp4", providerConn.LocalAddr().(*net.UDPAddr))
+	require.NoError(t, err)
+	defer providerService.Close()
+	consumerConn.Close()
+	consumerService, err := net.DialUDP("udp4", consumerConn.LocalAddr().(*net.UDPAddr), consumerConn.RemoteAddr().(*net.UDPAddr))
+	require.NoError(t, err)
+	defer consumerService.Close()
+
+	_, err = consumerService.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	providerService.SetReadDeadline(time.Now().Add(time.Second))
+	n, addr, err := providerService.ReadFromUDP(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf[:n]))
+
+	_, err = providerService.WriteToUDP([]byte("pong"), addr)
+	require.NoError(t, err)
+
+	consumerService.SetReadDeadline(time.Now().Add(time.Second))
+	n, err = consumerService.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(buf[:n]))
+}
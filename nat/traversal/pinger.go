@@ -20,6 +20,7 @@ package traversal
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"net"
 	"sort"
 	"sync"
@@ -41,6 +42,12 @@ const (
 	maxTTL   = 128
 	msgOK    = "OK"
 	msgOKACK = "OK_ACK"
+
+	// sprayPortMin and sprayPortMax define range of ports NAT usually assigns for external mappings.
+	sprayPortMin = 1024
+	sprayPortMax = 65535
+	// MaxSprayPorts limits number of random remote ports pinged from every local port by birthday strategy.
+	MaxSprayPorts = 1024
 )
 
 var (
@@ -56,11 +63,48 @@ type NATPinger interface {
 	SetProtectSocketCallback(SocketProtect func(socket int) bool)
 }
 
+// Strategy defines how NAT pinger tries to punch a hole to the remote peer.
+type Strategy string
+
+const (
+	// StrategyDefault pings only remote ports announced by peer during config exchange.
+	StrategyDefault Strategy = "default"
+	// StrategyBirthday additionally sprays pings to random remote ports. It increases chance
+	// to punch a hole when peer is behind symmetric NAT which assigns random external ports.
+	StrategyBirthday Strategy = "birthday"
+)
+
+// ParseStrategy parses NAT traversal strategy from its name.
+func ParseStrategy(name string) (Strategy, error) {
+	switch s := Strategy(name); s {
+	case StrategyDefault, StrategyBirthday:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown NAT traversal strategy %q", name)
+}
+
 // PingConfig represents NAT pinger config.
 type PingConfig struct {
 	Interval            time.Duration
 	Timeout             time.Duration
 	SendConnACKInterval time.Duration
+
+	// Strategy is hole punching strategy used by pinger.
+	Strategy Strategy
+	// MaxPorts is a number of local ports used for pinging when peer is behind NAT.
+	MaxPorts int
+	// ProviderInitialTTL is TTL provider starts punching a hole with. It is kept low
+	// to open provider NAT mapping without hitting consumer NAT.
+	ProviderInitialTTL int
+	// ConsumerInitialTTL is TTL consumer starts punching a hole with.
+	ConsumerInitialTTL int
+	// TTLStep is TTL increase for every next pinged port.
+	TTLStep int
+	// SprayPorts is a number of random remote ports pinged from every local port by birthday strategy.
+	SprayPorts int
+	// PreferPortForwarding makes provider skip UPnP mapping and pinging assuming that
+	// p2p ports are forwarded manually on the router.
+	PreferPortForwarding bool
 }
 
 // DefaultPingConfig returns default NAT pinger config.
@@ -69,6 +113,12 @@ func DefaultPingConfig() *PingConfig {
 		Interval:            5 * time.Millisecond,
 		Timeout:             10 * time.Second,
 		SendConnACKInterval: 100 * time.Millisecond,
+		Strategy:            StrategyDefault,
+		MaxPorts:            20,
+		ProviderInitialTTL:  defaultProviderInitialTTL,
+		ConsumerInitialTTL:  maxTTL,
+		TTLStep:             1,
+		SprayPorts:          64,
 	}
 }
 
@@ -153,12 +203,12 @@ func (p *Pinger) PingConsumerPeer(ctx context.Context, id string, ip string, loc
 	for {
 		select {
 		case <-ctx.Done():
-			p.eventPublisher.Publish(event.AppTopicTraversal, event.BuildFailureEvent(id, StageName, ctx.Err()))
+			event.PublishTraversal(p.eventPublisher, event.BuildFailureEvent(id, StageName, ctx.Err()))
 			return nil, fmt.Errorf("ping failed: %w", ctx.Err())
 		case ping := <-pingsCh:
 			pings = append(pings, ping)
 			if len(pings) == n {
-				p.eventPublisher.Publish(event.AppTopicTraversal, event.BuildSuccessfulEvent(id, StageName))
+				event.PublishTraversal(p.eventPublisher, event.BuildSuccessfulEvent(id, StageName))
 				return sortedConns(pings), nil
 			}
 		}
@@ -283,13 +333,15 @@ func sendMsg(conn *net.UDPConn, msg string) {
 	conn.Write([]byte(msg))
 }
 
-func (p *Pinger) ping(ctx context.Context, conn *net.UDPConn, remoteAddr *net.UDPAddr, ttl int, pingReceived <-chan struct{}) error {
+// ping sends pings to the remote address every interval. Spray addresses take turns,
+// one of them is pinged per interval, so spraying at most doubles the packet rate.
+func (p *Pinger) ping(ctx context.Context, conn *net.UDPConn, remoteAddr *net.UDPAddr, sprayAddrs []*net.UDPAddr, ttl int, pingReceived <-chan struct{}) error {
 	err := ipv4.NewConn(conn).SetTTL(ttl)
 	if err != nil {
 		return fmt.Errorf("pinger setting ttl failed: %w", err)
 	}
 
-	for {
+	for i := 0; ; i++ {
 		select {
 		case <-ctx.Done():
 			return nil
@@ -299,11 +351,17 @@ func (p *Pinger) ping(ctx context.Context, conn *net.UDPConn, remoteAddr *net.UD
 			return nil
 
 		case <-time.After(p.pingConfig.Interval):
-			log.Trace().Msgf("Pinging %s from %s... with ttl %d", remoteAddr, conn.LocalAddr(), ttl)
+			addrs := []*net.UDPAddr{remoteAddr}
+			if len(sprayAddrs) > 0 {
+				addrs = append(addrs, sprayAddrs[i%len(sprayAddrs)])
+			}
+			for _, addr := range addrs {
+				log.Trace().Msgf("Pinging %s from %s... with ttl %d", addr, conn.LocalAddr(), ttl)
 
-			_, err := conn.WriteToUDP([]byte("continuously pinging to "+remoteAddr.String()), remoteAddr)
-			if err != nil {
-				return fmt.Errorf("pinging request failed: %w", err)
+				_, err := conn.WriteToUDP([]byte("continuously pinging to "+addr.String()), addr)
+				if err != nil {
+					return fmt.Errorf("pinging request failed: %w", err)
+				}
 			}
 		}
 	}
@@ -364,8 +422,12 @@ func (p *Pinger) multiPingN(ctx context.Context, ip string, localPorts, remotePo
 
 	var wg sync.WaitGroup
 	ch := make(chan pingResponse, len(localPorts))
+	step := p.pingConfig.TTLStep
+	if step < 1 {
+		step = 1
+	}
 	ttl := initialTTL
-	resetTTL := initialTTL + (len(localPorts) / n)
+	resetTTL := initialTTL + step*(len(localPorts)/n)
 
 	for i := range localPorts {
 		wg.Add(1)
@@ -378,9 +440,12 @@ func (p *Pinger) multiPingN(ctx context.Context, ip string, localPorts, remotePo
 
 		// TTL increase is only needed for provider side which starts with low TTL value.
 		if ttl < maxTTL {
-			ttl++
+			ttl += step
+			if ttl > maxTTL {
+				ttl = maxTTL
+			}
 		}
-		if ttl == resetTTL {
+		if ttl >= resetTTL && ttl < maxTTL {
 			ttl = initialTTL
 		}
 	}
@@ -405,7 +470,7 @@ func (p *Pinger) singlePing(ctx context.Context, remoteIP string, localPort, rem
 
 	pingReceived := make(chan struct{}, 1)
 	go func() {
-		err := p.ping(ctx, conn, remoteAddr, ttl, pingReceived)
+		err := p.ping(ctx, conn, remoteAddr, p.sprayAddrs(remoteAddr), ttl, pingReceived)
 		if err != nil {
 			log.Warn().Err(err).Msg("Error while pinging")
 		}
@@ -423,3 +488,24 @@ func (p *Pinger) singlePing(ctx context.Context, remoteIP string, localPort, rem
 
 	return net.DialUDP("udp4", laddr, raddr)
 }
+
+// sprayAddrs returns random remote peer addresses which should be pinged in addition
+// to announced remote address when birthday strategy is used.
+func (p *Pinger) sprayAddrs(remoteAddr *net.UDPAddr) []*net.UDPAddr {
+	if p.pingConfig.Strategy != StrategyBirthday {
+		return nil
+	}
+
+	n := p.pingConfig.SprayPorts
+	if n > MaxSprayPorts {
+		n = MaxSprayPorts
+	}
+	var addrs []*net.UDPAddr
+	for i := 0; i < n; i++ {
+		addrs = append(addrs, &net.UDPAddr{
+			IP:   remoteAddr.IP,
+			Port: sprayPortMin + rand.Intn(sprayPortMax-sprayPortMin+1),
+		})
+	}
+	return addrs
+}
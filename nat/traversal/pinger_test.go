@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -136,12 +137,33 @@ func TestPinger_PingConsumerPeer_Timeout(t *testing.T) {
 	assert.EqualError(t, err, "ping failed: context deadline exceeded")
 }
 
-func newPinger(config *PingConfig) NATPinger {
-	return NewPinger(config, &mockPublisher{})
+func TestPinger_SprayAddrs(t *testing.T) {
+	remoteAddr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 5000}
+
+	pinger := &Pinger{pingConfig: &PingConfig{Strategy: StrategyDefault, SprayPorts: 10}}
+	assert.Empty(t, pinger.sprayAddrs(remoteAddr))
+
+	pinger = &Pinger{pingConfig: &PingConfig{Strategy: StrategyBirthday, SprayPorts: 10}}
+	addrs := pinger.sprayAddrs(remoteAddr)
+	assert.Len(t, addrs, 10)
+	for _, addr := range addrs {
+		assert.Equal(t, remoteAddr.IP, addr.IP)
+		assert.True(t, addr.Port >= sprayPortMin && addr.Port <= sprayPortMax)
+	}
+
+	pinger = &Pinger{pingConfig: &PingConfig{Strategy: StrategyBirthday, SprayPorts: MaxSprayPorts + 1}}
+	assert.Len(t, pinger.sprayAddrs(remoteAddr), MaxSprayPorts)
 }
 
-type mockPublisher struct {
+func TestParseStrategy(t *testing.T) {
+	strategy, err := ParseStrategy("birthday")
+	assert.NoError(t, err)
+	assert.Equal(t, StrategyBirthday, strategy)
+
+	_, err = ParseStrategy("unknown")
+	assert.EqualError(t, err, `unknown NAT traversal strategy "unknown"`)
 }
 
-func (p mockPublisher) Publish(topic string, data interface{}) {
+func newPinger(config *PingConfig) NATPinger {
+	return NewPinger(config, eventbus.Tap(func(string, interface{}) {}))
 }
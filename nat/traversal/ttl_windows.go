@@ -15,7 +15,7 @@
  * along with this program.  If not, see <http://www.gnu.org/licenses/>.
  */
 
-package p2p
+package traversal
 
-//providerInitialTTL initial ttl to start punching a hole with. Minimal value us 2 is needed to make windows network stack happy
-const providerInitialTTL = 2
+//defaultProviderInitialTTL initial ttl to start punching a hole with. Minimal value us 2 is needed to make windows network stack happy
+const defaultProviderInitialTTL = 2
@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package obfs
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// Method identifies datagram obfuscation method.
+type Method string
+
+const (
+	// MethodNone sends datagrams as is.
+	MethodNone Method = "none"
+	// MethodScramble encrypts whole datagram including protocol headers with
+	// a random IV and adds random padding to hide packet sizes.
+	MethodScramble Method = "scramble"
+)
+
+// Obfuscator transforms datagrams so they can't be fingerprinted by DPI.
+type Obfuscator interface {
+	// Obfuscate returns obfuscated copy of the given datagram.
+	Obfuscate(packet []byte) ([]byte, error)
+	// Deobfuscate restores original datagram from obfuscated one.
+	Deobfuscate(packet []byte) ([]byte, error)
+}
+
+// ParseMethods parses obfuscation methods from their names skipping "none".
+func ParseMethods(names []string) ([]Method, error) {
+	var methods []Method
+	for _, name := range names {
+		switch m := Method(name); m {
+		case MethodNone:
+		case MethodScramble:
+			methods = append(methods, m)
+		default:
+			return nil, fmt.Errorf("unknown obfuscation method %q", name)
+		}
+	}
+	return methods, nil
+}
+
+// Negotiate returns the first of preferred methods which is supported by peer.
+// MethodNone is returned if there is no common method.
+func Negotiate(preferred, supported []Method) Method {
+	for _, p := range preferred {
+		for _, s := range supported {
+			if p == s {
+				return p
+			}
+		}
+	}
+	return MethodNone
+}
+
+// New creates obfuscator for given method. Both peers must use the same secret
+// which is usually derived from shared channel key.
+func New(method Method, secret []byte) (Obfuscator, error) {
+	switch method {
+	case MethodNone, "":
+		return nil, nil
+	case MethodScramble:
+		s, err := newScrambler(secret)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown obfuscation method %q", method)
+}
+
+// DeriveSecret derives obfuscation secret for given purpose from shared key so
+// different streams between the same peers are obfuscated with different keys.
+func DeriveSecret(sharedKey []byte, purpose string) []byte {
+	h := sha256.New()
+	h.Write([]byte("myst-obfs-" + purpose))
+	h.Write(sharedKey)
+	return h.Sum(nil)
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package obfs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrambler_RoundTrip(t *testing.T) {
+	secret := DeriveSecret([]byte("shared key"), "test")
+	o, err := New(MethodScramble, secret)
+	assert.NoError(t, err)
+
+	packet := []byte("\x01\x00\x00\x00 wireguard handshake initiation")
+	obfuscated, err := o.Obfuscate(packet)
+	assert.NoError(t, err)
+	assert.False(t, bytes.Contains(obfuscated, packet))
+	assert.True(t, len(obfuscated) >= len(packet)+scrambleIVSize+scrambleLenSize)
+
+	peer, err := New(MethodScramble, secret)
+	assert.NoError(t, err)
+	res, err := peer.Deobfuscate(obfuscated)
+	assert.NoError(t, err)
+	assert.Equal(t, packet, res)
+}
+
+func TestScrambler_Padding_Does_Not_Exceed_Datagram_Limit(t *testing.T) {
+	o, err := New(MethodScramble, DeriveSecret([]byte("shared key"), "test"))
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		obfuscated, err := o.Obfuscate(make([]byte, 1440))
+		assert.NoError(t, err)
+		assert.True(t, len(obfuscated) <= scrambleMaxDatagram)
+	}
+}
+
+func TestScrambler_Deobfuscate_Short_Packet(t *testing.T) {
+	o, err := New(MethodScramble, DeriveSecret([]byte("shared key"), "test"))
+	assert.NoError(t, err)
+
+	_, err = o.Deobfuscate([]byte("short"))
+	assert.Equal(t, errShortPacket, err)
+}
+
+func TestNegotiate(t *testing.T) {
+	assert.Equal(t, MethodScramble, Negotiate([]Method{MethodScramble}, []Method{MethodScramble}))
+	assert.Equal(t, MethodNone, Negotiate([]Method{MethodScramble}, nil))
+	assert.Equal(t, MethodNone, Negotiate(nil, []Method{MethodScramble}))
+}
+
+func TestParseMethods(t *testing.T) {
+	methods, err := ParseMethods([]string{"none", "scramble"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Method{MethodScramble}, methods)
+
+	_, err = ParseMethods([]string{"rot13"})
+	assert.EqualError(t, err, `unknown obfuscation method "rot13"`)
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package obfs
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	mrand "math/rand"
+)
+
+const (
+	scrambleIVSize  = aes.BlockSize
+	scrambleLenSize = 2
+	// scrambleMaxPadding is max random padding added to every datagram.
+	scrambleMaxPadding = 64
+	// scrambleMaxDatagram is datagram size which padding should not exceed to avoid IP fragmentation.
+	scrambleMaxDatagram = 1472
+)
+
+var errShortPacket = errors.New("obfuscated packet is too short")
+
+// scrambler encrypts datagram with AES-CTR using random IV per packet. Layout of obfuscated datagram:
+// IV | encrypted(payload length | payload | random padding).
+// Underlying protocols authenticate their payload so scrambler only hides them from DPI.
+type scrambler struct {
+	block cipher.Block
+}
+
+func newScrambler(secret []byte) (*scrambler, error) {
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("obfuscation secret is too short: %d", len(secret))
+	}
+	block, err := aes.NewCipher(secret[:32])
+	if err != nil {
+		return nil, fmt.Errorf("could not create cipher: %w", err)
+	}
+	return &scrambler{block: block}, nil
+}
+
+func (s *scrambler) Obfuscate(packet []byte) ([]byte, error) {
+	if len(packet) > 0xffff {
+		return nil, fmt.Errorf("packet is too big: %d", len(packet))
+	}
+
+	size := scrambleIVSize + scrambleLenSize + len(packet)
+	padding := 0
+	if room := scrambleMaxDatagram - size; room > 0 {
+		padding = mrand.Intn(min(room, scrambleMaxPadding) + 1)
+	}
+
+	out := make([]byte, size+padding)
+	iv := out[:scrambleIVSize]
+	if _, err := rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("could not generate IV: %w", err)
+	}
+	body := out[scrambleIVSize:]
+	binary.BigEndian.PutUint16(body, uint16(len(packet)))
+	copy(body[scrambleLenSize:], packet)
+	if _, err := rand.Read(body[scrambleLenSize+len(packet):]); err != nil {
+		return nil, fmt.Errorf("could not generate padding: %w", err)
+	}
+
+	cipher.NewCTR(s.block, iv).XORKeyStream(body, body)
+	return out, nil
+}
+
+func (s *scrambler) Deobfuscate(packet []byte) ([]byte, error) {
+	if len(packet) < scrambleIVSize+scrambleLenSize {
+		return nil, errShortPacket
+	}
+
+	iv := packet[:scrambleIVSize]
+	body := make([]byte, len(packet)-scrambleIVSize)
+	cipher.NewCTR(s.block, iv).XORKeyStream(body, packet[scrambleIVSize:])
+
+	n := int(binary.BigEndian.Uint16(body))
+	if n > len(body)-scrambleLenSize {
+		return nil, errShortPacket
+	}
+	return body[scrambleLenSize : scrambleLenSize+n], nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -29,9 +29,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/obfs"
 	"github.com/mysteriumnetwork/node/trace"
 	"github.com/rs/zerolog/log"
 	kcp "github.com/xtaci/kcp-go/v5"
+	"golang.org/x/crypto/curve25519"
 	"golang.org/x/crypto/nacl/box"
 )
 
@@ -123,8 +127,9 @@ type transport struct {
 	session *kcp.UDPSession
 
 	// remoteConn is initial conn which should be created from NAT hole punching or manually. It contains
-	// initial local and remote peer addresses.
-	remoteConn *net.UDPConn
+	// initial local and remote peer addresses. It is replaced when channel is migrated to new addresses.
+	remoteConn   *net.UDPConn
+	remoteConnMu sync.RWMutex
 
 	// proxyConn is used for KCP session as a remote. Since KCP doesn't expose it's data read loop
 	// this is needed to detect remote peer address changes as we can simply use conn.ReadFromUDP and
@@ -179,6 +184,18 @@ type channel struct {
 
 	// terminate remote aliveness checking only once
 	remoteAliveOnce sync.Once
+
+	// onClose is called once channel is closed.
+	onClose func()
+
+	// obfuscation is negotiated obfuscation method applied to channel and service traffic.
+	obfuscation obfs.Method
+
+	// obfuscator wraps packets sent to and received from remote peer. Nil if obfuscation is disabled.
+	obfuscator obfs.Obfuscator
+
+	// serviceProxyStop stops service traffic obfuscation proxy if it was started.
+	serviceProxyStop func()
 }
 
 // newChannel creates new p2p channel with initialized crypto primitives for data encryption
@@ -235,7 +252,8 @@ func newChannel(remoteConn *net.UDPConn, privateKey PrivateKey, peerPubKey Publi
 }
 
 func (c *channel) launchReadSendLoops() {
-	go c.remoteReadLoop()
+	go c.checkIfChannelAlive()
+	go c.remoteReadLoop(c.remoteConn())
 	go c.remoteSendLoop()
 	go c.localReadLoop()
 	go c.localSendLoop()
@@ -243,12 +261,11 @@ func (c *channel) launchReadSendLoops() {
 
 // remoteReadLoop reads from remote conn and writes to local KCP UDP conn.
 // If remote peer addr changes it will be updated and next send will use new addr.
-func (c *channel) remoteReadLoop() {
+// Loop exits when given conn is replaced by channel migration.
+func (c *channel) remoteReadLoop(conn *net.UDPConn) {
 	buf := make([]byte, mtuLimit)
 	latestPeerAddr := c.peer.addr()
 
-	go c.checkIfChannelAlive()
-
 	for {
 		select {
 		case <-c.stop:
@@ -256,14 +273,23 @@ func (c *channel) remoteReadLoop() {
 		default:
 		}
 
-		n, addr, err := c.tr.remoteConn.ReadFrom(buf)
+		n, addr, err := conn.ReadFrom(buf)
 		if err != nil {
-			if !errNetClose(err) {
+			if !errNetClose(err) && conn == c.remoteConn() {
 				log.Error().Err(err).Msg("Read from remote conn failed")
 			}
 			return
 		}
 
+		packet := buf[:n]
+		if c.obfuscator != nil {
+			packet, err = c.obfuscator.Deobfuscate(packet)
+			if err != nil {
+				log.Trace().Err(err).Msg("Dropping packet which could not be deobfuscated")
+				continue
+			}
+		}
+
 		c.remoteAliveOnce.Do(func() {
 			close(c.remoteAlive)
 		})
@@ -277,7 +303,7 @@ func (c *channel) remoteReadLoop() {
 			}
 		}
 
-		_, err = c.tr.proxyConn.WriteToUDP(buf[:n], c.localSessionAddr)
+		_, err = c.tr.proxyConn.WriteToUDP(packet, c.localSessionAddr)
 		if err != nil {
 			if !errNetClose(err) {
 				log.Error().Err(err).Msg("Write to local udp session failed")
@@ -306,8 +332,22 @@ func (c *channel) remoteSendLoop() {
 			return
 		}
 
-		_, err = c.tr.remoteConn.WriteToUDP(buf[:n], c.peer.addr())
+		packet := buf[:n]
+		if c.obfuscator != nil {
+			packet, err = c.obfuscator.Obfuscate(packet)
+			if err != nil {
+				log.Error().Err(err).Msg("Could not obfuscate packet")
+				continue
+			}
+		}
+
+		conn := c.remoteConn()
+		_, err = conn.WriteToUDP(packet, c.peer.addr())
 		if err != nil {
+			if conn != c.remoteConn() {
+				// Conn was replaced during migration, next packets will go through the new one.
+				continue
+			}
 			if !errNetClose(err) {
 				log.Error().Err(err).Msgf("Write to remote peer conn failed")
 			}
@@ -411,6 +451,9 @@ func (c *channel) handleRequest(msg *transportMsg) {
 	} else if ctx.publicError != nil {
 		log.Err(ctx.publicError).Msgf("Handler %q public error", msg.topic)
 		resMsg.statusCode = statusCodePublicErr
+		if errors.Is(ctx.publicError, market.ErrProposalOutdated) {
+			resMsg.statusCode = statusCodeProposalOutdated
+		}
 		resMsg.data = []byte(ctx.publicError.Error())
 	} else {
 		resMsg.statusCode = statusCodeOK
@@ -428,6 +471,9 @@ func (c *channel) Tracer() *trace.Tracer {
 
 // ServiceConn returns UDP connection which can be used for services.
 func (c *channel) ServiceConn() *net.UDPConn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return c.serviceConn
 }
 
@@ -443,7 +489,7 @@ func (c *channel) Close() error {
 			release()
 		}
 
-		if err := c.tr.remoteConn.Close(); err != nil {
+		if err := c.remoteConn().Close(); err != nil {
 			closeErr = fmt.Errorf("could not close remote conn: %w", err)
 		}
 
@@ -455,6 +501,10 @@ func (c *channel) Close() error {
 			closeErr = fmt.Errorf("could not close p2p transport session: %w", err)
 		}
 
+		if c.serviceProxyStop != nil {
+			c.serviceProxyStop()
+		}
+
 		if c.serviceConn != nil {
 			if err := c.serviceConn.Close(); err != nil {
 				if errors.Is(err, errors.New("use of closed network connection")) { // Have to check this error as a string match https://github.com/golang/go/issues/4373
@@ -462,6 +512,10 @@ func (c *channel) Close() error {
 				}
 			}
 		}
+
+		if c.onClose != nil {
+			go c.onClose()
+		}
 	})
 
 	return closeErr
@@ -469,9 +523,56 @@ func (c *channel) Close() error {
 
 // Conn returns underlying channel's UDP connection.
 func (c *channel) Conn() *net.UDPConn {
+	return c.remoteConn()
+}
+
+func (c *channel) remoteConn() *net.UDPConn {
+	c.tr.remoteConnMu.RLock()
+	defer c.tr.remoteConnMu.RUnlock()
+
 	return c.tr.remoteConn
 }
 
+// rebind replaces channel's remote conn with new one created from NAT hole punching or manually.
+// KCP session, keys, handlers and pending streams are kept so channel users are not affected.
+func (c *channel) rebind(conn *net.UDPConn) error {
+	peerAddr := conn.RemoteAddr().(*net.UDPAddr)
+	conn, err := reopenConn(conn)
+	if err != nil {
+		return fmt.Errorf("could not reopen remote conn: %w", err)
+	}
+
+	select {
+	case <-c.stop:
+		conn.Close()
+		return errors.New("channel is closed")
+	default:
+	}
+
+	c.tr.remoteConnMu.Lock()
+	oldConn := c.tr.remoteConn
+	c.tr.remoteConn = conn
+	c.tr.remoteConnMu.Unlock()
+
+	c.peer.updateAddr(peerAddr)
+	oldConn.Close()
+
+	log.Debug().Msgf("Migrated p2p channel to local addr: %s, remote peer addr: x.x.x.x:%d", conn.LocalAddr().String(), peerAddr.Port)
+	go c.remoteReadLoop(conn)
+	return nil
+}
+
+// publicKey returns channel's public key derived from its private key.
+func (c *channel) publicKey() (PublicKey, error) {
+	var pubKey PublicKey
+	pub, err := curve25519.X25519(c.privateKey[:], curve25519.Basepoint)
+	if err != nil {
+		return pubKey, fmt.Errorf("could not derive public key: %w", err)
+	}
+	copy(pubKey[:], pub)
+	return pubKey, nil
+}
+
 // Send sends message to given topic. Peer listening to topic will receive message.
 func (c *channel) Send(ctx context.Context, topic string, msg *Message) (*Message, error) {
 	reply, err := c.sendRequest(ctx, topic, msg)
@@ -509,6 +610,9 @@ func (c *channel) sendRequest(ctx context.Context, topic string, m *Message) (*M
 			if res.statusCode == statusCodeHandlerNotFoundErr {
 				return nil, fmt.Errorf("%s: %w", string(res.data), ErrHandlerNotFound)
 			}
+			if res.statusCode == statusCodeProposalOutdated {
+				return nil, fmt.Errorf("public peer error: %s: %w", string(res.data), market.ErrProposalOutdated)
+			}
 			return nil, fmt.Errorf("peer error: %w", errors.New(res.msg))
 		}
 		return &Message{Data: res.data}, nil
@@ -539,19 +643,80 @@ func (c *channel) setTracer(tracer *trace.Tracer) {
 	c.tracer = tracer
 }
 
-func (c *channel) setServiceConn(conn *net.UDPConn) {
+// setObfuscation enables given obfuscation method for channel traffic. It must be called before read and send loops are launched.
+func (c *channel) setObfuscation(method obfs.Method) error {
+	obfuscator, err := obfs.New(method, obfs.DeriveSecret(c.sharedKey(), "p2p"))
+	if err != nil {
+		return fmt.Errorf("could not create channel obfuscator: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.obfuscation = method
+	c.obfuscator = obfuscator
+	return nil
+}
+
+// setServiceConn sets conn for services. If obfuscation is enabled services get loopback conn
+// and their traffic is obfuscated by the proxy before sending it through given conn.
+func (c *channel) setServiceConn(conn *net.UDPConn) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	return c.setServiceConnLocked(conn)
+}
+
+// migrateServiceConn replaces service conn with the one punched during channel migration.
+// Old conn and its obfuscation proxy are closed, service must switch to ServiceConn itself.
+func (c *channel) migrateServiceConn(conn *net.UDPConn) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.serviceProxyStop != nil {
+		c.serviceProxyStop()
+		c.serviceProxyStop = nil
+	}
+	if c.serviceConn != nil {
+		// Services which bind service port by themselves close it right away.
+		c.serviceConn.Close()
+	}
+
+	return c.setServiceConnLocked(conn)
+}
+
+func (c *channel) setServiceConnLocked(conn *net.UDPConn) error {
+	if c.obfuscation != "" && c.obfuscation != obfs.MethodNone {
+		obfuscator, err := obfs.New(c.obfuscation, obfs.DeriveSecret(c.sharedKey(), "service"))
+		if err != nil {
+			return fmt.Errorf("could not create service obfuscator: %w", err)
+		}
+		proxiedConn, stop, err := traversal.ObfuscatedServiceConn(conn, obfuscator)
+		if err != nil {
+			return fmt.Errorf("could not start service obfuscation proxy: %w", err)
+		}
+		log.Debug().Msgf("Service traffic will be obfuscated using %q", c.obfuscation)
+		c.serviceProxyStop = stop
+		conn = proxiedConn
+	}
+
 	log.Debug().Msgf("Will use service conn with local port: %d, remote port: %d", conn.LocalAddr().(*net.UDPAddr).Port, conn.RemoteAddr().(*net.UDPAddr).Port)
 	c.serviceConn = conn
+	return nil
 }
 
 func (c *channel) setUpnpPortsRelease(release []func()) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.upnpPortsRelease = release
+	c.upnpPortsRelease = append(c.upnpPortsRelease, release...)
+}
+
+func (c *channel) setOnClose(onClose func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onClose = onClose
 }
 
 func (c *channel) checkIfChannelAlive() {
@@ -602,10 +767,21 @@ func listenUDPSession(proxyAddr net.Addr, privateKey PrivateKey, peerPubKey Publ
 	return sess, localAddr, err
 }
 
-func newBlockCrypt(privateKey PrivateKey, peerPublicKey PublicKey) (kcp.BlockCrypt, error) {
-	// Compute shared key. Nonce for each message will be added inside kcp salsa block crypt.
+// sharedKey returns key shared between channel peers.
+func (c *channel) sharedKey() []byte {
+	sharedKey := computeSharedKey(c.privateKey, c.peer.publicKey)
+	return sharedKey[:]
+}
+
+func computeSharedKey(privateKey PrivateKey, peerPublicKey PublicKey) [32]byte {
 	var sharedKey [32]byte
 	box.Precompute(&sharedKey, (*[32]byte)(&peerPublicKey), (*[32]byte)(&privateKey))
+	return sharedKey
+}
+
+func newBlockCrypt(privateKey PrivateKey, peerPublicKey PublicKey) (kcp.BlockCrypt, error) {
+	// Compute shared key. Nonce for each message will be added inside kcp salsa block crypt.
+	sharedKey := computeSharedKey(privateKey, peerPublicKey)
 	blockCrypt, err := kcp.NewSalsa20BlockCrypt(sharedKey[:])
 	if err != nil {
 		return nil, fmt.Errorf("could not create Sasla20 block crypt: %w", err)
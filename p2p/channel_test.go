@@ -20,12 +20,14 @@ package p2p
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -160,6 +162,15 @@ func TestChannelFullCommunicationFlow(t *testing.T) {
 		assert.EqualError(t, err, "public peer error: I don't like you")
 	})
 
+	t.Run("Test peer returns proposal outdated error", func(t *testing.T) {
+		provider.Handle("get-error", func(c Context) error {
+			return c.Error(fmt.Errorf("%w: requested 1, current 2", market.ErrProposalOutdated))
+		})
+
+		_, err := consumer.Send(context.Background(), "get-error", &Message{Data: []byte("hello")})
+		assert.True(t, errors.Is(err, market.ErrProposalOutdated))
+	})
+
 	t.Run("Test peer returns internal error", func(t *testing.T) {
 		provider.Handle("get-error", func(c Context) error {
 			return errors.New("I don't like you")
@@ -33,9 +33,7 @@ import (
 )
 
 const (
-	pingMaxPorts       = 20
-	requiredConnCount  = 2
-	consumerInitialTTL = 128
+	requiredConnCount = 2
 )
 
 type brokerConnector interface {
@@ -58,6 +56,14 @@ func configExchangeACKSubject(providerID identity.Identity, serviceType string)
 	return fmt.Sprintf("%s.%s.p2p-config-exchange-ack", providerID.Address, serviceType)
 }
 
+func configMigrateSubject(providerID identity.Identity, serviceType string) string {
+	return fmt.Sprintf("%s.%s.p2p-config-migrate", providerID.Address, serviceType)
+}
+
+func configMigrateACKSubject(providerID identity.Identity, serviceType string) string {
+	return fmt.Sprintf("%s.%s.p2p-config-migrate-ack", providerID.Address, serviceType)
+}
+
 func channelHandlersReadySubject(providerID identity.Identity, serviceType string) string {
 	return fmt.Sprintf("%s.%s.p2p-channel-handlers-ready", providerID.Address, serviceType)
 }
@@ -33,13 +33,27 @@ var (
 const (
 	// ContactTypeV1 is p2p contact type.
 	ContactTypeV1 = "nats/p2p/v1"
+	// ContactTypeWebSocketV1 is p2p contact type for signalling over HTTPS/WebSocket relays.
+	ContactTypeWebSocketV1 = "ws/p2p/v1"
 )
 
 // ContactDefinition represents p2p contact which contains NATS broker addresses for connection.
 type ContactDefinition struct {
 	BrokerAddresses []string `json:"broker_addresses"`
+	Obfuscation     []string `json:"obfuscation,omitempty"`
 }
 
+// WebSocketContactDefinition represents p2p contact which contains WebSocket relay addresses for connection.
+type WebSocketContactDefinition struct {
+	RelayAddresses []string `json:"relay_addresses"`
+	Obfuscation    []string `json:"obfuscation,omitempty"`
+}
+
+var (
+	_ market.ObfuscatedContact = ContactDefinition{}
+	_ market.ObfuscatedContact = WebSocketContactDefinition{}
+)
+
 // ParseContact tries to parse p2p contact from given contacts list.
 func ParseContact(contacts market.ContactList) (ContactDefinition, error) {
 	for _, c := range contacts {
@@ -54,6 +68,31 @@ func ParseContact(contacts market.ContactList) (ContactDefinition, error) {
 	return ContactDefinition{}, ErrContactNotFound
 }
 
+// ParseContacts returns all p2p contacts from given contacts list keeping their order.
+func ParseContacts(contacts market.ContactList) (market.ContactList, error) {
+	var res market.ContactList
+	for _, c := range contacts {
+		switch c.Type {
+		case ContactTypeV1, ContactTypeWebSocketV1:
+			res = append(res, c)
+		}
+	}
+	if len(res) == 0 {
+		return nil, ErrContactNotFound
+	}
+	return res, nil
+}
+
+// ObfuscationMethods returns obfuscation methods advertised in contact.
+func (def ContactDefinition) ObfuscationMethods() []string {
+	return def.Obfuscation
+}
+
+// ObfuscationMethods returns obfuscation methods advertised in contact.
+func (def WebSocketContactDefinition) ObfuscationMethods() []string {
+	return def.Obfuscation
+}
+
 // RegisterContactUnserializer registers global proposal contact unserializer.
 func RegisterContactUnserializer() {
 	market.RegisterContactUnserializer(
@@ -64,4 +103,12 @@ func RegisterContactUnserializer() {
 			return contact, err
 		},
 	)
+	market.RegisterContactUnserializer(
+		ContactTypeWebSocketV1,
+		func(rawDefinition *json.RawMessage) (market.ContactDefinition, error) {
+			var contact WebSocketContactDefinition
+			err := json.Unmarshal(*rawDefinition, &contact)
+			return contact, err
+		},
+	)
 }
@@ -25,33 +25,42 @@ import (
 	"sync"
 	"time"
 
-	"github.com/mysteriumnetwork/node/trace"
-	nats_lib "github.com/nats-io/nats.go"
-
-	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/obfs"
 	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/trace"
+	"github.com/mysteriumnetwork/node/utils/netutil"
 
 	"github.com/rs/zerolog/log"
 	"google.golang.org/protobuf/proto"
 )
 
-const maxBrokerConnectAttempts = 25
+const maxSignallingConnectAttempts = 25
 
 // Dialer knows how to exchange p2p keys and encrypted configuration and creates ready to use p2p channels.
 type Dialer interface {
-	// Dial exchanges p2p configuration via broker, performs NAT pinging if needed
-	// and create p2p channel which is ready for communication.
-	Dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contactDef ContactDefinition, tracer *trace.Tracer) (Channel, error)
+	// Dial exchanges p2p configuration via the first reachable signalling server from
+	// given contacts, performs NAT pinging if needed and create p2p channel which is ready for communication.
+	Dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contacts market.ContactList, tracer *trace.Tracer) (Channel, error)
+
+	// Migrate moves already established channel and its service conn to the current
+	// consumer network address. Services using the old service conn must be moved by the caller.
+	// Channel keys are reused and only channel connection is punched again, so peers
+	// keep the same channel and its handlers after consumer network change.
+	Migrate(ctx context.Context, ch Channel, consumerID, providerID identity.Identity, serviceType string, contacts market.ContactList, tracer *trace.Tracer) error
 }
 
 // NewDialer creates new p2p communication dialer which is used on consumer side.
-func NewDialer(broker brokerConnector, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, consumerPinger natConsumerPinger, portPool port.ServicePortSupplier) Dialer {
+func NewDialer(signalling []SignallingConnector, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, consumerPinger natConsumerPinger, pingConfig *traversal.PingConfig, portPool port.ServicePortSupplier, obfuscation []obfs.Method) Dialer {
 	return &dialer{
-		broker:         broker,
+		pingConfig:     pingConfig,
+		obfuscation:    obfuscation,
+		signalling:     signalling,
 		ipResolver:     ipResolver,
 		signer:         signer,
 		verifier:       verifier,
@@ -63,28 +72,30 @@ func NewDialer(broker brokerConnector, signer identity.SignerFactory, verifier i
 // dialer implements Dialer interface.
 type dialer struct {
 	portPool       port.ServicePortSupplier
-	broker         brokerConnector
+	signalling     []SignallingConnector
 	consumerPinger natConsumerPinger
+	pingConfig     *traversal.PingConfig
+	obfuscation    []obfs.Method
 	signer         identity.SignerFactory
 	verifier       identity.Verifier
 	ipResolver     ip.Resolver
 }
 
-// Dial exchanges p2p configuration via broker, performs NAT pinging if needed
-// and create p2p channel which is ready for communication.
-func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contactDef ContactDefinition, tracer *trace.Tracer) (Channel, error) {
+// Dial exchanges p2p configuration via the first reachable signalling server from
+// given contacts, performs NAT pinging if needed and create p2p channel which is ready for communication.
+func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contacts market.ContactList, tracer *trace.Tracer) (Channel, error) {
 	config := &p2pConnectConfig{tracer: tracer}
 
 	// Send initial exchange with signed consumer public key.
-	brokerConn, err := m.connect(contactDef, tracer)
+	signallingConn, err := m.connect(consumerID, contacts, tracer)
 	if err != nil {
-		return nil, fmt.Errorf("could not open broker conn: %w", err)
+		return nil, fmt.Errorf("could not open signalling conn: %w", err)
 	}
-	defer brokerConn.Close()
+	defer signallingConn.Close()
 
 	peerReady := make(chan struct{})
 	var once sync.Once
-	_, err = brokerConn.Subscribe(channelHandlersReadySubject(providerID, serviceType), func(msg *nats_lib.Msg) {
+	_, err = signallingConn.Subscribe(channelHandlersReadySubject(providerID, serviceType), func(msg *SignallingMsg) {
 		defer once.Do(func() { close(peerReady) })
 		if err := m.channelHandlersReady(msg); err != nil {
 			log.Err(err).Msg("Channel handlers ready handler setup failed")
@@ -92,7 +103,16 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 		}
 	})
 
-	config, err = m.startConfigExchange(config, ctx, brokerConn, providerID, serviceType, consumerID)
+	if err != nil {
+		return nil, fmt.Errorf("could not subscribe to channel handlers ready subject: %w", err)
+	}
+
+	config.publicKey, config.privateKey, err = GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate consumer p2p keys: %w", err)
+	}
+
+	config, err = m.startConfigExchange(config, ctx, signallingConn, configExchangeSubject(providerID, serviceType), providerID, consumerID)
 	if err != nil {
 		return nil, fmt.Errorf("could not exchange config: %w", err)
 	}
@@ -103,16 +123,12 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 	}
 
 	// Finally send consumer encrypted and signed connect config in ack message.
-	err = m.ackConfigExchange(config, ctx, brokerConn, providerID, serviceType, consumerID)
+	err = m.ackConfigExchange(config, ctx, signallingConn, configExchangeACKSubject(providerID, serviceType), providerID, consumerID)
 	if err != nil {
 		return nil, fmt.Errorf("could not ack config: %w", err)
 	}
 
-	dial := m.dialPinger
-	if len(config.peerPorts) == requiredConnCount {
-		dial = m.dialDirect
-	}
-	conn1, conn2, err := dial(ctx, providerID, config)
+	conns, err := m.dial(ctx, providerID, config)
 	if err != nil {
 		return nil, fmt.Errorf("could not dial p2p channel: %w", err)
 	}
@@ -126,64 +142,162 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 		return nil, errors.New("timeout while performing configuration exchange")
 	}
 
-	channel, err := newChannel(conn1, config.privateKey, config.peerPubKey)
+	channel, err := newChannel(conns[0], config.privateKey, config.peerPubKey)
 	if err != nil {
 		return nil, fmt.Errorf("could not create p2p channel during dial: %w", err)
 	}
 	channel.setTracer(tracer)
-	channel.setServiceConn(conn2)
+	if err := channel.setObfuscation(config.obfuscation); err != nil {
+		channel.Close()
+		return nil, fmt.Errorf("could not set p2p channel obfuscation: %w", err)
+	}
+	if err := channel.setServiceConn(conns[1]); err != nil {
+		channel.Close()
+		return nil, fmt.Errorf("could not set p2p service conn: %w", err)
+	}
+	if err := excludeServiceRoute(config); err != nil {
+		channel.Close()
+		return nil, fmt.Errorf("could not exclude provider route: %w", err)
+	}
 	channel.launchReadSendLoops()
 	config.tracer.EndStage(traceAck)
 
 	return channel, nil
 }
 
-func (m *dialer) connect(contactDef ContactDefinition, tracer *trace.Tracer) (conn nats.Connection, err error) {
+// Migrate moves already established channel to the current consumer network address.
+func (m *dialer) Migrate(ctx context.Context, ch Channel, consumerID, providerID identity.Identity, serviceType string, contacts market.ContactList, tracer *trace.Tracer) error {
+	c, ok := ch.(*channel)
+	if !ok {
+		return fmt.Errorf("unsupported p2p channel type %T", ch)
+	}
+
+	pubKey, err := c.publicKey()
+	if err != nil {
+		return err
+	}
+	config := &p2pConnectConfig{
+		tracer:     tracer,
+		publicKey:  pubKey,
+		privateKey: c.privateKey,
+		channel:    c,
+	}
+
+	signallingConn, err := m.connect(consumerID, contacts, tracer)
+	if err != nil {
+		return fmt.Errorf("could not open signalling conn: %w", err)
+	}
+	defer signallingConn.Close()
+
+	config, err = m.startConfigExchange(config, ctx, signallingConn, configMigrateSubject(providerID, serviceType), providerID, consumerID)
+	if err != nil {
+		return fmt.Errorf("could not exchange migrate config: %w", err)
+	}
+	if config.peerPubKey != c.peer.publicKey {
+		return errors.New("provider replied with unexpected public key")
+	}
+
+	config.publicIP, config.localPorts, err = m.prepareLocalPorts(config)
+	if err != nil {
+		return fmt.Errorf("could not prepare ports: %w", err)
+	}
+
+	err = m.ackConfigExchange(config, ctx, signallingConn, configMigrateACKSubject(providerID, serviceType), providerID, consumerID)
+	if err != nil {
+		return fmt.Errorf("could not ack migrate config: %w", err)
+	}
+
+	conns, err := m.dial(ctx, providerID, config)
+	if err != nil {
+		return fmt.Errorf("could not dial p2p channel: %w", err)
+	}
+
+	if err := c.rebind(conns[0]); err != nil {
+		return fmt.Errorf("could not migrate p2p channel: %w", err)
+	}
+	if err := c.migrateServiceConn(conns[1]); err != nil {
+		return fmt.Errorf("could not migrate p2p service conn: %w", err)
+	}
+	if err := excludeServiceRoute(config); err != nil {
+		return fmt.Errorf("could not exclude provider route: %w", err)
+	}
+	return nil
+}
+
+// excludeServiceRoute keeps obfuscated service traffic to the provider out of the VPN tunnel.
+// Service then talks to the local obfuscation proxy, so its endpoint can't exclude provider route.
+func excludeServiceRoute(config *p2pConnectConfig) error {
+	if config.obfuscation == "" || config.obfuscation == obfs.MethodNone {
+		return nil
+	}
+	ip := net.ParseIP(config.peerIP())
+	if ip == nil || ip.IsLoopback() {
+		return nil
+	}
+	return netutil.ExcludeRoute(ip)
+}
+
+// connect opens signalling conn as consumer using contacts in the given order. The first signalling
+// server which accepts connection is used for config exchange.
+func (m *dialer) connect(consumerID identity.Identity, contacts market.ContactList, tracer *trace.Tracer) (conn SignallingConn, err error) {
 	trace := tracer.StartStage("Consumer P2P connect")
 	defer tracer.EndStage(trace)
 
-	// broker connect might fail due to reconfiguration of network routes in progress
-	for i := 0; i < maxBrokerConnectAttempts; i++ {
-		serverURLs, err := nats.ParseServerURIs(contactDef.BrokerAddresses)
-		if err != nil {
+	err = ErrContactNotFound
+	// signalling connect might fail due to reconfiguration of network routes in progress
+	for i := 0; i < maxSignallingConnectAttempts; i++ {
+		var supported bool
+		for _, contact := range contacts {
+			connector, ok := m.connector(contact.Type)
+			if !ok {
+				continue
+			}
+			supported = true
+
+			conn, err = connector.Connect(consumerID, contact)
+			if err == nil {
+				return conn, nil
+			}
+			log.Warn().Msgf("signalling connect via %s failed: %s", contact.Type, err)
+		}
+		if !supported {
 			return nil, err
 		}
 
-		conn, err = m.broker.Connect(serverURLs...)
-		if err != nil {
-			log.Warn().Msgf("broker connect failed - attempting again in 1sec: %s", err)
-			time.Sleep(time.Second)
-			continue
+		log.Warn().Msg("all signalling connects failed - attempting again in 1sec")
+		time.Sleep(time.Second)
+	}
+	return nil, err
+}
+
+func (m *dialer) connector(contactType string) (SignallingConnector, bool) {
+	for _, c := range m.signalling {
+		if c.ContactType() == contactType {
+			return c, true
 		}
-		break
 	}
-	return conn, err
+	return nil, false
 }
 
-func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Context, brokerConn nats.Connection, providerID identity.Identity, serviceType string, consumerID identity.Identity) (*p2pConnectConfig, error) {
+func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Context, signallingConn SignallingConn, subject string, providerID identity.Identity, consumerID identity.Identity) (*p2pConnectConfig, error) {
 	trace := config.tracer.StartStage("Consumer P2P exchange")
 	defer config.tracer.EndStage(trace)
 
-	pubKey, privateKey, err := GenerateKey()
-	if err != nil {
-		return nil, fmt.Errorf("could not generate consumer p2p keys: %w", err)
-	}
-
 	beginExchangeMsg := &pb.P2PConfigExchangeMsg{
-		PublicKey: pubKey.Hex(),
+		PublicKey: config.publicKey.Hex(),
 	}
 	log.Debug().Msgf("Consumer %s sending public key %s to provider %s", consumerID.Address, beginExchangeMsg.PublicKey, providerID.Address)
 	packedMsg, err := packSignedMsg(m.signer, consumerID, beginExchangeMsg)
 	if err != nil {
 		return nil, fmt.Errorf("could not pack signed message: %v", err)
 	}
-	exchangeMsgBrokerReply, err := m.sendSignedMsg(ctx, configExchangeSubject(providerID, serviceType), packedMsg, brokerConn)
+	exchangeMsgReplyData, err := m.sendSignedMsg(ctx, subject, packedMsg, signallingConn)
 	if err != nil {
 		return nil, fmt.Errorf("could not send signed message: %w", err)
 	}
 
 	// Parse provider response with public key and encrypted and signed connection config.
-	exchangeMsgReplySignedMsg, err := unpackSignedMsg(m.verifier, exchangeMsgBrokerReply)
+	exchangeMsgReplySignedMsg, err := unpackSignedMsg(m.verifier, exchangeMsgReplyData)
 	if err != nil {
 		return nil, fmt.Errorf("could not unpack peer siged message: %w", err)
 	}
@@ -195,21 +309,29 @@ func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Conte
 	if err != nil {
 		return nil, err
 	}
-	peerConnConfig, err := decryptConnConfigMsg(exchangeMsgReply.ConfigCiphertext, privateKey, peerPubKey)
+	peerConnConfig, err := decryptConnConfigMsg(exchangeMsgReply.ConfigCiphertext, config.privateKey, peerPubKey)
 	if err != nil {
 		return nil, fmt.Errorf("could not decrypt peer conn config: %w", err)
 	}
 	log.Debug().Msgf("Consumer %s received provider %s with config: %v", consumerID.Address, providerID.Address, peerConnConfig)
 
-	config.publicKey = pubKey
-	config.privateKey = privateKey
 	config.peerPubKey = peerPubKey
 	config.peerPublicIP = peerConnConfig.PublicIP
 	config.peerPorts = int32ToIntSlice(peerConnConfig.Ports)
+	config.obfuscation = m.negotiateObfuscation(peerConnConfig.Obfuscation)
 	return config, nil
 }
 
-func (m *dialer) ackConfigExchange(config *p2pConnectConfig, ctx context.Context, brokerConn nats.Connection, providerID identity.Identity, serviceType string, consumerID identity.Identity) error {
+// negotiateObfuscation picks the most preferred consumer obfuscation method offered by provider.
+func (m *dialer) negotiateObfuscation(offered []string) obfs.Method {
+	var supported []obfs.Method
+	for _, method := range offered {
+		supported = append(supported, obfs.Method(method))
+	}
+	return obfs.Negotiate(m.obfuscation, supported)
+}
+
+func (m *dialer) ackConfigExchange(config *p2pConnectConfig, ctx context.Context, signallingConn SignallingConn, subject string, providerID identity.Identity, consumerID identity.Identity) error {
 	trace := config.tracer.StartStage("Consumer P2P exchange ack")
 	defer config.tracer.EndStage(trace)
 
@@ -217,6 +339,9 @@ func (m *dialer) ackConfigExchange(config *p2pConnectConfig, ctx context.Context
 		PublicIP: config.publicIP,
 		Ports:    intToInt32Slice(config.localPorts),
 	}
+	if config.obfuscation != "" && config.obfuscation != obfs.MethodNone {
+		connConfig.Obfuscation = []string{string(config.obfuscation)}
+	}
 	connConfigCiphertext, err := encryptConnConfigMsg(connConfig, config.privateKey, config.peerPubKey)
 	if err != nil {
 		return fmt.Errorf("could not encrypt config msg: %v", err)
@@ -231,11 +356,11 @@ func (m *dialer) ackConfigExchange(config *p2pConnectConfig, ctx context.Context
 		return fmt.Errorf("could not pack signed message: %v", err)
 	}
 
-	// simple broker Publish will not work here since we have to delay Consumer from pinging Provider
+	// simple signalling Publish will not work here since we have to delay Consumer from pinging Provider
 	//  until provider receives consumer config ( IP, ports ) and starts pinging Consumer first.
-	// This is why we use broker Request method to be sure that Provider processed our given configuration.
-	// To improve speed here investigate options to reduce broker communication round trip.
-	_, err = m.sendSignedMsg(ctx, configExchangeACKSubject(providerID, serviceType), packedMsg, brokerConn)
+	// This is why we use signalling Request method to be sure that Provider processed our given configuration.
+	// To improve speed here investigate options to reduce signalling communication round trip.
+	_, err = m.sendSignedMsg(ctx, subject, packedMsg, signallingConn)
 
 	if err != nil {
 		return fmt.Errorf("could not send signed msg: %v", err)
@@ -262,51 +387,61 @@ func (m *dialer) prepareLocalPorts(config *p2pConnectConfig) (string, []int, err
 	return publicIP, localPorts, nil
 }
 
-func (m *dialer) dialDirect(ctx context.Context, providerID identity.Identity, config *p2pConnectConfig) (*net.UDPConn, *net.UDPConn, error) {
+// dial creates UDP conns to provider either directly or by pinging provider if it is behind NAT.
+func (m *dialer) dial(ctx context.Context, providerID identity.Identity, config *p2pConnectConfig) ([]*net.UDPConn, error) {
+	if len(config.peerPorts) == requiredConnCount {
+		return m.dialDirect(ctx, providerID, config)
+	}
+	return m.dialPinger(ctx, providerID, config)
+}
+
+func (m *dialer) dialDirect(ctx context.Context, providerID identity.Identity, config *p2pConnectConfig) ([]*net.UDPConn, error) {
 	trace := config.tracer.StartStage("Consumer P2P dial (upnp)")
 	defer config.tracer.EndStage(trace)
 
 	if _, err := firewall.AllowIPAccess(config.peerPublicIP); err != nil {
-		return nil, nil, fmt.Errorf("could not add peer IP firewall rule: %w", err)
+		return nil, fmt.Errorf("could not add peer IP firewall rule: %w", err)
 	}
 
 	log.Debug().Msg("Skipping provider ping")
-	conn1, err := net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[0]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[0]})
-	if err != nil {
-		return nil, nil, fmt.Errorf("could not create UDP conn for p2p channel: %w", err)
-	}
-	conn2, err := net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[1]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[1]})
-	if err != nil {
-		return nil, nil, fmt.Errorf("could not create UDP conn for service: %w", err)
+	var conns []*net.UDPConn
+	for i := 0; i < requiredConnCount; i++ {
+		conn, err := net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[i]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[i]})
+		if err != nil {
+			return nil, fmt.Errorf("could not create UDP conn: %w", err)
+		}
+		conns = append(conns, conn)
 	}
-	return conn1, conn2, err
+	return conns, nil
 }
 
-func (m *dialer) dialPinger(ctx context.Context, providerID identity.Identity, config *p2pConnectConfig) (*net.UDPConn, *net.UDPConn, error) {
+func (m *dialer) dialPinger(ctx context.Context, providerID identity.Identity, config *p2pConnectConfig) ([]*net.UDPConn, error) {
 	trace := config.tracer.StartStage("Consumer P2P dial (pinger)")
-	defer config.tracer.EndStage(trace)
 
 	if _, err := firewall.AllowIPAccess(config.peerPublicIP); err != nil {
-		return nil, nil, fmt.Errorf("could not add peer IP firewall rule: %w", err)
+		config.tracer.FailStage(trace, err)
+		return nil, fmt.Errorf("could not add peer IP firewall rule: %w", err)
 	}
 
-	log.Debug().Msgf("Pinging provider %s with IP %s using ports %v:%v", providerID.Address, config.peerIP(), config.localPorts, config.peerPorts)
-	conns, err := m.consumerPinger.PingProviderPeer(ctx, config.peerIP(), config.localPorts, config.peerPorts, consumerInitialTTL, requiredConnCount)
+	log.Debug().Msgf("Pinging provider %s with IP %s using ports %v:%v, strategy: %s", providerID.Address, config.peerIP(), config.localPorts, config.peerPorts, m.pingConfig.Strategy)
+	conns, err := m.consumerPinger.PingProviderPeer(ctx, config.peerIP(), config.localPorts, config.peerPorts, m.pingConfig.ConsumerInitialTTL, requiredConnCount)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not ping peer: %w", err)
+		config.tracer.FailStage(trace, err)
+		return nil, fmt.Errorf("could not ping peer: %w", err)
 	}
-	return conns[0], conns[1], nil
+	config.tracer.EndStage(trace)
+	return conns, nil
 }
 
-func (m *dialer) sendSignedMsg(ctx context.Context, subject string, msg []byte, brokerConn nats.Connection) ([]byte, error) {
-	reply, err := brokerConn.RequestWithContext(ctx, subject, msg)
+func (m *dialer) sendSignedMsg(ctx context.Context, subject string, msg []byte, signallingConn SignallingConn) ([]byte, error) {
+	reply, err := signallingConn.Request(ctx, subject, msg)
 	if err != nil {
-		return nil, fmt.Errorf("could not send broker request to subject %s: %v", subject, err)
+		return nil, fmt.Errorf("could not send signalling request to subject %s: %v", subject, err)
 	}
-	return reply.Data, nil
+	return reply, nil
 }
 
-func (m *dialer) channelHandlersReady(msg *nats_lib.Msg) error {
+func (m *dialer) channelHandlersReady(msg *SignallingMsg) error {
 	var handlersReady pb.P2PChannelHandlersReady
 	if err := proto.Unmarshal(msg.Data, &handlersReady); err != nil {
 		return fmt.Errorf("failed to unmarshal handlers ready message: %w", err)
@@ -19,8 +19,11 @@ package p2p
 
 import (
 	"context"
+	"errors"
 	"net"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,8 +34,10 @@ import (
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/obfs"
 	"github.com/mysteriumnetwork/node/trace"
 )
 
@@ -45,6 +50,7 @@ func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 		natProviderPinger natProviderPinger
 		natConsumerPinger natConsumerPinger
 		portMapper        mapping.PortMapper
+		pingConfig        *traversal.PingConfig
 	}{
 		{
 			name:              "Provider with public IP",
@@ -74,6 +80,14 @@ func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 			natConsumerPinger: traversal.NewNoopPinger(eventbus.New()),
 			portMapper:        &mockPortMapper{enabled: false},
 		},
+		{
+			name:              "Provider behind NAT with manual port forwarding preferred",
+			ipResolver:        ip.NewResolverMockMultiple("127.0.0.1", "1.1.1.1"),
+			natProviderPinger: &mockProviderNATPinger{},
+			natConsumerPinger: &mockConsumerNATPinger{},
+			portMapper:        &mockPortMapper{enabled: false},
+			pingConfig:        &traversal.PingConfig{MaxPorts: 20, PreferPortForwarding: true},
+		},
 	}
 
 	for _, test := range tests {
@@ -87,9 +101,13 @@ func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 			defer brokerConn.Close()
 			mockBroker := &mockBroker{conn: brokerConn}
 			portPool := port.NewPool()
+			pingConfig := traversal.DefaultPingConfig()
+			if test.pingConfig != nil {
+				pingConfig = test.pingConfig
+			}
 
 			// Provider starts listening.
-			channelListener := NewListener(brokerConn, signerFactory, verifier, test.ipResolver, test.natProviderPinger, portPool, test.portMapper, eventbus.New())
+			channelListener := NewListener([]SignallingConn{NewNATSSignallingConn(brokerConn)}, signerFactory, verifier, test.ipResolver, test.natProviderPinger, pingConfig, portPool, test.portMapper, nil, eventbus.New())
 			_, err := channelListener.Listen(providerID, "wireguard", func(ch Channel) {
 				ch.Handle("test", func(c Context) error {
 					return c.OkWithReply(&Message{Data: []byte("pong")})
@@ -98,10 +116,11 @@ func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 			assert.NoError(t, err)
 
 			// Consumer starts dialing provider.
-			channelDialer := NewDialer(mockBroker, signerFactory, verifier, test.ipResolver, test.natConsumerPinger, portPool)
+			channelDialer := NewDialer([]SignallingConnector{NewNATSSignallingConnector(mockBroker)}, signerFactory, verifier, test.ipResolver, test.natConsumerPinger, pingConfig, portPool, nil)
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
-			consumerChannel, err := channelDialer.Dial(ctx, identity.FromAddress("0x2"), providerID, "wireguard", ContactDefinition{BrokerAddresses: []string{"broker"}}, trace.NewTracer("Dial"))
+			contacts := market.ContactList{{Type: ContactTypeV1, Definition: ContactDefinition{BrokerAddresses: []string{"broker"}}}}
+			consumerChannel, err := channelDialer.Dial(ctx, identity.FromAddress("0x2"), providerID, "wireguard", contacts, trace.NewTracer("Dial"))
 			assert.NoError(t, err)
 			defer consumerChannel.Close()
 
@@ -112,6 +131,164 @@ func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 	}
 }
 
+func TestDialer_Exchange_Via_WebSocket_Relay_When_Broker_Unreachable(t *testing.T) {
+	relay := httptest.NewServer(NewWebSocketSignallingRelay(&identity.VerifierFake{}))
+	defer relay.Close()
+	relayAddr := "ws" + strings.TrimPrefix(relay.URL, "http")
+
+	providerID := identity.FromAddress("0x1")
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return &identity.SignerFake{}
+	}
+	verifier := &identity.VerifierFake{}
+	ipResolver := ip.NewResolverMock("127.0.0.1")
+	portPool := port.NewPool()
+
+	// Provider starts listening on broker and WebSocket relay.
+	brokerConn := nats.StartConnectionMock()
+	defer brokerConn.Close()
+	wsConn, err := DialWebSocketSignalling([]string{relayAddr}, fakeWebSocketAuth)
+	assert.NoError(t, err)
+	defer wsConn.Close()
+
+	channelListener := NewListener([]SignallingConn{NewNATSSignallingConn(brokerConn), wsConn}, signerFactory, verifier, ipResolver, &mockProviderNATPinger{}, traversal.DefaultPingConfig(), portPool, &mockPortMapper{}, nil, eventbus.New())
+	_, err = channelListener.Listen(providerID, "wireguard", func(ch Channel) {
+		ch.Handle("test", func(c Context) error {
+			return c.OkWithReply(&Message{Data: []byte("pong")})
+		})
+	})
+	assert.NoError(t, err)
+	contacts := channelListener.GetContacts()
+	assert.Len(t, contacts, 2)
+	assert.Equal(t, ContactTypeV1, contacts[0].Type)
+	assert.Equal(t, ContactTypeWebSocketV1, contacts[1].Type)
+
+	// Consumer can't reach broker so it falls back to WebSocket relay.
+	connectors := []SignallingConnector{NewNATSSignallingConnector(&mockBroker{err: errors.New("broker is blocked")}), NewWebSocketSignallingConnector(fakeSignerFactory)}
+	channelDialer := NewDialer(connectors, signerFactory, verifier, ipResolver, &mockConsumerNATPinger{}, traversal.DefaultPingConfig(), portPool, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	consumerChannel, err := channelDialer.Dial(ctx, identity.FromAddress("0x2"), providerID, "wireguard", contacts, trace.NewTracer("Dial"))
+	assert.NoError(t, err)
+	defer consumerChannel.Close()
+
+	res, err := consumerChannel.Send(context.Background(), "test", &Message{Data: []byte("ping")})
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", string(res.Data))
+}
+
+func TestDialer_Exchange_With_Obfuscation(t *testing.T) {
+	relay := httptest.NewServer(NewWebSocketSignallingRelay(&identity.VerifierFake{}))
+	defer relay.Close()
+	relayAddr := "ws" + strings.TrimPrefix(relay.URL, "http")
+
+	providerID := identity.FromAddress("0x1")
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return &identity.SignerFake{}
+	}
+	verifier := &identity.VerifierFake{}
+	ipResolver := ip.NewResolverMock("127.0.0.1")
+	portPool := port.NewPool()
+
+	wsConn, err := DialWebSocketSignalling([]string{relayAddr}, fakeWebSocketAuth)
+	assert.NoError(t, err)
+	defer wsConn.Close()
+
+	providerChannels := make(chan Channel, 1)
+	channelListener := NewListener([]SignallingConn{wsConn}, signerFactory, verifier, ipResolver, &mockProviderNATPinger{}, traversal.DefaultPingConfig(), portPool, &mockPortMapper{}, []obfs.Method{obfs.MethodScramble}, eventbus.New())
+	_, err = channelListener.Listen(providerID, "wireguard", func(ch Channel) {
+		ch.Handle("test", func(c Context) error {
+			return c.OkWithReply(&Message{Data: []byte("pong")})
+		})
+		providerChannels <- ch
+	})
+	assert.NoError(t, err)
+	contacts := channelListener.GetContacts()
+	assert.Equal(t, []string{"scramble"}, contacts.ObfuscationMethods())
+
+	channelDialer := NewDialer([]SignallingConnector{NewWebSocketSignallingConnector(fakeSignerFactory)}, signerFactory, verifier, ipResolver, &mockConsumerNATPinger{}, traversal.DefaultPingConfig(), portPool, []obfs.Method{obfs.MethodScramble})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	consumerChannel, err := channelDialer.Dial(ctx, identity.FromAddress("0x2"), providerID, "wireguard", contacts, trace.NewTracer("Dial"))
+	assert.NoError(t, err)
+	defer consumerChannel.Close()
+
+	res, err := consumerChannel.Send(context.Background(), "test", &Message{Data: []byte("ping")})
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", string(res.Data))
+
+	// Service traffic goes through local obfuscation proxies.
+	providerChannel := <-providerChannels
+	assert.True(t, consumerChannel.ServiceConn().RemoteAddr().(*net.UDPAddr).IP.IsLoopback())
+	_, err = consumerChannel.ServiceConn().Write([]byte("service"))
+	assert.NoError(t, err)
+	buf := make([]byte, 64)
+	providerChannel.ServiceConn().SetReadDeadline(time.Now().Add(time.Second))
+	n, err := providerChannel.ServiceConn().Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "service", string(buf[:n]))
+}
+
+func TestDialer_Migrate_Channel(t *testing.T) {
+	relay := httptest.NewServer(NewWebSocketSignallingRelay(&identity.VerifierFake{}))
+	defer relay.Close()
+	relayAddr := "ws" + strings.TrimPrefix(relay.URL, "http")
+
+	providerID := identity.FromAddress("0x1")
+	consumerID := identity.FromAddress("0x2")
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return &identity.SignerFake{}
+	}
+	verifier := &identity.VerifierFake{}
+	ipResolver := ip.NewResolverMock("127.0.0.1")
+	portPool := port.NewPool()
+
+	wsConn, err := DialWebSocketSignalling([]string{relayAddr}, fakeWebSocketAuth)
+	assert.NoError(t, err)
+	defer wsConn.Close()
+
+	providerChannel := make(chan Channel, 1)
+	channelListener := NewListener([]SignallingConn{wsConn}, signerFactory, verifier, ipResolver, &mockProviderNATPinger{}, traversal.DefaultPingConfig(), portPool, &mockPortMapper{}, nil, eventbus.New())
+	_, err = channelListener.Listen(providerID, "wireguard", func(ch Channel) {
+		ch.Handle("test", func(c Context) error {
+			return c.OkWithReply(&Message{Data: []byte("pong")})
+		})
+		providerChannel <- ch
+	})
+	assert.NoError(t, err)
+	contacts := channelListener.GetContacts()
+
+	channelDialer := NewDialer([]SignallingConnector{NewWebSocketSignallingConnector(fakeSignerFactory)}, signerFactory, verifier, ipResolver, &mockConsumerNATPinger{}, traversal.DefaultPingConfig(), portPool, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	consumerChannel, err := channelDialer.Dial(ctx, consumerID, providerID, "wireguard", contacts, trace.NewTracer("Dial"))
+	assert.NoError(t, err)
+	defer consumerChannel.Close()
+	localAddr := consumerChannel.Conn().LocalAddr().String()
+	serviceAddr := consumerChannel.ServiceConn().LocalAddr().String()
+	provider := <-providerChannel
+	providerServiceAddr := provider.ServiceConn().LocalAddr().String()
+
+	err = channelDialer.Migrate(ctx, consumerChannel, consumerID, providerID, "wireguard", contacts, trace.NewTracer("Migrate"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, localAddr, consumerChannel.Conn().LocalAddr().String())
+	assert.NotEqual(t, serviceAddr, consumerChannel.ServiceConn().LocalAddr().String())
+
+	res, err := consumerChannel.Send(context.Background(), "test", &Message{Data: []byte("ping")})
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", string(res.Data))
+	assert.NotEqual(t, providerServiceAddr, provider.ServiceConn().LocalAddr().String())
+}
+
+func TestDialer_Fails_Without_Supported_Contacts(t *testing.T) {
+	channelDialer := NewDialer([]SignallingConnector{NewWebSocketSignallingConnector(fakeSignerFactory)}, nil, &identity.VerifierFake{}, ip.NewResolverMock("127.0.0.1"), &mockConsumerNATPinger{}, traversal.DefaultPingConfig(), port.NewPool(), nil)
+	contacts := market.ContactList{{Type: ContactTypeV1, Definition: ContactDefinition{BrokerAddresses: []string{"broker"}}}}
+
+	_, err := channelDialer.Dial(context.Background(), identity.FromAddress("0x2"), identity.FromAddress("0x1"), "wireguard", contacts, trace.NewTracer("Dial"))
+
+	assert.True(t, errors.Is(err, ErrContactNotFound))
+}
+
 func natTestPingers(t *testing.T) (providerPinger natProviderPinger, consumerPinger natConsumerPinger) {
 	ports, err := acquirePorts(2)
 	assert.NoError(t, err)
@@ -144,10 +321,11 @@ func (m *mockProviderNATPinger) PingConsumerPeer(ctx context.Context, id, ip str
 
 type mockBroker struct {
 	conn nats.Connection
+	err  error
 }
 
 func (m *mockBroker) Connect(serverURLs ...*url.URL) (nats.Connection, error) {
-	return m.conn, nil
+	return m.conn, m.err
 }
 
 type mockPortMapper struct {
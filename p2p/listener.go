@@ -24,11 +24,9 @@ import (
 	"sync"
 	"time"
 
-	nats_lib "github.com/nats-io/nats.go"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/protobuf/proto"
 
-	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/eventbus"
@@ -37,6 +35,7 @@ import (
 	"github.com/mysteriumnetwork/node/nat/event"
 	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/obfs"
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/trace"
 )
@@ -47,22 +46,26 @@ type Listener interface {
 	// to channelHandlers
 	Listen(providerID identity.Identity, serviceType string, channelHandler func(ch Channel)) (func(), error)
 
-	// GetContact returns contract which is later can be added to proposal contacts definition so consumer can
-	// know how to connect to this p2p listener.
-	GetContact() market.Contact
+	// GetContacts returns contacts which later can be added to proposal contacts definition so consumer can
+	// know how to connect to this p2p listener. Contacts are ordered by signalling preference.
+	GetContacts() market.ContactList
 }
 
 // NewListener creates new p2p communication listener which is used on provider side.
-func NewListener(brokerConn nats.Connection, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, providerPinger natProviderPinger, portPool port.ServicePortSupplier, portMapper mapping.PortMapper, eventBus eventbus.EventBus) Listener {
+// Listener accepts config exchange on every given signalling connection.
+func NewListener(signalling []SignallingConn, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, providerPinger natProviderPinger, pingConfig *traversal.PingConfig, portPool port.ServicePortSupplier, portMapper mapping.PortMapper, obfuscation []obfs.Method, eventBus eventbus.EventBus) Listener {
 	return &listener{
-		brokerConn:     brokerConn,
+		signalling:     signalling,
 		pendingConfigs: map[PublicKey]p2pConnectConfig{},
+		channels:       map[PublicKey]*channel{},
 		ipResolver:     ipResolver,
 		signer:         signer,
 		verifier:       verifier,
 		portPool:       portPool,
 		providerPinger: providerPinger,
 		portMapper:     portMapper,
+		pingConfig:     pingConfig,
+		obfuscation:    obfuscation,
 		eventBus:       eventBus,
 	}
 }
@@ -71,17 +74,26 @@ func NewListener(brokerConn nats.Connection, signer identity.SignerFactory, veri
 type listener struct {
 	eventBus       eventbus.EventBus
 	portPool       port.ServicePortSupplier
-	brokerConn     nats.Connection
+	signalling     []SignallingConn
 	providerPinger natProviderPinger
 	signer         identity.SignerFactory
 	verifier       identity.Verifier
 	ipResolver     ip.Resolver
 	portMapper     mapping.PortMapper
+	pingConfig     *traversal.PingConfig
+
+	// obfuscation holds obfuscation methods offered to consumers in preference order.
+	obfuscation []obfs.Method
 
 	// Keys holds pendingConfigs temporary configs for provider side since it
 	// need to handle key exchange in two steps.
 	pendingConfigs   map[PublicKey]p2pConnectConfig
 	pendingConfigsMu sync.Mutex
+
+	// channels holds established channels by consumer public key so they
+	// can be migrated when consumer network changes.
+	channels   map[PublicKey]*channel
+	channelsMu sync.Mutex
 }
 
 type p2pConnectConfig struct {
@@ -94,6 +106,12 @@ type p2pConnectConfig struct {
 	peerPubKey       PublicKey
 	tracer           *trace.Tracer
 	upnpPortsRelease []func()
+
+	// channel is set when config is used to migrate already established channel.
+	channel *channel
+
+	// obfuscation is obfuscation method negotiated during config exchange.
+	obfuscation obfs.Method
 }
 
 func (c *p2pConnectConfig) peerIP() string {
@@ -104,11 +122,26 @@ func (c *p2pConnectConfig) peerIP() string {
 	return c.peerPublicIP
 }
 
-func (m *listener) GetContact() market.Contact {
-	return market.Contact{
-		Type:       ContactTypeV1,
-		Definition: ContactDefinition{BrokerAddresses: m.brokerConn.Servers()},
+func (m *listener) GetContacts() market.ContactList {
+	var obfuscation []string
+	for _, method := range m.obfuscation {
+		obfuscation = append(obfuscation, string(method))
 	}
+
+	var contacts market.ContactList
+	for _, conn := range m.signalling {
+		contact := conn.Contact()
+		switch def := contact.Definition.(type) {
+		case ContactDefinition:
+			def.Obfuscation = obfuscation
+			contact.Definition = def
+		case WebSocketContactDefinition:
+			def.Obfuscation = obfuscation
+			contact.Definition = def
+		}
+		contacts = append(contacts, contact)
+	}
+	return contacts
 }
 
 // Listen listens for incoming peer connections to establish new p2p channels. Establishes p2p channel and passes it
@@ -119,8 +152,28 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		return func() {}, fmt.Errorf("could not get outbound IP: %w", err)
 	}
 
-	configSub, err := m.brokerConn.Subscribe(configExchangeSubject(providerID, serviceType), func(msg *nats_lib.Msg) {
-		if err := m.providerStartConfigExchange(providerID, msg, outboundIP); err != nil {
+	var stops []func()
+	stopAll := func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}
+	for _, conn := range m.signalling {
+		stop, err := m.listen(conn, providerID, serviceType, outboundIP, channelHandlers)
+		if err != nil {
+			stopAll()
+			return func() {}, err
+		}
+		stops = append(stops, stop)
+	}
+
+	return stopAll, nil
+}
+
+// listen handles config exchange coming through single signalling connection.
+func (m *listener) listen(conn SignallingConn, providerID identity.Identity, serviceType string, outboundIP string, channelHandlers func(ch Channel)) (func(), error) {
+	unsubscribeConfig, err := conn.Subscribe(configExchangeSubject(providerID, serviceType), func(msg *SignallingMsg) {
+		if err := m.providerStartConfigExchange(conn, providerID, msg, outboundIP); err != nil {
 			log.Err(err).Msg("Could not handle initial exchange")
 			return
 		}
@@ -129,77 +182,51 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		return func() {}, fmt.Errorf("could not get subscribe to config exchange topic: %w", err)
 	}
 
-	ackSub, err := m.brokerConn.Subscribe(configExchangeACKSubject(providerID, serviceType), func(msg *nats_lib.Msg) {
+	unsubscribeAck, err := conn.Subscribe(configExchangeACKSubject(providerID, serviceType), func(msg *SignallingMsg) {
 		config, err := m.providerAckConfigExchange(msg)
 		if err != nil {
 			log.Err(err).Msg("Could not handle exchange ack")
 			return
 		}
+		if config.channel != nil {
+			log.Error().Msg("Could not handle exchange ack: config is pending for channel migration")
+			return
+		}
 
-		trace := config.tracer.StartStage("Provider P2P exchange ack")
-		// Send ack in separate goroutine and start pinging.
-		// It is important that provider starts sending pings first otherwise
-		// providers router can think that consumer is sending DDoS packets.
-		go func(reply string) {
-			// race condition still happens when consumer starts to ping until provider did not manage to complete required number of pings
-			// this might be provider / consumer performance dependent
-			// make sleep time dependent on pinger interval and wait for 2 ping iterations
-			// TODO: either reintroduce eventual increase of TTL on consumer or maintain some sane delay
-			dur := traversal.DefaultPingConfig().Interval.Milliseconds() * int64(len(config.localPorts)) / 2
-			log.Debug().Msgf("Delaying pings from consumer for %v ms", dur)
-			time.Sleep(time.Duration(dur) * time.Millisecond)
-
-			if err := m.brokerConn.Publish(reply, []byte("OK")); err != nil {
-				log.Err(err).Msg("Could not publish exchange ack")
-			}
-			config.tracer.EndStage(trace)
-		}(msg.Reply)
-
-		var conn1, conn2 *net.UDPConn
-		if len(config.peerPorts) == requiredConnCount {
-			traceDial := config.tracer.StartStage("Provider P2P dial (upnp)")
-			log.Debug().Msg("Skipping consumer ping")
-			conn1, err = net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[0]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[0]})
-			if err != nil {
-				log.Err(err).Msg("Could not create UDP conn for p2p channel")
-				return
-			}
-			conn2, err = net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[1]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[1]})
-			if err != nil {
-				log.Err(err).Msg("Could not create UDP conn for service")
-				return
-			}
-			config.tracer.EndStage(traceDial)
-		} else {
-			traceDial := config.tracer.StartStage("Provider P2P dial (pinger)")
-			log.Debug().Msgf("Pinging consumer with IP %s using ports %v:%v initial ttl: %v",
-				config.peerIP(), config.localPorts, config.peerPorts, providerInitialTTL)
-			conns, err := m.providerPinger.PingConsumerPeer(context.Background(), providerID.Address, config.peerIP(), config.localPorts, config.peerPorts, providerInitialTTL, requiredConnCount)
-			if err != nil {
-				log.Err(err).Msg("Could not ping peer")
-				return
-			}
-			conn1 = conns[0]
-			conn2 = conns[1]
-			config.tracer.EndStage(traceDial)
+		m.replyExchangeAck(conn, msg.Reply, config)
+
+		conns, err := m.dialPeer(providerID, config)
+		if err != nil {
+			log.Err(err).Msg("Could not dial consumer")
+			return
 		}
 
 		traceAck := config.tracer.StartStage("Provider P2P dial ack")
-		channel, err := newChannel(conn1, config.privateKey, config.peerPubKey)
+		channel, err := newChannel(conns[0], config.privateKey, config.peerPubKey)
 		if err != nil {
 			log.Err(err).Msg("Could not create channel")
 			return
 		}
 		channel.setTracer(config.tracer)
-		channel.setServiceConn(conn2)
+		if err := channel.setObfuscation(config.obfuscation); err != nil {
+			log.Err(err).Msg("Could not set channel obfuscation")
+			channel.Close()
+			return
+		}
+		if err := channel.setServiceConn(conns[1]); err != nil {
+			log.Err(err).Msg("Could not set service conn")
+			channel.Close()
+			return
+		}
 		channel.setUpnpPortsRelease(config.upnpPortsRelease)
+		m.addChannel(config.peerPubKey, channel)
 
 		channelHandlers(channel)
 
 		channel.launchReadSendLoops()
 
 		// Send handlers ready to consumer.
-		if err := m.providerChannelHandlersReady(providerID, serviceType); err != nil {
+		if err := m.providerChannelHandlersReady(conn, providerID, serviceType); err != nil {
 			log.Err(err).Msg("Could not handle channel handlers ready")
 			channel.Close()
 			return
@@ -207,23 +234,133 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		config.tracer.EndStage(traceAck)
 	})
 	if err != nil {
-		if err := configSub.Unsubscribe(); err != nil {
+		if err := unsubscribeConfig(); err != nil {
 			log.Err(err).Msg("Failed to unsubscribe from config exchange topic")
 		}
 		return func() {}, fmt.Errorf("could not get subscribe to config exchange acknowledge topic: %w", err)
 	}
 
+	unsubscribeMigrate, err := conn.Subscribe(configMigrateSubject(providerID, serviceType), func(msg *SignallingMsg) {
+		if err := m.providerStartMigrate(conn, providerID, msg, outboundIP); err != nil {
+			log.Err(err).Msg("Could not handle channel migration")
+			return
+		}
+	})
+	if err != nil {
+		unsubscribeConfig()
+		unsubscribeAck()
+		return func() {}, fmt.Errorf("could not get subscribe to config migrate topic: %w", err)
+	}
+
+	unsubscribeMigrateAck, err := conn.Subscribe(configMigrateACKSubject(providerID, serviceType), func(msg *SignallingMsg) {
+		config, err := m.providerAckConfigExchange(msg)
+		if err != nil {
+			log.Err(err).Msg("Could not handle migrate ack")
+			return
+		}
+		if config.channel == nil {
+			log.Error().Msg("Could not handle migrate ack: config is not pending for channel migration")
+			return
+		}
+
+		m.replyExchangeAck(conn, msg.Reply, config)
+
+		conns, err := m.dialPeer(providerID, config)
+		if err != nil {
+			log.Err(err).Msg("Could not dial consumer for migration")
+			return
+		}
+
+		// Service conn is replaced first so consumer request to migrate service
+		// coming through the rebound channel finds the new conn.
+		if err := config.channel.migrateServiceConn(conns[1]); err != nil {
+			log.Err(err).Msg("Could not migrate service conn")
+			return
+		}
+		if err := config.channel.rebind(conns[0]); err != nil {
+			log.Err(err).Msg("Could not migrate channel")
+			return
+		}
+		config.channel.setUpnpPortsRelease(config.upnpPortsRelease)
+		config.tracer.Finish(m.eventBus, "")
+	})
+	if err != nil {
+		unsubscribeConfig()
+		unsubscribeAck()
+		unsubscribeMigrate()
+		return func() {}, fmt.Errorf("could not get subscribe to config migrate acknowledge topic: %w", err)
+	}
+
 	return func() {
-		if err := configSub.Unsubscribe(); err != nil {
+		if err := unsubscribeConfig(); err != nil {
 			log.Err(err).Msg("Failed to unsubscribe from config exchange topic")
 		}
-		if err := ackSub.Unsubscribe(); err != nil {
+		if err := unsubscribeAck(); err != nil {
 			log.Err(err).Msg("Failed to unsubscribe from config exchange acknowledge topic")
 		}
+		if err := unsubscribeMigrate(); err != nil {
+			log.Err(err).Msg("Failed to unsubscribe from config migrate topic")
+		}
+		if err := unsubscribeMigrateAck(); err != nil {
+			log.Err(err).Msg("Failed to unsubscribe from config migrate acknowledge topic")
+		}
 	}, nil
 }
 
-func (m *listener) providerStartConfigExchange(providerID identity.Identity, msg *nats_lib.Msg, outboundIP string) error {
+// replyExchangeAck sends ack reply to consumer in separate goroutine so provider can start pinging.
+// It is important that provider starts sending pings first otherwise
+// providers router can think that consumer is sending DDoS packets.
+func (m *listener) replyExchangeAck(conn SignallingConn, reply string, config *p2pConnectConfig) {
+	trace := config.tracer.StartStage("Provider P2P exchange ack")
+	go func() {
+		// race condition still happens when consumer starts to ping until provider did not manage to complete required number of pings
+		// this might be provider / consumer performance dependent
+		// make sleep time dependent on pinger interval and wait for 2 ping iterations
+		// TODO: either reintroduce eventual increase of TTL on consumer or maintain some sane delay
+		dur := m.pingConfig.Interval.Milliseconds() * int64(len(config.localPorts)) / 2
+		log.Debug().Msgf("Delaying pings from consumer for %v ms", dur)
+		time.Sleep(time.Duration(dur) * time.Millisecond)
+
+		if err := conn.Publish(reply, []byte("OK")); err != nil {
+			log.Err(err).Msg("Could not publish exchange ack")
+		}
+		config.tracer.EndStage(trace)
+	}()
+}
+
+// dialPeer creates UDP conns to consumer either directly or by pinging consumer if provider is behind NAT.
+func (m *listener) dialPeer(providerID identity.Identity, config *p2pConnectConfig) ([]*net.UDPConn, error) {
+	n := requiredConnCount
+	if len(config.peerPorts) == n {
+		traceDial := config.tracer.StartStage("Provider P2P dial (upnp)")
+		defer config.tracer.EndStage(traceDial)
+
+		log.Debug().Msg("Skipping consumer ping")
+		var conns []*net.UDPConn
+		for i := 0; i < n; i++ {
+			conn, err := net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[i]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[i]})
+			if err != nil {
+				return nil, fmt.Errorf("could not create UDP conn: %w", err)
+			}
+			conns = append(conns, conn)
+		}
+		return conns, nil
+	}
+
+	traceDial := config.tracer.StartStage("Provider P2P dial (pinger)")
+
+	log.Debug().Msgf("Pinging consumer with IP %s using ports %v:%v initial ttl: %v, strategy: %s",
+		config.peerIP(), config.localPorts, config.peerPorts, m.pingConfig.ProviderInitialTTL, m.pingConfig.Strategy)
+	conns, err := m.providerPinger.PingConsumerPeer(context.Background(), providerID.Address, config.peerIP(), config.localPorts, config.peerPorts, m.pingConfig.ProviderInitialTTL, n)
+	if err != nil {
+		config.tracer.FailStage(traceDial, err)
+		return nil, fmt.Errorf("could not ping peer: %w", err)
+	}
+	config.tracer.EndStage(traceDial)
+	return conns, nil
+}
+
+func (m *listener) providerStartConfigExchange(conn SignallingConn, providerID identity.Identity, msg *SignallingMsg, outboundIP string) error {
 	tracer := trace.NewTracer("Provider whole Connect")
 
 	trace := tracer.StartStage("Provider P2P exchange")
@@ -249,12 +386,12 @@ func (m *listener) providerStartConfigExchange(providerID identity.Identity, msg
 	}
 	log.Debug().Msgf("Received consumer public key %s", peerPubKey.Hex())
 
-	publicIP, localPorts, portsRelease, err := m.prepareLocalPorts(providerID.Address, outboundIP, tracer)
+	publicIP, localPorts, portsRelease, err := m.prepareLocalPorts(providerID.Address, outboundIP, requiredConnCount, tracer)
 	if err != nil {
 		return fmt.Errorf("could not prepare ports: %w", err)
 	}
 
-	m.setPendingConfig(p2pConnectConfig{
+	config := p2pConnectConfig{
 		publicIP:         publicIP,
 		localPorts:       localPorts,
 		publicKey:        pubKey,
@@ -264,18 +401,81 @@ func (m *listener) providerStartConfigExchange(providerID identity.Identity, msg
 		upnpPortsRelease: portsRelease,
 		peerPublicIP:     "",
 		peerPorts:        nil,
-	})
+	}
+	m.setPendingConfig(config)
+
+	return m.replyConfigExchange(conn, providerID, msg.Reply, config)
+}
+
+// providerStartMigrate handles consumer request to move established channel to new consumer address.
+// Channel keys are reused so only consumer which owns the channel can complete migration.
+func (m *listener) providerStartMigrate(conn SignallingConn, providerID identity.Identity, msg *SignallingMsg, outboundIP string) error {
+	tracer := trace.NewTracer("Provider whole Migrate")
+
+	trace := tracer.StartStage("Provider P2P migrate exchange")
+	defer tracer.EndStage(trace)
+
+	signedMsg, err := unpackSignedMsg(m.verifier, msg.Data)
+	if err != nil {
+		return fmt.Errorf("could not unpack signed msg: %w", err)
+	}
+	var peerExchangeMsg pb.P2PConfigExchangeMsg
+	if err := proto.Unmarshal(signedMsg.Data, &peerExchangeMsg); err != nil {
+		return err
+	}
+	peerPubKey, err := DecodePublicKey(peerExchangeMsg.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	ch, ok := m.channel(peerPubKey)
+	if !ok {
+		return fmt.Errorf("channel not found for key %s", peerPubKey.Hex())
+	}
+	pubKey, err := ch.publicKey()
+	if err != nil {
+		return err
+	}
+	log.Debug().Msgf("Received channel migration request from consumer with public key %s", peerPubKey.Hex())
+
+	publicIP, localPorts, portsRelease, err := m.prepareLocalPorts(providerID.Address, outboundIP, requiredConnCount, tracer)
+	if err != nil {
+		return fmt.Errorf("could not prepare ports: %w", err)
+	}
 
-	config := pb.P2PConnectConfig{
-		PublicIP: publicIP,
-		Ports:    intToInt32Slice(localPorts),
+	config := p2pConnectConfig{
+		publicIP:         publicIP,
+		localPorts:       localPorts,
+		publicKey:        pubKey,
+		privateKey:       ch.privateKey,
+		peerPubKey:       peerPubKey,
+		tracer:           tracer,
+		upnpPortsRelease: portsRelease,
+		channel:          ch,
 	}
-	configCiphertext, err := encryptConnConfigMsg(&config, privateKey, peerPubKey)
+	m.setPendingConfig(config)
+
+	return m.replyConfigExchange(conn, providerID, msg.Reply, config)
+}
+
+// replyConfigExchange sends provider public key and encrypted connect config to consumer.
+func (m *listener) replyConfigExchange(conn SignallingConn, providerID identity.Identity, reply string, config p2pConnectConfig) error {
+	connConfig := pb.P2PConnectConfig{
+		PublicIP: config.publicIP,
+		Ports:    intToInt32Slice(config.localPorts),
+	}
+	// Obfuscation of migrated channel stays as negotiated initially.
+	if config.channel == nil {
+		for _, method := range m.obfuscation {
+			connConfig.Obfuscation = append(connConfig.Obfuscation, string(method))
+		}
+	}
+	configCiphertext, err := encryptConnConfigMsg(&connConfig, config.privateKey, config.peerPubKey)
 	if err != nil {
 		return fmt.Errorf("could not encrypt config msg: %w", err)
 	}
 	exchangeMsg := pb.P2PConfigExchangeMsg{
-		PublicKey:        pubKey.Hex(),
+		PublicKey:        config.publicKey.Hex(),
 		ConfigCiphertext: configCiphertext,
 	}
 	log.Debug().Msgf("Sending reply with public key %s and encrypted config to consumer", exchangeMsg.PublicKey)
@@ -283,9 +483,9 @@ func (m *listener) providerStartConfigExchange(providerID identity.Identity, msg
 	if err != nil {
 		return fmt.Errorf("could not pack signed message: %w", err)
 	}
-	err = m.brokerConn.Publish(msg.Reply, packedMsg)
+	err = conn.Publish(reply, packedMsg)
 	if err != nil {
-		return fmt.Errorf("could not publish message via broker: %w", err)
+		return fmt.Errorf("could not publish message via signalling: %w", err)
 	}
 	return nil
 }
@@ -294,7 +494,7 @@ func (m *listener) providerStartConfigExchange(providerID identity.Identity, msg
 // required ports count for actual p2p and service connections and fallback to
 // acquiring extra ports for nat pinger if provider is behind nat, port mapping failed
 // and no manual port forwarding is enabled.
-func (m *listener) prepareLocalPorts(id, outboundIP string, tracer *trace.Tracer) (string, []int, []func(), error) {
+func (m *listener) prepareLocalPorts(id, outboundIP string, connCount int, tracer *trace.Tracer) (string, []int, []func(), error) {
 	trace := tracer.StartStage("Provider P2P exchange (ports)")
 	defer tracer.EndStage(trace)
 
@@ -305,14 +505,20 @@ func (m *listener) prepareLocalPorts(id, outboundIP string, tracer *trace.Tracer
 	}
 
 	// First acquire required only ports for needed n connections.
-	localPorts, err := acquireLocalPorts(m.portPool, requiredConnCount)
+	localPorts, err := acquireLocalPorts(m.portPool, connCount)
 	if err != nil {
 		return "", nil, nil, fmt.Errorf("could not acquire initial local ports: %w", err)
 	}
 
 	// Return these ports if provider is not behind NAT.
 	if outboundIP == publicIP {
-		m.eventBus.Publish(event.AppTopicTraversal, event.BuildSuccessfulEvent(id, "public_ip"))
+		event.PublishTraversal(m.eventBus, event.BuildSuccessfulEvent(id, "public_ip"))
+		return publicIP, localPorts, nil, nil
+	}
+
+	// Consumer can dial these ports directly if they are forwarded on the router manually.
+	if m.pingConfig.PreferPortForwarding {
+		event.PublishTraversal(m.eventBus, event.BuildSuccessfulEvent(id, "port_forwarding"))
 		return publicIP, localPorts, nil, nil
 	}
 
@@ -341,7 +547,7 @@ func (m *listener) prepareLocalPorts(id, outboundIP string, tracer *trace.Tracer
 	}
 
 	// Acquire more ports for nat pinger.
-	morePorts, err := acquireLocalPorts(m.portPool, pingMaxPorts-requiredConnCount)
+	morePorts, err := acquireLocalPorts(m.portPool, m.pingConfig.MaxPorts-connCount)
 	if err != nil {
 		return publicIP, nil, nil, fmt.Errorf("could not acquire more local ports: %w", err)
 	}
@@ -353,7 +559,7 @@ func (m *listener) prepareLocalPorts(id, outboundIP string, tracer *trace.Tracer
 	return publicIP, localPorts, nil, nil
 }
 
-func (m *listener) providerAckConfigExchange(msg *nats_lib.Msg) (*p2pConnectConfig, error) {
+func (m *listener) providerAckConfigExchange(msg *SignallingMsg) (*p2pConnectConfig, error) {
 	signedMsg, err := unpackSignedMsg(m.verifier, msg.Data)
 	if err != nil {
 		return nil, fmt.Errorf("could not unpack signed msg: %w", err)
@@ -380,6 +586,11 @@ func (m *listener) providerAckConfigExchange(msg *nats_lib.Msg) (*p2pConnectConf
 
 	log.Debug().Msgf("Decrypted consumer config: %v", peerConfig)
 
+	obfuscation, err := m.acceptObfuscation(peerConfig.Obfuscation)
+	if err != nil {
+		return nil, err
+	}
+
 	return &p2pConnectConfig{
 		peerPublicIP:     peerConfig.PublicIP,
 		peerPorts:        int32ToIntSlice(peerConfig.Ports),
@@ -390,10 +601,29 @@ func (m *listener) providerAckConfigExchange(msg *nats_lib.Msg) (*p2pConnectConf
 		publicIP:         config.publicIP,
 		tracer:           config.tracer,
 		upnpPortsRelease: config.upnpPortsRelease,
+		channel:          config.channel,
+		obfuscation:      obfuscation,
 	}, nil
 }
 
-func (m *listener) providerChannelHandlersReady(providerID identity.Identity, serviceType string) error {
+// acceptObfuscation checks that obfuscation method chosen by consumer was offered by provider.
+func (m *listener) acceptObfuscation(chosen []string) (obfs.Method, error) {
+	if len(chosen) == 0 {
+		return obfs.MethodNone, nil
+	}
+	method := obfs.Method(chosen[0])
+	if method == obfs.MethodNone {
+		return obfs.MethodNone, nil
+	}
+	for _, offered := range m.obfuscation {
+		if offered == method {
+			return method, nil
+		}
+	}
+	return "", fmt.Errorf("consumer chose obfuscation method %q which was not offered", method)
+}
+
+func (m *listener) providerChannelHandlersReady(conn SignallingConn, providerID identity.Identity, serviceType string) error {
 	handlersReadyMsg := pb.P2PChannelHandlersReady{Value: "HANDLERS READY"}
 
 	message, err := proto.Marshal(&handlersReadyMsg)
@@ -402,7 +632,7 @@ func (m *listener) providerChannelHandlersReady(providerID identity.Identity, se
 	}
 
 	log.Debug().Msgf("Sending handlers ready message")
-	return m.brokerConn.Publish(channelHandlersReadySubject(providerID, serviceType), message)
+	return conn.Publish(channelHandlersReadySubject(providerID, serviceType), message)
 }
 
 func (m *listener) pendingConfig(peerPubKey PublicKey) (p2pConnectConfig, bool) {
@@ -423,3 +653,24 @@ func (m *listener) deletePendingConfig(peerPubKey PublicKey) {
 	defer m.pendingConfigsMu.Unlock()
 	delete(m.pendingConfigs, peerPubKey)
 }
+
+func (m *listener) channel(peerPubKey PublicKey) (*channel, bool) {
+	m.channelsMu.Lock()
+	defer m.channelsMu.Unlock()
+	ch, ok := m.channels[peerPubKey]
+	return ch, ok
+}
+
+func (m *listener) addChannel(peerPubKey PublicKey, ch *channel) {
+	m.channelsMu.Lock()
+	defer m.channelsMu.Unlock()
+	m.channels[peerPubKey] = ch
+
+	ch.setOnClose(func() {
+		m.channelsMu.Lock()
+		defer m.channelsMu.Unlock()
+		if m.channels[peerPubKey] == ch {
+			delete(m.channels, peerPubKey)
+		}
+	})
+}
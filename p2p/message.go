@@ -46,6 +46,8 @@ const (
 	TopicSessionStatus = "p2p-session-connectivity-status"
 	// TopicSessionDestroy is a session destroy endpoint for p2p communication.
 	TopicSessionDestroy = "p2p-session-destroy"
+	// TopicSessionMigrate is a request to move session service traffic to the migrated service conn.
+	TopicSessionMigrate = "p2p-session-migrate"
 
 	// TopicPaymentMessage is a payment messages endpoint for p2p communication.
 	TopicPaymentMessage = "p2p-payment-message"
@@ -85,6 +87,7 @@ const (
 	statusCodePublicErr          = 2
 	statusCodeInternalErr        = 3
 	statusCodeHandlerNotFoundErr = 4
+	statusCodeProposalOutdated   = 5
 )
 
 // transportMsg is internal structure for sending and receiving messages.
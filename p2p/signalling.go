@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"fmt"
+
+	nats_lib "github.com/nats-io/nats.go"
+
+	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// SignallingMsg is a message received from signalling server.
+type SignallingMsg struct {
+	Data []byte
+	// Reply is a subject to which receiver can publish reply.
+	Reply string
+}
+
+// SignallingHandler handles messages received from signalling server.
+type SignallingHandler func(msg *SignallingMsg)
+
+// SignallingConn is a connection to signalling server which is used by peers
+// for initial signed config exchange before p2p channel is established.
+type SignallingConn interface {
+	// Contact returns proposal contact which describes how to reach this signalling server.
+	Contact() market.Contact
+
+	// Publish sends data to given subject without waiting for reply.
+	Publish(subject string, data []byte) error
+
+	// Request sends data to given subject and waits for a single reply.
+	Request(ctx context.Context, subject string, data []byte) ([]byte, error)
+
+	// Subscribe registers handler for messages sent to given subject.
+	Subscribe(subject string, handler SignallingHandler) (unsubscribe func() error, err error)
+
+	// Close closes signalling connection.
+	Close()
+}
+
+// SignallingConnector knows how to open signalling connection described by proposal contact.
+type SignallingConnector interface {
+	// ContactType returns proposal contact type which is supported by connector.
+	ContactType() string
+
+	// Connect opens signalling connection to servers from given contact authenticated as given identity.
+	Connect(id identity.Identity, contact market.Contact) (SignallingConn, error)
+}
+
+// NewNATSSignallingConn wraps NATS broker connection as signalling connection.
+func NewNATSSignallingConn(conn nats.Connection) SignallingConn {
+	return &natsSignallingConn{conn: conn}
+}
+
+type natsSignallingConn struct {
+	conn nats.Connection
+}
+
+func (n *natsSignallingConn) Contact() market.Contact {
+	return market.Contact{
+		Type:       ContactTypeV1,
+		Definition: ContactDefinition{BrokerAddresses: n.conn.Servers()},
+	}
+}
+
+func (n *natsSignallingConn) Publish(subject string, data []byte) error {
+	return n.conn.Publish(subject, data)
+}
+
+func (n *natsSignallingConn) Request(ctx context.Context, subject string, data []byte) ([]byte, error) {
+	reply, err := n.conn.RequestWithContext(ctx, subject, data)
+	if err != nil {
+		return nil, err
+	}
+	return reply.Data, nil
+}
+
+func (n *natsSignallingConn) Subscribe(subject string, handler SignallingHandler) (func() error, error) {
+	sub, err := n.conn.Subscribe(subject, func(msg *nats_lib.Msg) {
+		handler(&SignallingMsg{Data: msg.Data, Reply: msg.Reply})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub.Unsubscribe, nil
+}
+
+func (n *natsSignallingConn) Close() {
+	n.conn.Close()
+}
+
+// NewNATSSignallingConnector creates signalling connector for NATS broker contacts.
+func NewNATSSignallingConnector(broker brokerConnector) SignallingConnector {
+	return &natsSignallingConnector{broker: broker}
+}
+
+type natsSignallingConnector struct {
+	broker brokerConnector
+}
+
+func (n *natsSignallingConnector) ContactType() string {
+	return ContactTypeV1
+}
+
+func (n *natsSignallingConnector) Connect(_ identity.Identity, contact market.Contact) (SignallingConn, error) {
+	def, ok := contact.Definition.(ContactDefinition)
+	if !ok {
+		return nil, fmt.Errorf("invalid p2p contact definition: %#v", contact.Definition)
+	}
+	serverURLs, err := nats.ParseServerURIs(def.BrokerAddresses)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := n.broker.Connect(serverURLs...)
+	if err != nil {
+		return nil, err
+	}
+	return NewNATSSignallingConn(conn), nil
+}
@@ -0,0 +1,427 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const (
+	wsOpSubscribe   = "sub"
+	wsOpUnsubscribe = "unsub"
+	wsOpPublish     = "pub"
+	wsOpMessage     = "msg"
+
+	wsReconnectInterval    = 2 * time.Second
+	wsReconnectMaxInterval = time.Minute
+	wsHandshakeTimeout     = 10 * time.Second
+
+	wsHeaderAuthorization = "Authorization"
+	wsHeaderTimestamp     = "X-Timestamp"
+	wsAuthSchema          = "Signature "
+	wsAuthMaxClockSkew    = 5 * time.Minute
+)
+
+var (
+	errSignallingClosed       = errors.New("signalling connection is closed")
+	errSignallingNotConnected = errors.New("signalling connection is not connected to any relay")
+)
+
+// WebSocketAuth returns handshake headers which authenticate node to the given WebSocket relay.
+type WebSocketAuth func(address string) (http.Header, error)
+
+// NewWebSocketAuth authenticates node to WebSocket relays by signing relay host and current time
+// with the identity returned by getIdentity.
+func NewWebSocketAuth(signer identity.SignerFactory, getIdentity func() (identity.Identity, error)) WebSocketAuth {
+	return func(address string) (http.Header, error) {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WebSocket relay address %s: %w", address, err)
+		}
+		id, err := getIdentity()
+		if err != nil {
+			return nil, fmt.Errorf("could not get identity for WebSocket relay auth: %w", err)
+		}
+
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		signature, err := signer(id).Sign(wsAuthMessage(u.Host, timestamp))
+		if err != nil {
+			return nil, fmt.Errorf("could not sign WebSocket relay auth: %w", err)
+		}
+
+		header := http.Header{}
+		header.Set(wsHeaderAuthorization, wsAuthSchema+signature.Base64())
+		header.Set(wsHeaderTimestamp, timestamp)
+		return header, nil
+	}
+}
+
+func wsAuthMessage(host, timestamp string) []byte {
+	return []byte(host + "\n" + timestamp)
+}
+
+// wsFrame is a single message of WebSocket signalling protocol.
+type wsFrame struct {
+	Op      string `json:"op"`
+	Subject string `json:"subject"`
+	Reply   string `json:"reply,omitempty"`
+	Data    []byte `json:"data,omitempty"`
+}
+
+// DialWebSocketSignalling connects to the first reachable WebSocket relay from given addresses.
+// Connection is re-established in background if relay goes away.
+func DialWebSocketSignalling(addresses []string, auth WebSocketAuth) (SignallingConn, error) {
+	c, err := newWebSocketSignalling(addresses, auth)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := c.dial()
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.setConn(conn)
+	go c.readLoop(conn)
+
+	return c, nil
+}
+
+// NewWebSocketSignalling creates signalling conn which keeps connecting to WebSocket relays in background
+// until it is closed. Subscriptions made while relays are unreachable are restored once connected.
+func NewWebSocketSignalling(addresses []string, auth WebSocketAuth) (SignallingConn, error) {
+	c, err := newWebSocketSignalling(addresses, auth)
+	if err != nil {
+		return nil, err
+	}
+	go c.readLoop(nil)
+
+	return c, nil
+}
+
+func newWebSocketSignalling(addresses []string, auth WebSocketAuth) (*wsSignallingConn, error) {
+	if len(addresses) == 0 {
+		return nil, errors.New("no WebSocket relay addresses given")
+	}
+
+	removeFirewallRule, err := firewall.AllowURLAccess(addresses...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allow WebSocket relays %v in firewall: %w", addresses, err)
+	}
+
+	boff := backoff.NewExponentialBackOff()
+	boff.InitialInterval = wsReconnectInterval
+	boff.MaxInterval = wsReconnectMaxInterval
+	boff.MaxElapsedTime = 0
+
+	return &wsSignallingConn{
+		addresses:          addresses,
+		auth:               auth,
+		dialer:             &websocket.Dialer{HandshakeTimeout: wsHandshakeTimeout},
+		backoff:            boff,
+		subs:               make(map[string]SignallingHandler),
+		stop:               make(chan struct{}),
+		removeFirewallRule: removeFirewallRule,
+	}, nil
+}
+
+type wsSignallingConn struct {
+	addresses []string
+	auth      WebSocketAuth
+	dialer    *websocket.Dialer
+	backoff   backoff.BackOff
+
+	mu   sync.Mutex
+	conn *websocket.Conn
+	subs map[string]SignallingHandler
+
+	writeMu sync.Mutex
+
+	stop               chan struct{}
+	once               sync.Once
+	removeFirewallRule func()
+}
+
+func (c *wsSignallingConn) Contact() market.Contact {
+	return market.Contact{
+		Type:       ContactTypeWebSocketV1,
+		Definition: WebSocketContactDefinition{RelayAddresses: c.addresses},
+	}
+}
+
+func (c *wsSignallingConn) Publish(subject string, data []byte) error {
+	return c.write(wsFrame{Op: wsOpPublish, Subject: subject, Data: data})
+}
+
+func (c *wsSignallingConn) Request(ctx context.Context, subject string, data []byte) ([]byte, error) {
+	inbox, err := newInbox()
+	if err != nil {
+		return nil, err
+	}
+
+	replyCh := make(chan []byte, 1)
+	unsubscribe, err := c.Subscribe(inbox, func(msg *SignallingMsg) {
+		select {
+		case replyCh <- msg.Data:
+		default:
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer unsubscribe()
+
+	if err := c.write(wsFrame{Op: wsOpPublish, Subject: subject, Reply: inbox, Data: data}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case reply := <-replyCh:
+		return reply, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.stop:
+		return nil, errSignallingClosed
+	}
+}
+
+func (c *wsSignallingConn) Subscribe(subject string, handler SignallingHandler) (func() error, error) {
+	c.mu.Lock()
+	if _, ok := c.subs[subject]; ok {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("already subscribed to subject %s", subject)
+	}
+	c.subs[subject] = handler
+	c.mu.Unlock()
+
+	// Subscription is restored by the relay connection if it is not established yet.
+	if err := c.write(wsFrame{Op: wsOpSubscribe, Subject: subject}); err != nil && !errors.Is(err, errSignallingNotConnected) {
+		c.deleteSub(subject)
+		return nil, err
+	}
+
+	return func() error {
+		c.deleteSub(subject)
+		err := c.write(wsFrame{Op: wsOpUnsubscribe, Subject: subject})
+		if errors.Is(err, errSignallingNotConnected) {
+			return nil
+		}
+		return err
+	}, nil
+}
+
+func (c *wsSignallingConn) Close() {
+	c.once.Do(func() {
+		close(c.stop)
+		c.mu.Lock()
+		if c.conn != nil {
+			c.conn.Close()
+		}
+		c.mu.Unlock()
+		c.removeFirewallRule()
+	})
+}
+
+func (c *wsSignallingConn) dial() (conn *websocket.Conn, err error) {
+	for _, addr := range c.addresses {
+		var header http.Header
+		if c.auth != nil {
+			if header, err = c.auth(addr); err != nil {
+				log.Warn().Err(err).Msgf("Could not authenticate to WebSocket relay %s", addr)
+				continue
+			}
+		}
+		conn, _, err = c.dialer.Dial(addr, header)
+		if err == nil {
+			return conn, nil
+		}
+		log.Warn().Err(err).Msgf("Could not connect to WebSocket relay %s", addr)
+	}
+	return nil, fmt.Errorf("could not connect to any WebSocket relay: %w", err)
+}
+
+func (c *wsSignallingConn) setConn(conn *websocket.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn = conn
+}
+
+func (c *wsSignallingConn) readLoop(conn *websocket.Conn) {
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	for {
+		if conn == nil {
+			if conn = c.reconnect(); conn == nil {
+				return
+			}
+		}
+
+		var frame wsFrame
+		if err := conn.ReadJSON(&frame); err != nil {
+			select {
+			case <-c.stop:
+				return
+			default:
+			}
+			log.Warn().Err(err).Msg("WebSocket relay connection lost, reconnecting")
+			c.mu.Lock()
+			if c.conn == conn {
+				c.conn = nil
+			}
+			c.mu.Unlock()
+			conn.Close()
+			conn = nil
+
+			select {
+			case <-c.stop:
+				return
+			case <-time.After(wsReconnectInterval):
+			}
+			continue
+		}
+
+		if frame.Op != wsOpMessage {
+			continue
+		}
+		c.mu.Lock()
+		handler, ok := c.subs[frame.Subject]
+		c.mu.Unlock()
+		if ok {
+			go handler(&SignallingMsg{Data: frame.Data, Reply: frame.Reply})
+		}
+	}
+}
+
+// reconnect dials relays with exponential backoff until connection is established
+// or signalling conn is closed and restores all active subscriptions.
+func (c *wsSignallingConn) reconnect() *websocket.Conn {
+	c.backoff.Reset()
+	for {
+		conn, err := c.dial()
+		if err != nil {
+			delay := c.backoff.NextBackOff()
+			log.Debug().Err(err).Msgf("Retrying to connect to WebSocket relays in %s", delay)
+			select {
+			case <-c.stop:
+				return nil
+			case <-time.After(delay):
+			}
+			continue
+		}
+
+		c.mu.Lock()
+		select {
+		case <-c.stop:
+			c.mu.Unlock()
+			conn.Close()
+			return nil
+		default:
+		}
+		c.conn = conn
+		var subjects []string
+		for subject := range c.subs {
+			subjects = append(subjects, subject)
+		}
+		c.mu.Unlock()
+
+		for _, subject := range subjects {
+			if err := c.write(wsFrame{Op: wsOpSubscribe, Subject: subject}); err != nil {
+				log.Err(err).Msgf("Could not restore subscription to %s", subject)
+			}
+		}
+		return conn
+	}
+}
+
+func (c *wsSignallingConn) write(frame wsFrame) error {
+	select {
+	case <-c.stop:
+		return errSignallingClosed
+	default:
+	}
+
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		return errSignallingNotConnected
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return conn.WriteJSON(frame)
+}
+
+func (c *wsSignallingConn) deleteSub(subject string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.subs, subject)
+}
+
+func newInbox() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate inbox: %w", err)
+	}
+	return "_INBOX." + hex.EncodeToString(b), nil
+}
+
+// NewWebSocketSignallingConnector creates signalling connector for WebSocket relay contacts.
+// Relay handshake is signed by the identity which opens the connection.
+func NewWebSocketSignallingConnector(signer identity.SignerFactory) SignallingConnector {
+	return &wsSignallingConnector{signer: signer}
+}
+
+type wsSignallingConnector struct {
+	signer identity.SignerFactory
+}
+
+func (w *wsSignallingConnector) ContactType() string {
+	return ContactTypeWebSocketV1
+}
+
+func (w *wsSignallingConnector) Connect(id identity.Identity, contact market.Contact) (SignallingConn, error) {
+	def, ok := contact.Definition.(WebSocketContactDefinition)
+	if !ok {
+		return nil, fmt.Errorf("invalid p2p contact definition: %#v", contact.Definition)
+	}
+	auth := NewWebSocketAuth(w.signer, func() (identity.Identity, error) {
+		return id, nil
+	})
+	return DialWebSocketSignalling(def.RelayAddresses, auth)
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// NewWebSocketSignallingRelay creates HTTP handler which relays signalling messages
+// between WebSocket clients subscribed to the same subjects.
+// Only clients which signed the handshake with their identity are accepted.
+// Node itself does not serve the relay, it is meant to be embedded by relay servers.
+func NewWebSocketSignallingRelay(verifier identity.Verifier) http.Handler {
+	return &wsRelay{
+		upgrader: websocket.Upgrader{},
+		verifier: verifier,
+		subs:     make(map[string]map[*wsRelayClient]struct{}),
+	}
+}
+
+type wsRelay struct {
+	upgrader websocket.Upgrader
+	verifier identity.Verifier
+
+	mu   sync.RWMutex
+	subs map[string]map[*wsRelayClient]struct{}
+}
+
+type wsRelayClient struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (c *wsRelayClient) write(frame wsFrame) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.WriteJSON(frame)
+}
+
+// ServeHTTP upgrades connection to WebSocket and serves signalling frames until client disconnects.
+func (r *wsRelay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if err := r.authenticate(req); err != nil {
+		log.Warn().Err(err).Msgf("Rejected signalling relay connection from %s", req.RemoteAddr)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := r.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not upgrade signalling relay connection")
+		return
+	}
+	client := &wsRelayClient{conn: conn}
+	defer func() {
+		r.unsubscribeAll(client)
+		conn.Close()
+	}()
+
+	for {
+		var frame wsFrame
+		if err := conn.ReadJSON(&frame); err != nil {
+			return
+		}
+
+		switch frame.Op {
+		case wsOpSubscribe:
+			r.subscribe(frame.Subject, client)
+		case wsOpUnsubscribe:
+			r.unsubscribe(frame.Subject, client)
+		case wsOpPublish:
+			r.publish(frame)
+		}
+	}
+}
+
+// authenticate checks that handshake is signed for this relay host recently.
+func (r *wsRelay) authenticate(req *http.Request) error {
+	auth := req.Header.Get(wsHeaderAuthorization)
+	if !strings.HasPrefix(auth, wsAuthSchema) {
+		return errors.New("missing handshake signature")
+	}
+	timestamp := req.Header.Get(wsHeaderTimestamp)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid handshake timestamp")
+	}
+	skew := time.Since(time.Unix(unix, 0))
+	if skew > wsAuthMaxClockSkew || skew < -wsAuthMaxClockSkew {
+		return errors.New("handshake timestamp is expired")
+	}
+
+	signature := identity.SignatureBase64(strings.TrimPrefix(auth, wsAuthSchema))
+	if !r.verifier.Verify(wsAuthMessage(req.Host, timestamp), signature) {
+		return errors.New("invalid handshake signature")
+	}
+	return nil
+}
+
+func (r *wsRelay) subscribe(subject string, client *wsRelayClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subs[subject]; !ok {
+		r.subs[subject] = make(map[*wsRelayClient]struct{})
+	}
+	r.subs[subject][client] = struct{}{}
+}
+
+func (r *wsRelay) unsubscribe(subject string, client *wsRelayClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.subs[subject], client)
+	if len(r.subs[subject]) == 0 {
+		delete(r.subs, subject)
+	}
+}
+
+func (r *wsRelay) unsubscribeAll(client *wsRelayClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for subject, clients := range r.subs {
+		delete(clients, client)
+		if len(clients) == 0 {
+			delete(r.subs, subject)
+		}
+	}
+}
+
+func (r *wsRelay) publish(frame wsFrame) {
+	r.mu.RLock()
+	var clients []*wsRelayClient
+	for c := range r.subs[frame.Subject] {
+		clients = append(clients, c)
+	}
+	r.mu.RUnlock()
+
+	msg := wsFrame{Op: wsOpMessage, Subject: frame.Subject, Reply: frame.Reply, Data: frame.Data}
+	for _, c := range clients {
+		if err := c.write(msg); err != nil {
+			log.Debug().Err(err).Msgf("Could not relay message to subject %s", frame.Subject)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+var fakeSignerFactory = func(id identity.Identity) identity.Signer {
+	return &identity.SignerFake{}
+}
+
+var fakeWebSocketAuth = NewWebSocketAuth(
+	fakeSignerFactory,
+	func() (identity.Identity, error) {
+		return identity.FromAddress("0x1"), nil
+	},
+)
+
+func TestWebSocketSignallingRelay_RejectsUnauthenticatedClients(t *testing.T) {
+	relay := httptest.NewServer(NewWebSocketSignallingRelay(&identity.VerifierFake{}))
+	defer relay.Close()
+	relayAddr := "ws" + strings.TrimPrefix(relay.URL, "http")
+
+	_, res, err := websocket.DefaultDialer.Dial(relayAddr, nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	header, err := fakeWebSocketAuth("ws://other-relay.example")
+	require.NoError(t, err)
+	_, res, err = websocket.DefaultDialer.Dial(relayAddr, header)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	header, err = fakeWebSocketAuth(relayAddr)
+	require.NoError(t, err)
+	conn, _, err := websocket.DefaultDialer.Dial(relayAddr, header)
+	assert.NoError(t, err)
+	conn.Close()
+}
+
+func TestWebSocketSignallingConnector_SignsWithDialingIdentity(t *testing.T) {
+	relay := httptest.NewServer(NewWebSocketSignallingRelay(&identity.VerifierFake{}))
+	defer relay.Close()
+	relayAddr := "ws" + strings.TrimPrefix(relay.URL, "http")
+
+	var signers []identity.Identity
+	connector := NewWebSocketSignallingConnector(func(id identity.Identity) identity.Signer {
+		signers = append(signers, id)
+		return &identity.SignerFake{}
+	})
+	contact := market.Contact{
+		Type:       ContactTypeWebSocketV1,
+		Definition: WebSocketContactDefinition{RelayAddresses: []string{relayAddr}},
+	}
+
+	conn, err := connector.Connect(identity.FromAddress("0x2"), contact)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	assert.Equal(t, []identity.Identity{identity.FromAddress("0x2")}, signers)
+}
+
+func TestWebSocketSignalling_ConnectsWhenRelayBecomesAvailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	relayAddr := "ws://" + listener.Addr().String()
+	listener.Close()
+
+	provider, err := NewWebSocketSignalling([]string{relayAddr}, fakeWebSocketAuth)
+	require.NoError(t, err)
+	defer provider.Close()
+
+	received := make(chan []byte, 1)
+	_, err = provider.Subscribe("test", func(msg *SignallingMsg) {
+		received <- msg.Data
+	})
+	require.NoError(t, err)
+	assert.Equal(t, errSignallingNotConnected, provider.Publish("test", []byte("hello")))
+
+	listener, err = net.Listen("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	relay := &httptest.Server{Listener: listener, Config: &http.Server{Handler: NewWebSocketSignallingRelay(&identity.VerifierFake{})}}
+	relay.Start()
+	defer relay.Close()
+
+	consumer, err := DialWebSocketSignalling([]string{relayAddr}, fakeWebSocketAuth)
+	require.NoError(t, err)
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for {
+		require.NoError(t, consumer.Publish("test", []byte("hello")))
+		select {
+		case data := <-received:
+			assert.Equal(t, "hello", string(data))
+			return
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+			t.Fatal("provider did not subscribe after relay became available")
+		}
+	}
+}
@@ -17,6 +17,8 @@
 
 package pilvytis
 
+import "github.com/mysteriumnetwork/node/eventbus"
+
 // AppTopicOrderUpdated is an topic when the payment order is updated.
 const AppTopicOrderUpdated = "order_updated"
 
@@ -24,3 +26,13 @@ const AppTopicOrderUpdated = "order_updated"
 type AppEventOrderUpdated struct {
 	OrderSummary
 }
+
+// PublishOrderUpdated publishes payment order updates on the bus.
+func PublishOrderUpdated(publisher eventbus.Publisher, e AppEventOrderUpdated) {
+	eventbus.Handle(AppTopicOrderUpdated).Publish(publisher, e)
+}
+
+// SubscribeOrderUpdated subscribes fn to payment order updates.
+func SubscribeOrderUpdated(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventOrderUpdated)) error {
+	return eventbus.Handle(AppTopicOrderUpdated).Subscribe(subscriber, delivery, fn)
+}
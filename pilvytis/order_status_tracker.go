@@ -141,7 +141,7 @@ func (t *StatusTracker) update() error {
 		for _, newOrder := range newOrders {
 			order := t.getOrCreate(newOrder.ID, newOrder.Identity)
 			if applyChanges(order, &newOrder) {
-				t.eventBus.Publish(AppTopicOrderUpdated, AppEventOrderUpdated{*order})
+				PublishOrderUpdated(t.eventBus, AppEventOrderUpdated{*order})
 			}
 			if newOrder.Status.Incomplete() {
 				keepTracking = true
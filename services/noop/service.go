@@ -24,6 +24,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/location/locationstate"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -43,7 +44,7 @@ type Manager struct {
 }
 
 // ProvideConfig provides the session configuration
-func (manager *Manager) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn) (*service.ConfigParams, error) {
+func (manager *Manager) ProvideConfig(_ string, _ identity.Identity, _ json.RawMessage, _ *net.UDPConn) (*service.ConfigParams, error) {
 	return &service.ConfigParams{}, nil
 }
 
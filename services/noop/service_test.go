@@ -23,6 +23,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/location/locationstate"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/stretchr/testify/assert"
 )
@@ -45,7 +46,7 @@ func Test_GetProposal(t *testing.T) {
 
 func Test_Manager_ProvideConfig(t *testing.T) {
 	manager := NewManager()
-	sessionConfig, err := manager.ProvideConfig("", nil, nil)
+	sessionConfig, err := manager.ProvideConfig("", identity.Identity{}, nil, nil)
 	assert.NoError(t, err)
 	assert.Nil(t, sessionConfig.SessionServiceConfig)
 	assert.Nil(t, sessionConfig.SessionDestroyCallback)
@@ -20,6 +20,7 @@ package openvpn
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"sync"
 	"time"
 
@@ -36,6 +37,8 @@ import (
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	dto_openvpn "github.com/mysteriumnetwork/node/services/openvpn/discovery/dto"
 	"github.com/mysteriumnetwork/node/session"
 )
 
@@ -117,6 +120,9 @@ func (c *Client) Start(ctx context.Context, options connection.ConnectOptions) e
 	if err != nil {
 		return errors.Wrap(err, "failed to unmarshal session config")
 	}
+	if sessionConfig, err = selectTransport(options.Proposal, sessionConfig); err != nil {
+		return errors.Wrap(err, "failed to select transport")
+	}
 
 	c.removeAllowedIPRule, err = firewall.AllowIPAccess(sessionConfig.RemoteIP)
 	if err != nil {
@@ -138,6 +144,34 @@ func (c *Client) Start(ctx context.Context, options connection.ConnectOptions) e
 	return errors.Wrap(err, "failed to start client process")
 }
 
+// selectTransport picks transport mode advertised in the proposal, session config of older providers lacks some of it.
+// Session config which contradicts the proposal is rejected, consumer has chosen the provider by the proposal.
+func selectTransport(proposal market.ServiceProposal, config VPNConfig) (VPNConfig, error) {
+	definition, ok := proposal.ServiceDefinition.(dto_openvpn.ServiceDefinition)
+	if !ok {
+		return config, nil
+	}
+
+	if config.RemoteProtocol == "" {
+		config.RemoteProtocol = definition.Protocol
+	}
+	if definition.Protocol != "" && config.RemoteProtocol != definition.Protocol {
+		return config, fmt.Errorf("session protocol %q does not match proposal protocol %q", config.RemoteProtocol, definition.Protocol)
+	}
+
+	if config.TLSMode == "" {
+		config.TLSMode = definition.TLSMode
+	}
+	if definition.TLSMode != "" && config.TLSMode != definition.TLSMode {
+		return config, fmt.Errorf("session TLS mode %q does not match proposal TLS mode %q", config.TLSMode, definition.TLSMode)
+	}
+
+	if config.RemoteProtocol == "tcp" && config.RemotePort == 0 {
+		config.RemotePort = definition.Port
+	}
+	return config, nil
+}
+
 // Stop stops the connection
 func (c *Client) Stop() {
 	c.stopOnce.Do(func() {
@@ -165,12 +199,14 @@ func (c *Client) GetConfig() (connection.ConsumerConfig, error) {
 
 // VPNConfig structure represents VPN configuration options for given session
 type VPNConfig struct {
-	DNSIPs          string `json:"dns_ips"`
-	RemoteIP        string `json:"remote"`
-	RemotePort      int    `json:"port"`
-	LocalPort       int    `json:"lport"`
-	Ports           []int  `json:"ports"`
-	RemoteProtocol  string `json:"protocol"`
+	DNSIPs         string `json:"dns_ips"`
+	RemoteIP       string `json:"remote"`
+	RemotePort     int    `json:"port"`
+	LocalPort      int    `json:"lport"`
+	Ports          []int  `json:"ports"`
+	RemoteProtocol string `json:"protocol"`
+	// TLSMode is either tls-crypt or tls-auth, providers which don't set it use tls-crypt.
+	TLSMode         string `json:"tls_mode,omitempty"`
 	TLSPresharedKey string `json:"TLSPresharedKey"`
 	CACertificate   string `json:"CACertificate"`
 }
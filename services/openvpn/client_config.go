@@ -99,8 +99,9 @@ func NewClientConfigFromSession(vpnConfig VPNConfig, scriptDir string, runtimeDi
 		clientFileConfig.SetParam("dhcp-option", "DNS", ip)
 	}
 
+	// TCP connects directly to the provider port, so UDP hole punched ports are not used.
 	var remotePort, localPort int
-	if options.ProviderNATConn != nil && vpnConfig.RemoteIP != "127.0.0.1" {
+	if options.ProviderNATConn != nil && vpnConfig.RemoteIP != "127.0.0.1" && vpnConfig.RemoteProtocol != "tcp" {
 		options.ProviderNATConn.Close()
 		remotePort = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
 		localPort = options.ProviderNATConn.LocalAddr().(*net.UDPAddr).Port
@@ -114,7 +115,7 @@ func NewClientConfigFromSession(vpnConfig VPNConfig, scriptDir string, runtimeDi
 	clientFileConfig.SetClientMode(vpnConfig.RemoteIP, remotePort, localPort)
 	clientFileConfig.SetProtocol(vpnConfig.RemoteProtocol)
 	clientFileConfig.SetTLSCACertificate(vpnConfig.CACertificate)
-	clientFileConfig.SetTLSCrypt(vpnConfig.TLSPresharedKey)
+	setTLSMode(clientFileConfig.GenericConfig, runtimeDir, vpnConfig.TLSMode, vpnConfig.TLSPresharedKey, keyDirectionClient)
 
 	return clientFileConfig, nil
 }
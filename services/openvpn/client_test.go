@@ -24,6 +24,8 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	dto_openvpn "github.com/mysteriumnetwork/node/services/openvpn/discovery/dto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,6 +41,24 @@ func TestConnection_ErrorsOnInvalidConfig(t *testing.T) {
 	assert.EqualError(t, err, "failed to unmarshal session config: unexpected end of JSON input")
 }
 
+func TestSelectTransport(t *testing.T) {
+	proposal := market.ServiceProposal{ServiceDefinition: dto_openvpn.ServiceDefinition{Protocol: "tcp", Port: 443, TLSMode: TLSModeAuth}}
+
+	config, err := selectTransport(proposal, VPNConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, VPNConfig{RemoteProtocol: "tcp", RemotePort: 443, TLSMode: TLSModeAuth}, config)
+
+	_, err = selectTransport(proposal, VPNConfig{RemoteProtocol: "udp"})
+	assert.EqualError(t, err, `session protocol "udp" does not match proposal protocol "tcp"`)
+
+	_, err = selectTransport(proposal, VPNConfig{RemoteProtocol: "tcp", TLSMode: TLSModeCrypt})
+	assert.EqualError(t, err, `session TLS mode "tls-crypt" does not match proposal TLS mode "tls-auth"`)
+
+	config, err = selectTransport(market.ServiceProposal{}, VPNConfig{RemoteProtocol: "udp"})
+	assert.NoError(t, err)
+	assert.Equal(t, VPNConfig{RemoteProtocol: "udp"}, config)
+}
+
 func TestConnection_CreatesConnection(t *testing.T) {
 	conn, err := NewClient("./", "./", "./", fakeSignerFactory, ip.NewResolverMock("1.1.1.1"))
 	assert.Nil(t, err)
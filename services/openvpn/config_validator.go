@@ -42,6 +42,7 @@ func NewDefaultValidator() *ConfigValidator {
 	return &ConfigValidator{
 		validators: []ValidateConfig{
 			validProtocol,
+			validTLSMode,
 			validIPFormat,
 			validTLSPresharedKey,
 			validCACertificate,
@@ -69,6 +70,17 @@ func validProtocol(config VPNConfig) error {
 	return errors.New("invalid protocol: " + config.RemoteProtocol)
 }
 
+func validTLSMode(config VPNConfig) error {
+	switch config.TLSMode {
+	case
+		"",
+		TLSModeCrypt,
+		TLSModeAuth:
+		return nil
+	}
+	return errors.New("invalid TLS mode: " + config.TLSMode)
+}
+
 func validIPFormat(config VPNConfig) error {
 	parsed := net.ParseIP(config.RemoteIP)
 	if parsed == nil {
@@ -83,6 +83,12 @@ func TestUnknownProtocolIsNotAllowed(t *testing.T) {
 	assert.Error(t, validProtocol(vpnConfig))
 }
 
+func TestUnknownTLSModeIsNotAllowed(t *testing.T) {
+	assert.NoError(t, validTLSMode(VPNConfig{}))
+	assert.NoError(t, validTLSMode(VPNConfig{TLSMode: TLSModeAuth}))
+	assert.Error(t, validTLSMode(VPNConfig{TLSMode: "none"}))
+}
+
 func TestTLSPresharedKeyIsValid(t *testing.T) {
 	vpnConfig := VPNConfig{TLSPresharedKey: tlsTestKey}
 	assert.NoError(t, validTLSPresharedKey(vpnConfig))
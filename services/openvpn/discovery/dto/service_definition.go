@@ -33,6 +33,12 @@ type ServiceDefinition struct {
 
 	// Transport protocol used by service
 	Protocol string `json:"protocol,omitempty"`
+
+	// Port of the service, advertised when it is fixed (e.g. 443 for TCP)
+	Port int `json:"port,omitempty"`
+
+	// TLSMode used to protect control channel, either "tls-crypt" or "tls-auth"
+	TLSMode string `json:"tls_mode,omitempty"`
 }
 
 // GetLocation returns geographic location of service definition provider
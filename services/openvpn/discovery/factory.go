@@ -25,27 +25,47 @@ import (
 	"github.com/mysteriumnetwork/node/services/openvpn/discovery/dto"
 )
 
+// Transport describes how consumers reach the openvpn service.
+type Transport struct {
+	Protocol string
+	Port     int
+	TLSMode  string
+}
+
 // NewServiceProposalWithLocation creates service proposal description for openvpn service
 func NewServiceProposalWithLocation(
 	loc locationstate.Location,
 	protocol string,
 ) market.ServiceProposal {
-	serviceLocation := market.Location{
-		Continent: loc.Continent,
-		Country:   loc.Country,
-		City:      loc.City,
-		ASN:       loc.ASN,
-		ISP:       loc.ISP,
-		NodeType:  loc.NodeType,
-	}
+	return NewServiceProposalWithLocations(loc, loc, Transport{Protocol: protocol, TLSMode: openvpn.TLSModeCrypt})
+}
 
+// NewServiceProposalWithLocations creates service proposal description for openvpn service
+// whose traffic originates from another location.
+func NewServiceProposalWithLocations(
+	loc, originate locationstate.Location,
+	transport Transport,
+) market.ServiceProposal {
 	return market.ServiceProposal{
 		ServiceType: openvpn.ServiceType,
 		ServiceDefinition: dto.ServiceDefinition{
-			Location:          serviceLocation,
-			LocationOriginate: serviceLocation,
+			Location:          marketLocation(loc),
+			LocationOriginate: marketLocation(originate),
 			SessionBandwidth:  dto.Bandwidth(10 * datasize.MiB),
-			Protocol:          protocol,
+			Protocol:          transport.Protocol,
+			Port:              transport.Port,
+			TLSMode:           transport.TLSMode,
 		},
 	}
 }
+
+func marketLocation(loc locationstate.Location) market.Location {
+	return market.Location{
+		Continent: loc.Continent,
+		Country:   loc.Country,
+		City:      loc.City,
+		ASN:       loc.ASN,
+		ISP:       loc.ISP,
+		NodeType:  loc.NodeType,
+	}
+}
@@ -50,6 +50,7 @@ func Test_NewServiceProposalWithLocation(t *testing.T) {
 				LocationOriginate: proposal.ServiceDefinition.GetLocation(),
 				SessionBandwidth:  83886080,
 				Protocol:          "tcp",
+				TLSMode:           "tls-crypt",
 			},
 		},
 		proposal,
@@ -17,9 +17,50 @@
 
 package openvpn
 
+import (
+	"path/filepath"
+
+	"github.com/mysteriumnetwork/go-openvpn/openvpn/config"
+)
+
 // AuthSignaturePrefix is used to prefix with each session string before calculating signature or extracting identity
 const AuthSignaturePrefix = "MystVpnSessionId:"
 
+const (
+	// TLSModeCrypt encrypts and authenticates control channel with the preshared key, which hides OpenVPN handshake.
+	TLSModeCrypt = "tls-crypt"
+	// TLSModeAuth only authenticates control channel with the preshared key.
+	TLSModeAuth = "tls-auth"
+)
+
+const (
+	keyDirectionServer = "0"
+	keyDirectionClient = "1"
+)
+
+// TLSMode returns control channel protection mode for the given service option.
+func TLSMode(tlsCrypt bool) string {
+	if tlsCrypt {
+		return TLSModeCrypt
+	}
+	return TLSModeAuth
+}
+
+// SetServerTLSMode protects server control channel with the preshared key using given mode.
+func SetServerTLSMode(c *config.GenericConfig, runtimeDir, mode, presharedKey string) {
+	setTLSMode(c, runtimeDir, mode, presharedKey, keyDirectionServer)
+}
+
+// setTLSMode protects control channel with the preshared key, tls-crypt is used unless tls-auth mode is given.
+func setTLSMode(c *config.GenericConfig, runtimeDir, mode, presharedKey, keyDirection string) {
+	if mode != TLSModeAuth {
+		c.SetTLSCrypt(presharedKey)
+		return
+	}
+	c.AddOptions(config.OptionFile("tls-auth", presharedKey, filepath.Join(runtimeDir, "ta.key")))
+	c.SetParam("key-direction", keyDirection)
+}
+
 // ConsumerConfig is used for sending some configuration from consumer to provider
 type ConsumerConfig struct {
 	IP    string `json:"Ip,omitempty"`
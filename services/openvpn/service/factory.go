@@ -19,14 +19,18 @@ package service
 
 import (
 	"crypto/x509/pkix"
+	"net"
 
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/tls"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/nat/mapping"
+	"github.com/mysteriumnetwork/node/session"
 	"github.com/rs/zerolog/log"
 )
 
@@ -38,22 +42,28 @@ func NewManager(nodeOptions node.Options,
 	sessionMap SessionMap,
 	natService nat.NATService,
 	natEventGetter NATEventGetter,
+	portMapper mapping.PortMapper,
 	portPool port.ServicePortSupplier,
 	bus eventbus.EventBus,
 	trafficFirewall firewall.IncomingTrafficFirewall,
+	dnsHandlerFactory dns.HandlerFactory,
 ) *Manager {
 	return &Manager{
 		nodeOptions:     nodeOptions,
 		serviceOptions:  serviceOptions,
 		natService:      natService,
 		natEventGetter:  natEventGetter,
+		portMapper:      portMapper,
 		ports:           portPool,
 		bus:             bus,
 		trafficFirewall: trafficFirewall,
+		dnsHandler:      dnsHandlerFactory,
 		country:         country,
 		ipResolver:      ipResolver,
 
 		openvpnClients: NewClientMap(sessionMap),
+		sessionEgress:  make(map[session.ID]net.IP),
+		clientRules:    make(map[int][]interface{}),
 	}
 }
 
@@ -22,8 +22,10 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sync"
 
 	"github.com/mysteriumnetwork/go-openvpn/openvpn"
+	"github.com/mysteriumnetwork/go-openvpn/openvpn/middlewares/server"
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/middlewares/server/filter"
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/middlewares/state"
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/tls"
@@ -40,6 +42,7 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat"
 	nat_event "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/nat/mapping"
 	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/utils/netutil"
@@ -60,7 +63,9 @@ type Manager struct {
 	natService      nat.NATService
 	ports           port.ServicePortSupplier
 	natEventGetter  NATEventGetter
+	portMapper      mapping.PortMapper
 	dnsProxy        *dns.Proxy
+	dnsHandler      dns.HandlerFactory
 	bus             eventbus.EventBus
 	trafficFirewall firewall.IncomingTrafficFirewall
 	vpnNetwork      net.IPNet
@@ -73,10 +78,18 @@ type Manager struct {
 	nodeOptions     node.Options
 
 	outboundIP    string
+	natOptions    nat.Options
 	country       string
 	dnsIP         net.IP
 	dnsOK         bool
+	tcpPortMapped bool
 	tlsPrimitives *tls.Primitives
+
+	// Each client is NATed to the egress address selected for its consumer when pool is configured.
+	egressPool    *nat.EgressPool
+	egressMu      sync.Mutex
+	sessionEgress map[session.ID]net.IP
+	clientRules   map[int][]interface{}
 }
 
 // Serve starts service - does block
@@ -87,7 +100,7 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 	}
 
 	var dnsPort = 11153
-	dnsHandler, err := dns.ResolveViaSystem()
+	dnsHandler, err := m.dnsHandler()
 	if err == nil {
 		if instance.Policies().HasDNSRules() {
 			dnsHandler = dns.WhitelistAnswers(dnsHandler, m.trafficFirewall, instance.Policies())
@@ -124,6 +137,33 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 		return fmt.Errorf("could not get outbound IP: %w", err)
 	}
 
+	// TCP consumers dial the server port directly, so it is mapped on the router for providers behind NAT.
+	if m.serviceOptions.Protocol == "tcp" {
+		release, ok := m.portMapper.Map(string(instance.ID), "TCP", m.vpnServerPort, "Myst node OpenVPN port mapping")
+		if ok {
+			defer release()
+		}
+		m.tcpPortMapped = ok
+	}
+
+	egressPool, err := nat.NewEgressPool(m.serviceOptions.EgressIPs, m.serviceOptions.EgressSelection)
+	if err != nil {
+		return fmt.Errorf("invalid egress addresses: %w", err)
+	}
+	if !m.serviceOptions.EgressSkipLocalCheck {
+		if err := egressPool.CheckLocal(); err != nil {
+			return fmt.Errorf("invalid egress addresses: %w", err)
+		}
+	}
+	m.egressPool = egressPool
+	m.natOptions = nat.Options{
+		VPNNetwork:        m.vpnNetwork,
+		ProviderExtIP:     net.ParseIP(m.outboundIP),
+		EnableDNSRedirect: m.dnsOK,
+		DNSIP:             m.dnsIP,
+		DNSPort:           dnsPort,
+	}
+
 	m.tlsPrimitives, err = primitiveFactory(m.country, instance.ProviderID.Address)
 	if err != nil {
 		return
@@ -143,14 +183,11 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 		return fmt.Errorf("failed to start Openvpn server: %w", err)
 	}
 
-	if _, err := m.natService.Setup(nat.Options{
-		VPNNetwork:        m.vpnNetwork,
-		ProviderExtIP:     net.ParseIP(m.outboundIP),
-		EnableDNSRedirect: m.dnsOK,
-		DNSIP:             m.dnsIP,
-		DNSPort:           dnsPort,
-	}); err != nil {
-		return fmt.Errorf("failed to setup NAT/firewall rules: %w", err)
+	// With egress addresses configured clients are NATed one by one once they are established.
+	if egressPool.IsEmpty() {
+		if _, err := m.natService.Setup(m.natOptions); err != nil {
+			return fmt.Errorf("failed to setup NAT/firewall rules: %w", err)
+		}
 	}
 
 	s := shaper.New(m.bus)
@@ -180,7 +217,7 @@ func (m *Manager) Stop() error {
 }
 
 // ProvideConfig takes session creation config from end consumer and provides the service configuration to the end consumer
-func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage, conn *net.UDPConn) (*service.ConfigParams, error) {
+func (m *Manager) ProvideConfig(sessionID string, consumerID identity.Identity, sessionConfig json.RawMessage, conn *net.UDPConn) (*service.ConfigParams, error) {
 	if m.vpnServerPort == 0 {
 		return nil, errors.New("service port not initialized")
 	}
@@ -195,6 +232,7 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		RemoteIP:        serverIP,
 		RemotePort:      m.vpnServerPort,
 		RemoteProtocol:  m.serviceOptions.Protocol,
+		TLSMode:         openvpn_service.TLSMode(m.serviceOptions.TLSCrypt),
 		TLSPresharedKey: m.tlsPrimitives.PresharedKey.ToPEMFormat(),
 		CACertificate:   m.tlsPrimitives.CertificateAuthority.ToPEMFormat(),
 	}
@@ -202,12 +240,24 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		vpnConfig.DNSIPs = m.dnsIP.String()
 	}
 
-	if err := proxyOpenVPN(conn, m.vpnServerPort); err != nil {
+	// Consumers connect to the TCP server port directly, hole punched UDP connection is not needed.
+	if m.serviceOptions.Protocol == "tcp" {
+		if conn != nil {
+			conn.Close()
+		}
+		if !m.tcpPortReachable(publicIP) {
+			return nil, errors.New("OpenVPN TCP port is not reachable behind NAT, enable port mapping on the router or forward a fixed port")
+		}
+	} else if err := proxyOpenVPN(conn, m.vpnServerPort); err != nil {
 		return nil, fmt.Errorf("could not proxy connection to OpenVPN server: %w", err)
 	}
 
+	egressIP := m.selectEgress(session.ID(sessionID), consumerID)
 	destroy := func() {
 		log.Info().Msgf("Cleaning up session %s", sessionID)
+		m.egressMu.Lock()
+		delete(m.sessionEgress, session.ID(sessionID))
+		m.egressMu.Unlock()
 
 		sessionClients := m.openvpnClients.GetSessionClients(session.ID(sessionID))
 		for clientID := range sessionClients {
@@ -217,7 +267,71 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		}
 	}
 
-	return &service.ConfigParams{SessionServiceConfig: vpnConfig, SessionDestroyCallback: destroy}, nil
+	params := &service.ConfigParams{SessionServiceConfig: vpnConfig, SessionDestroyCallback: destroy}
+	if egressIP != nil {
+		params.EgressIP = egressIP.String()
+	}
+	return params, nil
+}
+
+// selectEgress picks egress address for the consumer session, nil if the pool is empty.
+func (m *Manager) selectEgress(sessionID session.ID, consumerID identity.Identity) net.IP {
+	egressIP := m.egressPool.Select(consumerID.Address)
+	if egressIP == nil {
+		return nil
+	}
+
+	m.egressMu.Lock()
+	defer m.egressMu.Unlock()
+	m.sessionEgress[sessionID] = egressIP
+	return egressIP
+}
+
+// handleClientEvent NATs established clients to the egress address of their session and removes rules on disconnect.
+func (m *Manager) handleClientEvent(event server.ClientEvent) {
+	switch event.EventType {
+	case server.Established:
+		m.egressMu.Lock()
+		egressIP, ok := m.sessionEgress[session.ID(event.Env["username"])]
+		m.egressMu.Unlock()
+		if !ok {
+			return
+		}
+
+		clientIP := net.ParseIP(event.Env["ifconfig_pool_remote_ip"]).To4()
+		if clientIP == nil {
+			log.Error().Msgf("Could not setup egress address for OpenVPN client %d: unknown client IP", event.ClientID)
+			return
+		}
+		options := m.natOptions
+		options.VPNNetwork = net.IPNet{IP: clientIP, Mask: net.CIDRMask(32, 32)}
+		options.ProviderExtIP = egressIP
+		rules, err := m.natService.Setup(options)
+		if err != nil {
+			log.Error().Err(err).Msgf("Could not setup NAT/firewall rules for OpenVPN client %d", event.ClientID)
+			return
+		}
+
+		m.egressMu.Lock()
+		m.clientRules[event.ClientID] = rules
+		m.egressMu.Unlock()
+	case server.Disconnect:
+		m.egressMu.Lock()
+		rules, ok := m.clientRules[event.ClientID]
+		delete(m.clientRules, event.ClientID)
+		m.egressMu.Unlock()
+		if ok {
+			if err := m.natService.Del(rules); err != nil {
+				log.Error().Err(err).Msgf("Could not remove NAT/firewall rules of OpenVPN client %d", event.ClientID)
+			}
+		}
+	}
+}
+
+// tcpPortReachable checks that consumers are able to dial the TCP server port: provider is not behind NAT,
+// the port is mapped on the router or the operator forwards the fixed port.
+func (m *Manager) tcpPortReachable(publicIP string) bool {
+	return publicIP == m.outboundIP || m.nodeOptions.OptionsNetwork.Localnet || m.tcpPortMapped || m.serviceOptions.Port != 0
 }
 
 func (m *Manager) startServer() error {
@@ -230,6 +344,7 @@ func (m *Manager) startServer() error {
 		m.nodeOptions.BindAddress,
 		m.vpnServerPort,
 		m.serviceOptions.Protocol,
+		openvpn_service.TLSMode(m.serviceOptions.TLSCrypt),
 	)
 
 	openvpnFilterDeny := stringutil.Split(config.GetString(config.FlagFirewallProtectedNetworks), ',')
@@ -240,6 +355,7 @@ func (m *Manager) startServer() error {
 
 	stateChannel := make(chan openvpn.State, 10)
 	m.openvpnAuth = newAuthHandler(m.openvpnClients, identity.NewExtractor())
+	m.openvpnAuth.ClientsSubscribe(m.handleClientEvent)
 	m.openvpnProcess = openvpn.CreateNewProcess(
 		m.nodeOptions.Openvpn.BinaryPath(),
 		vpnServerConfig.GenericConfig,
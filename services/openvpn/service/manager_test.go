@@ -18,9 +18,16 @@
 package service
 
 import (
+	"net"
 	"testing"
 
+	"github.com/mysteriumnetwork/go-openvpn/openvpn/middlewares/server"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/session"
 )
 
 func TestManager_StopNotPanic(t *testing.T) {
@@ -28,3 +35,60 @@ func TestManager_StopNotPanic(t *testing.T) {
 	err := m.Stop()
 	assert.NoError(t, err)
 }
+
+func TestManager_TCPPortReachable(t *testing.T) {
+	m := Manager{outboundIP: "192.168.1.10"}
+	assert.True(t, m.tcpPortReachable("192.168.1.10"))
+	assert.False(t, m.tcpPortReachable("1.2.3.4"))
+
+	m.tcpPortMapped = true
+	assert.True(t, m.tcpPortReachable("1.2.3.4"))
+
+	m = Manager{outboundIP: "192.168.1.10", serviceOptions: Options{Protocol: "tcp", Port: 443}}
+	assert.True(t, m.tcpPortReachable("1.2.3.4"))
+}
+
+func TestManager_ClientsAreNATedToConsumerEgress(t *testing.T) {
+	egressPool, err := nat.NewEgressPool([]string{"1.1.1.1", "2.2.2.2"}, nat.EgressSticky)
+	require.NoError(t, err)
+	natService := &mockNATService{}
+	m := Manager{
+		natService:    natService,
+		egressPool:    egressPool,
+		sessionEgress: make(map[session.ID]net.IP),
+		clientRules:   make(map[int][]interface{}),
+	}
+
+	consumerID := identity.FromAddress("0x1")
+	egressIP := m.selectEgress("session-1", consumerID)
+	assert.Equal(t, egressPool.Select(consumerID.Address), egressIP)
+
+	m.handleClientEvent(server.ClientEvent{
+		EventType: server.Established,
+		ClientID:  7,
+		Env:       map[string]string{"username": "session-1", "ifconfig_pool_remote_ip": "10.8.0.6"},
+	})
+	require.Len(t, natService.setups, 1)
+	assert.Equal(t, "10.8.0.6/32", natService.setups[0].VPNNetwork.String())
+	assert.Equal(t, egressIP, natService.setups[0].ProviderExtIP)
+
+	m.handleClientEvent(server.ClientEvent{EventType: server.Disconnect, ClientID: 7})
+	assert.Equal(t, 1, natService.deleted)
+	assert.Empty(t, m.clientRules)
+}
+
+type mockNATService struct {
+	nat.NATService
+	setups  []nat.Options
+	deleted int
+}
+
+func (m *mockNATService) Setup(opts nat.Options) ([]interface{}, error) {
+	m.setups = append(m.setups, opts)
+	return []interface{}{opts}, nil
+}
+
+func (m *mockNATService) Del(rules []interface{}) error {
+	m.deleted++
+	return nil
+}
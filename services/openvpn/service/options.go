@@ -19,9 +19,11 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/nat"
 	"github.com/rs/zerolog/log"
 )
 
@@ -31,16 +33,31 @@ type Options struct {
 	Port     int    `json:"port"`
 	Subnet   string `json:"subnet"`
 	Netmask  string `json:"netmask"`
+	// TLSCrypt obfuscates control channel with tls-crypt, tls-auth is used otherwise.
+	TLSCrypt bool `json:"tls_crypt"`
+	// EgressIPs lists public addresses the service can be NATed to, outbound IP is used if empty.
+	// Every OpenVPN client is NATed to the address selected for its consumer.
+	EgressIPs       []string `json:"egress_ips,omitempty"`
+	EgressSelection string   `json:"egress_selection,omitempty"`
+	// EgressSkipLocalCheck allows egress addresses which are not assigned to local interfaces, e.g. on 1:1 NAT hosts.
+	EgressSkipLocalCheck bool `json:"egress_skip_local_check,omitempty"`
 }
 
 // GetOptions returns effective OpenVPN service options from application configuration.
 func GetOptions() Options {
-	return Options{
+	options := Options{
 		Protocol: config.GetString(config.FlagOpenvpnProtocol),
 		Port:     config.GetInt(config.FlagOpenvpnPort),
 		Subnet:   config.GetString(config.FlagOpenvpnSubnet),
 		Netmask:  config.GetString(config.FlagOpenvpnNetmask),
+		TLSCrypt: config.GetBool(config.FlagOpenvpnTLSCrypt),
 	}
+	if ips := config.GetStringSlice(config.FlagEgressIPs); len(ips) > 0 {
+		options.EgressIPs = ips
+		options.EgressSelection = config.GetString(config.FlagEgressSelection)
+		options.EgressSkipLocalCheck = config.GetBool(config.FlagEgressSkipLocalCheck)
+	}
+	return options
 }
 
 // ParseJSONOptions function fills in OpenVPN options from JSON request, falling back to configured options for
@@ -55,5 +72,11 @@ func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 		log.Warn().Err(err).Msg("Failed to parse options from request, using effective options")
 		return &Options{}, err
 	}
+	if requestOptions.Protocol != "udp" && requestOptions.Protocol != "tcp" {
+		return &Options{}, fmt.Errorf("invalid protocol %q, expected udp or tcp", requestOptions.Protocol)
+	}
+	if _, err := nat.NewEgressPool(requestOptions.EgressIPs, requestOptions.EgressSelection); err != nil {
+		return &Options{}, err
+	}
 	return requestOptions, nil
 }
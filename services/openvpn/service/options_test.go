@@ -32,6 +32,7 @@ var DefaultOptionsOpenvpn = Options{
 	Port:     config.FlagOpenvpnPort.Value,
 	Subnet:   config.FlagOpenvpnSubnet.Value,
 	Netmask:  config.FlagOpenvpnNetmask.Value,
+	TLSCrypt: config.FlagOpenvpnTLSCrypt.Value,
 }
 
 func Test_ParseJSONOptions_HandlesNil(t *testing.T) {
@@ -62,9 +63,25 @@ func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
 		Port:     1123,
 		Subnet:   "10.10.10.0",
 		Netmask:  "255.255.255.0",
+		TLSCrypt: true,
 	}, options)
 }
 
+func Test_ParseJSONOptions_TCP(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"port": 443, "protocol": "tcp", "tls_crypt": false}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "tcp", options.(Options).Protocol)
+	assert.Equal(t, 443, options.(Options).Port)
+	assert.False(t, options.(Options).TLSCrypt)
+
+	request = json.RawMessage(`{"protocol": "sctp"}`)
+	_, err = ParseJSONOptions(&request)
+	assert.Error(t, err)
+}
+
 func configureDefaults() {
 	ctx := emptyContext()
 	config.ParseFlagsServiceOpenvpn(ctx)
@@ -20,6 +20,7 @@ package service
 import (
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/config"
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/tls"
+	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn"
 )
 
 // ServerConfig defines openvpn in server mode configuration structure
@@ -58,6 +59,7 @@ func NewServerConfig(
 	bindAddress string,
 	port int,
 	protocol string,
+	tlsMode string,
 ) *ServerConfig {
 	serverConfig := ServerConfig{config.NewConfig(runtimeDir, scriptDir)}
 	serverConfig.SetServerMode(port, network, netmask)
@@ -68,7 +70,7 @@ func NewServerConfig(
 		secPrimitives.ServerCertificate.ToPEMFormat(),
 		secPrimitives.ServerCertificate.KeyToPEMFormat(),
 	)
-	serverConfig.SetTLSCrypt(secPrimitives.PresharedKey.ToPEMFormat())
+	openvpn_service.SetServerTLSMode(serverConfig.GenericConfig, runtimeDir, tlsMode, secPrimitives.PresharedKey.ToPEMFormat())
 
 	serverConfig.SetParam("cipher", "AES-256-GCM")
 	serverConfig.SetParam("verb", "3")
@@ -46,7 +46,7 @@ func (sb *statsPublisher) handleStatsEvent(clientStats bytecount.SessionByteCoun
 		return
 	}
 
-	sb.bus.Publish(event.AppTopicDataTransferred, event.AppEventDataTransferred{
+	event.PublishDataTransferred(sb.bus, event.AppEventDataTransferred{
 		ID:   string(session),
 		Up:   clientStats.BytesOut,
 		Down: clientStats.BytesIn,
@@ -24,6 +24,7 @@ import (
 	"github.com/mysteriumnetwork/node/services/noop"
 	"github.com/mysteriumnetwork/node/services/openvpn"
 	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn/service"
+	"github.com/mysteriumnetwork/node/services/socks5"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	wireguard_service "github.com/mysteriumnetwork/node/services/wireguard/service"
 	"github.com/pkg/errors"
@@ -33,6 +34,7 @@ var (
 	// JSONParsersByType parsers of service specific options from JSON request.
 	JSONParsersByType = map[string]ServiceOptionsParser{
 		noop.ServiceType:      noop.ParseJSONOptions,
+		socks5.ServiceType:    socks5.ParseJSONOptions,
 		openvpn.ServiceType:   openvpn_service.ParseJSONOptions,
 		wireguard.ServiceType: wireguard_service.ParseJSONOptions,
 	}
@@ -43,7 +45,7 @@ type ServiceOptionsParser func(*json.RawMessage) (service.Options, error)
 
 // Types returns all possible service types.
 func Types() []string {
-	return []string{openvpn.ServiceType, wireguard.ServiceType, noop.ServiceType}
+	return []string{openvpn.ServiceType, wireguard.ServiceType, noop.ServiceType, socks5.ServiceType}
 }
 
 // TypeConfiguredOptions returns specific service options.
@@ -55,6 +57,8 @@ func TypeConfiguredOptions(serviceType string) (service.Options, error) {
 		return wireguard_service.GetOptions(), nil
 	case noop.ServiceType:
 		return noop.GetOptions(), nil
+	case socks5.ServiceType:
+		return socks5.GetOptions(), nil
 	default:
 		return nil, errors.Errorf("unknown service type: %q", serviceType)
 	}
@@ -0,0 +1,199 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package socks5
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-yamux"
+	"github.com/rs/zerolog/log"
+	"github.com/xtaci/kcp-go/v5"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+)
+
+// NewConnection creates a new SOCKS5 connection which accepts local proxy clients on the given address.
+func NewConnection(listenAddress string) (connection.Connection, error) {
+	return &Connection{
+		listenAddress: listenAddress,
+		stateCh:       make(chan connectionstate.State, 100),
+	}, nil
+}
+
+// Connection forwards local proxy clients to the provider over the p2p service connection.
+type Connection struct {
+	listenAddress string
+	stateCh       chan connectionstate.State
+	key           []byte
+	traffic       trafficCounter
+
+	conn       *net.UDPConn
+	kcpSession *kcp.UDPSession
+	mux        *yamux.Session
+	listener   net.Listener
+
+	stopOnce sync.Once
+}
+
+var (
+	_ connection.Connection      = &Connection{}
+	_ connection.ProxyConnection = &Connection{}
+)
+
+// State returns connection state channel.
+func (c *Connection) State() <-chan connectionstate.State {
+	return c.stateCh
+}
+
+// Statistics returns connection statistics channel.
+func (c *Connection) Statistics() (connectionstate.Statistics, error) {
+	sent, received := c.traffic.stats()
+	return connectionstate.Statistics{
+		At:            time.Now(),
+		BytesSent:     sent,
+		BytesReceived: received,
+	}, nil
+}
+
+// Start implements the connection.Connection interface
+func (c *Connection) Start(ctx context.Context, options connection.ConnectOptions) (err error) {
+	if options.ProviderNATConn == nil {
+		return errors.New("p2p service connection is required")
+	}
+	if c.key == nil {
+		return errors.New("consumer config was not created")
+	}
+
+	c.stateCh <- connectionstate.Connecting
+	defer func() {
+		if err != nil {
+			c.Stop()
+		}
+	}()
+
+	blockCrypt, err := newBlockCrypt(c.key)
+	if err != nil {
+		return err
+	}
+
+	remoteAddr := options.ProviderNATConn.RemoteAddr()
+	c.conn, err = reopenConn(options.ProviderNATConn)
+	if err != nil {
+		return err
+	}
+
+	c.kcpSession, err = kcp.NewConn3(kcpConvID, remoteAddr, blockCrypt, 0, 0, c.conn)
+	if err != nil {
+		return fmt.Errorf("could not create KCP session: %w", err)
+	}
+	configureSession(c.kcpSession)
+
+	c.mux, err = yamux.Client(&meteredConn{Conn: c.kcpSession, counter: &c.traffic}, muxConfig())
+	if err != nil {
+		return fmt.Errorf("could not create stream multiplexer: %w", err)
+	}
+
+	listenAddress := c.listenAddress
+	if options.Params.ProxyAddress != "" {
+		listenAddress = options.Params.ProxyAddress
+	}
+	if err := connection.ValidateProxyAddress(listenAddress); err != nil {
+		return fmt.Errorf("invalid proxy address: %w", err)
+	}
+	c.listener, err = net.Listen("tcp", listenAddress)
+	if err != nil {
+		return fmt.Errorf("could not listen for proxy clients: %w", err)
+	}
+
+	go c.acceptClients(c.listener, c.mux)
+	go func() {
+		<-c.mux.CloseChan()
+		c.Stop()
+	}()
+
+	log.Info().Msgf("SOCKS5/HTTP proxy is available at %s", c.listener.Addr())
+	c.stateCh <- connectionstate.Connected
+	return nil
+}
+
+// ProxyAddr returns the address local proxy clients connect to.
+func (c *Connection) ProxyAddr() string {
+	if c.listener == nil {
+		return ""
+	}
+	return c.listener.Addr().String()
+}
+
+func (c *Connection) acceptClients(listener net.Listener, mux *yamux.Session) {
+	for {
+		client, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			stream, err := mux.Open()
+			if err != nil {
+				log.Warn().Err(err).Msg("Could not open proxy stream")
+				client.Close()
+				return
+			}
+			pipe(client, stream)
+		}()
+	}
+}
+
+// Stop implements the connection.Connection interface
+func (c *Connection) Stop() {
+	c.stopOnce.Do(func() {
+		c.stateCh <- connectionstate.Disconnecting
+
+		if c.listener != nil {
+			c.listener.Close()
+		}
+		if c.mux != nil {
+			c.mux.Close()
+		}
+		if c.kcpSession != nil {
+			c.kcpSession.Close()
+		}
+		if c.conn != nil {
+			c.conn.Close()
+		}
+
+		c.stateCh <- connectionstate.NotConnected
+		close(c.stateCh)
+	})
+}
+
+// GetConfig returns the consumer configuration for session creation
+func (c *Connection) GetConfig() (connection.ConsumerConfig, error) {
+	key, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	c.key = key
+
+	return ConsumerConfig{Key: key}, nil
+}
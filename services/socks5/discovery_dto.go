@@ -0,0 +1,42 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package socks5
+
+import (
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// ServiceType indicates "socks5" service type
+const ServiceType = "socks5"
+
+// ServiceDefinition structure represents "socks5" service parameters
+type ServiceDefinition struct {
+	// Approximate information on location where the service is provided from
+	Location market.Location `json:"location"`
+}
+
+// GetLocation returns geographic location of service definition provider
+func (service ServiceDefinition) GetLocation() market.Location {
+	return service.Location
+}
+
+// ConsumerConfig is the configuration consumer sends to provider when creating a session.
+type ConsumerConfig struct {
+	// Key is used to encrypt proxied traffic over the p2p service connection.
+	Key []byte `json:"key"`
+}
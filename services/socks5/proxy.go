@@ -0,0 +1,246 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package socks5
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// ErrDestinationNotAllowed is returned by dialer when destination is forbidden by provider.
+var ErrDestinationNotAllowed = errors.New("destination is not allowed")
+
+const (
+	socksVersion = 0x05
+
+	socksMethodNoAuth       = 0x00
+	socksMethodNoAcceptable = 0xff
+
+	socksCmdConnect = 0x01
+
+	socksAddrIPv4   = 0x01
+	socksAddrDomain = 0x03
+	socksAddrIPv6   = 0x04
+
+	socksReplySucceeded           = 0x00
+	socksReplyGeneralFailure      = 0x01
+	socksReplyNotAllowed          = 0x02
+	socksReplyHostUnreachable     = 0x04
+	socksReplyCmdNotSupported     = 0x07
+	socksReplyAddrTypeUnsupported = 0x08
+)
+
+// Dialer opens connections to proxied destinations.
+type Dialer func(network, address string) (net.Conn, error)
+
+// Proxy serves SOCKS5 and HTTP proxy requests read from client connections.
+type Proxy struct {
+	dial Dialer
+}
+
+// NewProxy creates new proxy which reaches destinations using given dialer.
+func NewProxy(dial Dialer) *Proxy {
+	return &Proxy{dial: dial}
+}
+
+// ServeConn handles a single client connection, the protocol is detected from the first byte.
+// It blocks until the connection is closed.
+func (p *Proxy) ServeConn(conn net.Conn) error {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	first, err := reader.Peek(1)
+	if err != nil {
+		return fmt.Errorf("could not read proxy request: %w", err)
+	}
+
+	if first[0] == socksVersion {
+		return p.serveSOCKS(conn, reader)
+	}
+	return p.serveHTTP(conn, reader)
+}
+
+func (p *Proxy) serveSOCKS(conn net.Conn, reader *bufio.Reader) error {
+	// Method negotiation: VER | NMETHODS | METHODS.
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return fmt.Errorf("could not read SOCKS greeting: %w", err)
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(reader, methods); err != nil {
+		return fmt.Errorf("could not read SOCKS methods: %w", err)
+	}
+	if !containsByte(methods, socksMethodNoAuth) {
+		conn.Write([]byte{socksVersion, socksMethodNoAcceptable})
+		return errors.New("no acceptable SOCKS authentication method")
+	}
+	if _, err := conn.Write([]byte{socksVersion, socksMethodNoAuth}); err != nil {
+		return fmt.Errorf("could not write SOCKS method: %w", err)
+	}
+
+	// Request: VER | CMD | RSV | ATYP | DST.ADDR | DST.PORT.
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(reader, request); err != nil {
+		return fmt.Errorf("could not read SOCKS request: %w", err)
+	}
+	if request[1] != socksCmdConnect {
+		writeSOCKSReply(conn, socksReplyCmdNotSupported)
+		return fmt.Errorf("unsupported SOCKS command %d", request[1])
+	}
+
+	host, err := readSOCKSHost(reader, request[3])
+	if err != nil {
+		writeSOCKSReply(conn, socksReplyAddrTypeUnsupported)
+		return err
+	}
+	portBytes := make([]byte, 2)
+	if _, err := io.ReadFull(reader, portBytes); err != nil {
+		return fmt.Errorf("could not read SOCKS port: %w", err)
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(portBytes))))
+
+	target, err := p.dial("tcp", address)
+	if err != nil {
+		if errors.Is(err, ErrDestinationNotAllowed) {
+			writeSOCKSReply(conn, socksReplyNotAllowed)
+		} else {
+			writeSOCKSReply(conn, socksReplyHostUnreachable)
+		}
+		return fmt.Errorf("could not dial %s: %w", address, err)
+	}
+
+	if err := writeSOCKSReply(conn, socksReplySucceeded); err != nil {
+		target.Close()
+		return err
+	}
+
+	pipe(&bufferedConn{Conn: conn, reader: reader}, target)
+	return nil
+}
+
+func readSOCKSHost(reader *bufio.Reader, addrType byte) (string, error) {
+	switch addrType {
+	case socksAddrIPv4, socksAddrIPv6:
+		size := net.IPv4len
+		if addrType == socksAddrIPv6 {
+			size = net.IPv6len
+		}
+		ip := make(net.IP, size)
+		if _, err := io.ReadFull(reader, ip); err != nil {
+			return "", fmt.Errorf("could not read SOCKS address: %w", err)
+		}
+		return ip.String(), nil
+	case socksAddrDomain:
+		size, err := reader.ReadByte()
+		if err != nil {
+			return "", fmt.Errorf("could not read SOCKS domain length: %w", err)
+		}
+		domain := make([]byte, size)
+		if _, err := io.ReadFull(reader, domain); err != nil {
+			return "", fmt.Errorf("could not read SOCKS domain: %w", err)
+		}
+		return string(domain), nil
+	default:
+		return "", fmt.Errorf("unsupported SOCKS address type %d", addrType)
+	}
+}
+
+func writeSOCKSReply(conn net.Conn, reply byte) error {
+	_, err := conn.Write([]byte{socksVersion, reply, 0x00, socksAddrIPv4, 0, 0, 0, 0, 0, 0})
+	if err != nil {
+		return fmt.Errorf("could not write SOCKS reply: %w", err)
+	}
+	return nil
+}
+
+func (p *Proxy) serveHTTP(conn net.Conn, reader *bufio.Reader) error {
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		return fmt.Errorf("could not read HTTP proxy request: %w", err)
+	}
+
+	address := req.Host
+	if req.Method != http.MethodConnect {
+		if req.URL.Scheme != "http" {
+			writeHTTPStatus(conn, http.StatusBadRequest)
+			return fmt.Errorf("unsupported HTTP proxy request scheme %q", req.URL.Scheme)
+		}
+		address = req.URL.Host
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "80")
+	}
+
+	target, err := p.dial("tcp", address)
+	if err != nil {
+		if errors.Is(err, ErrDestinationNotAllowed) {
+			writeHTTPStatus(conn, http.StatusForbidden)
+		} else {
+			writeHTTPStatus(conn, http.StatusBadGateway)
+		}
+		return fmt.Errorf("could not dial %s: %w", address, err)
+	}
+
+	if req.Method == http.MethodConnect {
+		if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+			target.Close()
+			return fmt.Errorf("could not write HTTP proxy response: %w", err)
+		}
+	} else {
+		req.Header.Del("Proxy-Connection")
+		req.Header.Del("Proxy-Authorization")
+		req.Close = true
+		if err := req.Write(target); err != nil {
+			target.Close()
+			writeHTTPStatus(conn, http.StatusBadGateway)
+			return fmt.Errorf("could not forward HTTP request: %w", err)
+		}
+	}
+
+	pipe(&bufferedConn{Conn: conn, reader: reader}, target)
+	return nil
+}
+
+func writeHTTPStatus(conn net.Conn, status int) {
+	fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\nConnection: close\r\n\r\n", status, http.StatusText(status))
+}
+
+func containsByte(values []byte, value byte) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedConn reads the data already buffered while parsing the proxy request.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
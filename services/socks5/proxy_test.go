@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package socks5
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy_ServeConn_SOCKS(t *testing.T) {
+	echo := startEchoServer(t)
+	defer echo.Close()
+
+	client, server := net.Pipe()
+	defer client.Close()
+	go NewProxy(net.Dial).ServeConn(server)
+
+	_, err := client.Write([]byte{socksVersion, 1, socksMethodNoAuth})
+	require.NoError(t, err)
+	assertRead(t, client, []byte{socksVersion, socksMethodNoAuth})
+
+	addr := echo.Addr().(*net.TCPAddr)
+	request := append([]byte{socksVersion, socksCmdConnect, 0, socksAddrIPv4}, addr.IP.To4()...)
+	request = append(request, byte(addr.Port>>8), byte(addr.Port))
+	_, err = client.Write(request)
+	require.NoError(t, err)
+	assertRead(t, client, []byte{socksVersion, socksReplySucceeded, 0, socksAddrIPv4, 0, 0, 0, 0, 0, 0})
+
+	_, err = client.Write([]byte("ping"))
+	require.NoError(t, err)
+	assertRead(t, client, []byte("ping"))
+}
+
+func TestProxy_ServeConn_SOCKSNotAllowed(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	deny := func(network, address string) (net.Conn, error) {
+		return nil, fmt.Errorf("%w: %s", ErrDestinationNotAllowed, address)
+	}
+	go NewProxy(deny).ServeConn(server)
+
+	_, err := client.Write([]byte{socksVersion, 1, socksMethodNoAuth})
+	require.NoError(t, err)
+	assertRead(t, client, []byte{socksVersion, socksMethodNoAuth})
+
+	request := []byte{socksVersion, socksCmdConnect, 0, socksAddrDomain, 11}
+	request = append(request, []byte("example.com")...)
+	request = append(request, 0, 80)
+	_, err = client.Write(request)
+	require.NoError(t, err)
+	assertRead(t, client, []byte{socksVersion, socksReplyNotAllowed, 0, socksAddrIPv4, 0, 0, 0, 0, 0, 0})
+}
+
+func TestProxy_ServeConn_HTTPConnect(t *testing.T) {
+	echo := startEchoServer(t)
+	defer echo.Close()
+
+	client, server := net.Pipe()
+	defer client.Close()
+	go NewProxy(net.Dial).ServeConn(server)
+
+	_, err := fmt.Fprintf(client, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", echo.Addr(), echo.Addr())
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(client)
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = client.Write([]byte("ping"))
+	require.NoError(t, err)
+	assertRead(t, reader, []byte("ping"))
+}
+
+func TestProxy_ServeConn_HTTPForbidden(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	deny := func(network, address string) (net.Conn, error) {
+		return nil, fmt.Errorf("%w: %s", ErrDestinationNotAllowed, address)
+	}
+	go NewProxy(deny).ServeConn(server)
+
+	_, err := fmt.Fprint(client, "GET http://example.com/ HTTP/1.1\r\nHost: example.com\r\n\r\n")
+	require.NoError(t, err)
+
+	resp, err := http.ReadResponse(bufio.NewReader(client), nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func startEchoServer(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener
+}
+
+func assertRead(t *testing.T, reader io.Reader, expected []byte) {
+	actual := make([]byte, len(expected))
+	_, err := io.ReadFull(reader, actual)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,349 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package socks5
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-yamux"
+	"github.com/rs/zerolog/log"
+	"github.com/xtaci/kcp-go/v5"
+
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+const (
+	statsFrequency = time.Second
+	dialTimeout    = 30 * time.Second
+)
+
+// alwaysProtectedNetworks are never reachable through the proxy, regardless of configured protected networks.
+var alwaysProtectedNetworks = parseNetworks("127.0.0.0/8", "0.0.0.0/8", "::1/128", "169.254.0.0/16", "fe80::/10", "fc00::/7")
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
+
+// DialContext connects to the address, it is used to reach proxied destinations.
+type DialContext func(ctx context.Context, network, address string) (net.Conn, error)
+
+// NewManager creates new instance of SOCKS5 service.
+// Destinations within protected networks are never reachable through the proxy.
+// Destinations are reached directly if dialContext is nil.
+func NewManager(publisher eventbus.Publisher, protectedNetworks []*net.IPNet, dialContext DialContext) *Manager {
+	if dialContext == nil {
+		dialContext = (&net.Dialer{}).DialContext
+	}
+	return &Manager{
+		publisher:         publisher,
+		protectedNetworks: protectedNetworks,
+		dialContext:       dialContext,
+		sessions:          make(map[string]*session),
+		done:              make(chan struct{}),
+	}
+}
+
+// Manager represents entrypoint for SOCKS5 service
+type Manager struct {
+	publisher         eventbus.Publisher
+	protectedNetworks []*net.IPNet
+	dialContext       DialContext
+
+	lock     sync.Mutex
+	instance *service.Instance
+	sessions map[string]*session
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// ProvideConfig starts serving proxy requests of the session over the p2p service connection.
+func (m *Manager) ProvideConfig(sessionID string, _ identity.Identity, sessionConfig json.RawMessage, serviceConn *net.UDPConn) (*service.ConfigParams, error) {
+	if serviceConn == nil {
+		return nil, errors.New("p2p service connection is required")
+	}
+
+	var config ConsumerConfig
+	if err := json.Unmarshal(sessionConfig, &config); err != nil {
+		return nil, fmt.Errorf("could not unmarshal socks5 consumer config: %w", err)
+	}
+
+	blockCrypt, err := newBlockCrypt(config.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := reopenConn(serviceConn)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := kcp.ServeConn(blockCrypt, 0, 0, conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not serve KCP: %w", err)
+	}
+
+	sess := &session{
+		id:       sessionID,
+		conn:     conn,
+		listener: listener,
+		proxy:    NewProxy(m.dial),
+		done:     make(chan struct{}),
+	}
+
+	m.lock.Lock()
+	m.sessions[sessionID] = sess
+	m.lock.Unlock()
+
+	go sess.serve()
+	go sess.publishStats(m.publisher, statsFrequency)
+
+	log.Info().Msgf("SOCKS5 session %s started", sessionID)
+	return &service.ConfigParams{
+		SessionDestroyCallback: func() {
+			m.closeSession(sessionID)
+		},
+	}, nil
+}
+
+// Serve starts service - does block
+func (m *Manager) Serve(instance *service.Instance) error {
+	m.lock.Lock()
+	m.instance = instance
+	m.lock.Unlock()
+
+	log.Info().Msg("SOCKS5 service started successfully")
+	<-m.done
+	return nil
+}
+
+// Stop stops service
+func (m *Manager) Stop() error {
+	m.stopOnce.Do(func() {
+		close(m.done)
+	})
+
+	m.lock.Lock()
+	ids := make([]string, 0, len(m.sessions))
+	for id := range m.sessions {
+		ids = append(ids, id)
+	}
+	m.lock.Unlock()
+
+	for _, id := range ids {
+		m.closeSession(id)
+	}
+
+	log.Info().Msg("SOCKS5 service stopped")
+	return nil
+}
+
+func (m *Manager) closeSession(sessionID string) {
+	m.lock.Lock()
+	sess, ok := m.sessions[sessionID]
+	delete(m.sessions, sessionID)
+	m.lock.Unlock()
+
+	if ok {
+		log.Info().Msgf("Cleaning up session %s", sessionID)
+		sess.close()
+	}
+}
+
+func (m *Manager) policies() *policy.Repository {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.instance == nil {
+		return nil
+	}
+	return m.instance.Policies()
+}
+
+// dial connects to the destination if it is allowed by service access policies and protected networks.
+func (m *Manager) dial(network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	host = strings.TrimRight(host, ".")
+
+	if policies := m.policies(); policies != nil && policies.HasDNSRules() {
+		// DNS rules allow only named hosts, raw IP destinations can not be matched against them.
+		if net.ParseIP(host) != nil || !policies.IsHostAllowed(host) {
+			return nil, fmt.Errorf("%w: %s", ErrDestinationNotAllowed, host)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve %s: %w", host, err)
+	}
+
+	lastErr := fmt.Errorf("%w: %s", ErrDestinationNotAllowed, host)
+	for _, addr := range addrs {
+		if m.isProtected(addr.IP) {
+			continue
+		}
+		conn, err := m.dialContext(ctx, network, net.JoinHostPort(addr.IP.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (m *Manager) isProtected(ip net.IP) bool {
+	if ip.IsUnspecified() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range alwaysProtectedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	for _, network := range m.protectedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type session struct {
+	id       string
+	conn     *net.UDPConn
+	listener *kcp.Listener
+	proxy    *Proxy
+	traffic  trafficCounter
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *session) serve() {
+	for {
+		kcpSession, err := s.listener.AcceptKCP()
+		if err != nil {
+			select {
+			case <-s.done:
+			default:
+				log.Warn().Err(err).Msgf("Stopped accepting connections of session %s", s.id)
+			}
+			return
+		}
+		configureSession(kcpSession)
+		go s.serveMux(kcpSession)
+	}
+}
+
+func (s *session) serveMux(conn net.Conn) {
+	mux, err := yamux.Server(&meteredConn{Conn: conn, counter: &s.traffic}, muxConfig())
+	if err != nil {
+		log.Error().Err(err).Msgf("Could not create stream multiplexer for session %s", s.id)
+		conn.Close()
+		return
+	}
+	go func() {
+		select {
+		case <-s.done:
+		case <-mux.CloseChan():
+		}
+		mux.Close()
+	}()
+
+	for {
+		stream, err := mux.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			if err := s.proxy.ServeConn(stream); err != nil {
+				log.Debug().Err(err).Msgf("Proxy request of session %s failed", s.id)
+			}
+		}()
+	}
+}
+
+func (s *session) publishStats(publisher eventbus.Publisher, frequency time.Duration) {
+	for {
+		select {
+		case <-time.After(frequency):
+			sent, received := s.traffic.stats()
+			event.PublishDataTransferred(publisher, event.AppEventDataTransferred{
+				ID:   s.id,
+				Up:   sent,
+				Down: received,
+			})
+		case <-s.done:
+			log.Info().Msgf("Stopped publishing statistics for session %s", s.id)
+			return
+		}
+	}
+}
+
+func (s *session) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.listener.Close()
+		s.conn.Close()
+	})
+}
+
+// GetProposal returns the proposal for SOCKS5 service for given country
+func GetProposal(location locationstate.Location) market.ServiceProposal {
+	return market.ServiceProposal{
+		ServiceType: ServiceType,
+		ServiceDefinition: ServiceDefinition{
+			Location: market.Location{
+				Continent: location.Continent,
+				Country:   location.Country,
+				City:      location.City,
+
+				ASN:      location.ASN,
+				ISP:      location.ISP,
+				NodeType: location.NodeType,
+			},
+		},
+	}
+}
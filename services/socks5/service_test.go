@@ -0,0 +1,179 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package socks5
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/proxy"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+var _ service.Service = NewManager(nil, nil, nil)
+
+func Test_GetProposal(t *testing.T) {
+	country := "LT"
+	assert.Exactly(
+		t,
+		market.ServiceProposal{
+			ServiceType: "socks5",
+			ServiceDefinition: ServiceDefinition{
+				Location: market.Location{Country: country},
+			},
+		},
+		GetProposal(locationstate.Location{Country: country}),
+	)
+}
+
+func Test_Manager_ProvideConfig_RequiresServiceConn(t *testing.T) {
+	manager := NewManager(mocks.NewEventBus(), nil, nil)
+	_, err := manager.ProvideConfig("session", identity.Identity{}, json.RawMessage(`{}`), nil)
+	assert.Error(t, err)
+}
+
+func Test_Manager_ProxiesConsumerTraffic(t *testing.T) {
+	echo := startEchoServer(t)
+	defer echo.Close()
+
+	// Loopback is never reachable through the proxy, so public destination is redirected to the echo server.
+	redirect := func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, echo.Addr().String())
+	}
+	eventBus := mocks.NewEventBus()
+	manager := NewManager(eventBus, nil, redirect)
+	go manager.Serve(&service.Instance{})
+	defer manager.Stop()
+
+	providerConn, consumerConn := connectedUDPConns(t)
+
+	conn, err := NewConnection("127.0.0.1:0")
+	require.NoError(t, err)
+	consumerConfig, err := conn.GetConfig()
+	require.NoError(t, err)
+	sessionConfig, err := json.Marshal(consumerConfig)
+	require.NoError(t, err)
+
+	config, err := manager.ProvideConfig("session", identity.FromAddress("0x1"), sessionConfig, providerConn)
+	require.NoError(t, err)
+	defer config.SessionDestroyCallback()
+
+	err = conn.Start(context.Background(), connection.ConnectOptions{ProviderNATConn: consumerConn})
+	require.NoError(t, err)
+	defer conn.Stop()
+	assert.Equal(t, connectionstate.Connecting, <-conn.State())
+	assert.Equal(t, connectionstate.Connected, <-conn.State())
+
+	dialer, err := proxy.SOCKS5("tcp", conn.(*Connection).ProxyAddr(), nil, proxy.Direct)
+	require.NoError(t, err)
+	client, err := dialer.Dial("tcp", "198.51.100.1:7")
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte("ping"))
+	require.NoError(t, err)
+	assertRead(t, client, []byte("ping"))
+
+	assert.Eventually(t, func() bool {
+		for _, e := range eventBus.GetEventHistory() {
+			transferred, ok := e.Event.(event.AppEventDataTransferred)
+			if ok && e.Topic == event.AppTopicDataTransferred && transferred.ID == "session" && transferred.Up > 0 && transferred.Down > 0 {
+				return true
+			}
+		}
+		return false
+	}, 3*time.Second, 100*time.Millisecond)
+
+	stats, err := conn.Statistics()
+	require.NoError(t, err)
+	assert.NotZero(t, stats.BytesSent)
+	assert.NotZero(t, stats.BytesReceived)
+}
+
+func Test_Manager_Dial_RespectsProtectedNetworks(t *testing.T) {
+	echo := startEchoServer(t)
+	defer echo.Close()
+
+	_, loopback, _ := net.ParseCIDR("127.0.0.0/8")
+	manager := NewManager(mocks.NewEventBus(), []*net.IPNet{loopback}, nil)
+
+	_, err := manager.dial("tcp", echo.Addr().String())
+	assert.True(t, errors.Is(err, ErrDestinationNotAllowed))
+}
+
+func Test_Manager_IsProtected_AlwaysBlocksLocalNetworks(t *testing.T) {
+	_, custom, _ := net.ParseCIDR("192.168.0.0/16")
+	for _, protected := range [][]*net.IPNet{nil, {custom}} {
+		manager := NewManager(mocks.NewEventBus(), protected, nil)
+
+		for _, ip := range []string{"127.0.0.1", "127.1.2.3", "0.0.0.0", "::1", "169.254.169.254", "fe80::1", "fd00::1", "fc00::1"} {
+			assert.True(t, manager.isProtected(net.ParseIP(ip)), ip)
+		}
+		for _, ip := range []string{"1.1.1.1", "2606:4700:4700::1111"} {
+			assert.False(t, manager.isProtected(net.ParseIP(ip)), ip)
+		}
+	}
+}
+
+func Test_Manager_Dial_RespectsAccessPolicies(t *testing.T) {
+	policies := policy.NewRepository()
+	policies.SetPolicyRules(
+		market.AccessPolicy{ID: "allowed-hosts"},
+		market.AccessPolicyRuleSet{Allow: []market.AccessRule{{Type: market.AccessPolicyTypeDNSHostname, Value: "localhost"}}},
+	)
+	manager := NewManager(mocks.NewEventBus(), nil, nil)
+	manager.instance = service.NewInstance(identity.Identity{}, ServiceType, nil, market.ServiceProposal{}, servicestate.Running, manager, policies, nil)
+
+	_, err := manager.dial("tcp", "127.0.0.1:1")
+	assert.True(t, errors.Is(err, ErrDestinationNotAllowed))
+	_, err = manager.dial("tcp", "example.com:80")
+	assert.True(t, errors.Is(err, ErrDestinationNotAllowed))
+}
+
+func connectedUDPConns(t *testing.T) (*net.UDPConn, *net.UDPConn) {
+	providerListener, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	consumerListener, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	providerAddr := providerListener.LocalAddr().(*net.UDPAddr)
+	consumerAddr := consumerListener.LocalAddr().(*net.UDPAddr)
+	providerListener.Close()
+	consumerListener.Close()
+
+	providerConn, err := net.DialUDP("udp4", providerAddr, consumerAddr)
+	require.NoError(t, err)
+	consumerConn, err := net.DialUDP("udp4", consumerAddr, providerAddr)
+	require.NoError(t, err)
+	return providerConn, consumerConn
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package socks5
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/libp2p/go-yamux"
+	"github.com/xtaci/kcp-go/v5"
+)
+
+const (
+	keySize     = 32
+	kcpMTUSize  = 1280
+	kcpWindow   = 1024
+	kcpConvID   = 1
+	muxInterval = 15 * time.Second
+)
+
+// newKey generates random key used for traffic encryption.
+func newKey() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("could not generate key: %w", err)
+	}
+	return key, nil
+}
+
+func newBlockCrypt(key []byte) (kcp.BlockCrypt, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("invalid key size %d", len(key))
+	}
+	blockCrypt, err := kcp.NewSalsa20BlockCrypt(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not create Sasla20 block crypt: %w", err)
+	}
+	return blockCrypt, nil
+}
+
+func reopenConn(conn *net.UDPConn) (*net.UDPConn, error) {
+	// conn first must be closed to prevent use of WriteTo with pre-connected connection error.
+	conn.Close()
+	conn, err := net.ListenUDP("udp4", conn.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		return nil, fmt.Errorf("could not listen UDP: %w", err)
+	}
+	return conn, nil
+}
+
+func configureSession(sess *kcp.UDPSession) {
+	sess.SetMtu(kcpMTUSize)
+	sess.SetStreamMode(true)
+	sess.SetWindowSize(kcpWindow, kcpWindow)
+	sess.SetNoDelay(1, 10, 2, 1)
+}
+
+func muxConfig() *yamux.Config {
+	config := yamux.DefaultConfig()
+	config.KeepAliveInterval = muxInterval
+	config.LogOutput = ioutil.Discard
+	return config
+}
+
+// trafficCounter counts bytes passing through the proxy transport.
+type trafficCounter struct {
+	sent     uint64
+	received uint64
+}
+
+func (c *trafficCounter) stats() (sent, received uint64) {
+	return atomic.LoadUint64(&c.sent), atomic.LoadUint64(&c.received)
+}
+
+// meteredConn is a net.Conn which reports transferred bytes to the traffic counter.
+type meteredConn struct {
+	net.Conn
+	counter *trafficCounter
+}
+
+func (c *meteredConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	atomic.AddUint64(&c.counter.received, uint64(n))
+	return n, err
+}
+
+func (c *meteredConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	atomic.AddUint64(&c.counter.sent, uint64(n))
+	return n, err
+}
+
+// pipe copies data between both connections until one of them is closed.
+func pipe(a, b io.ReadWriteCloser) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	copyAndClose := func(dst, src io.ReadWriteCloser) {
+		defer wg.Done()
+		io.Copy(dst, src)
+		dst.Close()
+		src.Close()
+	}
+	go copyAndClose(a, b)
+	go copyAndClose(b, a)
+	wg.Wait()
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package upstream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Monitor periodically checks upstream and reports when it goes down and when it recovers.
+type Monitor struct {
+	check       func() error
+	onDown      func(err error)
+	onUp        func()
+	interval    time.Duration
+	maxFailures int
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMonitor creates upstream monitor, onDown is called once check fails maxFailures times in a row,
+// onUp is called once check succeeds again after the upstream was reported down.
+func NewMonitor(check func() error, onDown func(err error), onUp func(), interval time.Duration, maxFailures int) *Monitor {
+	return &Monitor{
+		check:       check,
+		onDown:      onDown,
+		onUp:        onUp,
+		interval:    interval,
+		maxFailures: maxFailures,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Start starts checking upstream in the background.
+func (m *Monitor) Start() {
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		failures := 0
+		down := false
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+			}
+
+			err := m.check()
+			if err == nil {
+				failures = 0
+				if down {
+					down = false
+					m.onUp()
+				}
+				continue
+			}
+			if down {
+				continue
+			}
+
+			failures++
+			log.Warn().Err(err).Msgf("Upstream check failed (%d/%d)", failures, m.maxFailures)
+			if failures >= m.maxFailures {
+				down = true
+				m.onDown(err)
+				failures = 0
+			}
+		}
+	}()
+}
+
+// Stop stops checking upstream.
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package upstream
+
+import (
+	"errors"
+	"io"
+
+	"github.com/mysteriumnetwork/node/nat"
+)
+
+type natService struct {
+	nat.NATService
+	upstream Upstream
+}
+
+// NewNATService wraps NAT service to forward consumer traffic through the upstream interface instead of the provider's own address.
+func NewNATService(service nat.NATService, upstream Upstream) nat.NATService {
+	return &natService{NATService: service, upstream: upstream}
+}
+
+// Setup sets NAT/Firewall rules forwarding consumer traffic through the upstream interface
+// and routes traffic of the VPN network through it.
+func (s *natService) Setup(opts nat.Options) ([]interface{}, error) {
+	route, err := s.upstream.Route(opts.VPNNetwork)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.UpstreamInterface = s.upstream.InterfaceName()
+	rules, err := s.NATService.Setup(opts)
+	if err != nil {
+		route.Close()
+		return nil, err
+	}
+	return append(rules, route), nil
+}
+
+// Del removes rules and stops routing traffic of the VPN networks they were set up for.
+func (s *natService) Del(rules []interface{}) error {
+	natRules := make([]interface{}, 0, len(rules))
+	for _, rule := range rules {
+		if route, ok := rule.(io.Closer); ok {
+			route.Close()
+			continue
+		}
+		natRules = append(natRules, rule)
+	}
+	return s.NATService.Del(natRules)
+}
+
+// ForwardPorts is not supported, consumer replies would be routed through the upstream instead of the forwarded address.
+func (s *natService) ForwardPorts(_ []nat.PortForward) ([]interface{}, error) {
+	return nil, errors.New("port forwarding is not supported when forwarding traffic through upstream")
+}
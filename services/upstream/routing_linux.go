@@ -0,0 +1,59 @@
+// +build linux
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package upstream
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/mysteriumnetwork/node/utils/cmdutil"
+)
+
+// routeTable is the routing table with the default route through the upstream interface.
+const routeTable = "51821"
+
+// addRoutes routes traffic from the tunnel address and from networks added with addSourceRule
+// through the upstream interface, host routing table is not changed.
+func addRoutes(iface string, address net.IP, mtu int) error {
+	if err := cmdutil.SudoExec("ip", "link", "set", "dev", iface, "mtu", strconv.Itoa(mtu)); err != nil {
+		return err
+	}
+	if err := cmdutil.SudoExec("ip", "route", "replace", "default", "dev", iface, "table", routeTable); err != nil {
+		return err
+	}
+	return cmdutil.SudoExec("ip", "rule", "add", "from", address.String(), "lookup", routeTable)
+}
+
+func delRoutes(address net.IP) error {
+	cmdutil.SudoExec("ip", "rule", "del", "from", address.String(), "lookup", routeTable)
+	return cmdutil.SudoExec("ip", "route", "flush", "table", routeTable)
+}
+
+func addSourceRule(network net.IPNet) error {
+	return cmdutil.SudoExec("ip", "rule", "add", "from", prefix(network), "lookup", routeTable)
+}
+
+func delSourceRule(network net.IPNet) error {
+	return cmdutil.SudoExec("ip", "rule", "del", "from", prefix(network), "lookup", routeTable)
+}
+
+// prefix returns network address with the host part cleared, e.g. VPN network may be given by its gateway address.
+func prefix(network net.IPNet) string {
+	return (&net.IPNet{IP: network.IP.Mask(network.Mask), Mask: network.Mask}).String()
+}
@@ -0,0 +1,42 @@
+// +build !linux
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package upstream
+
+import (
+	"errors"
+	"net"
+)
+
+var errRoutingNotSupported = errors.New("upstream is supported only on Linux")
+
+func addRoutes(string, net.IP, int) error {
+	return errRoutingNotSupported
+}
+
+func delRoutes(net.IP) error {
+	return errRoutingNotSupported
+}
+
+func addSourceRule(net.IPNet) error {
+	return errRoutingNotSupported
+}
+
+func delSourceRule(net.IPNet) error {
+	return errRoutingNotSupported
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package upstream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+)
+
+// ErrTCPOnlyUpstream is returned for upstreams which can't forward consumer traffic of all protocols.
+var ErrTCPOnlyUpstream = errors.New("SOCKS5 upstream forwards TCP only, consumer UDP and ICMP traffic could not be chained through it; use WireGuard upstream instead")
+
+// Upstream forwards provider traffic through a tunnel to another exit point.
+type Upstream interface {
+	// DialContext connects to the address through the upstream.
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+	// InterfaceName returns the network interface consumer traffic is routed through.
+	InterfaceName() string
+	// Route starts routing traffic from the network through the upstream, returned closer stops it.
+	Route(network net.IPNet) (io.Closer, error)
+	Close() error
+}
+
+// Options describes the upstream.
+type Options struct {
+	WireGuardConfig string
+	// SOCKS5Address is not supported as an upstream, it is only checked to reject such configuration.
+	SOCKS5Address string
+	// DNS servers used by WireGuard upstream when its configuration has none.
+	DNS []string
+}
+
+// IsEnabled returns true if any upstream is configured.
+func (o Options) IsEnabled() bool {
+	return o.WireGuardConfig != "" || o.SOCKS5Address != ""
+}
+
+// New creates the configured upstream.
+func New(options Options) (Upstream, error) {
+	switch {
+	case options.SOCKS5Address != "":
+		return nil, ErrTCPOnlyUpstream
+	case options.WireGuardConfig != "":
+		file, err := os.Open(options.WireGuardConfig)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		config, err := ParseWireGuardConfig(file)
+		if err != nil {
+			return nil, err
+		}
+		if len(config.DNS) == 0 {
+			config.DNS = options.DNS
+		}
+		return NewWireGuard(config)
+	default:
+		return nil, errors.New("upstream is not configured")
+	}
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package upstream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/nat"
+)
+
+const wgQuickConfig = `
+[Interface]
+PrivateKey = yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk=
+Address = fd00::2/128, 10.8.0.2/32
+DNS = 10.8.0.1, wireguard.example
+MTU = 1380
+
+# Upstream exit
+[Peer]
+PublicKey = xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg=
+Endpoint = 127.0.0.1:51820
+AllowedIPs = 0.0.0.0/0, ::/0
+PersistentKeepalive = 25
+`
+
+func TestParseWireGuardConfig(t *testing.T) {
+	config, err := ParseWireGuardConfig(strings.NewReader(wgQuickConfig))
+	require.NoError(t, err)
+
+	assert.Equal(t, "yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk=", config.Device.PrivateKey)
+	assert.Equal(t, "10.8.0.2", config.Address.String())
+	assert.Equal(t, []string{"10.8.0.1"}, config.DNS)
+	assert.Equal(t, 1380, config.MTU)
+	assert.Equal(t, "xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg=", config.Device.Peer.PublicKey)
+	assert.Equal(t, "127.0.0.1:51820", config.Device.Peer.Endpoint.String())
+	assert.Equal(t, []string{"0.0.0.0/0", "::/0"}, config.Device.Peer.AllowedIPs)
+	assert.Equal(t, 25, config.Device.Peer.KeepAlivePeriodSeconds)
+}
+
+func TestParseWireGuardConfig_Invalid(t *testing.T) {
+	for name, config := range map[string]string{
+		"no peer":       "[Interface]\nPrivateKey = a\nAddress = 10.8.0.2/32\n",
+		"two peers":     wgQuickConfig + "[Peer]\nPublicKey = b\nEndpoint = 127.0.0.1:1\n",
+		"invalid entry": "[Interface]\nPrivateKey\n",
+		"preshared key": wgQuickConfig + "PresharedKey = c\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseWireGuardConfig(strings.NewReader(config))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNew_RejectsSOCKS5(t *testing.T) {
+	_, err := New(Options{SOCKS5Address: "127.0.0.1:1080"})
+	assert.Equal(t, ErrTCPOnlyUpstream, err)
+}
+
+func TestNATService_RoutesThroughUpstream(t *testing.T) {
+	_, network, _ := net.ParseCIDR("10.182.0.0/24")
+	natFake := &natServiceFake{}
+	upstream := &upstreamFake{}
+	service := NewNATService(natFake, upstream)
+
+	rules, err := service.Setup(nat.Options{VPNNetwork: *network})
+	require.NoError(t, err)
+	assert.Equal(t, "myst-upstream", natFake.options.UpstreamInterface)
+	assert.Equal(t, []string{"10.182.0.0/24"}, upstream.routed)
+
+	require.NoError(t, service.Del(rules))
+	assert.Empty(t, upstream.routed)
+	assert.Equal(t, []interface{}{"rule"}, natFake.deleted)
+}
+
+func TestNATService_ForwardPortsIsNotSupported(t *testing.T) {
+	_, err := NewNATService(&natServiceFake{}, &upstreamFake{}).ForwardPorts([]nat.PortForward{{Protocol: "tcp", Port: 40001}})
+	assert.Error(t, err)
+}
+
+func TestMonitor_ReportsDownAfterConsecutiveFailures(t *testing.T) {
+	var checks int32
+	down := make(chan error, 1)
+	monitor := NewMonitor(
+		func() error {
+			// Recovers once, so only the failures after it count.
+			if atomic.AddInt32(&checks, 1) == 2 {
+				return nil
+			}
+			return errors.New("upstream is down")
+		},
+		func(err error) { down <- err },
+		func() {},
+		time.Millisecond,
+		3,
+	)
+	monitor.Start()
+	defer monitor.Stop()
+
+	select {
+	case err := <-down:
+		assert.EqualError(t, err, "upstream is down")
+		assert.GreaterOrEqual(t, atomic.LoadInt32(&checks), int32(5))
+	case <-time.After(time.Second):
+		t.Fatal("upstream was not reported down")
+	}
+}
+
+func TestMonitor_ReportsUpAfterRecovery(t *testing.T) {
+	var checks int32
+	down := make(chan error, 1)
+	up := make(chan struct{}, 1)
+	monitor := NewMonitor(
+		func() error {
+			if atomic.AddInt32(&checks, 1) <= 3 {
+				return errors.New("upstream is down")
+			}
+			return nil
+		},
+		func(err error) { down <- err },
+		func() { up <- struct{}{} },
+		time.Millisecond,
+		3,
+	)
+	monitor.Start()
+	defer monitor.Stop()
+
+	select {
+	case <-down:
+	case <-time.After(time.Second):
+		t.Fatal("upstream was not reported down")
+	}
+	select {
+	case <-up:
+		assert.GreaterOrEqual(t, atomic.LoadInt32(&checks), int32(4))
+	case <-time.After(time.Second):
+		t.Fatal("upstream was not reported up")
+	}
+}
+
+type upstreamFake struct {
+	routed []string
+}
+
+func (u *upstreamFake) DialContext(context.Context, string, string) (net.Conn, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (u *upstreamFake) InterfaceName() string { return "myst-upstream" }
+
+func (u *upstreamFake) Route(network net.IPNet) (io.Closer, error) {
+	u.routed = append(u.routed, network.String())
+	return closerFunc(func() error {
+		u.routed = u.routed[:len(u.routed)-1]
+		return nil
+	}), nil
+}
+
+func (u *upstreamFake) Close() error { return nil }
+
+type natServiceFake struct {
+	nat.NATService
+	options nat.Options
+	deleted []interface{}
+}
+
+func (s *natServiceFake) Setup(opts nat.Options) ([]interface{}, error) {
+	s.options = opts
+	return []interface{}{"rule"}, nil
+}
+
+func (s *natServiceFake) Del(rules []interface{}) error {
+	s.deleted = rules
+	return nil
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package upstream
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"golang.zx2c4.com/wireguard/device"
+
+	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+)
+
+// WireGuardConfig is an upstream WireGuard tunnel configuration.
+type WireGuardConfig struct {
+	Device  wgcfg.DeviceConfig
+	Address net.IP
+	DNS     []string
+	MTU     int
+}
+
+// ParseWireGuardConfig parses wg-quick style configuration file.
+// Only IPv4 tunnel address is used and a single peer is supported.
+func ParseWireGuardConfig(r io.Reader) (WireGuardConfig, error) {
+	config := WireGuardConfig{MTU: device.DefaultMTU}
+	config.Device.Peer.AllowedIPs = []string{"0.0.0.0/0"}
+
+	var section string
+	var peers int
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexAny(text, "#;"); i >= 0 {
+			text = strings.TrimSpace(text[:i])
+		}
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+			section = strings.ToLower(text[1 : len(text)-1])
+			if section == "peer" {
+				peers++
+			}
+			continue
+		}
+
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return WireGuardConfig{}, fmt.Errorf("line %d: invalid entry %q", line, text)
+		}
+		key, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+		if err := config.set(section, key, value); err != nil {
+			return WireGuardConfig{}, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return WireGuardConfig{}, err
+	}
+
+	switch {
+	case peers != 1:
+		return WireGuardConfig{}, fmt.Errorf("exactly one peer is required, got %d", peers)
+	case config.Device.PrivateKey == "":
+		return WireGuardConfig{}, fmt.Errorf("interface private key is required")
+	case config.Address == nil:
+		return WireGuardConfig{}, fmt.Errorf("interface IPv4 address is required")
+	case config.Device.Peer.PublicKey == "":
+		return WireGuardConfig{}, fmt.Errorf("peer public key is required")
+	case config.Device.Peer.Endpoint == nil:
+		return WireGuardConfig{}, fmt.Errorf("peer endpoint is required")
+	}
+	return config, nil
+}
+
+func (c *WireGuardConfig) set(section, key, value string) (err error) {
+	switch section + "." + key {
+	case "interface.privatekey":
+		c.Device.PrivateKey = value
+	case "interface.address":
+		for _, address := range splitList(value) {
+			ip, _, err := net.ParseCIDR(address)
+			if err != nil {
+				ip = net.ParseIP(address)
+			}
+			if ip == nil {
+				return fmt.Errorf("invalid address %q", address)
+			}
+			if ip.To4() != nil && c.Address == nil {
+				c.Address = ip.To4()
+			}
+		}
+	case "interface.dns":
+		// Non IP entries are search domains.
+		for _, server := range splitList(value) {
+			if net.ParseIP(server) != nil {
+				c.DNS = append(c.DNS, server)
+			}
+		}
+	case "interface.mtu":
+		c.MTU, err = strconv.Atoi(value)
+	case "interface.listenport":
+		c.Device.ListenPort, err = strconv.Atoi(value)
+	case "peer.publickey":
+		c.Device.Peer.PublicKey = value
+	case "peer.endpoint":
+		c.Device.Peer.Endpoint, err = net.ResolveUDPAddr("udp4", value)
+	case "peer.allowedips":
+		c.Device.Peer.AllowedIPs = splitList(value)
+	case "peer.persistentkeepalive":
+		c.Device.Peer.KeepAlivePeriodSeconds, err = strconv.Atoi(value)
+	case "peer.presharedkey":
+		return fmt.Errorf("preshared keys are not supported")
+	default:
+		// wg-quick specific entries (PostUp, Table, SaveConfig...) don't apply to the userspace tunnel.
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return nil
+}
+
+func splitList(value string) (list []string) {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
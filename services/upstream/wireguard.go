@@ -0,0 +1,166 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/endpoint"
+)
+
+// interfaceName is the upstream tunnel interface, it has no allocator prefix so WireGuard services don't clean it up.
+const interfaceName = "myst-upstream"
+
+type wireGuardUpstream struct {
+	endpoint wg.ConnectionEndpoint
+	address  net.IP
+	dns      []string
+
+	mu     sync.Mutex
+	routes map[string]*sourceRoute
+}
+
+// sourceRoute is a network routed through the upstream, it is shared by services using the same network.
+type sourceRoute struct {
+	network net.IPNet
+	refs    int
+}
+
+// NewWireGuard creates upstream which routes traffic through the WireGuard tunnel interface.
+// Only traffic routed to the upstream routing table goes through it, host routes are not changed.
+func NewWireGuard(config WireGuardConfig) (Upstream, error) {
+	connEndpoint, err := endpoint.NewConnectionEndpoint(nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create WireGuard upstream: %w", err)
+	}
+
+	device := config.Device
+	device.IfaceName = interfaceName
+	device.Subnet = net.IPNet{IP: config.Address, Mask: net.CIDRMask(32, 32)}
+	// Peer endpoint is set once the device is up, otherwise its allowed IPs would be routed through the main table.
+	peerEndpoint := device.Peer.Endpoint
+	device.Peer.Endpoint = nil
+	if err := connEndpoint.StartConsumerMode(device); err != nil {
+		return nil, fmt.Errorf("could not start WireGuard upstream: %w", err)
+	}
+	if err := connEndpoint.Rebind(device.ListenPort, peerEndpoint); err != nil {
+		connEndpoint.Stop()
+		return nil, fmt.Errorf("could not set WireGuard upstream endpoint: %w", err)
+	}
+	if err := addRoutes(interfaceName, config.Address, config.MTU); err != nil {
+		connEndpoint.Stop()
+		return nil, fmt.Errorf("could not route through WireGuard upstream: %w", err)
+	}
+
+	return &wireGuardUpstream{
+		endpoint: connEndpoint,
+		address:  config.Address,
+		dns:      config.DNS,
+		routes:   make(map[string]*sourceRoute),
+	}, nil
+}
+
+// DialContext connects from the tunnel address, so the connection is routed through the upstream.
+func (u *wireGuardUpstream) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{LocalAddr: u.localAddr(network)}
+	if len(u.dns) > 0 {
+		dialer.Resolver = &net.Resolver{PreferGo: true, Dial: u.dialDNS}
+	}
+	return dialer.DialContext(ctx, network, address)
+}
+
+// dialDNS connects to the upstream DNS servers instead of the system ones.
+func (u *wireGuardUpstream) dialDNS(ctx context.Context, network, _ string) (conn net.Conn, err error) {
+	dialer := &net.Dialer{LocalAddr: u.localAddr(network)}
+	for _, server := range u.dns {
+		if conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(server, "53")); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+func (u *wireGuardUpstream) localAddr(network string) net.Addr {
+	if strings.HasPrefix(network, "udp") {
+		return &net.UDPAddr{IP: u.address}
+	}
+	return &net.TCPAddr{IP: u.address}
+}
+
+func (u *wireGuardUpstream) InterfaceName() string {
+	return interfaceName
+}
+
+func (u *wireGuardUpstream) Route(network net.IPNet) (io.Closer, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	key := network.String()
+	route, ok := u.routes[key]
+	if !ok {
+		if err := addSourceRule(network); err != nil {
+			return nil, err
+		}
+		route = &sourceRoute{network: network}
+		u.routes[key] = route
+	}
+	route.refs++
+
+	var once sync.Once
+	return closerFunc(func() (err error) {
+		once.Do(func() {
+			err = u.release(key, route)
+		})
+		return err
+	}), nil
+}
+
+func (u *wireGuardUpstream) release(key string, route *sourceRoute) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if route.refs--; route.refs > 0 || u.routes[key] != route {
+		return nil
+	}
+	delete(u.routes, key)
+	return delSourceRule(route.network)
+}
+
+func (u *wireGuardUpstream) Close() error {
+	u.mu.Lock()
+	for key, route := range u.routes {
+		delSourceRule(route.network)
+		delete(u.routes, key)
+	}
+	u.mu.Unlock()
+
+	delRoutes(u.address)
+	return u.endpoint.Stop()
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
@@ -26,21 +26,29 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"golang.zx2c4.com/wireguard/tun"
 
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/firewall"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/endpoint"
 	"github.com/mysteriumnetwork/node/services/wireguard/key"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
+// splitTunnelRefreshInterval defines how often split tunnel domains are resolved again.
+const splitTunnelRefreshInterval = 5 * time.Minute
+
 // Options represents connection options.
 type Options struct {
 	DNSScriptDir     string
 	HandshakeTimeout time.Duration
+	// UserspaceFallbackDNS is used in userspace mode when no DNS servers are resolved for the connection.
+	UserspaceFallbackDNS []string
 }
 
 // NewConnection returns new WireGuard connection.
@@ -58,6 +66,11 @@ func NewConnection(opts Options, ipResolver ip.Resolver, endpointFactory wg.Endp
 		ipResolver:          ipResolver,
 		connEndpointFactory: endpointFactory,
 		handshakeWaiter:     handshakeWaiter,
+		lookupHost:          lookupViaSystem,
+		lookupVia:           LookupViaServers,
+		netstackEndpointFactory: func(stack tun.Device) wg.ConnectionEndpoint {
+			return endpoint.NewNetstackConnectionEndpoint(stack)
+		},
 	}, nil
 }
 
@@ -72,12 +85,25 @@ type Connection struct {
 	ipResolver          ip.Resolver
 	connectionEndpoint  wg.ConnectionEndpoint
 	removeAllowedIPRule func()
+	splitMu             sync.Mutex
+	splitStopped        bool
+	splitAllowedIPs     []string
+	splitRules          map[string]firewall.OutgoingRuleRemove
+	lookupHost          func(host string) ([]net.IP, error)
+	lookupVia           func(servers []string) func(host string) ([]net.IP, error)
 	opts                Options
 	connEndpointFactory wg.EndpointFactory
 	handshakeWaiter     HandshakeWaiter
+
+	netstackEndpointFactory func(stack tun.Device) wg.ConnectionEndpoint
+	proxyListener           net.Listener
 }
 
-var _ connection.Connection = &Connection{}
+var (
+	_ connection.Connection          = &Connection{}
+	_ connection.ProxyConnection     = &Connection{}
+	_ connection.ServiceConnMigrator = &Connection{}
+)
 
 // State returns connection state channel.
 func (c *Connection) State() <-chan connectionstate.State {
@@ -104,6 +130,10 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 		return errors.Wrap(err, "failed to unmarshal connection config")
 	}
 
+	if options.Params.ProxyAddress != "" {
+		return c.startUserspace(options, config)
+	}
+
 	removeAllowedIPRule, err := firewall.AllowIPAccess(config.Provider.Endpoint.IP.String())
 	if err != nil {
 		return errors.Wrap(err, "failed to add firewall exception for wireguard remote IP")
@@ -121,7 +151,13 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 	if options.ProviderNATConn != nil {
 		options.ProviderNATConn.Close()
 		config.LocalPort = options.ProviderNATConn.LocalAddr().(*net.UDPAddr).Port
-		config.Provider.Endpoint.Port = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
+		remoteAddr := options.ProviderNATConn.RemoteAddr().(*net.UDPAddr)
+		if remoteAddr.IP.IsLoopback() {
+			// Traffic goes through local p2p obfuscation proxy which sends it to the provider.
+			config.Provider.Endpoint = *remoteAddr
+		} else {
+			config.Provider.Endpoint.Port = remoteAddr.Port
+		}
 	}
 
 	dnsIPs, err := options.Params.DNS.ResolveIPs(config.Consumer.DNSIPs)
@@ -129,10 +165,16 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 		return errors.Wrap(err, "could not resolve DNS IPs")
 	}
 
+	allowedIPs, err := c.configureSplitTunnel(options.Params.SplitTunnel, dnsIPs)
+	if err != nil {
+		return errors.Wrap(err, "could not configure split tunnel")
+	}
+
 	log.Info().Msg("Starting new connection")
 	conn, err := c.startConn(wgcfg.DeviceConfig{
 		IfaceName:    "", // Interface name will be generated by connection endpoint.
 		Subnet:       config.Consumer.IPAddress,
+		Subnet6:      config.Consumer.IPAddress6,
 		PrivateKey:   c.privateKey,
 		ListenPort:   config.LocalPort,
 		DNS:          dnsIPs,
@@ -140,7 +182,7 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 		Peer: wgcfg.Peer{
 			Endpoint:               &config.Provider.Endpoint,
 			PublicKey:              config.Provider.PublicKey,
-			AllowedIPs:             []string{"0.0.0.0/0", "::/0"},
+			AllowedIPs:             allowedIPs,
 			KeepAlivePeriodSeconds: 18,
 		},
 	})
@@ -157,9 +199,184 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 	}
 
 	c.stateCh <- connectionstate.Connected
+
+	if options.Params.SplitTunnel.HasDomains() {
+		go c.refreshSplitTunnel(options.Params.SplitTunnel, dnsIPs)
+	}
+	return nil
+}
+
+// MigrateServiceConn moves WireGuard traffic to the p2p service conn punched during channel migration.
+func (c *Connection) MigrateServiceConn(conn *net.UDPConn) error {
+	if c.connectionEndpoint == nil {
+		return errors.New("connection is not started")
+	}
+
+	conn.Close()
+	localPort := conn.LocalAddr().(*net.UDPAddr).Port
+	remoteAddr := conn.RemoteAddr().(*net.UDPAddr)
+	log.Info().Msgf("Migrating connection to local port %d", localPort)
+	return c.connectionEndpoint.Rebind(localPort, remoteAddr)
+}
+
+// configureSplitTunnel returns networks routed through the tunnel and adds
+// kill switch exceptions for destinations which are reached outside of it.
+// Tunnel is not up yet, so domains are resolved using the system resolver.
+func (c *Connection) configureSplitTunnel(splitTunnel connection.SplitTunnel, dnsIPs []string) ([]string, error) {
+	if !splitTunnel.IsEnabled() {
+		return []string{"0.0.0.0/0", "::/0"}, nil
+	}
+
+	allowedIPs, bypass, err := splitTunnelNetworks(splitTunnel, c.lookupHost, dnsIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	c.splitMu.Lock()
+	defer c.splitMu.Unlock()
+
+	c.splitAllowedIPs = allowedIPs
+	return allowedIPs, c.allowSplitBypass(bypass)
+}
+
+// refreshSplitTunnel periodically resolves split tunnel domains through the connection DNS servers,
+// so tunnel routes follow the addresses applications get once connection is established.
+func (c *Connection) refreshSplitTunnel(splitTunnel connection.SplitTunnel, dnsIPs []string) {
+	lookup := c.lookupHost
+	if len(dnsIPs) > 0 {
+		lookup = c.lookupVia(dnsIPs)
+	}
+
+	for {
+		if err := c.updateSplitTunnel(splitTunnel, lookup, dnsIPs); err != nil {
+			log.Warn().Err(err).Msg("Failed to refresh split tunnel routes")
+		}
+
+		select {
+		case <-c.done:
+			return
+		case <-time.After(splitTunnelRefreshInterval):
+		}
+	}
+}
+
+func (c *Connection) updateSplitTunnel(splitTunnel connection.SplitTunnel, lookup func(host string) ([]net.IP, error), dnsIPs []string) error {
+	allowedIPs, bypass, err := splitTunnelNetworks(splitTunnel, lookup, dnsIPs)
+	if err != nil {
+		return err
+	}
+
+	c.splitMu.Lock()
+	defer c.splitMu.Unlock()
+
+	if c.splitStopped || equalStrings(c.splitAllowedIPs, allowedIPs) {
+		return nil
+	}
+
+	log.Info().Msgf("Split tunnel routes changed, routing %v through the tunnel", allowedIPs)
+	if err := c.connectionEndpoint.Reroute(allowedIPs); err != nil {
+		return err
+	}
+	c.splitAllowedIPs = allowedIPs
+	return c.allowSplitBypass(bypass)
+}
+
+// allowSplitBypass replaces kill switch exceptions of split tunnel, caller must hold the split lock.
+func (c *Connection) allowSplitBypass(bypass []string) error {
+	rules := make(map[string]firewall.OutgoingRuleRemove, len(bypass))
+	for _, network := range bypass {
+		if removeRule, ok := c.splitRules[network]; ok {
+			rules[network] = removeRule
+			delete(c.splitRules, network)
+		}
+	}
+	for _, removeRule := range c.splitRules {
+		removeRule()
+	}
+	c.splitRules = rules
+
+	for _, network := range bypass {
+		if _, ok := rules[network]; ok {
+			continue
+		}
+		removeRule, err := firewall.AllowIPAccess(network)
+		if err != nil {
+			return errors.Wrapf(err, "failed to add firewall exception for %s", network)
+		}
+		rules[network] = removeRule
+	}
 	return nil
 }
 
+// splitTunnelNetworks returns networks which are routed through the tunnel and bypass it.
+// DNS servers are added to included networks, tunnel DNS is not reachable otherwise.
+func splitTunnelNetworks(splitTunnel connection.SplitTunnel, lookup func(host string) ([]net.IP, error), dnsIPs []string) (allowedIPs, bypass []string, err error) {
+	routes, err := splitTunnel.Resolve(lookup)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(routes.TunnelNetworks()) == 0 {
+		return nil, nil, errors.New("split tunnel excludes all destinations")
+	}
+
+	for _, server := range dnsIPs {
+		ip := net.ParseIP(server)
+		if ip == nil || len(routes.Include) == 0 || tunnelContains(routes.TunnelNetworks(), ip) {
+			continue
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			routes.Include = append(routes.Include, net.IPNet{IP: ip4, Mask: net.CIDRMask(8*net.IPv4len, 8*net.IPv4len)})
+		} else {
+			routes.Include = append(routes.Include, net.IPNet{IP: ip, Mask: net.CIDRMask(8*net.IPv6len, 8*net.IPv6len)})
+		}
+	}
+
+	for _, network := range routes.TunnelNetworks() {
+		allowedIPs = append(allowedIPs, network.String())
+	}
+	for _, network := range routes.BypassNetworks() {
+		bypass = append(bypass, network.String())
+	}
+	return allowedIPs, bypass, nil
+}
+
+func tunnelContains(networks []net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func lookupViaSystem(host string) ([]net.IP, error) {
+	handler, err := dns.ResolveViaSystem()
+	if err != nil {
+		return nil, err
+	}
+	return dns.Lookup(handler, host)
+}
+
+// LookupViaServers returns lookup function which resolves hosts through the given DNS servers.
+func LookupViaServers(servers []string) func(host string) ([]net.IP, error) {
+	handler := dns.ResolveVia((&net.Dialer{}).DialContext, servers)
+	return func(host string) ([]net.IP, error) {
+		return dns.Lookup(handler, host)
+	}
+}
+
 func (c *Connection) startConn(conf wgcfg.DeviceConfig) (wg.ConnectionEndpoint, error) {
 	conn, err := c.connEndpointFactory()
 	if err != nil {
@@ -193,9 +410,19 @@ func (c *Connection) Stop() {
 		log.Info().Msg("Stopping WireGuard connection")
 		c.stateCh <- connectionstate.Disconnecting
 
+		if c.proxyListener != nil {
+			c.proxyListener.Close()
+		}
 		if c.removeAllowedIPRule != nil {
 			c.removeAllowedIPRule()
 		}
+		c.splitMu.Lock()
+		c.splitStopped = true
+		for _, removeRule := range c.splitRules {
+			removeRule()
+		}
+		c.splitRules = nil
+		c.splitMu.Unlock()
 
 		if c.connectionEndpoint != nil {
 			if err := c.connectionEndpoint.Stop(); err != nil {
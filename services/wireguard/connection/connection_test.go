@@ -93,6 +93,97 @@ func TestConnectionStopOnceAfterHandshakeErrorAndStopCall(t *testing.T) {
 	assert.Equal(t, connectionstate.NotConnected, <-conn.State())
 }
 
+func TestConnectionStartWithSplitTunnel(t *testing.T) {
+	conn := newConn(t)
+	endpoint := &mockConnectionEndpoint{}
+	conn.connEndpointFactory = func() (wg.ConnectionEndpoint, error) {
+		return endpoint, nil
+	}
+	conn.lookupHost = func(host string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("1.2.3.4")}, nil
+	}
+
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+	err := conn.Start(context.Background(), connection.ConnectOptions{
+		Params: connection.ConnectParams{
+			DNS: "1.2.3.4",
+			SplitTunnel: connection.SplitTunnel{
+				IncludeCIDRs:   []string{"10.0.0.0/8"},
+				IncludeDomains: []string{"example.com"},
+			},
+		},
+		SessionConfig: sessionConfig,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "1.2.3.4/32"}, endpoint.config.Peer.AllowedIPs)
+	conn.Stop()
+}
+
+func TestConnectionRefreshesSplitTunnelThroughConnectionDNS(t *testing.T) {
+	conn := newConn(t)
+	endpoint := &mockConnectionEndpoint{rerouted: make(chan []string, 1)}
+	conn.connEndpointFactory = func() (wg.ConnectionEndpoint, error) {
+		return endpoint, nil
+	}
+	conn.lookupHost = func(host string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("1.2.3.4")}, nil
+	}
+	var lookupServers []string
+	conn.lookupVia = func(servers []string) func(host string) ([]net.IP, error) {
+		lookupServers = servers
+		return func(host string) ([]net.IP, error) {
+			return []net.IP{net.ParseIP("5.6.7.8")}, nil
+		}
+	}
+
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+	err := conn.Start(context.Background(), connection.ConnectOptions{
+		Params: connection.ConnectParams{
+			DNS: "provider",
+			SplitTunnel: connection.SplitTunnel{
+				IncludeCIDRs:   []string{"10.0.0.0/8"},
+				IncludeDomains: []string{"example.com"},
+			},
+		},
+		SessionConfig: sessionConfig,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "1.2.3.4/32", "128.0.0.1/32"}, endpoint.config.Peer.AllowedIPs)
+
+	select {
+	case allowedIPs := <-endpoint.rerouted:
+		assert.Equal(t, []string{"10.0.0.0/8", "5.6.7.8/32", "128.0.0.1/32"}, allowedIPs)
+		assert.Equal(t, []string{"128.0.0.1"}, lookupServers)
+	case <-time.After(time.Second):
+		assert.Fail(t, "split tunnel routes were not refreshed")
+	}
+	conn.Stop()
+}
+
+func TestConnectionMigrateServiceConn(t *testing.T) {
+	conn := newConn(t)
+	endpoint := &mockConnectionEndpoint{}
+	conn.connEndpointFactory = func() (wg.ConnectionEndpoint, error) {
+		return endpoint, nil
+	}
+
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+	err := conn.Start(context.Background(), connection.ConnectOptions{
+		Params:        connection.ConnectParams{DNS: "1.2.3.4"},
+		SessionConfig: sessionConfig,
+	})
+	assert.NoError(t, err)
+
+	serviceConn, err := net.DialUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 51002})
+	assert.NoError(t, err)
+
+	assert.NoError(t, conn.MigrateServiceConn(serviceConn))
+	assert.Equal(t, serviceConn.LocalAddr().(*net.UDPAddr).Port, endpoint.listenPort)
+	assert.Equal(t, "127.0.0.1:51002", endpoint.peerEndpoint.String())
+	conn.Stop()
+}
+
 func newConn(t *testing.T) *Connection {
 	endpointFactory := func() (wg.ConnectionEndpoint, error) {
 		return &mockConnectionEndpoint{}, nil
@@ -118,8 +209,9 @@ func newServiceConfig() wg.ServiceConfig {
 			Endpoint:  *endpoint,
 		},
 		Consumer: struct {
-			IPAddress net.IPNet
-			DNSIPs    string
+			IPAddress  net.IPNet
+			IPAddress6 *net.IPNet
+			DNSIPs     string
 		}{
 			IPAddress: net.IPNet{
 				IP:   net.IPv4(127, 0, 0, 1),
@@ -130,12 +222,30 @@ func newServiceConfig() wg.ServiceConfig {
 	}
 }
 
-type mockConnectionEndpoint struct{}
+type mockConnectionEndpoint struct {
+	config       wgcfg.DeviceConfig
+	listenPort   int
+	peerEndpoint *net.UDPAddr
+	rerouted     chan []string
+}
 
-func (mce *mockConnectionEndpoint) StartConsumerMode(config wgcfg.DeviceConfig) error { return nil }
+func (mce *mockConnectionEndpoint) StartConsumerMode(config wgcfg.DeviceConfig) error {
+	mce.config = config
+	return nil
+}
 func (mce *mockConnectionEndpoint) StartProviderMode(ip string, config wgcfg.DeviceConfig) error {
 	return nil
 }
+func (mce *mockConnectionEndpoint) Rebind(listenPort int, peerEndpoint *net.UDPAddr) error {
+	mce.listenPort, mce.peerEndpoint = listenPort, peerEndpoint
+	return nil
+}
+func (mce *mockConnectionEndpoint) Reroute(allowedIPs []string) error {
+	if mce.rerouted != nil {
+		mce.rerouted <- allowedIPs
+	}
+	return nil
+}
 func (mce *mockConnectionEndpoint) InterfaceName() string                { return "mce0" }
 func (mce *mockConnectionEndpoint) Stop() error                          { return nil }
 func (mce *mockConnectionEndpoint) Config() (wg.ServiceConfig, error)    { return wg.ServiceConfig{}, nil }
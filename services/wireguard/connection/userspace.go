@@ -0,0 +1,140 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"golang.zx2c4.com/wireguard/device"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/services/socks5"
+	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/netstack"
+	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+)
+
+const proxyDialTimeout = 30 * time.Second
+
+// startUserspace runs WireGuard on top of the user space network stack and exposes
+// the tunnel as a local SOCKS5/HTTP proxy. OS network configuration is not changed.
+func (c *Connection) startUserspace(options connection.ConnectOptions, config wg.ServiceConfig) (err error) {
+	if options.Params.SplitTunnel.IsEnabled() {
+		return errors.New("split tunnelling is not supported in userspace mode")
+	}
+
+	if err := connection.ValidateProxyAddress(options.Params.ProxyAddress); err != nil {
+		return errors.Wrap(err, "invalid proxy address")
+	}
+
+	defer func() {
+		if err != nil {
+			c.Stop()
+		}
+	}()
+
+	c.stateCh <- connectionstate.Connecting
+
+	if options.ProviderNATConn != nil {
+		options.ProviderNATConn.Close()
+		config.LocalPort = options.ProviderNATConn.LocalAddr().(*net.UDPAddr).Port
+		config.Provider.Endpoint = *options.ProviderNATConn.RemoteAddr().(*net.UDPAddr)
+	}
+
+	dnsIPs, err := options.Params.DNS.ResolveIPs(config.Consumer.DNSIPs)
+	if err != nil {
+		return errors.Wrap(err, "could not resolve DNS IPs")
+	}
+	if len(dnsIPs) == 0 {
+		// System DNS is not reachable from the tunnel, use configured public one.
+		dnsIPs = c.opts.UserspaceFallbackDNS
+	}
+
+	stack, err := netstack.New(config.Consumer.IPAddress.IP, device.DefaultMTU)
+	if err != nil {
+		return errors.Wrap(err, "could not create network stack")
+	}
+
+	log.Info().Msg("Starting new userspace connection")
+	c.connectionEndpoint = c.netstackEndpointFactory(stack)
+	if err := c.connectionEndpoint.StartConsumerMode(wgcfg.DeviceConfig{
+		PrivateKey: c.privateKey,
+		ListenPort: config.LocalPort,
+		Peer: wgcfg.Peer{
+			Endpoint:               &config.Provider.Endpoint,
+			PublicKey:              config.Provider.PublicKey,
+			AllowedIPs:             []string{"0.0.0.0/0"},
+			KeepAlivePeriodSeconds: 18,
+		},
+	}); err != nil {
+		stack.Close()
+		return errors.Wrap(err, "could not start new connection")
+	}
+
+	log.Info().Msg("Waiting for initial handshake")
+	if err := c.handshakeWaiter.Wait(c.connectionEndpoint.PeerStats, c.opts.HandshakeTimeout, c.done); err != nil {
+		return errors.Wrap(err, "failed while waiting for a peer handshake")
+	}
+
+	c.proxyListener, err = net.Listen("tcp", options.Params.ProxyAddress)
+	if err != nil {
+		return errors.Wrap(err, "could not listen for proxy clients")
+	}
+	go serveProxy(c.proxyListener, socks5.NewProxy(tunnelDialer(stack, dnsIPs)))
+
+	log.Info().Msgf("SOCKS5/HTTP proxy is available at %s", c.proxyListener.Addr())
+	c.stateCh <- connectionstate.Connected
+	return nil
+}
+
+// ProxyAddr returns local proxy address in userspace mode.
+func (c *Connection) ProxyAddr() string {
+	if c.proxyListener == nil {
+		return ""
+	}
+	return c.proxyListener.Addr().String()
+}
+
+func serveProxy(listener net.Listener, proxy *socks5.Proxy) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			if err := proxy.ServeConn(conn); err != nil {
+				log.Debug().Err(err).Msg("Proxy request failed")
+			}
+		}()
+	}
+}
+
+// tunnelDialer dials destinations inside the tunnel, host names are resolved by DNS servers reached through the tunnel.
+func tunnelDialer(stack *netstack.Stack, dnsIPs []string) socks5.Dialer {
+	dialer := netstack.NewDialer(stack, dnsIPs)
+	return func(network, address string) (net.Conn, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), proxyDialTimeout)
+		defer cancel()
+		return dialer.DialContext(ctx, network, address)
+	}
+}
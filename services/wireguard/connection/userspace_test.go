@@ -0,0 +1,146 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/tun"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/netstack"
+)
+
+func TestConnectionStartUserspace(t *testing.T) {
+	conn := newConn(t)
+	endpoint := &mockConnectionEndpoint{}
+	conn.netstackEndpointFactory = func(stack tun.Device) wg.ConnectionEndpoint {
+		return endpoint
+	}
+
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+	err := conn.Start(context.Background(), connection.ConnectOptions{
+		Params:        connection.ConnectParams{DNS: "1.2.3.4", ProxyAddress: "127.0.0.1:0"},
+		SessionConfig: sessionConfig,
+	})
+	require.NoError(t, err)
+	defer conn.Stop()
+
+	assert.Equal(t, connectionstate.Connecting, <-conn.State())
+	assert.Equal(t, connectionstate.Connected, <-conn.State())
+	assert.Equal(t, []string{"0.0.0.0/0"}, endpoint.config.Peer.AllowedIPs)
+	assert.Empty(t, endpoint.config.DNS)
+
+	proxyConn, err := net.Dial("tcp", conn.ProxyAddr())
+	require.NoError(t, err)
+	proxyConn.Close()
+}
+
+func TestConnectionStartUserspaceRejectsSplitTunnel(t *testing.T) {
+	conn := newConn(t)
+
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+	err := conn.Start(context.Background(), connection.ConnectOptions{
+		Params: connection.ConnectParams{
+			ProxyAddress: "127.0.0.1:0",
+			SplitTunnel:  connection.SplitTunnel{IncludeCIDRs: []string{"10.0.0.0/8"}},
+		},
+		SessionConfig: sessionConfig,
+	})
+	assert.Error(t, err)
+}
+
+func TestTunnelDialerResolvesThroughTunnel(t *testing.T) {
+	client, err := netstack.New(net.ParseIP("10.182.0.2"), 1280)
+	require.NoError(t, err)
+	defer client.Close()
+	server, err := netstack.New(net.ParseIP("10.182.0.1"), 1280)
+	require.NoError(t, err)
+	defer server.Close()
+	go forwardPackets(client, server)
+	go forwardPackets(server, client)
+
+	dnsConn, err := server.ListenPacket(53)
+	require.NoError(t, err)
+	go serveDNS(dnsConn, "example.com.", net.ParseIP("10.182.0.1"))
+
+	listener, err := server.Listen(80)
+	require.NoError(t, err)
+	go func() {
+		c, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		c.Write([]byte("hello"))
+		c.Close()
+	}()
+
+	dial := tunnelDialer(client, []string{"10.182.0.1"})
+	c, err := dial("tcp", "example.com:80")
+	require.NoError(t, err)
+	defer c.Close()
+
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(c, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf))
+}
+
+func forwardPackets(from, to *netstack.Stack) {
+	buf := make([]byte, 2000)
+	for {
+		n, err := from.Read(buf, 0)
+		if err != nil {
+			return
+		}
+		to.Write(buf[:n], 0)
+	}
+}
+
+func serveDNS(conn net.PacketConn, name string, ip net.IP) {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		req := new(dns.Msg)
+		if err := req.Unpack(buf[:n]); err != nil {
+			continue
+		}
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		if req.Question[0].Name == name && req.Question[0].Qtype == dns.TypeA {
+			resp.Answer = append(resp.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   ip,
+			})
+		}
+		packed, _ := resp.Pack()
+		conn.WriteTo(packed, addr)
+	}
+}
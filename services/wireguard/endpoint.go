@@ -18,6 +18,8 @@
 package wireguard
 
 import (
+	"net"
+
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 )
 
@@ -32,5 +34,7 @@ type ConnectionEndpoint interface {
 	PeerStats() (*wgcfg.Stats, error)
 	Config() (ServiceConfig, error)
 	InterfaceName() string
+	Rebind(listenPort int, peerEndpoint *net.UDPAddr) error
+	Reroute(allowedIPs []string) error
 	Stop() error
 }
@@ -21,12 +21,14 @@ import (
 	"net"
 
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/userspace"
 	"github.com/mysteriumnetwork/node/services/wireguard/key"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"golang.zx2c4.com/wireguard/tun"
 )
 
 // NewConnectionEndpoint returns new connection endpoint instance.
@@ -42,6 +44,14 @@ func NewConnectionEndpoint(resourceAllocator *resources.Allocator) (wg.Connectio
 	}, nil
 }
 
+// NewNetstackConnectionEndpoint returns connection endpoint which runs WireGuard
+// in user space on top of the given network stack without OS network changes.
+func NewNetstackConnectionEndpoint(stack tun.Device) wg.ConnectionEndpoint {
+	return &connectionEndpoint{
+		wgClient: userspace.NewNetstackClient(stack),
+	}
+}
+
 type connectionEndpoint struct {
 	cfg               wgcfg.DeviceConfig
 	endpoint          net.UDPAddr
@@ -51,16 +61,18 @@ type connectionEndpoint struct {
 
 // StartConsumerMode starts and configure wireguard network interface running in consumer mode.
 func (ce *connectionEndpoint) StartConsumerMode(cfg wgcfg.DeviceConfig) error {
-	if err := ce.cleanAbandonedInterfaces(); err != nil {
-		return err
-	}
-	iface, err := ce.resourceAllocator.AllocateInterface()
-	if err != nil {
-		return errors.Wrap(err, "could not allocate interface")
+	// Netstack endpoint has no OS interface to allocate.
+	if ce.resourceAllocator != nil {
+		if err := ce.cleanAbandonedInterfaces(); err != nil {
+			return err
+		}
+		iface, err := ce.resourceAllocator.AllocateInterface()
+		if err != nil {
+			return errors.Wrap(err, "could not allocate interface")
+		}
+		log.Debug().Msgf("Allocated interface: %s", iface)
+		cfg.IfaceName = iface
 	}
-	log.Debug().Msgf("Allocated interface: %s", iface)
-
-	cfg.IfaceName = iface
 	ce.cfg = cfg
 
 	if err := ce.wgClient.ConfigureDevice(cfg); err != nil {
@@ -70,6 +82,9 @@ func (ce *connectionEndpoint) StartConsumerMode(cfg wgcfg.DeviceConfig) error {
 }
 
 func (ce *connectionEndpoint) StartProviderMode(publicIP string, config wgcfg.DeviceConfig) (err error) {
+	if ce.resourceAllocator == nil {
+		return errors.New("provider mode is not supported by netstack endpoint")
+	}
 	if publicIP == "" {
 		return errors.New("public IP is required")
 	}
@@ -88,6 +103,11 @@ func (ce *connectionEndpoint) StartProviderMode(publicIP string, config wgcfg.De
 
 	config.IfaceName = iface
 	config.Subnet.IP = netutil.FirstIP(config.Subnet)
+	if config.Subnet6 != nil {
+		subnet6 := *config.Subnet6
+		subnet6.IP = netutil.FirstIP(subnet6)
+		config.Subnet6 = &subnet6
+	}
 	ce.cfg = config
 	ce.endpoint = net.UDPAddr{IP: net.ParseIP(publicIP), Port: config.ListenPort}
 
@@ -97,6 +117,37 @@ func (ce *connectionEndpoint) StartProviderMode(publicIP string, config wgcfg.De
 	return nil
 }
 
+// Rebind moves wireguard device to the new listen port and peer endpoint.
+// Nil peer endpoint keeps the current one, provider learns consumer address from handshakes.
+func (ce *connectionEndpoint) Rebind(listenPort int, peerEndpoint *net.UDPAddr) error {
+	cfg := ce.cfg
+	cfg.ListenPort = listenPort
+	if peerEndpoint != nil {
+		cfg.Peer.Endpoint = peerEndpoint
+	}
+	if err := ce.wgClient.ReconfigureEndpoint(cfg); err != nil {
+		return errors.Wrap(err, "could not reconfigure device endpoint")
+	}
+
+	ce.cfg = cfg
+	if ce.endpoint.Port != 0 {
+		ce.endpoint.Port = listenPort
+	}
+	return nil
+}
+
+// Reroute replaces networks which are routed through the tunnel.
+func (ce *connectionEndpoint) Reroute(allowedIPs []string) error {
+	cfg := ce.cfg
+	cfg.Peer.AllowedIPs = allowedIPs
+	if err := ce.wgClient.ReconfigureAllowedIPs(cfg, ce.cfg.Peer.AllowedIPs); err != nil {
+		return errors.Wrap(err, "could not reconfigure device allowed IPs")
+	}
+
+	ce.cfg = cfg
+	return nil
+}
+
 // InterfaceName returns a connection endpoint interface name.
 func (ce *connectionEndpoint) InterfaceName() string {
 	return ce.cfg.IfaceName
@@ -119,6 +170,9 @@ func (ce *connectionEndpoint) Config() (wg.ServiceConfig, error) {
 	config.Provider.Endpoint = ce.endpoint
 	config.Consumer.IPAddress = ce.cfg.Subnet
 	config.Consumer.IPAddress.IP = ce.consumerIP(ce.cfg.Subnet)
+	if ce.cfg.Subnet6 != nil {
+		config.Consumer.IPAddress6 = consumerIP6(*ce.cfg.Subnet6)
+	}
 	return config, nil
 }
 
@@ -127,6 +181,9 @@ func (ce *connectionEndpoint) Stop() error {
 	if err := ce.wgClient.Close(); err != nil {
 		return err
 	}
+	if ce.resourceAllocator == nil {
+		return nil
+	}
 
 	return ce.resourceAllocator.ReleaseInterface(ce.cfg.IfaceName)
 }
@@ -146,3 +203,10 @@ func (ce *connectionEndpoint) cleanAbandonedInterfaces() error {
 
 	return nil
 }
+
+// consumerIP6 returns consumer address in the IPv6 network of the session, provider takes the first one.
+func consumerIP6(subnet net.IPNet) *net.IPNet {
+	ip := subnet.IP.Mask(subnet.Mask)
+	ip[len(ip)-1] = byte(2)
+	return &net.IPNet{IP: ip, Mask: subnet.Mask}
+}
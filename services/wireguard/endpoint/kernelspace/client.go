@@ -29,6 +29,7 @@ import (
 	"github.com/mysteriumnetwork/node/utils/cmdutil"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 	"golang.zx2c4.com/wireguard/wgctrl"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 )
@@ -61,12 +62,12 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) error {
 	deviceConfig.PrivateKey = &privateKey
 	deviceConfig.ListenPort = &port
 
-	if err := c.up(config.IfaceName, config.Subnet); err != nil {
+	if err := c.up(config.IfaceName, config.Subnet, config.Subnet6); err != nil {
 		return err
 	}
 
 	if config.Peer.Endpoint != nil {
-		if err := configureRoutes(config.IfaceName, config.Peer.Endpoint.IP); err != nil {
+		if err := configureRoutes(config.IfaceName, config.Peer.Endpoint.IP, config.Peer.AllowedIPs); err != nil {
 			return err
 		}
 	}
@@ -90,6 +91,54 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) error {
 	return nil
 }
 
+func (c *client) ReconfigureEndpoint(config wgcfg.DeviceConfig) error {
+	port := config.ListenPort
+	publicKey, err := stringToKey(config.Peer.PublicKey)
+	if err != nil {
+		return errors.Wrap(err, "could not convert string key to wgtypes.Key")
+	}
+	deviceConfig := wgtypes.Config{
+		ListenPort: &port,
+		Peers: []wgtypes.PeerConfig{{
+			PublicKey:  publicKey,
+			Endpoint:   config.Peer.Endpoint,
+			UpdateOnly: true,
+		}},
+	}
+	if err := c.wgClient.ConfigureDevice(config.IfaceName, deviceConfig); err != nil {
+		return fmt.Errorf("could not reconfigure kernel space device: %w", err)
+	}
+	if config.Peer.Endpoint != nil && !config.Peer.Endpoint.IP.IsLoopback() {
+		// Route to the provider might be gone together with the previous network.
+		if err := netutil.ExcludeRoute(config.Peer.Endpoint.IP); err != nil {
+			log.Warn().Err(err).Msgf("Could not exclude route %s", config.Peer.Endpoint.IP)
+		}
+	}
+	return nil
+}
+
+func (c *client) ReconfigureAllowedIPs(config wgcfg.DeviceConfig, previous []string) error {
+	peer, err := addPeerConfig(config.Peer)
+	if err != nil {
+		return err
+	}
+	deviceConfig := wgtypes.Config{
+		Peers: []wgtypes.PeerConfig{{
+			PublicKey:         peer.PublicKey,
+			AllowedIPs:        peer.AllowedIPs,
+			ReplaceAllowedIPs: true,
+			UpdateOnly:        true,
+		}},
+	}
+	if err := c.wgClient.ConfigureDevice(config.IfaceName, deviceConfig); err != nil {
+		return fmt.Errorf("could not reconfigure kernel space device: %w", err)
+	}
+	if err := netutil.UpdateTunnelRoutes(config.IfaceName, previous, config.Peer.AllowedIPs); err != nil {
+		return fmt.Errorf("could not update tunnel routes for %s: %w", config.IfaceName, err)
+	}
+	return nil
+}
+
 func addPeerConfig(peer wgcfg.Peer) (wgtypes.PeerConfig, error) {
 	endpoint := peer.Endpoint
 	publicKey, err := stringToKey(peer.PublicKey)
@@ -143,7 +192,7 @@ func (c *client) DestroyDevice(name string) error {
 	return cmdutil.SudoExec("ip", "link", "del", "dev", name)
 }
 
-func (c *client) up(iface string, ipAddr net.IPNet) error {
+func (c *client) up(iface string, ipAddr net.IPNet, ipAddr6 *net.IPNet) error {
 	if d, err := c.wgClient.Device(iface); err != nil || d.Name != iface {
 		if err := cmdutil.SudoExec("ip", "link", "add", "dev", iface, "type", "wireguard"); err != nil {
 			return err
@@ -153,6 +202,11 @@ func (c *client) up(iface string, ipAddr net.IPNet) error {
 	if err := cmdutil.SudoExec("ip", "address", "replace", "dev", iface, ipAddr.String()); err != nil {
 		return err
 	}
+	if ipAddr6 != nil {
+		if err := cmdutil.SudoExec("ip", "-6", "address", "replace", "dev", iface, ipAddr6.String()); err != nil {
+			return err
+		}
+	}
 
 	return cmdutil.SudoExec("ip", "link", "set", "dev", iface, "up")
 }
@@ -174,11 +228,14 @@ func (c *client) Close() (err error) {
 	return nil
 }
 
-func configureRoutes(iface string, ip net.IP) error {
-	if err := netutil.ExcludeRoute(ip); err != nil {
-		return err
+func configureRoutes(iface string, ip net.IP, allowedIPs []string) error {
+	// Loopback endpoint is a local obfuscation proxy, provider route is excluded by p2p dialer.
+	if !ip.IsLoopback() {
+		if err := netutil.ExcludeRoute(ip); err != nil {
+			return err
+		}
 	}
-	return netutil.AddDefaultRoute(iface)
+	return netutil.AddTunnelRoutes(iface, allowedIPs)
 }
 
 func stringToKey(key string) (wgtypes.Key, error) {
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netstack
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+)
+
+// Dialer dials destinations through the stack, host names are resolved by DNS servers reached through the stack.
+type Dialer struct {
+	stack    *Stack
+	resolver *net.Resolver
+}
+
+// NewDialer creates a dialer resolving host names with the given DNS servers.
+func NewDialer(stack *Stack, dnsServers []string) *Dialer {
+	var next uint32
+	return &Dialer{
+		stack: stack,
+		resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				if len(dnsServers) == 0 {
+					return nil, fmt.Errorf("no DNS servers configured")
+				}
+				server := dnsServers[atomic.AddUint32(&next, 1)%uint32(len(dnsServers))]
+				return stack.DialContext(ctx, "udp", net.JoinHostPort(server, "53"))
+			},
+		},
+	}
+}
+
+// DialContext connects to the address on the named network, host names are resolved to IPv4 addresses.
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if net.ParseIP(host) == nil {
+		ips, err := d.resolver.LookupIP(ctx, "ip4", host)
+		if err != nil {
+			return nil, err
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("no IPv4 address found for %s", host)
+		}
+		host = ips[0].String()
+	}
+	return d.stack.DialContext(ctx, network, net.JoinHostPort(host, port))
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netstack
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	ipv4HeaderLen = 20
+	tcpHeaderLen  = 20
+	udpHeaderLen  = 8
+
+	protocolTCP = 6
+	protocolUDP = 17
+
+	flagFIN = 0x01
+	flagSYN = 0x02
+	flagRST = 0x04
+	flagPSH = 0x08
+	flagACK = 0x10
+
+	tcpOptionEnd = 0
+	tcpOptionNop = 1
+	tcpOptionMSS = 2
+)
+
+var errInvalidPacket = errors.New("invalid packet")
+
+type ipv4Packet struct {
+	src, dst [4]byte
+	protocol uint8
+	payload  []byte
+}
+
+func parseIPv4(packet []byte) (ipv4Packet, error) {
+	if len(packet) < ipv4HeaderLen || packet[0]>>4 != 4 {
+		return ipv4Packet{}, errInvalidPacket
+	}
+	headerLen := int(packet[0]&0x0f) * 4
+	totalLen := int(binary.BigEndian.Uint16(packet[2:4]))
+	if headerLen < ipv4HeaderLen || totalLen < headerLen || totalLen > len(packet) {
+		return ipv4Packet{}, errInvalidPacket
+	}
+	// Fragmented packets are not supported, WireGuard MTU keeps them away.
+	if binary.BigEndian.Uint16(packet[6:8])&0x3fff != 0 {
+		return ipv4Packet{}, errInvalidPacket
+	}
+	if checksum(packet[:headerLen], 0) != 0 {
+		return ipv4Packet{}, errInvalidPacket
+	}
+
+	p := ipv4Packet{
+		protocol: packet[9],
+		payload:  packet[headerLen:totalLen],
+	}
+	copy(p.src[:], packet[12:16])
+	copy(p.dst[:], packet[16:20])
+	return p, nil
+}
+
+func encodeIPv4(src, dst [4]byte, protocol uint8, id uint16, payload []byte) []byte {
+	packet := make([]byte, ipv4HeaderLen+len(payload))
+	packet[0] = 0x45
+	binary.BigEndian.PutUint16(packet[2:4], uint16(len(packet)))
+	binary.BigEndian.PutUint16(packet[4:6], id)
+	binary.BigEndian.PutUint16(packet[6:8], 0x4000) // Don't fragment.
+	packet[8] = 64
+	packet[9] = protocol
+	copy(packet[12:16], src[:])
+	copy(packet[16:20], dst[:])
+	binary.BigEndian.PutUint16(packet[10:12], checksum(packet[:ipv4HeaderLen], 0))
+	copy(packet[ipv4HeaderLen:], payload)
+	return packet
+}
+
+type tcpSegment struct {
+	srcPort, dstPort uint16
+	seq, ack         uint32
+	flags            uint8
+	window           uint16
+	mss              uint16
+	payload          []byte
+}
+
+// length returns the sequence space occupied by the segment.
+func (s tcpSegment) length() uint32 {
+	n := uint32(len(s.payload))
+	if s.flags&flagSYN != 0 {
+		n++
+	}
+	if s.flags&flagFIN != 0 {
+		n++
+	}
+	return n
+}
+
+func parseTCP(src, dst [4]byte, data []byte) (tcpSegment, error) {
+	if len(data) < tcpHeaderLen {
+		return tcpSegment{}, errInvalidPacket
+	}
+	headerLen := int(data[12]>>4) * 4
+	if headerLen < tcpHeaderLen || headerLen > len(data) {
+		return tcpSegment{}, errInvalidPacket
+	}
+	if checksum(data, pseudoHeaderSum(src, dst, protocolTCP, len(data))) != 0 {
+		return tcpSegment{}, errInvalidPacket
+	}
+
+	s := tcpSegment{
+		srcPort: binary.BigEndian.Uint16(data[0:2]),
+		dstPort: binary.BigEndian.Uint16(data[2:4]),
+		seq:     binary.BigEndian.Uint32(data[4:8]),
+		ack:     binary.BigEndian.Uint32(data[8:12]),
+		flags:   data[13],
+		window:  binary.BigEndian.Uint16(data[14:16]),
+		payload: data[headerLen:],
+	}
+	options := data[tcpHeaderLen:headerLen]
+	for len(options) > 0 {
+		switch options[0] {
+		case tcpOptionEnd:
+			options = nil
+		case tcpOptionNop:
+			options = options[1:]
+		default:
+			if len(options) < 2 || int(options[1]) < 2 || int(options[1]) > len(options) {
+				return tcpSegment{}, errInvalidPacket
+			}
+			if options[0] == tcpOptionMSS && options[1] == 4 {
+				s.mss = binary.BigEndian.Uint16(options[2:4])
+			}
+			options = options[options[1]:]
+		}
+	}
+	return s, nil
+}
+
+func (s tcpSegment) encode(src, dst [4]byte) []byte {
+	headerLen := tcpHeaderLen
+	if s.mss != 0 {
+		headerLen += 4
+	}
+	data := make([]byte, headerLen+len(s.payload))
+	binary.BigEndian.PutUint16(data[0:2], s.srcPort)
+	binary.BigEndian.PutUint16(data[2:4], s.dstPort)
+	binary.BigEndian.PutUint32(data[4:8], s.seq)
+	binary.BigEndian.PutUint32(data[8:12], s.ack)
+	data[12] = byte(headerLen/4) << 4
+	data[13] = s.flags
+	binary.BigEndian.PutUint16(data[14:16], s.window)
+	if s.mss != 0 {
+		data[20], data[21] = tcpOptionMSS, 4
+		binary.BigEndian.PutUint16(data[22:24], s.mss)
+	}
+	copy(data[headerLen:], s.payload)
+	binary.BigEndian.PutUint16(data[16:18], checksum(data, pseudoHeaderSum(src, dst, protocolTCP, len(data))))
+	return data
+}
+
+type udpDatagram struct {
+	srcPort, dstPort uint16
+	payload          []byte
+}
+
+func parseUDP(src, dst [4]byte, data []byte) (udpDatagram, error) {
+	if len(data) < udpHeaderLen {
+		return udpDatagram{}, errInvalidPacket
+	}
+	length := int(binary.BigEndian.Uint16(data[4:6]))
+	if length < udpHeaderLen || length > len(data) {
+		return udpDatagram{}, errInvalidPacket
+	}
+	data = data[:length]
+	if binary.BigEndian.Uint16(data[6:8]) != 0 && checksum(data, pseudoHeaderSum(src, dst, protocolUDP, length)) != 0 {
+		return udpDatagram{}, errInvalidPacket
+	}
+	return udpDatagram{
+		srcPort: binary.BigEndian.Uint16(data[0:2]),
+		dstPort: binary.BigEndian.Uint16(data[2:4]),
+		payload: data[udpHeaderLen:],
+	}, nil
+}
+
+func (d udpDatagram) encode(src, dst [4]byte) []byte {
+	data := make([]byte, udpHeaderLen+len(d.payload))
+	binary.BigEndian.PutUint16(data[0:2], d.srcPort)
+	binary.BigEndian.PutUint16(data[2:4], d.dstPort)
+	binary.BigEndian.PutUint16(data[4:6], uint16(len(data)))
+	copy(data[udpHeaderLen:], d.payload)
+	sum := checksum(data, pseudoHeaderSum(src, dst, protocolUDP, len(data)))
+	if sum == 0 {
+		sum = 0xffff
+	}
+	binary.BigEndian.PutUint16(data[6:8], sum)
+	return data
+}
+
+func pseudoHeaderSum(src, dst [4]byte, protocol uint8, length int) uint32 {
+	return uint32(binary.BigEndian.Uint16(src[0:2])) + uint32(binary.BigEndian.Uint16(src[2:4])) +
+		uint32(binary.BigEndian.Uint16(dst[0:2])) + uint32(binary.BigEndian.Uint16(dst[2:4])) +
+		uint32(protocol) + uint32(length)
+}
+
+func checksum(data []byte, initial uint32) uint16 {
+	sum := initial
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(data[i])<<8 | uint32(data[i+1])
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
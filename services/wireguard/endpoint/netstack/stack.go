@@ -0,0 +1,392 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netstack
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"golang.zx2c4.com/wireguard/tun"
+)
+
+const (
+	ephemeralPortFirst = 32768
+	ephemeralPortLast  = 60999
+	outboundQueueSize  = 1024
+)
+
+var (
+	errClosed            = errors.New("use of closed network connection")
+	errConnectionRefused = errors.New("connection refused")
+	errConnectionReset   = errors.New("connection reset by peer")
+	errConnectionTimeout = errors.New("connection timed out")
+	errNoFreePort        = errors.New("no free local port")
+)
+
+type connID struct {
+	localPort  uint16
+	remoteAddr [4]byte
+	remotePort uint16
+}
+
+// Stack is a minimal IPv4 TCP/UDP network stack running in user space.
+// It implements tun.Device, so WireGuard exchanges packets with it instead
+// of an OS network interface.
+type Stack struct {
+	addr [4]byte
+	mtu  int
+
+	outbound  chan []byte
+	events    chan tun.Event
+	done      chan struct{}
+	closeOnce sync.Once
+	packetID  uint32
+
+	mu           sync.Mutex
+	tcpConns     map[connID]*tcpConn
+	tcpListeners map[uint16]*tcpListener
+	udpConns     map[uint16]*udpConn
+	nextPort     uint16
+}
+
+var _ tun.Device = &Stack{}
+
+// New creates network stack with the given local IPv4 address.
+func New(addr net.IP, mtu int) (*Stack, error) {
+	ip := addr.To4()
+	if ip == nil {
+		return nil, fmt.Errorf("address %s is not IPv4", addr)
+	}
+
+	s := &Stack{
+		mtu:          mtu,
+		outbound:     make(chan []byte, outboundQueueSize),
+		events:       make(chan tun.Event, 1),
+		done:         make(chan struct{}),
+		tcpConns:     make(map[connID]*tcpConn),
+		tcpListeners: make(map[uint16]*tcpListener),
+		udpConns:     make(map[uint16]*udpConn),
+		nextPort:     ephemeralPortFirst + uint16(randomUint32()%(ephemeralPortLast-ephemeralPortFirst)),
+	}
+	copy(s.addr[:], ip)
+	s.events <- tun.EventUp
+	return s, nil
+}
+
+// Addr returns local address of the stack.
+func (s *Stack) Addr() net.IP {
+	return net.IPv4(s.addr[0], s.addr[1], s.addr[2], s.addr[3])
+}
+
+// DialContext connects to the address on the named network inside the stack.
+// Supported networks are "tcp", "tcp4", "udp" and "udp4", address host must be an IPv4 address.
+func (s *Stack) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	ip, port, err := parseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	switch network {
+	case "tcp", "tcp4":
+		return s.dialTCP(ctx, ip, port)
+	case "udp", "udp4":
+		return s.listenUDP(0, &net.UDPAddr{IP: net.IP(ip[:]), Port: int(port)})
+	default:
+		return nil, fmt.Errorf("unsupported network %q", network)
+	}
+}
+
+// Listen listens for incoming TCP connections on the given port.
+func (s *Stack) Listen(port int) (net.Listener, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tcpListeners[uint16(port)]; ok {
+		return nil, fmt.Errorf("port %d is already in use", port)
+	}
+	l := newTCPListener(s, uint16(port))
+	s.tcpListeners[uint16(port)] = l
+	return l, nil
+}
+
+// ListenPacket listens for UDP datagrams on the given port, zero port selects a free one.
+func (s *Stack) ListenPacket(port int) (net.PacketConn, error) {
+	return s.listenUDP(uint16(port), nil)
+}
+
+// File is not supported by the stack.
+func (s *Stack) File() *os.File {
+	return nil
+}
+
+// Read returns next packet sent by the stack.
+func (s *Stack) Read(buf []byte, offset int) (int, error) {
+	select {
+	case packet := <-s.outbound:
+		return copy(buf[offset:], packet), nil
+	case <-s.done:
+		return 0, os.ErrClosed
+	}
+}
+
+// Write delivers a packet received from the tunnel to the stack.
+func (s *Stack) Write(buf []byte, offset int) (int, error) {
+	packet := buf[offset:]
+	s.deliver(packet)
+	return len(packet), nil
+}
+
+// Flush does nothing as packets are not buffered.
+func (s *Stack) Flush() error {
+	return nil
+}
+
+// MTU returns the stack MTU.
+func (s *Stack) MTU() (int, error) {
+	return s.mtu, nil
+}
+
+// Name returns the stack name.
+func (s *Stack) Name() (string, error) {
+	return "netstack", nil
+}
+
+// Events returns device events channel.
+func (s *Stack) Events() chan tun.Event {
+	return s.events
+}
+
+// Close closes all connections and the stack.
+func (s *Stack) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		close(s.events)
+
+		s.mu.Lock()
+		var tcpConns []*tcpConn
+		for _, c := range s.tcpConns {
+			tcpConns = append(tcpConns, c)
+		}
+		var udpConns []*udpConn
+		for _, c := range s.udpConns {
+			udpConns = append(udpConns, c)
+		}
+		var listeners []*tcpListener
+		for _, l := range s.tcpListeners {
+			listeners = append(listeners, l)
+		}
+		s.mu.Unlock()
+
+		for _, c := range tcpConns {
+			c.abort(errClosed)
+		}
+		for _, c := range udpConns {
+			c.Close()
+		}
+		for _, l := range listeners {
+			l.Close()
+		}
+	})
+	return nil
+}
+
+func (s *Stack) deliver(packet []byte) {
+	p, err := parseIPv4(packet)
+	if err != nil || p.dst != s.addr {
+		return
+	}
+
+	switch p.protocol {
+	case protocolTCP:
+		seg, err := parseTCP(p.src, p.dst, p.payload)
+		if err != nil {
+			return
+		}
+		s.deliverTCP(p.src, seg)
+	case protocolUDP:
+		d, err := parseUDP(p.src, p.dst, p.payload)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		c, ok := s.udpConns[d.dstPort]
+		s.mu.Unlock()
+		if ok {
+			c.deliver(p.src, d)
+		}
+	}
+}
+
+func (s *Stack) deliverTCP(src [4]byte, seg tcpSegment) {
+	id := connID{localPort: seg.dstPort, remoteAddr: src, remotePort: seg.srcPort}
+
+	s.mu.Lock()
+	c, ok := s.tcpConns[id]
+	var listener *tcpListener
+	if !ok && seg.flags&(flagSYN|flagACK|flagRST) == flagSYN {
+		if l, listening := s.tcpListeners[seg.dstPort]; listening {
+			c, listener = newTCPConn(s, id), l
+			s.tcpConns[id] = c
+		}
+	}
+	s.mu.Unlock()
+
+	if listener != nil {
+		c.acceptSyn(listener, seg)
+		return
+	}
+	if ok {
+		c.handleSegment(seg)
+		return
+	}
+	if seg.flags&flagRST != 0 {
+		return
+	}
+
+	reset := tcpSegment{srcPort: seg.dstPort, dstPort: seg.srcPort, flags: flagRST}
+	if seg.flags&flagACK != 0 {
+		reset.seq = seg.ack
+	} else {
+		reset.flags |= flagACK
+		reset.ack = seg.seq + seg.length()
+	}
+	s.sendTCP(src, reset)
+}
+
+func (s *Stack) sendTCP(dst [4]byte, seg tcpSegment) {
+	s.send(dst, protocolTCP, seg.encode(s.addr, dst))
+}
+
+func (s *Stack) sendUDP(dst [4]byte, d udpDatagram) error {
+	return s.send(dst, protocolUDP, d.encode(s.addr, dst))
+}
+
+func (s *Stack) send(dst [4]byte, protocol uint8, payload []byte) error {
+	packet := encodeIPv4(s.addr, dst, protocol, uint16(atomic.AddUint32(&s.packetID, 1)), payload)
+	select {
+	case s.outbound <- packet:
+		return nil
+	case <-s.done:
+		return errClosed
+	}
+}
+
+func (s *Stack) dialTCP(ctx context.Context, addr [4]byte, port uint16) (net.Conn, error) {
+	s.mu.Lock()
+	localPort, err := s.allocatePort(func(p uint16) bool {
+		_, used := s.tcpConns[connID{localPort: p, remoteAddr: addr, remotePort: port}]
+		_, listening := s.tcpListeners[p]
+		return used || listening
+	})
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	c := newTCPConn(s, connID{localPort: localPort, remoteAddr: addr, remotePort: port})
+	s.tcpConns[c.id] = c
+	s.mu.Unlock()
+
+	if err := c.connect(ctx); err != nil {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Addr: c.RemoteAddr(), Err: err}
+	}
+	return c, nil
+}
+
+func (s *Stack) listenUDP(port uint16, remote *net.UDPAddr) (*udpConn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if port == 0 {
+		var err error
+		port, err = s.allocatePort(func(p uint16) bool {
+			_, used := s.udpConns[p]
+			return used
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if _, used := s.udpConns[port]; used {
+		return nil, fmt.Errorf("port %d is already in use", port)
+	}
+
+	c := newUDPConn(s, port, remote)
+	s.udpConns[port] = c
+	return c, nil
+}
+
+func (s *Stack) allocatePort(used func(port uint16) bool) (uint16, error) {
+	for i := 0; i <= ephemeralPortLast-ephemeralPortFirst; i++ {
+		port := s.nextPort
+		if s.nextPort++; s.nextPort > ephemeralPortLast {
+			s.nextPort = ephemeralPortFirst
+		}
+		if !used(port) {
+			return port, nil
+		}
+	}
+	return 0, errNoFreePort
+}
+
+func (s *Stack) removeTCPConn(id connID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tcpConns, id)
+}
+
+func (s *Stack) removeTCPListener(port uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tcpListeners, port)
+}
+
+func (s *Stack) removeUDPConn(port uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.udpConns, port)
+}
+
+func parseAddress(address string) (ip [4]byte, port uint16, err error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return ip, 0, err
+	}
+	addr := net.ParseIP(host).To4()
+	if addr == nil {
+		return ip, 0, fmt.Errorf("address %q is not IPv4", host)
+	}
+	p, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return ip, 0, fmt.Errorf("invalid port %q: %w", portStr, err)
+	}
+	copy(ip[:], addr)
+	return ip, uint16(p), nil
+}
+
+func randomUint32() uint32 {
+	var b [4]byte
+	rand.Read(b[:])
+	return binary.BigEndian.Uint32(b[:])
+}
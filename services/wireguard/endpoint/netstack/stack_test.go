@@ -0,0 +1,186 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netstack
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// link forwards packets between two stacks, drop decides which packets are lost.
+func link(t *testing.T, a, b *Stack, drop func(n uint64) bool) {
+	var counter uint64
+	forward := func(from, to *Stack) {
+		buf := make([]byte, 2000)
+		for {
+			n, err := from.Read(buf, 0)
+			if err != nil {
+				return
+			}
+			if drop != nil && drop(atomic.AddUint64(&counter, 1)) {
+				continue
+			}
+			to.Write(buf[:n], 0)
+		}
+	}
+	go forward(a, b)
+	go forward(b, a)
+}
+
+func newStackPair(t *testing.T, drop func(n uint64) bool) (*Stack, *Stack) {
+	client, err := New(net.ParseIP("10.182.0.2"), 1280)
+	require.NoError(t, err)
+	server, err := New(net.ParseIP("10.182.0.1"), 1280)
+	require.NoError(t, err)
+	link(t, client, server, drop)
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client, server
+}
+
+func echoServer(t *testing.T, s *Stack, port int) {
+	l, err := s.Listen(port)
+	require.NoError(t, err)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+}
+
+func TestStackTCPEcho(t *testing.T) {
+	client, server := newStackPair(t, nil)
+	echoServer(t, server, 80)
+
+	conn, err := client.DialContext(context.Background(), "tcp", "10.182.0.1:80")
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "10.182.0.1:80", conn.RemoteAddr().String())
+
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf))
+}
+
+func TestStackTCPTransferWithPacketLoss(t *testing.T) {
+	client, server := newStackPair(t, func(n uint64) bool {
+		return n%10 == 0
+	})
+	echoServer(t, server, 80)
+
+	data := make([]byte, 64<<10)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+
+	conn, err := client.DialContext(context.Background(), "tcp", "10.182.0.1:80")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	go func() {
+		conn.Write(data)
+		conn.(*tcpConn).CloseWrite()
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(time.Minute))
+	received, err := ioutil.ReadAll(conn)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(data, received))
+}
+
+func TestStackTCPConnectionRefused(t *testing.T) {
+	client, _ := newStackPair(t, nil)
+
+	_, err := client.DialContext(context.Background(), "tcp", "10.182.0.1:81")
+	assert.Contains(t, err.Error(), errConnectionRefused.Error())
+}
+
+func TestStackTCPReadDeadline(t *testing.T) {
+	client, server := newStackPair(t, nil)
+	echoServer(t, server, 80)
+
+	conn, err := client.DialContext(context.Background(), "tcp", "10.182.0.1:80")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	_, err = conn.Read(make([]byte, 1))
+	netErr, ok := err.(net.Error)
+	require.True(t, ok)
+	assert.True(t, netErr.Timeout())
+}
+
+func TestStackUDP(t *testing.T) {
+	client, server := newStackPair(t, nil)
+
+	pc, err := server.ListenPacket(53)
+	require.NoError(t, err)
+	defer pc.Close()
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			pc.WriteTo(buf[:n], addr)
+		}
+	}()
+
+	conn, err := client.DialContext(context.Background(), "udp", "10.182.0.1:53")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("query"))
+	require.NoError(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 512)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "query", string(buf[:n]))
+}
+
+func TestStackDialRejectsHostnames(t *testing.T) {
+	client, _ := newStackPair(t, nil)
+
+	_, err := client.DialContext(context.Background(), "tcp", "example.com:80")
+	assert.Error(t, err)
+}
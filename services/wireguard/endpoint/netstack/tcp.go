@@ -0,0 +1,689 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netstack
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	tcpSendBufferSize    = 256 << 10
+	tcpReceiveBufferSize = 65535
+	tcpInitialRTO        = time.Second
+	tcpMaxRTO            = 60 * time.Second
+	tcpMaxRetries        = 8
+	tcpLingerTimeout     = time.Minute
+	tcpAcceptQueueSize   = 128
+	tcpDupAckThreshold   = 3
+)
+
+type tcpState int
+
+const (
+	tcpSynSent tcpState = iota
+	tcpSynReceived
+	tcpEstablished
+	tcpClosed
+)
+
+// tcpConn is a TCP connection of the stack. It implements a small subset of TCP:
+// no window scaling, no selective acknowledgements and go-back-N retransmissions
+// triggered by timeout or duplicate acknowledgements.
+type tcpConn struct {
+	stack *Stack
+	id    connID
+
+	mu      sync.Mutex
+	changed chan struct{} // Closed and replaced on every state change.
+	state   tcpState
+	err     error
+
+	iss         uint32
+	sndUna      uint32
+	sndNxt      uint32
+	sndMax      uint32
+	sndWnd      uint32
+	dupAcks     int
+	mss         int
+	sendBuf     []byte // Data starting at sndUna.
+	writeClosed bool
+	finSent     bool
+	finAcked    bool
+
+	rcvNxt      uint32
+	recvBuf     []byte
+	recvWnd     int // Last advertised receive window.
+	finReceived bool
+	closed      bool
+
+	rto          time.Duration
+	retries      int
+	timer        *time.Timer
+	timerGen     int
+	timerRunning bool
+	lingerTimer  *time.Timer
+
+	listener *tcpListener
+
+	readDeadline  *deadline
+	writeDeadline *deadline
+}
+
+var _ net.Conn = &tcpConn{}
+
+func newTCPConn(stack *Stack, id connID) *tcpConn {
+	iss := randomUint32()
+	return &tcpConn{
+		stack:         stack,
+		id:            id,
+		changed:       make(chan struct{}),
+		iss:           iss,
+		sndUna:        iss,
+		sndNxt:        iss,
+		sndMax:        iss,
+		mss:           stack.mtu - ipv4HeaderLen - tcpHeaderLen,
+		rto:           tcpInitialRTO,
+		readDeadline:  newDeadline(),
+		writeDeadline: newDeadline(),
+	}
+}
+
+// connect performs active open and waits until the connection is established.
+func (c *tcpConn) connect(ctx context.Context) error {
+	c.mu.Lock()
+	c.state = tcpSynSent
+	c.sendSyn()
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		state, err, changed := c.state, c.err, c.changed
+		c.mu.Unlock()
+
+		switch {
+		case err != nil:
+			return err
+		case state == tcpEstablished:
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			c.abort(ctx.Err())
+			return ctx.Err()
+		}
+	}
+}
+
+// acceptSyn handles the SYN of passive open, connection is queued to the listener once established.
+func (c *tcpConn) acceptSyn(l *tcpListener, seg tcpSegment) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.state = tcpSynReceived
+	c.listener = l
+	c.rcvNxt = seg.seq + 1
+	c.sndWnd = uint32(seg.window)
+	c.updateMSS(seg.mss)
+	c.sendSyn()
+}
+
+func (c *tcpConn) sendSyn() {
+	c.sndNxt = c.iss
+	flags := uint8(flagSYN)
+	if c.state == tcpSynReceived {
+		flags |= flagACK
+	}
+	c.sendSegment(flags, nil)
+	c.sndNxt = c.iss + 1
+	c.sndMax = c.sndNxt
+	c.startTimer()
+}
+
+func (c *tcpConn) updateMSS(mss uint16) {
+	if mss != 0 && int(mss) < c.mss {
+		c.mss = int(mss)
+	}
+}
+
+func (c *tcpConn) handleSegment(seg tcpSegment) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == tcpClosed {
+		return
+	}
+
+	if seg.flags&flagRST != 0 {
+		c.handleReset(seg)
+		return
+	}
+
+	switch c.state {
+	case tcpSynSent:
+		if seg.flags&flagACK != 0 && seg.ack != c.iss+1 {
+			c.stack.sendTCP(c.id.remoteAddr, tcpSegment{srcPort: c.id.localPort, dstPort: c.id.remotePort, seq: seg.ack, flags: flagRST})
+			return
+		}
+		if seg.flags&(flagSYN|flagACK) != flagSYN|flagACK {
+			return
+		}
+		c.rcvNxt = seg.seq + 1
+		c.sndUna = seg.ack
+		c.sndWnd = uint32(seg.window)
+		c.updateMSS(seg.mss)
+		c.state = tcpEstablished
+		c.stopTimer()
+		c.sendAck()
+		c.notify()
+		return
+	case tcpSynReceived:
+		if seg.flags&flagSYN != 0 {
+			// Our SYN-ACK was lost.
+			c.sendSyn()
+			return
+		}
+		if seg.flags&flagACK == 0 || seg.ack != c.iss+1 {
+			return
+		}
+		c.sndUna = seg.ack
+		c.state = tcpEstablished
+		c.stopTimer()
+		if !c.listener.enqueue(c) {
+			c.abortLocked(errConnectionRefused, true)
+			return
+		}
+		c.notify()
+	}
+
+	if seg.flags&flagSYN != 0 {
+		// Our ACK of SYN-ACK was lost.
+		c.sendAck()
+		return
+	}
+	if seg.flags&flagACK != 0 {
+		c.handleAck(seg)
+	}
+	c.handleData(seg)
+	c.output()
+
+	if c.finAcked && c.finReceived {
+		c.finish(nil, false)
+	}
+}
+
+func (c *tcpConn) handleReset(seg tcpSegment) {
+	if c.state == tcpSynSent {
+		if seg.flags&flagACK != 0 && seg.ack == c.sndNxt {
+			c.finish(errConnectionRefused, false)
+		}
+		return
+	}
+	if offset := seg.seq - c.rcvNxt; offset < tcpReceiveBufferSize {
+		c.finish(errConnectionReset, false)
+	}
+}
+
+func (c *tcpConn) handleAck(seg tcpSegment) {
+	if int32(seg.ack-c.sndUna) < 0 || int32(seg.ack-c.sndMax) > 0 {
+		if int32(seg.ack-c.sndMax) > 0 {
+			c.sendAck()
+		}
+		return
+	}
+
+	acked := seg.ack - c.sndUna
+	if acked == 0 {
+		if len(seg.payload) == 0 && seg.flags&flagFIN == 0 && uint32(seg.window) == c.sndWnd && c.sndUna != c.sndMax {
+			if c.dupAcks++; c.dupAcks == tcpDupAckThreshold {
+				c.retransmit()
+			}
+		}
+		c.sndWnd = uint32(seg.window)
+		return
+	}
+	c.sndWnd = uint32(seg.window)
+	c.dupAcks = 0
+
+	if acked > uint32(len(c.sendBuf)) {
+		acked = uint32(len(c.sendBuf))
+		c.finSent = true
+		c.finAcked = true
+	}
+	c.sendBuf = c.sendBuf[acked:]
+	c.sndUna = seg.ack
+	if int32(c.sndNxt-c.sndUna) < 0 {
+		c.sndNxt = c.sndUna
+	}
+
+	c.retries = 0
+	c.rto = tcpInitialRTO
+	c.stopTimer()
+	c.notify()
+}
+
+func (c *tcpConn) handleData(seg tcpSegment) {
+	fin := seg.flags&flagFIN != 0
+	if len(seg.payload) == 0 && !fin {
+		return
+	}
+
+	offset := int32(c.rcvNxt - seg.seq)
+	if offset < 0 || int(offset) > len(seg.payload) || c.finReceived {
+		// Out of order or duplicate segment, acknowledge what we have.
+		c.sendAck()
+		return
+	}
+
+	payload := seg.payload[offset:]
+	if space := tcpReceiveBufferSize - len(c.recvBuf); len(payload) > space {
+		payload, fin = payload[:space], false
+	}
+	if !c.closed {
+		c.recvBuf = append(c.recvBuf, payload...)
+	}
+	c.rcvNxt += uint32(len(payload))
+	if fin {
+		c.rcvNxt++
+		c.finReceived = true
+	}
+	c.sendAck()
+	c.notify()
+}
+
+// output sends queued data allowed by the peer window and FIN once all data is sent.
+func (c *tcpConn) output() {
+	if c.state != tcpEstablished {
+		return
+	}
+
+	for !c.finSent {
+		offset := int(c.sndNxt - c.sndUna)
+		unsent := len(c.sendBuf) - offset
+		if unsent > 0 {
+			n := int(c.sndWnd) - offset
+			if n <= 0 {
+				break
+			}
+			if n > unsent {
+				n = unsent
+			}
+			if n > c.mss {
+				n = c.mss
+			}
+			c.sendSegment(flagACK|flagPSH, c.sendBuf[offset:offset+n])
+			c.sndNxt += uint32(n)
+			continue
+		}
+		if c.writeClosed {
+			c.sendSegment(flagACK|flagFIN, nil)
+			c.sndNxt++
+			c.finSent = true
+		}
+		break
+	}
+	if int32(c.sndNxt-c.sndMax) > 0 {
+		c.sndMax = c.sndNxt
+	}
+
+	// Retransmit unacknowledged data or probe zero window.
+	if c.sndUna != c.sndMax || len(c.sendBuf) > 0 {
+		c.startTimer()
+	}
+}
+
+func (c *tcpConn) onTimeout(gen int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.timerGen || c.state == tcpClosed {
+		return
+	}
+	c.timerRunning = false
+
+	switch {
+	case c.state == tcpSynSent || c.state == tcpSynReceived:
+		if c.retries >= tcpMaxRetries {
+			c.finish(errConnectionTimeout, false)
+			return
+		}
+		c.backoff()
+		c.sendSyn()
+	case c.sndUna != c.sndMax:
+		if c.retries >= tcpMaxRetries {
+			c.finish(errConnectionTimeout, true)
+			return
+		}
+		c.backoff()
+		c.retransmit()
+	case len(c.sendBuf) > 0:
+		// Zero window probe, peer acknowledges the byte once it has space.
+		c.sendSegment(flagACK, c.sendBuf[:1])
+		c.sndNxt = c.sndUna + 1
+		c.sndMax = c.sndNxt
+		if c.rto *= 2; c.rto > tcpMaxRTO {
+			c.rto = tcpMaxRTO
+		}
+		c.startTimer()
+	}
+}
+
+// retransmit resends all unacknowledged data, receiver drops out of order segments anyway.
+func (c *tcpConn) retransmit() {
+	c.sndNxt = c.sndUna
+	c.finSent = false
+	c.output()
+}
+
+func (c *tcpConn) backoff() {
+	c.retries++
+	if c.rto *= 2; c.rto > tcpMaxRTO {
+		c.rto = tcpMaxRTO
+	}
+}
+
+func (c *tcpConn) startTimer() {
+	if c.timerRunning {
+		return
+	}
+	c.timerRunning = true
+	gen := c.timerGen
+	c.timer = time.AfterFunc(c.rto, func() {
+		c.onTimeout(gen)
+	})
+}
+
+func (c *tcpConn) stopTimer() {
+	c.timerGen++
+	c.timerRunning = false
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+}
+
+func (c *tcpConn) sendAck() {
+	c.sendSegment(flagACK, nil)
+}
+
+func (c *tcpConn) sendSegment(flags uint8, payload []byte) {
+	window := tcpReceiveBufferSize - len(c.recvBuf)
+	seg := tcpSegment{
+		srcPort: c.id.localPort,
+		dstPort: c.id.remotePort,
+		seq:     c.sndNxt,
+		flags:   flags,
+		window:  uint16(window),
+		payload: payload,
+	}
+	if flags&flagACK != 0 {
+		seg.ack = c.rcvNxt
+	}
+	if flags&flagSYN != 0 {
+		seg.mss = uint16(c.stack.mtu - ipv4HeaderLen - tcpHeaderLen)
+	}
+	c.recvWnd = window
+	c.stack.sendTCP(c.id.remoteAddr, seg)
+}
+
+func (c *tcpConn) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *tcpConn) abort(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.abortLocked(err, true)
+}
+
+func (c *tcpConn) abortLocked(err error, reset bool) {
+	c.closed = true
+	c.recvBuf = nil
+	c.finish(err, reset && c.state != tcpSynSent)
+}
+
+// finish moves connection to the closed state and removes it from the stack.
+func (c *tcpConn) finish(err error, reset bool) {
+	if c.state == tcpClosed {
+		return
+	}
+	if reset {
+		c.sendSegment(flagRST|flagACK, nil)
+	}
+	c.state = tcpClosed
+	if c.err == nil {
+		c.err = err
+	}
+	c.stopTimer()
+	if c.lingerTimer != nil {
+		c.lingerTimer.Stop()
+	}
+	c.stack.removeTCPConn(c.id)
+	c.notify()
+}
+
+// Read reads data from the connection.
+func (c *tcpConn) Read(b []byte) (int, error) {
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return 0, errClosed
+		}
+		if len(c.recvBuf) > 0 {
+			n := copy(b, c.recvBuf)
+			c.recvBuf = c.recvBuf[n:]
+			// Let the peer know about the window opening when it was too small to send a full segment.
+			if c.state == tcpEstablished && c.recvWnd < c.mss && tcpReceiveBufferSize-len(c.recvBuf) >= c.mss {
+				c.sendAck()
+			}
+			c.mu.Unlock()
+			return n, nil
+		}
+		if c.finReceived {
+			c.mu.Unlock()
+			return 0, io.EOF
+		}
+		if c.err != nil {
+			err := c.err
+			c.mu.Unlock()
+			return 0, err
+		}
+		changed := c.changed
+		c.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-c.readDeadline.wait():
+			return 0, timeoutError{}
+		}
+	}
+}
+
+// Write writes data to the connection.
+func (c *tcpConn) Write(b []byte) (int, error) {
+	var written int
+	for written < len(b) {
+		c.mu.Lock()
+		if c.err != nil {
+			err := c.err
+			c.mu.Unlock()
+			return written, err
+		}
+		if c.writeClosed || c.state == tcpClosed {
+			c.mu.Unlock()
+			return written, errClosed
+		}
+		if space := tcpSendBufferSize - len(c.sendBuf); space > 0 {
+			n := len(b) - written
+			if n > space {
+				n = space
+			}
+			c.sendBuf = append(c.sendBuf, b[written:written+n]...)
+			written += n
+			c.output()
+			c.mu.Unlock()
+			continue
+		}
+		changed := c.changed
+		c.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-c.writeDeadline.wait():
+			return written, timeoutError{}
+		}
+	}
+	return written, nil
+}
+
+// CloseWrite shuts down the writing side, FIN is sent once all queued data is delivered.
+func (c *tcpConn) CloseWrite() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == tcpClosed {
+		return errClosed
+	}
+	c.writeClosed = true
+	c.output()
+	c.notify()
+	return nil
+}
+
+// Close closes the connection, queued data is still delivered to the peer.
+func (c *tcpConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return errClosed
+	}
+	c.closed = true
+	c.recvBuf = nil
+	c.writeClosed = true
+	c.output()
+	if c.state != tcpClosed {
+		c.lingerTimer = time.AfterFunc(tcpLingerTimeout, func() {
+			c.abort(errConnectionTimeout)
+		})
+	}
+	c.notify()
+	return nil
+}
+
+// LocalAddr returns local address of the connection.
+func (c *tcpConn) LocalAddr() net.Addr {
+	return &net.TCPAddr{IP: c.stack.Addr(), Port: int(c.id.localPort)}
+}
+
+// RemoteAddr returns remote address of the connection.
+func (c *tcpConn) RemoteAddr() net.Addr {
+	ip := c.id.remoteAddr
+	return &net.TCPAddr{IP: net.IPv4(ip[0], ip[1], ip[2], ip[3]), Port: int(c.id.remotePort)}
+}
+
+// SetDeadline sets read and write deadlines.
+func (c *tcpConn) SetDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	c.writeDeadline.set(t)
+	return nil
+}
+
+// SetReadDeadline sets read deadline.
+func (c *tcpConn) SetReadDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	return nil
+}
+
+// SetWriteDeadline sets write deadline.
+func (c *tcpConn) SetWriteDeadline(t time.Time) error {
+	c.writeDeadline.set(t)
+	return nil
+}
+
+type tcpListener struct {
+	stack     *Stack
+	port      uint16
+	accept    chan *tcpConn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+var _ net.Listener = &tcpListener{}
+
+func newTCPListener(stack *Stack, port uint16) *tcpListener {
+	return &tcpListener{
+		stack:  stack,
+		port:   port,
+		accept: make(chan *tcpConn, tcpAcceptQueueSize),
+		done:   make(chan struct{}),
+	}
+}
+
+func (l *tcpListener) enqueue(c *tcpConn) bool {
+	select {
+	case <-l.done:
+		return false
+	default:
+	}
+
+	select {
+	case l.accept <- c:
+		return true
+	default:
+		return false
+	}
+}
+
+// Accept waits for the next established connection.
+func (l *tcpListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.accept:
+		return c, nil
+	case <-l.done:
+		return nil, errClosed
+	}
+}
+
+// Close stops listening and resets connections which are not accepted yet.
+func (l *tcpListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+		l.stack.removeTCPListener(l.port)
+		for {
+			select {
+			case c := <-l.accept:
+				c.abort(errClosed)
+			default:
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// Addr returns listening address.
+func (l *tcpListener) Addr() net.Addr {
+	return &net.TCPAddr{IP: l.stack.Addr(), Port: int(l.port)}
+}
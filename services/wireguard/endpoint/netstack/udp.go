@@ -0,0 +1,171 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netstack
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+const udpQueueSize = 128
+
+type datagram struct {
+	addr    *net.UDPAddr
+	payload []byte
+}
+
+// udpConn is a UDP socket of the stack, connected when remote address is set.
+type udpConn struct {
+	stack     *Stack
+	localPort uint16
+	remote    *net.UDPAddr
+
+	incoming  chan datagram
+	done      chan struct{}
+	closeOnce sync.Once
+
+	readDeadline *deadline
+}
+
+var (
+	_ net.Conn       = &udpConn{}
+	_ net.PacketConn = &udpConn{}
+)
+
+func newUDPConn(stack *Stack, localPort uint16, remote *net.UDPAddr) *udpConn {
+	return &udpConn{
+		stack:        stack,
+		localPort:    localPort,
+		remote:       remote,
+		incoming:     make(chan datagram, udpQueueSize),
+		done:         make(chan struct{}),
+		readDeadline: newDeadline(),
+	}
+}
+
+func (c *udpConn) deliver(src [4]byte, d udpDatagram) {
+	addr := &net.UDPAddr{IP: net.IPv4(src[0], src[1], src[2], src[3]), Port: int(d.srcPort)}
+	if c.remote != nil && (!c.remote.IP.Equal(addr.IP) || c.remote.Port != addr.Port) {
+		return
+	}
+
+	payload := make([]byte, len(d.payload))
+	copy(payload, d.payload)
+	select {
+	case c.incoming <- datagram{addr: addr, payload: payload}:
+	default:
+		// Queue is full, drop the datagram as the network would.
+	}
+}
+
+// Read reads a datagram from the connection.
+func (c *udpConn) Read(b []byte) (int, error) {
+	n, _, err := c.ReadFrom(b)
+	return n, err
+}
+
+// ReadFrom reads a datagram and returns its sender address.
+func (c *udpConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	select {
+	case <-c.done:
+		return 0, nil, errClosed
+	default:
+	}
+
+	select {
+	case d := <-c.incoming:
+		return copy(b, d.payload), d.addr, nil
+	case <-c.done:
+		return 0, nil, errClosed
+	case <-c.readDeadline.wait():
+		return 0, nil, timeoutError{}
+	}
+}
+
+// Write sends a datagram to the connected remote address.
+func (c *udpConn) Write(b []byte) (int, error) {
+	if c.remote == nil {
+		return 0, errors.New("connection is not connected")
+	}
+	return c.WriteTo(b, c.remote)
+}
+
+// WriteTo sends a datagram to the given address.
+func (c *udpConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	select {
+	case <-c.done:
+		return 0, errClosed
+	default:
+	}
+
+	udpAddr, ok := addr.(*net.UDPAddr)
+	if !ok || udpAddr.IP.To4() == nil {
+		return 0, errors.New("address is not UDP IPv4")
+	}
+	if len(b) > c.stack.mtu-ipv4HeaderLen-udpHeaderLen {
+		return 0, errors.New("message too long")
+	}
+
+	var dst [4]byte
+	copy(dst[:], udpAddr.IP.To4())
+	err := c.stack.sendUDP(dst, udpDatagram{srcPort: c.localPort, dstPort: uint16(udpAddr.Port), payload: b})
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Close closes the connection.
+func (c *udpConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.stack.removeUDPConn(c.localPort)
+	})
+	return nil
+}
+
+// LocalAddr returns local address of the connection.
+func (c *udpConn) LocalAddr() net.Addr {
+	return &net.UDPAddr{IP: c.stack.Addr(), Port: int(c.localPort)}
+}
+
+// RemoteAddr returns remote address of the connected connection.
+func (c *udpConn) RemoteAddr() net.Addr {
+	if c.remote == nil {
+		return nil
+	}
+	return c.remote
+}
+
+// SetDeadline sets read deadline, writes never block.
+func (c *udpConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+// SetReadDeadline sets read deadline.
+func (c *udpConn) SetReadDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	return nil
+}
+
+// SetWriteDeadline does nothing as writes never block.
+func (c *udpConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
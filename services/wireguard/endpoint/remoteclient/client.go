@@ -20,6 +20,7 @@ package remoteclient
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/user"
 	"sync"
@@ -66,6 +67,14 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) error {
 	return nil
 }
 
+func (c *client) ReconfigureEndpoint(wgcfg.DeviceConfig) error {
+	return errors.New("endpoint reconfiguration is not supported by supervisor")
+}
+
+func (c *client) ReconfigureAllowedIPs(wgcfg.DeviceConfig, []string) error {
+	return errors.New("allowed IPs reconfiguration is not supported by supervisor")
+}
+
 func (c *client) DestroyDevice(iface string) error {
 	_, err := supervisorclient.Command("wg-down", "-iface", iface)
 	if err != nil {
@@ -26,6 +26,7 @@ import (
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 	"golang.zx2c4.com/wireguard/device"
 	"golang.zx2c4.com/wireguard/tun"
 )
@@ -47,6 +48,11 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 	if c.tun, err = CreateTUN(config.IfaceName, config.Subnet); err != nil {
 		return errors.Wrap(err, "failed to create TUN device")
 	}
+	if config.Subnet6 != nil {
+		if err := netutil.AssignIP(config.IfaceName, *config.Subnet6); err != nil {
+			return errors.Wrap(err, "failed to assign IPv6 address")
+		}
+	}
 
 	c.devAPI = device.NewDevice(c.tun, device.NewLogger(device.LogLevelDebug, "[userspace-wg]"))
 	if err := c.setDeviceConfig(config.Encode()); err != nil {
@@ -56,13 +62,16 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 	c.devAPI.Up()
 
 	// For consumer mode we need to exclude provider's IP from VPN tunnel
-	// and add default routes to forward all traffic via VPN tunnel.
+	// and add routes to forward allowed traffic via VPN tunnel.
 	if config.Peer.Endpoint != nil {
-		if err := netutil.ExcludeRoute(config.Peer.Endpoint.IP); err != nil {
-			return fmt.Errorf("could not exclude route %s: %w", config.Peer.Endpoint.IP.String(), err)
+		// Loopback endpoint is a local obfuscation proxy, provider route is excluded by p2p dialer.
+		if !config.Peer.Endpoint.IP.IsLoopback() {
+			if err := netutil.ExcludeRoute(config.Peer.Endpoint.IP); err != nil {
+				return fmt.Errorf("could not exclude route %s: %w", config.Peer.Endpoint.IP.String(), err)
+			}
 		}
-		if err := netutil.AddDefaultRoute(config.IfaceName); err != nil {
-			return fmt.Errorf("could not add default route for %s: %w", config.IfaceName, err)
+		if err := netutil.AddTunnelRoutes(config.IfaceName, config.Peer.AllowedIPs); err != nil {
+			return fmt.Errorf("could not add tunnel routes for %s: %w", config.IfaceName, err)
 		}
 	}
 
@@ -77,6 +86,29 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 	return nil
 }
 
+func (c *client) ReconfigureEndpoint(config wgcfg.DeviceConfig) error {
+	if err := c.setDeviceConfig(config.EncodeEndpoint()); err != nil {
+		return err
+	}
+	if config.Peer.Endpoint != nil && !config.Peer.Endpoint.IP.IsLoopback() {
+		// Route to the provider might be gone together with the previous network.
+		if err := netutil.ExcludeRoute(config.Peer.Endpoint.IP); err != nil {
+			log.Warn().Err(err).Msgf("Could not exclude route %s", config.Peer.Endpoint.IP)
+		}
+	}
+	return nil
+}
+
+func (c *client) ReconfigureAllowedIPs(config wgcfg.DeviceConfig, previous []string) error {
+	if err := c.setDeviceConfig(config.EncodeAllowedIPs()); err != nil {
+		return err
+	}
+	if err := netutil.UpdateTunnelRoutes(config.IfaceName, previous, config.Peer.AllowedIPs); err != nil {
+		return fmt.Errorf("could not update tunnel routes for %s: %w", config.IfaceName, err)
+	}
+	return nil
+}
+
 func (c *client) Close() error {
 	c.devAPI.Close() // c.devAPI.Close() closes c.tun too
 	if err := c.dnsManager.Clean(); err != nil {
@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package userspace
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/tun"
+)
+
+type netstackClient struct {
+	stack  tun.Device
+	devAPI *device.Device
+}
+
+// NewNetstackClient creates wireguard user space client which exchanges packets
+// with the given user space network stack. No OS interfaces, routes or DNS are changed.
+func NewNetstackClient(stack tun.Device) *netstackClient {
+	return &netstackClient{stack: stack}
+}
+
+func (c *netstackClient) ConfigureDevice(config wgcfg.DeviceConfig) error {
+	c.devAPI = device.NewDevice(c.stack, device.NewLogger(device.LogLevelError, "[netstack-wg]"))
+	if err := c.devAPI.IpcSetOperation(bufio.NewReader(strings.NewReader(config.Encode()))); err != nil {
+		return fmt.Errorf("failed to set device config: %w", err)
+	}
+	c.devAPI.Up()
+	return nil
+}
+
+func (c *netstackClient) ReconfigureEndpoint(config wgcfg.DeviceConfig) error {
+	if err := c.devAPI.IpcSetOperation(bufio.NewReader(strings.NewReader(config.EncodeEndpoint()))); err != nil {
+		return fmt.Errorf("failed to set device endpoint: %w", err)
+	}
+	return nil
+}
+
+func (c *netstackClient) ReconfigureAllowedIPs(config wgcfg.DeviceConfig, _ []string) error {
+	if err := c.devAPI.IpcSetOperation(bufio.NewReader(strings.NewReader(config.EncodeAllowedIPs()))); err != nil {
+		return fmt.Errorf("failed to set device allowed IPs: %w", err)
+	}
+	return nil
+}
+
+func (c *netstackClient) Close() error {
+	if c.devAPI != nil {
+		c.devAPI.Close() // c.devAPI.Close() closes c.stack too
+	}
+	return nil
+}
+
+func (c *netstackClient) PeerStats(string) (*wgcfg.Stats, error) {
+	deviceState, err := ParseUserspaceDevice(c.devAPI.IpcGetOperation)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDevicePeerStats(deviceState)
+}
+
+func (c *netstackClient) DestroyDevice(string) error {
+	return nil
+}
@@ -32,6 +32,8 @@ import (
 // WgClient represents WireGuard client.
 type WgClient interface {
 	ConfigureDevice(config wgcfg.DeviceConfig) error
+	ReconfigureEndpoint(config wgcfg.DeviceConfig) error
+	ReconfigureAllowedIPs(config wgcfg.DeviceConfig, previous []string) error
 	DestroyDevice(name string) error
 	PeerStats(iface string) (*wgcfg.Stats, error)
 	Close() error
@@ -46,19 +46,32 @@ type Allocator struct {
 
 	portSupplier portSupplier
 	subnet       net.IPNet
+	subnet6      *net.IPNet
 }
 
 // NewAllocator creates new resource pool for wireguard connection.
-func NewAllocator(ports portSupplier, subnet net.IPNet) *Allocator {
+// IPv6 networks are not allocated if subnet6 is nil.
+func NewAllocator(ports portSupplier, subnet net.IPNet, subnet6 *net.IPNet) *Allocator {
 	return &Allocator{
 		Ifaces:      make(map[int]struct{}),
 		IPAddresses: make(map[int]struct{}),
 
 		portSupplier: ports,
 		subnet:       subnet,
+		subnet6:      subnet6,
 	}
 }
 
+// Reconfigure changes ports and subnets used for new allocations, already allocated resources stay valid.
+func (a *Allocator) Reconfigure(ports portSupplier, subnet net.IPNet, subnet6 *net.IPNet) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.portSupplier = ports
+	a.subnet = subnet
+	a.subnet6 = subnet6
+}
+
 // AbandonedInterfaces returns a list of abandoned interfaces that exist in the system,
 // but was not allocated by the Allocator.
 func (a *Allocator) AbandonedInterfaces() ([]net.Interface, error) {
@@ -123,6 +136,20 @@ func (a *Allocator) AllocateIPNet() (net.IPNet, error) {
 	return net.IPNet{}, errors.New("no more unused subnets")
 }
 
+// AllocateIPNet6 provides IPv6 network paired with the allocated IPv4 network, nil if IPv6 is disabled.
+// It is released together with the IPv4 network.
+func (a *Allocator) AllocateIPNet6(ipnet net.IPNet) *net.IPNet {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ip4 := ipnet.IP.To4()
+	if a.subnet6 == nil || ip4 == nil {
+		return nil
+	}
+	ipnet6 := calcIPNet6(*a.subnet6, int(ip4[2]))
+	return &ipnet6
+}
+
 // AllocatePort provides available UDP port for the wireguard endpoint.
 func (a *Allocator) AllocatePort() (int, error) {
 	a.mu.Lock()
@@ -188,3 +215,10 @@ func calcIPNet(ipnet net.IPNet, index int) net.IPNet {
 	ip[2] = byte(index)
 	return net.IPNet{IP: ip, Mask: net.IPv4Mask(255, 255, 255, 0)}
 }
+
+func calcIPNet6(ipnet net.IPNet, index int) net.IPNet {
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, ipnet.IP.To16())
+	ip[7] = byte(index)
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(64, 8*net.IPv6len)}
+}
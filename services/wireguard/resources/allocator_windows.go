@@ -41,15 +41,18 @@ type Allocator struct {
 
 	portSupplier portSupplier
 	subnet       net.IPNet
+	subnet6      *net.IPNet
 }
 
 // NewAllocator creates new resource pool for wireguard connection.
-func NewAllocator(portSupplier portSupplier, subnet net.IPNet) *Allocator {
+// IPv6 networks are not allocated if subnet6 is nil.
+func NewAllocator(portSupplier portSupplier, subnet net.IPNet, subnet6 *net.IPNet) *Allocator {
 	return &Allocator{
 		IPAddresses: make(map[int]struct{}),
 
 		portSupplier: portSupplier,
 		subnet:       subnet,
+		subnet6:      subnet6,
 	}
 }
 
@@ -80,6 +83,17 @@ func (a *Allocator) AllocateIPNet() (net.IPNet, error) {
 	return net.IPNet{}, errors.New("no more unused subnets")
 }
 
+// AllocateIPNet6 provides IPv6 network paired with the allocated IPv4 network, nil if IPv6 is disabled.
+// It is released together with the IPv4 network.
+func (a *Allocator) AllocateIPNet6(ipnet net.IPNet) *net.IPNet {
+	ip4 := ipnet.IP.To4()
+	if a.subnet6 == nil || ip4 == nil {
+		return nil
+	}
+	ipnet6 := calcIPNet6(*a.subnet6, int(ip4[3]))
+	return &ipnet6
+}
+
 // AllocatePort provides available UDP port for the wireguard endpoint.
 func (a *Allocator) AllocatePort() (int, error) {
 	p, err := a.portSupplier.Acquire()
@@ -117,3 +131,11 @@ func calcIPNet(ipnet net.IPNet, index int) net.IPNet {
 	ip[3] = byte(index)
 	return net.IPNet{IP: ip, Mask: net.IPv4Mask(255, 255, 255, 0)}
 }
+
+// calcIPNet6 returns a separate /64 network of the session, so sessions don't share IPv6 subnet.
+func calcIPNet6(ipnet net.IPNet, index int) net.IPNet {
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, ipnet.IP.To16())
+	ip[7] = byte(index)
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(64, 8*net.IPv6len)}
+}
@@ -19,11 +19,13 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
 	"net"
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/nat"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	"github.com/rs/zerolog/log"
 )
@@ -32,6 +34,13 @@ import (
 type Options struct {
 	Ports  *port.Range
 	Subnet net.IPNet
+	// Subnet6 enables IPv6 inside the tunnel, each session gets a /64 network from it.
+	Subnet6 *net.IPNet
+	// EgressIPs lists public addresses consumer sessions are NATed to, outbound IP is used if empty.
+	EgressIPs       []string
+	EgressSelection string
+	// EgressSkipLocalCheck allows egress addresses which are not assigned to local interfaces, e.g. on 1:1 NAT hosts.
+	EgressSkipLocalCheck bool
 }
 
 // DefaultOptions is a wireguard service configuration that will be used if no options provided.
@@ -43,6 +52,15 @@ var DefaultOptions = Options{
 	},
 }
 
+// NewPortPool returns supplier of service ports from the given range, default pool is used if range is not specified.
+func NewPortPool(ports *port.Range) port.ServicePortSupplier {
+	if ports.IsSpecified() {
+		log.Info().Msgf("Fixed service port range (%s) configured, using custom port pool", ports)
+		return port.NewFixedRangePool(*ports)
+	}
+	return port.NewPool()
+}
+
 // GetOptions returns effective Wireguard service options from application configuration.
 func GetOptions() Options {
 	_, ipnet, err := net.ParseCIDR(config.GetString(config.FlagWireguardListenSubnet))
@@ -61,10 +79,22 @@ func GetOptions() Options {
 			"using default value", resources.MaxConnections)
 		portRange = port.UnspecifiedRange()
 	}
-	return Options{
+	options := Options{
 		Ports:  portRange,
 		Subnet: *ipnet,
 	}
+	if subnet6 := config.GetString(config.FlagWireguardListenSubnet6); subnet6 != "" {
+		options.Subnet6, err = parseSubnet6(subnet6)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to parse IPv6 subnet option, IPv6 will not be available")
+		}
+	}
+	if ips := config.GetStringSlice(config.FlagEgressIPs); len(ips) > 0 {
+		options.EgressIPs = ips
+		options.EgressSelection = config.GetString(config.FlagEgressSelection)
+		options.EgressSkipLocalCheck = config.GetBool(config.FlagEgressSkipLocalCheck)
+	}
+	return options
 }
 
 // ParseJSONOptions function fills in Wireguard options from JSON request
@@ -75,26 +105,45 @@ func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 	}
 
 	opts := DefaultOptions
-	err := json.Unmarshal(*request, &opts)
+	opts.Subnet6 = requestOptions.Subnet6
+	opts.EgressIPs = requestOptions.EgressIPs
+	opts.EgressSelection = requestOptions.EgressSelection
+	opts.EgressSkipLocalCheck = requestOptions.EgressSkipLocalCheck
+	if err := json.Unmarshal(*request, &opts); err != nil {
+		return opts, err
+	}
+	_, err := nat.NewEgressPool(opts.EgressIPs, opts.EgressSelection)
 	return opts, err
 }
 
 // MarshalJSON implements json.Marshaler interface to provide human readable configuration.
 func (o Options) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		Ports  string `json:"ports"`
-		Subnet string `json:"subnet"`
+		Ports           string   `json:"ports"`
+		Subnet          string   `json:"subnet"`
+		Subnet6         string   `json:"subnet6,omitempty"`
+		EgressIPs            []string `json:"egress_ips,omitempty"`
+		EgressSelection      string   `json:"egress_selection,omitempty"`
+		EgressSkipLocalCheck bool     `json:"egress_skip_local_check,omitempty"`
 	}{
-		Ports:  o.Ports.String(),
-		Subnet: o.Subnet.String(),
+		Ports:                o.Ports.String(),
+		Subnet:               o.Subnet.String(),
+		Subnet6:              subnetString(o.Subnet6),
+		EgressIPs:            o.EgressIPs,
+		EgressSelection:      o.EgressSelection,
+		EgressSkipLocalCheck: o.EgressSkipLocalCheck,
 	})
 }
 
 // UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
 func (o *Options) UnmarshalJSON(data []byte) error {
 	var options struct {
-		Ports  string `json:"ports"`
-		Subnet string `json:"subnet"`
+		Ports           string   `json:"ports"`
+		Subnet          string   `json:"subnet"`
+		Subnet6         string   `json:"subnet6"`
+		EgressIPs            []string `json:"egress_ips"`
+		EgressSelection      string   `json:"egress_selection"`
+		EgressSkipLocalCheck bool     `json:"egress_skip_local_check"`
 	}
 
 	if err := json.Unmarshal(data, &options); err != nil {
@@ -115,6 +164,44 @@ func (o *Options) UnmarshalJSON(data []byte) error {
 		}
 		o.Subnet = *ipnet
 	}
+	if len(options.Subnet6) > 0 {
+		ipnet, err := parseSubnet6(options.Subnet6)
+		if err != nil {
+			return err
+		}
+		o.Subnet6 = ipnet
+	}
+	if len(options.EgressIPs) > 0 {
+		o.EgressIPs = options.EgressIPs
+	}
+	if options.EgressSelection != "" {
+		o.EgressSelection = options.EgressSelection
+	}
+	if options.EgressSkipLocalCheck {
+		o.EgressSkipLocalCheck = true
+	}
 
 	return nil
 }
+
+// parseSubnet6 parses IPv6 subnet which is large enough to hand out a /64 network per session.
+func parseSubnet6(s string) (*net.IPNet, error) {
+	_, ipnet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+	if ipnet.IP.To4() != nil {
+		return nil, errors.New("IPv6 subnet expected: " + s)
+	}
+	if ones, _ := ipnet.Mask.Size(); ones > 56 {
+		return nil, errors.New("IPv6 subnet must be /56 or larger: " + s)
+	}
+	return ipnet, nil
+}
+
+func subnetString(ipnet *net.IPNet) string {
+	if ipnet == nil {
+		return ""
+	}
+	return ipnet.String()
+}
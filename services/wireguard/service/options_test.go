@@ -61,6 +61,40 @@ func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
 	}, options)
 }
 
+func Test_ParseJSONOptions_EgressIPs(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"egress_ips": ["1.1.1.1", "2.2.2.2"], "egress_selection": "sticky"}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.1.1.1", "2.2.2.2"}, options.(Options).EgressIPs)
+	assert.Equal(t, "sticky", options.(Options).EgressSelection)
+
+	request = json.RawMessage(`{"egress_ips": ["not-an-ip"]}`)
+	_, err = ParseJSONOptions(&request)
+	assert.Error(t, err)
+}
+
+func Test_ParseJSONOptions_Subnet6(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"subnet6": "fd6d:7973:7400::/48"}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	_, expected, _ := net.ParseCIDR("fd6d:7973:7400::/48")
+	assert.Equal(t, expected, options.(Options).Subnet6)
+
+	data, err := json.Marshal(options)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"subnet6":"fd6d:7973:7400::/48"`)
+
+	for _, subnet := range []string{"10.10.0.0/16", "fd6d:7973:7400::/64", "invalid"} {
+		request = json.RawMessage(`{"subnet6": "` + subnet + `"}`)
+		_, err = ParseJSONOptions(&request)
+		assert.Error(t, err, subnet)
+	}
+}
+
 func configureDefaults() {
 	ctx := emptyContext()
 	config.ParseFlagsServiceWireguard(ctx)
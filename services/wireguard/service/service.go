@@ -25,7 +25,24 @@ import (
 
 // GetProposal returns the proposal for wireguard service
 func GetProposal(location locationstate.Location) market.ServiceProposal {
-	marketLocation := market.Location{
+	return GetProposalWithOriginate(location, location, false)
+}
+
+// GetProposalWithOriginate returns the proposal for wireguard service whose traffic originates from another location,
+// portForwarding advertises that consumers may request forwarded ports.
+func GetProposalWithOriginate(location, originate locationstate.Location, portForwarding bool) market.ServiceProposal {
+	return market.ServiceProposal{
+		ServiceType: wg.ServiceType,
+		ServiceDefinition: wg.ServiceDefinition{
+			Location:          marketLocation(location),
+			LocationOriginate: marketLocation(originate),
+			PortForwarding:    portForwarding,
+		},
+	}
+}
+
+func marketLocation(location locationstate.Location) market.Location {
+	return market.Location{
 		Continent: location.Continent,
 		Country:   location.Country,
 		City:      location.City,
@@ -34,12 +51,4 @@ func GetProposal(location locationstate.Location) market.ServiceProposal {
 		ISP:      location.ISP,
 		NodeType: location.NodeType,
 	}
-
-	return market.ServiceProposal{
-		ServiceType: wg.ServiceType,
-		ServiceDefinition: wg.ServiceDefinition{
-			Location:          marketLocation,
-			LocationOriginate: marketLocation,
-		},
-	}
 }
@@ -25,12 +25,15 @@ import (
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/location/locationstate"
 	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 	"github.com/stretchr/testify/assert"
 )
@@ -58,6 +61,20 @@ func Test_GetProposal(t *testing.T) {
 	)
 }
 
+func Test_GetProposalWithOriginate(t *testing.T) {
+	proposal := GetProposalWithOriginate(locationstate.Location{Country: country}, locationstate.Location{Country: "DE"}, true)
+
+	assert.Equal(
+		t,
+		wg.ServiceDefinition{
+			Location:          market.Location{Country: country},
+			LocationOriginate: market.Location{Country: "DE"},
+			PortForwarding:    true,
+		},
+		proposal.ServiceDefinition,
+	)
+}
+
 func Test_Manager_Stop(t *testing.T) {
 	manager := newManagerStub(pubIP, outIP, country)
 	service := service.NewInstance(
@@ -84,12 +101,31 @@ func Test_Manager_Stop(t *testing.T) {
 func Test_Manager_ProviderConfig_FailsWhenSessionConfigIsInvalid(t *testing.T) {
 	manager := newManagerStub(pubIP, outIP, country)
 
-	params, err := manager.ProvideConfig("", nil, nil)
+	params, err := manager.ProvideConfig("", identity.Identity{}, nil, nil)
 
 	assert.Nil(t, params)
 	assert.Error(t, err)
 }
 
+func Test_Manager_UpdateOptions(t *testing.T) {
+	manager := newManagerStub(pubIP, outIP, country)
+	manager.options = DefaultOptions
+	manager.resourcesAllocator = resources.NewAllocator(port.NewPool(), DefaultOptions.Subnet, nil)
+
+	_, subnet, _ := net.ParseCIDR("10.100.0.0/16")
+	options := Options{Ports: port.UnspecifiedRange(), Subnet: *subnet}
+	assert.NoError(t, manager.UpdateOptions(options))
+	assert.Equal(t, options, manager.options)
+
+	network, err := manager.resourcesAllocator.AllocateIPNet()
+	assert.NoError(t, err)
+	assert.Equal(t, "10.100.0.0/24", network.String())
+
+	options.EgressIPs = []string{"invalid"}
+	assert.Error(t, manager.UpdateOptions(options))
+	assert.Nil(t, manager.options.EgressIPs)
+}
+
 // usually time.Sleep call gives a chance for other goroutines to kick in important when testing async code
 func waitABit() {
 	time.Sleep(10 * time.Millisecond)
@@ -101,6 +137,8 @@ func (mce *mockConnectionEndpoint) StartConsumerMode(config wgcfg.DeviceConfig)
 func (mce *mockConnectionEndpoint) StartProviderMode(ip string, config wgcfg.DeviceConfig) error {
 	return nil
 }
+func (mce *mockConnectionEndpoint) Rebind(_ int, _ *net.UDPAddr) error   { return nil }
+func (mce *mockConnectionEndpoint) Reroute(_ []string) error             { return nil }
 func (mce *mockConnectionEndpoint) InterfaceName() string                { return "mce0" }
 func (mce *mockConnectionEndpoint) Stop() error                          { return nil }
 func (mce *mockConnectionEndpoint) Config() (wg.ServiceConfig, error)    { return wg.ServiceConfig{}, nil }
@@ -113,9 +151,10 @@ func (mce *mockConnectionEndpoint) PeerStats() (*wgcfg.Stats, error) {
 
 func newManagerStub(pub, out, country string) *Manager {
 	return &Manager{
-		done:       make(chan struct{}),
-		ipResolver: ip.NewResolverMock("1.2.3.4"),
-		natService: &serviceFake{},
+		done:              make(chan struct{}),
+		ipResolver:        ip.NewResolverMock("1.2.3.4"),
+		natService:        &serviceFake{},
+		dnsHandlerFactory: dns.ResolveViaSystem,
 		connEndpointFactory: func() (wg.ConnectionEndpoint, error) {
 			return connectionEndpointStub, nil
 		},
@@ -127,6 +166,9 @@ type serviceFake struct{}
 func (service *serviceFake) Setup(nat.Options) (rules []interface{}, err error) {
 	return nil, nil
 }
+func (service *serviceFake) ForwardPorts([]nat.PortForward) ([]interface{}, error) {
+	return nil, nil
+}
 func (service *serviceFake) Del([]interface{}) error { return nil }
 func (service *serviceFake) Enable() error           { return nil }
 func (service *serviceFake) Disable() error          { return nil }
@@ -23,9 +23,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"reflect"
 	"sync"
 	"time"
 
+	"github.com/mysteriumnetwork/node/core/accounting"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
@@ -33,6 +35,7 @@ import (
 	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/nat"
 	natevent "github.com/mysteriumnetwork/node/nat/event"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
@@ -60,23 +63,30 @@ func NewManager(
 	options Options,
 	portSupplier port.ServicePortSupplier,
 	trafficFirewall firewall.IncomingTrafficFirewall,
+	accountingStorage *accounting.Storage,
+	dnsHandlerFactory dns.HandlerFactory,
 ) *Manager {
-	resourcesAllocator := resources.NewAllocator(portSupplier, options.Subnet)
+	resourcesAllocator := resources.NewAllocator(portSupplier, options.Subnet, options.Subnet6)
 
 	return &Manager{
 		done:               make(chan struct{}),
 		resourcesAllocator: resourcesAllocator,
+		portSupplier:       portSupplier,
 		ipResolver:         ipResolver,
 		natService:         natService,
 		natEventGetter:     natEventGetter,
 		eventBus:           eventBus,
 		trafficFirewall:    trafficFirewall,
+		accountingStorage:  accountingStorage,
+		dnsHandlerFactory:  dnsHandlerFactory,
+		options:            options,
 
 		connEndpointFactory: func() (wg.ConnectionEndpoint, error) {
 			return endpoint.NewConnectionEndpoint(resourcesAllocator)
 		},
 		country:        country,
 		sessionCleanup: map[string]func(){},
+		sessionConns:   map[string]wg.ConnectionEndpoint{},
 	}
 }
 
@@ -86,15 +96,20 @@ type Manager struct {
 	startStopMu sync.Mutex
 
 	resourcesAllocator *resources.Allocator
+	portSupplier       port.ServicePortSupplier
 
 	natService      nat.NATService
 	natEventGetter  NATEventGetter
 	eventBus        eventbus.EventBus
 	trafficFirewall firewall.IncomingTrafficFirewall
 
-	dnsOK    bool
-	dnsPort  int
-	dnsProxy *dns.Proxy
+	dnsOK             bool
+	dnsPort           int
+	dnsProxy          *dns.Proxy
+	dnsHandlerFactory dns.HandlerFactory
+
+	accountingStorage *accounting.Storage
+	trafficTracker    *accounting.Tracker
 
 	connEndpointFactory func() (wg.ConnectionEndpoint, error)
 
@@ -102,14 +117,19 @@ type Manager struct {
 
 	serviceInstance  *service.Instance
 	sessionCleanup   map[string]func()
+	sessionConns     map[string]wg.ConnectionEndpoint
 	sessionCleanupMu sync.Mutex
 
+	optionsMu  sync.RWMutex
+	options    Options
+	egressPool *nat.EgressPool
+
 	country    string
 	outboundIP string
 }
 
 // ProvideConfig provides the config for consumer and handles new WireGuard connection.
-func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage, remoteConn *net.UDPConn) (*service.ConfigParams, error) {
+func (m *Manager) ProvideConfig(sessionID string, consumerID identity.Identity, sessionConfig json.RawMessage, remoteConn *net.UDPConn) (*service.ConfigParams, error) {
 	log.Info().Msg("Accepting new WireGuard connection")
 	consumerConfig := wg.ConsumerConfig{}
 	err := json.Unmarshal(sessionConfig, &consumerConfig)
@@ -139,31 +159,58 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		return nil, errors.Wrap(err, "could not get peer config")
 	}
 
-	var dnsIP net.IP
-	var releaseTrafficFirewall firewall.IncomingRuleRemove
+	var dnsIP, dnsIP6 net.IP
+	var releaseTrafficFirewall []firewall.IncomingRuleRemove
 	if m.dnsOK {
 		if m.serviceInstance.Policies().HasDNSRules() {
-			releaseTrafficFirewall, err = m.trafficFirewall.BlockIncomingTraffic(providerConfig.Subnet)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to enable traffic blocking")
+			networks := []net.IPNet{providerConfig.Subnet}
+			if providerConfig.Subnet6 != nil {
+				networks = append(networks, *providerConfig.Subnet6)
+			}
+			for _, network := range networks {
+				release, err := m.trafficFirewall.BlockIncomingTraffic(network)
+				if err != nil {
+					releaseFirewall(releaseTrafficFirewall)
+					return nil, errors.Wrap(err, "failed to enable traffic blocking")
+				}
+				releaseTrafficFirewall = append(releaseTrafficFirewall, release)
 			}
 		}
 
 		dnsIP = netutil.FirstIP(config.Consumer.IPAddress)
 		config.Consumer.DNSIPs = dnsIP.String()
+		if config.Consumer.IPAddress6 != nil {
+			dnsIP6 = netutil.FirstIP(*config.Consumer.IPAddress6)
+			config.Consumer.DNSIPs += "," + dnsIP6.String()
+		}
+	}
+
+	m.optionsMu.RLock()
+	egressIP := m.egressPool.Select(consumerID.Address)
+	m.optionsMu.RUnlock()
+	providerExtIP := egressIP
+	if providerExtIP == nil {
+		providerExtIP = net.ParseIP(m.outboundIP)
 	}
 
 	natRules, err := m.natService.Setup(nat.Options{
 		VPNNetwork:        config.Consumer.IPAddress,
 		DNSIP:             dnsIP,
-		ProviderExtIP:     net.ParseIP(m.outboundIP),
+		ProviderExtIP:     providerExtIP,
 		EnableDNSRedirect: m.dnsOK,
 		DNSPort:           m.dnsPort,
+		TrafficAccounting: m.trafficTracker != nil,
+		VPNNetwork6:       config.Consumer.IPAddress6,
+		DNSIP6:            dnsIP6,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to setup NAT/firewall rules")
 	}
 
+	if m.trafficTracker != nil {
+		m.trafficTracker.StartSession(sessionID, wg.ServiceType, config.Consumer.IPAddress)
+	}
+
 	statsPublisher := newStatsPublisher(m.eventBus, time.Second)
 	go statsPublisher.start(sessionID, conn)
 
@@ -178,16 +225,17 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		log.Info().Msgf("Cleaning up session %s", sessionID)
 		m.sessionCleanupMu.Lock()
 		delete(m.sessionCleanup, sessionID)
+		delete(m.sessionConns, sessionID)
 		m.sessionCleanupMu.Unlock()
 
 		statsPublisher.stop()
 
 		s.Clear(ifaceName)
 
-		if releaseTrafficFirewall != nil {
-			if err := releaseTrafficFirewall(); err != nil {
-				log.Warn().Err(err).Msg("failed to disable traffic blocking")
-			}
+		releaseFirewall(releaseTrafficFirewall)
+
+		if m.trafficTracker != nil {
+			m.trafficTracker.EndSession(sessionID)
 		}
 
 		log.Trace().Msg("Deleting nat rules")
@@ -207,9 +255,41 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 
 	m.sessionCleanupMu.Lock()
 	m.sessionCleanup[sessionID] = destroy
+	m.sessionConns[sessionID] = conn
+	m.sessionCleanupMu.Unlock()
+
+	params := &service.ConfigParams{
+		SessionServiceConfig:   config,
+		SessionDestroyCallback: destroy,
+		ConsumerIP:             config.Consumer.IPAddress.IP,
+	}
+	if egressIP != nil {
+		params.EgressIP = egressIP.String()
+	}
+	return params, nil
+}
+
+// MigrateServiceConn moves session WireGuard device to the local port of the migrated p2p service conn.
+func (m *Manager) MigrateServiceConn(sessionID string, remoteConn *net.UDPConn) error {
+	m.sessionCleanupMu.Lock()
+	conn, ok := m.sessionConns[sessionID]
 	m.sessionCleanupMu.Unlock()
+	if !ok {
+		return fmt.Errorf("session %s has no wireguard connection", sessionID)
+	}
 
-	return &service.ConfigParams{SessionServiceConfig: config, SessionDestroyCallback: destroy}, nil
+	remoteConn.Close()
+	listenPort := remoteConn.LocalAddr().(*net.UDPAddr).Port
+	log.Info().Msgf("Migrating WireGuard session %s to port %d", sessionID, listenPort)
+	return conn.Rebind(listenPort, nil)
+}
+
+func releaseFirewall(removers []firewall.IncomingRuleRemove) {
+	for _, remove := range removers {
+		if err := remove(); err != nil {
+			log.Warn().Err(err).Msg("failed to disable traffic blocking")
+		}
+	}
 }
 
 func (m *Manager) createProviderConfig(listenPort int, peerPublicKey string) (wgcfg.DeviceConfig, error) {
@@ -226,6 +306,7 @@ func (m *Manager) createProviderConfig(listenPort int, peerPublicKey string) (wg
 	return wgcfg.DeviceConfig{
 		IfaceName:  "", // Interface name will be generated by connection endpoint.
 		Subnet:     network,
+		Subnet6:    m.resourcesAllocator.AllocateIPNet6(network),
 		PrivateKey: privateKey,
 		ListenPort: listenPort,
 		DNS:        nil,
@@ -251,13 +332,58 @@ func (m *Manager) startNewConnection(publicIP string, config wgcfg.DeviceConfig)
 	return connEndpoint, nil
 }
 
+// UpdateOptions applies changed port range, subnets and egress addresses to new sessions.
+func (m *Manager) UpdateOptions(options service.Options) error {
+	wgOptions, ok := options.(Options)
+	if !ok {
+		return errors.New("invalid wireguard options")
+	}
+
+	egressPool, err := nat.NewEgressPool(wgOptions.EgressIPs, wgOptions.EgressSelection)
+	if err != nil {
+		return errors.Wrap(err, "invalid egress addresses")
+	}
+	if err := checkEgressLocal(egressPool, wgOptions); err != nil {
+		return errors.Wrap(err, "invalid egress addresses")
+	}
+
+	m.optionsMu.Lock()
+	defer m.optionsMu.Unlock()
+
+	if !reflect.DeepEqual(wgOptions.Ports, m.options.Ports) {
+		m.portSupplier = NewPortPool(wgOptions.Ports)
+	}
+	m.resourcesAllocator.Reconfigure(m.portSupplier, wgOptions.Subnet, wgOptions.Subnet6)
+	m.options = wgOptions
+	m.egressPool = egressPool
+	return nil
+}
+
+// checkEgressLocal verifies that egress addresses are assigned to local interfaces unless it is disabled.
+func checkEgressLocal(egressPool *nat.EgressPool, options Options) error {
+	if options.EgressSkipLocalCheck {
+		return nil
+	}
+	return egressPool.CheckLocal()
+}
+
 // Serve starts service - does block
 func (m *Manager) Serve(instance *service.Instance) error {
 	log.Info().Msg("Wireguard: starting")
+	egressPool, err := nat.NewEgressPool(m.options.EgressIPs, m.options.EgressSelection)
+	if err != nil {
+		return errors.Wrap(err, "invalid egress addresses")
+	}
+	if err := checkEgressLocal(egressPool, m.options); err != nil {
+		return errors.Wrap(err, "invalid egress addresses")
+	}
+
 	m.startStopMu.Lock()
 	m.serviceInstance = instance
+	m.optionsMu.Lock()
+	m.egressPool = egressPool
+	m.optionsMu.Unlock()
 
-	var err error
 	m.outboundIP, err = m.ipResolver.GetOutboundIP()
 	if err != nil {
 		return errors.Wrap(err, "could not get outbound IP")
@@ -266,11 +392,20 @@ func (m *Manager) Serve(instance *service.Instance) error {
 	// Start DNS proxy.
 	m.dnsPort = 11253
 	m.dnsOK = false
-	dnsHandler, err := dns.ResolveViaSystem()
+	if m.accountingStorage != nil {
+		accountant, _ := m.natService.(nat.TrafficAccountant)
+		m.trafficTracker = accounting.NewTracker(m.accountingStorage, instance.Policies(), accountant)
+		m.trafficTracker.Start(time.Minute)
+	}
+
+	dnsHandler, err := m.dnsHandlerFactory()
 	if err == nil {
 		if m.serviceInstance.Policies().HasDNSRules() {
 			dnsHandler = dns.WhitelistAnswers(dnsHandler, m.trafficFirewall, instance.Policies())
 		}
+		if m.trafficTracker != nil {
+			dnsHandler = dns.AccountQueries(dnsHandler, m.trafficTracker)
+		}
 
 		m.dnsProxy = dns.NewProxy("", m.dnsPort, dnsHandler)
 		if err := m.dnsProxy.Run(); err != nil {
@@ -305,6 +440,10 @@ func (m *Manager) Stop() error {
 	}
 	cleanupWg.Wait()
 
+	if m.trafficTracker != nil {
+		m.trafficTracker.Stop()
+	}
+
 	// Stop DNS proxy.
 	if m.dnsProxy != nil {
 		if err := m.dnsProxy.Stop(); err != nil {
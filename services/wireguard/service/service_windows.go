@@ -21,11 +21,14 @@ import (
 	"encoding/json"
 	"net"
 
+	"github.com/mysteriumnetwork/node/core/accounting"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/nat"
 	natevent "github.com/mysteriumnetwork/node/nat/event"
 	"github.com/pkg/errors"
@@ -46,6 +49,8 @@ func NewManager(
 	options Options,
 	portSupplier port.ServicePortSupplier,
 	trafficFirewall firewall.IncomingTrafficFirewall,
+	accountingStorage *accounting.Storage,
+	dnsHandlerFactory dns.HandlerFactory,
 ) *Manager {
 	return &Manager{}
 }
@@ -54,7 +59,7 @@ func NewManager(
 type Manager struct{}
 
 // ProvideConfig provides the config for consumer
-func (manager *Manager) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn) (*service.ConfigParams, error) {
+func (manager *Manager) ProvideConfig(_ string, _ identity.Identity, _ json.RawMessage, _ *net.UDPConn) (*service.ConfigParams, error) {
 	return nil, errors.New("not implemented")
 }
 
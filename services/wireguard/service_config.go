@@ -35,6 +35,9 @@ type ServiceDefinition struct {
 	// Approximate information on location where the actual tunnelled traffic will originate from.
 	// This is used by providers having their own means of setting tunnels to other remote exit points.
 	LocationOriginate market.Location `json:"location_originate"`
+
+	// PortForwarding is set when provider forwards its public ports to consumers on request.
+	PortForwarding bool `json:"port_forwarding,omitempty"`
 }
 
 // GetLocation returns geographic location of service definition provider
@@ -42,6 +45,11 @@ func (service ServiceDefinition) GetLocation() market.Location {
 	return service.Location
 }
 
+// SupportsPortForwarding returns true if provider forwards ports to consumers.
+func (service ServiceDefinition) SupportsPortForwarding() bool {
+	return service.PortForwarding
+}
+
 // ServiceConfig represent a Wireguard service provider configuration that will be passed to the consumer for establishing a connection.
 type ServiceConfig struct {
 	// LocalPort and RemotePort are needed for NAT hole punching only.
@@ -55,7 +63,9 @@ type ServiceConfig struct {
 	}
 	Consumer struct {
 		IPAddress net.IPNet
-		DNSIPs    string
+		// IPAddress6 is set when provider supports IPv6 inside the tunnel.
+		IPAddress6 *net.IPNet
+		DNSIPs     string
 	}
 }
 
@@ -74,8 +84,14 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 		Endpoint  string `json:"endpoint"`
 	}
 	type consumer struct {
-		IPAddress string `json:"ip_address"`
-		DNSIPs    string `json:"dns_ips"`
+		IPAddress  string `json:"ip_address"`
+		IPAddress6 string `json:"ip_address6,omitempty"`
+		DNSIPs     string `json:"dns_ips"`
+	}
+
+	var ipAddress6 string
+	if s.Consumer.IPAddress6 != nil {
+		ipAddress6 = s.Consumer.IPAddress6.String()
 	}
 
 	return json.Marshal(&struct {
@@ -93,8 +109,9 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 			Endpoint:  s.Provider.Endpoint.String(),
 		},
 		Consumer: consumer{
-			IPAddress: s.Consumer.IPAddress.String(),
-			DNSIPs:    s.Consumer.DNSIPs,
+			IPAddress:  s.Consumer.IPAddress.String(),
+			IPAddress6: ipAddress6,
+			DNSIPs:     s.Consumer.DNSIPs,
 		},
 	})
 }
@@ -106,8 +123,9 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 		Endpoint  string `json:"endpoint"`
 	}
 	type consumer struct {
-		IPAddress string `json:"ip_address"`
-		DNSIPs    string `json:"dns_ips"`
+		IPAddress  string `json:"ip_address"`
+		IPAddress6 string `json:"ip_address6,omitempty"`
+		DNSIPs     string `json:"dns_ips"`
 	}
 	var config struct {
 		LocalPort  int      `json:"local_port"`
@@ -130,6 +148,15 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	if config.Consumer.IPAddress6 != "" {
+		ip6, ipnet6, err := net.ParseCIDR(config.Consumer.IPAddress6)
+		if err != nil {
+			return err
+		}
+		ipnet6.IP = ip6
+		s.Consumer.IPAddress6 = ipnet6
+	}
+
 	s.Ports = config.Ports
 	s.LocalPort = config.LocalPort
 	s.RemotePort = config.RemotePort
@@ -130,8 +130,9 @@ func TestServiceConfig_MarshalJSON(t *testing.T) {
 			Endpoint:  *endpoint,
 		},
 		Consumer: struct {
-			IPAddress net.IPNet
-			DNSIPs    string
+			IPAddress  net.IPNet
+			IPAddress6 *net.IPNet
+			DNSIPs     string
 		}{
 			IPAddress: net.IPNet{
 				IP:   net.IPv4(127, 0, 0, 1),
@@ -164,8 +165,9 @@ func TestServiceConfig_UnmarshalJSON(t *testing.T) {
 			Endpoint:  *endpoint,
 		},
 		Consumer: struct {
-			IPAddress net.IPNet
-			DNSIPs    string
+			IPAddress  net.IPNet
+			IPAddress6 *net.IPNet
+			DNSIPs     string
 		}{
 			IPAddress: net.IPNet{
 				IP:   net.IPv4(127, 0, 0, 1),
@@ -181,3 +183,17 @@ func TestServiceConfig_UnmarshalJSON(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expecteConfig, actualConfig)
 }
+
+func TestServiceConfig_IPAddress6(t *testing.T) {
+	configJSON := `{"local_port":0,"remote_port":0,"ports":null,"provider":{"public_key":"wg1","endpoint":"127.0.0.1:51001"},"consumer":{"ip_address":"10.182.1.2/24","ip_address6":"fd6d:7973:7400:1::2/64","dns_ips":"10.182.1.1,fd6d:7973:7400:1::1"}}`
+
+	var config ServiceConfig
+	err := json.Unmarshal([]byte(configJSON), &config)
+	assert.NoError(t, err)
+	assert.Equal(t, "fd6d:7973:7400:1::2/64", config.Consumer.IPAddress6.String())
+	assert.Equal(t, net.ParseIP("fd6d:7973:7400:1::2"), config.Consumer.IPAddress6.IP)
+
+	configBytes, err := json.Marshal(config)
+	assert.NoError(t, err)
+	assert.JSONEq(t, configJSON, string(configBytes))
+}
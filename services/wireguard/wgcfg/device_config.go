@@ -38,11 +38,13 @@ type Stats struct {
 
 // DeviceConfig describes wireguard device configuration.
 type DeviceConfig struct {
-	IfaceName  string    `json:"iface_name"`
-	Subnet     net.IPNet `json:"subnet"`
-	PrivateKey string    `json:"private_key"`
-	ListenPort int       `json:"listen_port"`
-	DNS        []string  `json:"dns"`
+	IfaceName string    `json:"iface_name"`
+	Subnet    net.IPNet `json:"subnet"`
+	// Subnet6 is an optional IPv6 address of the device.
+	Subnet6    *net.IPNet `json:"subnet6"`
+	PrivateKey string     `json:"private_key"`
+	ListenPort int        `json:"listen_port"`
+	DNS        []string   `json:"dns"`
 	// Used only for unix.
 	DNSScriptDir string `json:"dns_script_dir"`
 
@@ -61,6 +63,7 @@ func (dc DeviceConfig) MarshalJSON() ([]byte, error) {
 	type deviceConfig struct {
 		IfaceName    string   `json:"iface_name"`
 		Subnet       string   `json:"subnet"`
+		Subnet6      string   `json:"subnet6,omitempty"`
 		PrivateKey   string   `json:"private_key"`
 		ListenPort   int      `json:"listen_port"`
 		DNS          []string `json:"dns"`
@@ -72,10 +75,15 @@ func (dc DeviceConfig) MarshalJSON() ([]byte, error) {
 	if dc.Peer.Endpoint != nil {
 		peerEndpoint = dc.Peer.Endpoint.String()
 	}
+	var subnet6 string
+	if dc.Subnet6 != nil {
+		subnet6 = dc.Subnet6.String()
+	}
 
 	return json.Marshal(&deviceConfig{
 		IfaceName:    dc.IfaceName,
 		Subnet:       dc.Subnet.String(),
+		Subnet6:      subnet6,
 		PrivateKey:   dc.PrivateKey,
 		ListenPort:   dc.ListenPort,
 		DNS:          dc.DNS,
@@ -101,6 +109,7 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 	type deviceConfig struct {
 		IfaceName    string   `json:"iface_name"`
 		Subnet       string   `json:"subnet"`
+		Subnet6      string   `json:"subnet6,omitempty"`
 		PrivateKey   string   `json:"private_key"`
 		ListenPort   int      `json:"listen_port"`
 		DNS          []string `json:"dns"`
@@ -119,6 +128,16 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("could not parse subnet: %w", err)
 	}
 
+	var subnet6 *net.IPNet
+	if cfg.Subnet6 != "" {
+		ip, ipnet, err := net.ParseCIDR(cfg.Subnet6)
+		if err != nil {
+			return fmt.Errorf("could not parse IPv6 subnet: %w", err)
+		}
+		ipnet.IP = ip
+		subnet6 = ipnet
+	}
+
 	var peerEndpoint *net.UDPAddr
 	if cfg.Peer.Endpoint != "" {
 		peerEndpoint, err = net.ResolveUDPAddr("udp", cfg.Peer.Endpoint)
@@ -130,6 +149,7 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 	dc.IfaceName = cfg.IfaceName
 	dc.Subnet = *ipnet
 	dc.Subnet.IP = ip
+	dc.Subnet6 = subnet6
 	dc.PrivateKey = cfg.PrivateKey
 	dc.ListenPort = cfg.ListenPort
 	dc.DNS = cfg.DNS
@@ -161,6 +181,42 @@ func (dc *DeviceConfig) Encode() string {
 	return res.String()
 }
 
+// EncodeEndpoint encodes listen port and peer endpoint update of already configured device.
+func (dc *DeviceConfig) EncodeEndpoint() string {
+	var res strings.Builder
+	keyBytes, err := base64.StdEncoding.DecodeString(dc.Peer.PublicKey)
+	if err != nil {
+		log.Err(err).Msg("Could not decode device public key. Will use empty config.")
+		return ""
+	}
+
+	res.WriteString(fmt.Sprintf("listen_port=%d\n", dc.ListenPort))
+	res.WriteString(fmt.Sprintf("public_key=%s\n", hex.EncodeToString(keyBytes)))
+	res.WriteString("update_only=true\n")
+	if dc.Peer.Endpoint != nil {
+		res.WriteString(fmt.Sprintf("endpoint=%s\n", dc.Peer.Endpoint.String()))
+	}
+	return res.String()
+}
+
+// EncodeAllowedIPs encodes replacement of peer allowed IPs of already configured device.
+func (dc *DeviceConfig) EncodeAllowedIPs() string {
+	var res strings.Builder
+	keyBytes, err := base64.StdEncoding.DecodeString(dc.Peer.PublicKey)
+	if err != nil {
+		log.Err(err).Msg("Could not decode device public key. Will use empty config.")
+		return ""
+	}
+
+	res.WriteString(fmt.Sprintf("public_key=%s\n", hex.EncodeToString(keyBytes)))
+	res.WriteString("update_only=true\n")
+	res.WriteString("replace_allowed_ips=true\n")
+	for _, ip := range dc.Peer.AllowedIPs {
+		res.WriteString(fmt.Sprintf("allowed_ip=%s\n", ip))
+	}
+	return res.String()
+}
+
 // Peer represents wireguard peer.
 type Peer struct {
 	PublicKey              string       `json:"public_key"`
@@ -73,6 +73,25 @@ persistent_keepalive_interval=0
 	}
 }
 
+func TestDeviceConfig_EncodeAllowedIPs(t *testing.T) {
+	config := DeviceConfig{
+		PrivateKey: "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+		ListenPort: 53511,
+		Peer: Peer{
+			PublicKey:  "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+			Endpoint:   endpoint(),
+			AllowedIPs: []string{"192.168.4.10/32", "::/1"},
+		},
+	}
+
+	assert.Equal(t, `public_key=0f2c702c9fbe8d53be6b3bacbbbacf127cdd81f9bed1f88e050d464db924dd04
+update_only=true
+replace_allowed_ips=true
+allowed_ip=192.168.4.10/32
+allowed_ip=::/1
+`, config.EncodeAllowedIPs())
+}
+
 func TestDeviceConfig_MarshalJSON(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -23,6 +23,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 )
@@ -59,6 +60,8 @@ const (
 	RemovedStatus Status = "RemovedStatus"
 	// AcknowledgedStatus indicates a session has been reported as a success from consumer side
 	AcknowledgedStatus Status = "AcknowledgedStatus"
+	// UpdatedStatus indicates session details were updated after the session was created
+	UpdatedStatus Status = "UpdatedStatus"
 )
 
 // AppEventSession represents the session change payload
@@ -81,4 +84,40 @@ type SessionContext struct {
 	ConsumerLocation market.Location
 	HermesID         common.Address
 	Proposal         market.ServiceProposal
+	EgressIP         string
+}
+
+// PublishSession publishes service session changes on the bus.
+func PublishSession(publisher eventbus.Publisher, e AppEventSession) {
+	eventbus.Handle(AppTopicSession).Publish(publisher, e)
+}
+
+// SubscribeSession subscribes fn to service session changes.
+func SubscribeSession(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventSession)) error {
+	return eventbus.Handle(AppTopicSession).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishDataTransferred publishes session data transfer statistics on the bus.
+func PublishDataTransferred(publisher eventbus.Publisher, e AppEventDataTransferred) {
+	eventbus.Handle(AppTopicDataTransferred).Publish(publisher, e)
+}
+
+// SubscribeDataTransferred subscribes fn to session data transfer statistics.
+func SubscribeDataTransferred(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventDataTransferred)) error {
+	return eventbus.Handle(AppTopicDataTransferred).Subscribe(subscriber, delivery, fn)
+}
+
+// UnsubscribeDataTransferred unsubscribes fn from session data transfer statistics.
+func UnsubscribeDataTransferred(subscriber eventbus.Subscriber, fn func(AppEventDataTransferred)) error {
+	return eventbus.Handle(AppTopicDataTransferred).Unsubscribe(subscriber, fn)
+}
+
+// PublishTokensEarned publishes session earnings on the bus.
+func PublishTokensEarned(publisher eventbus.Publisher, e AppEventTokensEarned) {
+	eventbus.Handle(AppTopicTokensEarned).Publish(publisher, e)
+}
+
+// SubscribeTokensEarned subscribes fn to session earnings.
+func SubscribeTokensEarned(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventTokensEarned)) error {
+	return eventbus.Handle(AppTopicTokensEarned).Subscribe(subscriber, delivery, fn)
 }
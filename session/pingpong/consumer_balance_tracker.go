@@ -103,19 +103,19 @@ var errBalanceNotOffchain = errors.New("balance is not offchain, can't use herme
 
 // Subscribe subscribes the consumer balance tracker to relevant events
 func (cbt *ConsumerBalanceTracker) Subscribe(bus eventbus.Subscriber) error {
-	err := bus.SubscribeAsync(registry.AppTopicIdentityRegistration, cbt.handleRegistrationEvent)
+	err := registry.SubscribeIdentityRegistration(bus, eventbus.DeliverAsync, cbt.handleRegistrationEvent)
 	if err != nil {
 		return err
 	}
-	err = bus.SubscribeAsync(string(nodevent.StatusStopped), cbt.handleStopEvent)
+	err = nodevent.SubscribeNode(bus, eventbus.DeliverAsync, cbt.handleStopEvent)
 	if err != nil {
 		return err
 	}
-	err = bus.SubscribeAsync(event.AppTopicGrandTotalChanged, cbt.handleGrandTotalChanged)
+	err = event.SubscribeGrandTotalChanged(bus, eventbus.DeliverAsync, cbt.handleGrandTotalChanged)
 	if err != nil {
 		return err
 	}
-	return bus.SubscribeAsync(identity.AppTopicIdentityUnlock, cbt.handleUnlockEvent)
+	return identity.SubscribeIdentityUnlock(bus, eventbus.DeliverAsync, cbt.handleUnlockEvent)
 }
 
 // GetBalance gets the current balance for given identity
@@ -131,7 +131,7 @@ func (cbt *ConsumerBalanceTracker) publishChangeEvent(id identity.Identity, befo
 		return
 	}
 
-	cbt.bus.Publish(event.AppTopicBalanceChanged, event.AppEventBalanceChanged{
+	event.PublishBalanceChanged(cbt.bus, event.AppEventBalanceChanged{
 		Identity: id,
 		Previous: before,
 		Current:  after,
@@ -224,7 +224,7 @@ func (cbt *ConsumerBalanceTracker) subscribeToExternalChannelTopup(chainID int64
 		cancel()
 	}()
 
-	cbt.bus.Subscribe(registry.AppTopicEthereumClientReconnected, func(interface{}) {
+	registry.SubscribeEthereumClientReconnected(cbt.bus, eventbus.DeliverSync, func() {
 		cancel()
 	})
 
@@ -513,7 +513,10 @@ func (cbt *ConsumerBalanceTracker) recoverGrandTotalPromised(chainID int64, iden
 	return cbt.consumerGrandTotalsStorage.Store(chainID, identity, hermes, data.LatestPromise.Amount)
 }
 
-func (cbt *ConsumerBalanceTracker) handleStopEvent() {
+func (cbt *ConsumerBalanceTracker) handleStopEvent(e nodevent.Payload) {
+	if e.Status != nodevent.StatusStopped {
+		return
+	}
 	cbt.once.Do(func() {
 		close(cbt.stop)
 	})
@@ -66,12 +66,12 @@ func TestConsumerBalanceTracker_Fresh_Registration(t *testing.T) {
 	err := cbt.Subscribe(bus)
 	assert.NoError(t, err)
 
-	bus.Publish(registry.AppTopicIdentityRegistration, registry.AppEventIdentityRegistration{
+	registry.PublishIdentityRegistration(bus, registry.AppEventIdentityRegistration{
 		ID:      id1,
 		Status:  registry.Registered,
 		ChainID: 1,
 	})
-	bus.Publish(registry.AppTopicIdentityRegistration, registry.AppEventIdentityRegistration{
+	registry.PublishIdentityRegistration(bus, registry.AppEventIdentityRegistration{
 		ID:      id2,
 		Status:  registry.RegistrationError,
 		ChainID: 1,
@@ -85,7 +85,7 @@ func TestConsumerBalanceTracker_Fresh_Registration(t *testing.T) {
 		return cbt.GetBalance(1, id2).Uint64() == 0
 	}, defaultWaitTime, defaultWaitInterval)
 
-	bus.Publish(identity.AppTopicIdentityUnlock, identity.AppEventIdentityUnlock{
+	identity.PublishIdentityUnlock(bus, identity.AppEventIdentityUnlock{
 		ChainID: 1,
 		ID:      id2,
 	})
@@ -95,7 +95,7 @@ func TestConsumerBalanceTracker_Fresh_Registration(t *testing.T) {
 	}, defaultWaitTime, defaultWaitInterval)
 
 	var promised = big.NewInt(100)
-	bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
+	event.PublishGrandTotalChanged(bus, event.AppEventGrandTotalChanged{
 		ChainID:    1,
 		ConsumerID: id1,
 		Current:    promised,
@@ -133,7 +133,7 @@ func TestConsumerBalanceTracker_Fast_Registration(t *testing.T) {
 		err := cbt.Subscribe(bus)
 		assert.NoError(t, err)
 
-		bus.Publish(registry.AppTopicIdentityRegistration, registry.AppEventIdentityRegistration{
+		registry.PublishIdentityRegistration(bus, registry.AppEventIdentityRegistration{
 			ID:      id1,
 			Status:  registry.InProgress,
 			ChainID: 1,
@@ -171,7 +171,7 @@ func TestConsumerBalanceTracker_Fast_Registration(t *testing.T) {
 		err := cbt.Subscribe(bus)
 		assert.NoError(t, err)
 
-		bus.Publish(registry.AppTopicIdentityRegistration, registry.AppEventIdentityRegistration{
+		registry.PublishIdentityRegistration(bus, registry.AppEventIdentityRegistration{
 			ID:      id1,
 			Status:  registry.InProgress,
 			ChainID: 1,
@@ -229,7 +229,7 @@ func TestConsumerBalanceTracker_Handles_FreeRegistration(t *testing.T) {
 	err := cbt.Subscribe(bus)
 	assert.NoError(t, err)
 
-	bus.Publish(identity.AppTopicIdentityUnlock, identity.AppEventIdentityUnlock{
+	identity.PublishIdentityUnlock(bus, identity.AppEventIdentityUnlock{
 		ChainID: 1,
 		ID:      id1,
 	})
@@ -289,7 +289,7 @@ func TestConsumerBalanceTracker_Handles_GrandTotalChanges(t *testing.T) {
 
 	err := cbt.Subscribe(bus)
 	assert.NoError(t, err)
-	bus.Publish(identity.AppTopicIdentityUnlock, identity.AppEventIdentityUnlock{
+	identity.PublishIdentityUnlock(bus, identity.AppEventIdentityUnlock{
 		ChainID: 1,
 		ID:      id1,
 	})
@@ -298,7 +298,7 @@ func TestConsumerBalanceTracker_Handles_GrandTotalChanges(t *testing.T) {
 	}, defaultWaitTime, defaultWaitInterval)
 
 	var diff = big.NewInt(10)
-	bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
+	event.PublishGrandTotalChanged(bus, event.AppEventGrandTotalChanged{
 		ChainID:    1,
 		ConsumerID: id1,
 		Current:    new(big.Int).Add(grandTotalPromised, diff),
@@ -311,7 +311,7 @@ func TestConsumerBalanceTracker_Handles_GrandTotalChanges(t *testing.T) {
 	}, defaultWaitTime, defaultWaitInterval)
 
 	var diff2 = big.NewInt(20)
-	bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
+	event.PublishGrandTotalChanged(bus, event.AppEventGrandTotalChanged{
 		ChainID:    1,
 		ConsumerID: id1,
 		Current:    new(big.Int).Add(grandTotalPromised, diff2),
@@ -356,7 +356,7 @@ func TestConsumerBalanceTracker_FallsBackToTransactorIfInProgress(t *testing.T)
 
 	err := cbt.Subscribe(bus)
 	assert.NoError(t, err)
-	bus.Publish(identity.AppTopicIdentityUnlock, identity.AppEventIdentityUnlock{
+	identity.PublishIdentityUnlock(bus, identity.AppEventIdentityUnlock{
 		ChainID: 1,
 		ID:      id1,
 	})
@@ -393,7 +393,7 @@ func TestConsumerBalanceTracker_ForceUpdatesOnSuccessfulSubscription(t *testing.
 
 	err := cbt.Subscribe(bus)
 	assert.NoError(t, err)
-	bus.Publish(identity.AppTopicIdentityUnlock, identity.AppEventIdentityUnlock{
+	identity.PublishIdentityUnlock(bus, identity.AppEventIdentityUnlock{
 		ChainID: 1,
 		ID:      id1,
 	})
@@ -57,7 +57,7 @@ func (cts *ConsumerTotalsStorage) Store(chainID int64, id identity.Identity, her
 		return err
 	}
 
-	go cts.bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
+	go event.PublishGrandTotalChanged(cts.bus, event.AppEventGrandTotalChanged{
 		ChainID:    chainID,
 		Current:    amount,
 		HermesID:   hermesID,
@@ -21,6 +21,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
@@ -89,3 +90,63 @@ type AppEventGrandTotalChanged struct {
 	HermesID   common.Address
 	ConsumerID identity.Identity
 }
+
+// PublishHermesPromise publishes received hermes promises on the bus.
+func PublishHermesPromise(publisher eventbus.Publisher, e AppEventHermesPromise) {
+	eventbus.Handle(AppTopicHermesPromise).Publish(publisher, e)
+}
+
+// SubscribeHermesPromise subscribes fn to received hermes promises.
+func SubscribeHermesPromise(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventHermesPromise)) error {
+	return eventbus.Handle(AppTopicHermesPromise).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishBalanceChanged publishes consumer balance changes on the bus.
+func PublishBalanceChanged(publisher eventbus.Publisher, e AppEventBalanceChanged) {
+	eventbus.Handle(AppTopicBalanceChanged).Publish(publisher, e)
+}
+
+// SubscribeBalanceChanged subscribes fn to consumer balance changes.
+func SubscribeBalanceChanged(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventBalanceChanged)) error {
+	return eventbus.Handle(AppTopicBalanceChanged).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishEarningsChanged publishes provider earnings changes on the bus.
+func PublishEarningsChanged(publisher eventbus.Publisher, e AppEventEarningsChanged) {
+	eventbus.Handle(AppTopicEarningsChanged).Publish(publisher, e)
+}
+
+// SubscribeEarningsChanged subscribes fn to provider earnings changes.
+func SubscribeEarningsChanged(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventEarningsChanged)) error {
+	return eventbus.Handle(AppTopicEarningsChanged).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishInvoicePaid publishes paid invoices on the bus.
+func PublishInvoicePaid(publisher eventbus.Publisher, e AppEventInvoicePaid) {
+	eventbus.Handle(AppTopicInvoicePaid).Publish(publisher, e)
+}
+
+// SubscribeInvoicePaid subscribes fn to paid invoices.
+func SubscribeInvoicePaid(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventInvoicePaid)) error {
+	return eventbus.Handle(AppTopicInvoicePaid).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishSettlementRequest publishes settlement requests on the bus.
+func PublishSettlementRequest(publisher eventbus.Publisher, e AppEventSettlementRequest) {
+	eventbus.Handle(AppTopicSettlementRequest).Publish(publisher, e)
+}
+
+// SubscribeSettlementRequest subscribes fn to settlement requests.
+func SubscribeSettlementRequest(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventSettlementRequest)) error {
+	return eventbus.Handle(AppTopicSettlementRequest).Subscribe(subscriber, delivery, fn)
+}
+
+// PublishGrandTotalChanged publishes consumer grand total changes on the bus.
+func PublishGrandTotalChanged(publisher eventbus.Publisher, e AppEventGrandTotalChanged) {
+	eventbus.Handle(AppTopicGrandTotalChanged).Publish(publisher, e)
+}
+
+// SubscribeGrandTotalChanged subscribes fn to consumer grand total changes.
+func SubscribeGrandTotalChanged(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(AppEventGrandTotalChanged)) error {
+	return eventbus.Handle(AppTopicGrandTotalChanged).Subscribe(subscriber, delivery, fn)
+}
@@ -88,6 +88,24 @@ type PaymentMethod struct {
 	Duration time.Duration `json:"duration"`
 	Bytes    uint64        `json:"bytes"`
 	Type     string        `json:"type"`
+	// PortForwardingPrice is the price of a single forwarded port per hour.
+	PortForwardingPrice *big.Int `json:"port_forwarding_price,omitempty"`
+}
+
+// WithPrices returns a copy of the payment method with the given prices, nil prices are left unchanged.
+func (pm PaymentMethod) WithPrices(pricePerGB, pricePerMinute, pricePortHour *big.Int) PaymentMethod {
+	updated := NewPaymentMethod(pricePerGB, pricePerMinute)
+	if pricePerGB == nil {
+		updated.Bytes = pm.Bytes
+	}
+	if pricePerMinute == nil {
+		updated.Duration = pm.Duration
+	}
+	updated.PortForwardingPrice = pm.PortForwardingPrice
+	if pricePortHour != nil {
+		updated.PortForwardingPrice = pricePortHour
+	}
+	return updated
 }
 
 // GetPrice returns the payment methods price
@@ -100,6 +118,11 @@ func (pm PaymentMethod) GetType() string {
 	return pm.Type
 }
 
+// GetPortForwardingPrice returns the price of a single forwarded port per hour
+func (pm PaymentMethod) GetPortForwardingPrice() *big.Int {
+	return pm.PortForwardingPrice
+}
+
 // GetRate returns the payment rate for the method
 func (pm PaymentMethod) GetRate() market.PaymentRate {
 	return market.PaymentRate{PerByte: pm.Bytes, PerTime: pm.Duration}
@@ -115,11 +138,10 @@ func InvoiceFactoryCreator(
 	maxUnpaidInvoiceValue *big.Int,
 	hermesStatusChecker hermesStatusChecker,
 	eventBus eventbus.EventBus,
-	proposal market.ServiceProposal,
 	promiseHandler promiseHandler,
 	addressProvider addressProvider,
-) func(identity.Identity, identity.Identity, int64, common.Address, string, chan crypto.ExchangeMessage) (service.PaymentEngine, error) {
-	return func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage) (service.PaymentEngine, error) {
+) func(identity.Identity, identity.Identity, int64, common.Address, string, chan crypto.ExchangeMessage, market.ServiceProposal) (service.PaymentEngine, error) {
+	return func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage, proposal market.ServiceProposal) (service.PaymentEngine, error) {
 		timeTracker := session.NewTracker(mbtime.Now)
 		deps := InvoiceTrackerDeps{
 			Proposal:                   proposal,
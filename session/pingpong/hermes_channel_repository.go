@@ -161,11 +161,11 @@ func (hcr *HermesChannelRepository) sumChannels(chainID int64, id identity.Ident
 
 // Subscribe subscribes to the appropriate events.
 func (hcr *HermesChannelRepository) Subscribe(bus eventbus.Subscriber) error {
-	err := bus.SubscribeAsync(nodevent.AppTopicNode, hcr.handleNodeStart)
+	err := nodevent.SubscribeNode(bus, eventbus.DeliverAsync, hcr.handleNodeStart)
 	if err != nil {
 		return fmt.Errorf("could not subscribe to node status event: %w", err)
 	}
-	err = bus.SubscribeAsync(pinge.AppTopicHermesPromise, hcr.handleHermesPromiseReceived)
+	err = pinge.SubscribeHermesPromise(bus, eventbus.DeliverAsync, hcr.handleHermesPromiseReceived)
 	if err != nil {
 		return fmt.Errorf("could not subscribe to AppTopicHermesPromise event: %w", err)
 	}
@@ -281,7 +281,7 @@ func (hcr *HermesChannelRepository) updateChannel(chainID int64, new HermesChann
 	)
 
 	earningsNew := hcr.sumChannels(chainID, new.Identity)
-	go hcr.publisher.Publish(event.AppTopicEarningsChanged, event.AppEventEarningsChanged{
+	go event.PublishEarningsChanged(hcr.publisher, event.AppEventEarningsChanged{
 		Identity: new.Identity,
 		Previous: earningsOld,
 		Current:  earningsNew,
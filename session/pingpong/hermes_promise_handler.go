@@ -141,12 +141,12 @@ func (aph *HermesPromiseHandler) handleRequests() {
 
 // Subscribe subscribes HermesPromiseHandler to relevant events.
 func (aph *HermesPromiseHandler) Subscribe(bus eventbus.Subscriber) error {
-	err := bus.SubscribeAsync(event.AppTopicNode, aph.handleNodeStopEvents)
+	err := event.SubscribeNode(bus, eventbus.DeliverAsync, aph.handleNodeStopEvents)
 	if err != nil {
 		return fmt.Errorf("could not subscribe to node events: %w", err)
 	}
 
-	err = bus.SubscribeAsync(servicestate.AppTopicServiceStatus, aph.handleServiceEvent)
+	err = servicestate.SubscribeServiceStatus(bus, eventbus.DeliverAsync, aph.handleServiceEvent)
 	if err != nil {
 		return fmt.Errorf("could not subscribe to service events: %w", err)
 	}
@@ -246,12 +246,12 @@ func (aph *HermesPromiseHandler) requestPromise(er enqueuedRequest) {
 		return
 	}
 
-	aph.deps.EventBus.Publish(pinge.AppTopicHermesPromise, pinge.AppEventHermesPromise{
+	pinge.PublishHermesPromise(aph.deps.EventBus, pinge.AppEventHermesPromise{
 		Promise:    promise,
 		HermesID:   hermesID,
 		ProviderID: providerID,
 	})
-	aph.deps.EventBus.Publish(sessionEvent.AppTopicTokensEarned, sessionEvent.AppEventTokensEarned{
+	sessionEvent.PublishTokensEarned(aph.deps.EventBus, sessionEvent.AppEventTokensEarned{
 		ProviderID: providerID,
 		SessionID:  er.sessionID,
 		Total:      er.em.AgreementTotal,
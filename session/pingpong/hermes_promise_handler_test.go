@@ -49,10 +49,10 @@ func TestHermesPromiseHandler_RequestPromise(t *testing.T) {
 	}
 	err := aph.Subscribe(bus)
 	assert.NoError(t, err)
-	bus.Publish(servicestate.AppTopicServiceStatus, servicestate.AppEventServiceStatus{
+	servicestate.PublishServiceStatus(bus, servicestate.AppEventServiceStatus{
 		Status: string(servicestate.Running),
 	})
-	defer bus.Publish(event.AppTopicNode, event.Payload{
+	defer event.PublishNode(bus, event.Payload{
 		Status: event.StatusStopped,
 	})
 
@@ -90,10 +90,10 @@ func TestHermesPromiseHandler_RequestPromise_BubblesErrors(t *testing.T) {
 	}
 	err := aph.Subscribe(bus)
 	assert.NoError(t, err)
-	bus.Publish(servicestate.AppTopicServiceStatus, servicestate.AppEventServiceStatus{
+	servicestate.PublishServiceStatus(bus, servicestate.AppEventServiceStatus{
 		Status: string(servicestate.Running),
 	})
-	defer bus.Publish(event.AppTopicNode, event.Payload{
+	defer event.PublishNode(bus, event.Payload{
 		Status: event.StatusStopped,
 	})
 
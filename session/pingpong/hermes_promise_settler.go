@@ -167,27 +167,28 @@ func (aps *hermesPromiseSettler) loadInitialState(chainID int64, id identity.Ide
 
 // Subscribe subscribes the hermes promise settler to the appropriate events
 func (aps *hermesPromiseSettler) Subscribe(bus eventbus.Subscriber) error {
-	err := bus.SubscribeAsync(nodevent.AppTopicNode, aps.handleNodeEvent)
+	err := nodevent.SubscribeNode(bus, eventbus.DeliverAsync, aps.handleNodeEvent)
 	if err != nil {
 		return fmt.Errorf("could not subscribe to node status event: %w", err)
 	}
 
-	err = bus.SubscribeAsync(registry.AppTopicIdentityRegistration, aps.handleRegistrationEvent)
+	err = registry.SubscribeIdentityRegistration(bus, eventbus.DeliverAsync, aps.handleRegistrationEvent)
 	if err != nil {
 		return fmt.Errorf("could not subscribe to registration event: %w", err)
 	}
 
-	err = bus.SubscribeAsync(servicestate.AppTopicServiceStatus, aps.handleServiceEvent)
+	err = servicestate.SubscribeServiceStatus(bus, eventbus.DeliverAsync, aps.handleServiceEvent)
 	if err != nil {
 		return fmt.Errorf("could not subscribe to service status event: %w", err)
 	}
 
-	err = bus.SubscribeAsync(event.AppTopicSettlementRequest, aps.handleSettlementEvent)
+	// Settlement waits for the transaction to be mined, requests of different identities are settled concurrently.
+	err = event.SubscribeSettlementRequest(bus, eventbus.DeliverConcurrent, aps.handleSettlementEvent)
 	if err != nil {
 		return fmt.Errorf("could not subscribe to settlement event: %w", err)
 	}
 
-	err = bus.SubscribeAsync(event.AppTopicHermesPromise, aps.handleHermesPromiseReceived)
+	err = event.SubscribeHermesPromise(bus, eventbus.DeliverAsync, aps.handleHermesPromiseReceived)
 	if err != nil {
 		return fmt.Errorf("could not subscribe to hermes promise event: %w", err)
 	}
@@ -580,7 +581,7 @@ func (aps *hermesPromiseSettler) handleNodeStart() {
 		go func(address identity.Identity) {
 			err := aps.loadInitialState(aps.chainID(), address)
 			if err != nil {
-				log.Error().Err(err).Msgf("could not load initial state for %v", addr)
+				log.Error().Err(err).Msgf("could not load initial state for %v", address)
 			}
 		}(addr)
 	}
@@ -23,12 +23,10 @@ import (
 	"math/big"
 	"sync"
 
-	"github.com/asdine/storm/v3/codec/json"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/payments/crypto"
-	"go.etcd.io/bbolt"
 )
 
 const hermesPromiseBucketName = "hermes_promises"
@@ -39,11 +37,11 @@ var ErrAttemptToOverwrite = errors.New("attempted to overwrite a promise with an
 // HermesPromiseStorage allows for storing of hermes promises.
 type HermesPromiseStorage struct {
 	lock sync.Mutex
-	bolt *boltdb.Bolt
+	bolt storage.Storage
 }
 
 // NewHermesPromiseStorage returns a new instance of the hermes promise storage.
-func NewHermesPromiseStorage(bolt *boltdb.Bolt) *HermesPromiseStorage {
+func NewHermesPromiseStorage(bolt storage.Storage) *HermesPromiseStorage {
 	return &HermesPromiseStorage{
 		bolt: bolt,
 	}
@@ -120,40 +118,20 @@ func (aps *HermesPromiseStorage) List(filter HermesPromiseFilter) ([]HermesPromi
 	aps.lock.Lock()
 	defer aps.lock.Unlock()
 
+	var all []HermesPromise
+	if err := aps.bolt.GetAllValues(aps.getBucketName(filter.ChainID), &all); err != nil {
+		return nil, fmt.Errorf("could not list hermes promises: %w", err)
+	}
+
 	result := make([]HermesPromise, 0)
-	err := aps.bolt.DB().Bolt.View(func(tx *bbolt.Tx) error {
-		bucket := tx.Bucket([]byte(aps.getBucketName(filter.ChainID)))
-		if bucket == nil {
-			return nil
+	for _, entry := range all {
+		if filter.Identity != nil && *filter.Identity != entry.Identity {
+			continue
 		}
-
-		return bucket.ForEach(func(k, v []byte) error {
-			if string(k) == "__storm_metadata" {
-				return nil
-			}
-
-			var entry HermesPromise
-			if err := json.Codec.Unmarshal(v, &entry); err != nil {
-				return err
-			}
-
-			if filter.Identity != nil {
-				if *filter.Identity != entry.Identity {
-					return nil
-				}
-			}
-			if filter.HermesID != nil {
-				if *filter.HermesID != entry.HermesID {
-					return nil
-				}
-			}
-
-			result = append(result, entry)
-			return nil
-		})
-	})
-	if err != nil {
-		return nil, fmt.Errorf("could not list hermes promises: %w", err)
+		if filter.HermesID != nil && *filter.HermesID != entry.HermesID {
+			continue
+		}
+		result = append(result, entry)
 	}
 
 	return result, nil
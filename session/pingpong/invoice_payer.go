@@ -22,6 +22,7 @@ import (
 	"math/big"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -81,6 +82,7 @@ type InvoicePayer struct {
 
 	dataTransferred     DataTransferred
 	dataTransferredLock sync.Mutex
+	forwardedPorts      int32
 }
 
 type hashSigner interface {
@@ -131,7 +133,7 @@ func (ip *InvoicePayer) Start() error {
 
 	ip.deps.TimeTracker.StartTracking()
 
-	err = ip.deps.EventBus.Subscribe(connectionstate.AppTopicConnectionStatistics, ip.consumeDataTransferredEvent)
+	err = connectionstate.SubscribeConnectionStatistics(ip.deps.EventBus, eventbus.DeliverSync, ip.consumeDataTransferredEvent)
 	if err != nil {
 		return errors.Wrap(err, "could not subscribe to data transfer events")
 	}
@@ -181,7 +183,9 @@ func (ip *InvoicePayer) isInvoiceOK(invoice crypto.Invoice) error {
 	transferred := ip.getDataTransferred()
 	transferred.Up += ip.deps.DataLeeway.Bytes()
 
-	shouldBe := CalculatePaymentAmount(ip.deps.TimeTracker.Elapsed(), transferred, ip.deps.Proposal.PaymentMethod)
+	elapsed := ip.deps.TimeTracker.Elapsed()
+	shouldBe := CalculatePaymentAmount(elapsed, transferred, ip.deps.Proposal.PaymentMethod)
+	shouldBe.Add(shouldBe, CalculatePortForwardingAmount(elapsed, int(atomic.LoadInt32(&ip.forwardedPorts)), ip.deps.Proposal.PaymentMethod))
 	estimatedTolerance := estimateInvoiceTolerance(ip.deps.TimeTracker.Elapsed(), transferred)
 
 	upperBound, _ := new(big.Float).Mul(new(big.Float).SetInt(shouldBe), big.NewFloat(estimatedTolerance)).Int(nil)
@@ -265,7 +269,7 @@ func (ip *InvoicePayer) issueExchangeMessage(invoice crypto.Invoice) error {
 		log.Warn().Err(err).Msg("Failed to send exchange message")
 	}
 
-	ip.deps.EventBus.Publish(event.AppTopicInvoicePaid, event.AppEventInvoicePaid{
+	event.PublishInvoicePaid(ip.deps.EventBus, event.AppEventInvoicePaid{
 		ConsumerID: ip.deps.Identity,
 		SessionID:  ip.deps.SessionID,
 		Invoice:    invoice,
@@ -280,7 +284,7 @@ func (ip *InvoicePayer) issueExchangeMessage(invoice crypto.Invoice) error {
 func (ip *InvoicePayer) Stop() {
 	ip.once.Do(func() {
 		log.Debug().Msg("Stopping...")
-		_ = ip.deps.EventBus.Unsubscribe(connectionstate.AppTopicConnectionStatistics, ip.consumeDataTransferredEvent)
+		_ = connectionstate.UnsubscribeConnectionStatistics(ip.deps.EventBus, ip.consumeDataTransferredEvent)
 		close(ip.stop)
 	})
 }
@@ -319,6 +323,11 @@ func (ip *InvoicePayer) getDataTransferred() DataTransferred {
 	return ip.dataTransferred
 }
 
+// ChargeForwardedPorts accepts charges for ports the provider forwards to the consumer.
+func (ip *InvoicePayer) ChargeForwardedPorts(ports int) {
+	atomic.StoreInt32(&ip.forwardedPorts, int32(ports))
+}
+
 // SetSessionID updates invoice payer dependencies to set session ID once session established.
 func (ip *InvoicePayer) SetSessionID(sessionID string) {
 	ip.deps.SessionID = sessionID
@@ -579,9 +579,7 @@ func TestInvoicePayer_issueExchangeMessage_publishesEvents(t *testing.T) {
 
 	peerID := identity.FromAddress("0x01")
 
-	mp := &mockPublisher{
-		publicationChan: make(chan testEvent, 10),
-	}
+	mp := newMockPublisher()
 	emt := &InvoicePayer{
 		deps: InvoicePayerDeps{
 			PeerExchangeMessageSender: &MockPeerExchangeMessageSender{
@@ -791,7 +789,7 @@ type mockConsumerTotalsStorage struct {
 func (mcts *mockConsumerTotalsStorage) Store(chainID int64, id identity.Identity, hermesID common.Address, amount *big.Int) error {
 	mcts.calledWith = amount
 	if mcts.bus != nil {
-		go mcts.bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
+		go event.PublishGrandTotalChanged(mcts.bus, event.AppEventGrandTotalChanged{
 			ChainID:    chainID,
 			Current:    amount,
 			HermesID:   hermesID,
@@ -18,9 +18,12 @@
 package pingpong
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/payments/crypto"
@@ -81,12 +84,31 @@ func (pis *ProviderInvoiceStorage) GetR(providerID identity.Identity, agreementI
 type persistentStorage interface {
 	GetValue(bucket string, key interface{}, to interface{}) error
 	SetValue(bucket string, key interface{}, to interface{}) error
+	GetAllValues(bucket string, to interface{}) error
+	DeleteValues(bucket string, sample interface{}, remove func(value interface{}) bool) (int, error)
 }
 
 // InvoiceStorage allows to store promises.
 type InvoiceStorage struct {
 	bolt persistentStorage
 	lock sync.Mutex
+	now  func() time.Time
+}
+
+// storedR is the R of an agreement with the time it was stored at.
+// R stored by older versions is a plain string without the time.
+type storedR struct {
+	R      string    `json:"r"`
+	Stored time.Time `json:"stored"`
+}
+
+func decodeR(data json.RawMessage) (storedR, error) {
+	var r storedR
+	if err := json.Unmarshal(data, &r.R); err == nil {
+		return r, nil
+	}
+	err := json.Unmarshal(data, &r)
+	return r, err
 }
 
 var errBoltNotFound = "not found"
@@ -95,6 +117,7 @@ var errBoltNotFound = "not found"
 func NewInvoiceStorage(bolt persistentStorage) *InvoiceStorage {
 	return &InvoiceStorage{
 		bolt: bolt,
+		now:  time.Now,
 	}
 }
 
@@ -113,7 +136,7 @@ func (is *InvoiceStorage) getRKey(providerID identity.Identity, agreementID *big
 func (is *InvoiceStorage) StoreR(providerID identity.Identity, agreementID *big.Int, r string) error {
 	is.lock.Lock()
 	defer is.lock.Unlock()
-	err := is.bolt.SetValue(string(agreementRBucket), is.getRKey(providerID, agreementID), r)
+	err := is.bolt.SetValue(string(agreementRBucket), is.getRKey(providerID, agreementID), storedR{R: r, Stored: is.now().UTC()})
 	return errors.Wrap(err, "could not save R")
 }
 
@@ -121,16 +144,55 @@ func (is *InvoiceStorage) StoreR(providerID identity.Identity, agreementID *big.
 func (is *InvoiceStorage) GetR(providerID identity.Identity, agreementID *big.Int) (string, error) {
 	is.lock.Lock()
 	defer is.lock.Unlock()
-	var r string
-	err := is.bolt.GetValue(string(agreementRBucket), is.getRKey(providerID, agreementID), &r)
+	var data json.RawMessage
+	err := is.bolt.GetValue(string(agreementRBucket), is.getRKey(providerID, agreementID), &data)
 	if err != nil {
 		// wrap the error to an error we can check for
 		if err.Error() == errBoltNotFound {
 			return "", ErrNotFound
 		}
-		return r, errors.Wrap(err, "could not get r")
+		return "", errors.Wrap(err, "could not get r")
 	}
-	return r, nil
+	r, err := decodeR(data)
+	if err != nil {
+		return "", errors.Wrap(err, "could not get r")
+	}
+	return r.R, nil
+}
+
+// PruneR removes R of agreements stored before the given time or beyond the newest keep ones.
+// Zero values mean no limit, R stored by older versions without the time is removed by any limit.
+func (is *InvoiceStorage) PruneR(before time.Time, keep int) (int, error) {
+	is.lock.Lock()
+	defer is.lock.Unlock()
+
+	if keep > 0 {
+		var values []json.RawMessage
+		if err := is.bolt.GetAllValues(string(agreementRBucket), &values); err != nil {
+			return 0, errors.Wrap(err, "could not get r")
+		}
+		if len(values) > keep {
+			stored := make([]time.Time, 0, len(values))
+			for _, value := range values {
+				if r, err := decodeR(value); err == nil {
+					stored = append(stored, r.Stored)
+				}
+			}
+			sort.Slice(stored, func(i, j int) bool { return stored[i].After(stored[j]) })
+			if keep < len(stored) && stored[keep-1].After(before) {
+				before = stored[keep-1]
+			}
+		}
+	}
+	if before.IsZero() {
+		return 0, nil
+	}
+
+	pruned, err := is.bolt.DeleteValues(string(agreementRBucket), &json.RawMessage{}, func(value interface{}) bool {
+		r, err := decodeR(*value.(*json.RawMessage))
+		return err == nil && r.Stored.Before(before)
+	})
+	return pruned, errors.Wrap(err, "could not prune r")
 }
 
 // GetInvoice gets the corresponding invoice from storage.
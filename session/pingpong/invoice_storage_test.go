@@ -18,10 +18,12 @@
 package pingpong
 
 import (
+	"fmt"
 	"io/ioutil"
 	"math/big"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/identity"
@@ -138,3 +140,46 @@ func TestProviderInvoiceStorage(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, r2, r)
 }
+
+func TestInvoiceStorage_PruneR(t *testing.T) {
+	providerID := identity.FromAddress("0xprovider")
+	dir, err := ioutil.TempDir("", "providerInvoiceTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	storage := NewInvoiceStorage(bolt)
+	now := time.Date(2020, 10, 19, 12, 0, 0, 0, time.UTC)
+	storage.now = func() time.Time { return now }
+
+	// R stored by older versions has no time
+	assert.NoError(t, bolt.SetValue(string(agreementRBucket), storage.getRKey(providerID, big.NewInt(1)), "legacy r"))
+	r, err := storage.GetR(providerID, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, "legacy r", r)
+
+	for i := int64(2); i <= 5; i++ {
+		now = now.Add(time.Hour)
+		assert.NoError(t, storage.StoreR(providerID, big.NewInt(i), fmt.Sprintf("r%v", i)))
+	}
+
+	pruned, err := storage.PruneR(time.Date(2020, 10, 19, 14, 0, 0, 0, time.UTC), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pruned)
+	_, err = storage.GetR(providerID, big.NewInt(1))
+	assert.Equal(t, ErrNotFound, err)
+	_, err = storage.GetR(providerID, big.NewInt(2))
+	assert.Equal(t, ErrNotFound, err)
+
+	pruned, err = storage.PruneR(time.Time{}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+	_, err = storage.GetR(providerID, big.NewInt(3))
+	assert.Equal(t, ErrNotFound, err)
+	r, err = storage.GetR(providerID, big.NewInt(5))
+	assert.NoError(t, err)
+	assert.Equal(t, "r5", r)
+}
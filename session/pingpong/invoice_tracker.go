@@ -28,6 +28,7 @@ import (
 	"math/rand"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -128,6 +129,7 @@ type InvoiceTracker struct {
 
 	dataTransferred     DataTransferred
 	dataTransferredLock sync.Mutex
+	forwardedPorts      int32
 
 	criticalInvoiceErrors chan error
 	lastInvoiceSent       time.Duration
@@ -258,7 +260,7 @@ func (it *InvoiceTracker) handleExchangeMessage(em crypto.ExchangeMessage) error
 	it.resetNotSentExchangeMessageCount()
 
 	// incase of zero payment, we'll just skip going to the hermes
-	if isServiceFree(it.deps.Proposal.PaymentMethod) {
+	if isServiceFree(it.deps.Proposal.PaymentMethod) && !chargesPortForwarding(it.deps.Proposal.PaymentMethod, it.getForwardedPorts()) {
 		return nil
 	}
 
@@ -277,7 +279,7 @@ func (it *InvoiceTracker) Start() error {
 	log.Debug().Msg("Starting...")
 	it.deps.TimeTracker.StartTracking()
 
-	if err := it.deps.EventBus.SubscribeAsync(sessionEvent.AppTopicDataTransferred, it.consumeDataTransferredEvent); err != nil {
+	if err := sessionEvent.SubscribeDataTransferred(it.deps.EventBus, eventbus.DeliverAsync, it.consumeDataTransferredEvent); err != nil {
 		return err
 	}
 
@@ -351,7 +353,7 @@ func (it *InvoiceTracker) sendInvoicesWhenNeeded(interval time.Duration) {
 			return
 		case <-time.After(interval):
 			currentlyElapsed := it.deps.TimeTracker.Elapsed()
-			shouldBe := CalculatePaymentAmount(currentlyElapsed, it.getDataTransferred(), it.deps.Proposal.PaymentMethod)
+			shouldBe := it.calculatePaymentAmount(currentlyElapsed)
 			lastEM := it.getLastExchangeMessage()
 			diff := safeSub(shouldBe, lastEM.AgreementTotal)
 			if diff.Cmp(it.deps.MaxNotPaidInvoice) >= 0 && currentlyElapsed-it.lastInvoiceSent > it.invoiceDebounceRate {
@@ -459,7 +461,7 @@ func (it *InvoiceTracker) sendInvoice(isCritical bool) error {
 		return ErrExchangeWaitTimeout
 	}
 
-	shouldBe := CalculatePaymentAmount(it.deps.TimeTracker.Elapsed(), it.getDataTransferred(), it.deps.Proposal.PaymentMethod)
+	shouldBe := it.calculatePaymentAmount(it.deps.TimeTracker.Elapsed())
 
 	lastEm := it.getLastExchangeMessage()
 	if lastEm.AgreementTotal.Cmp(big.NewInt(0)) == 0 && shouldBe.Cmp(big.NewInt(0)) == 1 {
@@ -549,14 +551,11 @@ func (it *InvoiceTracker) handleHermesError(err error) error {
 		if err != nil {
 			return err
 		}
-		it.deps.EventBus.Publish(
-			event.AppTopicSettlementRequest,
-			event.AppEventSettlementRequest{
-				ChainID:    it.chainID(),
-				HermesID:   hermes,
-				ProviderID: it.deps.ProviderID,
-			},
-		)
+		event.PublishSettlementRequest(it.deps.EventBus, event.AppEventSettlementRequest{
+			ChainID:    it.chainID(),
+			HermesID:   hermes,
+			ProviderID: it.deps.ProviderID,
+		})
 		return err
 	default:
 		log.Err(err).Msgf("unknown hermes error encountered")
@@ -634,7 +633,7 @@ func (it *InvoiceTracker) validateExchangeMessage(em crypto.ExchangeMessage) err
 func (it *InvoiceTracker) Stop() {
 	it.once.Do(func() {
 		log.Debug().Msg("Stopping...")
-		_ = it.deps.EventBus.Unsubscribe(sessionEvent.AppTopicDataTransferred, it.consumeDataTransferredEvent)
+		_ = sessionEvent.UnsubscribeDataTransferred(it.deps.EventBus, it.consumeDataTransferredEvent)
 		close(it.stop)
 	})
 }
@@ -671,6 +670,20 @@ func (it *InvoiceTracker) updateDataTransfer(up, down uint64) {
 	}
 }
 
+// ChargeForwardedPorts starts charging for ports forwarded to the consumer.
+func (it *InvoiceTracker) ChargeForwardedPorts(ports int) {
+	atomic.StoreInt32(&it.forwardedPorts, int32(ports))
+}
+
+func (it *InvoiceTracker) getForwardedPorts() int {
+	return int(atomic.LoadInt32(&it.forwardedPorts))
+}
+
+func (it *InvoiceTracker) calculatePaymentAmount(elapsed time.Duration) *big.Int {
+	amount := CalculatePaymentAmount(elapsed, it.getDataTransferred(), it.deps.Proposal.PaymentMethod)
+	return amount.Add(amount, CalculatePortForwardingAmount(elapsed, it.getForwardedPorts(), it.deps.Proposal.PaymentMethod))
+}
+
 func (it *InvoiceTracker) getDataTransferred() DataTransferred {
 	it.dataTransferredLock.Lock()
 	defer it.dataTransferredLock.Unlock()
@@ -28,6 +28,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
@@ -807,27 +808,19 @@ type testEvent struct {
 }
 
 type mockPublisher struct {
+	eventbus.EventBus
 	publicationChan chan testEvent
 }
 
-func (mp *mockPublisher) Publish(topic string, payload interface{}) {
-	if mp.publicationChan != nil {
+func newMockPublisher() *mockPublisher {
+	mp := &mockPublisher{publicationChan: make(chan testEvent, 10)}
+	mp.EventBus = eventbus.Tap(func(topic string, payload interface{}) {
 		mp.publicationChan <- testEvent{
 			name:  topic,
 			value: payload,
 		}
-	}
-}
-
-func (mp *mockPublisher) Subscribe(topic string, fn interface{}) error {
-	return nil
-}
-func (mp *mockPublisher) SubscribeAsync(topic string, fn interface{}) error {
-	return nil
-}
-
-func (mp *mockPublisher) Unsubscribe(topic string, fn interface{}) error {
-	return nil
+	})
+	return mp
 }
 
 func TestInvoiceTracker_validateExchangeMessage(t *testing.T) {
@@ -41,6 +41,26 @@ func isServiceFree(method market.PaymentMethod) bool {
 	return false
 }
 
+func chargesPortForwarding(method market.PaymentMethod, ports int) bool {
+	pricer, ok := method.(market.PortForwardingPricer)
+	if !ok || ports == 0 {
+		return false
+	}
+	price := pricer.GetPortForwardingPrice()
+	return price != nil && price.Sign() > 0
+}
+
+// CalculatePortForwardingAmount calculates the payment amount for ports forwarded during the time passed.
+func CalculatePortForwardingAmount(timePassed time.Duration, ports int, method market.PaymentMethod) *big.Int {
+	if !chargesPortForwarding(method, ports) {
+		return new(big.Int)
+	}
+
+	amount := new(big.Int).Mul(method.(market.PortForwardingPricer).GetPortForwardingPrice(), big.NewInt(int64(ports)))
+	amount.Mul(amount, big.NewInt(int64(timePassed)))
+	return amount.Div(amount, big.NewInt(int64(time.Hour)))
+}
+
 // CalculatePaymentAmount calculates the required payment amount.
 func CalculatePaymentAmount(timePassed time.Duration, bytesTransferred DataTransferred, method market.PaymentMethod) *big.Int {
 	if isServiceFree(method) {
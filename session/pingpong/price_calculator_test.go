@@ -24,6 +24,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/money"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_isServiceFree(t *testing.T) {
@@ -179,3 +180,14 @@ func Test_CalculatePaymentAmount(t *testing.T) {
 		})
 	}
 }
+
+func Test_CalculatePortForwardingAmount(t *testing.T) {
+	method := NewPaymentMethod(big.NewInt(1000), big.NewInt(100))
+	assert.Equal(t, big.NewInt(0), CalculatePortForwardingAmount(time.Hour, 2, method))
+
+	method.PortForwardingPrice = big.NewInt(3000)
+	assert.Equal(t, big.NewInt(0), CalculatePortForwardingAmount(time.Hour, 0, method))
+	assert.Equal(t, big.NewInt(6000), CalculatePortForwardingAmount(time.Hour, 2, method))
+	assert.Equal(t, big.NewInt(1500), CalculatePortForwardingAmount(15*time.Minute, 2, method))
+	assert.Equal(t, big.NewInt(0), CalculatePortForwardingAmount(time.Hour, 2, &mockPaymentMethod{}))
+}
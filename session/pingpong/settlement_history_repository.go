@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+)
+
+const settlementHistoryBucket = "settlement-history"
+
+// SettlementHistoryRepository persists settlement history.
+type SettlementHistoryRepository interface {
+	// Store inserts the entry.
+	Store(entry SettlementHistoryEntry) error
+	// List returns entries matching the filter, most recent first.
+	List(filter SettlementHistoryFilter) ([]SettlementHistoryEntry, error)
+	// Prune removes entries settled before the given time and all but keep most recent entries in a single transaction.
+	Prune(before time.Time, keep int) (int, error)
+}
+
+// SettlementHistoryBoltRepository stores settlement history in boltdb.
+type SettlementHistoryBoltRepository struct {
+	storage *boltdb.Bolt
+}
+
+// NewSettlementHistoryBoltRepository creates settlement history repository stored in boltdb.
+func NewSettlementHistoryBoltRepository(storage *boltdb.Bolt) *SettlementHistoryBoltRepository {
+	return &SettlementHistoryBoltRepository{storage: storage}
+}
+
+// Store inserts the entry.
+func (br *SettlementHistoryBoltRepository) Store(entry SettlementHistoryEntry) error {
+	return br.storage.Store(settlementHistoryBucket, &entry)
+}
+
+// List returns entries matching the filter, most recent first.
+func (br *SettlementHistoryBoltRepository) List(filter SettlementHistoryFilter) (result []SettlementHistoryEntry, err error) {
+	err = br.storage.WithDB(func(db *storm.DB) error {
+		return db.From(settlementHistoryBucket).
+			Select(filter.toMatcher()).
+			OrderBy("Time").
+			Reverse().
+			Find(&result)
+	})
+	if errors.Is(err, storm.ErrNotFound) {
+		return []SettlementHistoryEntry{}, nil
+	}
+	return result, err
+}
+
+// Prune removes entries settled before the given time and all but keep most recent entries in a single transaction.
+func (br *SettlementHistoryBoltRepository) Prune(before time.Time, keep int) (removed int, err error) {
+	err = br.storage.WithDB(func(db *storm.DB) error {
+		tx, err := db.Begin(true)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		entries := tx.From(settlementHistoryBucket)
+		var expired []SettlementHistoryEntry
+		if !before.IsZero() {
+			var old []SettlementHistoryEntry
+			if err := entries.Select(q.Lt("Time", before)).Find(&old); err != nil && !errors.Is(err, storm.ErrNotFound) {
+				return err
+			}
+			expired = append(expired, old...)
+		}
+		if keep > 0 {
+			var exceeding []SettlementHistoryEntry
+			err := entries.Select().OrderBy("Time").Reverse().Skip(keep).Find(&exceeding)
+			if err != nil && !errors.Is(err, storm.ErrNotFound) {
+				return err
+			}
+			expired = append(expired, exceeding...)
+		}
+
+		deleted := make(map[string]bool, len(expired))
+		for i := range expired {
+			key := expired[i].TxHash.Hex()
+			if deleted[key] {
+				continue
+			}
+			if err := entries.DeleteStruct(&expired[i]); err != nil {
+				return err
+			}
+			deleted[key] = true
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		removed = len(deleted)
+		return nil
+	})
+	return removed, err
+}
+
+func (f SettlementHistoryFilter) toMatcher() q.Matcher {
+	where := make([]q.Matcher, 0)
+	if f.TimeFrom != nil {
+		where = append(where, q.Gte("Time", f.TimeFrom.UTC()))
+	}
+	if f.TimeTo != nil {
+		where = append(where, q.Lte("Time", f.TimeTo.UTC()))
+	}
+	if f.ProviderID != nil {
+		where = append(where, q.Eq("ProviderID", *f.ProviderID))
+	}
+	if f.HermesID != nil {
+		where = append(where, q.Eq("HermesID", *f.HermesID))
+	}
+	return q.And(where...)
+}
@@ -18,27 +18,23 @@
 package pingpong
 
 import (
-	"errors"
 	"math/big"
 	"time"
 
-	"github.com/asdine/storm/v3"
-	"github.com/asdine/storm/v3/q"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
 
 // SettlementHistoryStorage stores the settlement events for historical purposes.
 type SettlementHistoryStorage struct {
-	bolt *boltdb.Bolt
+	repository SettlementHistoryRepository
 }
 
 // NewSettlementHistoryStorage returns a new instance of the SettlementHistoryStorage.
-func NewSettlementHistoryStorage(bolt *boltdb.Bolt) *SettlementHistoryStorage {
+func NewSettlementHistoryStorage(repository SettlementHistoryRepository) *SettlementHistoryStorage {
 	return &SettlementHistoryStorage{
-		bolt: bolt,
+		repository: repository,
 	}
 }
 
@@ -56,11 +52,9 @@ type SettlementHistoryEntry struct {
 	Fees           *big.Int
 }
 
-const settlementHistoryBucket = "settlement-history"
-
 // Store stores a given settlement history entry.
 func (shs *SettlementHistoryStorage) Store(she SettlementHistoryEntry) error {
-	return shs.bolt.DB().From(settlementHistoryBucket).Save(&she)
+	return shs.repository.Store(she)
 }
 
 // SettlementHistoryFilter defines all flags for filtering in settlement history storage.
@@ -71,32 +65,13 @@ type SettlementHistoryFilter struct {
 	HermesID   *common.Address
 }
 
-// List retrieves stored entries.
+// List retrieves stored entries, most recent first.
 func (shs *SettlementHistoryStorage) List(filter SettlementHistoryFilter) (result []SettlementHistoryEntry, err error) {
-	where := make([]q.Matcher, 0)
-	if filter.TimeFrom != nil {
-		where = append(where, q.Gte("Time", filter.TimeFrom.UTC()))
-	}
-	if filter.TimeTo != nil {
-		where = append(where, q.Lte("Time", filter.TimeTo.UTC()))
-	}
-	if filter.ProviderID != nil {
-		where = append(where, q.Eq("ProviderID", filter.ProviderID))
-	}
-	if filter.HermesID != nil {
-		where = append(where, q.Eq("HermesID", filter.HermesID))
-	}
-
-	sq := shs.bolt.DB().
-		From(settlementHistoryBucket).
-		Select(q.And(where...)).
-		OrderBy("Time").
-		Reverse()
-
-	err = sq.Find(&result)
-	if errors.Is(err, storm.ErrNotFound) {
-		return []SettlementHistoryEntry{}, nil
-	}
+	return shs.repository.List(filter)
+}
 
-	return result, err
+// Prune removes entries settled before the given time and all but keep most recent entries.
+// Zero before or keep disables the corresponding limit.
+func (shs *SettlementHistoryStorage) Prune(before time.Time, keep int) (removed int, err error) {
+	return shs.repository.Prune(before.UTC(), keep)
 }
@@ -40,7 +40,7 @@ func TestSettlementHistoryStorage(t *testing.T) {
 	assert.NoError(t, err)
 	defer bolt.Close()
 
-	storage := NewSettlementHistoryStorage(bolt)
+	storage := NewSettlementHistoryStorage(NewSettlementHistoryBoltRepository(bolt))
 
 	hermesAddress := common.HexToAddress("0x3313189b9b945DD38E7bfB6167F9909451582eE5")
 	providerID := identity.FromAddress("0x79bb2a1c5E0075005F084a66A44D5e930A88eC86")
@@ -93,4 +93,36 @@ func TestSettlementHistoryStorage(t *testing.T) {
 		assert.Len(t, entries, 2)
 		assert.EqualValues(t, []SettlementHistoryEntry{entry2, entry1}, entries)
 	})
+
+	t.Run("Filters results", func(t *testing.T) {
+		from := time.Date(2020, 1, 1, 1, 30, 0, 0, time.UTC)
+		entries, err := storage.List(SettlementHistoryFilter{TimeFrom: &from, ProviderID: &providerID, HermesID: &hermesAddress})
+		assert.NoError(t, err)
+		assert.EqualValues(t, []SettlementHistoryEntry{entry2}, entries)
+
+		otherHermes := common.HexToAddress("0x1")
+		entries, err = storage.List(SettlementHistoryFilter{HermesID: &otherHermes})
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Prunes entries outside retention limits", func(t *testing.T) {
+		entry3 := entry2
+		entry3.TxHash = common.BigToHash(big.NewInt(3))
+		entry3.Time = time.Date(2020, 1, 1, 3, 0, 0, 0, time.UTC)
+		assert.NoError(t, storage.Store(entry3))
+
+		removed, err := storage.Prune(time.Date(2020, 1, 1, 1, 30, 0, 0, time.UTC), 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, removed)
+
+		removed, err = storage.Prune(time.Time{}, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, removed)
+
+		entries, err := storage.List(SettlementHistoryFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, entry3.TxHash, entries[0].TxHash)
+	})
 }
@@ -93,5 +93,15 @@ func (n *Notifier) handleSleepEvent(e Event) {
 
 // Subscribe subscribes to sleep notifications
 func (n *Notifier) Subscribe() {
-	n.eventBus.SubscribeAsync(AppTopicSleepNotification, n.handleSleepEvent)
+	SubscribeSleepNotification(n.eventBus, eventbus.DeliverAsync, n.handleSleepEvent)
+}
+
+// PublishSleepNotification publishes sleep notifications on the bus.
+func PublishSleepNotification(publisher eventbus.Publisher, e Event) {
+	eventbus.Handle(AppTopicSleepNotification).Publish(publisher, e)
+}
+
+// SubscribeSleepNotification subscribes fn to sleep notifications.
+func SubscribeSleepNotification(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(Event)) error {
+	return eventbus.Handle(AppTopicSleepNotification).Subscribe(subscriber, delivery, fn)
 }
@@ -38,7 +38,7 @@ func (n *Notifier) Start() {
 	for {
 		select {
 		case e := <-eventChannel:
-			n.eventBus.Publish(AppTopicSleepNotification, e)
+			PublishSleepNotification(n.eventBus, e)
 		case <-n.stop:
 			break
 		}
@@ -35,7 +35,7 @@ func (n *Notifier) Start() {
 	for {
 		select {
 		case <-watcher.Event():
-			n.eventBus.Publish(AppTopicSleepNotification, EventWakeup)
+			PublishSleepNotification(n.eventBus, EventWakeup)
 		case err := <-watcher.Error():
 			log.Error().Msgf("Log watcher error: %v\n", err)
 		case <-n.stop:
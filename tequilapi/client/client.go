@@ -22,6 +22,7 @@ import (
 	"math/big"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/pkg/errors"
 
@@ -117,6 +118,133 @@ func (client *Client) ImportIdentity(blob []byte, passphrase string, setDefault
 	return id, err
 }
 
+// CreateBackup returns node backup encrypted with the given passphrase.
+func (client *Client) CreateBackup(passphrase string) ([]byte, error) {
+	response, err := client.http.Post("backup", contract.BackupRequest{Passphrase: &passphrase})
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var res contract.BackupResponse
+	err = parseResponseJSON(response, &res)
+	return res.Data, err
+}
+
+// RestoreBackup restores node state from the given backup.
+func (client *Client) RestoreBackup(archive []byte, passphrase string, force bool) (contract.RestoreResponse, error) {
+	var res contract.RestoreResponse
+	response, err := client.http.Post("backup/restore", contract.RestoreRequest{
+		Data:       archive,
+		Passphrase: &passphrase,
+		Force:      force,
+	})
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// CompactStorage compacts node database.
+func (client *Client) CompactStorage() (contract.StorageCompactResponse, error) {
+	var res contract.StorageCompactResponse
+	response, err := client.http.Post("storage/compact", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// AuditLog returns audit log entries matching the given action and user, newest first.
+func (client *Client) AuditLog(action, user string, limit int) ([]contract.AuditEntryDTO, error) {
+	params := url.Values{}
+	if action != "" {
+		params.Add("action", action)
+	}
+	if user != "" {
+		params.Add("user", user)
+	}
+	if limit > 0 {
+		params.Add("limit", strconv.Itoa(limit))
+	}
+	response, err := client.http.Get("audit", params)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var res contract.AuditListResponse
+	err = parseResponseJSON(response, &res)
+	return res.Entries, err
+}
+
+// EventHistory returns recently published events of the given topics, oldest first.
+func (client *Client) EventHistory(topics []string, limit int) ([]contract.EventDTO, error) {
+	params := url.Values{}
+	for _, topic := range topics {
+		params.Add("topic", topic)
+	}
+	if limit > 0 {
+		params.Add("limit", strconv.Itoa(limit))
+	}
+	response, err := client.http.Get("events/history", params)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var res contract.EventHistoryResponse
+	err = parseResponseJSON(response, &res)
+	return res.Events, err
+}
+
+// ExportIdentity returns identity key encrypted with the new passphrase.
+func (client *Client) ExportIdentity(address, passphrase, newPassphrase string) ([]byte, error) {
+	response, err := client.http.Post("identities/"+address+"/export", contract.IdentityExportRequest{
+		CurrentPassphrase: &passphrase,
+		NewPassphrase:     &newPassphrase,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var export contract.IdentityExportResponse
+	err = parseResponseJSON(response, &export)
+	return export.Data, err
+}
+
+// DeleteIdentity deletes identity from the keystore.
+func (client *Client) DeleteIdentity(address, passphrase string) error {
+	response, err := client.http.Delete("identities/"+address, contract.IdentityDeleteRequest{Passphrase: &passphrase})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// ChangeIdentityPassphrase encrypts identity key with the new passphrase.
+func (client *Client) ChangeIdentityPassphrase(address, passphrase, newPassphrase string) error {
+	response, err := client.http.Put("identities/"+address+"/passphrase", contract.IdentityPassphraseChangeRequest{
+		CurrentPassphrase: &passphrase,
+		NewPassphrase:     &newPassphrase,
+	})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
 // GetIdentities returns a list of client identities
 func (client *Client) GetIdentities() (ids []contract.IdentityRefDTO, err error) {
 	response, err := client.http.Get("identities", url.Values{})
@@ -472,6 +600,18 @@ func (client *Client) ServiceStart(request contract.ServiceStartRequest) (servic
 	return service, err
 }
 
+// ServiceUpdate applies changes to the running service instance without restarting it.
+func (client *Client) ServiceUpdate(id string, request contract.ServiceUpdateRequest) (service contract.ServiceInfoDTO, err error) {
+	response, err := client.http.Put("services/"+id, request)
+	if err != nil {
+		return service, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &service)
+	return service, err
+}
+
 // ServiceStop stops the running service instance by the requested id.
 func (client *Client) ServiceStop(id string) error {
 	path := fmt.Sprintf("services/%s", id)
@@ -765,6 +905,32 @@ func (client *Client) UpdateTerms(obj contract.TermsRequest) error {
 	return nil
 }
 
+// ConfigSettings returns effective configuration values with their sources.
+func (client *Client) ConfigSettings() (contract.ConfigSettingsResponse, error) {
+	var res contract.ConfigSettingsResponse
+	response, err := client.http.Get("config/settings", nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// ApplyConfig replaces user configuration with the given TOML file content, dry run only reports changes.
+func (client *Client) ApplyConfig(content string, dryRun bool) (contract.ConfigApplyResponse, error) {
+	var res contract.ConfigApplyResponse
+	response, err := client.http.Post("config/apply", contract.ConfigApplyRequest{Config: content, DryRun: dryRun})
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
 // FetchConfig - fetches current config
 func (client *Client) FetchConfig() (map[string]interface{}, error) {
 	resp, err := client.http.Get("config", nil)
@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/mysteriumnetwork/node/core/audit"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// AuditQuery allows to filter audit log entries.
+// swagger:parameters auditList
+type AuditQuery struct {
+	// Filter the entries from this date. Formatted in RFC3339 e.g. 2020-07-01.
+	// in: query
+	DateFrom *strfmt.Date `json:"date_from"`
+
+	// Filter the entries until this date. Formatted in RFC3339 e.g. 2020-07-30.
+	// in: query
+	DateTo *strfmt.Date `json:"date_to"`
+
+	// Action to filter the entries by e.g. "config.set", a trailing dot matches a group e.g. "service.".
+	// in: query
+	Action string `json:"action"`
+
+	// Tequilapi user to filter the entries by, "local" for requests made without a token.
+	// in: query
+	User string `json:"user"`
+
+	// Maximum number of newest entries to return.
+	// in: query
+	Limit int `json:"limit"`
+}
+
+// Bind creates and validates query from API request.
+func (q *AuditQuery) Bind(request *http.Request) *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+
+	qs := request.URL.Query()
+	if qStr := qs.Get("date_from"); qStr != "" {
+		if qVal, err := parseDate(qStr); err != nil {
+			errs.ForField("date_from").Add(err)
+		} else {
+			q.DateFrom = qVal
+		}
+	}
+	if qStr := qs.Get("date_to"); qStr != "" {
+		if qVal, err := parseDate(qStr); err != nil {
+			errs.ForField("date_to").Add(err)
+		} else {
+			q.DateTo = qVal
+		}
+	}
+	if qStr := qs.Get("limit"); qStr != "" {
+		if qVal, err := parseInt(qStr); err != nil {
+			errs.ForField("limit").Add(err)
+		} else {
+			q.Limit = *qVal
+		}
+	}
+	q.Action = qs.Get("action")
+	q.User = qs.Get("user")
+
+	return errs
+}
+
+// ToFilter converts API query to audit log filter.
+func (q *AuditQuery) ToFilter() audit.Filter {
+	filter := audit.Filter{Action: q.Action, User: q.User, Limit: q.Limit}
+	if q.DateFrom != nil {
+		from := time.Time(*q.DateFrom).Truncate(24 * time.Hour)
+		filter.From = &from
+	}
+	if q.DateTo != nil {
+		to := time.Time(*q.DateTo).Truncate(24 * time.Hour).Add(24*time.Hour - time.Nanosecond)
+		filter.To = &to
+	}
+	return filter
+}
+
+// AuditEntryDTO represents a single audited action.
+// swagger:model AuditEntryDTO
+type AuditEntryDTO struct {
+	// example: 2020-10-01T12:00:00Z
+	Time string `json:"time"`
+	// example: myst
+	User string `json:"user"`
+	// example: config.set
+	Action string `json:"action"`
+	// example: payments.consumer.price-hour-max
+	Target string      `json:"target,omitempty"`
+	Old    interface{} `json:"old,omitempty"`
+	New    interface{} `json:"new,omitempty"`
+}
+
+// NewAuditListResponse maps audit entries to API response.
+func NewAuditListResponse(entries []audit.Entry) AuditListResponse {
+	res := AuditListResponse{Entries: make([]AuditEntryDTO, len(entries))}
+	for i, e := range entries {
+		res.Entries[i] = AuditEntryDTO{
+			Time:   e.Time.Format(time.RFC3339),
+			User:   e.User,
+			Action: e.Action,
+			Target: e.Target,
+			Old:    e.Old,
+			New:    e.New,
+		}
+	}
+	return res
+}
+
+// AuditListResponse lists audit log entries, newest first.
+// swagger:model AuditListResponseDTO
+type AuditListResponse struct {
+	Entries []AuditEntryDTO `json:"entries"`
+}
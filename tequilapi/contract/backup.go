@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// BackupRequest request used for creating node backup.
+// swagger:model BackupRequestDTO
+type BackupRequest struct {
+	Passphrase *string `json:"passphrase"`
+}
+
+// Validate validates fields in request
+func (r BackupRequest) Validate() *validation.FieldErrorMap {
+	errors := validation.NewErrorMap()
+	if r.Passphrase == nil || *r.Passphrase == "" {
+		errors.ForField("passphrase").Required()
+	}
+	return errors
+}
+
+// BackupResponse contains encrypted node backup.
+// swagger:model BackupResponseDTO
+type BackupResponse struct {
+	Data []byte `json:"data"`
+}
+
+// RestoreRequest request used for restoring node from backup.
+// swagger:model RestoreRequestDTO
+type RestoreRequest struct {
+	Data       []byte  `json:"data"`
+	Passphrase *string `json:"passphrase"`
+	// Restore even if node has identities which are not in the backup.
+	Force bool `json:"force"`
+}
+
+// Validate validates fields in request
+func (r RestoreRequest) Validate() *validation.FieldErrorMap {
+	errors := validation.NewErrorMap()
+	if len(r.Data) == 0 {
+		errors.ForField("data").Required()
+	}
+	if r.Passphrase == nil {
+		errors.ForField("passphrase").Required()
+	}
+	return errors
+}
+
+// RestoreResponse describes restored backup.
+// swagger:model RestoreResponseDTO
+type RestoreResponse struct {
+	Version     int      `json:"version"`
+	NodeVersion string   `json:"node_version"`
+	CreatedAt   string   `json:"created_at"`
+	Identities  []string `json:"identities"`
+	// Node must be restarted to use restored database and config.
+	RestartRequired bool `json:"restart_required"`
+}
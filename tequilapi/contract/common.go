@@ -72,6 +72,15 @@ func bindDate(ptr *strfmt.Date, str string) *validation.FieldError {
 	return nil
 }
 
+func parseDateTime(str string) (*strfmt.DateTime, *validation.FieldError) {
+	value, err := defaultFormats.Parse("date-time", str)
+	if err != nil {
+		return nil, &validation.FieldError{Code: "invalid", Message: err.Error()}
+	}
+
+	return value.(*strfmt.DateTime), nil
+}
+
 func parseDate(str string) (*strfmt.Date, *validation.FieldError) {
 	value, err := defaultFormats.Parse("date", str)
 	if err != nil {
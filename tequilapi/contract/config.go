@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import "github.com/mysteriumnetwork/node/config"
+
+// ConfigSettingDTO is an effective configuration value with the layer it comes from.
+// swagger:model ConfigSettingDTO
+type ConfigSettingDTO struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+	// example: user
+	Source string `json:"source"`
+}
+
+// NewConfigSettingDTO maps to API setting.
+func NewConfigSettingDTO(setting config.Setting) ConfigSettingDTO {
+	return ConfigSettingDTO{Key: setting.Key, Value: setting.Value, Source: setting.Source}
+}
+
+// ConfigSettingsResponse lists effective configuration values.
+// swagger:model ConfigSettingsResponseDTO
+type ConfigSettingsResponse struct {
+	Settings []ConfigSettingDTO `json:"settings"`
+}
+
+// ConfigApplyRequest request used to replace user configuration.
+// swagger:model ConfigApplyRequestDTO
+type ConfigApplyRequest struct {
+	// Configuration file content in TOML format.
+	Config string `json:"config"`
+	// Only report changes without applying them.
+	DryRun bool `json:"dry_run"`
+}
+
+// ConfigChangeDTO describes how applying configuration changes a setting.
+// swagger:model ConfigChangeDTO
+type ConfigChangeDTO struct {
+	Key     string           `json:"key"`
+	Current ConfigSettingDTO `json:"current"`
+	Next    ConfigSettingDTO `json:"next"`
+}
+
+// ConfigApplyResponse lists changes caused by applying configuration.
+// swagger:model ConfigApplyResponseDTO
+type ConfigApplyResponse struct {
+	Changes []ConfigChangeDTO `json:"changes"`
+	Applied bool              `json:"applied"`
+}
@@ -47,6 +47,14 @@ func NewConnectionInfoDTO(session connectionstate.Status) ConnectionInfoDTO {
 		proposalRes := NewProposalDTO(session.Proposal)
 		response.Proposal = &proposalRes
 	}
+	for _, port := range session.ForwardedPorts {
+		response.ForwardedPorts = append(response.ForwardedPorts, ForwardedPortDTO{
+			Protocol:     port.Protocol,
+			PublicIP:     port.PublicIP,
+			PublicPort:   port.PublicPort,
+			ConsumerPort: port.ConsumerPort,
+		})
+	}
 	return response
 }
 
@@ -67,6 +75,22 @@ type ConnectionInfoDTO struct {
 
 	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
 	SessionID string `json:"session_id,omitempty"`
+
+	// provider ports forwarded to the consumer
+	ForwardedPorts []ForwardedPortDTO `json:"forwarded_ports,omitempty"`
+}
+
+// ForwardedPortDTO describes provider's public port forwarded to the consumer.
+// swagger:model ForwardedPortDTO
+type ForwardedPortDTO struct {
+	// example: tcp
+	Protocol string `json:"protocol"`
+	// example: 1.2.3.4
+	PublicIP string `json:"public_ip"`
+	// example: 40001
+	PublicPort int `json:"public_port"`
+	// example: 40001
+	ConsumerPort int `json:"consumer_port"`
 }
 
 // NewConnectionDTO maps to API connection.
@@ -166,6 +190,21 @@ func (cr ConnectionCreateRequest) Validate() *validation.FieldErrorMap {
 	if len(cr.ProviderID) == 0 {
 		errs.ForField("provider_id").Required()
 	}
+	if err := cr.ConnectOptions.SplitTunnel.ToSplitTunnel().Validate(); err != nil {
+		errs.ForField("split_tunnel").Invalid(err.Error())
+	}
+	if cr.ConnectOptions.ProxyAddress != "" {
+		if err := connection.ValidateProxyAddress(cr.ConnectOptions.ProxyAddress); err != nil {
+			errs.ForField("proxy_address").Invalid(err.Error())
+		} else if cr.ConnectOptions.SplitTunnel.ToSplitTunnel().IsEnabled() {
+			errs.ForField("proxy_address").Invalid("split tunnelling is not supported in userspace mode")
+		}
+	}
+	if cr.ConnectOptions.PortForwarding.TCP < 0 || cr.ConnectOptions.PortForwarding.UDP < 0 {
+		errs.ForField("port_forwarding").Invalid("number of ports can not be negative")
+	} else if cr.ConnectOptions.ProxyAddress != "" && (cr.ConnectOptions.PortForwarding.TCP > 0 || cr.ConnectOptions.PortForwarding.UDP > 0) {
+		errs.ForField("port_forwarding").Invalid("port forwarding is not supported in userspace mode")
+	}
 	return errs
 }
 
@@ -193,4 +232,46 @@ type ConnectOptions struct {
 	// default: auto
 	// example: auto, provider, system, "1.1.1.1,8.8.8.8"
 	DNS connection.DNSOption `json:"dns"`
+	// split tunnel option selecting destinations routed through VPN, domains are resolved when connecting
+	// required: false
+	SplitTunnel SplitTunnelDTO `json:"split_tunnel"`
+	// local SOCKS5/HTTP proxy loopback address, when set the connection runs in userspace mode without OS network changes
+	// required: false
+	// example: 127.0.0.1:1080
+	ProxyAddress string `json:"proxy_address,omitempty"`
+	// number of provider ports to forward to the consumer, provider must allow port forwarding
+	// required: false
+	PortForwarding PortForwardingDTO `json:"port_forwarding"`
+}
+
+// PortForwardingDTO holds number of requested forwarded ports by protocol
+// swagger:model PortForwardingDTO
+type PortForwardingDTO struct {
+	// example: 1
+	TCP int `json:"tcp"`
+	// example: 0
+	UDP int `json:"udp"`
+}
+
+// SplitTunnelDTO holds destinations included in or excluded from VPN tunnel
+// swagger:model SplitTunnelDTO
+type SplitTunnelDTO struct {
+	// example: ["10.0.0.0/8"]
+	IncludeCIDRs []string `json:"include_cidrs,omitempty"`
+	// example: ["192.168.0.0/16"]
+	ExcludeCIDRs []string `json:"exclude_cidrs,omitempty"`
+	// example: ["example.com"]
+	IncludeDomains []string `json:"include_domains,omitempty"`
+	// example: ["example.org"]
+	ExcludeDomains []string `json:"exclude_domains,omitempty"`
+}
+
+// ToSplitTunnel converts DTO to connection split tunnel options.
+func (dto SplitTunnelDTO) ToSplitTunnel() connection.SplitTunnel {
+	return connection.SplitTunnel{
+		IncludeCIDRs:   dto.IncludeCIDRs,
+		ExcludeCIDRs:   dto.ExcludeCIDRs,
+		IncludeDomains: dto.IncludeDomains,
+		ExcludeDomains: dto.ExcludeDomains,
+	}
 }
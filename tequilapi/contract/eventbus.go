@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// NewEventBusStatsResponse maps event bus stats to API response.
+func NewEventBusStatsResponse(topics []eventbus.TopicStats) EventBusStatsResponse {
+	res := EventBusStatsResponse{Topics: make([]EventTopicDTO, len(topics))}
+	for i, t := range topics {
+		topic := EventTopicDTO{
+			Name:        t.Name,
+			Event:       t.Event,
+			Defined:     t.Defined,
+			Published:   t.Published,
+			Subscribers: make([]EventSubscriberDTO, len(t.Subscribers)),
+		}
+		for j, s := range t.Subscribers {
+			topic.Subscribers[j] = EventSubscriberDTO{
+				Handler:       s.Handler,
+				Async:         s.Async,
+				Overflow:      string(s.Overflow),
+				QueueSize:     s.QueueSize,
+				QueueDepth:    s.QueueDepth,
+				MaxQueueDepth: s.MaxQueueDepth,
+				Delivered:     s.Delivered,
+				Dropped:       s.Dropped,
+			}
+		}
+		res.Topics[i] = topic
+	}
+	return res
+}
+
+// EventBusStatsResponse lists event bus topics.
+// swagger:model EventBusStatsResponseDTO
+type EventBusStatsResponse struct {
+	Topics []EventTopicDTO `json:"topics"`
+}
+
+// EventTopicDTO describes an event bus topic and its subscribers.
+// swagger:model EventTopicDTO
+type EventTopicDTO struct {
+	// example: State
+	Name string `json:"name"`
+	// example: connectionstate.AppEventConnectionState
+	Event string `json:"event,omitempty"`
+	// false for topics used without a definition
+	Defined     bool                 `json:"defined"`
+	Published   uint64               `json:"published"`
+	Subscribers []EventSubscriberDTO `json:"subscribers"`
+}
+
+// EventSubscriberDTO describes delivery of events to a subscriber.
+// swagger:model EventSubscriberDTO
+type EventSubscriberDTO struct {
+	// example: github.com/mysteriumnetwork/node/core/state.(*Keeper).consumeConnectionStateEvent-fm
+	Handler string `json:"handler"`
+	Async   bool   `json:"async"`
+	// example: block
+	Overflow      string `json:"overflow,omitempty"`
+	QueueSize     int    `json:"queue_size,omitempty"`
+	QueueDepth    int    `json:"queue_depth"`
+	MaxQueueDepth int    `json:"max_queue_depth"`
+	Delivered     uint64 `json:"delivered"`
+	Dropped       uint64 `json:"dropped"`
+}
+
+// EventHistoryQuery allows to filter recorded events.
+// swagger:parameters eventHistory
+type EventHistoryQuery struct {
+	// Topics to return events of, repeated or comma separated e.g. "State,Traversal". All topics by default.
+	// in: query
+	Topic []string `json:"topic"`
+
+	// Return events published since this time. Formatted in RFC3339 e.g. 2020-07-01T12:00:00Z.
+	// in: query
+	Since *strfmt.DateTime `json:"since"`
+
+	// Maximum number of newest events to return.
+	// in: query
+	Limit int `json:"limit"`
+}
+
+// Bind creates and validates query from API request.
+func (q *EventHistoryQuery) Bind(request *http.Request) *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+
+	qs := request.URL.Query()
+	for _, qStr := range qs["topic"] {
+		for _, topic := range strings.Split(qStr, ",") {
+			if topic != "" {
+				q.Topic = append(q.Topic, topic)
+			}
+		}
+	}
+	if qStr := qs.Get("since"); qStr != "" {
+		if qVal, err := parseDateTime(qStr); err != nil {
+			errs.ForField("since").Add(err)
+		} else {
+			q.Since = qVal
+		}
+	}
+	if qStr := qs.Get("limit"); qStr != "" {
+		if qVal, err := parseInt(qStr); err != nil {
+			errs.ForField("limit").Add(err)
+		} else {
+			q.Limit = *qVal
+		}
+	}
+
+	return errs
+}
+
+// ToFilter converts API query to event history filter.
+func (q *EventHistoryQuery) ToFilter() eventbus.HistoryFilter {
+	filter := eventbus.HistoryFilter{Topics: q.Topic, Limit: q.Limit}
+	if q.Since != nil {
+		filter.Since = time.Time(*q.Since)
+	}
+	return filter
+}
+
+// NewEventHistoryResponse maps recorded events to API response.
+func NewEventHistoryResponse(events []eventbus.RecordedEvent) EventHistoryResponse {
+	res := EventHistoryResponse{Events: make([]EventDTO, len(events))}
+	for i, e := range events {
+		res.Events[i] = EventDTO{
+			Time:  e.Time.Format(time.RFC3339Nano),
+			Topic: e.Topic,
+			Event: e.Event,
+		}
+	}
+	return res
+}
+
+// EventHistoryResponse lists recorded events, oldest first.
+// swagger:model EventHistoryResponseDTO
+type EventHistoryResponse struct {
+	Events []EventDTO `json:"events"`
+}
+
+// EventDTO represents a recorded event.
+// swagger:model EventDTO
+type EventDTO struct {
+	// example: 2020-10-01T12:00:00.123Z
+	Time string `json:"time"`
+	// example: State
+	Topic string          `json:"topic"`
+	Event json.RawMessage `json:"event"`
+}
@@ -162,6 +162,65 @@ type IdentityBeneficiaryResponse struct {
 	Beneficiary string `json:"beneficiary"`
 }
 
+// IdentityExportRequest request used for identity export.
+// swagger:model IdentityExportRequestDTO
+type IdentityExportRequest struct {
+	CurrentPassphrase *string `json:"current_passphrase"`
+	NewPassphrase     *string `json:"new_passphrase"`
+}
+
+// Validate validates fields in request
+func (r IdentityExportRequest) Validate() *validation.FieldErrorMap {
+	errors := validation.NewErrorMap()
+	if r.CurrentPassphrase == nil {
+		errors.ForField("current_passphrase").Required()
+	}
+	if r.NewPassphrase == nil {
+		errors.ForField("new_passphrase").Required()
+	}
+	return errors
+}
+
+// IdentityExportResponse contains encrypted identity key which can be imported back.
+// swagger:model IdentityExportResponseDTO
+type IdentityExportResponse struct {
+	Data []byte `json:"data"`
+}
+
+// IdentityDeleteRequest request used for identity deletion.
+// swagger:model IdentityDeleteRequestDTO
+type IdentityDeleteRequest struct {
+	Passphrase *string `json:"passphrase"`
+}
+
+// Validate validates fields in request
+func (r IdentityDeleteRequest) Validate() *validation.FieldErrorMap {
+	errors := validation.NewErrorMap()
+	if r.Passphrase == nil {
+		errors.ForField("passphrase").Required()
+	}
+	return errors
+}
+
+// IdentityPassphraseChangeRequest request used for changing identity passphrase.
+// swagger:model IdentityPassphraseChangeRequestDTO
+type IdentityPassphraseChangeRequest struct {
+	CurrentPassphrase *string `json:"current_passphrase"`
+	NewPassphrase     *string `json:"new_passphrase"`
+}
+
+// Validate validates fields in request
+func (r IdentityPassphraseChangeRequest) Validate() *validation.FieldErrorMap {
+	errors := validation.NewErrorMap()
+	if r.CurrentPassphrase == nil {
+		errors.ForField("current_passphrase").Required()
+	}
+	if r.NewPassphrase == nil {
+		errors.ForField("new_passphrase").Required()
+	}
+	return errors
+}
+
 // IdentityImportRequest is received in identity import endpoint.
 //swagger:model IdentityImportRequest
 type IdentityImportRequest struct {
@@ -19,7 +19,10 @@ package contract
 
 import (
 	"fmt"
+	"math/big"
+	"time"
 
+	"github.com/mysteriumnetwork/node/core/discovery"
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/money"
@@ -42,7 +45,7 @@ func NewPaymentMethodDTO(m market.PaymentMethod) PaymentMethodDTO {
 	if m == nil {
 		return PaymentMethodDTO{}
 	}
-	return PaymentMethodDTO{
+	dto := PaymentMethodDTO{
 		Type:  m.GetType(),
 		Price: m.GetPrice(),
 		Rate: PaymentRateDTO{
@@ -50,6 +53,10 @@ func NewPaymentMethodDTO(m market.PaymentMethod) PaymentMethodDTO {
 			PerBytes:   m.GetRate().PerByte,
 		},
 	}
+	if pricer, ok := m.(market.PortForwardingPricer); ok {
+		dto.PortForwardingPrice = pricer.GetPortForwardingPrice()
+	}
+	return dto
 }
 
 // NewServiceDefinitionDTO maps to API service definition.
@@ -144,6 +151,8 @@ type PaymentMethodDTO struct {
 	Type  string         `json:"type"`
 	Price money.Money    `json:"price"`
 	Rate  PaymentRateDTO `json:"rate"`
+	// price of a single forwarded port per hour
+	PortForwardingPrice *big.Int `json:"port_forwarding_price,omitempty"`
 }
 
 // PaymentRateDTO holds payment frequencies.
@@ -190,3 +199,44 @@ type QualityMetricsDTO struct {
 	Quality          float64 `json:"quality"`
 	MonitoringFailed bool    `json:"monitoring_failed"`
 }
+
+// NewProposalHistoryResponse maps to API proposal change history.
+func NewProposalHistoryResponse(events []discovery.ProposalUpdatedEvent) ProposalHistoryResponse {
+	res := ProposalHistoryResponse{Updates: []ProposalUpdateDTO{}}
+	for _, e := range events {
+		changes := make([]ProposalChangeDTO, 0, len(e.Changes))
+		for _, c := range e.Changes {
+			changes = append(changes, ProposalChangeDTO{Field: c.Field, Old: c.Old, New: c.New})
+		}
+		res.Updates = append(res.Updates, ProposalUpdateDTO{
+			Proposal:   NewProposalDTO(e.Proposal),
+			PreviousID: e.PreviousID,
+			Changes:    changes,
+			ChangedAt:  e.ChangedAt,
+		})
+	}
+	return res
+}
+
+// ProposalHistoryResponse holds latest changes of a proposal, oldest first.
+// swagger:model ProposalHistoryResponse
+type ProposalHistoryResponse struct {
+	Updates []ProposalUpdateDTO `json:"updates"`
+}
+
+// ProposalUpdateDTO describes single re-announcement of a changed proposal.
+// swagger:model ProposalUpdateDTO
+type ProposalUpdateDTO struct {
+	Proposal   ProposalDTO         `json:"proposal"`
+	PreviousID int                 `json:"previous_id"`
+	Changes    []ProposalChangeDTO `json:"changes"`
+	ChangedAt  time.Time           `json:"changed_at"`
+}
+
+// ProposalChangeDTO describes single proposal field change.
+// swagger:model ProposalChangeDTO
+type ProposalChangeDTO struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
@@ -46,11 +46,28 @@ type ServiceStartRequest struct {
 	Options interface{} `json:"options"`
 }
 
+// ServiceUpdateRequest request used to change running service, omitted fields are left unchanged.
+// swagger:model ServiceUpdateRequestDTO
+type ServiceUpdateRequest struct {
+	// required: false
+	PaymentMethod *ServicePaymentMethod `json:"payment_method,omitempty"`
+
+	// required: false
+	AccessPolicies *ServiceAccessPolicies `json:"access_policies,omitempty"`
+
+	// service options. Changes which require restart of the service are rejected.
+	// required: false
+	// example: {"ports": "51820:51830"}
+	Options interface{} `json:"options,omitempty"`
+}
+
 // ServicePaymentMethod payment parameters for service start.
 // swagger:model ServicePaymentMethod
 type ServicePaymentMethod struct {
 	PriceGB     *big.Int `json:"price_gb"`
 	PriceMinute *big.Int `json:"price_minute"`
+	// price of a single port forwarded to the consumer per hour, port forwarding is free if not set
+	PricePortHour *big.Int `json:"price_port_hour,omitempty"`
 }
 
 // ServiceAccessPolicies represents the access controls for service start
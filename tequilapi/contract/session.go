@@ -258,6 +258,7 @@ func NewSessionDTO(se session.History) SessionDTO {
 		Tokens:          se.Tokens,
 		Status:          se.Status,
 		NodeType:        se.NodeType,
+		EgressIP:        se.EgressIP,
 	}
 }
 
@@ -309,4 +310,8 @@ type SessionDTO struct {
 
 	// example: residential
 	NodeType string `json:"node_type"`
+
+	// public address provided session traffic left from, empty if not known
+	// example: 1.2.3.4
+	EgressIP string `json:"egress_ip,omitempty"`
 }
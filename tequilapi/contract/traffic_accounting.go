@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/accounting"
+)
+
+// NewTrafficAccountingListResponse maps to API traffic accounting list.
+func NewTrafficAccountingListResponse(records []accounting.SessionTraffic) TrafficAccountingListResponse {
+	dtoArray := make([]TrafficAccountingDTO, len(records))
+	for i, record := range records {
+		dtoArray[i] = NewTrafficAccountingDTO(record)
+	}
+	return TrafficAccountingListResponse{Items: dtoArray}
+}
+
+// TrafficAccountingListResponse defines traffic accounting list representable as json.
+// swagger:model TrafficAccountingListResponse
+type TrafficAccountingListResponse struct {
+	Items []TrafficAccountingDTO `json:"items"`
+}
+
+// NewTrafficAccountingDTO maps to API session traffic.
+func NewTrafficAccountingDTO(record accounting.SessionTraffic) TrafficAccountingDTO {
+	traffic := make(map[string]TrafficCounterDTO, len(record.Traffic))
+	for class, counter := range record.Traffic {
+		traffic[class] = TrafficCounterDTO{BytesSent: counter.Up, BytesReceived: counter.Down}
+	}
+	return TrafficAccountingDTO{
+		SessionID:   record.SessionID,
+		ServiceType: record.ServiceType,
+		CreatedAt:   record.Started.Format(time.RFC3339),
+		UpdatedAt:   record.Updated.Format(time.RFC3339),
+		DNSQueries:  record.DNSQueries,
+		Traffic:     traffic,
+	}
+}
+
+// TrafficAccountingDTO represents aggregated traffic of a provided session.
+// swagger:model TrafficAccountingDTO
+type TrafficAccountingDTO struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id"`
+
+	// example: wireguard
+	ServiceType string `json:"service_type"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	CreatedAt string `json:"created_at"`
+
+	// example: 2019-06-06T11:24:43.910035Z
+	UpdatedAt string `json:"updated_at"`
+
+	// DNS queries count by access policy category
+	// example: {"mysterium": 12, "uncategorized": 40}
+	DNSQueries map[string]uint64 `json:"dns_queries"`
+
+	// Traffic by destination port class
+	Traffic map[string]TrafficCounterDTO `json:"traffic"`
+}
+
+// TrafficCounterDTO represents bytes sent and received by consumer.
+// swagger:model TrafficCounterDTO
+type TrafficCounterDTO struct {
+	// example: 1024
+	BytesSent uint64 `json:"bytes_sent"`
+
+	// example: 1024
+	BytesReceived uint64 `json:"bytes_received"`
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/mysteriumnetwork/node/core/audit"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type auditQuerier interface {
+	Query(filter audit.Filter) ([]audit.Entry, error)
+}
+
+type auditAPI struct {
+	log auditQuerier
+}
+
+// List returns audit log entries.
+// swagger:operation GET /audit Audit auditList
+// ---
+// summary: Returns audit log
+// description: Returns recorded config, service, identity, payout and settlement actions, newest first
+// responses:
+//   200:
+//     description: Audit log entries
+//     schema:
+//       "$ref": "#/definitions/AuditListResponseDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (api *auditAPI) List(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	query := contract.AuditQuery{}
+	if errs := query.Bind(req); errs.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errs)
+		return
+	}
+
+	entries, err := api.log.Query(query.ToFilter())
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewAuditListResponse(entries), resp)
+}
+
+// AddRoutesForAudit attaches audit log endpoints to router.
+func AddRoutesForAudit(router *httprouter.Router, log auditQuerier) {
+	api := &auditAPI{log: log}
+	router.GET("/audit", api.List)
+}
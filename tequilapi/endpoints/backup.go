@@ -0,0 +1,166 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/mysteriumnetwork/node/core/backup"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type nodeBackup interface {
+	Create(passphrase string) ([]byte, error)
+	Restore(archive []byte, passphrase string, force bool) (backup.Manifest, error)
+}
+
+type backupAPI struct {
+	backup nodeBackup
+}
+
+// Backup creates encrypted backup of the node state.
+// swagger:operation POST /backup Backup createBackup
+// ---
+// summary: Creates node backup
+// description: Returns archive of keystore, database, tequilapi password and config encrypted with the given passphrase
+// parameters:
+// - in: body
+//   name: body
+//   description: Passphrase used to encrypt the backup
+//   schema:
+//     $ref: "#/definitions/BackupRequestDTO"
+// responses:
+//   200:
+//     description: Encrypted backup
+//     schema:
+//       "$ref": "#/definitions/BackupResponseDTO"
+//   400:
+//     description: Body parsing error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (api *backupAPI) Backup(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	var req contract.BackupRequest
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	if errorMap := req.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	archive, err := api.backup.Create(*req.Passphrase)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	utils.WriteAsJSON(contract.BackupResponse{Data: archive}, resp)
+}
+
+// Restore restores node state from encrypted backup.
+// swagger:operation POST /backup/restore Backup restoreBackup
+// ---
+// summary: Restores node from backup
+// description: Restores node state from backup, node must be restarted afterwards
+// parameters:
+// - in: body
+//   name: body
+//   description: Backup and its passphrase
+//   schema:
+//     $ref: "#/definitions/RestoreRequestDTO"
+// responses:
+//   200:
+//     description: Backup restored
+//     schema:
+//       "$ref": "#/definitions/RestoreResponseDTO"
+//   400:
+//     description: Invalid backup
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   403:
+//     description: Wrong passphrase
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: Node has identities which are not in the backup
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (api *backupAPI) Restore(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	var req contract.RestoreRequest
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	if errorMap := req.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	manifest, err := api.backup.Restore(req.Data, *req.Passphrase, req.Force)
+	switch {
+	case errors.Is(err, backup.ErrWrongPassphrase):
+		utils.SendError(resp, err, http.StatusForbidden)
+		return
+	case errors.Is(err, backup.ErrIdentityConflict):
+		utils.SendError(resp, err, http.StatusConflict)
+		return
+	case errors.Is(err, backup.ErrInvalidArchive), errors.Is(err, backup.ErrUnsupportedVersion):
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	case err != nil:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.RestoreResponse{
+		Version:         manifest.Version,
+		NodeVersion:     manifest.NodeVersion,
+		CreatedAt:       manifest.CreatedAt.Format(time.RFC3339),
+		Identities:      manifest.Identities,
+		RestartRequired: true,
+	}, resp)
+}
+
+// AddRoutesForBackup attaches backup endpoints to router.
+func AddRoutesForBackup(router *httprouter.Router, backup nodeBackup) {
+	api := &backupAPI{backup: backup}
+	router.POST("/backup", api.Backup)
+	router.POST("/backup/restore", api.Restore)
+}
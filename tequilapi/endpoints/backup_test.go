@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/backup"
+)
+
+type nodeBackupFake struct {
+	restoreErr error
+	force      bool
+}
+
+func (nb *nodeBackupFake) Create(passphrase string) ([]byte, error) {
+	return []byte(passphrase), nil
+}
+
+func (nb *nodeBackupFake) Restore(archive []byte, passphrase string, force bool) (backup.Manifest, error) {
+	nb.force = force
+	return backup.Manifest{
+		Version:     1,
+		NodeVersion: "0.1.0",
+		CreatedAt:   time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+		Identities:  []string{"0xaaa"},
+	}, nb.restoreErr
+}
+
+func Test_Backup(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForBackup(router, &nodeBackupFake{})
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/backup", strings.NewReader(`{"passphrase": "secret"}`))
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"data": "c2VjcmV0"}`, resp.Body.String())
+}
+
+func Test_BackupRestore(t *testing.T) {
+	tests := map[string]struct {
+		restoreErr   error
+		expectedCode int
+	}{
+		"restored":       {expectedCode: http.StatusOK},
+		"conflict":       {restoreErr: fmt.Errorf("%w: 0xbbb", backup.ErrIdentityConflict), expectedCode: http.StatusConflict},
+		"bad passphrase": {restoreErr: backup.ErrWrongPassphrase, expectedCode: http.StatusForbidden},
+		"bad archive":    {restoreErr: backup.ErrInvalidArchive, expectedCode: http.StatusBadRequest},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := &nodeBackupFake{restoreErr: test.restoreErr}
+			router := httprouter.New()
+			AddRoutesForBackup(router, fake)
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/backup/restore", strings.NewReader(`{"data": "c2VjcmV0", "passphrase": "secret", "force": true}`))
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, test.expectedCode, resp.Code)
+			assert.True(t, fake.force)
+			if test.restoreErr == nil {
+				assert.JSONEq(t, `{
+					"version": 1,
+					"node_version": "0.1.0",
+					"created_at": "2020-10-01T00:00:00Z",
+					"identities": ["0xaaa"],
+					"restart_required": true
+				}`, resp.Body.String())
+			}
+		})
+	}
+}
@@ -18,23 +18,35 @@
 package endpoints
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"reflect"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/audit"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
 	"github.com/rs/zerolog/log"
 )
 
 type configProvider interface {
+	Get(key string) interface{}
 	GetConfig() map[string]interface{}
 	GetDefaultConfig() map[string]interface{}
 	GetUserConfig() map[string]interface{}
 	SetUser(key string, value interface{})
 	RemoveUser(key string)
 	SaveUserConfig() error
+	Settings() []config.Setting
+	DiffUserConfig(user map[string]interface{}) []config.Change
+	ReplaceUserConfig(user map[string]interface{}) error
+}
+
+type configParser interface {
+	Parse(data []byte) (map[string]interface{}, []config.Issue)
 }
 
 // swagger:model configPayload
@@ -43,12 +55,18 @@ type configPayload struct {
 	Data map[string]interface{} `json:"data"`
 }
 
+type auditRecorder interface {
+	Record(ctx context.Context, action, target string, old, new interface{})
+}
+
 type configAPI struct {
 	config configProvider
+	schema configParser
+	audit  auditRecorder
 }
 
-func newConfigAPI(config configProvider) *configAPI {
-	return &configAPI{config: config}
+func newConfigAPI(config configProvider, schema configParser, audit auditRecorder) *configAPI {
+	return &configAPI{config: config, schema: schema, audit: audit}
 }
 
 // GetConfig returns current configuration
@@ -135,6 +153,10 @@ func (api *configAPI) SetUserConfig(writer http.ResponseWriter, httpReq *http.Re
 		utils.SendError(writer, err, http.StatusBadRequest)
 		return
 	}
+	old := make(map[string]interface{}, len(req.Data))
+	for k := range req.Data {
+		old[k] = api.config.Get(k)
+	}
 	for k, v := range req.Data {
 		if isNil(v) {
 			log.Debug().Msgf("Clearing user config value: %q", v)
@@ -149,9 +171,100 @@ func (api *configAPI) SetUserConfig(writer http.ResponseWriter, httpReq *http.Re
 		utils.SendError(writer, err, http.StatusInternalServerError)
 		return
 	}
+	for k := range req.Data {
+		api.audit.Record(httpReq.Context(), audit.ActionConfigSet, k, old[k], api.config.Get(k))
+	}
 	api.GetUserConfig(writer, nil, nil)
 }
 
+// GetSettings returns effective configuration values with their sources
+// swagger:operation GET /config/settings Configuration getConfigSettings
+// ---
+// summary: Returns effective configuration values
+// description: Returns effective configuration values together with the layer (default, user or cli) each value comes from
+// responses:
+//   200:
+//     description: Effective configuration values
+//     schema:
+//       "$ref": "#/definitions/ConfigSettingsResponseDTO"
+func (api *configAPI) GetSettings(writer http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
+	res := contract.ConfigSettingsResponse{Settings: []contract.ConfigSettingDTO{}}
+	for _, setting := range api.config.Settings() {
+		res.Settings = append(res.Settings, contract.NewConfigSettingDTO(setting))
+	}
+	utils.WriteAsJSON(res, writer)
+}
+
+// ApplyConfig replaces user configuration
+// swagger:operation POST /config/apply Configuration applyConfig
+// ---
+// summary: Replaces user configuration
+// description: Validates the given configuration file and atomically replaces user configuration with it. Keys missing in the file fall back to defaults.
+// parameters:
+//   - in: body
+//     name: body
+//     description: configuration file
+//     schema:
+//       $ref: "#/definitions/ConfigApplyRequestDTO"
+// responses:
+//   200:
+//     description: Changes of effective configuration values
+//     schema:
+//       "$ref": "#/definitions/ConfigApplyResponseDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Configuration is invalid
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (api *configAPI) ApplyConfig(writer http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
+	var req contract.ConfigApplyRequest
+	if err := json.NewDecoder(httpReq.Body).Decode(&req); err != nil {
+		utils.SendError(writer, err, http.StatusBadRequest)
+		return
+	}
+
+	user, issues := api.schema.Parse([]byte(req.Config))
+	if len(issues) > 0 {
+		errorMap := validation.NewErrorMap()
+		for _, issue := range issues {
+			key := issue.Key
+			if key == "" {
+				key = "config"
+			}
+			errorMap.ForField(key).Invalid(issue.String())
+		}
+		utils.SendValidationErrorMessage(writer, errorMap)
+		return
+	}
+
+	res := contract.ConfigApplyResponse{Changes: []contract.ConfigChangeDTO{}}
+	for _, change := range api.config.DiffUserConfig(user) {
+		res.Changes = append(res.Changes, contract.ConfigChangeDTO{
+			Key:     change.Key,
+			Current: contract.NewConfigSettingDTO(change.Current),
+			Next:    contract.NewConfigSettingDTO(change.Next),
+		})
+	}
+	if !req.DryRun {
+		if err := api.config.ReplaceUserConfig(user); err != nil {
+			utils.SendError(writer, err, http.StatusInternalServerError)
+			return
+		}
+		for _, change := range res.Changes {
+			api.audit.Record(httpReq.Context(), audit.ActionConfigApply, change.Key, change.Current.Value, change.Next.Value)
+		}
+		res.Applied = true
+	}
+	utils.WriteAsJSON(res, writer)
+}
+
 func isNil(val interface{}) bool {
 	if val == nil {
 		return true
@@ -166,10 +279,19 @@ func isNil(val interface{}) bool {
 // AddRoutesForConfig registers /config endpoints in Tequilapi
 func AddRoutesForConfig(
 	router *httprouter.Router,
-) {
-	api := newConfigAPI(config.Current)
+	audit auditRecorder,
+) error {
+	schema, err := config.NodeSchema()
+	if err != nil {
+		return err
+	}
+
+	api := newConfigAPI(config.Current, schema, audit)
 	router.GET("/config", api.GetConfig)
 	router.GET("/config/default", api.GetDefaultConfig)
 	router.GET("/config/user", api.GetUserConfig)
 	router.POST("/config/user", api.SetUserConfig)
+	router.GET("/config/settings", api.GetSettings)
+	router.POST("/config/apply", api.ApplyConfig)
+	return nil
 }
@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/audit"
+)
+
+func Test_ConfigApply(t *testing.T) {
+	file, err := ioutil.TempFile("", "config")
+	require.NoError(t, err)
+	file.Close()
+	defer os.Remove(file.Name())
+
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadUserConfig(file.Name()))
+	cfg.SetDefault("openvpn.port", 1194)
+	auditLog := &mockAuditRecorder{}
+	api := newConfigAPI(cfg, config.NewSchema([]cli.Flag{&cli.IntFlag{Name: "openvpn.port"}}), auditLog)
+
+	router := httprouter.New()
+	router.GET("/config/settings", api.GetSettings)
+	router.POST("/config/apply", api.ApplyConfig)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/config/apply", strings.NewReader(`{"config": "[openvpn]\nport = \"x\"\nproto = \"udp\""}`))
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "validation_error",
+		"errors": {
+			"openvpn.port": [{"code": "invalid", "message": "line 2: openvpn.port: expected int, got x"}],
+			"openvpn.proto": [{"code": "invalid", "message": "line 3: openvpn.proto: unknown key"}]
+		}
+	}`, resp.Body.String())
+
+	expectedChange := `{
+		"changes": [{
+			"key": "openvpn.port",
+			"current": {"key": "openvpn.port", "value": 1194, "source": "default"},
+			"next": {"key": "openvpn.port", "value": 1195, "source": "user"}
+		}],
+		"applied": %s
+	}`
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/config/apply", strings.NewReader(`{"config": "[openvpn]\nport = 1195", "dry_run": true}`))
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, strings.Replace(expectedChange, "%s", "false", 1), resp.Body.String())
+	assert.Equal(t, 1194, cfg.GetInt("openvpn.port"))
+	assert.Empty(t, auditLog.entries)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/config/apply", strings.NewReader(`{"config": "[openvpn]\nport = 1195"}`))
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, strings.Replace(expectedChange, "%s", "true", 1), resp.Body.String())
+	assert.Equal(t, 1195, cfg.GetInt("openvpn.port"))
+	assert.Equal(t, []audit.Entry{
+		{User: audit.UserLocal, Action: audit.ActionConfigApply, Target: "openvpn.port", Old: 1194, New: int64(1195)},
+	}, auditLog.entries)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/config/settings", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"settings": [{"key": "openvpn.port", "value": 1195, "source": "user"}]}`, resp.Body.String())
+}
+
+func Test_ConfigSetUserIsAudited(t *testing.T) {
+	file, err := ioutil.TempFile("", "config")
+	require.NoError(t, err)
+	file.Close()
+	defer os.Remove(file.Name())
+
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadUserConfig(file.Name()))
+	cfg.SetDefault("openvpn.port", 1194)
+	auditLog := &mockAuditRecorder{}
+	api := newConfigAPI(cfg, config.NewSchema(nil), auditLog)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/config/user", strings.NewReader(`{"data": {"openvpn.port": 1195}}`))
+	req = req.WithContext(audit.WithUser(req.Context(), "myst"))
+	api.SetUserConfig(resp, req, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []audit.Entry{
+		{User: "myst", Action: audit.ActionConfigSet, Target: "openvpn.port", Old: 1194, New: float64(1195)},
+	}, auditLog.entries)
+}
+
+type mockAuditRecorder struct {
+	entries []audit.Entry
+}
+
+func (m *mockAuditRecorder) Record(ctx context.Context, action, target string, old, new interface{}) {
+	m.entries = append(m.entries, audit.Entry{User: audit.UserFrom(ctx), Action: action, Target: target, Old: old, New: new})
+}
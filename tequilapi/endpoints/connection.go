@@ -19,6 +19,7 @@ package endpoints
 
 import (
 	"encoding/json"
+	stdErr "errors"
 	"fmt"
 	"net/http"
 
@@ -121,7 +122,7 @@ func (ce *ConnectionEndpoint) Status(resp http.ResponseWriter, _ *http.Request,
 //     schema:
 //       "$ref": "#/definitions/ErrorMessageDTO"
 //   409:
-//     description: Conflict. Connection already exists
+//     description: Conflict. Connection already exists or proposal is outdated
 //     schema:
 //       "$ref": "#/definitions/ErrorMessageDTO"
 //   422:
@@ -146,7 +147,7 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 	cr, err := toConnectionRequest(req, hermes.Hex())
 	if err != nil {
 		utils.SendError(resp, err, http.StatusBadRequest)
-		ce.publisher.Publish(quality.AppTopicConnectionEvents, (&contract.ConnectionCreateRequest{}).Event(quality.StagePraseRequest, err.Error()))
+		quality.PublishConnectionEvents(ce.publisher, (&contract.ConnectionCreateRequest{}).Event(quality.StagePraseRequest, err.Error()))
 		return
 	}
 
@@ -154,7 +155,7 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 		if out, err := errorMap.MarshalJSON(); err != nil {
 			log.Error().Err(err).Msg("Failed to marshal error map")
 		} else {
-			ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageValidateRequest, string(out)))
+			quality.PublishConnectionEvents(ce.publisher, cr.Event(quality.StageValidateRequest, string(out)))
 		}
 
 		utils.SendValidationErrorMessage(resp, errorMap)
@@ -164,7 +165,7 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 	consumerID := identity.FromAddress(cr.ConsumerID)
 	status, err := ce.identityRegistry.GetRegistrationStatus(config.GetInt64(config.FlagChainID), consumerID)
 	if err != nil {
-		ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageRegistrationGetStatus, err.Error()))
+		quality.PublishConnectionEvents(ce.publisher, cr.Event(quality.StageRegistrationGetStatus, err.Error()))
 		log.Error().Err(err).Stack().Msg("could not check registration status")
 		utils.SendError(resp, err, http.StatusInternalServerError)
 		return
@@ -172,18 +173,18 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 
 	switch status {
 	case registry.Unregistered, registry.RegistrationError:
-		ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageRegistrationUnregistered, ""))
+		quality.PublishConnectionEvents(ce.publisher, cr.Event(quality.StageRegistrationUnregistered, ""))
 		log.Error().Msgf("identity %q is not registered, aborting...", cr.ConsumerID)
 		utils.SendErrorMessage(resp, fmt.Sprintf("identity %q is not registered. Please register the identity first", cr.ConsumerID), http.StatusExpectationFailed)
 		return
 	case registry.InProgress:
-		ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageRegistrationInProgress, ""))
+		quality.PublishConnectionEvents(ce.publisher, cr.Event(quality.StageRegistrationInProgress, ""))
 		log.Info().Msgf("identity %q registration is in progress, continuing...", cr.ConsumerID)
 	case registry.Registered:
-		ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageRegistrationRegistered, ""))
+		quality.PublishConnectionEvents(ce.publisher, cr.Event(quality.StageRegistrationRegistered, ""))
 		log.Info().Msgf("identity %q is registered, continuing...", cr.ConsumerID)
 	default:
-		ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageRegistrationUnknown, ""))
+		quality.PublishConnectionEvents(ce.publisher, cr.Event(quality.StageRegistrationUnknown, ""))
 		log.Error().Msgf("identity %q has unknown status, aborting...", cr.ConsumerID)
 		utils.SendErrorMessage(resp, fmt.Sprintf("identity %q has unknown status. aborting", cr.ConsumerID), http.StatusExpectationFailed)
 		return
@@ -195,13 +196,13 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 		ServiceType: cr.ServiceType,
 	})
 	if err != nil {
-		ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageGetProposal, err.Error()))
+		quality.PublishConnectionEvents(ce.publisher, cr.Event(quality.StageGetProposal, err.Error()))
 		utils.SendError(resp, err, http.StatusInternalServerError)
 		return
 	}
 
 	if proposal == nil {
-		ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageNoProposal, errNoProposal.Error()))
+		quality.PublishConnectionEvents(ce.publisher, cr.Event(quality.StageNoProposal, errNoProposal.Error()))
 		utils.SendError(resp, errNoProposal, http.StatusBadRequest)
 		return
 	}
@@ -211,20 +212,25 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 	if err != nil {
 		switch err {
 		case connection.ErrAlreadyExists:
-			ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageConnectionAlreadyExists, err.Error()))
+			quality.PublishConnectionEvents(ce.publisher, cr.Event(quality.StageConnectionAlreadyExists, err.Error()))
 			utils.SendError(resp, err, http.StatusConflict)
 		case connection.ErrConnectionCancelled:
-			ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageConnectionCanceled, err.Error()))
+			quality.PublishConnectionEvents(ce.publisher, cr.Event(quality.StageConnectionCanceled, err.Error()))
 			utils.SendError(resp, err, statusConnectCancelled)
 		default:
-			ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageConnectionUnknownError, err.Error()))
+			if stdErr.Is(err, connection.ErrStaleProposal) {
+				quality.PublishConnectionEvents(ce.publisher, cr.Event(quality.StageConnectionStaleProposal, err.Error()))
+				utils.SendError(resp, err, http.StatusConflict)
+				return
+			}
+			quality.PublishConnectionEvents(ce.publisher, cr.Event(quality.StageConnectionUnknownError, err.Error()))
 			log.Error().Err(err).Msg("Failed to connect")
 			utils.SendError(resp, err, http.StatusInternalServerError)
 		}
 		return
 	}
 
-	ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageConnectionOK, ""))
+	quality.PublishConnectionEvents(ce.publisher, cr.Event(quality.StageConnectionOK, ""))
 	resp.WriteHeader(http.StatusCreated)
 	ce.Status(resp, req, params)
 }
@@ -314,5 +320,11 @@ func getConnectOptions(cr *contract.ConnectionCreateRequest) connection.ConnectP
 	return connection.ConnectParams{
 		DisableKillSwitch: cr.ConnectOptions.DisableKillSwitch,
 		DNS:               dns,
+		SplitTunnel:       cr.ConnectOptions.SplitTunnel.ToSplitTunnel(),
+		ProxyAddress:      cr.ConnectOptions.ProxyAddress,
+		PortForwarding: connection.PortForwarding{
+			TCP: cr.ConnectOptions.PortForwarding.TCP,
+			UDP: cr.ConnectOptions.PortForwarding.UDP,
+		},
 	}
 }
@@ -220,6 +220,90 @@ func TestPutReturns422ErrorIfRequestBodyIsMissingFieldValues(t *testing.T) {
 		}`, resp.Body.String())
 }
 
+func TestPutReturns422ErrorIfSplitTunnelIsInvalid(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"connect_options": {"split_tunnel": {"exclude_cidrs": ["10.0.0.1"]}}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message" : "validation_error",
+			"errors" : {
+				"split_tunnel" : [ { "code" : "invalid" , "message" : "invalid CIDR \"10.0.0.1\"" } ]
+			}
+		}`, resp.Body.String())
+}
+
+func TestPutReturns422ErrorIfProxyAddressIsInvalid(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"connect_options": {"proxy_address": "localhost"}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message" : "validation_error",
+			"errors" : {
+				"proxy_address" : [ { "code" : "invalid" , "message" : "address localhost: missing port in address" } ]
+			}
+		}`, resp.Body.String())
+}
+
+func TestPutReturns422ErrorIfProxyAddressIsNotLoopback(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"connect_options": {"proxy_address": "0.0.0.0:1080"}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message" : "validation_error",
+			"errors" : {
+				"proxy_address" : [ { "code" : "invalid" , "message" : "proxy address host \"0.0.0.0\" is not a loopback address" } ]
+			}
+		}`, resp.Body.String())
+}
+
 func TestPutWithValidBodyCreatesConnection(t *testing.T) {
 	state := connectionstate.Status{
 		State:     connectionstate.Connected,
@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type eventBusInspector interface {
+	Stats() []eventbus.TopicStats
+}
+
+type eventHistory interface {
+	Events(filter eventbus.HistoryFilter) []eventbus.RecordedEvent
+}
+
+type eventBusAPI struct {
+	bus     eventBusInspector
+	history eventHistory
+}
+
+// Stats returns event bus topics with their subscribers.
+// swagger:operation GET /debug/eventbus Debug eventBusStats
+// ---
+// summary: Returns event bus topics
+// description: Returns event bus topics with subscribers, their queue depths and delivered and dropped event counts
+// responses:
+//   200:
+//     description: Event bus topics
+//     schema:
+//       "$ref": "#/definitions/EventBusStatsResponseDTO"
+func (api *eventBusAPI) Stats(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(contract.NewEventBusStatsResponse(api.bus.Stats()), resp)
+}
+
+// History returns recently published events.
+// swagger:operation GET /events/history Events eventHistory
+// ---
+// summary: Returns recent events
+// description: Returns recently published event bus events, oldest first, to trace what led to a failure
+// responses:
+//   200:
+//     description: Recorded events
+//     schema:
+//       "$ref": "#/definitions/EventHistoryResponseDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+func (api *eventBusAPI) History(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	query := contract.EventHistoryQuery{}
+	if errs := query.Bind(req); errs.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errs)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewEventHistoryResponse(api.history.Events(query.ToFilter())), resp)
+}
+
+// AddRoutesForEventHistory attaches event history endpoints to router.
+func AddRoutesForEventHistory(router *httprouter.Router, history eventHistory) {
+	api := &eventBusAPI{history: history}
+	router.GET("/events/history", api.History)
+}
+
+// AddRoutesForEventBus attaches event bus debug endpoints to router.
+func AddRoutesForEventBus(router *httprouter.Router, bus eventBusInspector) {
+	api := &eventBusAPI{bus: bus}
+	router.GET("/debug/eventbus", api.Stats)
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+func Test_EventHistory(t *testing.T) {
+	history, err := eventbus.NewHistory(10, filepath.Join(os.TempDir(), eventbus.HistoryFileName), false)
+	require.NoError(t, err)
+	history.Record("State", map[string]string{"state": "Connecting"})
+	history.Record("Traversal", map[string]bool{"successful": false})
+	history.Record("Node", map[string]string{"status": "Started"})
+
+	router := httprouter.New()
+	AddRoutesForEventHistory(router, history)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/events/history?topic=State,Traversal&limit=5", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var res struct {
+		Events []struct {
+			Topic string                 `json:"topic"`
+			Event map[string]interface{} `json:"event"`
+		} `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Len(t, res.Events, 2)
+	assert.Equal(t, "State", res.Events[0].Topic)
+	assert.Equal(t, "Connecting", res.Events[0].Event["state"])
+	assert.Equal(t, "Traversal", res.Events[1].Topic)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/events/history?since=yesterday", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
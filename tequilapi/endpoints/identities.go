@@ -55,6 +55,7 @@ type providerChannel interface {
 
 type identityMover interface {
 	Import(blob []byte, currPass, newPass string) (identity.Identity, error)
+	Export(address, currPass, newPass string) ([]byte, error)
 }
 
 type identitiesAPI struct {
@@ -500,6 +501,194 @@ func (endpoint *identitiesAPI) Import(w http.ResponseWriter, r *http.Request, pa
 	utils.WriteAsJSON(idDTO, w)
 }
 
+// swagger:operation POST /identities/{id}/export Identity exportIdentity
+// ---
+// summary: Exports identity
+// description: Returns identity key encrypted with the new passphrase, which can later be used to import it back.
+// parameters:
+// - in: path
+//   name: id
+//   description: Identity stored in keystore
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   description: Current passphrase of the identity and passphrase used to encrypt the exported key
+//   schema:
+//     $ref: "#/definitions/IdentityExportRequestDTO"
+// responses:
+//   200:
+//     description: Encrypted identity key
+//     schema:
+//       "$ref": "#/definitions/IdentityExportResponseDTO"
+//   400:
+//     description: Body parsing error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   403:
+//     description: Forbidden
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Identity not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+func (endpoint *identitiesAPI) Export(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id, err := endpoint.idm.GetIdentity(params.ByName("id"))
+	if err != nil {
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	}
+
+	var req contract.IdentityExportRequest
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	if errorMap := req.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	blob, err := endpoint.mover.Export(id.Address, *req.CurrentPassphrase, *req.NewPassphrase)
+	if err != nil {
+		utils.SendError(resp, fmt.Errorf("failed to export identity: %w", err), http.StatusForbidden)
+		return
+	}
+
+	utils.WriteAsJSON(contract.IdentityExportResponse{Data: blob}, resp)
+}
+
+// swagger:operation DELETE /identities/{id} Identity deleteIdentity
+// ---
+// summary: Deletes identity
+// description: Removes identity from keystore, identity used by a service or connection can't be deleted
+// parameters:
+// - in: path
+//   name: id
+//   description: Identity stored in keystore
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   description: Parameter in body (passphrase) required for deleting identity
+//   schema:
+//     $ref: "#/definitions/IdentityDeleteRequestDTO"
+// responses:
+//   202:
+//     description: Identity deleted
+//   400:
+//     description: Body parsing error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   403:
+//     description: Forbidden
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Identity not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: Identity is in use
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+func (endpoint *identitiesAPI) Delete(resp http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
+	id, err := endpoint.idm.GetIdentity(params.ByName("id"))
+	if err != nil {
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	}
+
+	var req contract.IdentityDeleteRequest
+	if err := json.NewDecoder(httpReq.Body).Decode(&req); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	if errorMap := req.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	if err := endpoint.idm.DeleteIdentity(id.Address, *req.Passphrase); err != nil {
+		if errors.Cause(err) == identity.ErrIdentityInUse {
+			utils.SendError(resp, err, http.StatusConflict)
+			return
+		}
+		utils.SendError(resp, err, http.StatusForbidden)
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// swagger:operation PUT /identities/{id}/passphrase Identity changeIdentityPassphrase
+// ---
+// summary: Changes identity passphrase
+// description: Encrypts identity stored in keystore with the new passphrase
+// parameters:
+// - in: path
+//   name: id
+//   description: Identity stored in keystore
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   description: Current and new passphrases of the identity
+//   schema:
+//     $ref: "#/definitions/IdentityPassphraseChangeRequestDTO"
+// responses:
+//   202:
+//     description: Passphrase changed
+//   400:
+//     description: Body parsing error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   403:
+//     description: Forbidden
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Identity not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+func (endpoint *identitiesAPI) ChangePassphrase(resp http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
+	id, err := endpoint.idm.GetIdentity(params.ByName("id"))
+	if err != nil {
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	}
+
+	var req contract.IdentityPassphraseChangeRequest
+	if err := json.NewDecoder(httpReq.Body).Decode(&req); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	if errorMap := req.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	if err := endpoint.idm.ChangePassphrase(id.Address, *req.CurrentPassphrase, *req.NewPassphrase); err != nil {
+		utils.SendError(resp, err, http.StatusForbidden)
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
 // AddRoutesForIdentities creates /identities endpoint on tequilapi service
 func AddRoutesForIdentities(
 	router *httprouter.Router,
@@ -538,7 +727,10 @@ func AddRoutesForIdentities(
 		}
 	})
 	router.GET("/identities/:id", idmEnd.Get)
+	router.DELETE("/identities/:id", idmEnd.Delete)
 	router.GET("/identities/:id/status", idmEnd.Get)
+	router.POST("/identities/:id/export", idmEnd.Export)
+	router.PUT("/identities/:id/passphrase", idmEnd.ChangePassphrase)
 	router.PUT("/identities/:id/unlock", idmEnd.Unlock)
 	router.GET("/identities/:id/registration", idmEnd.RegistrationStatus)
 	router.GET("/identities/:id/beneficiary", idmEnd.Beneficiary)
@@ -172,6 +172,86 @@ func TestUnlockFailure(t *testing.T) {
 	assert.Equal(t, int64(0), mockIdm.LastUnlockChainID)
 }
 
+func TestDeleteIdentity(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodDelete, identityUrl, bytes.NewBufferString(`{"passphrase": "mypassphrase"}`))
+	assert.NoError(t, err)
+	params := httprouter.Params{{Key: "id", Value: "0x000000000000000000000000000000000000000a"}}
+
+	endpoint := &identitiesAPI{idm: mockIdm}
+	endpoint.Delete(resp, req, params)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	_, err = mockIdm.GetIdentity("0x000000000000000000000000000000000000000a")
+	assert.Error(t, err)
+}
+
+func TestDeleteIdentityInUse(t *testing.T) {
+	address := "0x000000000000000000000000000000000000000a"
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	release, err := mockIdm.Use(address, "wireguard service")
+	assert.NoError(t, err)
+	defer release()
+
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodDelete, identityUrl, bytes.NewBufferString(`{"passphrase": "mypassphrase"}`))
+	assert.NoError(t, err)
+
+	endpoint := &identitiesAPI{idm: mockIdm}
+	endpoint.Delete(resp, req, httprouter.Params{{Key: "id", Value: address}})
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	_, err = mockIdm.GetIdentity(address)
+	assert.NoError(t, err)
+}
+
+type moverFake struct {
+	currPass, newPass string
+}
+
+func (m *moverFake) Import(_ []byte, _, _ string) (identity.Identity, error) {
+	return identity.Identity{}, nil
+}
+
+func (m *moverFake) Export(_, currPass, newPass string) ([]byte, error) {
+	m.currPass, m.newPass = currPass, newPass
+	return []byte("key"), nil
+}
+
+func TestExportIdentity(t *testing.T) {
+	mover := &moverFake{}
+	endpoint := &identitiesAPI{idm: identity.NewIdentityManagerFake(existingIdentities, newIdentity), mover: mover}
+	params := httprouter.Params{{Key: "id", Value: "0x000000000000000000000000000000000000000a"}}
+
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, identityUrl+"/export", bytes.NewBufferString(`{"current_passphrase": "old"}`))
+	assert.NoError(t, err)
+	endpoint.Export(resp, req, params)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodPost, identityUrl+"/export", bytes.NewBufferString(`{"current_passphrase": "old", "new_passphrase": "new"}`))
+	assert.NoError(t, err)
+	endpoint.Export(resp, req, params)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "old", mover.currPass)
+	assert.Equal(t, "new", mover.newPass)
+}
+
+func TestChangeIdentityPassphraseWithNoNewPassphrase(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPut, identityUrl, bytes.NewBufferString(`{"current_passphrase": "old"}`))
+	assert.NoError(t, err)
+	params := httprouter.Params{{Key: "id", Value: "0x000000000000000000000000000000000000000a"}}
+
+	endpoint := &identitiesAPI{idm: mockIdm}
+	endpoint.ChangePassphrase(resp, req, params)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
 func TestCreateNewIdentityEmptyPassphrase(t *testing.T) {
 	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
 	resp := httptest.NewRecorder()
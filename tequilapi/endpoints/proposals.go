@@ -24,8 +24,10 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
 
+	"github.com/mysteriumnetwork/node/core/discovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
 )
@@ -38,13 +40,15 @@ type QualityFinder interface {
 type proposalsEndpoint struct {
 	proposalRepository proposal.Repository
 	qualityProvider    QualityFinder
+	historyProvider    discovery.ProposalHistoryProvider
 }
 
 // NewProposalsEndpoint creates and returns proposal creation endpoint
-func NewProposalsEndpoint(proposalRepository proposal.Repository, qualityProvider QualityFinder) *proposalsEndpoint {
+func NewProposalsEndpoint(proposalRepository proposal.Repository, qualityProvider QualityFinder, historyProvider discovery.ProposalHistoryProvider) *proposalsEndpoint {
 	return &proposalsEndpoint{
 		proposalRepository: proposalRepository,
 		qualityProvider:    qualityProvider,
+		historyProvider:    historyProvider,
 	}
 }
 
@@ -81,6 +85,10 @@ func NewProposalsEndpoint(proposalRepository proposal.Repository, qualityProvide
 //     name: location_country
 //     description: If given will filter proposals by node location country.
 //     type: string
+//   - in: query
+//     name: obfuscation
+//     description: If given will filter proposals by offered p2p obfuscation method, e.g. "scramble".
+//     type: string
 // responses:
 //   200:
 //     description: List of proposals
@@ -120,6 +128,7 @@ func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, p
 		AccessPolicySource:  req.URL.Query().Get("access_policy_source"),
 		LocationType:        req.URL.Query().Get("location_type"),
 		LocationCountry:     req.URL.Query().Get("location_country"),
+		Obfuscation:         req.URL.Query().Get("obfuscation"),
 		LowerGBPriceBound:   lowerGBPriceBound,
 		UpperGBPriceBound:   upperGBPriceBound,
 		LowerTimePriceBound: lowerTimePriceBound,
@@ -160,6 +169,47 @@ func (pe *proposalsEndpoint) Quality(resp http.ResponseWriter, req *http.Request
 	utils.WriteAsJSON(contract.NewProposalQualityResponse(quality), resp)
 }
 
+// swagger:operation GET /proposals/history Proposal proposalHistory
+// ---
+// summary: Returns proposal change history
+// description: Returns latest re-announced changes of given proposal, oldest first
+// parameters:
+//   - in: query
+//     name: provider_id
+//     description: id of proposal provider
+//     type: string
+//     required: true
+//   - in: query
+//     name: service_type
+//     description: the service type of the proposal
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: List of proposal changes
+//     schema:
+//       "$ref": "#/definitions/ProposalHistoryResponse"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (pe *proposalsEndpoint) History(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	id := market.ProposalID{
+		ProviderID:  req.URL.Query().Get("provider_id"),
+		ServiceType: req.URL.Query().Get("service_type"),
+	}
+	if id.ProviderID == "" || id.ServiceType == "" {
+		utils.SendError(resp, errors.New("provider_id and service_type are required"), http.StatusBadRequest)
+		return
+	}
+
+	var history []discovery.ProposalUpdatedEvent
+	if pe.historyProvider != nil {
+		history = pe.historyProvider.ProposalHistory(id)
+	}
+	utils.WriteAsJSON(contract.NewProposalHistoryResponse(history), resp)
+}
+
 func parsePriceBound(req *http.Request, key string) (*big.Int, error) {
 	bound := req.URL.Query().Get(key)
 	if bound == "" {
@@ -173,10 +223,11 @@ func parsePriceBound(req *http.Request, key string) (*big.Int, error) {
 }
 
 // AddRoutesForProposals attaches proposals endpoints to router
-func AddRoutesForProposals(router *httprouter.Router, proposalRepository proposal.Repository, qualityProvider QualityFinder) {
-	pe := NewProposalsEndpoint(proposalRepository, qualityProvider)
+func AddRoutesForProposals(router *httprouter.Router, proposalRepository proposal.Repository, qualityProvider QualityFinder, historyProvider discovery.ProposalHistoryProvider) {
+	pe := NewProposalsEndpoint(proposalRepository, qualityProvider, historyProvider)
 	router.GET("/proposals", pe.List)
 	router.GET("/proposals/quality", pe.Quality)
+	router.GET("/proposals/history", pe.History)
 }
 
 // addProposalQuality adds quality metrics to proposals.
@@ -24,9 +24,11 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/mysteriumnetwork/node/core/discovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/market"
@@ -84,7 +86,7 @@ func TestProposalsEndpointListByNodeId(t *testing.T) {
 	req.URL.RawQuery = query.Encode()
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, nil).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -152,7 +154,7 @@ func TestProposalsEndpointAcceptsAccessPolicyParams(t *testing.T) {
 	req.URL.RawQuery = query.Encode()
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, nil).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -209,7 +211,7 @@ func TestProposalsEndpointList(t *testing.T) {
 	assert.Nil(t, err)
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, nil).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -281,7 +283,7 @@ func TestProposalsEndpointListFetchQuality(t *testing.T) {
 
 	resp := httptest.NewRecorder()
 
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, nil).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -344,6 +346,76 @@ func TestProposalsEndpointListFetchQuality(t *testing.T) {
 	)
 }
 
+func TestProposalsEndpointHistory(t *testing.T) {
+	history := &mockProposalHistory{
+		events: []discovery.ProposalUpdatedEvent{{
+			Proposal:   serviceProposals[0],
+			PreviousID: 0,
+			Changes:    []discovery.ProposalChange{{Field: "id", Old: "0", New: "1"}},
+			ChangedAt:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		}},
+	}
+	handlerFunc := NewProposalsEndpoint(&mockProposalRepository{}, &mockQualityProvider{}, history).History
+
+	req := httptest.NewRequest(http.MethodGet, "/proposals/history?provider_id=0xProviderId&service_type=testprotocol", nil)
+	resp := httptest.NewRecorder()
+	handlerFunc(resp, req, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, market.ProposalID{ProviderID: "0xProviderId", ServiceType: "testprotocol"}, history.requested)
+	assert.JSONEq(
+		t,
+		`{
+			"updates": [
+				{
+					"proposal": {
+						"id": 1,
+						"provider_id": "0xProviderId",
+						"service_type": "testprotocol",
+						"service_definition": {
+							"location_originate": {
+								"asn": 123,
+								"country": "Lithuania",
+								"city": "Vilnius"
+							}
+						},
+						"payment_method": {
+							"type": "BYTES_TRANSFERRED_WITH_TIME",
+							"price": {
+								"amount":50000,
+								"currency":"MYST"
+							},
+							"rate":{
+								"per_seconds":60,
+								"per_bytes":7669584
+							}
+						}
+					},
+					"previous_id": 0,
+					"changes": [{"field": "id", "old": "0", "new": "1"}],
+					"changed_at": "2020-01-01T00:00:00Z"
+				}
+			]
+		}`,
+		resp.Body.String(),
+	)
+
+	req = httptest.NewRequest(http.MethodGet, "/proposals/history?provider_id=0xProviderId", nil)
+	resp = httptest.NewRecorder()
+	handlerFunc(resp, req, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+type mockProposalHistory struct {
+	requested market.ProposalID
+	events    []discovery.ProposalUpdatedEvent
+}
+
+func (m *mockProposalHistory) ProposalHistory(id market.ProposalID) []discovery.ProposalUpdatedEvent {
+	m.requested = id
+	return m.events
+}
+
 type mockQualityProvider struct{}
 
 func (m *mockQualityProvider) ProposalsQuality() []quality.ProposalQuality {
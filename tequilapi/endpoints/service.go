@@ -19,6 +19,7 @@ package endpoints
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
@@ -36,9 +37,14 @@ import (
 // ServiceEndpoint struct represents management of service resource and it's sub-resources
 type ServiceEndpoint struct {
 	serviceManager ServiceManager
+	identities     unlockChecker
 	optionsParser  map[string]services.ServiceOptionsParser
 }
 
+type unlockChecker interface {
+	IsUnlocked(identity string) bool
+}
+
 var (
 	// serviceTypeInvalid represents service type which is unknown to node
 	serviceTypeInvalid = "<unknown>"
@@ -47,9 +53,10 @@ var (
 )
 
 // NewServiceEndpoint creates and returns service endpoint
-func NewServiceEndpoint(serviceManager ServiceManager, optionsParser map[string]services.ServiceOptionsParser) *ServiceEndpoint {
+func NewServiceEndpoint(serviceManager ServiceManager, identities unlockChecker, optionsParser map[string]services.ServiceOptionsParser) *ServiceEndpoint {
 	return &ServiceEndpoint{
 		serviceManager: serviceManager,
+		identities:     identities,
 		optionsParser:  optionsParser,
 	}
 }
@@ -102,7 +109,7 @@ func (se *ServiceEndpoint) ServiceGet(resp http.ResponseWriter, _ *http.Request,
 // swagger:operation POST /services Service serviceStart
 // ---
 // summary: Starts service
-// description: Provider starts serving new service to consumers
+// description: Provider starts serving new service to consumers, any unlocked identity can be used as provider
 // parameters:
 //   - in: body
 //     name: body
@@ -143,18 +150,27 @@ func (se *ServiceEndpoint) ServiceStart(resp http.ResponseWriter, req *http.Requ
 		return
 	}
 
+	if !se.identities.IsUnlocked(sr.ProviderID) {
+		errorMap := validation.NewErrorMap()
+		errorMap.ForField("provider_id").AddError("locked", "Identity is not unlocked")
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
 	if se.isAlreadyRunning(sr) {
 		utils.SendErrorMessage(resp, "Service already running", http.StatusConflict)
 		return
 	}
 
 	log.Info().Msgf("Service start options: %+v", sr)
+	paymentMethod := pingpong.NewPaymentMethod(sr.PaymentMethod.PriceGB, sr.PaymentMethod.PriceMinute)
+	paymentMethod.PortForwardingPrice = sr.PaymentMethod.PricePortHour
 	id, err := se.serviceManager.Start(
 		identity.FromAddress(sr.ProviderID),
 		sr.Type,
 		sr.AccessPolicies.IDs,
 		sr.Options,
-		pingpong.NewPaymentMethod(sr.PaymentMethod.PriceGB, sr.PaymentMethod.PriceMinute),
+		paymentMethod,
 	)
 	if err == service.ErrorLocation {
 		utils.SendError(resp, err, http.StatusBadRequest)
@@ -171,6 +187,114 @@ func (se *ServiceEndpoint) ServiceStart(resp http.ResponseWriter, req *http.Requ
 	utils.WriteAsJSON(statusResponse, resp)
 }
 
+// ServiceUpdate applies changes to the running service.
+// swagger:operation PUT /services/:id Service serviceUpdate
+// ---
+// summary: Updates service
+// description: Applies changed options, access policies and prices without stopping active sessions
+// parameters:
+//   - in: body
+//     name: body
+//     description: Changed service parameters, omitted ones are left unchanged
+//     schema:
+//       $ref: "#/definitions/ServiceUpdateRequestDTO"
+// responses:
+//   200:
+//     description: Service updated
+//     schema:
+//       "$ref": "#/definitions/ServiceInfoDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Service not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: Conflict. Changes require service restart
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (se *ServiceEndpoint) ServiceUpdate(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	id := service.ID(params.ByName("id"))
+
+	instance := se.serviceManager.Service(id)
+	if instance == nil {
+		utils.SendErrorMessage(resp, "Service not found", http.StatusNotFound)
+		return
+	}
+
+	var jsonData struct {
+		Options        *json.RawMessage                `json:"options"`
+		PaymentMethod  *contract.ServicePaymentMethod  `json:"payment_method"`
+		AccessPolicies *contract.ServiceAccessPolicies `json:"access_policies"`
+	}
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&jsonData); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	proposal := instance.Proposal()
+	options := instance.Options()
+	if jsonData.Options != nil {
+		// Options missing from the request keep their current values instead of falling back to defaults.
+		merged, err := mergeOptions(options, *jsonData.Options)
+		if err == nil {
+			options = se.toServiceOptions(instance.Type, &merged)
+		}
+		if err != nil || options == serviceOptionsInvalid {
+			errorMap := validation.NewErrorMap()
+			errorMap.ForField("options").AddError("invalid", "Invalid options")
+			utils.SendValidationErrorMessage(resp, errorMap)
+			return
+		}
+	}
+
+	paymentMethod := proposal.PaymentMethod
+	if jsonData.PaymentMethod != nil {
+		paymentMethod = updatePaymentMethod(proposal.PaymentMethod, *jsonData.PaymentMethod)
+	}
+
+	var policyIDs []string
+	if jsonData.AccessPolicies != nil {
+		policyIDs = jsonData.AccessPolicies.IDs
+	} else if proposal.AccessPolicies != nil {
+		for _, policy := range *proposal.AccessPolicies {
+			policyIDs = append(policyIDs, policy.ID)
+		}
+	}
+
+	log.Info().Msgf("Service %s update options: %+v", id, jsonData)
+	err := se.serviceManager.Update(id, policyIDs, options, paymentMethod)
+	switch {
+	case errors.Is(err, service.ErrNoSuchInstance):
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrRestartRequired):
+		utils.SendError(resp, err, http.StatusConflict)
+		return
+	case errors.Is(err, service.ErrUnsupportedAccessPolicy):
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	case err != nil:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	statusResponse := toServiceInfoResponse(id, instance)
+	utils.WriteAsJSON(statusResponse, resp)
+}
+
 // ServiceStop stops service on the node.
 // swagger:operation DELETE /services/:id Service serviceStop
 // ---
@@ -214,12 +338,13 @@ func (se *ServiceEndpoint) isAlreadyRunning(sr contract.ServiceStartRequest) boo
 }
 
 // AddRoutesForService adds service routes to given router
-func AddRoutesForService(router *httprouter.Router, serviceManager ServiceManager, optionsParser map[string]services.ServiceOptionsParser) {
-	serviceEndpoint := NewServiceEndpoint(serviceManager, optionsParser)
+func AddRoutesForService(router *httprouter.Router, serviceManager ServiceManager, identities unlockChecker, optionsParser map[string]services.ServiceOptionsParser) {
+	serviceEndpoint := NewServiceEndpoint(serviceManager, identities, optionsParser)
 
 	router.GET("/services", serviceEndpoint.ServiceList)
 	router.POST("/services", serviceEndpoint.ServiceStart)
 	router.GET("/services/:id", serviceEndpoint.ServiceGet)
+	router.PUT("/services/:id", serviceEndpoint.ServiceUpdate)
 	router.DELETE("/services/:id", serviceEndpoint.ServiceStop)
 }
 
@@ -286,14 +411,46 @@ func (se *ServiceEndpoint) toServiceOptions(serviceType string, value *json.RawM
 	return options
 }
 
+// mergeOptions overlays options given in the patch onto the current service options.
+func mergeOptions(current service.Options, patch json.RawMessage) (json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if current != nil {
+		data, err := json.Marshal(current)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+	}
+
+	var patchFields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &patchFields); err != nil {
+		return nil, err
+	}
+	for key, value := range patchFields {
+		fields[key] = value
+	}
+	return json.Marshal(fields)
+}
+
+// updatePaymentMethod changes prices given in the request, prices missing from the request are left unchanged.
+func updatePaymentMethod(current market.PaymentMethod, request contract.ServicePaymentMethod) market.PaymentMethod {
+	pm, ok := current.(pingpong.PaymentMethod)
+	if !ok {
+		pm = pingpong.NewPaymentMethod(request.PriceGB, request.PriceMinute)
+	}
+	return pm.WithPrices(request.PriceGB, request.PriceMinute, request.PricePortHour)
+}
+
 func toServiceInfoResponse(id service.ID, instance *service.Instance) contract.ServiceInfoDTO {
 	return contract.ServiceInfoDTO{
 		ID:         string(id),
 		ProviderID: instance.ProviderID.Address,
 		Type:       instance.Type,
-		Options:    instance.Options,
+		Options:    instance.Options(),
 		Status:     string(instance.State()),
-		Proposal:   contract.NewProposalDTO(instance.Proposal),
+		Proposal:   contract.NewProposalDTO(instance.Proposal()),
 	}
 }
 
@@ -326,6 +483,7 @@ func validateServiceRequest(sr contract.ServiceStartRequest) *validation.FieldEr
 type ServiceManager interface {
 	Start(providerID identity.Identity, serviceType string, policies []string, options service.Options, pm market.PaymentMethod) (service.ID, error)
 	Stop(id service.ID) error
+	Update(id service.ID, policies []string, options service.Options, pm market.PaymentMethod) error
 	Service(id service.ID) *service.Instance
 	Kill() error
 	List() map[service.ID]*service.Instance
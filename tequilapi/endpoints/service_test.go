@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -33,6 +34,8 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/services"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -90,7 +93,10 @@ type fancyServiceOptions struct {
 	Foo string `json:"foo"`
 }
 
-type mockServiceManager struct{}
+type mockServiceManager struct {
+	updatedPolicies []string
+	updatedPM       market.PaymentMethod
+}
 
 func (sm *mockServiceManager) Start(providerID identity.Identity, serviceType string, policyIDs []string, options service.Options, _ market.PaymentMethod) (service.ID, error) {
 	if serviceType == serviceTypeWithAccessPolicy {
@@ -99,6 +105,14 @@ func (sm *mockServiceManager) Start(providerID identity.Identity, serviceType st
 	return mockServiceID, nil
 }
 func (sm *mockServiceManager) Stop(id service.ID) error { return nil }
+func (sm *mockServiceManager) Update(id service.ID, policyIDs []string, options service.Options, pm market.PaymentMethod) error {
+	if options != mockServiceOptions {
+		return fmt.Errorf("%w: options changed", service.ErrRestartRequired)
+	}
+	sm.updatedPolicies = policyIDs
+	sm.updatedPM = pm
+	return nil
+}
 func (sm *mockServiceManager) Service(id service.ID) *service.Instance {
 	if id == "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
 		return mockServiceRunning
@@ -115,6 +129,10 @@ func (sm *mockServiceManager) List() map[service.ID]*service.Instance {
 }
 func (sm *mockServiceManager) Kill() error { return nil }
 
+type unlockCheckerFake bool
+
+func (uc unlockCheckerFake) IsUnlocked(_ string) bool { return bool(uc) }
+
 var fakeOptionsParser = map[string]services.ServiceOptionsParser{
 	"testprotocol": func(opts *json.RawMessage) (service.Options, error) {
 		return nil, nil
@@ -129,7 +147,7 @@ var fakeOptionsParser = map[string]services.ServiceOptionsParser{
 
 func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 	router := httprouter.New()
-	AddRoutesForService(router, &mockServiceManager{}, fakeOptionsParser)
+	AddRoutesForService(router, &mockServiceManager{}, unlockCheckerFake(true), fakeOptionsParser)
 
 	tests := []struct {
 		method         string
@@ -261,8 +279,36 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 	}
 }
 
+func Test_ServiceStartWithLockedIdentity(t *testing.T) {
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, unlockCheckerFake(false), fakeOptionsParser)
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/irrelevant",
+		strings.NewReader(`{
+			"type": "testprotocol",
+			"provider_id": "0x9edf75f870d87d2d1a69f0d950a99984ae955ee0"
+		}`),
+	)
+	resp := httptest.NewRecorder()
+
+	serviceEndpoint.ServiceStart(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"provider_id": [ {"code": "locked", "message": "Identity is not unlocked"} ]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
+
 func Test_ServiceStartInvalidType(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, unlockCheckerFake(true), fakeOptionsParser)
 
 	req := httptest.NewRequest(
 		http.MethodGet,
@@ -291,7 +337,7 @@ func Test_ServiceStartInvalidType(t *testing.T) {
 }
 
 func Test_ServiceStart_InvalidType(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, unlockCheckerFake(true), fakeOptionsParser)
 
 	req := httptest.NewRequest(
 		http.MethodGet,
@@ -320,7 +366,7 @@ func Test_ServiceStart_InvalidType(t *testing.T) {
 }
 
 func Test_ServiceStart_InvalidOptions(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, unlockCheckerFake(true), fakeOptionsParser)
 
 	req := httptest.NewRequest(
 		http.MethodGet,
@@ -349,7 +395,7 @@ func Test_ServiceStart_InvalidOptions(t *testing.T) {
 }
 
 func Test_ServiceStartAlreadyRunning(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, unlockCheckerFake(true), fakeOptionsParser)
 
 	req := httptest.NewRequest(
 		http.MethodGet,
@@ -373,7 +419,7 @@ func Test_ServiceStartAlreadyRunning(t *testing.T) {
 }
 
 func Test_ServiceStatus_NotFoundIsReturnedWhenNotStarted(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, unlockCheckerFake(true), fakeOptionsParser)
 
 	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
@@ -383,8 +429,31 @@ func Test_ServiceStatus_NotFoundIsReturnedWhenNotStarted(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, resp.Code)
 }
 
+func Test_ServiceUpdate_MergesOptions(t *testing.T) {
+	merged, err := mergeOptions(struct {
+		Protocol string `json:"protocol"`
+		Port     int    `json:"port"`
+	}{Protocol: "tcp", Port: 1194}, json.RawMessage(`{"port": 1195}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"protocol": "tcp", "port": 1195}`, string(merged))
+
+	_, err = mergeOptions(nil, json.RawMessage(`"invalid"`))
+	assert.Error(t, err)
+}
+
+func Test_ServiceUpdate_KeepsOmittedPrices(t *testing.T) {
+	current := pingpong.NewPaymentMethod(big.NewInt(1000), big.NewInt(10))
+	current.PortForwardingPrice = big.NewInt(5)
+
+	updated := updatePaymentMethod(current, contract.ServicePaymentMethod{PriceMinute: big.NewInt(20)})
+
+	expected := pingpong.NewPaymentMethod(big.NewInt(1000), big.NewInt(20))
+	expected.PortForwardingPrice = big.NewInt(5)
+	assert.Equal(t, expected, updated)
+}
+
 func Test_ServiceGetReturnsServiceInfo(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, unlockCheckerFake(true), fakeOptionsParser)
 
 	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
@@ -429,7 +498,7 @@ func Test_ServiceGetReturnsServiceInfo(t *testing.T) {
 	)
 }
 func Test_ServiceCreate_Returns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, unlockCheckerFake(true), fakeOptionsParser)
 
 	req := httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader("a"))
 	resp := httptest.NewRecorder()
@@ -447,7 +516,7 @@ func Test_ServiceCreate_Returns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 }
 
 func Test_ServiceCreate_Returns422ErrorIfRequestBodyIsMissingFieldValues(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, unlockCheckerFake(true), fakeOptionsParser)
 
 	req := httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader("{}"))
 	resp := httptest.NewRecorder()
@@ -468,7 +537,7 @@ func Test_ServiceCreate_Returns422ErrorIfRequestBodyIsMissingFieldValues(t *test
 }
 
 func Test_ServiceStart_WithAccessPolicy(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, unlockCheckerFake(true), fakeOptionsParser)
 
 	req := httptest.NewRequest(
 		http.MethodGet,
@@ -538,7 +607,7 @@ func Test_ServiceStart_WithAccessPolicy(t *testing.T) {
 }
 
 func Test_ServiceStart_ReturnsBadRequest_WithUnknownParams(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, unlockCheckerFake(true), fakeOptionsParser)
 
 	req := httptest.NewRequest(
 		http.MethodGet,
@@ -562,3 +631,29 @@ func Test_ServiceStart_ReturnsBadRequest_WithUnknownParams(t *testing.T) {
 		resp.Body.String(),
 	)
 }
+
+func Test_ServiceUpdate(t *testing.T) {
+	manager := &mockServiceManager{}
+	serviceEndpoint := NewServiceEndpoint(manager, unlockCheckerFake(true), fakeOptionsParser)
+	params := httprouter.Params{{Key: "id", Value: string(mockAccessPolicyServiceID)}}
+
+	req := httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader(`{"payment_method": {"price_gb": 1000, "price_minute": 10}}`))
+	resp := httptest.NewRecorder()
+	serviceEndpoint.ServiceUpdate(resp, req, params)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []string{"verified-traffic", "0x0000000000000001", "dvpn-traffic", "12312312332132"}, manager.updatedPolicies)
+	assert.NotEqual(t, mocks.DefaultPaymentMethod(), manager.updatedPM)
+
+	req = httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader(`{"options": {"foo": "baz"}}`))
+	resp = httptest.NewRecorder()
+	serviceEndpoint.ServiceUpdate(resp, req, params)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader(`{}`))
+	resp = httptest.NewRecorder()
+	serviceEndpoint.ServiceUpdate(resp, req, httprouter.Params{{Key: "id", Value: "unknown"}})
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
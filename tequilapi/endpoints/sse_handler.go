@@ -83,11 +83,11 @@ func NewSSEHandler(stateProvider stateProvider) *Handler {
 
 // Subscribe subscribes to the event bus.
 func (h *Handler) Subscribe(bus eventbus.Subscriber) error {
-	err := bus.Subscribe(nodeEvent.AppTopicNode, h.ConsumeNodeEvent)
+	err := nodeEvent.SubscribeNode(bus, eventbus.DeliverSync, h.ConsumeNodeEvent)
 	if err != nil {
 		return err
 	}
-	err = bus.Subscribe(stateEvent.AppTopicState, h.ConsumeStateEvent)
+	err = stateEvent.SubscribeState(bus, eventbus.DeliverSync, h.ConsumeStateEvent)
 	return err
 }
 
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type storageCompactor interface {
+	Compact() (storage.Compaction, error)
+}
+
+type storageAPI struct {
+	storage storageCompactor
+}
+
+// Compact compacts node database.
+// swagger:operation POST /storage/compact Storage compactStorage
+// ---
+// summary: Compacts node database
+// description: Rewrites database file without free space left by removed history, database is not available until it is done
+// responses:
+//   200:
+//     description: Database compacted
+//     schema:
+//       "$ref": "#/definitions/StorageCompactResponseDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (api *storageAPI) Compact(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	compaction, err := api.storage.Compact()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.StorageCompactResponse{
+		SizeBefore: compaction.SizeBefore,
+		SizeAfter:  compaction.SizeAfter,
+	}, resp)
+}
+
+// AddRoutesForStorage attaches storage endpoints to router.
+func AddRoutesForStorage(router *httprouter.Router, storage storageCompactor) {
+	api := &storageAPI{storage: storage}
+	router.POST("/storage/compact", api.Compact)
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+)
+
+type storageCompactorFake struct {
+	err error
+}
+
+func (sc *storageCompactorFake) Compact() (storage.Compaction, error) {
+	return storage.Compaction{SizeBefore: 2048, SizeAfter: 1024}, sc.err
+}
+
+func Test_StorageCompact(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForStorage(router, &storageCompactorFake{})
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/storage/compact", nil)
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"size_before": 2048, "size_after": 1024}`, resp.Body.String())
+}
+
+func Test_StorageCompactFails(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForStorage(router, &storageCompactorFake{err: errors.New("disk full")})
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/storage/compact", nil)
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
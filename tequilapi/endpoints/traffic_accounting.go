@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/mysteriumnetwork/node/core/accounting"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type trafficAccountingStorage interface {
+	List() ([]accounting.SessionTraffic, error)
+	Get(sessionID string) (accounting.SessionTraffic, error)
+}
+
+type trafficAccountingEndpoint struct {
+	storage trafficAccountingStorage
+}
+
+// NewTrafficAccountingEndpoint creates and returns traffic accounting endpoint
+func NewTrafficAccountingEndpoint(storage trafficAccountingStorage) *trafficAccountingEndpoint {
+	return &trafficAccountingEndpoint{
+		storage: storage,
+	}
+}
+
+// swagger:operation GET /traffic-accounting TrafficAccounting trafficAccountingList
+// ---
+// summary: Returns traffic accounting of provided sessions
+// description: Returns aggregated traffic by destination category of provided sessions, most recent first
+// responses:
+//   200:
+//     description: List of sessions traffic
+//     schema:
+//       "$ref": "#/definitions/TrafficAccountingListResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *trafficAccountingEndpoint) List(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	records, err := endpoint.storage.List()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewTrafficAccountingListResponse(records), resp)
+}
+
+// swagger:operation GET /traffic-accounting/{id} TrafficAccounting trafficAccountingGet
+// ---
+// summary: Returns traffic accounting of a provided session
+// description: Returns aggregated traffic by destination category of the given session
+// parameters:
+// - name: id
+//   in: path
+//   description: session id
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Session traffic
+//     schema:
+//       "$ref": "#/definitions/TrafficAccountingDTO"
+//   404:
+//     description: Session traffic not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *trafficAccountingEndpoint) Get(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	record, err := endpoint.storage.Get(params.ByName("id"))
+	if errors.Is(err, accounting.ErrNotFound) {
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewTrafficAccountingDTO(record), resp)
+}
+
+// AddRoutesForTrafficAccounting attaches traffic accounting endpoints to router
+func AddRoutesForTrafficAccounting(router *httprouter.Router, storage trafficAccountingStorage) {
+	endpoint := NewTrafficAccountingEndpoint(storage)
+	router.GET("/traffic-accounting", endpoint.List)
+	router.GET("/traffic-accounting/:id", endpoint.Get)
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/accounting"
+	"github.com/mysteriumnetwork/node/nat"
+)
+
+var trafficRecord = accounting.SessionTraffic{
+	SessionID:   "session1",
+	ServiceType: "wireguard",
+	Started:     time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC),
+	Updated:     time.Date(2020, 11, 1, 12, 30, 0, 0, time.UTC),
+	DNSQueries:  map[string]uint64{"streaming": 3},
+	Traffic:     map[string]nat.TrafficCounter{"web": {Up: 10, Down: 100}},
+}
+
+func Test_TrafficAccountingEndpoint_List(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/traffic-accounting", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	AddRoutesForTrafficAccounting(router, &mockTrafficAccountingStorage{records: []accounting.SessionTraffic{trafficRecord}})
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t,
+		`{
+			"items": [{
+				"session_id": "session1",
+				"service_type": "wireguard",
+				"created_at": "2020-11-01T12:00:00Z",
+				"updated_at": "2020-11-01T12:30:00Z",
+				"dns_queries": {"streaming": 3},
+				"traffic": {"web": {"bytes_sent": 10, "bytes_received": 100}}
+			}]
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func Test_TrafficAccountingEndpoint_GetReturns404WhenNotFound(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/traffic-accounting/unknown", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	AddRoutesForTrafficAccounting(router, &mockTrafficAccountingStorage{records: []accounting.SessionTraffic{trafficRecord}})
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+type mockTrafficAccountingStorage struct {
+	records []accounting.SessionTraffic
+}
+
+func (m *mockTrafficAccountingStorage) List() ([]accounting.SessionTraffic, error) {
+	return m.records, nil
+}
+
+func (m *mockTrafficAccountingStorage) Get(sessionID string) (accounting.SessionTraffic, error) {
+	for _, record := range m.records {
+		if record.SessionID == sessionID {
+			return record, nil
+		}
+	}
+	return accounting.SessionTraffic{}, accounting.ErrNotFound
+}
@@ -0,0 +1,215 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/audit"
+	"github.com/mysteriumnetwork/node/core/auth"
+)
+
+// Request fields never written to the audit log.
+var auditRedactedFields = map[string]bool{
+	"passphrase":         true,
+	"current_passphrase": true,
+	"new_passphrase":     true,
+	"password":           true,
+	"data":               true,
+}
+
+// auditRoute maps a tequilapi route to the audited action.
+// Config routes are not listed here, they record old and new values themselves.
+type auditRoute struct {
+	method  string
+	pattern string
+	action  string
+	// targetFromResponse takes the target from the "id" field of the response when the path has none.
+	targetFromResponse bool
+}
+
+var auditRoutes = []auditRoute{
+	{method: http.MethodPost, pattern: "/services", action: audit.ActionServiceStart, targetFromResponse: true},
+	{method: http.MethodPut, pattern: "/services/:id", action: audit.ActionServiceUpdate},
+	{method: http.MethodDelete, pattern: "/services/:id", action: audit.ActionServiceStop},
+	{method: http.MethodPost, pattern: "/identities", action: audit.ActionIdentityCreate, targetFromResponse: true},
+	{method: http.MethodPost, pattern: "/identities-import", action: audit.ActionIdentityImport, targetFromResponse: true},
+	{method: http.MethodPost, pattern: "/identities/:id/export", action: audit.ActionIdentityExport},
+	{method: http.MethodDelete, pattern: "/identities/:id", action: audit.ActionIdentityDelete},
+	{method: http.MethodPut, pattern: "/identities/:id/passphrase", action: audit.ActionIdentityPassphrase},
+	{method: http.MethodPost, pattern: "/identities/:id/register", action: audit.ActionIdentityRegister},
+	{method: http.MethodPut, pattern: "/identities/:id/payout", action: audit.ActionPayoutUpdate},
+	{method: http.MethodPut, pattern: "/identities/:id/referral", action: audit.ActionReferralUpdate},
+	{method: http.MethodPut, pattern: "/identities/:id/email", action: audit.ActionEmailUpdate},
+	{method: http.MethodPost, pattern: "/identities/:id/beneficiary", action: audit.ActionBeneficiary},
+	{method: http.MethodPost, pattern: "/transactor/settle/sync", action: audit.ActionSettle},
+	{method: http.MethodPost, pattern: "/transactor/settle/async", action: audit.ActionSettle},
+	{method: http.MethodPost, pattern: "/transactor/stake/increase/sync", action: audit.ActionStakeIncrease},
+	{method: http.MethodPost, pattern: "/transactor/stake/increase/async", action: audit.ActionStakeIncrease},
+	{method: http.MethodPost, pattern: "/transactor/stake/decrease", action: audit.ActionStakeDecrease},
+}
+
+// match returns whether the route matches the request and the value of its :id segment.
+func (r auditRoute) match(method, path string) (bool, string) {
+	if r.method != method {
+		return false, ""
+	}
+	patternParts := strings.Split(r.pattern, "/")
+	pathParts := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	if len(patternParts) != len(pathParts) {
+		return false, ""
+	}
+	var id string
+	for i, part := range patternParts {
+		if strings.HasPrefix(part, ":") {
+			id = pathParts[i]
+			continue
+		}
+		if part != pathParts[i] {
+			return false, ""
+		}
+	}
+	return true, id
+}
+
+type usernameResolver interface {
+	Username(token string) (string, error)
+}
+
+type auditRecorder interface {
+	Record(ctx context.Context, action, target string, old, new interface{})
+}
+
+type auditHandler struct {
+	originalHandler http.Handler
+	users           usernameResolver
+	recorder        auditRecorder
+}
+
+// ApplyAudit wraps original handler by attaching the authenticated tequilapi user to the request context
+// and recording successful state changing requests in the audit log.
+func ApplyAudit(original http.Handler, users usernameResolver, recorder auditRecorder) http.Handler {
+	return auditHandler{originalHandler: original, users: users, recorder: recorder}
+}
+
+func (wrapper auditHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if user := wrapper.username(req); user != "" {
+		req = req.WithContext(audit.WithUser(req.Context(), user))
+	}
+
+	route, target, ok := findAuditRoute(req)
+	if !ok {
+		wrapper.originalHandler.ServeHTTP(resp, req)
+		return
+	}
+
+	var body []byte
+	if req.Body != nil {
+		body, _ = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	recorder := &statusRecorder{ResponseWriter: resp, status: http.StatusOK, captureBody: route.targetFromResponse && target == ""}
+	wrapper.originalHandler.ServeHTTP(recorder, req)
+	if recorder.status >= http.StatusBadRequest {
+		return
+	}
+
+	if recorder.captureBody {
+		var created struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(recorder.body.Bytes(), &created); err == nil {
+			target = created.ID
+		}
+	}
+	wrapper.recorder.Record(req.Context(), route.action, target, nil, redactAuditBody(body))
+}
+
+func (wrapper auditHandler) username(req *http.Request) string {
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == req.Header.Get("Authorization") {
+		cookie, err := req.Cookie(auth.JWTCookieName)
+		if err != nil {
+			return ""
+		}
+		token = cookie.Value
+	}
+	user, err := wrapper.users.Username(token)
+	if err != nil {
+		return ""
+	}
+	return user
+}
+
+func findAuditRoute(req *http.Request) (auditRoute, string, bool) {
+	for _, route := range auditRoutes {
+		if ok, id := route.match(req.Method, req.URL.Path); ok {
+			return route, id, true
+		}
+	}
+	return auditRoute{}, "", false
+}
+
+// redactAuditBody decodes a JSON request body dropping secrets, non JSON bodies are not recorded.
+func redactAuditBody(body []byte) interface{} {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil
+	}
+	for key := range fields {
+		if auditRedactedFields[key] {
+			delete(fields, key)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	captureBody bool
+	body        bytes.Buffer
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if r.captureBody {
+		r.body.Write(data)
+	}
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/audit"
+)
+
+func TestAuditRecordsSuccessfulActions(t *testing.T) {
+	router := httprouter.New()
+	router.POST("/services", func(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		resp.WriteHeader(http.StatusCreated)
+		resp.Write([]byte(`{"id": "service-1", "type": "wireguard"}`))
+	})
+	router.PUT("/identities/:id/passphrase", func(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		resp.WriteHeader(http.StatusAccepted)
+	})
+	router.DELETE("/services/:id", func(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		resp.WriteHeader(http.StatusNotFound)
+	})
+	recorder := &mockAuditRecorder{}
+	handler := ApplyAudit(router, mockUsers{"valid": "myst"}, recorder)
+
+	req := httptest.NewRequest(http.MethodPost, "/services", strings.NewReader(`{"provider_id": "0x1", "type": "wireguard"}`))
+	req.Header.Set("Authorization", "Bearer valid")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodPut, "/identities/0x1/passphrase", strings.NewReader(`{"current_passphrase": "a", "new_passphrase": "b"}`))
+	req.AddCookie(&http.Cookie{Name: "token", Value: "invalid"})
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodDelete, "/services/service-2", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, []audit.Entry{
+		{User: "myst", Action: audit.ActionServiceStart, Target: "service-1", New: map[string]interface{}{"provider_id": "0x1", "type": "wireguard"}},
+		{User: audit.UserLocal, Action: audit.ActionIdentityPassphrase, Target: "0x1"},
+	}, recorder.entries)
+}
+
+func TestAuditAttachesUserToRequestContext(t *testing.T) {
+	var user string
+	handler := ApplyAudit(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		user = audit.UserFrom(req.Context())
+	}), mockUsers{"valid": "myst"}, &mockAuditRecorder{})
+
+	req := httptest.NewRequest(http.MethodGet, "/config", nil)
+	req.AddCookie(&http.Cookie{Name: "token", Value: "valid"})
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "myst", user)
+}
+
+type mockUsers map[string]string
+
+func (m mockUsers) Username(token string) (string, error) {
+	if user, ok := m[token]; ok {
+		return user, nil
+	}
+	return "", errors.New("invalid token")
+}
+
+type mockAuditRecorder struct {
+	entries []audit.Entry
+}
+
+func (m *mockAuditRecorder) Record(ctx context.Context, action, target string, old, new interface{}) {
+	m.entries = append(m.entries, audit.Entry{User: audit.UserFrom(ctx), Action: action, Target: target, Old: old, New: new})
+}
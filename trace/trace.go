@@ -88,6 +88,18 @@ func (t *Tracer) EndStage(key string) {
 	s.end = time.Now()
 }
 
+// FailStage ends tracing stage for given key and records error as stage outcome.
+func (t *Tracer) FailStage(key string, err error) {
+	t.EndStage(key)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if s, ok := t.findStage(key); ok {
+		s.err = err
+	}
+}
+
 // Finish finishes tracing and returns formatted string with stages durations.
 func (t *Tracer) Finish(eventPublisher eventbus.Publisher, id string) string {
 	t.EndStage(t.name)
@@ -100,7 +112,11 @@ func (t *Tracer) Finish(eventPublisher eventbus.Publisher, id string) string {
 	for _, s := range t.stages {
 		if s.end.After(time.Time{}) {
 			t.publishStageEvent(eventPublisher, id, *s)
-			strs = append(strs, fmt.Sprintf("%q took %s", s.key, s.end.Sub(s.start).String()))
+			if s.err != nil {
+				strs = append(strs, fmt.Sprintf("%q failed after %s: %v", s.key, s.end.Sub(s.start).String(), s.err))
+			} else {
+				strs = append(strs, fmt.Sprintf("%q took %s", s.key, s.end.Sub(s.start).String()))
+			}
 		} else {
 			strs = append(strs, fmt.Sprintf("%q did not start", s.key))
 		}
@@ -123,18 +139,21 @@ func (t *Tracer) publishStageEvent(eventPublisher eventbus.Publisher, id string,
 		return
 	}
 
-	eventPublisher.Publish(AppTopicTraceEvent,
-		Event{
-			ID:       id,
-			Key:      stage.key,
-			Duration: stage.end.Sub(stage.start),
-		},
-	)
+	event := Event{
+		ID:       id,
+		Key:      stage.key,
+		Duration: stage.end.Sub(stage.start),
+	}
+	if stage.err != nil {
+		event.Error = stage.err.Error()
+	}
+	PublishTraceEvent(eventPublisher, event)
 }
 
 type stage struct {
 	key        string
 	start, end time.Time
+	err        error
 }
 
 // Event represents a published Trace event.
@@ -142,4 +161,16 @@ type Event struct {
 	ID       string
 	Key      string
 	Duration time.Duration
+	// Error is set when stage failed.
+	Error string
+}
+
+// PublishTraceEvent publishes trace events on the bus.
+func PublishTraceEvent(publisher eventbus.Publisher, e Event) {
+	eventbus.Handle(AppTopicTraceEvent).Publish(publisher, e)
+}
+
+// SubscribeTraceEvent subscribes fn to trace events.
+func SubscribeTraceEvent(subscriber eventbus.Subscriber, delivery eventbus.Delivery, fn func(Event)) error {
+	return eventbus.Handle(AppTopicTraceEvent).Subscribe(subscriber, delivery, fn)
 }
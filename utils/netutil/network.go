@@ -23,7 +23,6 @@ import (
 	"strings"
 
 	"github.com/jackpal/gateway"
-	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -43,13 +42,19 @@ type route struct {
 	Record string `storm:"id"`
 }
 
+type routeStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
 type routeManager struct {
-	db          *boltdb.Bolt
+	db          routeStorage
 	deleteRoute func(ip, wg string) error
 }
 
 // SetRouteManagerStorage initiate defaultRouteManager with a provided storage.
-func SetRouteManagerStorage(db *boltdb.Bolt) {
+func SetRouteManagerStorage(db routeStorage) {
 	defaultRouteManager = &routeManager{
 		db:          db,
 		deleteRoute: deleteRoute,
@@ -113,6 +118,58 @@ func AddDefaultRoute(iface string) error {
 	return addDefaultRoute(iface)
 }
 
+// AddTunnelRoutes routes given networks through the tunnel interface.
+// Whole IPv4 address space is routed by adding the default VPN tunnel route.
+func AddTunnelRoutes(iface string, networks []string) error {
+	for _, network := range networks {
+		if network == "0.0.0.0/0" {
+			return addDefaultRoute(iface)
+		}
+	}
+
+	for _, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return fmt.Errorf("could not parse network %q: %w", network, err)
+		}
+		if err := addRoute(iface, *ipNet); err != nil {
+			return fmt.Errorf("could not add route %s: %w", network, err)
+		}
+	}
+	return nil
+}
+
+// UpdateTunnelRoutes adds routes of networks which were not routed through the tunnel
+// interface before and removes routes of networks which are not routed anymore.
+func UpdateTunnelRoutes(iface string, previous, networks []string) error {
+	for _, network := range networks {
+		if containsString(previous, network) {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return fmt.Errorf("could not parse network %q: %w", network, err)
+		}
+		if err := addRoute(iface, *ipNet); err != nil {
+			return fmt.Errorf("could not add route %s: %w", network, err)
+		}
+	}
+
+	for _, network := range previous {
+		if containsString(networks, network) {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return fmt.Errorf("could not parse network %q: %w", network, err)
+		}
+		if err := removeRoute(iface, *ipNet); err != nil {
+			log.Warn().Err(err).Msgf("Could not remove route %s", network)
+		}
+	}
+	return nil
+}
+
 // AssignIP assigns subnet to given interface.
 func AssignIP(iface string, subnet net.IPNet) error {
 	return assignIP(iface, subnet)
@@ -135,3 +192,12 @@ func logOutputToTrace(out []byte, err error, args ...string) {
 		(&logSkipFrame).Trace().Msgf("%q output:\n%s", strings.Join(args, " "), out)
 	}
 }
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
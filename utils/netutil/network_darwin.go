@@ -21,11 +21,17 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"strconv"
 
 	"github.com/mysteriumnetwork/node/utils/cmdutil"
 )
 
 func assignIP(iface string, subnet net.IPNet) error {
+	if subnet.IP.To4() == nil {
+		ones, _ := subnet.Mask.Size()
+		return cmdutil.SudoExec("ifconfig", iface, "inet6", subnet.IP.String(), "prefixlen", strconv.Itoa(ones), "alias")
+	}
+
 	if err := cmdutil.SudoExec("ifconfig", iface, subnet.String(), peerIP(subnet).String()); err != nil {
 		return err
 	}
@@ -65,6 +71,20 @@ func addDefaultRoute(iface string) error {
 	return nil
 }
 
+func addRoute(iface string, network net.IPNet) error {
+	if network.IP.To4() == nil {
+		return cmdutil.SudoExec("route", "add", "-inet6", network.String(), fmt.Sprintf("100::1%%%s", iface))
+	}
+	return cmdutil.SudoExec("route", "add", "-net", network.String(), "-interface", iface)
+}
+
+func removeRoute(iface string, network net.IPNet) error {
+	if network.IP.To4() == nil {
+		return cmdutil.SudoExec("route", "delete", "-inet6", network.String(), fmt.Sprintf("100::1%%%s", iface))
+	}
+	return cmdutil.SudoExec("route", "delete", "-net", network.String(), "-interface", iface)
+}
+
 func peerIP(subnet net.IPNet) net.IP {
 	lastOctetID := len(subnet.IP) - 1
 	if subnet.IP[lastOctetID] == byte(1) {
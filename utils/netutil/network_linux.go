@@ -59,6 +59,14 @@ func addDefaultRoute(iface string) error {
 	return nil
 }
 
+func addRoute(iface string, network net.IPNet) error {
+	return cmdutil.SudoExec("ip", "route", "add", network.String(), "dev", iface)
+}
+
+func removeRoute(iface string, network net.IPNet) error {
+	return cmdutil.SudoExec("ip", "route", "del", network.String(), "dev", iface)
+}
+
 func logNetworkStats() {
 	for _, args := range [][]string{{"iptables", "-L", "-n"}, {"iptables", "-L", "-n", "-t", "nat"}, {"ip", "route", "list"}, {"ip", "address", "list"}} {
 		out, err := exec.Command("sudo", args...).CombinedOutput()
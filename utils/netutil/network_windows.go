@@ -28,6 +28,10 @@ import (
 )
 
 func assignIP(iface string, subnet net.IPNet) error {
+	if subnet.IP.To4() == nil {
+		out, err := exec.Command("powershell", "-Command", "netsh interface ipv6 add address interface=\""+iface+"\" address="+subnet.String()).CombinedOutput()
+		return errors.Wrap(err, string(out))
+	}
 	out, err := exec.Command("powershell", "-Command", "netsh interface ip set address name=\""+iface+"\" source=static "+subnet.String()).CombinedOutput()
 	return errors.Wrap(err, string(out))
 }
@@ -71,6 +75,35 @@ func addDefaultRoute(name string) error {
 	return nil
 }
 
+func addRoute(name string, network net.IPNet) error {
+	id, gw, err := interfaceInfo(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get info of interface: "+name)
+	}
+
+	if network.IP.To4() == nil {
+		gw = "100::1"
+	}
+	if out, err := exec.Command("powershell", "-Command", "route add "+network.String()+" "+gw+" if "+id).CombinedOutput(); err != nil {
+		return errors.Wrap(err, string(out))
+	}
+
+	return nil
+}
+
+func removeRoute(name string, network net.IPNet) error {
+	id, _, err := interfaceInfo(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get info of interface: "+name)
+	}
+
+	if out, err := exec.Command("powershell", "-Command", "route delete "+network.String()+" if "+id).CombinedOutput(); err != nil {
+		return errors.Wrap(err, string(out))
+	}
+
+	return nil
+}
+
 func interfaceInfo(name string) (id, gw string, err error) {
 	iface, err := net.InterfaceByName(name)
 	if err != nil {
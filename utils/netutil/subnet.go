@@ -39,3 +39,49 @@ func inc(ip net.IP) {
 		}
 	}
 }
+
+// SubtractNetworks returns networks which cover the given network without the subtracted ones.
+func SubtractNetworks(network net.IPNet, subtract []net.IPNet) []net.IPNet {
+	network = normalizeNetwork(network)
+
+	overlaps := false
+	for _, s := range subtract {
+		s = normalizeNetwork(s)
+		if len(s.IP) != len(network.IP) {
+			continue
+		}
+		sOnes, _ := s.Mask.Size()
+		nOnes, _ := network.Mask.Size()
+		if s.Contains(network.IP) && sOnes <= nOnes {
+			return nil
+		}
+		if network.Contains(s.IP) {
+			overlaps = true
+		}
+	}
+	if !overlaps {
+		return []net.IPNet{network}
+	}
+
+	ones, bits := network.Mask.Size()
+	mask := net.CIDRMask(ones+1, bits)
+	lower := net.IPNet{IP: network.IP, Mask: mask}
+	upperIP := make(net.IP, len(network.IP))
+	copy(upperIP, network.IP)
+	upperIP[ones/8] |= 0x80 >> uint(ones%8)
+	upper := net.IPNet{IP: upperIP, Mask: mask}
+
+	return append(SubtractNetworks(lower, subtract), SubtractNetworks(upper, subtract)...)
+}
+
+func normalizeNetwork(network net.IPNet) net.IPNet {
+	ones, bits := network.Mask.Size()
+	if ip := network.IP.To4(); ip != nil && bits == 8*net.IPv4len {
+		return net.IPNet{IP: ip.Mask(network.Mask), Mask: network.Mask}
+	}
+	if ip := network.IP.To4(); ip != nil {
+		mask := net.CIDRMask(ones-(bits-8*net.IPv4len), 8*net.IPv4len)
+		return net.IPNet{IP: ip.Mask(mask), Mask: mask}
+	}
+	return net.IPNet{IP: network.IP.Mask(network.Mask), Mask: network.Mask}
+}
@@ -57,3 +57,35 @@ func TestFirstIP(t *testing.T) {
 		})
 	}
 }
+
+func TestSubtractNetworks(t *testing.T) {
+	parse := func(cidr string) net.IPNet {
+		_, network, err := net.ParseCIDR(cidr)
+		assert.NoError(t, err)
+		return *network
+	}
+	format := func(networks []net.IPNet) (res []string) {
+		for _, n := range networks {
+			res = append(res, n.String())
+		}
+		return res
+	}
+
+	assert.Equal(t,
+		[]string{"10.0.0.0/8"},
+		format(SubtractNetworks(parse("10.0.0.0/8"), []net.IPNet{parse("192.168.0.0/16")})),
+	)
+	assert.Nil(t, SubtractNetworks(parse("10.1.0.0/16"), []net.IPNet{parse("10.0.0.0/8")}))
+	assert.Equal(t,
+		[]string{"0.0.0.0/1", "192.0.0.0/2"},
+		format(SubtractNetworks(parse("0.0.0.0/0"), []net.IPNet{parse("128.0.0.0/2")})),
+	)
+	assert.Equal(t,
+		[]string{"10.0.0.0/25", "10.0.0.128/26", "10.0.0.192/27", "10.0.0.224/28", "10.0.0.240/29", "10.0.0.248/30", "10.0.0.252/31", "10.0.0.255/32"},
+		format(SubtractNetworks(parse("10.0.0.0/24"), []net.IPNet{parse("10.0.0.254/32")})),
+	)
+	assert.Equal(t,
+		[]string{"::/0"},
+		format(SubtractNetworks(parse("::/0"), []net.IPNet{parse("10.0.0.0/8")})),
+	)
+}